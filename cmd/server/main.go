@@ -2,16 +2,21 @@ package main
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
+	_ "time/tzdata" // member time zones must resolve without the OS database
 
+	"github.com/go-sql-driver/mysql"
 	"github.com/jmoiron/sqlx"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/quckapp/workspace-service/internal/api"
 	"github.com/quckapp/workspace-service/internal/config"
 	"github.com/quckapp/workspace-service/internal/db"
+	"github.com/quckapp/workspace-service/internal/metrics"
 	"github.com/quckapp/workspace-service/internal/repository"
 	"github.com/quckapp/workspace-service/internal/service"
 	"github.com/sirupsen/logrus"
@@ -46,7 +51,6 @@ func main() {
 	if err != nil {
 		logger.WithError(err).Fatal("Failed to connect to MySQL")
 	}
-	defer mysqlDB.Close()
 	logger.Info("Connected to MySQL database")
 
 	// Run database migrations
@@ -60,7 +64,6 @@ func main() {
 		logger.WithError(err).Warn("Failed to connect to Redis, continuing without cache")
 		redisClient = nil
 	} else {
-		defer redisClient.Close()
 		logger.Info("Connected to Redis")
 	}
 
@@ -72,7 +75,6 @@ func main() {
 			logger.WithError(err).Warn("Failed to connect to Kafka, continuing without events")
 			kafkaProducer = nil
 		} else {
-			defer kafkaProducer.Close()
 			logger.WithField("brokers", cfg.KafkaBrokers).Info("Connected to Kafka")
 		}
 	}
@@ -112,8 +114,39 @@ func main() {
 	billingRepo := repository.NewBillingRepository(mysqlDB)
 	securityRepo := repository.NewSecurityRepository(mysqlDB)
 	discoveryRepo := repository.NewDiscoveryRepository(mysqlDB)
+	apiKeyRepo := repository.NewAPIKeyRepository(mysqlDB)
+	joinRequestRepo := repository.NewJoinRequestRepository(mysqlDB)
+	outboxRepo := repository.NewOutboxRepository(mysqlDB)
 	logger.Info("Repositories initialized")
 
+	// Prometheus metrics
+	serviceMetrics := metrics.New(prometheus.NewRegistry())
+
+	// Integration credentials are encrypted at rest
+	credentialsKey := cfg.CredentialsKey
+	if credentialsKey == "" {
+		if cfg.Environment != "development" {
+			logger.Fatal("INTEGRATION_CREDENTIALS_KEY must be set")
+		}
+		logger.Warn("INTEGRATION_CREDENTIALS_KEY not set; deriving a development key from JWT_SECRET")
+		credentialsKey = service.DeriveCredentialKey(cfg.JWTSecret)
+	}
+	credentialCipher, err := service.NewCredentialCipher(credentialsKey, cfg.CredentialsPreviousKeys)
+	if err != nil {
+		logger.WithError(err).Fatal("Invalid integration credentials key")
+	}
+
+	auditSink := service.NewAuditSink(integrationRepo, credentialCipher, logger)
+	auditSink.Start()
+
+	// Deliver outbound webhooks from a bounded queue
+	webhookDispatcher := service.NewWebhookDispatcher(webhookRepo, serviceMetrics, cfg.WebhookWorkers, cfg.WebhookQueueSize, logger)
+	webhookDispatcher.Start()
+
+	// Relay events from the outbox to Kafka
+	outboxRelay := service.NewOutboxRelay(outboxRepo, kafkaProducer, serviceMetrics, logger)
+	outboxRelay.Start()
+
 	// Initialize service
 	workspaceService := service.NewWorkspaceService(
 		workspaceRepo,
@@ -146,18 +179,66 @@ func main() {
 		streakRepo,
 		onboardingRepo,
 		complianceRepo,
+		outboxRepo,
+		securityRepo,
+		discoveryRepo,
+		apiKeyRepo,
+		joinRequestRepo,
+		auditSink,
+		webhookDispatcher,
+		credentialCipher,
+		serviceMetrics,
+		service.CacheConfig{
+			WorkspaceTTL: cfg.CacheTTLWorkspace,
+			StatsTTL:     cfg.CacheTTLStats,
+			NegativeTTL:  cfg.CacheTTLNegative,
+		},
+		service.IconConfig{
+			AllowedHosts: cfg.IconAllowedHosts,
+			MaxBytes:     int64(cfg.IconMaxBytes),
+		},
+		cfg.AppBaseURL,
+		cfg.CanonicalizeGmailInvites,
 		redisClient,
 		kafkaProducer,
 		logger,
 	)
-	emojiService := service.NewEmojiService(emojiRepo, memberRepo, logger)
-	billingService := service.NewBillingService(billingRepo, memberRepo, logger)
-	securityService := service.NewSecurityService(securityRepo, memberRepo, logger)
-	discoveryService := service.NewDiscoveryService(discoveryRepo, workspaceRepo, memberRepo, logger)
+
+	// Keep channel and storage usage current from other services' events
+	var usageConsumers []*db.KafkaConsumer
+	if kafkaProducer != nil {
+		for _, topic := range []string{service.ChannelEventsTopic, service.StorageEventsTopic} {
+			usageConsumers = append(usageConsumers, db.NewKafkaConsumer(cfg.KafkaBrokers, cfg.KafkaGroupID, topic))
+		}
+	}
+	eventConsumer := service.NewEventConsumer(workspaceService, usageConsumers, serviceMetrics, logger)
+	eventConsumer.Start()
+
+	// Purge workspaces soft-deleted past the retention window
+	workspacePurger := service.NewWorkspacePurger(workspaceService, cfg.PurgeRetentionDays, cfg.PurgeDryRun, logger)
+	workspacePurger.Start()
+
+	// Publish daily announcement digests at each workspace's configured time
+	announcementDigester := service.NewAnnouncementDigester(workspaceService, logger)
+	announcementDigester.Start()
+
+	// Carry out scheduled actions, such as publishing scheduled announcements, once they fall due
+	scheduledActionRunner := service.NewScheduledActionRunner(workspaceService, logger)
+	scheduledActionRunner.Start()
+
+	// Periodically sync active integrations with their providers
+	integrationSync := service.NewIntegrationSyncScheduler(workspaceService, logger)
+	integrationSync.Start()
+
+	emojiService := service.NewEmojiService(emojiRepo, memberRepo, reactionRepo, redisClient, logger)
+	billingService := service.NewBillingService(billingRepo, memberRepo, redisClient, logger)
+	securityService := service.NewSecurityService(securityRepo, memberRepo, auditSink, logger)
+	discoveryService := service.NewDiscoveryService(discoveryRepo, workspaceRepo, memberRepo, redisClient, logger)
+	healthChecker := service.NewHealthChecker(mysqlDB, redisClient, kafkaProducer, logger)
 	logger.Info("Service layer initialized")
 
 	// Initialize router
-	router := api.NewRouter(workspaceService, emojiService, billingService, securityService, discoveryService, cfg, logger)
+	router := api.NewRouter(workspaceService, emojiService, billingService, securityService, discoveryService, healthChecker, serviceMetrics, cfg, logger)
 	logger.Info("HTTP router initialized")
 
 	// Create HTTP server
@@ -184,13 +265,26 @@ func main() {
 
 	logger.Info("Shutting down workspace service...")
 
-	// Graceful shutdown with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-
-	if err := srv.Shutdown(ctx); err != nil {
-		logger.WithError(err).Error("Server forced to shutdown")
+	// Stop taking requests first, then let everything that produces events
+	// or deliveries finish before closing the connections they depend on.
+	shutdown := service.NewShutdownCoordinator(logger)
+	shutdown.Add("http", srv.Shutdown)
+	shutdown.Add("event consumer", eventConsumer.Stop)
+	shutdown.Add("integration sync", integrationSync.Stop)
+	shutdown.Add("announcement digests", announcementDigester.Stop)
+	shutdown.Add("scheduled actions", scheduledActionRunner.Stop)
+	shutdown.Add("workspace purger", workspacePurger.Stop)
+	shutdown.Add("outbox relay", outboxRelay.Stop)
+	shutdown.Add("audit sink", auditSink.Stop)
+	shutdown.Add("webhooks", webhookDispatcher.Stop)
+	if kafkaProducer != nil {
+		shutdown.Add("kafka", func(ctx context.Context) error { return kafkaProducer.Close() })
+	}
+	if redisClient != nil {
+		shutdown.Add("redis", func(ctx context.Context) error { return redisClient.Close() })
 	}
+	shutdown.Add("mysql", func(ctx context.Context) error { return mysqlDB.Close() })
+	shutdown.Run(cfg.ShutdownDrainTimeout)
 
 	logger.Info("Workspace service stopped")
 }
@@ -229,6 +323,22 @@ func runMigrations(db *sqlx.DB) error {
 			INDEX idx_user_id (user_id),
 			FOREIGN KEY (workspace_id) REFERENCES workspaces(id) ON DELETE CASCADE
 		)`,
+		`CREATE TABLE IF NOT EXISTS workspace_event_outbox (
+			id CHAR(36) PRIMARY KEY,
+			topic VARCHAR(100) NOT NULL,
+			event_key VARCHAR(100) NOT NULL,
+			event_type VARCHAR(100) NOT NULL,
+			payload JSON NOT NULL,
+			attempts INT DEFAULT 0,
+			last_error VARCHAR(500),
+			claimed_by CHAR(36) NULL,
+			claimed_until TIMESTAMP(6) NULL,
+			dead_lettered_at TIMESTAMP(6) NULL,
+			created_at TIMESTAMP(6) DEFAULT CURRENT_TIMESTAMP(6),
+			published_at TIMESTAMP(6) NULL,
+			INDEX idx_pending (published_at, created_at),
+			INDEX idx_claimed_by (claimed_by)
+		)`,
 		`CREATE TABLE IF NOT EXISTS workspace_invites (
 			id CHAR(36) PRIMARY KEY,
 			workspace_id CHAR(36) NOT NULL,
@@ -238,6 +348,7 @@ func runMigrations(db *sqlx.DB) error {
 			invited_by CHAR(36) NOT NULL,
 			expires_at TIMESTAMP NOT NULL,
 			accepted_at TIMESTAMP NULL,
+			last_sent_at TIMESTAMP NULL,
 			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
 			INDEX idx_workspace_id (workspace_id),
 			INDEX idx_token (token),
@@ -254,12 +365,45 @@ func runMigrations(db *sqlx.DB) error {
 			created_by CHAR(36) NOT NULL,
 			expires_at TIMESTAMP NULL,
 			is_active BOOLEAN DEFAULT TRUE,
+			approval_required BOOLEAN DEFAULT FALSE,
 			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
 			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
 			INDEX idx_code (code),
 			INDEX idx_workspace_id (workspace_id),
 			FOREIGN KEY (workspace_id) REFERENCES workspaces(id) ON DELETE CASCADE
 		)`,
+		`CREATE TABLE IF NOT EXISTS workspace_settings_history (
+			id CHAR(36) PRIMARY KEY,
+			workspace_id CHAR(36) NOT NULL,
+			actor_id CHAR(36) NOT NULL,
+			changes JSON NOT NULL,
+			version INT NOT NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			INDEX idx_workspace_created (workspace_id, created_at),
+			FOREIGN KEY (workspace_id) REFERENCES workspaces(id) ON DELETE CASCADE
+		)`,
+		`CREATE TABLE IF NOT EXISTS workspace_departures (
+			workspace_id CHAR(36) NOT NULL,
+			user_id CHAR(36) NOT NULL,
+			left_at TIMESTAMP NOT NULL,
+			PRIMARY KEY (workspace_id, user_id),
+			FOREIGN KEY (workspace_id) REFERENCES workspaces(id) ON DELETE CASCADE
+		)`,
+		`CREATE TABLE IF NOT EXISTS workspace_join_requests (
+			id CHAR(36) PRIMARY KEY,
+			workspace_id CHAR(36) NOT NULL,
+			user_id CHAR(36) NOT NULL,
+			invite_code_id CHAR(36) NOT NULL,
+			role VARCHAR(20) NOT NULL DEFAULT 'member',
+			status VARCHAR(20) NOT NULL DEFAULT 'pending',
+			reviewed_by CHAR(36) NULL,
+			reviewed_at TIMESTAMP NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+			UNIQUE KEY uk_workspace_user_request (workspace_id, user_id),
+			INDEX idx_workspace_status (workspace_id, status),
+			FOREIGN KEY (workspace_id) REFERENCES workspaces(id) ON DELETE CASCADE
+		)`,
 		`CREATE TABLE IF NOT EXISTS workspace_activity_log (
 			id CHAR(36) PRIMARY KEY,
 			workspace_id CHAR(36) NOT NULL,
@@ -276,6 +420,14 @@ func runMigrations(db *sqlx.DB) error {
 			INDEX idx_created_at (created_at),
 			FOREIGN KEY (workspace_id) REFERENCES workspaces(id) ON DELETE CASCADE
 		)`,
+		`CREATE TABLE IF NOT EXISTS workspace_pinned_activity (
+			workspace_id CHAR(36) NOT NULL,
+			activity_id CHAR(36) NOT NULL,
+			pinned_by CHAR(36) NOT NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (workspace_id, activity_id),
+			FOREIGN KEY (activity_id) REFERENCES workspace_activity_log(id) ON DELETE CASCADE
+		)`,
 		`CREATE TABLE IF NOT EXISTS workspace_member_profiles (
 			id CHAR(36) PRIMARY KEY,
 			workspace_id CHAR(36) NOT NULL,
@@ -309,6 +461,18 @@ func runMigrations(db *sqlx.DB) error {
 			INDEX idx_workspace_id (workspace_id),
 			FOREIGN KEY (workspace_id) REFERENCES workspaces(id) ON DELETE CASCADE
 		)`,
+		`CREATE TABLE IF NOT EXISTS workspace_member_roles (
+			id CHAR(36) PRIMARY KEY,
+			workspace_id CHAR(36) NOT NULL,
+			user_id CHAR(36) NOT NULL,
+			role_id CHAR(36) NOT NULL,
+			assigned_by CHAR(36) NOT NULL,
+			assigned_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE KEY uk_member_role (workspace_id, user_id, role_id),
+			INDEX idx_workspace_user (workspace_id, user_id),
+			FOREIGN KEY (workspace_id) REFERENCES workspaces(id) ON DELETE CASCADE,
+			FOREIGN KEY (role_id) REFERENCES workspace_roles(id) ON DELETE CASCADE
+		)`,
 		`CREATE TABLE IF NOT EXISTS workspace_templates (
 			id CHAR(36) PRIMARY KEY,
 			name VARCHAR(100) NOT NULL,
@@ -324,6 +488,31 @@ func runMigrations(db *sqlx.DB) error {
 			INDEX idx_created_by (created_by),
 			INDEX idx_is_public (is_public)
 		)`,
+		`CREATE TABLE IF NOT EXISTS workspace_template_versions (
+			id CHAR(36) PRIMARY KEY,
+			template_id CHAR(36) NOT NULL,
+			version INT NOT NULL,
+			name VARCHAR(100) NOT NULL,
+			description TEXT,
+			default_roles JSON,
+			default_channels JSON,
+			default_settings JSON,
+			default_tags JSON,
+			default_labels JSON,
+			created_by CHAR(36) NOT NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE KEY uk_template_version (template_id, version),
+			FOREIGN KEY (template_id) REFERENCES workspace_templates(id) ON DELETE CASCADE
+		)`,
+		`CREATE TABLE IF NOT EXISTS workspace_template_shares (
+			template_id CHAR(36) NOT NULL,
+			user_id CHAR(36) NOT NULL,
+			shared_by CHAR(36) NOT NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (template_id, user_id),
+			INDEX idx_user (user_id),
+			FOREIGN KEY (template_id) REFERENCES workspace_templates(id) ON DELETE CASCADE
+		)`,
 		`CREATE TABLE IF NOT EXISTS workspace_member_preferences (
 			id CHAR(36) PRIMARY KEY,
 			workspace_id CHAR(36) NOT NULL,
@@ -389,7 +578,10 @@ func runMigrations(db *sqlx.DB) error {
 			priority VARCHAR(20) DEFAULT 'normal',
 			author_id CHAR(36) NOT NULL,
 			is_pinned BOOLEAN DEFAULT FALSE,
+			pin_position INT NULL,
 			expires_at TIMESTAMP NULL,
+			is_published BOOLEAN NOT NULL DEFAULT TRUE,
+			publish_at TIMESTAMP NULL,
 			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
 			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
 			INDEX idx_workspace_id (workspace_id),
@@ -399,6 +591,13 @@ func runMigrations(db *sqlx.DB) error {
 			INDEX idx_expires_at (expires_at),
 			FOREIGN KEY (workspace_id) REFERENCES workspaces(id) ON DELETE CASCADE
 		)`,
+		`CREATE TABLE IF NOT EXISTS workspace_announcement_digests (
+			workspace_id CHAR(36) NOT NULL,
+			send_date DATE NOT NULL,
+			sent_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (workspace_id, send_date),
+			FOREIGN KEY (workspace_id) REFERENCES workspaces(id) ON DELETE CASCADE
+		)`,
 		`CREATE TABLE IF NOT EXISTS workspace_webhooks (
 			id CHAR(36) PRIMARY KEY,
 			workspace_id CHAR(36) NOT NULL,
@@ -416,6 +615,20 @@ func runMigrations(db *sqlx.DB) error {
 			INDEX idx_is_active (is_active),
 			FOREIGN KEY (workspace_id) REFERENCES workspaces(id) ON DELETE CASCADE
 		)`,
+		`CREATE TABLE IF NOT EXISTS workspace_api_keys (
+			id CHAR(36) PRIMARY KEY,
+			workspace_id CHAR(36) NOT NULL,
+			name VARCHAR(100) NOT NULL,
+			key_prefix VARCHAR(16) NOT NULL,
+			key_hash CHAR(64) NOT NULL UNIQUE,
+			permissions JSON,
+			created_by CHAR(36) NOT NULL,
+			last_used_at TIMESTAMP NULL,
+			revoked_at TIMESTAMP NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			INDEX idx_workspace_id (workspace_id),
+			FOREIGN KEY (workspace_id) REFERENCES workspaces(id) ON DELETE CASCADE
+		)`,
 		`CREATE TABLE IF NOT EXISTS workspace_favorites (
 			id CHAR(36) PRIMARY KEY,
 			user_id CHAR(36) NOT NULL,
@@ -476,6 +689,16 @@ func runMigrations(db *sqlx.DB) error {
 			INDEX idx_workspace_id (workspace_id),
 			FOREIGN KEY (workspace_id) REFERENCES workspaces(id) ON DELETE CASCADE
 		)`,
+		`CREATE TABLE IF NOT EXISTS workspace_external_usage (
+			workspace_id CHAR(36) NOT NULL,
+			kind VARCHAR(20) NOT NULL,
+			entity_id VARCHAR(64) NOT NULL,
+			size_bytes BIGINT DEFAULT 0,
+			deleted BOOLEAN DEFAULT FALSE,
+			event_at TIMESTAMP(6) NOT NULL,
+			PRIMARY KEY (workspace_id, kind, entity_id),
+			FOREIGN KEY (workspace_id) REFERENCES workspaces(id) ON DELETE CASCADE
+		)`,
 		`CREATE TABLE IF NOT EXISTS workspace_pinned_items (
 			id CHAR(36) PRIMARY KEY,
 			workspace_id CHAR(36) NOT NULL,
@@ -684,6 +907,7 @@ func runMigrations(db *sqlx.DB) error {
 			title VARCHAR(200) NOT NULL,
 			description TEXT,
 			is_active BOOLEAN DEFAULT TRUE,
+			required_only BOOLEAN DEFAULT FALSE,
 			created_by CHAR(36) NOT NULL,
 			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
 			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
@@ -725,6 +949,7 @@ func runMigrations(db *sqlx.DB) error {
 			rules JSON,
 			severity VARCHAR(20) DEFAULT 'info',
 			is_enforced BOOLEAN DEFAULT FALSE,
+			reack_interval_days INT NULL,
 			created_by CHAR(36) NOT NULL,
 			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
 			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
@@ -916,6 +1141,13 @@ func runMigrations(db *sqlx.DB) error {
 			INDEX idx_created_at (created_at),
 			FOREIGN KEY (workspace_id) REFERENCES workspaces(id) ON DELETE CASCADE
 		)`,
+		`CREATE TABLE IF NOT EXISTS workspace_purge_log (
+			workspace_id CHAR(36) PRIMARY KEY,
+			slug VARCHAR(50) NOT NULL,
+			owner_id CHAR(36) NOT NULL,
+			deleted_at TIMESTAMP NULL,
+			purged_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)`,
 		`CREATE TABLE IF NOT EXISTS workspace_directory (
 			id CHAR(36) PRIMARY KEY,
 			workspace_id CHAR(36) NOT NULL,
@@ -949,6 +1181,47 @@ func runMigrations(db *sqlx.DB) error {
 			INDEX idx_score (score),
 			FOREIGN KEY (workspace_id) REFERENCES workspaces(id) ON DELETE CASCADE
 		)`,
+		`CREATE TABLE IF NOT EXISTS workspace_directory_reports (
+			id CHAR(36) PRIMARY KEY,
+			workspace_id CHAR(36) NOT NULL,
+			reporter_id CHAR(36) NOT NULL,
+			reason VARCHAR(50) NOT NULL,
+			details TEXT,
+			status VARCHAR(20) NOT NULL DEFAULT 'pending',
+			resolved_by CHAR(36),
+			resolution_note TEXT,
+			resolved_at TIMESTAMP NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			INDEX idx_workspace_status (workspace_id, status),
+			INDEX idx_status_created (status, created_at),
+			FOREIGN KEY (workspace_id) REFERENCES workspaces(id) ON DELETE CASCADE
+		)`,
+		`CREATE TABLE IF NOT EXISTS workspace_ownership_transfers (
+			id CHAR(36) PRIMARY KEY,
+			workspace_id CHAR(36) NOT NULL,
+			from_user_id CHAR(36) NOT NULL,
+			to_user_id CHAR(36) NOT NULL,
+			status VARCHAR(20) NOT NULL DEFAULT 'pending',
+			expires_at TIMESTAMP NOT NULL,
+			resolved_by CHAR(36),
+			resolved_at TIMESTAMP NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			INDEX idx_workspace_status (workspace_id, status),
+			FOREIGN KEY (workspace_id) REFERENCES workspaces(id) ON DELETE CASCADE
+		)`,
+		`CREATE TABLE IF NOT EXISTS workspace_feature_flag_history (
+			id CHAR(36) PRIMARY KEY,
+			workspace_id CHAR(36) NOT NULL,
+			flag_id CHAR(36) NOT NULL,
+			flag_key VARCHAR(100) NOT NULL,
+			action VARCHAR(30) NOT NULL,
+			enabled BOOLEAN NOT NULL,
+			rollout_percentage INT NOT NULL,
+			changed_by CHAR(36) NOT NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			INDEX idx_flag_created (flag_id, created_at),
+			FOREIGN KEY (workspace_id) REFERENCES workspaces(id) ON DELETE CASCADE
+		)`,
 	}
 
 	for _, migration := range migrations {
@@ -957,5 +1230,47 @@ func runMigrations(db *sqlx.DB) error {
 		}
 	}
 
+	// Columns added to tables after they were first created. MySQL has no
+	// ADD COLUMN IF NOT EXISTS, so duplicate column errors are ignored.
+	alterations := []string{
+		`ALTER TABLE onboarding_checklists ADD COLUMN required_only BOOLEAN DEFAULT FALSE`,
+		`ALTER TABLE workspace_announcements ADD COLUMN pin_position INT NULL`,
+		`ALTER TABLE compliance_policies ADD COLUMN reack_interval_days INT NULL`,
+		`UPDATE workspace_announcements SET priority = 'high' WHERE priority = 'important'`,
+		`ALTER TABLE workspaces ADD COLUMN version INT NOT NULL DEFAULT 1`,
+		`ALTER TABLE workspace_roles ADD COLUMN version INT NOT NULL DEFAULT 1`,
+		`ALTER TABLE workspace_announcements ADD COLUMN version INT NOT NULL DEFAULT 1`,
+		`ALTER TABLE workspace_member_profiles ADD INDEX idx_profile_search (workspace_id, display_name, title)`,
+		`ALTER TABLE workspace_invitation_history ADD INDEX idx_invitee (workspace_id, invitee_id)`,
+		`ALTER TABLE workspace_announcements ADD INDEX idx_created_at (created_at)`,
+		`ALTER TABLE workspace_announcements ADD INDEX idx_workspace_created (workspace_id, created_at)`,
+		`ALTER TABLE workspace_feature_flags ADD COLUMN rollout_percentage INT NOT NULL DEFAULT 100`,
+		`ALTER TABLE workspace_templates ADD COLUMN default_tags JSON`,
+		`ALTER TABLE workspace_templates ADD COLUMN default_labels JSON`,
+		`ALTER TABLE workspace_templates ADD COLUMN version INT NOT NULL DEFAULT 1`,
+		`ALTER TABLE workspace_invitation_history ADD INDEX idx_invitee_email (invitee_email)`,
+		`ALTER TABLE workspace_invite_codes ADD COLUMN approval_required BOOLEAN DEFAULT FALSE`,
+		`ALTER TABLE workspace_announcements ADD COLUMN is_published BOOLEAN NOT NULL DEFAULT TRUE`,
+		`ALTER TABLE workspace_announcements ADD COLUMN publish_at TIMESTAMP NULL`,
+		`ALTER TABLE workspace_invites ADD COLUMN last_sent_at TIMESTAMP NULL`,
+		`ALTER TABLE workspace_event_outbox ADD COLUMN claimed_by CHAR(36) NULL`,
+		`ALTER TABLE workspace_event_outbox ADD COLUMN claimed_until TIMESTAMP(6) NULL`,
+		`ALTER TABLE workspace_event_outbox ADD COLUMN dead_lettered_at TIMESTAMP(6) NULL`,
+		`ALTER TABLE workspace_event_outbox ADD INDEX idx_claimed_by (claimed_by)`,
+	}
+
+	for _, alteration := range alterations {
+		if _, err := db.Exec(alteration); err != nil && !isDuplicateColumn(err) {
+			return err
+		}
+	}
+
 	return nil
 }
+
+// isDuplicateColumn reports whether an alteration was already applied:
+// 1060 is a duplicate column, 1061 a duplicate index name.
+func isDuplicateColumn(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	return errors.As(err, &mysqlErr) && (mysqlErr.Number == 1060 || mysqlErr.Number == 1061)
+}
@@ -0,0 +1,76 @@
+package api
+
+import (
+	"encoding/csv"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func TestGetAnalyticsCSV(t *testing.T) {
+	h, mock := newTestHandler(t)
+	workspaceID, contributorID := uuid.New(), uuid.New()
+
+	// Growth, role and join-method lookups fail against the mock and are
+	// left empty, as GetAnalytics tolerates.
+	mock.ExpectQuery(`SELECT role FROM workspace_members`).
+		WillReturnRows(sqlmock.NewRows([]string{"role"}).AddRow("owner"))
+	mock.ExpectQuery(`SELECT actor_id as user_id, COUNT\(\*\) as actions FROM workspace_activity_log`).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "actions"}).AddRow(contributorID.String(), 42))
+	mock.ExpectQuery(`SELECT COUNT\(DISTINCT actor_id\)`).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(7))
+
+	r := gin.New()
+	r.GET("/workspaces/:id/analytics", func(c *gin.Context) {
+		c.Set("user_id", uuid.New().String())
+		h.GetAnalytics(c)
+	})
+	w := serve(r, http.MethodGet, "/workspaces/"+workspaceID.String()+"/analytics?format=csv")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d, want 200: %s", w.Code, w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/csv") {
+		t.Errorf("Content-Type = %q, want text/csv", ct)
+	}
+
+	reader := csv.NewReader(w.Body)
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		t.Fatalf("parse csv: %v", err)
+	}
+	want := [][]string{
+		{"active_members", "7"},
+		{"member_growth"},
+		{"date", "count"},
+		{"top_contributors"},
+		{"user_id", "actions"},
+		{contributorID.String(), "42"},
+	}
+	if len(records) != len(want) {
+		t.Fatalf("got %d records, want %d: %v", len(records), len(want), records)
+	}
+	for i := range want {
+		if strings.Join(records[i], ",") != strings.Join(want[i], ",") {
+			t.Errorf("record %d = %v, want %v", i, records[i], want[i])
+		}
+	}
+}
+
+func TestGetAnalyticsRejectsUnknownFormat(t *testing.T) {
+	h, _ := newTestHandler(t)
+	r := gin.New()
+	r.GET("/workspaces/:id/analytics", func(c *gin.Context) {
+		c.Set("user_id", uuid.New().String())
+		h.GetAnalytics(c)
+	})
+
+	w := serve(r, http.MethodGet, "/workspaces/"+uuid.New().String()+"/analytics?format=xml")
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("status %d, want 400", w.Code)
+	}
+}
@@ -0,0 +1,47 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func TestCreateAnnouncementRejectsUnknownPriority(t *testing.T) {
+	h, _ := newTestHandler(t)
+	r := gin.New()
+	r.POST("/workspaces/:id/announcements", func(c *gin.Context) {
+		c.Set("user_id", uuid.New().String())
+		h.CreateAnnouncement(c)
+	})
+
+	body := `{"title":"Heads up","content":"Maintenance tonight","priority":"critical"}`
+	req := httptest.NewRequest(http.MethodPost, "/workspaces/"+uuid.New().String()+"/announcements", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("status %d, want 400", w.Code)
+	}
+}
+
+func TestListAnnouncementsRejectsUnknownPriority(t *testing.T) {
+	h, _ := newTestHandler(t)
+	r := gin.New()
+	r.GET("/workspaces/:id/announcements", func(c *gin.Context) {
+		c.Set("user_id", uuid.New().String())
+		h.ListAnnouncements(c)
+	})
+
+	w := serve(r, http.MethodGet, "/workspaces/"+uuid.New().String()+"/announcements?priority=critical")
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("status %d, want 400", w.Code)
+	}
+	if !strings.Contains(w.Body.String(), "important") {
+		t.Errorf("error %s does not list every accepted priority", w.Body.String())
+	}
+}
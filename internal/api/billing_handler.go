@@ -188,6 +188,20 @@ func (h *BillingHandler) GetPlanFeatures(c *gin.Context) {
 	c.JSON(http.StatusOK, features)
 }
 
+func (h *BillingHandler) GetSeatUtilization(c *gin.Context) {
+	planType := c.Query("plan_type")
+	if planType == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "plan_type is required"})
+		return
+	}
+	report, err := h.service.GetPlanSeatUtilization(c.Request.Context(), planType)
+	if err != nil {
+		billingHandleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
 func billingHandleError(c *gin.Context, err error) {
 	switch err {
 	case service.ErrPlanNotFound:
@@ -202,6 +216,10 @@ func billingHandleError(c *gin.Context, err error) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot remove seats below current member count"})
 	case service.ErrAlreadyOnPlan:
 		c.JSON(http.StatusConflict, gin.H{"error": "Already on this plan"})
+	case service.ErrInvalidPlanType:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid plan type"})
+	case service.ErrWorkspaceNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "Workspace not found"})
 	default:
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
 	}
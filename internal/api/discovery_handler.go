@@ -61,7 +61,7 @@ func (h *DiscoveryHandler) SearchDirectory(c *gin.Context) {
 }
 
 func (h *DiscoveryHandler) GetCategories(c *gin.Context) {
-	categories, err := h.service.GetCategories(c.Request.Context())
+	categories, err := h.service.GetDirectoryCategories(c.Request.Context())
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get categories"})
 		return
@@ -94,6 +94,16 @@ func (h *DiscoveryHandler) DismissRecommendation(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Recommendation dismissed"})
 }
 
+func (h *DiscoveryHandler) DismissAllRecommendations(c *gin.Context) {
+	userID := getUserID(c)
+	dismissed, err := h.service.DismissAllRecommendations(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to dismiss recommendations"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Recommendations dismissed", "dismissed": dismissed})
+}
+
 func (h *DiscoveryHandler) GetTrending(c *gin.Context) {
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
 	trending, err := h.service.GetTrending(c.Request.Context(), limit)
@@ -104,6 +114,60 @@ func (h *DiscoveryHandler) GetTrending(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"trending": trending})
 }
 
+func (h *DiscoveryHandler) ReportWorkspace(c *gin.Context) {
+	userID := getUserID(c)
+	workspaceID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid workspace ID"})
+		return
+	}
+	var req models.ReportDirectoryWorkspaceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	report, err := h.service.ReportDirectoryWorkspace(c.Request.Context(), workspaceID, userID, &req)
+	if err != nil {
+		discoveryHandleError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, report)
+}
+
+func (h *DiscoveryHandler) ListReports(c *gin.Context) {
+	var params models.DirectoryReportListParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	reports, total, err := h.service.ListDirectoryReports(c.Request.Context(), &params)
+	if err != nil {
+		discoveryHandleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"reports": reports, "total": total, "page": params.Page, "per_page": params.PerPage})
+}
+
+func (h *DiscoveryHandler) ResolveReport(c *gin.Context) {
+	userID := getUserID(c)
+	reportID, err := uuid.Parse(c.Param("reportId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid report ID"})
+		return
+	}
+	var req models.ResolveDirectoryReportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	report, err := h.service.ResolveDirectoryReport(c.Request.Context(), reportID, userID, &req)
+	if err != nil {
+		discoveryHandleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
 func discoveryHandleError(c *gin.Context, err error) {
 	switch err {
 	case service.ErrNotMember:
@@ -114,6 +178,12 @@ func discoveryHandleError(c *gin.Context, err error) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Directory entry not found"})
 	case service.ErrWorkspaceNotFound:
 		c.JSON(http.StatusNotFound, gin.H{"error": "Workspace not found"})
+	case service.ErrAlreadyReported:
+		c.JSON(http.StatusConflict, gin.H{"error": "You already have a pending report for this workspace"})
+	case service.ErrReportNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "Report not found"})
+	case service.ErrReportAlreadyResolved:
+		c.JSON(http.StatusConflict, gin.H{"error": "Report already resolved"})
 	default:
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
 	}
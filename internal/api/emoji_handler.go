@@ -37,8 +37,9 @@ func (h *EmojiHandler) CreateEmoji(c *gin.Context) {
 }
 
 func (h *EmojiHandler) GetEmoji(c *gin.Context) {
+	workspaceID, _ := uuid.Parse(c.Param("id"))
 	emojiID, _ := uuid.Parse(c.Param("emojiId"))
-	emoji, err := h.service.GetEmoji(c.Request.Context(), emojiID)
+	emoji, err := h.service.GetEmoji(c.Request.Context(), workspaceID, emojiID)
 	if err != nil {
 		emojiHandleError(c, err)
 		return
@@ -145,6 +146,18 @@ func (h *EmojiHandler) GetEmojiStats(c *gin.Context) {
 	c.JSON(http.StatusOK, stats)
 }
 
+func (h *EmojiHandler) GetTopEmojis(c *gin.Context) {
+	userID := getUserID(c)
+	workspaceID, _ := uuid.Parse(c.Param("id"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	top, err := h.service.GetTopEmojis(c.Request.Context(), workspaceID, userID, limit)
+	if err != nil {
+		emojiHandleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"emojis": top})
+}
+
 // Packs
 func (h *EmojiHandler) CreatePack(c *gin.Context) {
 	userID := getUserID(c)
@@ -1,14 +1,21 @@
 package api
 
 import (
+	"archive/zip"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/quckapp/workspace-service/internal/models"
 	"github.com/quckapp/workspace-service/internal/service"
+	"github.com/quckapp/workspace-service/internal/version"
 	"github.com/sirupsen/logrus"
 )
 
@@ -132,6 +139,53 @@ func (h *WorkspaceHandler) GetWorkspaceSettings(c *gin.Context) {
 	c.JSON(http.StatusOK, settings)
 }
 
+func (h *WorkspaceHandler) GetSettingsHistory(c *gin.Context) {
+	userID := getUserID(c)
+	workspaceID, _ := uuid.Parse(c.Param("id"))
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	perPage, _ := strconv.Atoi(c.DefaultQuery("per_page", "20"))
+
+	changes, total, err := h.service.GetSettingsHistory(c.Request.Context(), workspaceID, userID, page, perPage)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"changes": changes, "total": total, "page": page, "per_page": perPage})
+}
+
+func (h *WorkspaceHandler) SetWorkspaceIcon(c *gin.Context) {
+	userID := getUserID(c)
+	workspaceID, _ := uuid.Parse(c.Param("id"))
+
+	var req models.SetWorkspaceIconRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	workspace, err := h.service.SetWorkspaceIcon(c.Request.Context(), workspaceID, userID, &req)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, workspace)
+}
+
+func (h *WorkspaceHandler) RemoveWorkspaceIcon(c *gin.Context) {
+	userID := getUserID(c)
+	workspaceID, _ := uuid.Parse(c.Param("id"))
+
+	workspace, err := h.service.RemoveWorkspaceIcon(c.Request.Context(), workspaceID, userID)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, workspace)
+}
+
 func (h *WorkspaceHandler) UpdateWorkspaceSettings(c *gin.Context) {
 	userID := getUserID(c)
 	workspaceID, _ := uuid.Parse(c.Param("id"))
@@ -142,7 +196,28 @@ func (h *WorkspaceHandler) UpdateWorkspaceSettings(c *gin.Context) {
 		return
 	}
 
-	result, err := h.service.UpdateWorkspaceSettings(c.Request.Context(), workspaceID, userID, settings)
+	strict := c.Query("strict") == "true"
+	result, err := h.service.UpdateWorkspaceSettings(c.Request.Context(), workspaceID, userID, settings, strict)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (h *WorkspaceHandler) MergeWorkspaceSettings(c *gin.Context) {
+	userID := getUserID(c)
+	workspaceID, _ := uuid.Parse(c.Param("id"))
+
+	var patch models.JSON
+	if err := c.ShouldBindJSON(&patch); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	strict := c.Query("strict") == "true"
+	result, err := h.service.MergeWorkspaceSettings(c.Request.Context(), workspaceID, userID, patch, strict)
 	if err != nil {
 		handleError(c, err)
 		return
@@ -183,7 +258,70 @@ func (h *WorkspaceHandler) TransferOwnership(c *gin.Context) {
 		return
 	}
 
-	if err := h.service.TransferOwnership(c.Request.Context(), workspaceID, userID, newOwnerID); err != nil {
+	transfer, err := h.service.InitiateOwnershipTransfer(c.Request.Context(), workspaceID, userID, newOwnerID)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, transfer)
+}
+
+// ForceTransferOwnership lets a service admin hand a workspace to another
+// member without waiting for them to accept.
+func (h *WorkspaceHandler) ForceTransferOwnership(c *gin.Context) {
+	adminID := getUserID(c)
+	workspaceID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid workspace ID"})
+		return
+	}
+
+	var req models.TransferOwnershipRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	newOwnerID, err := uuid.Parse(req.NewOwnerID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid new owner ID"})
+		return
+	}
+
+	if err := h.service.TransferOwnership(c.Request.Context(), workspaceID, adminID, newOwnerID); err != nil {
+		handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Ownership transferred"})
+}
+
+func (h *WorkspaceHandler) GetOwnershipTransfer(c *gin.Context) {
+	userID := getUserID(c)
+	workspaceID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid workspace ID"})
+		return
+	}
+
+	transfer, err := h.service.GetOwnershipTransfer(c.Request.Context(), workspaceID, userID)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, transfer)
+}
+
+func (h *WorkspaceHandler) AcceptOwnershipTransfer(c *gin.Context) {
+	userID := getUserID(c)
+	workspaceID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid workspace ID"})
+		return
+	}
+
+	if err := h.service.AcceptOwnershipTransfer(c.Request.Context(), workspaceID, userID); err != nil {
 		handleError(c, err)
 		return
 	}
@@ -191,6 +329,22 @@ func (h *WorkspaceHandler) TransferOwnership(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Ownership transferred"})
 }
 
+func (h *WorkspaceHandler) CancelOwnershipTransfer(c *gin.Context) {
+	userID := getUserID(c)
+	workspaceID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid workspace ID"})
+		return
+	}
+
+	if err := h.service.CancelOwnershipTransfer(c.Request.Context(), workspaceID, userID); err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Ownership transfer canceled"})
+}
+
 // ── Member Management ──
 
 func (h *WorkspaceHandler) GetMember(c *gin.Context) {
@@ -225,6 +379,23 @@ func (h *WorkspaceHandler) InviteMember(c *gin.Context) {
 	c.JSON(http.StatusCreated, invite)
 }
 
+func (h *WorkspaceHandler) GetMyInviteQuota(c *gin.Context) {
+	userID := getUserID(c)
+	workspaceID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid workspace ID"})
+		return
+	}
+
+	quota, err := h.service.GetMyInviteQuota(c.Request.Context(), workspaceID, userID)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, quota)
+}
+
 func (h *WorkspaceHandler) BulkInvite(c *gin.Context) {
 	userID := getUserID(c)
 	workspaceID, _ := uuid.Parse(c.Param("id"))
@@ -290,188 +461,488 @@ func (h *WorkspaceHandler) UpdateMemberRole(c *gin.Context) {
 }
 
 func (h *WorkspaceHandler) ListMembers(c *gin.Context) {
+	userID := getUserID(c)
 	workspaceID, _ := uuid.Parse(c.Param("id"))
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	perPage, _ := strconv.Atoi(c.DefaultQuery("per_page", "50"))
 
-	members, total, err := h.service.ListMembers(c.Request.Context(), workspaceID, page, perPage)
+	members, total, err := h.service.ListMembers(c.Request.Context(), workspaceID, userID, page, perPage)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list members"})
+		handleError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"members": members, "total": total})
 }
 
-// ── Invite Management ──
-
-func (h *WorkspaceHandler) ListInvites(c *gin.Context) {
+func (h *WorkspaceHandler) GetInactiveMembers(c *gin.Context) {
 	userID := getUserID(c)
-	workspaceID, _ := uuid.Parse(c.Param("id"))
-
-	invites, err := h.service.ListInvites(c.Request.Context(), workspaceID, userID)
+	workspaceID, err := uuid.Parse(c.Param("id"))
 	if err != nil {
-		handleError(c, err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid workspace ID"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"invites": invites})
-}
-
-func (h *WorkspaceHandler) RevokeInvite(c *gin.Context) {
-	userID := getUserID(c)
-	workspaceID, _ := uuid.Parse(c.Param("id"))
-	inviteID, _ := uuid.Parse(c.Param("inviteId"))
+	days, err := strconv.Atoi(c.DefaultQuery("days", "30"))
+	if err != nil || days < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "days must be a positive integer"})
+		return
+	}
+	since := time.Now().AddDate(0, 0, -days)
 
-	if err := h.service.RevokeInvite(c.Request.Context(), workspaceID, inviteID, userID); err != nil {
+	members, err := h.service.GetInactiveMembers(c.Request.Context(), workspaceID, userID, since)
+	if err != nil {
 		handleError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Invite revoked"})
+	c.JSON(http.StatusOK, gin.H{"members": members, "since": since})
 }
 
-// ── Invite Codes ──
-
-func (h *WorkspaceHandler) CreateInviteCode(c *gin.Context) {
+func (h *WorkspaceHandler) SuggestInactiveMembers(c *gin.Context) {
 	userID := getUserID(c)
-	workspaceID, _ := uuid.Parse(c.Param("id"))
+	workspaceID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid workspace ID"})
+		return
+	}
 
-	var req models.CreateInviteCodeRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	days, err := strconv.Atoi(c.DefaultQuery("inactive_days", "90"))
+	if err != nil || days < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "inactive_days must be a positive integer"})
 		return
 	}
 
-	code, err := h.service.CreateInviteCode(c.Request.Context(), workspaceID, userID, &req)
+	members, err := h.service.SuggestInactiveMembers(c.Request.Context(), workspaceID, userID, days)
 	if err != nil {
 		handleError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusCreated, code)
+	c.JSON(http.StatusOK, gin.H{"members": members, "inactive_days": days})
 }
 
-func (h *WorkspaceHandler) JoinByCode(c *gin.Context) {
+func (h *WorkspaceHandler) BulkRemoveMembers(c *gin.Context) {
 	userID := getUserID(c)
+	workspaceID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid workspace ID"})
+		return
+	}
 
-	var req models.JoinByCodeRequest
+	var req models.BulkRemoveMembersRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	workspace, err := h.service.JoinByCode(c.Request.Context(), req.InviteCode, userID)
+	result, err := h.service.BulkRemoveMembers(c.Request.Context(), workspaceID, userID, &req)
 	if err != nil {
 		handleError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, workspace)
+	c.JSON(http.StatusOK, result)
 }
 
-func (h *WorkspaceHandler) ListInviteCodes(c *gin.Context) {
+func (h *WorkspaceHandler) SearchMembers(c *gin.Context) {
 	userID := getUserID(c)
-	workspaceID, _ := uuid.Parse(c.Param("id"))
-
-	codes, err := h.service.ListInviteCodes(c.Request.Context(), workspaceID, userID)
+	workspaceID, err := uuid.Parse(c.Param("id"))
 	if err != nil {
-		handleError(c, err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid workspace ID"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"invite_codes": codes})
-}
-
-func (h *WorkspaceHandler) RevokeInviteCode(c *gin.Context) {
-	userID := getUserID(c)
-	workspaceID, _ := uuid.Parse(c.Param("id"))
-	codeID, _ := uuid.Parse(c.Param("codeId"))
+	query := c.Query("q")
+	if len(query) < 2 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "q must be at least 2 characters"})
+		return
+	}
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	perPage, _ := strconv.Atoi(c.DefaultQuery("per_page", "20"))
 
-	if err := h.service.RevokeInviteCode(c.Request.Context(), workspaceID, codeID, userID); err != nil {
+	members, total, err := h.service.SearchMembers(c.Request.Context(), workspaceID, userID, query, page, perPage)
+	if err != nil {
 		handleError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Invite code revoked"})
+	c.JSON(http.StatusOK, gin.H{"members": members, "total": total, "page": page, "per_page": perPage})
 }
 
-// ── Activity Log ──
+// ── Invite Management ──
 
-func (h *WorkspaceHandler) GetActivityLog(c *gin.Context) {
+func (h *WorkspaceHandler) ListInvites(c *gin.Context) {
 	userID := getUserID(c)
 	workspaceID, _ := uuid.Parse(c.Param("id"))
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	perPage, _ := strconv.Atoi(c.DefaultQuery("per_page", "50"))
 
-	result, err := h.service.GetActivityLog(c.Request.Context(), workspaceID, userID, page, perPage)
+	status := c.DefaultQuery("status", "pending")
+	switch status {
+	case "pending", "accepted", "expired", "all":
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "status must be pending, accepted, expired or all"})
+		return
+	}
+
+	invites, err := h.service.ListInvites(c.Request.Context(), workspaceID, userID, status)
 	if err != nil {
 		handleError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, result)
+	c.JSON(http.StatusOK, gin.H{"invites": invites, "status": status})
 }
 
-func (h *WorkspaceHandler) GetActivityLogByActor(c *gin.Context) {
+func (h *WorkspaceHandler) RevokeInvite(c *gin.Context) {
 	userID := getUserID(c)
 	workspaceID, _ := uuid.Parse(c.Param("id"))
-	actorID, _ := uuid.Parse(c.Param("actorId"))
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	perPage, _ := strconv.Atoi(c.DefaultQuery("per_page", "50"))
+	inviteID, _ := uuid.Parse(c.Param("inviteId"))
 
-	result, err := h.service.GetActivityLogByActor(c.Request.Context(), workspaceID, actorID, userID, page, perPage)
-	if err != nil {
+	if err := h.service.RevokeInvite(c.Request.Context(), workspaceID, inviteID, userID); err != nil {
 		handleError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, result)
+	c.JSON(http.StatusOK, gin.H{"message": "Invite revoked"})
 }
 
-// ── Member Profiles ──
-
-func (h *WorkspaceHandler) GetMemberProfile(c *gin.Context) {
-	workspaceID, _ := uuid.Parse(c.Param("id"))
-	memberUserID, _ := uuid.Parse(c.Param("userId"))
-
-	profile, err := h.service.GetMemberProfile(c.Request.Context(), workspaceID, memberUserID)
+func (h *WorkspaceHandler) ResendInvite(c *gin.Context) {
+	userID := getUserID(c)
+	workspaceID, err := uuid.Parse(c.Param("id"))
 	if err != nil {
-		handleError(c, err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid workspace ID"})
 		return
 	}
-	if profile == nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Profile not found"})
+	inviteID, err := uuid.Parse(c.Param("inviteId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid invite ID"})
 		return
 	}
 
-	c.JSON(http.StatusOK, profile)
+	invite, err := h.service.ResendInvite(c.Request.Context(), workspaceID, inviteID, userID)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, invite)
 }
 
-func (h *WorkspaceHandler) UpdateMemberProfile(c *gin.Context) {
+// ── Invite Codes ──
+
+func (h *WorkspaceHandler) CreateInviteCode(c *gin.Context) {
 	userID := getUserID(c)
 	workspaceID, _ := uuid.Parse(c.Param("id"))
 
-	var req models.UpdateMemberProfileRequest
+	var req models.CreateInviteCodeRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	profile, err := h.service.UpdateMemberProfile(c.Request.Context(), workspaceID, userID, &req)
+	code, err := h.service.CreateInviteCode(c.Request.Context(), workspaceID, userID, &req)
 	if err != nil {
 		handleError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, profile)
+	c.JSON(http.StatusCreated, code)
 }
 
-func (h *WorkspaceHandler) SetOnlineStatus(c *gin.Context) {
+func (h *WorkspaceHandler) CreateMagicJoinLink(c *gin.Context) {
 	userID := getUserID(c)
-	workspaceID, _ := uuid.Parse(c.Param("id"))
-
-	var req struct {
+	workspaceID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid workspace ID"})
+		return
+	}
+
+	var req models.CreateMagicJoinLinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	link, err := h.service.CreateMagicJoinLink(c.Request.Context(), workspaceID, req.Role, userID)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, link)
+}
+
+func (h *WorkspaceHandler) JoinByCode(c *gin.Context) {
+	userID := getUserID(c)
+
+	var req models.JoinByCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	workspace, joinRequest, err := h.service.JoinByCode(c.Request.Context(), req.InviteCode, userID)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	if joinRequest != nil {
+		c.JSON(http.StatusAccepted, gin.H{"join_request": joinRequest})
+		return
+	}
+
+	c.JSON(http.StatusOK, workspace)
+}
+
+func (h *WorkspaceHandler) ListJoinRequests(c *gin.Context) {
+	userID := getUserID(c)
+	workspaceID, _ := uuid.Parse(c.Param("id"))
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	perPage, _ := strconv.Atoi(c.DefaultQuery("per_page", "20"))
+
+	status := c.DefaultQuery("status", models.JoinRequestPending)
+	switch status {
+	case models.JoinRequestPending, models.JoinRequestApproved, models.JoinRequestRejected:
+	case "all":
+		status = ""
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "status must be one of pending, approved, rejected, all"})
+		return
+	}
+
+	requests, total, err := h.service.ListJoinRequests(c.Request.Context(), workspaceID, userID, status, page, perPage)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"join_requests": requests, "total": total, "page": page, "per_page": perPage})
+}
+
+func (h *WorkspaceHandler) ApproveJoinRequest(c *gin.Context) {
+	userID := getUserID(c)
+	workspaceID, _ := uuid.Parse(c.Param("id"))
+	requestID, err := uuid.Parse(c.Param("requestId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid join request ID"})
+		return
+	}
+
+	member, err := h.service.ApproveJoinRequest(c.Request.Context(), workspaceID, requestID, userID)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, member)
+}
+
+func (h *WorkspaceHandler) RejectJoinRequest(c *gin.Context) {
+	userID := getUserID(c)
+	workspaceID, _ := uuid.Parse(c.Param("id"))
+	requestID, err := uuid.Parse(c.Param("requestId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid join request ID"})
+		return
+	}
+
+	if err := h.service.RejectJoinRequest(c.Request.Context(), workspaceID, requestID, userID); err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Join request rejected"})
+}
+
+func (h *WorkspaceHandler) GetInviteCodePreview(c *gin.Context) {
+	preview, err := h.service.GetInviteCodePreview(c.Request.Context(), c.Param("code"))
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, preview)
+}
+
+func (h *WorkspaceHandler) ListInviteCodes(c *gin.Context) {
+	userID := getUserID(c)
+	workspaceID, _ := uuid.Parse(c.Param("id"))
+
+	codes, err := h.service.ListInviteCodes(c.Request.Context(), workspaceID, userID)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"invite_codes": codes})
+}
+
+func (h *WorkspaceHandler) RevokeInviteCode(c *gin.Context) {
+	userID := getUserID(c)
+	workspaceID, _ := uuid.Parse(c.Param("id"))
+	codeID, _ := uuid.Parse(c.Param("codeId"))
+
+	if err := h.service.RevokeInviteCode(c.Request.Context(), workspaceID, codeID, userID); err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Invite code revoked"})
+}
+
+// ── Activity Log ──
+
+func (h *WorkspaceHandler) GetActivityLog(c *gin.Context) {
+	userID := getUserID(c)
+	workspaceID, _ := uuid.Parse(c.Param("id"))
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	perPage, _ := strconv.Atoi(c.DefaultQuery("per_page", "50"))
+
+	pinnedFirst := c.Query("pinned_first") == "true"
+
+	result, err := h.service.GetActivityLog(c.Request.Context(), workspaceID, userID, page, perPage, pinnedFirst)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (h *WorkspaceHandler) PinActivity(c *gin.Context) {
+	userID := getUserID(c)
+	workspaceID, _ := uuid.Parse(c.Param("id"))
+	activityID, err := uuid.Parse(c.Param("activityId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid activity ID"})
+		return
+	}
+
+	pin, err := h.service.PinActivity(c.Request.Context(), workspaceID, userID, activityID)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, pin)
+}
+
+func (h *WorkspaceHandler) UnpinActivity(c *gin.Context) {
+	userID := getUserID(c)
+	workspaceID, _ := uuid.Parse(c.Param("id"))
+	activityID, err := uuid.Parse(c.Param("activityId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid activity ID"})
+		return
+	}
+
+	if err := h.service.UnpinActivity(c.Request.Context(), workspaceID, userID, activityID); err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Activity unpinned"})
+}
+
+func (h *WorkspaceHandler) GetActivityLogByActor(c *gin.Context) {
+	userID := getUserID(c)
+	workspaceID, _ := uuid.Parse(c.Param("id"))
+	actorID, _ := uuid.Parse(c.Param("actorId"))
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	perPage, _ := strconv.Atoi(c.DefaultQuery("per_page", "50"))
+
+	result, err := h.service.GetActivityLogByActor(c.Request.Context(), workspaceID, actorID, userID, page, perPage)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// ── Member Profiles ──
+
+func (h *WorkspaceHandler) GetMemberProfile(c *gin.Context) {
+	workspaceID, _ := uuid.Parse(c.Param("id"))
+	memberUserID, _ := uuid.Parse(c.Param("userId"))
+
+	profile, err := h.service.GetMemberProfile(c.Request.Context(), workspaceID, memberUserID)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	if profile == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Profile not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, profile)
+}
+
+func (h *WorkspaceHandler) UpdateMemberProfile(c *gin.Context) {
+	userID := getUserID(c)
+	workspaceID, _ := uuid.Parse(c.Param("id"))
+
+	var req models.UpdateMemberProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	profile, err := h.service.UpdateMemberProfile(c.Request.Context(), workspaceID, userID, &req)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, profile)
+}
+
+func (h *WorkspaceHandler) SetRequiredProfileFields(c *gin.Context) {
+	userID := getUserID(c)
+	workspaceID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid workspace ID"})
+		return
+	}
+
+	var req models.SetRequiredProfileFieldsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	settings, err := h.service.SetRequiredProfileFields(c.Request.Context(), workspaceID, userID, req.Fields)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, settings)
+}
+
+func (h *WorkspaceHandler) GetProfileCompleteness(c *gin.Context) {
+	userID := getUserID(c)
+	workspaceID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid workspace ID"})
+		return
+	}
+
+	completeness, err := h.service.GetProfileCompleteness(c.Request.Context(), workspaceID, userID)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, completeness)
+}
+
+func (h *WorkspaceHandler) SetOnlineStatus(c *gin.Context) {
+	userID := getUserID(c)
+	workspaceID, _ := uuid.Parse(c.Param("id"))
+
+	var req struct {
 		IsOnline bool `json:"is_online"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -511,150 +982,537 @@ func (h *WorkspaceHandler) CreateRole(c *gin.Context) {
 func (h *WorkspaceHandler) ListRoles(c *gin.Context) {
 	workspaceID, _ := uuid.Parse(c.Param("id"))
 
-	roles, err := h.service.ListRoles(c.Request.Context(), workspaceID)
+	roles, err := h.service.ListRoles(c.Request.Context(), workspaceID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list roles"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"roles": roles})
+}
+
+func (h *WorkspaceHandler) UpdateRole(c *gin.Context) {
+	userID := getUserID(c)
+	workspaceID, _ := uuid.Parse(c.Param("id"))
+	roleID, _ := uuid.Parse(c.Param("roleId"))
+
+	var req models.UpdateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	role, err := h.service.UpdateRole(c.Request.Context(), workspaceID, roleID, userID, &req)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, role)
+}
+
+func (h *WorkspaceHandler) DeleteRole(c *gin.Context) {
+	userID := getUserID(c)
+	workspaceID, _ := uuid.Parse(c.Param("id"))
+	roleID, _ := uuid.Parse(c.Param("roleId"))
+
+	if err := h.service.DeleteRole(c.Request.Context(), workspaceID, roleID, userID); err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Role deleted"})
+}
+
+func (h *WorkspaceHandler) GetMyPermissions(c *gin.Context) {
+	userID := getUserID(c)
+	workspaceID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid workspace ID"})
+		return
+	}
+
+	permissions, err := h.service.GetMyPermissions(c.Request.Context(), workspaceID, userID)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, permissions)
+}
+
+func (h *WorkspaceHandler) GetPermissionCatalog(c *gin.Context) {
+	userID := getUserID(c)
+	workspaceID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid workspace ID"})
+		return
+	}
+
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "csv" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be json or csv"})
+		return
+	}
+
+	catalog, err := h.service.GetPermissionCatalog(c.Request.Context(), workspaceID, userID)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	if format == "json" {
+		c.JSON(http.StatusOK, gin.H{"permissions": catalog, "total": len(catalog)})
+		return
+	}
+
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", `attachment; filename="permission-catalog.csv"`)
+	c.Status(http.StatusOK)
+
+	w := csv.NewWriter(c.Writer)
+	w.Write([]string{"key", "category", "description"})
+	for _, p := range catalog {
+		w.Write([]string{p.Key, p.Category, p.Description})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		h.logger.WithError(err).Warn("Failed to write permission catalog")
+	}
+}
+
+func (h *WorkspaceHandler) SetMaxRolesPerMember(c *gin.Context) {
+	userID := getUserID(c)
+	workspaceID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid workspace ID"})
+		return
+	}
+
+	var req models.SetMaxRolesPerMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	settings, err := h.service.SetMaxRolesPerMember(c.Request.Context(), workspaceID, userID, req.MaxRoles)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, settings)
+}
+
+func (h *WorkspaceHandler) AssignMemberRole(c *gin.Context) {
+	userID := getUserID(c)
+	workspaceID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid workspace ID"})
+		return
+	}
+	memberUserID, err := uuid.Parse(c.Param("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+	roleID, err := uuid.Parse(c.Param("roleId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid role ID"})
+		return
+	}
+
+	assignment, err := h.service.AssignMemberRole(c.Request.Context(), workspaceID, memberUserID, roleID, userID)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, assignment)
+}
+
+func (h *WorkspaceHandler) UnassignMemberRole(c *gin.Context) {
+	userID := getUserID(c)
+	workspaceID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid workspace ID"})
+		return
+	}
+	memberUserID, err := uuid.Parse(c.Param("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+	roleID, err := uuid.Parse(c.Param("roleId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid role ID"})
+		return
+	}
+
+	if err := h.service.UnassignMemberRole(c.Request.Context(), workspaceID, memberUserID, roleID, userID); err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Role unassigned"})
+}
+
+func (h *WorkspaceHandler) ListMemberRoles(c *gin.Context) {
+	userID := getUserID(c)
+	workspaceID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid workspace ID"})
+		return
+	}
+	memberUserID, err := uuid.Parse(c.Param("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	roles, err := h.service.ListMemberRoles(c.Request.Context(), workspaceID, memberUserID, userID)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, roles)
+}
+
+// ── Workspace Search ──
+
+func (h *WorkspaceHandler) SearchWorkspaces(c *gin.Context) {
+	query := c.Query("q")
+	if query == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Query parameter 'q' is required"})
+		return
+	}
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	perPage, _ := strconv.Atoi(c.DefaultQuery("per_page", "20"))
+
+	workspaces, total, err := h.service.SearchWorkspaces(c.Request.Context(), query, page, perPage)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Search failed"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"workspaces": workspaces, "total": total, "page": page, "per_page": perPage})
+}
+
+// ── Version ──
+
+func (h *WorkspaceHandler) GetVersion(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"version":      version.Version,
+		"commit":       version.Commit,
+		"build_time":   version.BuildTime,
+		"api_version":  version.APIVersion,
+		"capabilities": h.service.Capabilities(),
+	})
+}
+
+func (h *WorkspaceHandler) GetOutboxStats(c *gin.Context) {
+	stats, err := h.service.OutboxStats(c.Request.Context())
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
+
+// ── Workspace Analytics ──
+
+func (h *WorkspaceHandler) GetAnalytics(c *gin.Context) {
+	userID := getUserID(c)
+	workspaceID, _ := uuid.Parse(c.Param("id"))
+	days, _ := strconv.Atoi(c.DefaultQuery("days", "30"))
+
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "csv" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be json or csv"})
+		return
+	}
+
+	analytics, err := h.service.GetAnalytics(c.Request.Context(), workspaceID, userID, days)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	if format == "json" {
+		c.JSON(http.StatusOK, analytics)
+		return
+	}
+
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="workspace-%s-analytics.csv"`, workspaceID))
+	c.Status(http.StatusOK)
+
+	// Sections are separated by a blank line and start with their own header row
+	w := csv.NewWriter(c.Writer)
+	w.Write([]string{"active_members", strconv.Itoa(analytics.ActiveMembers)})
+	w.Write(nil)
+	w.Write([]string{"member_growth"})
+	w.Write([]string{"date", "count"})
+	for _, day := range analytics.MemberGrowth {
+		w.Write([]string{day.Date, strconv.Itoa(day.Count)})
+	}
+	w.Write(nil)
+	w.Write([]string{"top_contributors"})
+	w.Write([]string{"user_id", "actions"})
+	for _, contributor := range analytics.TopContributors {
+		w.Write([]string{contributor.UserID.String(), strconv.Itoa(contributor.Actions)})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		h.logger.WithError(err).Warn("Failed to write analytics export")
+	}
+}
+
+func (h *WorkspaceHandler) GetChurnStats(c *gin.Context) {
+	userID := getUserID(c)
+	workspaceID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid workspace ID"})
+		return
+	}
+	days, _ := strconv.Atoi(c.DefaultQuery("days", "30"))
+
+	stats, err := h.service.GetChurnStats(c.Request.Context(), workspaceID, userID, days)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
+
+func (h *WorkspaceHandler) GetMemberActivityHeatmap(c *gin.Context) {
+	userID := getUserID(c)
+	workspaceID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid workspace ID"})
+		return
+	}
+	targetID, err := uuid.Parse(c.Param("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+	days, _ := strconv.Atoi(c.DefaultQuery("days", "90"))
+
+	heatmap, err := h.service.GetMemberActivityHeatmap(c.Request.Context(), workspaceID, targetID, userID, days)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, heatmap)
+}
+
+func (h *WorkspaceHandler) GetInviteFunnel(c *gin.Context) {
+	userID := getUserID(c)
+	workspaceID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid workspace ID"})
+		return
+	}
+	days, _ := strconv.Atoi(c.DefaultQuery("days", "30"))
+
+	funnel, err := h.service.GetInviteFunnel(c.Request.Context(), workspaceID, userID, days)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, funnel)
+}
+
+// ── Workspace Templates ──
+
+func (h *WorkspaceHandler) CreateTemplateFromWorkspace(c *gin.Context) {
+	userID := getUserID(c)
+	workspaceID, _ := uuid.Parse(c.Param("id"))
+
+	var req models.CreateTemplateFromWorkspaceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	template, err := h.service.CreateTemplateFromWorkspace(c.Request.Context(), workspaceID, userID, &req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list roles"})
+		handleError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"roles": roles})
+	c.JSON(http.StatusCreated, template)
 }
 
-func (h *WorkspaceHandler) UpdateRole(c *gin.Context) {
+func (h *WorkspaceHandler) CreateWorkspaceFromTemplate(c *gin.Context) {
 	userID := getUserID(c)
-	workspaceID, _ := uuid.Parse(c.Param("id"))
-	roleID, _ := uuid.Parse(c.Param("roleId"))
 
-	var req models.UpdateRoleRequest
+	var req models.CreateWorkspaceFromTemplateRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	role, err := h.service.UpdateRole(c.Request.Context(), workspaceID, roleID, userID, &req)
+	workspace, err := h.service.CreateWorkspaceFromTemplate(c.Request.Context(), userID, &req)
 	if err != nil {
 		handleError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, role)
+	c.JSON(http.StatusCreated, workspace)
 }
 
-func (h *WorkspaceHandler) DeleteRole(c *gin.Context) {
-	userID := getUserID(c)
-	workspaceID, _ := uuid.Parse(c.Param("id"))
-	roleID, _ := uuid.Parse(c.Param("roleId"))
+func (h *WorkspaceHandler) ListTemplates(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	perPage, _ := strconv.Atoi(c.DefaultQuery("per_page", "20"))
 
-	if err := h.service.DeleteRole(c.Request.Context(), workspaceID, roleID, userID); err != nil {
-		handleError(c, err)
+	filter := models.TemplateFilter{
+		Query: c.Query("q"),
+		Sort:  c.DefaultQuery("sort", "use_count"),
+		Order: c.DefaultQuery("order", "desc"),
+	}
+	switch filter.Sort {
+	case "use_count", "created_at":
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "sort must be one of use_count, created_at"})
+		return
+	}
+	switch filter.Order {
+	case "asc", "desc":
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "order must be one of asc, desc"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Role deleted"})
-}
+	templates, total, err := h.service.ListTemplates(c.Request.Context(), getUserID(c), filter, page, perPage)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list templates"})
+		return
+	}
 
-// ── Workspace Search ──
+	c.JSON(http.StatusOK, gin.H{"templates": templates, "total": total, "page": page, "per_page": perPage})
+}
 
-func (h *WorkspaceHandler) SearchWorkspaces(c *gin.Context) {
-	query := c.Query("q")
-	if query == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Query parameter 'q' is required"})
+func (h *WorkspaceHandler) GetTemplate(c *gin.Context) {
+	templateID, err := uuid.Parse(c.Param("templateId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid template ID"})
 		return
 	}
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	perPage, _ := strconv.Atoi(c.DefaultQuery("per_page", "20"))
 
-	workspaces, total, err := h.service.SearchWorkspaces(c.Request.Context(), query, page, perPage)
+	template, err := h.service.GetTemplate(c.Request.Context(), templateID, getUserID(c))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Search failed"})
+		handleError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"workspaces": workspaces, "total": total, "page": page, "per_page": perPage})
+	c.JSON(http.StatusOK, template)
 }
 
-// ── Workspace Analytics ──
-
-func (h *WorkspaceHandler) GetAnalytics(c *gin.Context) {
+func (h *WorkspaceHandler) PreviewTemplate(c *gin.Context) {
 	userID := getUserID(c)
-	workspaceID, _ := uuid.Parse(c.Param("id"))
-	days, _ := strconv.Atoi(c.DefaultQuery("days", "30"))
+	templateID, err := uuid.Parse(c.Param("templateId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid template ID"})
+		return
+	}
 
-	analytics, err := h.service.GetAnalytics(c.Request.Context(), workspaceID, userID, days)
+	preview, err := h.service.PreviewTemplate(c.Request.Context(), templateID, userID)
 	if err != nil {
 		handleError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, analytics)
+	c.JSON(http.StatusOK, preview)
 }
 
-// ── Workspace Templates ──
-
-func (h *WorkspaceHandler) CreateTemplateFromWorkspace(c *gin.Context) {
+func (h *WorkspaceHandler) ListTemplateVersions(c *gin.Context) {
 	userID := getUserID(c)
-	workspaceID, _ := uuid.Parse(c.Param("id"))
-
-	var req models.CreateTemplateFromWorkspaceRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	templateID, err := uuid.Parse(c.Param("templateId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid template ID"})
 		return
 	}
 
-	template, err := h.service.CreateTemplateFromWorkspace(c.Request.Context(), workspaceID, userID, &req)
+	versions, err := h.service.ListTemplateVersions(c.Request.Context(), templateID, userID)
 	if err != nil {
 		handleError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusCreated, template)
+	c.JSON(http.StatusOK, gin.H{"versions": versions})
 }
 
-func (h *WorkspaceHandler) CreateWorkspaceFromTemplate(c *gin.Context) {
+func (h *WorkspaceHandler) ShareTemplate(c *gin.Context) {
 	userID := getUserID(c)
+	templateID, err := uuid.Parse(c.Param("templateId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid template ID"})
+		return
+	}
 
-	var req models.CreateWorkspaceFromTemplateRequest
+	var req models.ShareTemplateRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	targetUserID, err := uuid.Parse(req.UserID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
 
-	workspace, err := h.service.CreateWorkspaceFromTemplate(c.Request.Context(), userID, &req)
+	share, err := h.service.ShareTemplate(c.Request.Context(), templateID, userID, targetUserID)
 	if err != nil {
 		handleError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusCreated, workspace)
+	c.JSON(http.StatusCreated, share)
 }
 
-func (h *WorkspaceHandler) ListTemplates(c *gin.Context) {
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	perPage, _ := strconv.Atoi(c.DefaultQuery("per_page", "20"))
-
-	templates, total, err := h.service.ListTemplates(c.Request.Context(), page, perPage)
+func (h *WorkspaceHandler) UnshareTemplate(c *gin.Context) {
+	userID := getUserID(c)
+	templateID, err := uuid.Parse(c.Param("templateId"))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list templates"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid template ID"})
+		return
+	}
+	targetUserID, err := uuid.Parse(c.Param("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"templates": templates, "total": total, "page": page, "per_page": perPage})
+	if err := h.service.UnshareTemplate(c.Request.Context(), templateID, userID, targetUserID); err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Template unshared"})
 }
 
-func (h *WorkspaceHandler) GetTemplate(c *gin.Context) {
+func (h *WorkspaceHandler) ListTemplateShares(c *gin.Context) {
+	userID := getUserID(c)
 	templateID, err := uuid.Parse(c.Param("templateId"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid template ID"})
 		return
 	}
 
-	template, err := h.service.GetTemplate(c.Request.Context(), templateID)
+	shares, err := h.service.ListTemplateShares(c.Request.Context(), templateID, userID)
 	if err != nil {
 		handleError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, template)
+	c.JSON(http.StatusOK, gin.H{"shares": shares})
 }
 
 func (h *WorkspaceHandler) UpdateTemplate(c *gin.Context) {
@@ -734,6 +1592,44 @@ func (h *WorkspaceHandler) ResetPreferences(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Preferences reset to defaults"})
 }
 
+func (h *WorkspaceHandler) ShouldNotify(c *gin.Context) {
+	userID := getUserID(c)
+	workspaceID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid workspace ID"})
+		return
+	}
+	targetID, err := uuid.Parse(c.Param("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	eventType := c.Query("event_type")
+	if eventType == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "event_type is required"})
+		return
+	}
+	channel := c.DefaultQuery("channel", "in_app")
+	if channel != "in_app" && channel != "email" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "channel must be in_app or email"})
+		return
+	}
+
+	notify, reason, err := h.service.ShouldNotify(c.Request.Context(), workspaceID, userID, targetID, eventType, channel)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, models.NotificationDecision{
+		UserID:    targetID,
+		EventType: eventType,
+		Channel:   channel,
+		Notify:    notify,
+		Reason:    reason,
+	})
+}
+
 // ── Workspace Tags ──
 
 func (h *WorkspaceHandler) CreateTag(c *gin.Context) {
@@ -908,7 +1804,15 @@ func (h *WorkspaceHandler) ListAnnouncements(c *gin.Context) {
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	perPage, _ := strconv.Atoi(c.DefaultQuery("per_page", "20"))
 
-	announcements, total, err := h.service.ListAnnouncements(c.Request.Context(), workspaceID, userID, page, perPage)
+	filter := models.AnnouncementFilter{Query: c.Query("q"), Priority: c.Query("priority")}
+	switch filter.Priority {
+	case "", "low", "normal", "high", "urgent", "important":
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "priority must be one of low, normal, high, urgent, important (same as high)"})
+		return
+	}
+
+	announcements, total, err := h.service.ListAnnouncements(c.Request.Context(), workspaceID, userID, filter, page, perPage)
 	if err != nil {
 		handleError(c, err)
 		return
@@ -956,6 +1860,52 @@ func (h *WorkspaceHandler) PinAnnouncement(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Pin status updated"})
 }
 
+func (h *WorkspaceHandler) SetDefaultAnnouncementPriority(c *gin.Context) {
+	userID := getUserID(c)
+	workspaceID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid workspace ID"})
+		return
+	}
+
+	var req models.SetDefaultAnnouncementPriorityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	settings, err := h.service.SetDefaultAnnouncementPriority(c.Request.Context(), workspaceID, userID, req.Priority)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, settings)
+}
+
+func (h *WorkspaceHandler) ReorderAnnouncementPins(c *gin.Context) {
+	userID := getUserID(c)
+	workspaceID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid workspace ID"})
+		return
+	}
+
+	var req models.ReorderAnnouncementPinsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	announcements, err := h.service.ReorderAnnouncementPins(c.Request.Context(), workspaceID, userID, &req)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, announcements)
+}
+
 func (h *WorkspaceHandler) DeleteAnnouncement(c *gin.Context) {
 	userID := getUserID(c)
 	workspaceID, _ := uuid.Parse(c.Param("id"))
@@ -975,78 +1925,173 @@ func (h *WorkspaceHandler) CreateWebhook(c *gin.Context) {
 	userID := getUserID(c)
 	workspaceID, _ := uuid.Parse(c.Param("id"))
 
-	var req models.CreateWebhookRequest
+	var req models.CreateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	webhook, err := h.service.CreateWebhook(c.Request.Context(), workspaceID, userID, &req)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, webhook)
+}
+
+func (h *WorkspaceHandler) ListWebhooks(c *gin.Context) {
+	userID := getUserID(c)
+	workspaceID, _ := uuid.Parse(c.Param("id"))
+
+	webhooks, err := h.service.ListWebhooks(c.Request.Context(), workspaceID, userID)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"webhooks": webhooks})
+}
+
+func (h *WorkspaceHandler) GetWebhookHealth(c *gin.Context) {
+	userID := getUserID(c)
+	workspaceID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid workspace ID"})
+		return
+	}
+
+	report, err := h.service.GetWebhookHealth(c.Request.Context(), workspaceID, userID)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+func (h *WorkspaceHandler) UpdateWebhook(c *gin.Context) {
+	userID := getUserID(c)
+	workspaceID, _ := uuid.Parse(c.Param("id"))
+	webhookID, _ := uuid.Parse(c.Param("webhookId"))
+
+	var req models.UpdateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	webhook, err := h.service.UpdateWebhook(c.Request.Context(), workspaceID, webhookID, userID, &req)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, webhook)
+}
+
+func (h *WorkspaceHandler) DeleteWebhook(c *gin.Context) {
+	userID := getUserID(c)
+	workspaceID, _ := uuid.Parse(c.Param("id"))
+	webhookID, _ := uuid.Parse(c.Param("webhookId"))
+
+	if err := h.service.DeleteWebhook(c.Request.Context(), workspaceID, webhookID, userID); err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Webhook deleted"})
+}
+
+func (h *WorkspaceHandler) CreateAPIKey(c *gin.Context) {
+	userID := getUserID(c)
+	workspaceID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid workspace ID"})
+		return
+	}
+
+	var req models.CreateAPIKeyRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	webhook, err := h.service.CreateWebhook(c.Request.Context(), workspaceID, userID, &req)
+	resp, err := h.service.CreateAPIKey(c.Request.Context(), workspaceID, userID, &req)
 	if err != nil {
 		handleError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusCreated, webhook)
+	c.JSON(http.StatusCreated, resp)
 }
 
-func (h *WorkspaceHandler) ListWebhooks(c *gin.Context) {
+func (h *WorkspaceHandler) ListAPIKeys(c *gin.Context) {
 	userID := getUserID(c)
-	workspaceID, _ := uuid.Parse(c.Param("id"))
+	workspaceID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid workspace ID"})
+		return
+	}
 
-	webhooks, err := h.service.ListWebhooks(c.Request.Context(), workspaceID, userID)
+	keys, err := h.service.ListAPIKeys(c.Request.Context(), workspaceID, userID)
 	if err != nil {
 		handleError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"webhooks": webhooks})
+	c.JSON(http.StatusOK, gin.H{"api_keys": keys})
 }
 
-func (h *WorkspaceHandler) UpdateWebhook(c *gin.Context) {
+func (h *WorkspaceHandler) RevokeAPIKey(c *gin.Context) {
 	userID := getUserID(c)
-	workspaceID, _ := uuid.Parse(c.Param("id"))
-	webhookID, _ := uuid.Parse(c.Param("webhookId"))
-
-	var req models.UpdateWebhookRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	workspaceID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid workspace ID"})
 		return
 	}
-
-	webhook, err := h.service.UpdateWebhook(c.Request.Context(), workspaceID, webhookID, userID, &req)
+	keyID, err := uuid.Parse(c.Param("keyId"))
 	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid API key ID"})
+		return
+	}
+
+	if err := h.service.RevokeAPIKey(c.Request.Context(), workspaceID, keyID, userID); err != nil {
 		handleError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, webhook)
+	c.JSON(http.StatusOK, gin.H{"message": "API key revoked"})
 }
 
-func (h *WorkspaceHandler) DeleteWebhook(c *gin.Context) {
+func (h *WorkspaceHandler) TestWebhook(c *gin.Context) {
 	userID := getUserID(c)
 	workspaceID, _ := uuid.Parse(c.Param("id"))
 	webhookID, _ := uuid.Parse(c.Param("webhookId"))
 
-	if err := h.service.DeleteWebhook(c.Request.Context(), workspaceID, webhookID, userID); err != nil {
+	if err := h.service.TestWebhook(c.Request.Context(), workspaceID, webhookID, userID); err != nil {
 		handleError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Webhook deleted"})
+	c.JSON(http.StatusOK, gin.H{"message": "Webhook test successful"})
 }
 
-func (h *WorkspaceHandler) TestWebhook(c *gin.Context) {
+func (h *WorkspaceHandler) ListWebhookEvents(c *gin.Context) {
 	userID := getUserID(c)
-	workspaceID, _ := uuid.Parse(c.Param("id"))
-	webhookID, _ := uuid.Parse(c.Param("webhookId"))
+	workspaceID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid workspace ID"})
+		return
+	}
 
-	if err := h.service.TestWebhook(c.Request.Context(), workspaceID, webhookID, userID); err != nil {
+	events, err := h.service.GetWebhookEventCatalog(c.Request.Context(), workspaceID, userID)
+	if err != nil {
 		handleError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Webhook test successful"})
+	c.JSON(http.StatusOK, gin.H{"events": events, "total": len(events)})
 }
 
 // ── Workspace Favorites ──
@@ -1137,6 +2182,77 @@ func (h *WorkspaceHandler) ExportAuditLog(c *gin.Context) {
 	c.JSON(http.StatusOK, result)
 }
 
+func (h *WorkspaceHandler) ExportWorkspace(c *gin.Context) {
+	userID := getUserID(c)
+	workspaceID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid workspace ID"})
+		return
+	}
+
+	compress := c.DefaultQuery("compress", "none")
+	if compress != "none" && compress != "zip" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "compress must be none or zip"})
+		return
+	}
+
+	export, err := h.service.ExportWorkspace(c.Request.Context(), workspaceID, userID, c.Query("confirm") == "true")
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	// The document is streamed, so errors past this point can only be logged
+	filename := fmt.Sprintf("workspace-%s-export.json", workspaceID)
+	if compress == "zip" {
+		c.Header("Content-Type", "application/zip")
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.zip"`, filename))
+		c.Status(http.StatusOK)
+
+		zw := zip.NewWriter(c.Writer)
+		f, err := zw.Create(filename)
+		if err == nil {
+			err = export.Stream(c.Request.Context(), f)
+		}
+		if closeErr := zw.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			h.logger.WithError(err).WithField("workspace_id", workspaceID).Warn("Workspace export aborted")
+		}
+		return
+	}
+
+	c.Header("Content-Type", "application/json")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	c.Status(http.StatusOK)
+	if err := export.Stream(c.Request.Context(), c.Writer); err != nil {
+		h.logger.WithError(err).WithField("workspace_id", workspaceID).Warn("Workspace export aborted")
+	}
+}
+
+func (h *WorkspaceHandler) ExportMemberData(c *gin.Context) {
+	userID := getUserID(c)
+	workspaceID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid workspace ID"})
+		return
+	}
+	targetID, err := uuid.Parse(c.Param("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	export, err := h.service.ExportMemberData(c.Request.Context(), workspaceID, targetID, userID)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, export)
+}
+
 // ── Member Notes ──
 
 func (h *WorkspaceHandler) CreateMemberNote(c *gin.Context) {
@@ -1698,6 +2814,29 @@ func (h *WorkspaceHandler) GetCustomFieldValues(c *gin.Context) {
 	c.JSON(http.StatusOK, results)
 }
 
+func (h *WorkspaceHandler) GetMemberFacets(c *gin.Context) {
+	userID := getUserID(c)
+	workspaceID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid workspace ID"})
+		return
+	}
+
+	fieldID, err := uuid.Parse(c.Query("field_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid field ID"})
+		return
+	}
+
+	result, err := h.service.GetMemberFacets(c.Request.Context(), workspaceID, userID, fieldID)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
 // ── Reactions ──
 
 func (h *WorkspaceHandler) AddReaction(c *gin.Context) {
@@ -1794,6 +2933,23 @@ func (h *WorkspaceHandler) GetReactionSummary(c *gin.Context) {
 	c.JSON(http.StatusOK, summaries)
 }
 
+func (h *WorkspaceHandler) GetMyReactionsByEmoji(c *gin.Context) {
+	userID := getUserID(c)
+	workspaceID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid workspace ID"})
+		return
+	}
+
+	groups, err := h.service.GetMyReactionsByEmoji(c.Request.Context(), workspaceID, userID)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"reactions": groups})
+}
+
 // ── Bookmarks ──
 
 func (h *WorkspaceHandler) CreateBookmark(c *gin.Context) {
@@ -1864,6 +3020,75 @@ func (h *WorkspaceHandler) ListBookmarkFolders(c *gin.Context) {
 	c.JSON(http.StatusOK, folders)
 }
 
+func (h *WorkspaceHandler) RemapBookmarkFolders(c *gin.Context) {
+	userID := getUserID(c)
+	workspaceID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid workspace ID"})
+		return
+	}
+
+	var req models.RemapBookmarkFoldersRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.service.RemapBookmarkFolders(c.Request.Context(), workspaceID, userID, req.Mapping)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (h *WorkspaceHandler) RenameBookmarkFolder(c *gin.Context) {
+	userID := getUserID(c)
+	workspaceID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid workspace ID"})
+		return
+	}
+
+	var req models.RenameBookmarkFolderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.service.RenameBookmarkFolder(c.Request.Context(), workspaceID, userID, req.OldName, req.NewName)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (h *WorkspaceHandler) DeleteBookmarkFolder(c *gin.Context) {
+	userID := getUserID(c)
+	workspaceID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid workspace ID"})
+		return
+	}
+
+	var req models.DeleteBookmarkFolderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.service.DeleteBookmarkFolder(c.Request.Context(), workspaceID, userID, req.Name, req.MoveToUnfiled)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
 func (h *WorkspaceHandler) UpdateBookmark(c *gin.Context) {
 	userID := getUserID(c)
 	workspaceID, err := uuid.Parse(c.Param("id"))
@@ -2017,18 +3242,87 @@ func (h *WorkspaceHandler) GetAccessLogStats(c *gin.Context) {
 
 	days, _ := strconv.Atoi(c.DefaultQuery("days", "30"))
 
-	stats, err := h.service.GetAccessLogStats(c.Request.Context(), workspaceID, userID, days)
+	stats, err := h.service.GetAccessLogStats(c.Request.Context(), workspaceID, userID, days)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
+
+// ── Feature Flags ──
+
+func (h *WorkspaceHandler) CreateFeatureFlag(c *gin.Context) {
+	userID := getUserID(c)
+	workspaceID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid workspace ID"})
+		return
+	}
+
+	var req models.CreateFeatureFlagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	flag, err := h.service.CreateFeatureFlag(c.Request.Context(), workspaceID, userID, &req)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, flag)
+}
+
+func (h *WorkspaceHandler) ListFeatureFlags(c *gin.Context) {
+	userID := getUserID(c)
+	workspaceID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid workspace ID"})
+		return
+	}
+
+	flags, err := h.service.ListFeatureFlags(c.Request.Context(), workspaceID, userID)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, flags)
+}
+
+func (h *WorkspaceHandler) UpdateFeatureFlag(c *gin.Context) {
+	userID := getUserID(c)
+	workspaceID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid workspace ID"})
+		return
+	}
+
+	flagID, err := uuid.Parse(c.Param("flagId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid flag ID"})
+		return
+	}
+
+	var req models.UpdateFeatureFlagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	flag, err := h.service.UpdateFeatureFlag(c.Request.Context(), workspaceID, userID, flagID, &req)
 	if err != nil {
 		handleError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, stats)
+	c.JSON(http.StatusOK, flag)
 }
 
-// ── Feature Flags ──
-
-func (h *WorkspaceHandler) CreateFeatureFlag(c *gin.Context) {
+func (h *WorkspaceHandler) DeleteFeatureFlag(c *gin.Context) {
 	userID := getUserID(c)
 	workspaceID, err := uuid.Parse(c.Param("id"))
 	if err != nil {
@@ -2036,39 +3330,47 @@ func (h *WorkspaceHandler) CreateFeatureFlag(c *gin.Context) {
 		return
 	}
 
-	var req models.CreateFeatureFlagRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	flagID, err := uuid.Parse(c.Param("flagId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid flag ID"})
 		return
 	}
 
-	flag, err := h.service.CreateFeatureFlag(c.Request.Context(), workspaceID, userID, &req)
-	if err != nil {
+	if err := h.service.DeleteFeatureFlag(c.Request.Context(), workspaceID, userID, flagID); err != nil {
 		handleError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusCreated, flag)
+	c.JSON(http.StatusOK, gin.H{"message": "Feature flag deleted"})
 }
 
-func (h *WorkspaceHandler) ListFeatureFlags(c *gin.Context) {
+func (h *WorkspaceHandler) GetFeatureFlagHistory(c *gin.Context) {
 	userID := getUserID(c)
 	workspaceID, err := uuid.Parse(c.Param("id"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid workspace ID"})
 		return
 	}
+	// The route shares the :key wildcard with the check route; here it
+	// holds the flag ID.
+	flagID, err := uuid.Parse(c.Param("key"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid feature flag ID"})
+		return
+	}
 
-	flags, err := h.service.ListFeatureFlags(c.Request.Context(), workspaceID, userID)
+	history, err := h.service.GetFeatureFlagHistory(c.Request.Context(), workspaceID, userID, flagID)
 	if err != nil {
 		handleError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, flags)
+	c.JSON(http.StatusOK, gin.H{"history": history})
 }
 
-func (h *WorkspaceHandler) UpdateFeatureFlag(c *gin.Context) {
+// CheckFeatureFlagsQuery evaluates the flags named in ?keys=a,b,c in one
+// request. It is the recommended way for clients to load flags at startup.
+func (h *WorkspaceHandler) CheckFeatureFlagsQuery(c *gin.Context) {
 	userID := getUserID(c)
 	workspaceID, err := uuid.Parse(c.Param("id"))
 	if err != nil {
@@ -2076,28 +3378,29 @@ func (h *WorkspaceHandler) UpdateFeatureFlag(c *gin.Context) {
 		return
 	}
 
-	flagID, err := uuid.Parse(c.Param("flagId"))
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid flag ID"})
-		return
+	var keys []string
+	seen := map[string]bool{}
+	for _, key := range strings.Split(c.Query("keys"), ",") {
+		if key = strings.TrimSpace(key); key != "" && !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
 	}
-
-	var req models.UpdateFeatureFlagRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if len(keys) == 0 || len(keys) > 100 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "keys must list between 1 and 100 flag keys"})
 		return
 	}
 
-	flag, err := h.service.UpdateFeatureFlag(c.Request.Context(), workspaceID, userID, flagID, &req)
+	flags, err := h.service.CheckFeatureFlags(c.Request.Context(), workspaceID, userID, keys)
 	if err != nil {
 		handleError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, flag)
+	c.JSON(http.StatusOK, gin.H{"flags": flags})
 }
 
-func (h *WorkspaceHandler) DeleteFeatureFlag(c *gin.Context) {
+func (h *WorkspaceHandler) CheckFeatureFlags(c *gin.Context) {
 	userID := getUserID(c)
 	workspaceID, err := uuid.Parse(c.Param("id"))
 	if err != nil {
@@ -2105,18 +3408,19 @@ func (h *WorkspaceHandler) DeleteFeatureFlag(c *gin.Context) {
 		return
 	}
 
-	flagID, err := uuid.Parse(c.Param("flagId"))
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid flag ID"})
+	var req models.BatchCheckFeatureFlagsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	if err := h.service.DeleteFeatureFlag(c.Request.Context(), workspaceID, userID, flagID); err != nil {
+	flags, err := h.service.CheckFeatureFlags(c.Request.Context(), workspaceID, userID, req.Keys)
+	if err != nil {
 		handleError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Feature flag deleted"})
+	c.JSON(http.StatusOK, gin.H{"flags": flags})
 }
 
 func (h *WorkspaceHandler) CheckFeatureFlag(c *gin.Context) {
@@ -2236,6 +3540,84 @@ func (h *WorkspaceHandler) UpdateIntegration(c *gin.Context) {
 	c.JSON(http.StatusOK, integration)
 }
 
+func (h *WorkspaceHandler) RotateIntegrationCredentials(c *gin.Context) {
+	userID := getUserID(c)
+	workspaceID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid workspace ID"})
+		return
+	}
+	integrationID, err := uuid.Parse(c.Param("integrationId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid integration ID"})
+		return
+	}
+
+	var req models.RotateIntegrationCredentialsRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	integration, err := h.service.RotateIntegrationCredentials(c.Request.Context(), workspaceID, userID, integrationID, req.Credentials)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, integration)
+}
+
+func (h *WorkspaceHandler) ListSupportedProviders(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"providers": h.service.ListSupportedProviders()})
+}
+
+func (h *WorkspaceHandler) SyncIntegration(c *gin.Context) {
+	userID := getUserID(c)
+	workspaceID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid workspace ID"})
+		return
+	}
+	integrationID, err := uuid.Parse(c.Param("integrationId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid integration ID"})
+		return
+	}
+
+	status, err := h.service.SyncIntegration(c.Request.Context(), workspaceID, userID, integrationID)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
+
+func (h *WorkspaceHandler) GetIntegrationSyncStatus(c *gin.Context) {
+	userID := getUserID(c)
+	workspaceID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid workspace ID"})
+		return
+	}
+	integrationID, err := uuid.Parse(c.Param("integrationId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid integration ID"})
+		return
+	}
+
+	status, err := h.service.GetIntegrationSyncStatus(c.Request.Context(), workspaceID, userID, integrationID)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
+
 func (h *WorkspaceHandler) DeleteIntegration(c *gin.Context) {
 	userID := getUserID(c)
 	workspaceID, err := uuid.Parse(c.Param("id"))
@@ -2258,6 +3640,31 @@ func (h *WorkspaceHandler) DeleteIntegration(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Integration deleted"})
 }
 
+// ── Audit Sink ──
+
+func (h *WorkspaceHandler) ConfigureAuditSink(c *gin.Context) {
+	userID := getUserID(c)
+	workspaceID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid workspace ID"})
+		return
+	}
+
+	var req models.ConfigureAuditSinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	sink, err := h.service.ConfigureAuditSink(c.Request.Context(), workspaceID, userID, &req)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, sink)
+}
+
 // ── Labels ──
 
 func (h *WorkspaceHandler) CreateLabel(c *gin.Context) {
@@ -2550,6 +3957,64 @@ func (h *WorkspaceHandler) AddOnboardingStep(c *gin.Context) {
 	c.JSON(http.StatusCreated, step)
 }
 
+func (h *WorkspaceHandler) UpdateOnboardingStep(c *gin.Context) {
+	userID := getUserID(c)
+	workspaceID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid workspace ID"})
+		return
+	}
+
+	stepID, err := uuid.Parse(c.Param("stepId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid step ID"})
+		return
+	}
+
+	var req models.UpdateStepRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	step, err := h.service.UpdateOnboardingStep(c.Request.Context(), workspaceID, userID, stepID, &req)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, step)
+}
+
+func (h *WorkspaceHandler) ReorderOnboardingSteps(c *gin.Context) {
+	userID := getUserID(c)
+	workspaceID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid workspace ID"})
+		return
+	}
+
+	checklistID, err := uuid.Parse(c.Param("checklistId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid checklist ID"})
+		return
+	}
+
+	var req models.ReorderStepsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	steps, err := h.service.ReorderOnboardingSteps(c.Request.Context(), workspaceID, userID, checklistID, &req)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, steps)
+}
+
 func (h *WorkspaceHandler) DeleteOnboardingStep(c *gin.Context) {
 	userID := getUserID(c)
 	workspaceID, err := uuid.Parse(c.Param("id"))
@@ -2611,6 +4076,40 @@ func (h *WorkspaceHandler) GetMyOnboardingStatus(c *gin.Context) {
 	c.JSON(http.StatusOK, statuses)
 }
 
+func (h *WorkspaceHandler) GetMyWorkspaceReadiness(c *gin.Context) {
+	userID := getUserID(c)
+	workspaceID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid workspace ID"})
+		return
+	}
+
+	readiness, err := h.service.GetMyWorkspaceReadiness(c.Request.Context(), workspaceID, userID)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, readiness)
+}
+
+func (h *WorkspaceHandler) GetWorkspaceOnboardingStats(c *gin.Context) {
+	userID := getUserID(c)
+	workspaceID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid workspace ID"})
+		return
+	}
+
+	stats, err := h.service.GetWorkspaceOnboardingStats(c.Request.Context(), workspaceID, userID)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
+
 // ── Compliance Policies ──
 
 func (h *WorkspaceHandler) CreatePolicy(c *gin.Context) {
@@ -2749,15 +4248,100 @@ func (h *WorkspaceHandler) GetPolicyComplianceStatus(c *gin.Context) {
 	c.JSON(http.StatusOK, status)
 }
 
+func (h *WorkspaceHandler) ExportPolicyAcknowledgements(c *gin.Context) {
+	userID := getUserID(c)
+	workspaceID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid workspace ID"})
+		return
+	}
+
+	policyID, err := uuid.Parse(c.Param("policyId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid policy ID"})
+		return
+	}
+
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "csv" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be json or csv"})
+		return
+	}
+
+	export, err := h.service.ExportPolicyAcknowledgements(c.Request.Context(), workspaceID, policyID, userID)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	if format == "json" {
+		c.JSON(http.StatusOK, export)
+		return
+	}
+
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="policy-%s-acknowledgements.csv"`, policyID))
+	c.Status(http.StatusOK)
+
+	w := csv.NewWriter(c.Writer)
+	w.Write([]string{"user_id", "role", "acknowledged", "expired", "acked_at"})
+	for _, row := range export.Members {
+		ackedAt := ""
+		if row.AckedAt != nil {
+			ackedAt = row.AckedAt.UTC().Format(time.RFC3339)
+		}
+		w.Write([]string{row.UserID.String(), row.Role, strconv.FormatBool(row.Acknowledged), strconv.FormatBool(row.Expired), ackedAt})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		h.logger.WithError(err).Warn("Failed to write policy acknowledgement export")
+	}
+}
+
 // ── Helpers ──
 
+// getUserID returns the authenticated user. Every route that calls it sits
+// behind middleware.Auth, which rejects requests without a valid user ID.
 func getUserID(c *gin.Context) uuid.UUID {
-	userIDStr, _ := c.Get("user_id")
-	userID, _ := uuid.Parse(userIDStr.(string))
-	return userID
+	return uuid.MustParse(c.GetString("user_id"))
 }
 
 func handleError(c *gin.Context, err error) {
+	if errors.Is(err, context.DeadlineExceeded) {
+		c.JSON(http.StatusGatewayTimeout, gin.H{"error": "Request timed out", "code": "request_timeout"})
+		return
+	}
+	var settingsErr *service.SettingsValidationError
+	if errors.As(err, &settingsErr) {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Invalid settings", "code": "invalid_settings", "fields": settingsErr.Fields})
+		return
+	}
+	var integrationErr *service.IntegrationValidationError
+	if errors.As(err, &integrationErr) {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Invalid integration", "code": "invalid_integration", "fields": integrationErr.Fields})
+		return
+	}
+	var templateErr *service.TemplateValidationError
+	if errors.As(err, &templateErr) {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Invalid template", "code": "invalid_template", "fields": templateErr.Fields})
+		return
+	}
+	var inviteCapErr *service.InviteCapError
+	if errors.As(err, &inviteCapErr) {
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": "Daily invite cap reached for this workspace", "code": "daily_invite_cap_reached", "used": inviteCapErr.Used, "limit": inviteCapErr.Limit, "resets_at": inviteCapErr.ResetsAt})
+		return
+	}
+	var cooldownErr *service.RejoinCooldownError
+	if errors.As(err, &cooldownErr) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You left this workspace recently and cannot rejoin yet", "code": "rejoin_cooldown", "rejoin_after": cooldownErr.Until})
+		return
+	}
+	var bookmarkLimitErr *service.BookmarkLimitError
+	if errors.As(err, &bookmarkLimitErr) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Bookmark limit reached", "code": "bookmark_limit_reached", "count": bookmarkLimitErr.Count, "limit": bookmarkLimitErr.Limit})
+		return
+	}
+
 	switch err {
 	case service.ErrWorkspaceNotFound:
 		c.JSON(http.StatusNotFound, gin.H{"error": "Workspace not found"})
@@ -2785,6 +4369,14 @@ func handleError(c *gin.Context, err error) {
 		c.JSON(http.StatusConflict, gin.H{"error": "Cannot delete default role"})
 	case service.ErrTemplateNotFound:
 		c.JSON(http.StatusNotFound, gin.H{"error": "Template not found"})
+	case service.ErrReactionNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "Reaction not found"})
+	case service.ErrTemplateVersionNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "Template version not found"})
+	case service.ErrTemplateShareNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "Template is not shared with this user"})
+	case service.ErrInvalidShareTarget:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot share a template with its creator"})
 	case service.ErrTagNotFound:
 		c.JSON(http.StatusNotFound, gin.H{"error": "Tag not found"})
 	case service.ErrTagNameExists:
@@ -2801,8 +4393,20 @@ func handleError(c *gin.Context, err error) {
 		c.JSON(http.StatusForbidden, gin.H{"error": "Cannot mute workspace owner"})
 	case service.ErrAnnouncementNotFound:
 		c.JSON(http.StatusNotFound, gin.H{"error": "Announcement not found"})
+	case service.ErrExpiresBeforePublish:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Announcement would expire before it is published"})
+	case service.ErrInvalidIconURL:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Icon URL must be https on an allowed host"})
+	case service.ErrInvalidIcon:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Icon must be a PNG, JPEG, GIF or WebP image within the size limit"})
 	case service.ErrWebhookNotFound:
 		c.JSON(http.StatusNotFound, gin.H{"error": "Webhook not found"})
+	case service.ErrAPIKeyNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "API key not found"})
+	case service.ErrJoinRequestNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "Join request not found or already decided"})
+	case service.ErrJoinRequestPending:
+		c.JSON(http.StatusConflict, gin.H{"error": "A join request is already pending"})
 	case service.ErrAlreadyFavorited:
 		c.JSON(http.StatusConflict, gin.H{"error": "Workspace already favorited"})
 	case service.ErrNotFavorited:
@@ -2837,14 +4441,34 @@ func handleError(c *gin.Context, err error) {
 		c.JSON(http.StatusConflict, gin.H{"error": "Reaction already exists"})
 	case service.ErrBookmarkNotFound:
 		c.JSON(http.StatusNotFound, gin.H{"error": "Bookmark not found"})
+	case service.ErrBookmarkFolderNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "Bookmark folder not found"})
+	case service.ErrInvalidFolderName:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Folder name must not be empty"})
 	case service.ErrBookmarkLimitReached:
 		c.JSON(http.StatusForbidden, gin.H{"error": "Bookmark limit reached"})
+	case service.ErrPinLimitReached:
+		c.JSON(http.StatusForbidden, gin.H{"error": "Pinned item limit reached for this workspace; unpin something first", "code": "pin_limit_reached"})
+	case service.ErrActivityNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "Activity not found"})
+	case service.ErrActivityNotPinned:
+		c.JSON(http.StatusNotFound, gin.H{"error": "Activity is not pinned"})
+	case service.ErrActivityPinLimitReached:
+		c.JSON(http.StatusForbidden, gin.H{"error": "Pinned activity limit reached for this workspace; unpin something first", "code": "activity_pin_limit_reached"})
+	case service.ErrInvalidPinType:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Pinned item type must be one of message, link, file, note", "code": "invalid_pin_type"})
 	case service.ErrFeatureFlagNotFound:
 		c.JSON(http.StatusNotFound, gin.H{"error": "Feature flag not found"})
 	case service.ErrFeatureFlagKeyExists:
 		c.JSON(http.StatusConflict, gin.H{"error": "Feature flag key already exists"})
+	case service.ErrSyncNotSupported:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "This integration provider does not support sync", "code": "sync_not_supported"})
+	case service.ErrNoCredentials:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Integration has no credentials to rotate"})
 	case service.ErrIntegrationNotFound:
 		c.JSON(http.StatusNotFound, gin.H{"error": "Integration not found"})
+	case service.ErrAuditSinkInsecureURL:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Audit sink URL must use https"})
 	case service.ErrLabelNotFound:
 		c.JSON(http.StatusNotFound, gin.H{"error": "Label not found"})
 	case service.ErrLabelNameExists:
@@ -2855,6 +4479,32 @@ func handleError(c *gin.Context, err error) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Onboarding step not found"})
 	case service.ErrPolicyNotFound:
 		c.JSON(http.StatusNotFound, gin.H{"error": "Compliance policy not found"})
+	case service.ErrRoleAlreadyAssigned:
+		c.JSON(http.StatusConflict, gin.H{"error": "Role already assigned to this member"})
+	case service.ErrRoleNotAssigned:
+		c.JSON(http.StatusNotFound, gin.H{"error": "Role is not assigned to this member"})
+	case service.ErrRateLimited:
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": "Invite limit reached, try again later"})
+	case service.ErrUnknownPermission:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown permission key or non-boolean grant; see the permission catalog"})
+	case service.ErrProfileFieldsRequired:
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Required profile fields are missing", "code": "profile_fields_required"})
+	case service.ErrLastOwner:
+		c.JSON(http.StatusConflict, gin.H{"error": "Workspace must keep at least one owner; promote or transfer ownership first", "code": "last_owner"})
+	case service.ErrTransferNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "No pending ownership transfer"})
+	case service.ErrTransferPending:
+		c.JSON(http.StatusConflict, gin.H{"error": "An ownership transfer is already pending; cancel it first"})
+	case service.ErrWorkspaceBusy:
+		c.JSON(http.StatusConflict, gin.H{"error": "Another change to this workspace is in progress; retry shortly", "code": "workspace_busy"})
+	case service.ErrConflict:
+		c.JSON(http.StatusConflict, gin.H{"error": "Resource was modified by another request; reload and retry", "code": "version_conflict"})
+	case service.ErrInvalidColor:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Color must be a hex value like #1a2b3c or #abc", "code": "invalid_color"})
+	case service.ErrExportNotConfirmed:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Workspace export requires confirm=true"})
+	case service.ErrMemberRoleLimitReached:
+		c.JSON(http.StatusConflict, gin.H{"error": "Member has reached the maximum number of roles", "code": "member_role_limit_reached"})
 	default:
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
 	}
@@ -0,0 +1,39 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/quckapp/workspace-service/internal/service"
+	"github.com/sirupsen/logrus"
+)
+
+type HealthHandler struct {
+	checker *service.HealthChecker
+	logger  *logrus.Logger
+}
+
+func NewHealthHandler(checker *service.HealthChecker, logger *logrus.Logger) *HealthHandler {
+	return &HealthHandler{checker: checker, logger: logger}
+}
+
+// Liveness reports that the process is up; it never checks dependencies.
+func (h *HealthHandler) Liveness(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "alive", "service": "workspace-service"})
+}
+
+// Readiness returns 503 only when a required dependency is down.
+func (h *HealthHandler) Readiness(c *gin.Context) {
+	if h.checker == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "unready"})
+		return
+	}
+
+	report := h.checker.Readiness(c.Request.Context())
+	if report.Status == "unready" {
+		h.logger.WithField("dependencies", report.Dependencies).Warn("Readiness check failed")
+		c.JSON(http.StatusServiceUnavailable, report)
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
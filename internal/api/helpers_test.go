@@ -0,0 +1,99 @@
+package api
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	"github.com/quckapp/workspace-service/internal/repository"
+	"github.com/quckapp/workspace-service/internal/service"
+	"github.com/sirupsen/logrus"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+func testLogger() *logrus.Logger {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	return logger
+}
+
+// newTestHandler wires a WorkspaceHandler to a service backed by sqlmock,
+// with no Redis and no Kafka. Expectations are checked when the test ends.
+func newTestHandler(t *testing.T) (*WorkspaceHandler, sqlmock.Sqlmock) {
+	t.Helper()
+	conn, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("unmet expectations: %v", err)
+		}
+		conn.Close()
+	})
+	db := sqlx.NewDb(conn, "mysql")
+	integrationRepo := repository.NewIntegrationRepository(db)
+	s := service.NewWorkspaceService(
+		repository.NewWorkspaceRepository(db),
+		repository.NewMemberRepository(db),
+		repository.NewInviteRepository(db),
+		repository.NewInviteCodeRepository(db),
+		repository.NewActivityRepository(db),
+		repository.NewProfileRepository(db),
+		repository.NewRoleRepository(db),
+		repository.NewTemplateRepository(db),
+		repository.NewPreferenceRepository(db),
+		repository.NewTagRepository(db),
+		repository.NewModerationRepository(db),
+		repository.NewAnnouncementRepository(db),
+		repository.NewWebhookRepository(db),
+		repository.NewFavoriteRepository(db),
+		repository.NewMemberNoteRepository(db),
+		repository.NewScheduledActionRepository(db),
+		repository.NewQuotaRepository(db),
+		repository.NewPinnedItemRepository(db),
+		repository.NewGroupRepository(db),
+		repository.NewCustomFieldRepository(db),
+		repository.NewReactionRepository(db),
+		repository.NewBookmarkRepository(db),
+		repository.NewInvitationHistoryRepository(db),
+		repository.NewAccessLogRepository(db),
+		repository.NewFeatureFlagRepository(db),
+		integrationRepo,
+		repository.NewLabelRepository(db),
+		repository.NewStreakRepository(db),
+		repository.NewOnboardingRepository(db),
+		repository.NewComplianceRepository(db),
+		repository.NewOutboxRepository(db),
+		repository.NewSecurityRepository(db),
+		repository.NewDiscoveryRepository(db),
+		repository.NewAPIKeyRepository(db),
+		repository.NewJoinRequestRepository(db),
+		service.NewAuditSink(integrationRepo, nil, testLogger()),
+		nil,
+		nil,
+		nil,
+		service.CacheConfig{},
+		service.IconConfig{},
+		"https://app.example.com",
+		false,
+		nil,
+		nil,
+		testLogger(),
+	)
+	return NewWorkspaceHandler(s, testLogger()), mock
+}
+
+// serve sends a request with no body through r.
+func serve(r http.Handler, method, path string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+	return w
+}
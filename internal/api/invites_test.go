@@ -0,0 +1,23 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func TestListInvitesRejectsUnknownStatus(t *testing.T) {
+	h, _ := newTestHandler(t)
+	r := gin.New()
+	r.GET("/workspaces/:id/invites", func(c *gin.Context) {
+		c.Set("user_id", uuid.New().String())
+		h.ListInvites(c)
+	})
+
+	w := serve(r, http.MethodGet, "/workspaces/"+uuid.New().String()+"/invites?status=revoked")
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("status %d, want 400", w.Code)
+	}
+}
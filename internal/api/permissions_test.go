@@ -0,0 +1,59 @@
+package api
+
+import (
+	"encoding/csv"
+	"net/http"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func TestGetPermissionCatalogCSV(t *testing.T) {
+	h, mock := newTestHandler(t)
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM workspace_members`).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+	r := gin.New()
+	r.GET("/workspaces/:id/permissions/catalog", func(c *gin.Context) {
+		c.Set("user_id", uuid.New().String())
+		h.GetPermissionCatalog(c)
+	})
+	w := serve(r, http.MethodGet, "/workspaces/"+uuid.New().String()+"/permissions/catalog?format=csv")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d, want 200", w.Code)
+	}
+
+	records, err := csv.NewReader(w.Body).ReadAll()
+	if err != nil {
+		t.Fatalf("parse csv: %v", err)
+	}
+	if len(records) < 2 {
+		t.Fatalf("got %d records, want a header and at least one permission", len(records))
+	}
+	if h := records[0]; len(h) != 3 || h[0] != "key" || h[1] != "category" || h[2] != "description" {
+		t.Errorf("header = %v", h)
+	}
+	for _, rec := range records[1:] {
+		if rec[0] == "" || rec[2] == "" {
+			t.Errorf("incomplete row %v", rec)
+		}
+	}
+}
+
+func TestGetMyPermissionsNonMemberIsForbidden(t *testing.T) {
+	h, mock := newTestHandler(t)
+	mock.ExpectQuery(`SELECT role FROM workspace_members`).
+		WillReturnRows(sqlmock.NewRows([]string{"role"}))
+
+	r := gin.New()
+	r.GET("/workspaces/:id/me/permissions", func(c *gin.Context) {
+		c.Set("user_id", uuid.New().String())
+		h.GetMyPermissions(c)
+	})
+	w := serve(r, http.MethodGet, "/workspaces/"+uuid.New().String()+"/me/permissions")
+	if w.Code != http.StatusForbidden {
+		t.Errorf("status %d, want 403", w.Code)
+	}
+}
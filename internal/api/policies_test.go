@@ -0,0 +1,75 @@
+package api
+
+import (
+	"encoding/csv"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func TestExportPolicyAcknowledgementsCSV(t *testing.T) {
+	h, mock := newTestHandler(t)
+	workspaceID, userID, policyID, memberID := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	ackedAt := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery(`SELECT role FROM workspace_members`).
+		WillReturnRows(sqlmock.NewRows([]string{"role"}).AddRow("owner"))
+	mock.ExpectQuery(`SELECT \* FROM compliance_policies WHERE id = \?`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "workspace_id", "name"}).
+			AddRow(policyID.String(), workspaceID.String(), "Code of conduct"))
+	mock.ExpectQuery(`SELECT \* FROM policy_acknowledgements`).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "acked_at"}).AddRow(memberID.String(), ackedAt))
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM workspace_members`).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectQuery(`SELECT \* FROM workspace_members WHERE workspace_id = \?`).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "role"}).AddRow(memberID.String(), "member"))
+
+	r := gin.New()
+	r.GET("/workspaces/:id/policies/:policyId/acknowledgements/export", func(c *gin.Context) {
+		c.Set("user_id", userID.String())
+		h.ExportPolicyAcknowledgements(c)
+	})
+	w := serve(r, http.MethodGet, "/workspaces/"+workspaceID.String()+"/policies/"+policyID.String()+"/acknowledgements/export?format=csv")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d, want 200: %s", w.Code, w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/csv") {
+		t.Errorf("Content-Type = %q, want text/csv", ct)
+	}
+
+	records, err := csv.NewReader(w.Body).ReadAll()
+	if err != nil {
+		t.Fatalf("parse csv: %v", err)
+	}
+	want := [][]string{
+		{"user_id", "role", "acknowledged", "expired", "acked_at"},
+		{memberID.String(), "member", "true", "false", "2026-03-01T12:00:00Z"},
+	}
+	if len(records) != len(want) {
+		t.Fatalf("got %d records, want %d", len(records), len(want))
+	}
+	for i := range want {
+		if strings.Join(records[i], ",") != strings.Join(want[i], ",") {
+			t.Errorf("record %d = %v, want %v", i, records[i], want[i])
+		}
+	}
+}
+
+func TestExportPolicyAcknowledgementsRejectsUnknownFormat(t *testing.T) {
+	h, _ := newTestHandler(t)
+	r := gin.New()
+	r.GET("/workspaces/:id/policies/:policyId/acknowledgements/export", func(c *gin.Context) {
+		c.Set("user_id", uuid.New().String())
+		h.ExportPolicyAcknowledgements(c)
+	})
+
+	w := serve(r, http.MethodGet, "/workspaces/"+uuid.New().String()+"/policies/"+uuid.New().String()+"/acknowledgements/export?format=xml")
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("status %d, want 400", w.Code)
+	}
+}
@@ -0,0 +1,29 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func TestRemoveMissingReactionReturnsNotFound(t *testing.T) {
+	h, mock := newTestHandler(t)
+	r := gin.New()
+	r.DELETE("/workspaces/:id/reactions", func(c *gin.Context) {
+		c.Set("user_id", uuid.New().String())
+		h.RemoveReaction(c)
+	})
+
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM workspace_members`).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectExec(`DELETE FROM workspace_reactions`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	path := "/workspaces/" + uuid.New().String() + "/reactions?entity_type=announcement&entity_id=" + uuid.New().String() + "&emoji=x"
+	if w := serve(r, http.MethodDelete, path); w.Code != http.StatusNotFound {
+		t.Errorf("status %d, want 404", w.Code)
+	}
+}
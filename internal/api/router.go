@@ -3,17 +3,31 @@ package api
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/quckapp/workspace-service/internal/config"
+	"github.com/quckapp/workspace-service/internal/metrics"
 	"github.com/quckapp/workspace-service/internal/middleware"
 	"github.com/quckapp/workspace-service/internal/service"
 	"github.com/sirupsen/logrus"
 )
 
+// exportRoutes stream potentially large downloads and run under the export
+// timeout rather than the per-request one.
+var exportRoutes = []string{
+	"/api/v1/workspaces/:id/export",
+	"/api/v1/workspaces/:id/members/:userId/export",
+	"/api/v1/workspaces/:id/audit-export",
+	"/api/v1/workspaces/:id/analytics",
+	"/api/v1/workspaces/:id/roles/permission-catalog",
+	"/api/v1/workspaces/:id/policies/:policyId/acknowledgements/export",
+}
+
 func NewRouter(
 	workspaceService *service.WorkspaceService,
 	emojiService *service.EmojiService,
 	billingService *service.BillingService,
 	securityService *service.SecurityService,
 	discoveryService *service.DiscoveryService,
+	healthChecker *service.HealthChecker,
+	m *metrics.Metrics,
 	cfg *config.Config,
 	logger *logrus.Logger,
 ) *gin.Engine {
@@ -26,11 +40,19 @@ func NewRouter(
 	r.Use(middleware.Logger(logger))
 	r.Use(middleware.CORS())
 	r.Use(middleware.RequestID())
+	r.Use(middleware.Metrics(m))
+	r.Use(middleware.Timeout(cfg.RequestTimeout, cfg.ExportTimeout, exportRoutes...))
 
 	r.GET("/health", func(c *gin.Context) {
 		c.JSON(200, gin.H{"status": "healthy", "service": "workspace-service"})
 	})
 
+	healthHandler := NewHealthHandler(healthChecker, logger)
+	r.GET("/healthz", healthHandler.Liveness)
+	r.GET("/readyz", healthHandler.Readiness)
+
+	r.GET("/metrics", gin.WrapH(m.Handler()))
+
 	api := r.Group("/api/v1")
 	{
 		handler := NewWorkspaceHandler(workspaceService, logger)
@@ -39,8 +61,12 @@ func NewRouter(
 		securityHandler := NewSecurityHandler(securityService, logger)
 		discoveryHandler := NewDiscoveryHandler(discoveryService, logger)
 
+		// Build and capability info (no auth)
+		r.GET("/version", handler.GetVersion)
+
 		workspaces := api.Group("/workspaces")
-		workspaces.Use(middleware.Auth(cfg.JWTSecret))
+		workspaces.Use(middleware.AuthWithAPIKeys(cfg.JWTSecret, workspaceService))
+		workspaces.Use(middleware.RequirePolicyAcknowledgement(workspaceService))
 		{
 			// Workspace CRUD
 			workspaces.POST("", handler.CreateWorkspace)
@@ -53,41 +79,73 @@ func NewRouter(
 			workspaces.GET("/:id/stats", handler.GetWorkspaceStats)
 			workspaces.GET("/:id/settings", handler.GetWorkspaceSettings)
 			workspaces.PUT("/:id/settings", handler.UpdateWorkspaceSettings)
+			workspaces.PATCH("/:id/settings", handler.MergeWorkspaceSettings)
+			workspaces.GET("/:id/settings/history", handler.GetSettingsHistory)
+			workspaces.PUT("/:id/icon", handler.SetWorkspaceIcon)
+			workspaces.DELETE("/:id/icon", handler.RemoveWorkspaceIcon)
 			workspaces.POST("/:id/leave", handler.LeaveWorkspace)
 			workspaces.POST("/:id/transfer-ownership", handler.TransferOwnership)
+			workspaces.GET("/:id/transfer-ownership", handler.GetOwnershipTransfer)
+			workspaces.POST("/:id/transfer-ownership/accept", handler.AcceptOwnershipTransfer)
+			workspaces.DELETE("/:id/transfer-ownership", handler.CancelOwnershipTransfer)
 			workspaces.GET("/:id/analytics", handler.GetAnalytics)
+			workspaces.GET("/:id/analytics/churn", handler.GetChurnStats)
+			workspaces.GET("/:id/analytics/invites", handler.GetInviteFunnel)
+			workspaces.GET("/:id/members/:userId/activity-heatmap", handler.GetMemberActivityHeatmap)
 
 			// Members
 			workspaces.GET("/:id/members", handler.ListMembers)
+			workspaces.GET("/:id/members/search", handler.SearchMembers)
+			workspaces.GET("/:id/members/inactive", handler.GetInactiveMembers)
+			workspaces.GET("/:id/members/prune-suggestions", handler.SuggestInactiveMembers)
 			workspaces.GET("/:id/members/:userId", handler.GetMember)
 			workspaces.POST("/:id/members/invite", handler.InviteMember)
 			workspaces.POST("/:id/members/bulk-invite", handler.BulkInvite)
+			workspaces.POST("/:id/members/bulk-remove", handler.BulkRemoveMembers)
+			workspaces.GET("/:id/me/invite-quota", handler.GetMyInviteQuota)
 			workspaces.DELETE("/:id/members/:userId", handler.RemoveMember)
 			workspaces.PUT("/:id/members/:userId/role", handler.UpdateMemberRole)
 
 			// Member Profiles
 			workspaces.GET("/:id/members/:userId/profile", handler.GetMemberProfile)
 			workspaces.PUT("/:id/profile", handler.UpdateMemberProfile)
+			workspaces.PUT("/:id/profile/required-fields", handler.SetRequiredProfileFields)
+			workspaces.GET("/:id/me/profile-completeness", handler.GetProfileCompleteness)
 			workspaces.PUT("/:id/online-status", handler.SetOnlineStatus)
 
 			// Invites
 			workspaces.GET("/:id/invites", handler.ListInvites)
 			workspaces.DELETE("/:id/invites/:inviteId", handler.RevokeInvite)
+			workspaces.POST("/:id/invites/:inviteId/resend", handler.ResendInvite)
 
 			// Invite Codes
 			workspaces.POST("/:id/invite-codes", handler.CreateInviteCode)
 			workspaces.GET("/:id/invite-codes", handler.ListInviteCodes)
 			workspaces.DELETE("/:id/invite-codes/:codeId", handler.RevokeInviteCode)
+			workspaces.POST("/:id/magic-link", handler.CreateMagicJoinLink)
+
+			// Join Requests
+			workspaces.GET("/:id/join-requests", handler.ListJoinRequests)
+			workspaces.POST("/:id/join-requests/:requestId/approve", handler.ApproveJoinRequest)
+			workspaces.POST("/:id/join-requests/:requestId/reject", handler.RejectJoinRequest)
 
 			// Activity Log
 			workspaces.GET("/:id/activity", handler.GetActivityLog)
 			workspaces.GET("/:id/activity/actor/:actorId", handler.GetActivityLogByActor)
+			workspaces.POST("/:id/activity/:activityId/pin", handler.PinActivity)
+			workspaces.DELETE("/:id/activity/:activityId/pin", handler.UnpinActivity)
 
 			// Custom Roles
 			workspaces.POST("/:id/roles", handler.CreateRole)
 			workspaces.GET("/:id/roles", handler.ListRoles)
 			workspaces.PUT("/:id/roles/:roleId", handler.UpdateRole)
 			workspaces.DELETE("/:id/roles/:roleId", handler.DeleteRole)
+			workspaces.GET("/:id/roles/permission-catalog", handler.GetPermissionCatalog)
+			workspaces.GET("/:id/me/permissions", handler.GetMyPermissions)
+			workspaces.PUT("/:id/roles/member-limit", handler.SetMaxRolesPerMember)
+			workspaces.GET("/:id/members/:userId/roles", handler.ListMemberRoles)
+			workspaces.POST("/:id/members/:userId/roles/:roleId", handler.AssignMemberRole)
+			workspaces.DELETE("/:id/members/:userId/roles/:roleId", handler.UnassignMemberRole)
 
 			// Templates (per-workspace)
 			workspaces.POST("/:id/template", handler.CreateTemplateFromWorkspace)
@@ -96,6 +154,7 @@ func NewRouter(
 			workspaces.GET("/:id/preferences", handler.GetPreferences)
 			workspaces.PUT("/:id/preferences", handler.UpdatePreferences)
 			workspaces.DELETE("/:id/preferences", handler.ResetPreferences)
+			workspaces.GET("/:id/members/:userId/should-notify", handler.ShouldNotify)
 
 			// Tags
 			workspaces.POST("/:id/tags", handler.CreateTag)
@@ -116,20 +175,31 @@ func NewRouter(
 			workspaces.PUT("/:id/announcements/:announcementId", handler.UpdateAnnouncement)
 			workspaces.DELETE("/:id/announcements/:announcementId", handler.DeleteAnnouncement)
 			workspaces.PUT("/:id/announcements/:announcementId/pin", handler.PinAnnouncement)
+			workspaces.PUT("/:id/announcements/pins/reorder", handler.ReorderAnnouncementPins)
+			workspaces.PUT("/:id/announcements/default-priority", handler.SetDefaultAnnouncementPriority)
 
 			// Webhooks
 			workspaces.POST("/:id/webhooks", handler.CreateWebhook)
 			workspaces.GET("/:id/webhooks", handler.ListWebhooks)
+			workspaces.GET("/:id/webhooks/health", handler.GetWebhookHealth)
+			workspaces.GET("/:id/webhooks/events", handler.ListWebhookEvents)
 			workspaces.PUT("/:id/webhooks/:webhookId", handler.UpdateWebhook)
 			workspaces.DELETE("/:id/webhooks/:webhookId", handler.DeleteWebhook)
 			workspaces.POST("/:id/webhooks/:webhookId/test", handler.TestWebhook)
 
+			// API keys
+			workspaces.POST("/:id/api-keys", handler.CreateAPIKey)
+			workspaces.GET("/:id/api-keys", handler.ListAPIKeys)
+			workspaces.DELETE("/:id/api-keys/:keyId", handler.RevokeAPIKey)
+
 			// Favorites
 			workspaces.POST("/:id/favorite", handler.FavoriteWorkspace)
 			workspaces.DELETE("/:id/favorite", handler.UnfavoriteWorkspace)
 
 			// Audit Export
 			workspaces.GET("/:id/audit-export", handler.ExportAuditLog)
+			workspaces.GET("/:id/export", handler.ExportWorkspace)
+			workspaces.GET("/:id/members/:userId/export", handler.ExportMemberData)
 
 			// Member Notes
 			workspaces.POST("/:id/members/:userId/notes", handler.CreateMemberNote)
@@ -179,17 +249,23 @@ func NewRouter(
 			workspaces.DELETE("/:id/custom-fields/:fieldId", handler.DeleteCustomField)
 			workspaces.PUT("/:id/custom-fields/:fieldId/value", handler.SetCustomFieldValue)
 			workspaces.GET("/:id/custom-fields/values", handler.GetCustomFieldValues)
+			workspaces.GET("/:id/members/facets", handler.GetMemberFacets)
 
 			// Reactions
 			workspaces.POST("/:id/reactions", handler.AddReaction)
 			workspaces.DELETE("/:id/reactions", handler.RemoveReaction)
 			workspaces.GET("/:id/reactions", handler.ListReactions)
 			workspaces.GET("/:id/reactions/summary", handler.GetReactionSummary)
+			workspaces.GET("/:id/me/reactions", handler.GetMyReactionsByEmoji)
+			workspaces.GET("/:id/me/readiness", handler.GetMyWorkspaceReadiness)
 
 			// Bookmarks
 			workspaces.POST("/:id/bookmarks", handler.CreateBookmark)
 			workspaces.GET("/:id/bookmarks", handler.ListBookmarks)
 			workspaces.GET("/:id/bookmarks/folders", handler.ListBookmarkFolders)
+			workspaces.POST("/:id/bookmarks/folders/remap", handler.RemapBookmarkFolders)
+			workspaces.POST("/:id/bookmarks/folders/rename", handler.RenameBookmarkFolder)
+			workspaces.POST("/:id/bookmarks/folders/delete", handler.DeleteBookmarkFolder)
 			workspaces.PUT("/:id/bookmarks/:bookmarkId", handler.UpdateBookmark)
 			workspaces.DELETE("/:id/bookmarks/:bookmarkId", handler.DeleteBookmark)
 
@@ -207,6 +283,9 @@ func NewRouter(
 			workspaces.PUT("/:id/feature-flags/:flagId", handler.UpdateFeatureFlag)
 			workspaces.DELETE("/:id/feature-flags/:flagId", handler.DeleteFeatureFlag)
 			workspaces.GET("/:id/feature-flags/:key/check", handler.CheckFeatureFlag)
+			workspaces.GET("/:id/feature-flags/:key/history", handler.GetFeatureFlagHistory)
+			workspaces.POST("/:id/feature-flags/check-batch", handler.CheckFeatureFlags)
+			workspaces.GET("/:id/feature-flags/check", handler.CheckFeatureFlagsQuery)
 
 			// Integrations
 			workspaces.POST("/:id/integrations", handler.CreateIntegration)
@@ -214,6 +293,12 @@ func NewRouter(
 			workspaces.GET("/:id/integrations/:integrationId", handler.GetIntegration)
 			workspaces.PUT("/:id/integrations/:integrationId", handler.UpdateIntegration)
 			workspaces.DELETE("/:id/integrations/:integrationId", handler.DeleteIntegration)
+			workspaces.POST("/:id/integrations/:integrationId/rotate-credentials", handler.RotateIntegrationCredentials)
+			workspaces.POST("/:id/integrations/:integrationId/sync", handler.SyncIntegration)
+			workspaces.GET("/:id/integrations/:integrationId/sync", handler.GetIntegrationSyncStatus)
+
+			// Audit Sink
+			workspaces.POST("/:id/audit-sink", handler.ConfigureAuditSink)
 
 			// Labels
 			workspaces.POST("/:id/labels", handler.CreateLabel)
@@ -233,9 +318,12 @@ func NewRouter(
 			workspaces.PUT("/:id/onboarding/:checklistId", handler.UpdateChecklist)
 			workspaces.DELETE("/:id/onboarding/:checklistId", handler.DeleteChecklist)
 			workspaces.POST("/:id/onboarding/:checklistId/steps", handler.AddOnboardingStep)
+			workspaces.PUT("/:id/onboarding/:checklistId/steps/reorder", handler.ReorderOnboardingSteps)
+			workspaces.PUT("/:id/onboarding/steps/:stepId", handler.UpdateOnboardingStep)
 			workspaces.DELETE("/:id/onboarding/steps/:stepId", handler.DeleteOnboardingStep)
 			workspaces.POST("/:id/onboarding/steps/:stepId/complete", handler.CompleteOnboardingStep)
 			workspaces.GET("/:id/onboarding/status", handler.GetMyOnboardingStatus)
+			workspaces.GET("/:id/onboarding/stats", handler.GetWorkspaceOnboardingStats)
 
 			// Compliance Policies
 			workspaces.POST("/:id/policies", handler.CreatePolicy)
@@ -244,6 +332,7 @@ func NewRouter(
 			workspaces.DELETE("/:id/policies/:policyId", handler.DeletePolicy)
 			workspaces.POST("/:id/policies/:policyId/acknowledge", handler.AcknowledgePolicy)
 			workspaces.GET("/:id/policies/:policyId/compliance", handler.GetPolicyComplianceStatus)
+			workspaces.GET("/:id/policies/:policyId/acknowledgements/export", handler.ExportPolicyAcknowledgements)
 
 			// ── NEW: Custom Emojis ──
 			workspaces.POST("/:id/emojis", emojiHandler.CreateEmoji)
@@ -251,6 +340,7 @@ func NewRouter(
 			workspaces.GET("/:id/emojis/search", emojiHandler.SearchEmojis)
 			workspaces.GET("/:id/emojis/categories", emojiHandler.GetCategories)
 			workspaces.GET("/:id/emojis/stats", emojiHandler.GetEmojiStats)
+			workspaces.GET("/:id/emojis/top", emojiHandler.GetTopEmojis)
 			workspaces.GET("/:id/emojis/:emojiId", emojiHandler.GetEmoji)
 			workspaces.PUT("/:id/emojis/:emojiId", emojiHandler.UpdateEmoji)
 			workspaces.DELETE("/:id/emojis/:emojiId", emojiHandler.DeleteEmoji)
@@ -301,6 +391,9 @@ func NewRouter(
 		// Join by invite code (auth required)
 		api.POST("/join", middleware.Auth(cfg.JWTSecret), handler.JoinByCode)
 
+		// Invite code preview for landing pages (public)
+		api.GET("/join/:code/preview", handler.GetInviteCodePreview)
+
 		// Invite acceptance (auth required)
 		api.POST("/invites/:token/accept", middleware.Auth(cfg.JWTSecret), handler.AcceptInvite)
 
@@ -308,6 +401,11 @@ func NewRouter(
 		api.POST("/workspaces/from-template", middleware.Auth(cfg.JWTSecret), handler.CreateWorkspaceFromTemplate)
 		api.GET("/templates", middleware.Auth(cfg.JWTSecret), handler.ListTemplates)
 		api.GET("/templates/:templateId", middleware.Auth(cfg.JWTSecret), handler.GetTemplate)
+		api.GET("/templates/:templateId/preview", middleware.Auth(cfg.JWTSecret), handler.PreviewTemplate)
+		api.GET("/templates/:templateId/versions", middleware.Auth(cfg.JWTSecret), handler.ListTemplateVersions)
+		api.GET("/templates/:templateId/shares", middleware.Auth(cfg.JWTSecret), handler.ListTemplateShares)
+		api.POST("/templates/:templateId/shares", middleware.Auth(cfg.JWTSecret), handler.ShareTemplate)
+		api.DELETE("/templates/:templateId/shares/:userId", middleware.Auth(cfg.JWTSecret), handler.UnshareTemplate)
 		api.PUT("/templates/:templateId", middleware.Auth(cfg.JWTSecret), handler.UpdateTemplate)
 		api.DELETE("/templates/:templateId", middleware.Auth(cfg.JWTSecret), handler.DeleteTemplate)
 
@@ -315,6 +413,9 @@ func NewRouter(
 		api.GET("/favorites", middleware.Auth(cfg.JWTSecret), handler.ListFavorites)
 		api.PUT("/favorites/reorder", middleware.Auth(cfg.JWTSecret), handler.ReorderFavorites)
 
+		// Integration provider registry (standalone)
+		api.GET("/integrations/providers", middleware.Auth(cfg.JWTSecret), handler.ListSupportedProviders)
+
 		// Archived workspaces (standalone)
 		api.GET("/workspaces/archived", middleware.Auth(cfg.JWTSecret), handler.ListArchivedWorkspaces)
 
@@ -322,6 +423,15 @@ func NewRouter(
 		api.GET("/plans", middleware.Auth(cfg.JWTSecret), billingHandler.GetAvailablePlans)
 		api.GET("/plans/:planType", middleware.Auth(cfg.JWTSecret), billingHandler.GetPlanFeatures)
 
+		// Service-admin reports and overrides
+		admin := api.Group("/admin")
+		admin.Use(middleware.Auth(cfg.JWTSecret), middleware.RequireServiceAdmin(cfg.ServiceAdminIDs))
+		{
+			admin.GET("/billing/seat-utilization", billingHandler.GetSeatUtilization)
+			admin.GET("/outbox/stats", handler.GetOutboxStats)
+			admin.POST("/workspaces/:id/transfer-ownership", handler.ForceTransferOwnership)
+		}
+
 		// ── NEW: Discovery (standalone) ──
 		discovery := api.Group("/discovery")
 		discovery.Use(middleware.Auth(cfg.JWTSecret))
@@ -331,6 +441,15 @@ func NewRouter(
 			discovery.GET("/trending", discoveryHandler.GetTrending)
 			discovery.GET("/recommendations", discoveryHandler.GetRecommendations)
 			discovery.POST("/recommendations/dismiss", discoveryHandler.DismissRecommendation)
+			discovery.POST("/recommendations/dismiss-all", discoveryHandler.DismissAllRecommendations)
+			discovery.POST("/workspaces/:id/report", discoveryHandler.ReportWorkspace)
+
+			reports := discovery.Group("/reports")
+			reports.Use(middleware.RequireServiceAdmin(cfg.ServiceAdminIDs))
+			{
+				reports.GET("", discoveryHandler.ListReports)
+				reports.POST("/:reportId/resolve", discoveryHandler.ResolveReport)
+			}
 		}
 	}
 
@@ -0,0 +1,30 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func TestDeleteTagOfAnotherWorkspaceIsNotFound(t *testing.T) {
+	h, mock := newTestHandler(t)
+	tagID := uuid.New()
+	mock.ExpectQuery(`SELECT role FROM workspace_members`).
+		WillReturnRows(sqlmock.NewRows([]string{"role"}).AddRow("owner"))
+	mock.ExpectQuery(`SELECT \* FROM workspace_tags WHERE id = \?`).
+		WithArgs(tagID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "workspace_id"}).AddRow(tagID.String(), uuid.New().String()))
+
+	r := gin.New()
+	r.DELETE("/workspaces/:id/tags/:tagId", func(c *gin.Context) {
+		c.Set("user_id", uuid.New().String())
+		h.DeleteTag(c)
+	})
+	w := serve(r, http.MethodDelete, "/workspaces/"+uuid.New().String()+"/tags/"+tagID.String())
+	if w.Code != http.StatusNotFound {
+		t.Errorf("status %d, want 404", w.Code)
+	}
+}
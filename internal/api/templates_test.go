@@ -0,0 +1,24 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func TestListTemplatesRejectsUnknownSortAndOrder(t *testing.T) {
+	h, _ := newTestHandler(t)
+	r := gin.New()
+	r.GET("/templates", func(c *gin.Context) {
+		c.Set("user_id", uuid.New().String())
+		h.ListTemplates(c)
+	})
+
+	for _, query := range []string{"sort=name", "order=up", "sort=use_count%3BDROP"} {
+		if w := serve(r, http.MethodGet, "/templates?"+query); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status %d, want 400", query, w.Code)
+		}
+	}
+}
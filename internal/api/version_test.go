@@ -0,0 +1,37 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/quckapp/workspace-service/internal/version"
+)
+
+func TestGetVersion(t *testing.T) {
+	h, _ := newTestHandler(t)
+	r := gin.New()
+	r.GET("/version", h.GetVersion)
+
+	w := serve(r, http.MethodGet, "/version")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d, want 200", w.Code)
+	}
+	var body struct {
+		Version      string   `json:"version"`
+		Commit       string   `json:"commit"`
+		APIVersion   string   `json:"api_version"`
+		Capabilities []string `json:"capabilities"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if body.Version != version.Version || body.Commit != version.Commit || body.APIVersion != version.APIVersion {
+		t.Errorf("got %+v, want the build info from the version package", body)
+	}
+	// No Redis, Kafka or running audit sink is configured.
+	if body.Capabilities == nil || len(body.Capabilities) != 0 {
+		t.Errorf("capabilities = %v, want an empty list", body.Capabilities)
+	}
+}
@@ -2,7 +2,9 @@ package config
 
 import (
 	"os"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -13,8 +15,49 @@ type Config struct {
 	DatabaseURL  string
 	RedisURL     string
 	KafkaBrokers []string
+	KafkaGroupID string
 	JWTSecret    string
 	ServiceName  string
+	AppBaseURL   string // public web app URL used in shareable links
+
+	// User IDs allowed to moderate the public directory
+	ServiceAdminIDs []string
+
+	// Soft-deleted workspaces are purged after this many days
+	PurgeRetentionDays int
+	PurgeDryRun        bool
+
+	// Base64 32-byte master key for integration credentials at rest, plus
+	// retired keys still accepted for decryption during rotation
+	CredentialsKey          string
+	CredentialsPreviousKeys []string
+
+	// Treat Gmail addresses that differ only by dots or a +tag as the same
+	// invitee
+	CanonicalizeGmailInvites bool
+
+	// Hosts workspace icons may be served from; a leading "." also matches
+	// subdomains. Icons are rejected when none are configured
+	IconAllowedHosts []string
+	IconMaxBytes     int
+
+	// Redis cache TTLs per entity type
+	CacheTTLWorkspace time.Duration
+	CacheTTLStats     time.Duration
+	CacheTTLNegative  time.Duration
+
+	// Deadline applied to each HTTP request's context, and the longer one
+	// given to export routes
+	RequestTimeout time.Duration
+	ExportTimeout  time.Duration
+
+	// Webhook delivery worker pool; deliveries beyond the queue are dropped
+	WebhookWorkers   int
+	WebhookQueueSize int
+
+	// Upper bound on draining in-flight work (HTTP requests, webhook
+	// deliveries, pending events) during shutdown
+	ShutdownDrainTimeout time.Duration
 }
 
 func Load() (*Config, error) {
@@ -31,8 +74,35 @@ func Load() (*Config, error) {
 		DatabaseURL:  getEnv("DATABASE_URL", "root:password@tcp(localhost:3306)/quckapp_workspaces?parseTime=true"),
 		RedisURL:     getEnv("REDIS_URL", "localhost:6379"),
 		KafkaBrokers: strings.Split(kafkaBrokers, ","),
+		KafkaGroupID: getEnv("KAFKA_GROUP_ID", "workspace-service"),
 		JWTSecret:    getEnv("JWT_SECRET", "your-secret-key"),
 		ServiceName:  "workspace-service",
+		AppBaseURL:   getEnv("APP_BASE_URL", "http://localhost:3000"),
+
+		ServiceAdminIDs: getEnvList("SERVICE_ADMIN_IDS"),
+
+		PurgeRetentionDays: getEnvInt("WORKSPACE_PURGE_RETENTION_DAYS", 30),
+		PurgeDryRun:        os.Getenv("WORKSPACE_PURGE_DRY_RUN") == "true",
+
+		CredentialsKey:          os.Getenv("INTEGRATION_CREDENTIALS_KEY"),
+		CredentialsPreviousKeys: getEnvList("INTEGRATION_CREDENTIALS_PREVIOUS_KEYS"),
+
+		CanonicalizeGmailInvites: os.Getenv("INVITE_CANONICALIZE_GMAIL") == "true",
+
+		IconAllowedHosts: getEnvList("ICON_ALLOWED_HOSTS"),
+		IconMaxBytes:     getEnvInt("ICON_MAX_BYTES", 1<<20),
+
+		CacheTTLWorkspace: getEnvDuration("CACHE_TTL_WORKSPACE", 15*time.Minute),
+		CacheTTLStats:     getEnvDuration("CACHE_TTL_STATS", 15*time.Minute),
+		CacheTTLNegative:  getEnvDuration("CACHE_TTL_NEGATIVE", 30*time.Second),
+
+		RequestTimeout: getEnvDuration("REQUEST_TIMEOUT", 10*time.Second),
+		ExportTimeout:  getEnvDuration("EXPORT_TIMEOUT", 5*time.Minute),
+
+		WebhookWorkers:   getEnvInt("WEBHOOK_WORKERS", 8),
+		WebhookQueueSize: getEnvInt("WEBHOOK_QUEUE_SIZE", 1000),
+
+		ShutdownDrainTimeout: getEnvDuration("SHUTDOWN_DRAIN_TIMEOUT", 30*time.Second),
 	}, nil
 }
 
@@ -42,3 +112,31 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+// getEnvInt parses a positive integer, falling back to the default.
+func getEnvInt(key string, defaultValue int) int {
+	if n, err := strconv.Atoi(os.Getenv(key)); err == nil && n > 0 {
+		return n
+	}
+	return defaultValue
+}
+
+// getEnvList splits a comma-separated variable, dropping empty entries.
+func getEnvList(key string) []string {
+	var values []string
+	for _, v := range strings.Split(os.Getenv(key), ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// getEnvDuration parses a Go duration such as "5m"; unset, invalid or
+// non-positive values fall back to the default.
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	if d, err := time.ParseDuration(os.Getenv(key)); err == nil && d > 0 {
+		return d
+	}
+	return defaultValue
+}
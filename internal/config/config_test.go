@@ -0,0 +1,25 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGetEnvDuration(t *testing.T) {
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{"", time.Minute},
+		{"90s", 90 * time.Second},
+		{"soon", time.Minute},
+		{"-5s", time.Minute},
+		{"0s", time.Minute},
+	}
+	for _, tt := range tests {
+		t.Setenv("TEST_CACHE_TTL", tt.value)
+		if got := getEnvDuration("TEST_CACHE_TTL", time.Minute); got != tt.want {
+			t.Errorf("getEnvDuration(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
@@ -33,7 +33,8 @@ func NewRedis(addr string) (*redis.Client, error) {
 }
 
 type KafkaProducer struct {
-	writer *kafka.Writer
+	writer  *kafka.Writer
+	brokers []string
 }
 
 func NewKafkaProducer(brokers []string) (*KafkaProducer, error) {
@@ -41,7 +42,7 @@ func NewKafkaProducer(brokers []string) (*KafkaProducer, error) {
 		Addr:     kafka.TCP(brokers...),
 		Balancer: &kafka.LeastBytes{},
 	}
-	return &KafkaProducer{writer: writer}, nil
+	return &KafkaProducer{writer: writer, brokers: brokers}, nil
 }
 
 func (p *KafkaProducer) Publish(ctx context.Context, topic, key string, value interface{}) error {
@@ -53,9 +54,79 @@ func (p *KafkaProducer) Publish(ctx context.Context, topic, key string, value in
 	})
 }
 
+// Ping succeeds if any configured broker accepts a connection.
+func (p *KafkaProducer) Ping(ctx context.Context) error {
+	var lastErr error
+	for _, broker := range p.brokers {
+		conn, err := kafka.DialContext(ctx, "tcp", broker)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		conn.Close()
+		return nil
+	}
+	return lastErr
+}
+
 func (p *KafkaProducer) Close() error {
 	if p.writer != nil {
 		return p.writer.Close()
 	}
 	return nil
 }
+
+// KafkaConsumer reads one topic as part of a consumer group. Offsets are
+// committed explicitly, after a message has been handled.
+type KafkaConsumer struct {
+	reader *kafka.Reader
+	topic  string
+}
+
+// ConsumedMessage is a message fetched from a KafkaConsumer.
+type ConsumedMessage struct {
+	Topic string
+	Key   []byte
+	Value []byte
+	Time  time.Time
+
+	raw kafka.Message
+}
+
+func NewKafkaConsumer(brokers []string, groupID, topic string) *KafkaConsumer {
+	reader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers:  brokers,
+		GroupID:  groupID,
+		Topic:    topic,
+		MinBytes: 1,
+		MaxBytes: 10e6,
+	})
+	return &KafkaConsumer{reader: reader, topic: topic}
+}
+
+func (c *KafkaConsumer) Topic() string {
+	return c.topic
+}
+
+// Fetch blocks until the next message is available or ctx is done.
+func (c *KafkaConsumer) Fetch(ctx context.Context) (*ConsumedMessage, error) {
+	m, err := c.reader.FetchMessage(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &ConsumedMessage{Topic: m.Topic, Key: m.Key, Value: m.Value, Time: m.Time, raw: m}, nil
+}
+
+func (c *KafkaConsumer) Commit(ctx context.Context, m *ConsumedMessage) error {
+	return c.reader.CommitMessages(ctx, m.raw)
+}
+
+// Lag is how many messages the consumer is behind the partition it last
+// fetched from.
+func (c *KafkaConsumer) Lag() int64 {
+	return c.reader.Stats().Lag
+}
+
+func (c *KafkaConsumer) Close() error {
+	return c.reader.Close()
+}
@@ -0,0 +1,179 @@
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Metrics holds the service's Prometheus collectors. All methods are safe to
+// call on a nil *Metrics so components can run without instrumentation.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	httpRequests         *prometheus.CounterVec
+	httpDuration         *prometheus.HistogramVec
+	webhookDeliveries    *prometheus.CounterVec
+	webhookQueueDepth    prometheus.Gauge
+	webhookDropped       prometheus.Counter
+	cacheLookups         *prometheus.CounterVec
+	kafkaPublishFailures prometheus.Counter
+	outboxPending        prometheus.Gauge
+	outboxLag            prometheus.Gauge
+	eventsConsumed       *prometheus.CounterVec
+	consumerLag          *prometheus.GaugeVec
+}
+
+// New registers the service collectors on registry. Pass a fresh
+// prometheus.NewRegistry() in tests to keep them isolated.
+func New(registry *prometheus.Registry) *Metrics {
+	m := &Metrics{
+		registry: registry,
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "workspace_http_requests_total",
+			Help: "HTTP requests by route, method and status.",
+		}, []string{"route", "method", "status"}),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "workspace_http_request_duration_seconds",
+			Help:    "HTTP request latency by route and method.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"route", "method"}),
+		webhookDeliveries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "workspace_webhook_deliveries_total",
+			Help: "Outbound webhook deliveries by result.",
+		}, []string{"result"}),
+		webhookQueueDepth: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "workspace_webhook_queue_depth",
+			Help: "Webhook deliveries waiting for a worker.",
+		}),
+		webhookDropped: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "workspace_webhook_dropped_total",
+			Help: "Webhook deliveries dropped because the queue was full.",
+		}),
+		cacheLookups: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "workspace_cache_lookups_total",
+			Help: "Redis cache lookups by cache and result (hit or miss).",
+		}, []string{"cache", "result"}),
+		kafkaPublishFailures: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "workspace_kafka_publish_failures_total",
+			Help: "Events that failed to publish to Kafka.",
+		}),
+		outboxPending: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "workspace_event_outbox_pending",
+			Help: "Events waiting in the outbox to be relayed.",
+		}),
+		outboxLag: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "workspace_event_outbox_lag_seconds",
+			Help: "Age of the oldest unrelayed outbox event.",
+		}),
+		eventsConsumed: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "workspace_events_consumed_total",
+			Help: "Consumed events by topic and result (applied, ignored, malformed, failed).",
+		}, []string{"topic", "result"}),
+		consumerLag: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "workspace_event_consumer_lag",
+			Help: "Messages the consumer is behind, by topic.",
+		}, []string{"topic"}),
+	}
+
+	registry.MustRegister(
+		m.httpRequests,
+		m.httpDuration,
+		m.webhookDeliveries,
+		m.webhookQueueDepth,
+		m.webhookDropped,
+		m.cacheLookups,
+		m.kafkaPublishFailures,
+		m.outboxPending,
+		m.outboxLag,
+		m.eventsConsumed,
+		m.consumerLag,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	return m
+}
+
+// Handler serves the registry in the Prometheus exposition format.
+func (m *Metrics) Handler() http.Handler {
+	if m == nil {
+		return http.NotFoundHandler()
+	}
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+func (m *Metrics) ObserveHTTPRequest(route, method, status string, elapsed time.Duration) {
+	if m == nil {
+		return
+	}
+	m.httpRequests.WithLabelValues(route, method, status).Inc()
+	m.httpDuration.WithLabelValues(route, method).Observe(elapsed.Seconds())
+}
+
+func (m *Metrics) WebhookDelivered(err error) {
+	if m == nil {
+		return
+	}
+	if err != nil {
+		m.webhookDeliveries.WithLabelValues("failure").Inc()
+		return
+	}
+	m.webhookDeliveries.WithLabelValues("success").Inc()
+}
+
+func (m *Metrics) SetWebhookQueueDepth(depth int) {
+	if m == nil {
+		return
+	}
+	m.webhookQueueDepth.Set(float64(depth))
+}
+
+func (m *Metrics) WebhookDropped() {
+	if m == nil {
+		return
+	}
+	m.webhookDropped.Inc()
+}
+
+func (m *Metrics) CacheLookup(cache string, hit bool) {
+	if m == nil {
+		return
+	}
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	m.cacheLookups.WithLabelValues(cache, result).Inc()
+}
+
+func (m *Metrics) KafkaPublishFailed() {
+	if m == nil {
+		return
+	}
+	m.kafkaPublishFailures.Inc()
+}
+
+func (m *Metrics) SetOutboxLag(pending int, lagSeconds float64) {
+	if m == nil {
+		return
+	}
+	m.outboxPending.Set(float64(pending))
+	m.outboxLag.Set(lagSeconds)
+}
+
+func (m *Metrics) EventConsumed(topic, result string) {
+	if m == nil {
+		return
+	}
+	m.eventsConsumed.WithLabelValues(topic, result).Inc()
+}
+
+func (m *Metrics) SetConsumerLag(topic string, lag int64) {
+	if m == nil {
+		return
+	}
+	m.consumerLag.WithLabelValues(topic).Set(float64(lag))
+}
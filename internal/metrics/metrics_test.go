@@ -0,0 +1,64 @@
+package metrics
+
+import (
+	"errors"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func scrape(t *testing.T, m *Metrics) string {
+	t.Helper()
+	w := httptest.NewRecorder()
+	m.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	body, _ := io.ReadAll(w.Body)
+	return string(body)
+}
+
+func TestMetricsExposesRecordedValues(t *testing.T) {
+	m := New(prometheus.NewRegistry())
+	m.ObserveHTTPRequest("/api/v1/workspaces/:id", "GET", "200", 10*time.Millisecond)
+	m.WebhookDelivered(nil)
+	m.WebhookDelivered(errors.New("timeout"))
+	m.CacheLookup("workspace", true)
+	m.KafkaPublishFailed()
+	m.SetOutboxLag(4, 2.5)
+
+	out := scrape(t, m)
+	for _, want := range []string{
+		`workspace_http_requests_total{method="GET",route="/api/v1/workspaces/:id",status="200"} 1`,
+		`workspace_webhook_deliveries_total{result="success"} 1`,
+		`workspace_webhook_deliveries_total{result="failure"} 1`,
+		`workspace_cache_lookups_total{cache="workspace",result="hit"} 1`,
+		`workspace_kafka_publish_failures_total 1`,
+		`workspace_event_outbox_pending 4`,
+		`workspace_event_outbox_lag_seconds 2.5`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("scrape is missing %s", want)
+		}
+	}
+}
+
+func TestNilMetricsIsSafe(t *testing.T) {
+	var m *Metrics
+	m.ObserveHTTPRequest("/", "GET", "200", time.Millisecond)
+	m.WebhookDelivered(nil)
+	m.SetWebhookQueueDepth(3)
+	m.WebhookDropped()
+	m.CacheLookup("workspace", false)
+	m.KafkaPublishFailed()
+	m.SetOutboxLag(1, 1)
+	m.EventConsumed("users", "applied")
+	m.SetConsumerLag("users", 5)
+
+	w := httptest.NewRecorder()
+	m.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	if w.Code != 404 {
+		t.Errorf("status = %d, want 404 without metrics", w.Code)
+	}
+}
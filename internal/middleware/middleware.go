@@ -1,13 +1,18 @@
 package middleware
 
 import (
+	"context"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
+	"github.com/quckapp/workspace-service/internal/metrics"
+	"github.com/quckapp/workspace-service/internal/models"
+	"github.com/quckapp/workspace-service/internal/requestid"
 	"github.com/sirupsen/logrus"
 )
 
@@ -16,14 +21,54 @@ func Logger(logger *logrus.Logger) gin.HandlerFunc {
 		start := time.Now()
 		c.Next()
 		logger.WithFields(logrus.Fields{
-			"status":    c.Writer.Status(),
-			"latency":   time.Since(start),
-			"method":    c.Request.Method,
-			"path":      c.Request.URL.Path,
+			"status":     c.Writer.Status(),
+			"latency":    time.Since(start),
+			"method":     c.Request.Method,
+			"path":       c.Request.URL.Path,
+			"request_id": c.GetString("request_id"),
 		}).Info("Request")
 	}
 }
 
+// Metrics records request count and latency per matched route.
+func Metrics(m *metrics.Metrics) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		m.ObserveHTTPRequest(route, c.Request.Method, strconv.Itoa(c.Writer.Status()), time.Since(start))
+	}
+}
+
+// Timeout bounds each request's context so repository queries and outbound
+// calls made with c.Request.Context() give up once the deadline passes.
+// Handlers that did not write a response by then get a 504. The routes in
+// slowRoutes get slowTimeout instead, and their write deadline is moved to
+// match so the server's WriteTimeout does not cut a long export short.
+func Timeout(d, slowTimeout time.Duration, slowRoutes ...string) gin.HandlerFunc {
+	slow := make(map[string]bool, len(slowRoutes))
+	for _, route := range slowRoutes {
+		slow[route] = true
+	}
+	return func(c *gin.Context) {
+		timeout := d
+		if slow[c.FullPath()] {
+			timeout = slowTimeout
+			http.NewResponseController(c.Writer).SetWriteDeadline(time.Now().Add(slowTimeout))
+		}
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+		if ctx.Err() == context.DeadlineExceeded && !c.Writer.Written() {
+			c.AbortWithStatusJSON(http.StatusGatewayTimeout, gin.H{"error": "Request timed out", "code": "request_timeout"})
+		}
+	}
+}
+
 func CORS() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
@@ -37,19 +82,43 @@ func CORS() gin.HandlerFunc {
 	}
 }
 
+// RequestID accepts the caller's X-Request-ID, or generates one, and stores
+// it on both the gin context and the request context so downstream logs,
+// events and webhook deliveries carry it.
 func RequestID() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		requestID := c.GetHeader("X-Request-ID")
-		if requestID == "" {
+		requestID := c.GetHeader(requestid.Header)
+		if !validRequestID(requestID) {
 			requestID = uuid.New().String()
 		}
 		c.Set("request_id", requestID)
-		c.Writer.Header().Set("X-Request-ID", requestID)
+		c.Request = c.Request.WithContext(requestid.NewContext(c.Request.Context(), requestID))
+		c.Writer.Header().Set(requestid.Header, requestID)
 		c.Next()
 	}
 }
 
+// validRequestID rejects empty, oversized or non-printable IDs so a caller
+// cannot inject arbitrary content into logs and outbound headers.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > 128 {
+		return false
+	}
+	for _, r := range id {
+		if r < 0x21 || r > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
 func Auth(jwtSecret string) gin.HandlerFunc {
+	return AuthWithAPIKeys(jwtSecret, nil)
+}
+
+// AuthWithAPIKeys is Auth that also accepts workspace API keys
+// ("Bearer wsk_..."). A nil keys accepts JWTs only.
+func AuthWithAPIKeys(jwtSecret string, keys APIKeyAuthenticator) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
@@ -65,6 +134,11 @@ func Auth(jwtSecret string) gin.HandlerFunc {
 			return
 		}
 
+		if keys != nil && strings.HasPrefix(parts[1], apiKeyPrefix) {
+			authenticateAPIKey(c, keys, parts[1])
+			return
+		}
+
 		token, err := jwt.Parse(parts[1], func(token *jwt.Token) (interface{}, error) {
 			return []byte(jwtSecret), nil
 		})
@@ -75,8 +149,368 @@ func Auth(jwtSecret string) gin.HandlerFunc {
 			return
 		}
 
+		// Handlers rely on a valid user ID being present, so a token whose
+		// subject is missing or not a UUID is rejected here rather than
+		// letting the request run as the nil user.
 		claims := token.Claims.(jwt.MapClaims)
-		c.Set("user_id", claims["sub"])
+		sub, _ := claims["sub"].(string)
+		userID, err := uuid.Parse(sub)
+		if err != nil || userID == uuid.Nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Token subject must be a valid user ID", "code": "invalid_subject"})
+			c.Abort()
+			return
+		}
+		c.Set("user_id", userID.String())
 		c.Next()
 	}
 }
+
+const apiKeyPrefix = "wsk_"
+
+// APIKeyAuthenticator resolves a presented workspace API key.
+type APIKeyAuthenticator interface {
+	AuthenticateAPIKey(ctx context.Context, key string) (*models.WorkspaceAPIKey, error)
+}
+
+// apiKeyRoutes lists every route under /workspaces/:id an API key may call,
+// keyed by method and the path after /workspaces/:id, with the permission the
+// key needs for it. An empty permission means any key for the workspace may
+// call the route. Routes not listed, such as personal preferences, ownership
+// transfer, managing API keys or deleting the workspace, need a user token.
+var apiKeyRoutes = map[string]string{
+	// Workspace
+	"GET ":                             "",
+	"PUT ":                             "workspace.manage",
+	"GET /stats":                       "",
+	"GET /settings":                    "",
+	"PUT /settings":                    "workspace.manage",
+	"PATCH /settings":                  "workspace.manage",
+	"GET /settings/history":            "",
+	"PUT /icon":                        "workspace.manage",
+	"DELETE /icon":                     "workspace.manage",
+	"POST /template":                   "workspace.manage",
+	"POST /clone":                      "workspace.manage",
+	"GET /directory":                   "",
+	"PUT /directory":                   "workspace.manage",
+	"POST /archive":                    "workspace.archive",
+	"POST /restore":                    "workspace.archive",
+	"GET /activity":                    "",
+	"GET /activity/actor/:actorId":     "",
+	"POST /activity/:activityId/pin":   "messages.pin",
+	"DELETE /activity/:activityId/pin": "messages.pin",
+
+	// Members
+	"GET /members":                          "",
+	"GET /members/search":                   "",
+	"GET /members/facets":                   "",
+	"GET /members/inactive":                 "",
+	"GET /members/prune-suggestions":        "",
+	"GET /members/:userId":                  "",
+	"GET /members/:userId/profile":          "",
+	"GET /members/:userId/groups":           "",
+	"GET /members/:userId/roles":            "",
+	"GET /members/:userId/activity-heatmap": "",
+	"GET /members/:userId/should-notify":    "",
+	"GET /members/:userId/export":           "analytics.view",
+	"GET /members/:userId/notes":            "members.view_notes",
+	"POST /members/invite":                  "members.invite",
+	"POST /members/bulk-invite":             "members.invite",
+	"POST /members/bulk-remove":             "members.remove",
+	"DELETE /members/:userId":               "members.remove",
+	"PUT /members/:userId/role":             "members.manage_roles",
+	"POST /members/:userId/roles/:roleId":   "members.manage_roles",
+	"DELETE /members/:userId/roles/:roleId": "members.manage_roles",
+	"POST /members/:userId/ban":             "moderation.ban",
+	"DELETE /members/:userId/ban":           "moderation.ban",
+	"POST /members/:userId/mute":            "moderation.mute",
+	"DELETE /members/:userId/mute":          "moderation.mute",
+	"GET /moderation":                       "",
+
+	// Invites and join requests
+	"GET /invites":                           "members.invite",
+	"POST /invites/:inviteId/resend":         "members.invite",
+	"DELETE /invites/:inviteId":              "members.invite",
+	"GET /invite-codes":                      "members.invite",
+	"POST /invite-codes":                     "members.invite",
+	"DELETE /invite-codes/:codeId":           "members.invite",
+	"POST /magic-link":                       "members.invite",
+	"GET /join-requests":                     "members.invite",
+	"POST /join-requests/:requestId/approve": "members.invite",
+	"POST /join-requests/:requestId/reject":  "members.invite",
+	"GET /invitation-history":                "",
+	"GET /invitation-stats":                  "",
+
+	// Groups and roles
+	"GET /groups":                             "",
+	"POST /groups":                            "members.manage_roles",
+	"GET /groups/:groupId":                    "",
+	"PUT /groups/:groupId":                    "members.manage_roles",
+	"DELETE /groups/:groupId":                 "members.manage_roles",
+	"POST /groups/:groupId/members":           "members.manage_roles",
+	"DELETE /groups/:groupId/members/:userId": "members.manage_roles",
+	"GET /roles":                              "",
+	"GET /roles/permission-catalog":           "",
+	"POST /roles":                             "roles.manage",
+	"PUT /roles/:roleId":                      "roles.manage",
+	"DELETE /roles/:roleId":                   "roles.manage",
+	"PUT /roles/member-limit":                 "roles.manage",
+
+	// Pins and announcements
+	"GET /pins":                              "",
+	"POST /pins":                             "messages.pin",
+	"PUT /pins/:pinId":                       "messages.pin",
+	"DELETE /pins/:pinId":                    "messages.pin",
+	"PUT /pins/reorder":                      "messages.pin",
+	"GET /announcements":                     "",
+	"POST /announcements":                    "announcements.manage",
+	"PUT /announcements/:announcementId":     "announcements.manage",
+	"DELETE /announcements/:announcementId":  "announcements.manage",
+	"PUT /announcements/:announcementId/pin": "announcements.manage",
+	"PUT /announcements/default-priority":    "announcements.manage",
+	"PUT /announcements/pins/reorder":        "announcements.manage",
+
+	// Customization
+	"GET /tags":                                "",
+	"POST /tags":                               "workspace.manage",
+	"PUT /tags/:tagId":                         "workspace.manage",
+	"DELETE /tags/:tagId":                      "workspace.manage",
+	"GET /labels":                              "",
+	"POST /labels":                             "workspace.manage",
+	"PUT /labels/:labelId":                     "workspace.manage",
+	"DELETE /labels/:labelId":                  "workspace.manage",
+	"GET /custom-fields":                       "",
+	"GET /custom-fields/values":                "",
+	"POST /custom-fields":                      "workspace.manage",
+	"PUT /custom-fields/:fieldId":              "workspace.manage",
+	"DELETE /custom-fields/:fieldId":           "workspace.manage",
+	"GET /emojis":                              "",
+	"GET /emojis/search":                       "",
+	"GET /emojis/categories":                   "",
+	"GET /emojis/stats":                        "",
+	"GET /emojis/top":                          "",
+	"GET /emojis/:emojiId":                     "",
+	"POST /emojis":                             "workspace.manage",
+	"PUT /emojis/:emojiId":                     "workspace.manage",
+	"DELETE /emojis/:emojiId":                  "workspace.manage",
+	"POST /emojis/bulk-delete":                 "workspace.manage",
+	"GET /emoji-packs":                         "",
+	"GET /emoji-packs/:packId/emojis":          "",
+	"POST /emoji-packs":                        "workspace.manage",
+	"DELETE /emoji-packs/:packId":              "workspace.manage",
+	"GET /feature-flags":                       "",
+	"GET /feature-flags/check":                 "",
+	"POST /feature-flags/check-batch":          "",
+	"GET /feature-flags/:key/check":            "",
+	"GET /feature-flags/:key/history":          "",
+	"POST /feature-flags":                      "workspace.manage",
+	"PUT /feature-flags/:flagId":               "workspace.manage",
+	"DELETE /feature-flags/:flagId":            "workspace.manage",
+	"GET /scheduled-actions":                   "",
+	"POST /scheduled-actions":                  "workspace.manage",
+	"PUT /scheduled-actions/:actionId":         "workspace.manage",
+	"DELETE /scheduled-actions/:actionId":      "workspace.manage",
+	"POST /scheduled-actions/:actionId/cancel": "workspace.manage",
+	"GET /reactions":                           "",
+	"GET /reactions/summary":                   "",
+	"GET /onboarding":                          "",
+	"GET /onboarding/stats":                    "",
+	"GET /onboarding/:checklistId":             "",
+
+	// Security and compliance
+	"GET /security":                                   "",
+	"GET /security/policy":                            "",
+	"PUT /security/policy":                            "workspace.manage",
+	"GET /security/ip-allowlist":                      "",
+	"POST /security/ip-allowlist":                     "workspace.manage",
+	"PUT /security/ip-allowlist/:entryId":             "workspace.manage",
+	"DELETE /security/ip-allowlist/:entryId":          "workspace.manage",
+	"GET /security/sessions":                          "audit.view",
+	"POST /security/sessions/revoke":                  "workspace.manage",
+	"DELETE /security/sessions/:sessionId":            "workspace.manage",
+	"GET /security/audit":                             "audit.view",
+	"GET /audit-export":                               "audit.view",
+	"GET /access-logs":                                "audit.view",
+	"GET /access-logs/stats":                          "audit.view",
+	"GET /policies":                                   "",
+	"POST /policies":                                  "policies.manage",
+	"PUT /policies/:policyId":                         "policies.manage",
+	"DELETE /policies/:policyId":                      "policies.manage",
+	"GET /policies/:policyId/compliance":              "audit.view",
+	"GET /policies/:policyId/acknowledgements/export": "audit.view",
+
+	// Analytics
+	"GET /export":              "analytics.view",
+	"GET /analytics":           "analytics.view",
+	"GET /analytics/churn":     "analytics.view",
+	"GET /analytics/invites":   "analytics.view",
+	"GET /streaks/leaderboard": "",
+
+	// Integrations; reads expose webhook secrets and key metadata
+	"GET /integrations":                                    "integrations.manage",
+	"POST /integrations":                                   "integrations.manage",
+	"GET /integrations/:integrationId":                     "integrations.manage",
+	"PUT /integrations/:integrationId":                     "integrations.manage",
+	"DELETE /integrations/:integrationId":                  "integrations.manage",
+	"GET /integrations/:integrationId/sync":                "integrations.manage",
+	"POST /integrations/:integrationId/sync":               "integrations.manage",
+	"POST /integrations/:integrationId/rotate-credentials": "integrations.manage",
+	"GET /webhooks":                                        "integrations.manage",
+	"POST /webhooks":                                       "integrations.manage",
+	"GET /webhooks/events":                                 "integrations.manage",
+	"GET /webhooks/health":                                 "integrations.manage",
+	"PUT /webhooks/:webhookId":                             "integrations.manage",
+	"DELETE /webhooks/:webhookId":                          "integrations.manage",
+	"POST /webhooks/:webhookId/test":                       "integrations.manage",
+	"POST /audit-sink":                                     "integrations.manage",
+	"GET /api-keys":                                        "integrations.manage",
+
+	// Billing
+	"GET /billing":                                   "billing.manage",
+	"GET /billing/plan":                              "billing.manage",
+	"PUT /billing/plan":                              "billing.manage",
+	"DELETE /billing/plan":                           "billing.manage",
+	"GET /billing/events":                            "billing.manage",
+	"GET /billing/invoices":                          "billing.manage",
+	"GET /billing/invoices/:invoiceId":               "billing.manage",
+	"GET /billing/payment-methods":                   "billing.manage",
+	"POST /billing/payment-methods":                  "billing.manage",
+	"PUT /billing/payment-methods/:methodId/default": "billing.manage",
+	"DELETE /billing/payment-methods/:methodId":      "billing.manage",
+	"POST /billing/seats/add":                        "billing.manage",
+	"POST /billing/seats/remove":                     "billing.manage",
+	"GET /quota":                                     "",
+	"PUT /quota":                                     "billing.manage",
+}
+
+// authenticateAPIKey lets a key act as the member who created it, limited
+// to the routes of its own workspace listed in apiKeyRoutes, and to those
+// whose permission has been granted to the key.
+func authenticateAPIKey(c *gin.Context, keys APIKeyAuthenticator, token string) {
+	key, err := keys.AuthenticateAPIKey(c.Request.Context(), token)
+	if err != nil || key == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid API key"})
+		c.Abort()
+		return
+	}
+
+	const scope = "/workspaces/:id"
+	path := c.FullPath()
+	i := strings.Index(path, scope)
+	if i < 0 || c.Param("id") != key.WorkspaceID.String() {
+		c.JSON(http.StatusForbidden, gin.H{"error": "API key is not valid for this resource", "code": "api_key_scope"})
+		c.Abort()
+		return
+	}
+
+	method := c.Request.Method
+	if method == http.MethodHead {
+		method = http.MethodGet
+	}
+	permission, listed := apiKeyRoutes[method+" "+path[i+len(scope):]]
+	if !listed {
+		c.JSON(http.StatusForbidden, gin.H{"error": "This action requires a user token", "code": "api_key_scope"})
+		c.Abort()
+		return
+	}
+	if permission != "" && !key.HasPermission(permission) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "API key lacks the permission for this action", "code": "api_key_scope", "permission": permission})
+		c.Abort()
+		return
+	}
+
+	c.Set("user_id", key.CreatedBy.String())
+	c.Set("api_key_id", key.ID.String())
+	c.Next()
+}
+
+// RequireServiceAdmin restricts a route to the configured service admins.
+// Must run after Auth.
+func RequireServiceAdmin(adminIDs []string) gin.HandlerFunc {
+	admins := make(map[string]bool, len(adminIDs))
+	for _, id := range adminIDs {
+		admins[strings.ToLower(id)] = true
+	}
+	return func(c *gin.Context) {
+		userIDStr, _ := c.Get("user_id")
+		sub, _ := userIDStr.(string)
+		if !admins[strings.ToLower(sub)] {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Service admin access required"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// PolicyAckChecker reports the enforced policies a user has yet to acknowledge.
+type PolicyAckChecker interface {
+	IsMember(ctx context.Context, workspaceID, userID uuid.UUID) (bool, error)
+	OutstandingPolicyAcknowledgements(ctx context.Context, workspaceID, userID uuid.UUID) ([]uuid.UUID, error)
+}
+
+// RequirePolicyAcknowledgement blocks write requests to a workspace until the
+// user has acknowledged every enforced acknowledgement policy. Reads, the
+// policy routes and leaving stay open so users can read and ack the policies.
+// Membership is only checked when policies are outstanding; requests from
+// non-members are then passed on to the handler's own membership check, so
+// the guard never tells outsiders which policies exist. If the
+// outstanding policies cannot be looked up, writes are refused with a 503
+// rather than let through unchecked.
+func RequirePolicyAcknowledgement(checker PolicyAckChecker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			c.Next()
+			return
+		}
+
+		path := c.FullPath()
+		if strings.Contains(path, "/:id/policies") || strings.HasSuffix(path, "/:id/leave") {
+			c.Next()
+			return
+		}
+
+		workspaceID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.Next()
+			return
+		}
+		userIDStr, _ := c.Get("user_id")
+		sub, _ := userIDStr.(string)
+		userID, err := uuid.Parse(sub)
+		if err != nil {
+			c.Next()
+			return
+		}
+
+		// The cached lookup comes first so the common case, nothing
+		// outstanding, costs no membership query.
+		outstanding, err := checker.OutstandingPolicyAcknowledgements(c.Request.Context(), workspaceID, userID)
+		if err == nil && len(outstanding) == 0 {
+			c.Next()
+			return
+		}
+		var isMember bool
+		if err == nil {
+			isMember, err = checker.IsMember(c.Request.Context(), workspaceID, userID)
+		}
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{
+				"error": "Policy acknowledgements could not be checked, try again later",
+				"code":  "policy_check_unavailable",
+			})
+			return
+		}
+		if !isMember {
+			c.Next()
+			return
+		}
+
+		c.JSON(http.StatusForbidden, gin.H{
+			"error":      "Policy acknowledgement required",
+			"code":       "policy_acknowledgement_required",
+			"policy_ids": outstanding,
+		})
+		c.Abort()
+	}
+}
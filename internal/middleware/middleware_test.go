@@ -0,0 +1,313 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/quckapp/workspace-service/internal/metrics"
+	"github.com/quckapp/workspace-service/internal/models"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+type fakePolicyChecker struct {
+	nonMember   bool
+	outstanding []uuid.UUID
+	err         error
+	calls       int
+	memberCalls int
+}
+
+func (f *fakePolicyChecker) IsMember(ctx context.Context, workspaceID, userID uuid.UUID) (bool, error) {
+	f.memberCalls++
+	return !f.nonMember, nil
+}
+
+func (f *fakePolicyChecker) OutstandingPolicyAcknowledgements(ctx context.Context, workspaceID, userID uuid.UUID) ([]uuid.UUID, error) {
+	f.calls++
+	return f.outstanding, f.err
+}
+
+func newPolicyRouter(checker PolicyAckChecker, userID string) *gin.Engine {
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set("user_id", userID)
+		c.Next()
+	})
+	r.Use(RequirePolicyAcknowledgement(checker))
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	r.GET("/workspaces/:id", ok)
+	r.PUT("/workspaces/:id", ok)
+	r.POST("/workspaces/:id/policies/:policyId/acknowledge", ok)
+	r.POST("/workspaces/:id/leave", ok)
+	return r
+}
+
+func serve(r http.Handler, method, path string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+	return w
+}
+
+func TestRequirePolicyAcknowledgementBlocksWrites(t *testing.T) {
+	checker := &fakePolicyChecker{outstanding: []uuid.UUID{uuid.New()}}
+	r := newPolicyRouter(checker, uuid.New().String())
+	path := "/workspaces/" + uuid.New().String()
+
+	if w := serve(r, http.MethodPut, path); w.Code != http.StatusForbidden {
+		t.Errorf("write with outstanding policy: status %d, want 403", w.Code)
+	}
+	if w := serve(r, http.MethodGet, path); w.Code != http.StatusOK {
+		t.Errorf("read with outstanding policy: status %d, want 200", w.Code)
+	}
+	if w := serve(r, http.MethodPost, path+"/policies/"+uuid.New().String()+"/acknowledge"); w.Code != http.StatusOK {
+		t.Errorf("acknowledge: status %d, want 200", w.Code)
+	}
+	if w := serve(r, http.MethodPost, path+"/leave"); w.Code != http.StatusOK {
+		t.Errorf("leave: status %d, want 200", w.Code)
+	}
+}
+
+func TestRequirePolicyAcknowledgementAllowsAcknowledgedMember(t *testing.T) {
+	checker := &fakePolicyChecker{}
+	r := newPolicyRouter(checker, uuid.New().String())
+
+	if w := serve(r, http.MethodPut, "/workspaces/"+uuid.New().String()); w.Code != http.StatusOK {
+		t.Errorf("status %d, want 200", w.Code)
+	}
+	if checker.calls != 1 {
+		t.Errorf("checker called %d times, want 1", checker.calls)
+	}
+	if checker.memberCalls != 0 {
+		t.Errorf("membership checked %d times with nothing outstanding, want 0", checker.memberCalls)
+	}
+}
+
+func TestRequirePolicyAcknowledgementFailsClosed(t *testing.T) {
+	checker := &fakePolicyChecker{err: errors.New("database unavailable")}
+	r := newPolicyRouter(checker, uuid.New().String())
+
+	if w := serve(r, http.MethodPut, "/workspaces/"+uuid.New().String()); w.Code != http.StatusServiceUnavailable {
+		t.Errorf("status %d, want 503", w.Code)
+	}
+}
+
+func TestRequirePolicyAcknowledgementSkipsNonMembers(t *testing.T) {
+	checker := &fakePolicyChecker{nonMember: true, outstanding: []uuid.UUID{uuid.New()}}
+	r := newPolicyRouter(checker, uuid.New().String())
+
+	w := serve(r, http.MethodPut, "/workspaces/"+uuid.New().String())
+	if w.Code != http.StatusOK {
+		t.Errorf("status %d, want the handler's response", w.Code)
+	}
+	if strings.Contains(w.Body.String(), "policy_ids") {
+		t.Errorf("non-member was shown outstanding policies: %s", w.Body.String())
+	}
+	if checker.memberCalls != 1 {
+		t.Errorf("membership checked %d times, want 1", checker.memberCalls)
+	}
+}
+
+func TestMetricsLabelsRequestsByRoute(t *testing.T) {
+	m := metrics.New(prometheus.NewRegistry())
+	r := gin.New()
+	r.Use(Metrics(m))
+	r.GET("/workspaces/:id", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	serve(r, "GET", "/workspaces/"+uuid.New().String())
+	serve(r, "GET", "/nowhere")
+
+	body, _ := io.ReadAll(serve(m.Handler(), "GET", "/metrics").Body)
+	for _, want := range []string{
+		`route="/workspaces/:id",status="200"`,
+		`route="unmatched",status="404"`,
+	} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("scrape is missing %s", want)
+		}
+	}
+}
+
+func TestRequireServiceAdmin(t *testing.T) {
+	admin := uuid.New().String()
+	newRouter := func(userID string) *gin.Engine {
+		r := gin.New()
+		r.Use(func(c *gin.Context) {
+			c.Set("user_id", userID)
+			c.Next()
+		})
+		r.Use(RequireServiceAdmin([]string{strings.ToUpper(admin)}))
+		r.GET("/admin/reports", func(c *gin.Context) { c.Status(http.StatusOK) })
+		return r
+	}
+
+	if w := serve(newRouter(admin), "GET", "/admin/reports"); w.Code != http.StatusOK {
+		t.Errorf("admin got %d, want 200", w.Code)
+	}
+	if w := serve(newRouter(uuid.New().String()), "GET", "/admin/reports"); w.Code != http.StatusForbidden {
+		t.Errorf("non-admin got %d, want 403", w.Code)
+	}
+	if w := serve(newRouter(""), "GET", "/admin/reports"); w.Code != http.StatusForbidden {
+		t.Errorf("anonymous caller got %d, want 403", w.Code)
+	}
+}
+
+func signedToken(t *testing.T, secret string, claims jwt.MapClaims) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+	if err != nil {
+		t.Fatalf("sign token: %v", err)
+	}
+	return token
+}
+
+func TestAuthSetsUserIDFromSubject(t *testing.T) {
+	userID := uuid.New()
+	var got string
+	r := gin.New()
+	r.Use(Auth("secret"))
+	r.GET("/me", func(c *gin.Context) { got = c.GetString("user_id") })
+
+	req := httptest.NewRequest(http.MethodGet, "/me", nil)
+	req.Header.Set("Authorization", "Bearer "+signedToken(t, "secret", jwt.MapClaims{"sub": strings.ToUpper(userID.String())}))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d, want 200", w.Code)
+	}
+	if got != userID.String() {
+		t.Errorf("user_id = %q, want %q", got, userID.String())
+	}
+}
+
+func TestAuthRejectsTokenWithoutValidSubject(t *testing.T) {
+	r := gin.New()
+	r.Use(Auth("secret"))
+	r.GET("/me", func(c *gin.Context) { t.Error("handler ran for an invalid subject") })
+
+	tests := map[string]jwt.MapClaims{
+		"missing":  {},
+		"not uuid": {"sub": "alice"},
+		"nil uuid": {"sub": uuid.Nil.String()},
+		"number":   {"sub": 42},
+	}
+	for name, claims := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/me", nil)
+		req.Header.Set("Authorization", "Bearer "+signedToken(t, "secret", claims))
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if w.Code != http.StatusUnauthorized || !strings.Contains(w.Body.String(), "invalid_subject") {
+			t.Errorf("%s subject: status %d body %s, want 401 invalid_subject", name, w.Code, w.Body)
+		}
+	}
+}
+
+type fakeKeyAuthenticator struct {
+	key *models.WorkspaceAPIKey
+}
+
+func (f fakeKeyAuthenticator) AuthenticateAPIKey(ctx context.Context, key string) (*models.WorkspaceAPIKey, error) {
+	return f.key, nil
+}
+
+func TestAuthenticateAPIKeyChecksEachRoute(t *testing.T) {
+	workspaceID, creator := uuid.New(), uuid.New()
+	ws := "/workspaces/" + workspaceID.String()
+	member := "/" + uuid.New().String()
+
+	tests := []struct {
+		method, route, path string
+		granted             []string
+		want                int
+	}{
+		{"GET", "/workspaces/:id", ws, nil, http.StatusOK},
+		{"PUT", "/workspaces/:id", ws, []string{"workspace.manage"}, http.StatusOK},
+		{"PUT", "/workspaces/:id", ws, nil, http.StatusForbidden},
+		{"DELETE", "/workspaces/:id", ws, []string{"workspace.manage", "workspace.archive"}, http.StatusForbidden},
+		{"GET", "/workspaces/:id", "/workspaces/" + uuid.New().String(), nil, http.StatusForbidden},
+		{"GET", "/workspaces/:id/members", ws + "/members", nil, http.StatusOK},
+		{"GET", "/workspaces/:id/members/:userId/notes", ws + "/members" + member + "/notes", nil, http.StatusForbidden},
+		{"GET", "/workspaces/:id/members/:userId/notes", ws + "/members" + member + "/notes", []string{"members.view_notes"}, http.StatusOK},
+		{"POST", "/workspaces/:id/members/invite", ws + "/members/invite", []string{"members.manage_roles"}, http.StatusForbidden},
+		{"POST", "/workspaces/:id/members/invite", ws + "/members/invite", []string{"members.invite"}, http.StatusOK},
+		{"DELETE", "/workspaces/:id/members/:userId", ws + "/members" + member, []string{"members.manage_roles"}, http.StatusForbidden},
+		{"DELETE", "/workspaces/:id/members/:userId", ws + "/members" + member, []string{"members.remove"}, http.StatusOK},
+		{"PUT", "/workspaces/:id/members/:userId/role", ws + "/members" + member + "/role", []string{"members.manage_roles"}, http.StatusOK},
+		{"POST", "/workspaces/:id/members/:userId/ban", ws + "/members" + member + "/ban", []string{"members.manage_roles"}, http.StatusForbidden},
+		{"POST", "/workspaces/:id/members/:userId/ban", ws + "/members" + member + "/ban", []string{"moderation.ban"}, http.StatusOK},
+		{"POST", "/workspaces/:id/members/:userId/mute", ws + "/members" + member + "/mute", []string{"moderation.mute"}, http.StatusOK},
+		{"GET", "/workspaces/:id/webhooks", ws + "/webhooks", nil, http.StatusForbidden},
+		{"GET", "/workspaces/:id/webhooks", ws + "/webhooks", []string{"integrations.manage"}, http.StatusOK},
+		{"GET", "/workspaces/:id/integrations", ws + "/integrations", nil, http.StatusForbidden},
+		{"POST", "/workspaces/:id/audit-sink", ws + "/audit-sink", nil, http.StatusForbidden},
+		{"GET", "/workspaces/:id/api-keys", ws + "/api-keys", nil, http.StatusForbidden},
+		{"GET", "/workspaces/:id/api-keys", ws + "/api-keys", []string{"integrations.manage"}, http.StatusOK},
+		{"POST", "/workspaces/:id/api-keys", ws + "/api-keys", []string{"integrations.manage"}, http.StatusForbidden},
+		{"GET", "/workspaces/:id/analytics", ws + "/analytics", []string{"analytics.view"}, http.StatusOK},
+		{"GET", "/workspaces/:id/access-logs", ws + "/access-logs", nil, http.StatusForbidden},
+		{"GET", "/workspaces/:id/preferences", ws + "/preferences", nil, http.StatusForbidden},
+		{"PUT", "/workspaces/:id/preferences", ws + "/preferences", []string{"workspace.manage"}, http.StatusForbidden},
+		{"POST", "/workspaces/:id/transfer-ownership", ws + "/transfer-ownership", []string{"workspace.manage"}, http.StatusForbidden},
+	}
+
+	r := gin.New()
+	var key *models.WorkspaceAPIKey
+	r.Use(func(c *gin.Context) {
+		AuthWithAPIKeys("secret", fakeKeyAuthenticator{key: key})(c)
+	})
+	registered := map[string]bool{}
+	for _, tt := range tests {
+		if !registered[tt.method+" "+tt.route] {
+			registered[tt.method+" "+tt.route] = true
+			r.Handle(tt.method, tt.route, func(c *gin.Context) {
+				if c.GetString("user_id") != creator.String() {
+					t.Errorf("user_id = %q, want the key's creator", c.GetString("user_id"))
+				}
+				c.Status(http.StatusOK)
+			})
+		}
+	}
+
+	for _, tt := range tests {
+		permissions := models.JSON{}
+		for _, p := range tt.granted {
+			permissions[p] = true
+		}
+		key = &models.WorkspaceAPIKey{ID: uuid.New(), WorkspaceID: workspaceID, Permissions: permissions, CreatedBy: creator}
+
+		req := httptest.NewRequest(tt.method, tt.path, nil)
+		req.Header.Set("Authorization", "Bearer wsk_test")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != tt.want {
+			t.Errorf("%s %s with %v: status %d, want %d", tt.method, tt.route, tt.granted, w.Code, tt.want)
+		}
+	}
+}
+
+func TestAPIKeyRoutesAreWellFormed(t *testing.T) {
+	for route := range apiKeyRoutes {
+		method, path, _ := strings.Cut(route, " ")
+		switch method {
+		case http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		default:
+			t.Errorf("%q: unknown method", route)
+		}
+		if path != "" && !strings.HasPrefix(path, "/") {
+			t.Errorf("%q: path must be empty or start with /", route)
+		}
+	}
+}
@@ -97,6 +97,26 @@ type BillingOverview struct {
 	EstimatedCost  int              `json:"estimated_cost"` // cents
 }
 
+type SeatUtilization struct {
+	WorkspaceID    uuid.UUID `json:"workspace_id" db:"workspace_id"`
+	WorkspaceName  string    `json:"workspace_name" db:"workspace_name"`
+	Status         string    `json:"status" db:"status"`
+	PurchasedSeats int       `json:"purchased_seats" db:"purchased_seats"`
+	ActiveMembers  int       `json:"active_members" db:"active_members"`
+	Overage        int       `json:"overage" db:"-"`
+	Utilization    float64   `json:"utilization" db:"-"` // percent of purchased seats in use
+}
+
+type PlanSeatUtilization struct {
+	PlanType            string             `json:"plan_type"`
+	Workspaces          []*SeatUtilization `json:"workspaces"`
+	TotalPurchasedSeats int                `json:"total_purchased_seats"`
+	TotalActiveMembers  int                `json:"total_active_members"`
+	TotalOverage        int                `json:"total_overage"`
+	WorkspacesOverLimit int                `json:"workspaces_over_limit"`
+	GeneratedAt         time.Time          `json:"generated_at"`
+}
+
 type PlanFeatures struct {
 	PlanType       string `json:"plan_type"`
 	MaxMembers     int    `json:"max_members"`
@@ -110,6 +130,8 @@ type PlanFeatures struct {
 	SSO            bool   `json:"sso"`
 	GuestAccess    bool   `json:"guest_access"`
 	PricePerSeat   int    `json:"price_per_seat"` // cents/month
+	MaxBookmarksPerUser int `json:"max_bookmarks_per_user"`
+	MaxInvitesPerDay int `json:"max_invites_per_day"` // per workspace, rolling 24h
 }
 
 type UsageReport struct {
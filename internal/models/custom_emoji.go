@@ -78,6 +78,18 @@ type EmojiPackMapping struct {
 	Position int       `json:"position" db:"position"`
 }
 
+// TopEmoji ranks an emoji by how much the workspace uses it. Custom emojis
+// combine their usage_count with reactions naming them.
+type TopEmoji struct {
+	Emoji         string     `json:"emoji"` // the character, or the custom emoji's name
+	IsCustom      bool       `json:"is_custom"`
+	EmojiID       *uuid.UUID `json:"emoji_id,omitempty"`
+	ImageURL      *string    `json:"image_url,omitempty"`
+	ReactionCount int        `json:"reaction_count"`
+	UsageCount    int        `json:"usage_count"`
+	Total         int        `json:"total"`
+}
+
 type EmojiStats struct {
 	TotalEmojis    int             `json:"total_emojis"`
 	AnimatedCount  int             `json:"animated_count"`
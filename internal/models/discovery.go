@@ -73,3 +73,32 @@ type WorkspaceCategory struct {
 type DismissRecommendationRequest struct {
 	WorkspaceID string `json:"workspace_id" binding:"required"`
 }
+
+type DirectoryReport struct {
+	ID             uuid.UUID  `json:"id" db:"id"`
+	WorkspaceID    uuid.UUID  `json:"workspace_id" db:"workspace_id"`
+	ReporterID     uuid.UUID  `json:"reporter_id" db:"reporter_id"`
+	Reason         string     `json:"reason" db:"reason"`
+	Details        *string    `json:"details" db:"details"`
+	Status         string     `json:"status" db:"status"` // pending, dismissed, actioned
+	ResolvedBy     *uuid.UUID `json:"resolved_by" db:"resolved_by"`
+	ResolutionNote *string    `json:"resolution_note" db:"resolution_note"`
+	ResolvedAt     *time.Time `json:"resolved_at" db:"resolved_at"`
+	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
+}
+
+type ReportDirectoryWorkspaceRequest struct {
+	Reason  string  `json:"reason" binding:"required,oneof=spam abuse inappropriate impersonation scam other"`
+	Details *string `json:"details" binding:"omitempty,max=2000"`
+}
+
+type ResolveDirectoryReportRequest struct {
+	Action string  `json:"action" binding:"required,oneof=dismiss unlist"`
+	Note   *string `json:"note" binding:"omitempty,max=2000"`
+}
+
+type DirectoryReportListParams struct {
+	Status  string `form:"status,default=pending"`
+	Page    int    `form:"page,default=1"`
+	PerPage int    `form:"per_page,default=20"`
+}
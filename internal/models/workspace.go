@@ -16,6 +16,7 @@ type Workspace struct {
 	Plan        string     `json:"plan" db:"plan"` // free, pro, enterprise
 	Settings    JSON       `json:"settings" db:"settings"`
 	IsActive    bool       `json:"is_active" db:"is_active"`
+	Version     int        `json:"version" db:"version"` // bumped on every write; used for optimistic concurrency
 	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
 	UpdatedAt   time.Time  `json:"updated_at" db:"updated_at"`
 	DeletedAt   *time.Time `json:"deleted_at,omitempty" db:"deleted_at"`
@@ -31,6 +32,19 @@ type WorkspaceMember struct {
 	IsActive    bool       `json:"is_active" db:"is_active"`
 	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
 	UpdatedAt   time.Time  `json:"updated_at" db:"updated_at"`
+	LastSeenAt  *time.Time `json:"last_seen_at" db:"last_seen_at"` // from the member's profile, when loaded
+}
+
+// WorkspaceSettingsChange records one settings update. Changes holds only
+// the top-level keys it changed, each as {"old": ..., "new": ...}; a key
+// that was added has a null old value and a removed key a null new value.
+type WorkspaceSettingsChange struct {
+	ID          uuid.UUID `json:"id" db:"id"`
+	WorkspaceID uuid.UUID `json:"workspace_id" db:"workspace_id"`
+	ActorID     uuid.UUID `json:"actor_id" db:"actor_id"`
+	Changes     JSON      `json:"changes" db:"changes"`
+	Version     int       `json:"version" db:"version"` // workspace version the update produced
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
 }
 
 type WorkspaceInvite struct {
@@ -42,26 +56,82 @@ type WorkspaceInvite struct {
 	InvitedBy   uuid.UUID  `json:"invited_by" db:"invited_by"`
 	ExpiresAt   time.Time  `json:"expires_at" db:"expires_at"`
 	AcceptedAt  *time.Time `json:"accepted_at" db:"accepted_at"`
+	LastSentAt  *time.Time `json:"last_sent_at" db:"last_sent_at"`
 	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+	Status      string     `json:"status" db:"-"` // pending, accepted, expired; computed on read
 }
 
 type WorkspaceInviteCode struct {
 	ID          uuid.UUID  `json:"id" db:"id"`
 	WorkspaceID uuid.UUID  `json:"workspace_id" db:"workspace_id"`
 	Code        string     `json:"code" db:"code"`
-	Role        string     `json:"role" db:"role"`
+	Role        string     `json:"role" db:"role"` // empty defers to the default_member_role setting
 	MaxUses     int        `json:"max_uses" db:"max_uses"`
 	UseCount    int        `json:"use_count" db:"use_count"`
 	CreatedBy   uuid.UUID  `json:"created_by" db:"created_by"`
 	ExpiresAt   *time.Time `json:"expires_at" db:"expires_at"`
 	IsActive    bool       `json:"is_active" db:"is_active"`
-	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
-	UpdatedAt   time.Time  `json:"updated_at" db:"updated_at"`
+	// ApprovalRequired makes joining through the code open a join request
+	// for an admin to approve instead of adding the member directly.
+	ApprovalRequired bool      `json:"approval_required" db:"approval_required"`
+	CreatedAt        time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// WorkspaceJoinRequest is a pending (or decided) request to join through an
+// approval-required invite code. A user has at most one row per workspace;
+// asking again after a decision reopens it.
+type WorkspaceJoinRequest struct {
+	ID           uuid.UUID  `json:"id" db:"id"`
+	WorkspaceID  uuid.UUID  `json:"workspace_id" db:"workspace_id"`
+	UserID       uuid.UUID  `json:"user_id" db:"user_id"`
+	InviteCodeID uuid.UUID  `json:"invite_code_id" db:"invite_code_id"`
+	Role         string     `json:"role" db:"role"`
+	Status       string     `json:"status" db:"status"` // pending, approved, rejected
+	ReviewedBy   *uuid.UUID `json:"reviewed_by" db:"reviewed_by"`
+	ReviewedAt   *time.Time `json:"reviewed_at" db:"reviewed_at"`
+	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+const (
+	JoinRequestPending  = "pending"
+	JoinRequestApproved = "approved"
+	JoinRequestRejected = "rejected"
+)
+
+// InviteCodePreview is the public view of an invite code shown on the
+// landing page before joining. The workspace ID is only included when the
+// workspace is listed in the directory.
+type InviteCodePreview struct {
+	Status         string     `json:"status"` // valid, expired, full, revoked
+	Valid          bool       `json:"valid"`
+	WorkspaceID    *uuid.UUID `json:"workspace_id,omitempty"`
+	Name           string     `json:"name"`
+	Description    *string    `json:"description"`
+	IconURL        *string    `json:"icon_url"`
+	MemberCount    int        `json:"member_count"`
+	Role           string     `json:"role"`
+	ExpiresAt      *time.Time `json:"expires_at"`
+	LandingMessage *string    `json:"landing_message,omitempty"`
 }
 
 type CreateInviteCodeRequest struct {
-	Role    string `json:"role" binding:"required,oneof=admin member guest"`
-	MaxUses int    `json:"max_uses"`
+	Role             string `json:"role" binding:"omitempty,oneof=admin member guest"` // empty uses the default_member_role setting at join time
+	MaxUses          int    `json:"max_uses"`
+	ApprovalRequired bool   `json:"approval_required"`
+}
+
+type CreateMagicJoinLinkRequest struct {
+	Role string `json:"role" binding:"required,oneof=admin member guest"`
+}
+
+type MagicJoinLink struct {
+	InviteCodeID uuid.UUID `json:"invite_code_id"`
+	Code         string    `json:"code"`
+	URL          string    `json:"url"`
+	Role         string    `json:"role"`
+	ExpiresAt    time.Time `json:"expires_at"`
 }
 
 // ── Activity Log ──
@@ -76,6 +146,16 @@ type ActivityLog struct {
 	Details     JSON      `json:"details" db:"details"`
 	IPAddress   string    `json:"ip_address,omitempty" db:"ip_address"`
 	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	IsPinned    bool      `json:"is_pinned" db:"-"`
+}
+
+// PinnedActivity highlights an activity entry. Pins live apart from the
+// activity log so the log itself stays append-only.
+type PinnedActivity struct {
+	WorkspaceID uuid.UUID `json:"workspace_id" db:"workspace_id"`
+	ActivityID  uuid.UUID `json:"activity_id" db:"activity_id"`
+	PinnedBy    uuid.UUID `json:"pinned_by" db:"pinned_by"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
 }
 
 type ActivityLogResponse struct {
@@ -110,6 +190,16 @@ type UpdateMemberProfileRequest struct {
 	Timezone    *string `json:"timezone"`
 }
 
+type SetRequiredProfileFieldsRequest struct {
+	Fields []string `json:"fields" binding:"max=3,dive,oneof=display_name title timezone"`
+}
+
+type ProfileCompleteness struct {
+	RequiredFields []string `json:"required_fields"`
+	MissingFields  []string `json:"missing_fields"`
+	IsComplete     bool     `json:"is_complete"`
+}
+
 type MemberWithProfile struct {
 	WorkspaceMember
 	Profile *MemberProfile `json:"profile,omitempty"`
@@ -126,6 +216,7 @@ type WorkspaceRole struct {
 	Permissions JSON      `json:"permissions" db:"permissions"`
 	IsDefault   bool      `json:"is_default" db:"is_default"`
 	CreatedBy   uuid.UUID `json:"created_by" db:"created_by"`
+	Version     int       `json:"version" db:"version"`
 	CreatedAt   time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
 }
@@ -142,6 +233,40 @@ type UpdateRoleRequest struct {
 	Color       *string `json:"color"`
 	Priority    *int    `json:"priority"`
 	Permissions JSON    `json:"permissions"`
+	Version     *int    `json:"version"` // when set, must match the current version
+}
+
+type PermissionDefinition struct {
+	Key         string `json:"key"`
+	Category    string `json:"category"`
+	Description string `json:"description"`
+}
+
+// EffectivePermissions is what a member may do in a workspace: the grants
+// of their base role and every custom role assigned to them, combined.
+type EffectivePermissions struct {
+	WorkspaceID uuid.UUID `json:"workspace_id"`
+	UserID      uuid.UUID `json:"user_id"`
+	Role        string    `json:"role"`
+	Permissions []string  `json:"permissions"`
+}
+
+type WebhookEventDefinition struct {
+	Type        string `json:"type"`
+	Description string `json:"description"`
+}
+
+type MemberRoleAssignment struct {
+	ID          uuid.UUID `json:"id" db:"id"`
+	WorkspaceID uuid.UUID `json:"workspace_id" db:"workspace_id"`
+	UserID      uuid.UUID `json:"user_id" db:"user_id"`
+	RoleID      uuid.UUID `json:"role_id" db:"role_id"`
+	AssignedBy  uuid.UUID `json:"assigned_by" db:"assigned_by"`
+	AssignedAt  time.Time `json:"assigned_at" db:"assigned_at"`
+}
+
+type SetMaxRolesPerMemberRequest struct {
+	MaxRoles int `json:"max_roles" binding:"required,min=1,max=100"`
 }
 
 // ── Workspace Analytics ──
@@ -154,11 +279,29 @@ type WorkspaceAnalytics struct {
 	JoinMethodStats  map[string]int `json:"join_method_stats"`
 }
 
+type ChurnStats struct {
+	Days           int     `json:"days"`
+	Joins          int     `json:"joins"`
+	Leaves         int     `json:"leaves"`
+	Removals       int     `json:"removals"`
+	NetGrowth      int     `json:"net_growth"`
+	StartMembers   int     `json:"start_members"`
+	CurrentMembers int     `json:"current_members"`
+	ChurnRate      float64 `json:"churn_rate"` // percentage of start members who left
+}
+
 type DailyCount struct {
 	Date  string `json:"date" db:"date"`
 	Count int    `json:"count" db:"count"`
 }
 
+type MemberActivityHeatmap struct {
+	UserID uuid.UUID    `json:"user_id"`
+	Days   int          `json:"days"`
+	Total  int          `json:"total"`
+	Counts []DailyCount `json:"counts"` // one entry per day, oldest first
+}
+
 type ContributorStat struct {
 	UserID  uuid.UUID `json:"user_id" db:"user_id"`
 	Actions int       `json:"actions" db:"actions"`
@@ -176,9 +319,10 @@ type JSON map[string]interface{}
 
 // DTOs
 type CreateWorkspaceRequest struct {
-	Name        string  `json:"name" binding:"required,min=2,max=100"`
-	Slug        string  `json:"slug" binding:"required,min=2,max=50"`
-	Description *string `json:"description"`
+	Name             string  `json:"name" binding:"required,min=2,max=100"`
+	Slug             string  `json:"slug" binding:"required,min=2,max=50"`
+	Description      *string `json:"description"`
+	SkipDefaultRoles bool    `json:"skip_default_roles"`
 }
 
 type UpdateWorkspaceRequest struct {
@@ -186,6 +330,12 @@ type UpdateWorkspaceRequest struct {
 	Description *string `json:"description"`
 	IconURL     *string `json:"icon_url"`
 	Settings    JSON    `json:"settings"`
+	Version     *int    `json:"version"` // when set, must match the current version
+}
+
+type SetWorkspaceIconRequest struct {
+	IconURL      string  `json:"icon_url" binding:"required"`
+	ThumbnailURL *string `json:"thumbnail_url"` // stored in the icon_thumbnail_url setting
 }
 
 type InviteMemberRequest struct {
@@ -201,6 +351,18 @@ type TransferOwnershipRequest struct {
 	NewOwnerID string `json:"new_owner_id" binding:"required"`
 }
 
+type OwnershipTransfer struct {
+	ID          uuid.UUID  `json:"id" db:"id"`
+	WorkspaceID uuid.UUID  `json:"workspace_id" db:"workspace_id"`
+	FromUserID  uuid.UUID  `json:"from_user_id" db:"from_user_id"`
+	ToUserID    uuid.UUID  `json:"to_user_id" db:"to_user_id"`
+	Status      string     `json:"status" db:"status"` // pending, accepted, canceled, expired
+	ExpiresAt   time.Time  `json:"expires_at" db:"expires_at"`
+	ResolvedBy  *uuid.UUID `json:"resolved_by" db:"resolved_by"`
+	ResolvedAt  *time.Time `json:"resolved_at" db:"resolved_at"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+}
+
 type JoinByCodeRequest struct {
 	InviteCode string `json:"invite_code" binding:"required"`
 }
@@ -209,8 +371,16 @@ type BulkInviteRequest struct {
 	Invites []InviteMemberRequest `json:"invites" binding:"required,min=1,max=50"`
 }
 
+type BulkRemoveMembersRequest struct {
+	UserIDs []string `json:"user_ids" binding:"required,min=1,max=100"`
+}
+
 // Response DTOs
 
+// WorkspaceResponse.ChannelCount (and WorkspaceStats.ChannelCount) come from
+// workspace_quotas.current_channels. Channels live in the channel service;
+// this service keeps the count from its channel-events topic (see
+// service.EventConsumer).
 type WorkspaceResponse struct {
 	Workspace    *Workspace `json:"workspace"`
 	MemberCount  int        `json:"member_count"`
@@ -242,6 +412,14 @@ type BulkInviteResponse struct {
 	} `json:"failed"`
 }
 
+type BulkRemoveMembersResponse struct {
+	Removed []string `json:"removed"`
+	Failed  []struct {
+		UserID string `json:"user_id"`
+		Reason string `json:"reason"`
+	} `json:"failed"`
+}
+
 // ── Workspace Templates ──
 
 type WorkspaceTemplate struct {
@@ -252,28 +430,124 @@ type WorkspaceTemplate struct {
 	DefaultRoles    JSON      `json:"default_roles" db:"default_roles"`
 	DefaultChannels JSON      `json:"default_channels" db:"default_channels"`
 	DefaultSettings JSON      `json:"default_settings" db:"default_settings"`
+	DefaultTags     JSON      `json:"default_tags" db:"default_tags"`
+	DefaultLabels   JSON      `json:"default_labels" db:"default_labels"`
 	IsPublic        bool      `json:"is_public" db:"is_public"`
-	UseCount        int       `json:"use_count" db:"use_count"`
+	UseCount        int       `json:"use_count" db:"use_count"` // across all versions
+	Version         int       `json:"version" db:"version"`
 	CreatedAt       time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt       time.Time `json:"updated_at" db:"updated_at"`
 }
 
-type CreateTemplateFromWorkspaceRequest struct {
-	Name        string  `json:"name" binding:"required,min=2,max=100"`
+// WorkspaceTemplateVersion is an immutable snapshot of a template's content
+// as of one version. The template row always holds the latest version.
+type WorkspaceTemplateVersion struct {
+	ID              uuid.UUID `json:"id" db:"id"`
+	TemplateID      uuid.UUID `json:"template_id" db:"template_id"`
+	Version         int       `json:"version" db:"version"`
+	Name            string    `json:"name" db:"name"`
+	Description     *string   `json:"description" db:"description"`
+	DefaultRoles    JSON      `json:"default_roles" db:"default_roles"`
+	DefaultChannels JSON      `json:"default_channels" db:"default_channels"`
+	DefaultSettings JSON      `json:"default_settings" db:"default_settings"`
+	DefaultTags     JSON      `json:"default_tags" db:"default_tags"`
+	DefaultLabels   JSON      `json:"default_labels" db:"default_labels"`
+	CreatedBy       uuid.UUID `json:"created_by" db:"created_by"`
+	CreatedAt       time.Time `json:"created_at" db:"created_at"`
+}
+
+// WorkspaceTemplateShare grants one user visibility of a private template.
+type WorkspaceTemplateShare struct {
+	TemplateID uuid.UUID `json:"template_id" db:"template_id"`
+	UserID     uuid.UUID `json:"user_id" db:"user_id"`
+	SharedBy   uuid.UUID `json:"shared_by" db:"shared_by"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+}
+
+type ShareTemplateRequest struct {
+	UserID string `json:"user_id" binding:"required"`
+}
+
+// TemplateFilter narrows and orders template listings. Empty fields use the
+// defaults: all names, most used first.
+type TemplateFilter struct {
+	Query string // matched against the template name
+	Sort  string // use_count or created_at
+	Order string // asc or desc
+}
+
+// TemplateSpec is the typed content of a template: everything creating a
+// workspace from it provisions. It is stored across the template's default_*
+// columns, each holding an object keyed by section name.
+type TemplateSpec struct {
+	Roles    []TemplateRole    `json:"roles"`
+	Channels []TemplateChannel `json:"channels"`
+	Tags     []TemplateTag     `json:"tags"`
+	Labels   []TemplateLabel   `json:"labels"`
+	Settings JSON              `json:"settings"`
+}
+
+type TemplateRole struct {
+	Name        string  `json:"name"`
+	Color       *string `json:"color"`
+	Priority    int     `json:"priority"`
+	Permissions JSON    `json:"permissions"`
+	IsDefault   bool    `json:"is_default"`
+}
+
+// TemplateChannel is provisioned by the channel service, which is asked to
+// create it through an event.
+type TemplateChannel struct {
+	Name        string  `json:"name"`
 	Description *string `json:"description"`
-	IsPublic    bool    `json:"is_public"`
+	IsPrivate   bool    `json:"is_private"`
+}
+
+type TemplateTag struct {
+	Name  string  `json:"name"`
+	Color *string `json:"color"`
+}
+
+type TemplateLabel struct {
+	Name        string  `json:"name"`
+	Color       string  `json:"color"`
+	Description *string `json:"description"`
+}
+
+type CreateTemplateFromWorkspaceRequest struct {
+	Name        string            `json:"name" binding:"required,min=2,max=100"`
+	Description *string           `json:"description"`
+	IsPublic    bool              `json:"is_public"`
+	Channels    []TemplateChannel `json:"channels"` // channels are not stored here, so they are listed explicitly
 }
 
 type CreateWorkspaceFromTemplateRequest struct {
 	TemplateID string  `json:"template_id" binding:"required"`
 	Name       string  `json:"name" binding:"required,min=2,max=100"`
 	Slug       string  `json:"slug" binding:"required,min=2,max=50"`
+	Version    *int    `json:"version"` // defaults to the latest
+}
+
+// TemplatePreview is what creating a workspace from a template would
+// provision. Problems lists content that would be skipped or fail.
+type TemplatePreview struct {
+	TemplateID uuid.UUID         `json:"template_id"`
+	Name       string            `json:"name"`
+	Valid      bool              `json:"valid"`
+	Roles      []TemplateRole    `json:"roles"`
+	Channels   []TemplateChannel `json:"channels"`
+	Tags       []TemplateTag     `json:"tags"`
+	Labels     []TemplateLabel   `json:"labels"`
+	Settings   JSON              `json:"settings"`
+	Problems   map[string]string `json:"problems,omitempty"`
 }
 
 type UpdateTemplateRequest struct {
-	Name        *string `json:"name"`
-	Description *string `json:"description"`
-	IsPublic    *bool   `json:"is_public"`
+	Name        *string       `json:"name"`
+	Description *string       `json:"description"`
+	IsPublic    *bool         `json:"is_public"`
+	Spec        *TemplateSpec `json:"spec"`
+	Version     *int          `json:"version"` // expected current version, for optimistic concurrency
 }
 
 // ── Member Preferences ──
@@ -285,12 +559,21 @@ type WorkspaceMemberPreference struct {
 	NotificationLevel  string     `json:"notification_level" db:"notification_level"`
 	EmailNotifications bool       `json:"email_notifications" db:"email_notifications"`
 	MuteUntil          *time.Time `json:"mute_until" db:"mute_until"`
+	IsMuted            bool       `json:"is_muted" db:"-"` // derived from mute_until at read time
 	SidebarPosition    int        `json:"sidebar_position" db:"sidebar_position"`
 	Theme              *string    `json:"theme" db:"theme"`
 	CreatedAt          time.Time  `json:"created_at" db:"created_at"`
 	UpdatedAt          time.Time  `json:"updated_at" db:"updated_at"`
 }
 
+type NotificationDecision struct {
+	UserID    uuid.UUID `json:"user_id"`
+	EventType string    `json:"event_type"`
+	Channel   string    `json:"channel"`
+	Notify    bool      `json:"notify"`
+	Reason    string    `json:"reason"` // default, allowed, muted, notifications_off, mentions_only, email_disabled, not_member
+}
+
 type UpdatePreferencesRequest struct {
 	NotificationLevel  *string    `json:"notification_level" binding:"omitempty,oneof=all mentions none"`
 	EmailNotifications *bool      `json:"email_notifications"`
@@ -367,10 +650,14 @@ type WorkspaceAnnouncement struct {
 	WorkspaceID uuid.UUID  `json:"workspace_id" db:"workspace_id"`
 	Title       string     `json:"title" db:"title"`
 	Content     string     `json:"content" db:"content"`
-	Priority    string     `json:"priority" db:"priority"`
+	Priority    string     `json:"priority" db:"priority"` // low, normal, high, urgent
 	AuthorID    uuid.UUID  `json:"author_id" db:"author_id"`
 	IsPinned    bool       `json:"is_pinned" db:"is_pinned"`
+	PinPosition *int       `json:"pin_position" db:"pin_position"`
 	ExpiresAt   *time.Time `json:"expires_at" db:"expires_at"`
+	IsPublished bool       `json:"is_published" db:"is_published"`
+	PublishAt   *time.Time `json:"publish_at" db:"publish_at"` // when a scheduled announcement goes out
+	Version     int        `json:"version" db:"version"`
 	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
 	UpdatedAt   time.Time  `json:"updated_at" db:"updated_at"`
 }
@@ -378,22 +665,39 @@ type WorkspaceAnnouncement struct {
 type CreateAnnouncementRequest struct {
 	Title     string     `json:"title" binding:"required,min=1,max=200"`
 	Content   string     `json:"content" binding:"required,min=1"`
-	Priority  string     `json:"priority" binding:"required,oneof=normal important urgent"`
+	Priority  string     `json:"priority" binding:"omitempty,oneof=low normal high urgent important"` // defaults to the workspace default
 	IsPinned  bool       `json:"is_pinned"`
 	ExpiresAt *time.Time `json:"expires_at"`
+	PublishAt *time.Time `json:"publish_at"` // a future time holds the announcement back until then
 }
 
 type UpdateAnnouncementRequest struct {
 	Title     *string    `json:"title"`
 	Content   *string    `json:"content"`
-	Priority  *string    `json:"priority" binding:"omitempty,oneof=normal important urgent"`
+	Priority  *string    `json:"priority" binding:"omitempty,oneof=low normal high urgent important"`
 	ExpiresAt *time.Time `json:"expires_at"`
+	Version   *int       `json:"version"` // when set, must match the current version
+}
+
+// AnnouncementFilter narrows announcement listings. Empty fields match all.
+type AnnouncementFilter struct {
+	Query    string // matched against title and content
+	Priority string
+	ViewerID uuid.UUID // sees their own unpublished announcements
+}
+
+type SetDefaultAnnouncementPriorityRequest struct {
+	Priority string `json:"priority" binding:"required,oneof=low normal high urgent"`
 }
 
 type PinAnnouncementRequest struct {
 	IsPinned bool `json:"is_pinned"`
 }
 
+type ReorderAnnouncementPinsRequest struct {
+	AnnouncementIDs []string `json:"announcement_ids" binding:"required,min=1"`
+}
+
 // ── Workspace Webhooks ──
 
 type WorkspaceWebhook struct {
@@ -424,6 +728,54 @@ type UpdateWebhookRequest struct {
 	IsActive *bool    `json:"is_active"`
 }
 
+// WorkspaceAPIKey lets an integration call the API for one workspace. Only
+// a SHA-256 hash of the key is stored; the key itself is shown once.
+type WorkspaceAPIKey struct {
+	ID          uuid.UUID  `json:"id" db:"id"`
+	WorkspaceID uuid.UUID  `json:"workspace_id" db:"workspace_id"`
+	Name        string     `json:"name" db:"name"`
+	KeyPrefix   string     `json:"key_prefix" db:"key_prefix"`
+	KeyHash     string     `json:"-" db:"key_hash"`
+	Permissions JSON       `json:"permissions" db:"permissions"`
+	CreatedBy   uuid.UUID  `json:"created_by" db:"created_by"`
+	LastUsedAt  *time.Time `json:"last_used_at" db:"last_used_at"`
+	RevokedAt   *time.Time `json:"revoked_at" db:"revoked_at"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+}
+
+// HasPermission reports whether the key was granted a permission key.
+func (k *WorkspaceAPIKey) HasPermission(permission string) bool {
+	granted, _ := k.Permissions[permission].(bool)
+	return granted
+}
+
+type CreateAPIKeyRequest struct {
+	Name        string   `json:"name" binding:"required,min=1,max=100"`
+	Permissions []string `json:"permissions" binding:"max=50"`
+}
+
+// CreateAPIKeyResponse is the only place the plaintext key is returned.
+type CreateAPIKeyResponse struct {
+	APIKey *WorkspaceAPIKey `json:"api_key"`
+	Key    string           `json:"key"`
+}
+
+type WebhookHealth struct {
+	WebhookID       uuid.UUID  `json:"webhook_id"`
+	Name            string     `json:"name"`
+	URL             string     `json:"url"`
+	FailureCount    int        `json:"failure_count"`
+	LastTriggeredAt *time.Time `json:"last_triggered_at"`
+	Status          string     `json:"status"` // healthy, degraded, disabled
+}
+
+type WebhookHealthReport struct {
+	Webhooks []*WebhookHealth `json:"webhooks"`
+	Healthy  int              `json:"healthy"`
+	Degraded int              `json:"degraded"`
+	Disabled int              `json:"disabled"`
+}
+
 // ── Workspace Favorites ──
 
 type WorkspaceFavorite struct {
@@ -677,6 +1029,18 @@ type CustomFieldWithValue struct {
 	Value *string `json:"value,omitempty"`
 }
 
+type CustomFieldFacet struct {
+	Value string `json:"value" db:"value"`
+	Count int    `json:"count" db:"count"`
+}
+
+type MemberFacetsResponse struct {
+	FieldID   uuid.UUID           `json:"field_id"`
+	FieldName string              `json:"field_name"`
+	Facets    []*CustomFieldFacet `json:"facets"`
+	Total     int                 `json:"total"`
+}
+
 // ── Workspace Reactions ──
 
 type WorkspaceReaction struct {
@@ -694,6 +1058,18 @@ type AddReactionRequest struct {
 	Emoji      string `json:"emoji" binding:"required,min=1,max=10"`
 }
 
+type ReactedEntity struct {
+	EntityType string    `json:"entity_type"`
+	EntityID   uuid.UUID `json:"entity_id"`
+	ReactedAt  time.Time `json:"reacted_at"`
+}
+
+type EmojiReactionGroup struct {
+	Emoji    string           `json:"emoji"`
+	Count    int              `json:"count"`
+	Entities []*ReactedEntity `json:"entities"`
+}
+
 type ReactionSummary struct {
 	Emoji string      `json:"emoji" db:"emoji"`
 	Count int         `json:"count" db:"count"`
@@ -733,6 +1109,36 @@ type UpdateBookmarkRequest struct {
 	FolderName *string `json:"folder_name"`
 }
 
+// RemapBookmarkFoldersRequest maps old folder names to new ones. Mapping two
+// folders to the same name merges them; an empty new name unfiles the bookmarks.
+type RemapBookmarkFoldersRequest struct {
+	Mapping map[string]string `json:"mapping" binding:"required,min=1"`
+}
+
+type RemapBookmarkFoldersResponse struct {
+	Updated int64    `json:"updated"`
+	Folders []string `json:"folders"`
+}
+
+type RenameBookmarkFolderRequest struct {
+	OldName string `json:"old_name" binding:"required"`
+	NewName string `json:"new_name" binding:"required"`
+}
+
+// DeleteBookmarkFolderRequest deletes a folder. With MoveToUnfiled the
+// folder's bookmarks are kept without a folder instead of being deleted.
+type DeleteBookmarkFolderRequest struct {
+	Name          string `json:"name" binding:"required"`
+	MoveToUnfiled bool   `json:"move_to_unfiled"`
+}
+
+// BookmarkFolderResponse reports how many bookmarks a folder change touched
+// and the caller's folders afterwards.
+type BookmarkFolderResponse struct {
+	Updated int64    `json:"updated"`
+	Folders []string `json:"folders"`
+}
+
 // ── Invitation Tracking ──
 
 type InvitationHistory struct {
@@ -749,6 +1155,30 @@ type InvitationHistory struct {
 	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
 }
 
+type InviteQuota struct {
+	Limit       int        `json:"limit"`
+	Used        int        `json:"used"`
+	Remaining   int        `json:"remaining"`
+	WindowHours int        `json:"window_hours"`
+	ResetsAt    *time.Time `json:"resets_at"`
+	Unlimited   bool       `json:"unlimited"`
+}
+
+type MemberDataExport struct {
+	WorkspaceID      uuid.UUID                  `json:"workspace_id"`
+	UserID           uuid.UUID                  `json:"user_id"`
+	ExportedAt       time.Time                  `json:"exported_at"`
+	Membership       *WorkspaceMember           `json:"membership"`
+	Roles            []*WorkspaceRole           `json:"roles"`
+	Profile          *MemberProfile             `json:"profile"`
+	Preferences      *WorkspaceMemberPreference `json:"preferences"`
+	NotesAboutMember []*MemberNote              `json:"notes_about_member"`
+	Activity         []*ActivityLog             `json:"activity"`
+	Reactions        []*WorkspaceReaction       `json:"reactions"`
+	Bookmarks        []*WorkspaceBookmark       `json:"bookmarks"`
+	Onboarding       []*UserOnboardingStatus    `json:"onboarding"`
+}
+
 type InvitationHistoryResponse struct {
 	Invitations []*InvitationHistory `json:"invitations"`
 	Total       int64                `json:"total"`
@@ -764,6 +1194,21 @@ type InvitationStats struct {
 	ByMethod      map[string]int `json:"by_method"`
 }
 
+type InviteFunnelStage struct {
+	Sent           int     `json:"sent"`
+	Accepted       int     `json:"accepted"`
+	Expired        int     `json:"expired"`
+	Pending        int     `json:"pending"`
+	Revoked        int     `json:"revoked"`
+	ConversionRate float64 `json:"conversion_rate"` // percentage of sent invites accepted
+}
+
+type InviteFunnel struct {
+	Days     int                           `json:"days"`
+	Total    *InviteFunnelStage            `json:"total"`
+	ByMethod map[string]*InviteFunnelStage `json:"by_method"`
+}
+
 // ── Workspace Access Logs ──
 
 type WorkspaceAccessLog struct {
@@ -799,29 +1244,46 @@ type ResourceStat struct {
 // ── Workspace Feature Flags ──
 
 type WorkspaceFeatureFlag struct {
-	ID          uuid.UUID  `json:"id" db:"id"`
-	WorkspaceID uuid.UUID  `json:"workspace_id" db:"workspace_id"`
-	Key         string     `json:"key" db:"key"`
-	Enabled     bool       `json:"enabled" db:"enabled"`
-	Description *string    `json:"description" db:"description"`
-	Metadata    JSON       `json:"metadata" db:"metadata"`
-	CreatedBy   uuid.UUID  `json:"created_by" db:"created_by"`
-	UpdatedBy   *uuid.UUID `json:"updated_by" db:"updated_by"`
-	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
-	UpdatedAt   time.Time  `json:"updated_at" db:"updated_at"`
+	ID                uuid.UUID  `json:"id" db:"id"`
+	WorkspaceID       uuid.UUID  `json:"workspace_id" db:"workspace_id"`
+	Key               string     `json:"key" db:"key"`
+	Enabled           bool       `json:"enabled" db:"enabled"`
+	RolloutPercentage int        `json:"rollout_percentage" db:"rollout_percentage"` // share of members an enabled flag is on for, 0-100
+	Description       *string    `json:"description" db:"description"`
+	Metadata          JSON       `json:"metadata" db:"metadata"`
+	CreatedBy         uuid.UUID  `json:"created_by" db:"created_by"`
+	UpdatedBy         *uuid.UUID `json:"updated_by" db:"updated_by"`
+	CreatedAt         time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// FeatureFlagHistoryEntry records one change to a flag's state. Enabled and
+// RolloutPercentage are the values after the change.
+type FeatureFlagHistoryEntry struct {
+	ID                uuid.UUID `json:"id" db:"id"`
+	WorkspaceID       uuid.UUID `json:"workspace_id" db:"workspace_id"`
+	FlagID            uuid.UUID `json:"flag_id" db:"flag_id"`
+	FlagKey           string    `json:"flag_key" db:"flag_key"`
+	Action            string    `json:"action" db:"action"` // created, enabled, disabled, rollout_changed
+	Enabled           bool      `json:"enabled" db:"enabled"`
+	RolloutPercentage int       `json:"rollout_percentage" db:"rollout_percentage"`
+	ChangedBy         uuid.UUID `json:"changed_by" db:"changed_by"`
+	CreatedAt         time.Time `json:"created_at" db:"created_at"`
 }
 
 type CreateFeatureFlagRequest struct {
-	Key         string  `json:"key" binding:"required,min=1,max=100"`
-	Enabled     bool    `json:"enabled"`
-	Description *string `json:"description"`
-	Metadata    JSON    `json:"metadata"`
+	Key               string  `json:"key" binding:"required,min=1,max=100"`
+	Enabled           bool    `json:"enabled"`
+	RolloutPercentage *int    `json:"rollout_percentage" binding:"omitempty,min=0,max=100"` // defaults to 100
+	Description       *string `json:"description"`
+	Metadata          JSON    `json:"metadata"`
 }
 
 type UpdateFeatureFlagRequest struct {
-	Enabled     *bool   `json:"enabled"`
-	Description *string `json:"description"`
-	Metadata    JSON    `json:"metadata"`
+	Enabled           *bool   `json:"enabled"`
+	RolloutPercentage *int    `json:"rollout_percentage" binding:"omitempty,min=0,max=100"`
+	Description       *string `json:"description"`
+	Metadata          JSON    `json:"metadata"`
 }
 
 type FeatureFlagCheckResponse struct {
@@ -829,26 +1291,42 @@ type FeatureFlagCheckResponse struct {
 	Enabled bool   `json:"enabled"`
 }
 
+type BatchCheckFeatureFlagsRequest struct {
+	Keys []string `json:"keys" binding:"required,min=1,max=100,dive,min=1,max=100"`
+}
+
 // ── Workspace Integrations ──
 
 type WorkspaceIntegration struct {
-	ID           uuid.UUID  `json:"id" db:"id"`
-	WorkspaceID  uuid.UUID  `json:"workspace_id" db:"workspace_id"`
-	Provider     string     `json:"provider" db:"provider"` // slack, github, jira, discord, etc.
-	Name         string     `json:"name" db:"name"`
-	Status       string     `json:"status" db:"status"` // active, inactive, error
-	Config       JSON       `json:"config" db:"config"`
-	Credentials  *string    `json:"-" db:"credentials"` // encrypted, never exposed in JSON
-	WebhookURL   *string    `json:"webhook_url" db:"webhook_url"`
-	LastSyncAt   *time.Time `json:"last_sync_at" db:"last_sync_at"`
-	ErrorMessage *string    `json:"error_message" db:"error_message"`
-	CreatedBy    uuid.UUID  `json:"created_by" db:"created_by"`
-	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
-	UpdatedAt    time.Time  `json:"updated_at" db:"updated_at"`
+	ID                uuid.UUID  `json:"id" db:"id"`
+	WorkspaceID       uuid.UUID  `json:"workspace_id" db:"workspace_id"`
+	Provider          string     `json:"provider" db:"provider"` // slack, github, jira, discord, etc.
+	Name              string     `json:"name" db:"name"`
+	Status            string     `json:"status" db:"status"` // active, inactive, error
+	Config            JSON       `json:"config" db:"config"`
+	Credentials       *string    `json:"-" db:"credentials"`           // encrypted, never exposed in JSON
+	CredentialsMasked string     `json:"credentials,omitempty" db:"-"` // fixed mask when credentials are set
+	WebhookURL        *string    `json:"webhook_url" db:"webhook_url"`
+	LastSyncAt        *time.Time `json:"last_sync_at" db:"last_sync_at"`
+	ErrorMessage      *string    `json:"error_message" db:"error_message"`
+	CreatedBy         uuid.UUID  `json:"created_by" db:"created_by"`
+	CreatedAt         time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// IntegrationProvider describes a supported integration provider and what
+// creating an integration for it requires.
+type IntegrationProvider struct {
+	Provider            string   `json:"provider"`
+	DisplayName         string   `json:"display_name"`
+	RequiredConfig      []string `json:"required_config"`
+	RequiresCredentials bool     `json:"requires_credentials"`
+	CredentialsLabel    string   `json:"credentials_label"`
+	SupportsSync        bool     `json:"supports_sync"`
 }
 
 type CreateIntegrationRequest struct {
-	Provider string  `json:"provider" binding:"required,oneof=slack github jira discord linear notion"`
+	Provider string  `json:"provider" binding:"required"` // validated against the provider registry
 	Name     string  `json:"name" binding:"required,min=1,max=100"`
 	Config   JSON    `json:"config"`
 	Credentials *string `json:"credentials"`
@@ -861,6 +1339,36 @@ type UpdateIntegrationRequest struct {
 	Credentials *string `json:"credentials"`
 }
 
+// IntegrationSyncStatus reports the outcome of an integration's last sync.
+// Supported is false for providers that cannot be synced.
+type IntegrationSyncStatus struct {
+	IntegrationID uuid.UUID  `json:"integration_id"`
+	Provider      string     `json:"provider"`
+	Status        string     `json:"status"`
+	Supported     bool       `json:"supported"`
+	LastSyncAt    *time.Time `json:"last_sync_at"`
+	ErrorMessage  *string    `json:"error_message"`
+}
+
+// RotateIntegrationCredentialsRequest optionally replaces the secret while
+// rotating; without it the existing secret is re-encrypted.
+type RotateIntegrationCredentialsRequest struct {
+	Credentials *string `json:"credentials"`
+}
+
+type ConfigureAuditSinkRequest struct {
+	URL       string `json:"url" binding:"required,url,max=500"`
+	Secret    string `json:"secret" binding:"required,min=16"`
+	BatchSize *int   `json:"batch_size" binding:"omitempty,min=1,max=500"`
+	Enabled   *bool  `json:"enabled"`
+}
+
+// AuditSinkRecord is a single forwarded entry; Source is activity_log or security_audit.
+type AuditSinkRecord struct {
+	Source string      `json:"source"`
+	Entry  interface{} `json:"entry"`
+}
+
 // ── Workspace Labels ──
 
 type WorkspaceLabel struct {
@@ -912,14 +1420,15 @@ type StreakLeaderboard struct {
 // ── Onboarding Checklists ──
 
 type OnboardingChecklist struct {
-	ID          uuid.UUID `json:"id" db:"id"`
-	WorkspaceID uuid.UUID `json:"workspace_id" db:"workspace_id"`
-	Title       string    `json:"title" db:"title"`
-	Description *string   `json:"description" db:"description"`
-	IsActive    bool      `json:"is_active" db:"is_active"`
-	CreatedBy   uuid.UUID `json:"created_by" db:"created_by"`
-	CreatedAt   time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+	ID           uuid.UUID `json:"id" db:"id"`
+	WorkspaceID  uuid.UUID `json:"workspace_id" db:"workspace_id"`
+	Title        string    `json:"title" db:"title"`
+	Description  *string   `json:"description" db:"description"`
+	IsActive     bool      `json:"is_active" db:"is_active"`
+	RequiredOnly bool      `json:"required_only" db:"required_only"` // completion counts required steps only
+	CreatedBy    uuid.UUID `json:"created_by" db:"created_by"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
 }
 
 type OnboardingStep struct {
@@ -943,14 +1452,16 @@ type OnboardingProgress struct {
 }
 
 type CreateChecklistRequest struct {
-	Title       string  `json:"title" binding:"required,min=1,max=200"`
-	Description *string `json:"description"`
+	Title        string  `json:"title" binding:"required,min=1,max=200"`
+	Description  *string `json:"description"`
+	RequiredOnly bool    `json:"required_only"`
 }
 
 type UpdateChecklistRequest struct {
-	Title       *string `json:"title"`
-	Description *string `json:"description"`
-	IsActive    *bool   `json:"is_active"`
+	Title        *string `json:"title"`
+	Description  *string `json:"description"`
+	IsActive     *bool   `json:"is_active"`
+	RequiredOnly *bool   `json:"required_only"`
 }
 
 type AddStepRequest struct {
@@ -961,6 +1472,18 @@ type AddStepRequest struct {
 	IsRequired  bool    `json:"is_required"`
 }
 
+type UpdateStepRequest struct {
+	Title       *string `json:"title" binding:"omitempty,min=1,max=200"`
+	Description *string `json:"description"`
+	ActionType  *string `json:"action_type" binding:"omitempty,oneof=link task acknowledgement"`
+	ActionData  *string `json:"action_data"`
+	IsRequired  *bool   `json:"is_required"`
+}
+
+type ReorderStepsRequest struct {
+	StepIDs []string `json:"step_ids" binding:"required,min=1"`
+}
+
 type ChecklistWithSteps struct {
 	OnboardingChecklist
 	Steps []OnboardingStep `json:"steps"`
@@ -971,46 +1494,82 @@ type UserOnboardingStatus struct {
 	Steps          []StepWithProgress  `json:"steps"`
 	CompletedCount int                 `json:"completed_count"`
 	TotalSteps     int                 `json:"total_steps"`
+	Percentage     float64             `json:"percentage"`
 	IsComplete     bool                `json:"is_complete"`
 }
 
+type ChecklistCompletionStats struct {
+	Checklist      OnboardingChecklist `json:"checklist"`
+	TotalSteps     int                 `json:"total_steps"`
+	StartedCount   int                 `json:"started_count"`
+	CompletedCount int                 `json:"completed_count"`
+	CompletionRate float64             `json:"completion_rate"` // percentage of active members
+}
+
+type WorkspaceOnboardingStats struct {
+	MemberCount int                         `json:"member_count"`
+	Checklists  []*ChecklistCompletionStats `json:"checklists"`
+}
+
 type StepWithProgress struct {
 	OnboardingStep
 	Completed   bool       `json:"completed"`
 	CompletedAt *time.Time `json:"completed_at"`
 }
 
+type ReadinessItem struct {
+	Type        string     `json:"type"` // onboarding_step, policy_acknowledgement
+	ID          uuid.UUID  `json:"id"`
+	Title       string     `json:"title"`
+	ChecklistID *uuid.UUID `json:"checklist_id,omitempty"`
+}
+
+type MemberReadiness struct {
+	Percentage           int              `json:"percentage"` // 0-100, only 100 once every item is done
+	IsReady              bool             `json:"is_ready"`
+	CompletedItems       int              `json:"completed_items"`
+	TotalItems           int              `json:"total_items"`
+	OnboardingCompleted  int              `json:"onboarding_completed"`
+	OnboardingTotal      int              `json:"onboarding_total"`
+	PoliciesAcknowledged int              `json:"policies_acknowledged"`
+	PoliciesTotal        int              `json:"policies_total"`
+	Remaining            []*ReadinessItem `json:"remaining"`
+}
+
 // ── Compliance Policies ──
 
 type CompliancePolicy struct {
-	ID          uuid.UUID `json:"id" db:"id"`
-	WorkspaceID uuid.UUID `json:"workspace_id" db:"workspace_id"`
-	Name        string    `json:"name" db:"name"`
-	Description *string   `json:"description" db:"description"`
-	PolicyType  string    `json:"policy_type" db:"policy_type"` // data_retention, access_control, content_policy, privacy
-	Rules       JSON      `json:"rules" db:"rules"`
-	Severity    string    `json:"severity" db:"severity"` // info, warning, critical
-	IsEnforced  bool      `json:"is_enforced" db:"is_enforced"`
-	CreatedBy   uuid.UUID `json:"created_by" db:"created_by"`
-	CreatedAt   time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+	ID                uuid.UUID `json:"id" db:"id"`
+	WorkspaceID       uuid.UUID `json:"workspace_id" db:"workspace_id"`
+	Name              string    `json:"name" db:"name"`
+	Description       *string   `json:"description" db:"description"`
+	PolicyType        string    `json:"policy_type" db:"policy_type"` // data_retention, access_control, content_policy, privacy, acknowledgement
+	Rules             JSON      `json:"rules" db:"rules"`
+	Severity          string    `json:"severity" db:"severity"` // info, warning, critical
+	IsEnforced        bool      `json:"is_enforced" db:"is_enforced"`
+	ReackIntervalDays *int      `json:"reack_interval_days" db:"reack_interval_days"` // acknowledgements older than this expire
+	CreatedBy         uuid.UUID `json:"created_by" db:"created_by"`
+	CreatedAt         time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at" db:"updated_at"`
 }
 
 type CreatePolicyRequest struct {
-	Name        string  `json:"name" binding:"required,min=1,max=200"`
-	Description *string `json:"description"`
-	PolicyType  string  `json:"policy_type" binding:"required,oneof=data_retention access_control content_policy privacy"`
-	Rules       JSON    `json:"rules"`
-	Severity    string  `json:"severity" binding:"required,oneof=info warning critical"`
-	IsEnforced  bool    `json:"is_enforced"`
+	Name              string  `json:"name" binding:"required,min=1,max=200"`
+	Description       *string `json:"description"`
+	PolicyType        string  `json:"policy_type" binding:"required,oneof=data_retention access_control content_policy privacy acknowledgement"`
+	Rules             JSON    `json:"rules"`
+	Severity          string  `json:"severity" binding:"required,oneof=info warning critical"`
+	IsEnforced        bool    `json:"is_enforced"`
+	ReackIntervalDays *int    `json:"reack_interval_days" binding:"omitempty,min=1,max=3650"`
 }
 
 type UpdatePolicyRequest struct {
-	Name        *string `json:"name"`
-	Description *string `json:"description"`
-	Rules       JSON    `json:"rules"`
-	Severity    *string `json:"severity" binding:"omitempty,oneof=info warning critical"`
-	IsEnforced  *bool   `json:"is_enforced"`
+	Name              *string `json:"name"`
+	Description       *string `json:"description"`
+	Rules             JSON    `json:"rules"`
+	Severity          *string `json:"severity" binding:"omitempty,oneof=info warning critical"`
+	IsEnforced        *bool   `json:"is_enforced"`
+	ReackIntervalDays *int    `json:"reack_interval_days" binding:"omitempty,min=0,max=3650"` // 0 clears the interval
 }
 
 type PolicyAcknowledgement struct {
@@ -1026,3 +1585,92 @@ type PolicyComplianceStatus struct {
 	AcknowledgedCount int             `json:"acknowledged_count"`
 	ComplianceRate   float64          `json:"compliance_rate"`
 }
+
+type PolicyAcknowledgementRow struct {
+	UserID       uuid.UUID  `json:"user_id"`
+	Role         string     `json:"role"`
+	Acknowledged bool       `json:"acknowledged"`
+	Expired      bool       `json:"expired"`
+	AckedAt      *time.Time `json:"acked_at"`
+}
+
+type PolicyAcknowledgementExport struct {
+	PolicyID          uuid.UUID                   `json:"policy_id"`
+	PolicyName        string                      `json:"policy_name"`
+	Members           []*PolicyAcknowledgementRow `json:"members"`
+	TotalMembers      int                         `json:"total_members"`
+	AcknowledgedCount int                         `json:"acknowledged_count"`
+	ExportedAt        time.Time                   `json:"exported_at"`
+}
+
+// ── Events ──
+
+// EventSchemaVersion is the version of the envelope wrapping every event
+// published to Kafka. Bump it when the envelope or payload shapes change
+// incompatibly.
+const EventSchemaVersion = 1
+
+type EventEnvelope struct {
+	SchemaVersion int                    `json:"schema_version"`
+	Type          string                 `json:"type"`
+	Timestamp     time.Time              `json:"timestamp"`
+	RequestID     string                 `json:"request_id,omitempty"`
+	Data          map[string]interface{} `json:"data"`
+}
+
+// Kinds of externally owned entities whose usage is tracked from events.
+const (
+	UsageKindChannel = "channel"
+	UsageKindFile    = "file"
+)
+
+// UsageEvent is a channel or file change reported by another service,
+// reduced to what the workspace quotas need.
+type UsageEvent struct {
+	WorkspaceID uuid.UUID
+	Kind        string
+	EntityID    string
+	SizeBytes   int64
+	Deleted     bool
+	OccurredAt  time.Time
+}
+
+// OutboxEvent is an event waiting in the transactional outbox to be relayed
+// to Kafka. Payload holds the serialized EventEnvelope.
+type OutboxEvent struct {
+	ID             uuid.UUID  `json:"id" db:"id"`
+	Topic          string     `json:"topic" db:"topic"`
+	EventKey       string     `json:"event_key" db:"event_key"`
+	EventType      string     `json:"event_type" db:"event_type"`
+	Payload        []byte     `json:"-" db:"payload"`
+	Attempts       int        `json:"attempts" db:"attempts"`
+	LastError      *string    `json:"last_error" db:"last_error"`
+	ClaimedBy      *string    `json:"-" db:"claimed_by"`
+	ClaimedUntil   *time.Time `json:"-" db:"claimed_until"`
+	DeadLetteredAt *time.Time `json:"dead_lettered_at" db:"dead_lettered_at"`
+	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
+	PublishedAt    *time.Time `json:"published_at" db:"published_at"`
+}
+
+type OutboxStats struct {
+	Pending         int        `json:"pending" db:"pending"`
+	Failing         int        `json:"failing" db:"failing"`
+	DeadLettered    int        `json:"dead_lettered" db:"dead_lettered"`
+	OldestPendingAt *time.Time `json:"oldest_pending_at" db:"oldest_pending_at"`
+	LagSeconds      float64    `json:"lag_seconds" db:"-"`
+}
+
+// ── Health ──
+
+type DependencyStatus struct {
+	Name      string `json:"name"`
+	Status    string `json:"status"` // up, down
+	Required  bool   `json:"required"`
+	LatencyMs int64  `json:"latency_ms"`
+}
+
+type ReadinessReport struct {
+	Status       string              `json:"status"` // ready, degraded, unready
+	Dependencies []*DependencyStatus `json:"dependencies"`
+	CheckedAt    time.Time           `json:"checked_at"`
+}
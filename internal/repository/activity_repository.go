@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"database/sql"
 	"time"
 
 	"github.com/google/uuid"
@@ -42,6 +43,56 @@ func (r *ActivityRepository) ListByWorkspace(ctx context.Context, workspaceID uu
 	return activities, total, err
 }
 
+func (r *ActivityRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.ActivityLog, error) {
+	var activity models.ActivityLog
+	err := r.db.GetContext(ctx, &activity, "SELECT * FROM workspace_activity_log WHERE id = ?", id)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return &activity, err
+}
+
+// Pin is idempotent: pinning an already pinned entry is a no-op.
+func (r *ActivityRepository) Pin(ctx context.Context, pin *models.PinnedActivity) error {
+	query := `INSERT IGNORE INTO workspace_pinned_activity (workspace_id, activity_id, pinned_by, created_at) VALUES (?, ?, ?, ?)`
+	_, err := r.db.ExecContext(ctx, query, pin.WorkspaceID, pin.ActivityID, pin.PinnedBy, pin.CreatedAt)
+	return err
+}
+
+func (r *ActivityRepository) Unpin(ctx context.Context, workspaceID, activityID uuid.UUID) (int64, error) {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM workspace_pinned_activity WHERE workspace_id = ? AND activity_id = ?", workspaceID, activityID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func (r *ActivityRepository) IsPinned(ctx context.Context, workspaceID, activityID uuid.UUID) (bool, error) {
+	var count int
+	err := r.db.GetContext(ctx, &count, "SELECT COUNT(*) FROM workspace_pinned_activity WHERE workspace_id = ? AND activity_id = ?", workspaceID, activityID)
+	return count > 0, err
+}
+
+func (r *ActivityRepository) CountPinned(ctx context.Context, workspaceID uuid.UUID) (int, error) {
+	var count int
+	err := r.db.GetContext(ctx, &count, "SELECT COUNT(*) FROM workspace_pinned_activity WHERE workspace_id = ?", workspaceID)
+	return count, err
+}
+
+// ListPinned returns the workspace's pinned entries, most recently pinned
+// first.
+func (r *ActivityRepository) ListPinned(ctx context.Context, workspaceID uuid.UUID) ([]*models.ActivityLog, error) {
+	var activities []*models.ActivityLog
+	query := `
+		SELECT a.* FROM workspace_activity_log a
+		JOIN workspace_pinned_activity p ON p.activity_id = a.id AND p.workspace_id = a.workspace_id
+		WHERE a.workspace_id = ?
+		ORDER BY p.created_at DESC
+	`
+	err := r.db.SelectContext(ctx, &activities, query, workspaceID)
+	return activities, err
+}
+
 func (r *ActivityRepository) ListByActor(ctx context.Context, workspaceID, actorID uuid.UUID, page, perPage int) ([]*models.ActivityLog, int64, error) {
 	var activities []*models.ActivityLog
 	var total int64
@@ -74,6 +125,15 @@ func (r *ActivityRepository) ListByAction(ctx context.Context, workspaceID uuid.
 	return activities, total, err
 }
 
+// ListActorsSince returns the distinct members with activity logged since
+// the given time.
+func (r *ActivityRepository) ListActorsSince(ctx context.Context, workspaceID uuid.UUID, since time.Time) ([]uuid.UUID, error) {
+	var actors []uuid.UUID
+	query := `SELECT DISTINCT actor_id FROM workspace_activity_log WHERE workspace_id = ? AND created_at >= ?`
+	err := r.db.SelectContext(ctx, &actors, query, workspaceID, since)
+	return actors, err
+}
+
 func (r *ActivityRepository) GetTopContributors(ctx context.Context, workspaceID uuid.UUID, since time.Time, limit int) ([]models.ContributorStat, error) {
 	var stats []models.ContributorStat
 	query := `
@@ -85,6 +145,36 @@ func (r *ActivityRepository) GetTopContributors(ctx context.Context, workspaceID
 	return stats, err
 }
 
+func (r *ActivityRepository) CountDistinctActors(ctx context.Context, workspaceID uuid.UUID, since time.Time) (int, error) {
+	var count int
+	err := r.db.GetContext(ctx, &count, "SELECT COUNT(DISTINCT actor_id) FROM workspace_activity_log WHERE workspace_id = ? AND created_at >= ?", workspaceID, since)
+	return count, err
+}
+
+func (r *ActivityRepository) CountActionsSince(ctx context.Context, workspaceID uuid.UUID, actions []string, since time.Time) (map[string]int, error) {
+	type actionCount struct {
+		Action string `db:"action"`
+		Count  int    `db:"count"`
+	}
+	query, args, err := sqlx.In(`
+		SELECT action, COUNT(*) as count FROM workspace_activity_log
+		WHERE workspace_id = ? AND action IN (?) AND created_at >= ?
+		GROUP BY action
+	`, workspaceID, actions, since)
+	if err != nil {
+		return nil, err
+	}
+	var counts []actionCount
+	if err := r.db.SelectContext(ctx, &counts, r.db.Rebind(query), args...); err != nil {
+		return nil, err
+	}
+	result := make(map[string]int)
+	for _, ac := range counts {
+		result[ac.Action] = ac.Count
+	}
+	return result, nil
+}
+
 func (r *ActivityRepository) ListByDateRange(ctx context.Context, workspaceID uuid.UUID, startDate, endDate *time.Time, actionType string) ([]*models.ActivityLog, int64, error) {
 	var activities []*models.ActivityLog
 	var total int64
@@ -116,6 +206,19 @@ func (r *ActivityRepository) ListByDateRange(ctx context.Context, workspaceID uu
 	return activities, total, err
 }
 
+// GetDailyActionCountsByActor counts an actor's actions per calendar day since
+// the given time. Days without activity are omitted.
+func (r *ActivityRepository) GetDailyActionCountsByActor(ctx context.Context, workspaceID, actorID uuid.UUID, since time.Time) ([]models.DailyCount, error) {
+	var counts []models.DailyCount
+	query := `
+		SELECT DATE_FORMAT(created_at, '%Y-%m-%d') as date, COUNT(*) as count FROM workspace_activity_log
+		WHERE workspace_id = ? AND actor_id = ? AND created_at >= ?
+		GROUP BY date ORDER BY date ASC
+	`
+	err := r.db.SelectContext(ctx, &counts, query, workspaceID, actorID, since)
+	return counts, err
+}
+
 func (r *ActivityRepository) GetDailyActionCounts(ctx context.Context, workspaceID uuid.UUID, days int) ([]models.DailyCount, error) {
 	var counts []models.DailyCount
 	query := `
@@ -19,9 +19,12 @@ func NewAnnouncementRepository(db *sqlx.DB) *AnnouncementRepository {
 }
 
 func (r *AnnouncementRepository) Create(ctx context.Context, a *models.WorkspaceAnnouncement) error {
-	query := `INSERT INTO workspace_announcements (id, workspace_id, title, content, priority, author_id, is_pinned, expires_at, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
-	_, err := r.db.ExecContext(ctx, query, a.ID, a.WorkspaceID, a.Title, a.Content, a.Priority, a.AuthorID, a.IsPinned, a.ExpiresAt, a.CreatedAt, a.UpdatedAt)
+	query := `INSERT INTO workspace_announcements (id, workspace_id, title, content, priority, author_id, is_pinned, pin_position, expires_at, is_published, publish_at, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	_, err := r.db.ExecContext(ctx, query, a.ID, a.WorkspaceID, a.Title, a.Content, a.Priority, a.AuthorID, a.IsPinned, a.PinPosition, a.ExpiresAt, a.IsPublished, a.PublishAt, a.CreatedAt, a.UpdatedAt)
+	if err == nil {
+		a.Version = 1 // column default
+	}
 	return err
 }
 
@@ -34,9 +37,23 @@ func (r *AnnouncementRepository) GetByID(ctx context.Context, id uuid.UUID) (*mo
 	return &a, err
 }
 
-func (r *AnnouncementRepository) ListByWorkspace(ctx context.Context, workspaceID uuid.UUID, page, perPage int) ([]*models.WorkspaceAnnouncement, int64, error) {
+// ListByWorkspace lists live announcements. Unpublished ones are included
+// only for their author, filter.ViewerID.
+func (r *AnnouncementRepository) ListByWorkspace(ctx context.Context, workspaceID uuid.UUID, filter models.AnnouncementFilter, page, perPage int) ([]*models.WorkspaceAnnouncement, int64, error) {
+	where := "workspace_id = ? AND (expires_at IS NULL OR expires_at > NOW()) AND (is_published = TRUE OR author_id = ?)"
+	args := []interface{}{workspaceID, filter.ViewerID}
+	if filter.Query != "" {
+		where += " AND (title LIKE ? OR content LIKE ?)"
+		like := "%" + filter.Query + "%"
+		args = append(args, like, like)
+	}
+	if filter.Priority != "" {
+		where += " AND priority = ?"
+		args = append(args, filter.Priority)
+	}
+
 	var total int64
-	err := r.db.GetContext(ctx, &total, "SELECT COUNT(*) FROM workspace_announcements WHERE workspace_id = ? AND (expires_at IS NULL OR expires_at > NOW())", workspaceID)
+	err := r.db.GetContext(ctx, &total, "SELECT COUNT(*) FROM workspace_announcements WHERE "+where, args...)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -44,23 +61,92 @@ func (r *AnnouncementRepository) ListByWorkspace(ctx context.Context, workspaceI
 	offset := (page - 1) * perPage
 	var announcements []*models.WorkspaceAnnouncement
 	err = r.db.SelectContext(ctx, &announcements,
-		`SELECT * FROM workspace_announcements WHERE workspace_id = ? AND (expires_at IS NULL OR expires_at > NOW())
-		ORDER BY is_pinned DESC, FIELD(priority, 'urgent', 'important', 'normal'), created_at DESC
-		LIMIT ? OFFSET ?`, workspaceID, perPage, offset)
+		`SELECT * FROM workspace_announcements WHERE `+where+`
+		ORDER BY is_pinned DESC, pin_position IS NULL, pin_position ASC, FIELD(priority, 'urgent', 'high', 'normal', 'low'), created_at DESC
+		LIMIT ? OFFSET ?`, append(args, perPage, offset)...)
 	return announcements, total, err
 }
 
-func (r *AnnouncementRepository) Update(ctx context.Context, a *models.WorkspaceAnnouncement) error {
-	query := `UPDATE workspace_announcements SET title = ?, content = ?, priority = ?, expires_at = ?, updated_at = ? WHERE id = ?`
-	_, err := r.db.ExecContext(ctx, query, a.Title, a.Content, a.Priority, a.ExpiresAt, time.Now(), a.ID)
-	return err
+// ListAllByWorkspace lists every announcement in the workspace, oldest
+// first, including expired, scheduled and unpublished ones.
+func (r *AnnouncementRepository) ListAllByWorkspace(ctx context.Context, workspaceID uuid.UUID, page, perPage int) ([]*models.WorkspaceAnnouncement, error) {
+	offset := (page - 1) * perPage
+	var announcements []*models.WorkspaceAnnouncement
+	err := r.db.SelectContext(ctx, &announcements,
+		`SELECT * FROM workspace_announcements WHERE workspace_id = ? ORDER BY created_at ASC, id ASC LIMIT ? OFFSET ?`,
+		workspaceID, perPage, offset)
+	return announcements, err
 }
 
-func (r *AnnouncementRepository) UpdatePinStatus(ctx context.Context, id uuid.UUID, isPinned bool) error {
-	_, err := r.db.ExecContext(ctx, "UPDATE workspace_announcements SET is_pinned = ?, updated_at = ? WHERE id = ?", isPinned, time.Now(), id)
+// Update writes the announcement only if its version still matches
+// a.Version, reporting false when another write got there first.
+func (r *AnnouncementRepository) Update(ctx context.Context, a *models.WorkspaceAnnouncement) (bool, error) {
+	query := `UPDATE workspace_announcements SET title = ?, content = ?, priority = ?, expires_at = ?, version = version + 1, updated_at = ? WHERE id = ? AND version = ?`
+	result, err := r.db.ExecContext(ctx, query, a.Title, a.Content, a.Priority, a.ExpiresAt, time.Now(), a.ID, a.Version)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	if err != nil || n == 0 {
+		return false, err
+	}
+	a.Version++
+	return true, nil
+}
+
+// Publish makes a scheduled announcement visible, reporting false when it
+// was already published, so the publish event goes out once.
+func (r *AnnouncementRepository) Publish(ctx context.Context, id uuid.UUID, at time.Time) (bool, error) {
+	result, err := r.db.ExecContext(ctx, "UPDATE workspace_announcements SET is_published = TRUE, publish_at = ?, updated_at = ? WHERE id = ? AND is_published = FALSE", at, at, id)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n == 1, err
+}
+
+func (r *AnnouncementRepository) UpdatePinStatus(ctx context.Context, id uuid.UUID, isPinned bool, pinPosition *int) error {
+	_, err := r.db.ExecContext(ctx, "UPDATE workspace_announcements SET is_pinned = ?, pin_position = ?, updated_at = ? WHERE id = ?", isPinned, pinPosition, time.Now(), id)
 	return err
 }
 
+func (r *AnnouncementRepository) GetMaxPinPosition(ctx context.Context, workspaceID uuid.UUID) (int, error) {
+	var maxPos sql.NullInt64
+	err := r.db.GetContext(ctx, &maxPos, "SELECT MAX(pin_position) FROM workspace_announcements WHERE workspace_id = ? AND is_pinned = TRUE", workspaceID)
+	if err != nil || !maxPos.Valid {
+		return -1, err
+	}
+	return int(maxPos.Int64), nil
+}
+
+// ListPinned lists live pinned announcements in pin order. Unpublished ones
+// are included only for their author, viewerID.
+func (r *AnnouncementRepository) ListPinned(ctx context.Context, workspaceID, viewerID uuid.UUID) ([]*models.WorkspaceAnnouncement, error) {
+	var announcements []*models.WorkspaceAnnouncement
+	err := r.db.SelectContext(ctx, &announcements,
+		`SELECT * FROM workspace_announcements WHERE workspace_id = ? AND is_pinned = TRUE AND (expires_at IS NULL OR expires_at > NOW())
+		AND (is_published = TRUE OR author_id = ?)
+		ORDER BY pin_position IS NULL, pin_position ASC, created_at DESC`, workspaceID, viewerID)
+	return announcements, err
+}
+
+func (r *AnnouncementRepository) UpdatePinPositions(ctx context.Context, workspaceID uuid.UUID, announcementIDs []uuid.UUID) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for i, id := range announcementIDs {
+		_, err := tx.ExecContext(ctx, `UPDATE workspace_announcements SET pin_position = ? WHERE id = ? AND workspace_id = ? AND is_pinned = TRUE`, i, id, workspaceID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
 func (r *AnnouncementRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	_, err := r.db.ExecContext(ctx, "DELETE FROM workspace_announcements WHERE id = ?", id)
 	return err
@@ -68,6 +154,38 @@ func (r *AnnouncementRepository) Delete(ctx context.Context, id uuid.UUID) error
 
 func (r *AnnouncementRepository) CountActive(ctx context.Context, workspaceID uuid.UUID) (int, error) {
 	var count int
-	err := r.db.GetContext(ctx, &count, "SELECT COUNT(*) FROM workspace_announcements WHERE workspace_id = ? AND (expires_at IS NULL OR expires_at > NOW())", workspaceID)
+	err := r.db.GetContext(ctx, &count, "SELECT COUNT(*) FROM workspace_announcements WHERE workspace_id = ? AND is_published = TRUE AND (expires_at IS NULL OR expires_at > NOW())", workspaceID)
 	return count, err
 }
+
+// ClaimDigest records that the workspace's digest for the send date went
+// out, reporting false when it was already recorded.
+func (r *AnnouncementRepository) ClaimDigest(ctx context.Context, workspaceID uuid.UUID, sendTime time.Time) (bool, error) {
+	result, err := r.db.ExecContext(ctx, "INSERT IGNORE INTO workspace_announcement_digests (workspace_id, send_date, sent_at) VALUES (?, ?, ?)",
+		workspaceID, sendTime.UTC().Format("2006-01-02"), time.Now())
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n == 1, err
+}
+
+// ListWorkspacesWithAnnouncementsSince returns the IDs of workspaces that
+// published at least one announcement at or after since.
+func (r *AnnouncementRepository) ListWorkspacesWithAnnouncementsSince(ctx context.Context, since time.Time) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := r.db.SelectContext(ctx, &ids, "SELECT DISTINCT workspace_id FROM workspace_announcements WHERE is_published = TRUE AND COALESCE(publish_at, created_at) >= ?", since)
+	return ids, err
+}
+
+// ListCreatedBetween returns the workspace's announcements published in
+// [from, to), oldest first. Scheduled announcements count from the time
+// they went out rather than when they were written.
+func (r *AnnouncementRepository) ListCreatedBetween(ctx context.Context, workspaceID uuid.UUID, from, to time.Time) ([]*models.WorkspaceAnnouncement, error) {
+	var announcements []*models.WorkspaceAnnouncement
+	err := r.db.SelectContext(ctx, &announcements,
+		`SELECT * FROM workspace_announcements WHERE workspace_id = ? AND is_published = TRUE
+		AND COALESCE(publish_at, created_at) >= ? AND COALESCE(publish_at, created_at) < ? ORDER BY COALESCE(publish_at, created_at) ASC`,
+		workspaceID, from, to)
+	return announcements, err
+}
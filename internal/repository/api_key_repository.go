@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/quckapp/workspace-service/internal/models"
+)
+
+type APIKeyRepository struct {
+	db *sqlx.DB
+}
+
+func NewAPIKeyRepository(db *sqlx.DB) *APIKeyRepository {
+	return &APIKeyRepository{db: db}
+}
+
+func (r *APIKeyRepository) Create(ctx context.Context, k *models.WorkspaceAPIKey) error {
+	query := `INSERT INTO workspace_api_keys (id, workspace_id, name, key_prefix, key_hash, permissions, created_by, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+	_, err := r.db.ExecContext(ctx, query, k.ID, k.WorkspaceID, k.Name, k.KeyPrefix, k.KeyHash, k.Permissions, k.CreatedBy, k.CreatedAt)
+	return err
+}
+
+func (r *APIKeyRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.WorkspaceAPIKey, error) {
+	var k models.WorkspaceAPIKey
+	err := r.db.GetContext(ctx, &k, "SELECT * FROM workspace_api_keys WHERE id = ?", id)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return &k, err
+}
+
+func (r *APIKeyRepository) GetByHash(ctx context.Context, hash string) (*models.WorkspaceAPIKey, error) {
+	var k models.WorkspaceAPIKey
+	err := r.db.GetContext(ctx, &k, "SELECT * FROM workspace_api_keys WHERE key_hash = ?", hash)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return &k, err
+}
+
+func (r *APIKeyRepository) ListByWorkspace(ctx context.Context, workspaceID uuid.UUID) ([]*models.WorkspaceAPIKey, error) {
+	var keys []*models.WorkspaceAPIKey
+	err := r.db.SelectContext(ctx, &keys, "SELECT * FROM workspace_api_keys WHERE workspace_id = ? ORDER BY created_at DESC", workspaceID)
+	return keys, err
+}
+
+// Revoke marks an active key revoked and returns the rows affected, so 0
+// means the key was already revoked.
+func (r *APIKeyRepository) Revoke(ctx context.Context, id uuid.UUID) (int64, error) {
+	result, err := r.db.ExecContext(ctx, "UPDATE workspace_api_keys SET revoked_at = ? WHERE id = ? AND revoked_at IS NULL", time.Now(), id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// TouchLastUsed records a use of the key, writing at most once per
+// interval so a busy integration does not update the row on every call.
+func (r *APIKeyRepository) TouchLastUsed(ctx context.Context, id uuid.UUID, interval time.Duration) error {
+	now := time.Now()
+	_, err := r.db.ExecContext(ctx,
+		"UPDATE workspace_api_keys SET last_used_at = ? WHERE id = ? AND (last_used_at IS NULL OR last_used_at < ?)",
+		now, id, now.Add(-interval))
+	return err
+}
@@ -41,6 +41,53 @@ func (r *BillingRepository) UpdatePlan(ctx context.Context, plan *models.Workspa
 	return err
 }
 
+// ApplyPlan writes a plan change everywhere it is recorded in one
+// transaction: the plan row (created if the workspace has none), the
+// workspace's plan column, the quota limits the plan sets, and the billing
+// event. Quota usage counters and limits the plan does not set are kept.
+// It reports false, writing nothing, when the workspace does not exist.
+func (r *BillingRepository) ApplyPlan(ctx context.Context, plan *models.WorkspacePlan, quota *models.WorkspaceQuota, event *models.BillingEvent) (bool, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, "UPDATE workspaces SET plan = ?, version = version + 1, updated_at = ? WHERE id = ? AND deleted_at IS NULL", plan.PlanType, plan.UpdatedAt, plan.WorkspaceID)
+	if err != nil {
+		return false, err
+	}
+	if n, err := result.RowsAffected(); err != nil || n == 0 {
+		return false, err
+	}
+
+	planQuery := `INSERT INTO workspace_plans (id, workspace_id, plan_type, status, billing_cycle, seat_count, seat_limit, storage_limit_mb, storage_used_mb, price_per_seat, currency, trial_ends_at, current_period_start, current_period_end, canceled_at, external_id, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE
+		plan_type = VALUES(plan_type), status = VALUES(status), billing_cycle = VALUES(billing_cycle), seat_count = VALUES(seat_count),
+		seat_limit = VALUES(seat_limit), storage_limit_mb = VALUES(storage_limit_mb), storage_used_mb = VALUES(storage_used_mb),
+		price_per_seat = VALUES(price_per_seat), current_period_start = VALUES(current_period_start), current_period_end = VALUES(current_period_end),
+		canceled_at = VALUES(canceled_at), updated_at = VALUES(updated_at)`
+	if _, err := tx.ExecContext(ctx, planQuery, plan.ID, plan.WorkspaceID, plan.PlanType, plan.Status, plan.BillingCycle, plan.SeatCount, plan.SeatLimit, plan.StorageLimitMB, plan.StorageUsedMB, plan.PricePerSeat, plan.Currency, plan.TrialEndsAt, plan.CurrentPeriodStart, plan.CurrentPeriodEnd, plan.CanceledAt, plan.ExternalID, plan.CreatedAt, plan.UpdatedAt); err != nil {
+		return false, err
+	}
+
+	quotaQuery := `INSERT INTO workspace_quotas (id, workspace_id, max_members, max_channels, max_storage_mb, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE
+		max_members = VALUES(max_members), max_channels = VALUES(max_channels), max_storage_mb = VALUES(max_storage_mb), updated_at = VALUES(updated_at)`
+	if _, err := tx.ExecContext(ctx, quotaQuery, quota.ID, quota.WorkspaceID, quota.MaxMembers, quota.MaxChannels, quota.MaxStorageMB, quota.CreatedAt, quota.UpdatedAt); err != nil {
+		return false, err
+	}
+
+	eventQuery := `INSERT INTO workspace_billing_events (id, workspace_id, event_type, description, metadata, actor_id, created_at) VALUES (?, ?, ?, ?, ?, ?, ?)`
+	if _, err := tx.ExecContext(ctx, eventQuery, event.ID, event.WorkspaceID, event.EventType, event.Description, event.Metadata, event.ActorID, event.CreatedAt); err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
+
 // Invoice methods
 func (r *BillingRepository) CreateInvoice(ctx context.Context, invoice *models.BillingInvoice) error {
 	query := `INSERT INTO workspace_invoices (id, workspace_id, invoice_number, amount, currency, status, description, period_start, period_end, paid_at, due_date, external_id, pdf_url, created_at)
@@ -144,6 +191,21 @@ func (r *BillingRepository) UpdateStorageUsage(ctx context.Context, workspaceID
 	return err
 }
 
+// ListSeatUsageByPlan returns purchased seats and active member counts for
+// every live workspace on the given plan type.
+func (r *BillingRepository) ListSeatUsageByPlan(ctx context.Context, planType string) ([]*models.SeatUtilization, error) {
+	var usage []*models.SeatUtilization
+	query := `SELECT p.workspace_id, w.name AS workspace_name, p.status, p.seat_count AS purchased_seats, COUNT(m.id) AS active_members
+		FROM workspace_plans p
+		JOIN workspaces w ON w.id = p.workspace_id AND w.deleted_at IS NULL
+		LEFT JOIN workspace_members m ON m.workspace_id = p.workspace_id AND m.is_active = TRUE
+		WHERE p.plan_type = ?
+		GROUP BY p.workspace_id, w.name, p.status, p.seat_count
+		ORDER BY active_members - p.seat_count DESC, w.name ASC`
+	err := r.db.SelectContext(ctx, &usage, query, planType)
+	return usage, err
+}
+
 func (r *BillingRepository) UpdateSeatCount(ctx context.Context, workspaceID uuid.UUID, seatCount int) error {
 	_, err := r.db.ExecContext(ctx, "UPDATE workspace_plans SET seat_count = ?, updated_at = ? WHERE workspace_id = ?", seatCount, time.Now(), workspaceID)
 	return err
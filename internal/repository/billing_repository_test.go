@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/quckapp/workspace-service/internal/models"
+)
+
+// jsonConverter lets models.JSON arguments reach the mock; the type has no
+// driver.Valuer of its own.
+type jsonConverter struct{}
+
+func (jsonConverter) ConvertValue(v interface{}) (driver.Value, error) {
+	if j, ok := v.(models.JSON); ok {
+		if j == nil {
+			return nil, nil
+		}
+		return json.Marshal(j)
+	}
+	return driver.DefaultParameterConverter.ConvertValue(v)
+}
+
+func newBillingTestDB(t *testing.T) (*BillingRepository, sqlmock.Sqlmock) {
+	conn, mock, err := sqlmock.New(sqlmock.ValueConverterOption(jsonConverter{}))
+	if err != nil {
+		t.Fatalf("sqlmock: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("unmet expectations: %v", err)
+		}
+		conn.Close()
+	})
+	return NewBillingRepository(sqlx.NewDb(conn, "mysql")), mock
+}
+
+func newPlanChange() (*models.WorkspacePlan, *models.WorkspaceQuota, *models.BillingEvent) {
+	workspaceID, now := uuid.New(), time.Now()
+	plan := &models.WorkspacePlan{ID: uuid.New(), WorkspaceID: workspaceID, PlanType: "pro", Status: "active", UpdatedAt: now}
+	quota := &models.WorkspaceQuota{ID: uuid.New(), WorkspaceID: workspaceID, MaxMembers: 500, CreatedAt: now, UpdatedAt: now}
+	event := &models.BillingEvent{ID: uuid.New(), WorkspaceID: workspaceID, EventType: "plan_changed", CreatedAt: now}
+	return plan, quota, event
+}
+
+func TestApplyPlanRollsBackWhenQuotaWriteFails(t *testing.T) {
+	repo, mock := newBillingTestDB(t)
+	plan, quota, event := newPlanChange()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE workspaces SET plan = \?`).
+		WithArgs("pro", plan.UpdatedAt, plan.WorkspaceID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO workspace_plans .* ON DUPLICATE KEY UPDATE`).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO workspace_quotas .* ON DUPLICATE KEY UPDATE`).
+		WillReturnError(errors.New("lock wait timeout"))
+	mock.ExpectRollback()
+
+	applied, err := repo.ApplyPlan(context.Background(), plan, quota, event)
+	if err == nil || applied {
+		t.Errorf("got (%v, %v), want the failure reported", applied, err)
+	}
+}
+
+func TestApplyPlanRollsBackWhenEventWriteFails(t *testing.T) {
+	repo, mock := newBillingTestDB(t)
+	plan, quota, event := newPlanChange()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE workspaces SET plan = \?`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO workspace_plans`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO workspace_quotas`).
+		WithArgs(quota.ID, quota.WorkspaceID, 500, 0, 0, quota.CreatedAt, quota.UpdatedAt).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO workspace_billing_events`).
+		WillReturnError(errors.New("connection reset"))
+	mock.ExpectRollback()
+
+	if applied, err := repo.ApplyPlan(context.Background(), plan, quota, event); err == nil || applied {
+		t.Errorf("got (%v, %v), want the failure reported", applied, err)
+	}
+}
+
+func TestApplyPlanMissingWorkspaceWritesNothing(t *testing.T) {
+	repo, mock := newBillingTestDB(t)
+	plan, quota, event := newPlanChange()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE workspaces SET plan = \?`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	applied, err := repo.ApplyPlan(context.Background(), plan, quota, event)
+	if err != nil || applied {
+		t.Errorf("got (%v, %v), want (false, nil)", applied, err)
+	}
+}
+
+func TestApplyPlanCommitsAllWrites(t *testing.T) {
+	repo, mock := newBillingTestDB(t)
+	plan, quota, event := newPlanChange()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE workspaces SET plan = \?`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO workspace_plans`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO workspace_quotas`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO workspace_billing_events`).
+		WithArgs(event.ID, event.WorkspaceID, "plan_changed", "", nil, event.ActorID, event.CreatedAt).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	applied, err := repo.ApplyPlan(context.Background(), plan, quota, event)
+	if err != nil || !applied {
+		t.Errorf("got (%v, %v), want (true, nil)", applied, err)
+	}
+}
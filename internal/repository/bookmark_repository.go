@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"strings"
 
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
@@ -79,6 +80,67 @@ func (r *BookmarkRepository) CountByUser(ctx context.Context, workspaceID, userI
 	return count, err
 }
 
+// RemapFolders renames folders for a user's bookmarks in a single statement so
+// every rename applies atomically, including swaps and merges.
+func (r *BookmarkRepository) RemapFolders(ctx context.Context, workspaceID, userID uuid.UUID, mapping map[string]string) (int64, error) {
+	var caseSQL strings.Builder
+	var caseArgs []interface{}
+	var oldNames []string
+	caseSQL.WriteString("CASE folder_name")
+	for oldName, newName := range mapping {
+		caseSQL.WriteString(" WHEN ? THEN ?")
+		var target interface{} = newName
+		if newName == "" {
+			target = nil
+		}
+		caseArgs = append(caseArgs, oldName, target)
+		oldNames = append(oldNames, oldName)
+	}
+	caseSQL.WriteString(" END")
+
+	query, inArgs, err := sqlx.In(`UPDATE workspace_bookmarks SET folder_name = `+caseSQL.String()+`, updated_at = NOW()
+		WHERE workspace_id = ? AND user_id = ? AND folder_name IN (?)`, append(caseArgs, workspaceID, userID, oldNames)...)
+	if err != nil {
+		return 0, err
+	}
+
+	result, err := r.db.ExecContext(ctx, r.db.Rebind(query), inArgs...)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// RenameFolder moves every bookmark in oldName to newName in one statement.
+func (r *BookmarkRepository) RenameFolder(ctx context.Context, workspaceID, userID uuid.UUID, oldName, newName string) (int64, error) {
+	query := `UPDATE workspace_bookmarks SET folder_name = ?, updated_at = NOW() WHERE workspace_id = ? AND user_id = ? AND folder_name = ?`
+	result, err := r.db.ExecContext(ctx, query, newName, workspaceID, userID, oldName)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// UnfileFolder clears the folder on every bookmark in name.
+func (r *BookmarkRepository) UnfileFolder(ctx context.Context, workspaceID, userID uuid.UUID, name string) (int64, error) {
+	query := `UPDATE workspace_bookmarks SET folder_name = NULL, updated_at = NOW() WHERE workspace_id = ? AND user_id = ? AND folder_name = ?`
+	result, err := r.db.ExecContext(ctx, query, workspaceID, userID, name)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// DeleteFolder deletes every bookmark in name.
+func (r *BookmarkRepository) DeleteFolder(ctx context.Context, workspaceID, userID uuid.UUID, name string) (int64, error) {
+	query := `DELETE FROM workspace_bookmarks WHERE workspace_id = ? AND user_id = ? AND folder_name = ?`
+	result, err := r.db.ExecContext(ctx, query, workspaceID, userID, name)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 func (r *BookmarkRepository) ListFolders(ctx context.Context, workspaceID, userID uuid.UUID) ([]string, error) {
 	var folders []string
 	query := `SELECT DISTINCT folder_name FROM workspace_bookmarks WHERE workspace_id = ? AND user_id = ? AND folder_name IS NOT NULL ORDER BY folder_name ASC`
@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+)
+
+func TestRemapFoldersRenamesInOneStatement(t *testing.T) {
+	db, mock := newTestDB(t)
+	repo := NewBookmarkRepository(db)
+	workspaceID, userID := uuid.New(), uuid.New()
+
+	// A swap only works if both renames happen in the same statement.
+	mock.ExpectExec(`UPDATE workspace_bookmarks SET folder_name = CASE folder_name WHEN \? THEN \? WHEN \? THEN \? END`).
+		WillReturnResult(sqlmock.NewResult(0, 5))
+
+	n, err := repo.RemapFolders(context.Background(), workspaceID, userID, map[string]string{"Work": "Personal", "Personal": "Work"})
+	if err != nil {
+		t.Fatalf("RemapFolders: %v", err)
+	}
+	if n != 5 {
+		t.Errorf("updated = %d, want 5", n)
+	}
+}
+
+func TestRemapFoldersEmptyTargetClearsFolder(t *testing.T) {
+	db, mock := newTestDB(t)
+	repo := NewBookmarkRepository(db)
+	workspaceID, userID := uuid.New(), uuid.New()
+
+	mock.ExpectExec(`UPDATE workspace_bookmarks SET folder_name = CASE folder_name WHEN \? THEN \? END.*folder_name IN \(\?\)`).
+		WithArgs("Old", nil, workspaceID, userID, "Old").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	if _, err := repo.RemapFolders(context.Background(), workspaceID, userID, map[string]string{"Old": ""}); err != nil {
+		t.Fatalf("RemapFolders: %v", err)
+	}
+}
@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
@@ -18,12 +19,12 @@ func NewComplianceRepository(db *sqlx.DB) *ComplianceRepository {
 }
 
 func (r *ComplianceRepository) Create(ctx context.Context, policy *models.CompliancePolicy) error {
-	query := `INSERT INTO compliance_policies (id, workspace_id, name, description, policy_type, rules, severity, is_enforced, created_by, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	query := `INSERT INTO compliance_policies (id, workspace_id, name, description, policy_type, rules, severity, is_enforced, reack_interval_days, created_by, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
 	_, err := r.db.ExecContext(ctx, query,
 		policy.ID, policy.WorkspaceID, policy.Name, policy.Description,
 		policy.PolicyType, policy.Rules, policy.Severity, policy.IsEnforced,
-		policy.CreatedBy, policy.CreatedAt, policy.UpdatedAt)
+		policy.ReackIntervalDays, policy.CreatedBy, policy.CreatedAt, policy.UpdatedAt)
 	return err
 }
 
@@ -52,9 +53,9 @@ func (r *ComplianceRepository) ListByType(ctx context.Context, workspaceID uuid.
 }
 
 func (r *ComplianceRepository) Update(ctx context.Context, policy *models.CompliancePolicy) error {
-	query := `UPDATE compliance_policies SET name = ?, description = ?, rules = ?, severity = ?, is_enforced = ?, updated_at = NOW() WHERE id = ?`
+	query := `UPDATE compliance_policies SET name = ?, description = ?, rules = ?, severity = ?, is_enforced = ?, reack_interval_days = ?, updated_at = NOW() WHERE id = ?`
 	_, err := r.db.ExecContext(ctx, query,
-		policy.Name, policy.Description, policy.Rules, policy.Severity, policy.IsEnforced, policy.ID)
+		policy.Name, policy.Description, policy.Rules, policy.Severity, policy.IsEnforced, policy.ReackIntervalDays, policy.ID)
 	return err
 }
 
@@ -81,9 +82,14 @@ func (r *ComplianceRepository) HasAcknowledged(ctx context.Context, policyID, us
 	return count > 0, err
 }
 
+// GetAcknowledgementCount counts acknowledgements that have not expired under
+// the policy's re-acknowledgement interval.
 func (r *ComplianceRepository) GetAcknowledgementCount(ctx context.Context, policyID uuid.UUID) (int, error) {
 	var count int
-	query := `SELECT COUNT(*) FROM policy_acknowledgements WHERE policy_id = ?`
+	query := `SELECT COUNT(*) FROM policy_acknowledgements a
+		JOIN compliance_policies p ON p.id = a.policy_id
+		WHERE a.policy_id = ?
+		AND (p.reack_interval_days IS NULL OR a.acked_at >= DATE_SUB(NOW(), INTERVAL p.reack_interval_days DAY))`
 	err := r.db.GetContext(ctx, &count, query, policyID)
 	return count, err
 }
@@ -95,6 +101,32 @@ func (r *ComplianceRepository) ListAcknowledgements(ctx context.Context, policyI
 	return acks, err
 }
 
+// ListOutstandingAcknowledgements returns the enforced acknowledgement policies
+// the user has not acknowledged yet, or whose acknowledgement is older than
+// the policy's re-acknowledgement interval.
+func (r *ComplianceRepository) ListOutstandingAcknowledgements(ctx context.Context, workspaceID, userID uuid.UUID) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	query := `SELECT p.id FROM compliance_policies p
+		LEFT JOIN policy_acknowledgements a ON a.policy_id = p.id AND a.user_id = ?
+		WHERE p.workspace_id = ? AND p.is_enforced = TRUE AND p.policy_type = 'acknowledgement'
+		AND (a.id IS NULL OR (p.reack_interval_days IS NOT NULL AND a.acked_at < DATE_SUB(NOW(), INTERVAL p.reack_interval_days DAY)))
+		ORDER BY p.created_at ASC`
+	err := r.db.SelectContext(ctx, &ids, query, userID, workspaceID)
+	return ids, err
+}
+
+// NextAcknowledgementExpiry returns when the user's earliest current
+// acknowledgement of an enforced policy runs out, or nil if none of them do.
+func (r *ComplianceRepository) NextAcknowledgementExpiry(ctx context.Context, workspaceID, userID uuid.UUID) (*time.Time, error) {
+	var next *time.Time
+	query := `SELECT MIN(DATE_ADD(a.acked_at, INTERVAL p.reack_interval_days DAY)) FROM compliance_policies p
+		JOIN policy_acknowledgements a ON a.policy_id = p.id AND a.user_id = ?
+		WHERE p.workspace_id = ? AND p.is_enforced = TRUE AND p.policy_type = 'acknowledgement'
+		AND p.reack_interval_days IS NOT NULL AND a.acked_at >= DATE_SUB(NOW(), INTERVAL p.reack_interval_days DAY)`
+	err := r.db.GetContext(ctx, &next, query, userID, workspaceID)
+	return next, err
+}
+
 func (r *ComplianceRepository) CountByWorkspace(ctx context.Context, workspaceID uuid.UUID) (int, error) {
 	var count int
 	query := `SELECT COUNT(*) FROM compliance_policies WHERE workspace_id = ?`
@@ -119,6 +119,18 @@ func (r *CustomFieldRepository) ListValuesByEntity(ctx context.Context, entityID
 	return values, err
 }
 
+func (r *CustomFieldRepository) GetMemberFacets(ctx context.Context, workspaceID, fieldID uuid.UUID) ([]*models.CustomFieldFacet, error) {
+	var facets []*models.CustomFieldFacet
+	query := `
+		SELECT v.value, COUNT(*) as count FROM workspace_custom_field_values v
+		INNER JOIN workspace_members m ON v.entity_id = m.user_id
+		WHERE v.field_id = ? AND m.workspace_id = ? AND m.is_active = TRUE
+		GROUP BY v.value ORDER BY count DESC, v.value ASC
+	`
+	err := r.db.SelectContext(ctx, &facets, query, fieldID, workspaceID)
+	return facets, err
+}
+
 func (r *CustomFieldRepository) DeleteValue(ctx context.Context, fieldID, entityID uuid.UUID) error {
 	query := `DELETE FROM workspace_custom_field_values WHERE field_id = ? AND entity_id = ?`
 	_, err := r.db.ExecContext(ctx, query, fieldID, entityID)
@@ -68,7 +68,7 @@ func (r *DiscoveryRepository) SearchDirectory(ctx context.Context, query string,
 
 func (r *DiscoveryRepository) GetCategories(ctx context.Context) ([]models.WorkspaceCategory, error) {
 	var categories []models.WorkspaceCategory
-	err := r.db.SelectContext(ctx, &categories, "SELECT COALESCE(category, 'other') as name, COUNT(*) as count FROM workspace_directory WHERE is_listed = TRUE GROUP BY category ORDER BY count DESC")
+	err := r.db.SelectContext(ctx, &categories, "SELECT COALESCE(category, 'other') as name, COUNT(*) as count FROM workspace_directory WHERE is_listed = TRUE GROUP BY name ORDER BY count DESC, name")
 	return categories, err
 }
 
@@ -97,6 +97,14 @@ func (r *DiscoveryRepository) DismissRecommendation(ctx context.Context, userID,
 	return err
 }
 
+func (r *DiscoveryRepository) DismissAllRecommendations(ctx context.Context, userID uuid.UUID) (int64, error) {
+	result, err := r.db.ExecContext(ctx, "UPDATE workspace_recommendations SET is_dismissed = TRUE, dismissed_at = ? WHERE user_id = ? AND is_dismissed = FALSE", time.Now(), userID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 func (r *DiscoveryRepository) GetTrending(ctx context.Context, limit int) ([]*models.TrendingWorkspace, error) {
 	var trending []*models.TrendingWorkspace
 	query := `SELECT w.*, d.member_count, 0 as growth_rate, 0 as active_users
@@ -107,3 +115,53 @@ func (r *DiscoveryRepository) GetTrending(ctx context.Context, limit int) ([]*mo
 	err := r.db.SelectContext(ctx, &trending, query, limit)
 	return trending, err
 }
+
+// Reports
+func (r *DiscoveryRepository) CreateReport(ctx context.Context, report *models.DirectoryReport) error {
+	query := `INSERT INTO workspace_directory_reports (id, workspace_id, reporter_id, reason, details, status, created_at) VALUES (?, ?, ?, ?, ?, ?, ?)`
+	_, err := r.db.ExecContext(ctx, query, report.ID, report.WorkspaceID, report.ReporterID, report.Reason, report.Details, report.Status, report.CreatedAt)
+	return err
+}
+
+func (r *DiscoveryRepository) GetReport(ctx context.Context, id uuid.UUID) (*models.DirectoryReport, error) {
+	var report models.DirectoryReport
+	err := r.db.GetContext(ctx, &report, "SELECT * FROM workspace_directory_reports WHERE id = ?", id)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return &report, err
+}
+
+func (r *DiscoveryRepository) HasPendingReport(ctx context.Context, workspaceID, reporterID uuid.UUID) (bool, error) {
+	var count int
+	err := r.db.GetContext(ctx, &count, "SELECT COUNT(*) FROM workspace_directory_reports WHERE workspace_id = ? AND reporter_id = ? AND status = 'pending'", workspaceID, reporterID)
+	return count > 0, err
+}
+
+func (r *DiscoveryRepository) ListReports(ctx context.Context, status string, limit, offset int) ([]*models.DirectoryReport, int64, error) {
+	var reports []*models.DirectoryReport
+	var total int64
+	if err := r.db.GetContext(ctx, &total, "SELECT COUNT(*) FROM workspace_directory_reports WHERE status = ?", status); err != nil {
+		return nil, 0, err
+	}
+	err := r.db.SelectContext(ctx, &reports, "SELECT * FROM workspace_directory_reports WHERE status = ? ORDER BY created_at ASC LIMIT ? OFFSET ?", status, limit, offset)
+	return reports, total, err
+}
+
+// ResolvePendingReports resolves the given pending report, or every pending
+// report for the workspace when reportID is nil.
+func (r *DiscoveryRepository) ResolvePendingReports(ctx context.Context, workspaceID uuid.UUID, reportID *uuid.UUID, status string, resolvedBy uuid.UUID, note *string) error {
+	query := "UPDATE workspace_directory_reports SET status = ?, resolved_by = ?, resolution_note = ?, resolved_at = ? WHERE workspace_id = ? AND status = 'pending'"
+	args := []interface{}{status, resolvedBy, note, time.Now(), workspaceID}
+	if reportID != nil {
+		query += " AND id = ?"
+		args = append(args, *reportID)
+	}
+	_, err := r.db.ExecContext(ctx, query, args...)
+	return err
+}
+
+func (r *DiscoveryRepository) Unlist(ctx context.Context, workspaceID uuid.UUID) error {
+	_, err := r.db.ExecContext(ctx, "UPDATE workspace_directory SET is_listed = FALSE, updated_at = ? WHERE workspace_id = ?", time.Now(), workspaceID)
+	return err
+}
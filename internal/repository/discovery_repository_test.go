@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+)
+
+func TestDismissAllRecommendationsSkipsDismissed(t *testing.T) {
+	db, mock := newTestDB(t)
+	repo := NewDiscoveryRepository(db)
+	userID := uuid.New()
+
+	mock.ExpectExec(`UPDATE workspace_recommendations SET is_dismissed = TRUE, dismissed_at = \? WHERE user_id = \? AND is_dismissed = FALSE`).
+		WithArgs(sqlmock.AnyArg(), userID).
+		WillReturnResult(sqlmock.NewResult(0, 4))
+
+	n, err := repo.DismissAllRecommendations(context.Background(), userID)
+	if err != nil {
+		t.Fatalf("DismissAllRecommendations: %v", err)
+	}
+	if n != 4 {
+		t.Errorf("dismissed %d, want 4", n)
+	}
+}
+
+func TestDismissAllRecommendationsReportsError(t *testing.T) {
+	db, mock := newTestDB(t)
+	repo := NewDiscoveryRepository(db)
+
+	mock.ExpectExec(`UPDATE workspace_recommendations`).WillReturnError(context.DeadlineExceeded)
+
+	if n, err := repo.DismissAllRecommendations(context.Background(), uuid.New()); err == nil || n != 0 {
+		t.Errorf("got %d, %v; want 0 and the error", n, err)
+	}
+}
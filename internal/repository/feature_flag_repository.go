@@ -19,10 +19,10 @@ func NewFeatureFlagRepository(db *sqlx.DB) *FeatureFlagRepository {
 
 func (r *FeatureFlagRepository) Create(ctx context.Context, flag *models.WorkspaceFeatureFlag) error {
 	query := `
-		INSERT INTO workspace_feature_flags (id, workspace_id, ` + "`key`" + `, enabled, description, metadata, created_by, updated_by, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO workspace_feature_flags (id, workspace_id, ` + "`key`" + `, enabled, rollout_percentage, description, metadata, created_by, updated_by, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
-	_, err := r.db.ExecContext(ctx, query, flag.ID, flag.WorkspaceID, flag.Key, flag.Enabled, flag.Description, flag.Metadata, flag.CreatedBy, flag.UpdatedBy, flag.CreatedAt, flag.UpdatedAt)
+	_, err := r.db.ExecContext(ctx, query, flag.ID, flag.WorkspaceID, flag.Key, flag.Enabled, flag.RolloutPercentage, flag.Description, flag.Metadata, flag.CreatedBy, flag.UpdatedBy, flag.CreatedAt, flag.UpdatedAt)
 	return err
 }
 
@@ -53,9 +53,19 @@ func (r *FeatureFlagRepository) ListByWorkspace(ctx context.Context, workspaceID
 	return flags, err
 }
 
+func (r *FeatureFlagRepository) ListByKeys(ctx context.Context, workspaceID uuid.UUID, keys []string) ([]*models.WorkspaceFeatureFlag, error) {
+	query, args, err := sqlx.In("SELECT * FROM workspace_feature_flags WHERE workspace_id = ? AND `key` IN (?)", workspaceID, keys)
+	if err != nil {
+		return nil, err
+	}
+	var flags []*models.WorkspaceFeatureFlag
+	err = r.db.SelectContext(ctx, &flags, r.db.Rebind(query), args...)
+	return flags, err
+}
+
 func (r *FeatureFlagRepository) Update(ctx context.Context, flag *models.WorkspaceFeatureFlag) error {
-	query := "UPDATE workspace_feature_flags SET enabled = ?, description = ?, metadata = ?, updated_by = ?, updated_at = NOW() WHERE id = ?"
-	_, err := r.db.ExecContext(ctx, query, flag.Enabled, flag.Description, flag.Metadata, flag.UpdatedBy, flag.ID)
+	query := "UPDATE workspace_feature_flags SET enabled = ?, rollout_percentage = ?, description = ?, metadata = ?, updated_by = ?, updated_at = NOW() WHERE id = ?"
+	_, err := r.db.ExecContext(ctx, query, flag.Enabled, flag.RolloutPercentage, flag.Description, flag.Metadata, flag.UpdatedBy, flag.ID)
 	return err
 }
 
@@ -74,3 +84,17 @@ func (r *FeatureFlagRepository) IsEnabled(ctx context.Context, workspaceID uuid.
 	}
 	return enabled, err
 }
+
+func (r *FeatureFlagRepository) CreateHistory(ctx context.Context, entry *models.FeatureFlagHistoryEntry) error {
+	query := `INSERT INTO workspace_feature_flag_history (id, workspace_id, flag_id, flag_key, action, enabled, rollout_percentage, changed_by, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	_, err := r.db.ExecContext(ctx, query, entry.ID, entry.WorkspaceID, entry.FlagID, entry.FlagKey, entry.Action, entry.Enabled, entry.RolloutPercentage, entry.ChangedBy, entry.CreatedAt)
+	return err
+}
+
+func (r *FeatureFlagRepository) ListHistory(ctx context.Context, flagID uuid.UUID) ([]*models.FeatureFlagHistoryEntry, error) {
+	var entries []*models.FeatureFlagHistoryEntry
+	query := `SELECT * FROM workspace_feature_flag_history WHERE flag_id = ? ORDER BY created_at DESC`
+	err := r.db.SelectContext(ctx, &entries, query, flagID)
+	return entries, err
+}
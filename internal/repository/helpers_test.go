@@ -0,0 +1,25 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+)
+
+// newTestDB returns a sqlx handle backed by sqlmock. Expectations are
+// checked when the test ends.
+func newTestDB(t testing.TB) (*sqlx.DB, sqlmock.Sqlmock) {
+	t.Helper()
+	conn, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("unmet expectations: %v", err)
+		}
+		conn.Close()
+	})
+	return sqlx.NewDb(conn, "mysql"), mock
+}
@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
@@ -59,12 +60,49 @@ func (r *IntegrationRepository) Update(ctx context.Context, integration *models.
 	return err
 }
 
+func (r *IntegrationRepository) UpdateCredentials(ctx context.Context, id uuid.UUID, credentials *string) error {
+	query := `UPDATE workspace_integrations SET credentials = ?, updated_at = NOW() WHERE id = ?`
+	_, err := r.db.ExecContext(ctx, query, credentials, id)
+	return err
+}
+
 func (r *IntegrationRepository) UpdateSyncTime(ctx context.Context, id uuid.UUID) error {
 	query := `UPDATE workspace_integrations SET last_sync_at = NOW(), updated_at = NOW() WHERE id = ?`
 	_, err := r.db.ExecContext(ctx, query, id)
 	return err
 }
 
+// MarkSyncSucceeded records a successful sync, clearing any earlier error.
+func (r *IntegrationRepository) MarkSyncSucceeded(ctx context.Context, id uuid.UUID, at time.Time) error {
+	query := `UPDATE workspace_integrations SET last_sync_at = ?, status = 'active', error_message = NULL, updated_at = NOW() WHERE id = ?`
+	_, err := r.db.ExecContext(ctx, query, at, id)
+	return err
+}
+
+// MarkSyncFailed records a failed sync and moves the integration to error.
+func (r *IntegrationRepository) MarkSyncFailed(ctx context.Context, id uuid.UUID, message string) error {
+	query := `UPDATE workspace_integrations SET status = 'error', error_message = ?, updated_at = NOW() WHERE id = ?`
+	_, err := r.db.ExecContext(ctx, query, message, id)
+	return err
+}
+
+// ListDueForSync returns active integrations of the given providers that
+// have never synced or last synced before staleBefore, oldest first.
+func (r *IntegrationRepository) ListDueForSync(ctx context.Context, providers []string, staleBefore time.Time, limit int) ([]*models.WorkspaceIntegration, error) {
+	if len(providers) == 0 {
+		return nil, nil
+	}
+	query, args, err := sqlx.In(`SELECT * FROM workspace_integrations
+		WHERE status = 'active' AND provider IN (?) AND (last_sync_at IS NULL OR last_sync_at < ?)
+		ORDER BY last_sync_at IS NOT NULL, last_sync_at ASC LIMIT ?`, providers, staleBefore, limit)
+	if err != nil {
+		return nil, err
+	}
+	var integrations []*models.WorkspaceIntegration
+	err = r.db.SelectContext(ctx, &integrations, r.db.Rebind(query), args...)
+	return integrations, err
+}
+
 func (r *IntegrationRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	query := `DELETE FROM workspace_integrations WHERE id = ?`
 	_, err := r.db.ExecContext(ctx, query, id)
@@ -2,6 +2,8 @@ package repository
 
 import (
 	"context"
+	"database/sql"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
@@ -45,12 +47,74 @@ func (r *InvitationHistoryRepository) ListByInviter(ctx context.Context, workspa
 	return records, err
 }
 
+// CountByInviterSince counts email invitations sent by the inviter since the
+// given time and returns when the oldest of them was sent. Joins through the
+// inviter's codes and magic links are not counted.
+func (r *InvitationHistoryRepository) CountByInviterSince(ctx context.Context, workspaceID, inviterID uuid.UUID, since time.Time) (int, *time.Time, error) {
+	var result struct {
+		Count  int        `db:"count"`
+		Oldest *time.Time `db:"oldest"`
+	}
+	query := `SELECT COUNT(*) AS count, MIN(created_at) AS oldest FROM workspace_invitation_history
+		WHERE workspace_id = ? AND inviter_id = ? AND method = 'email' AND created_at >= ?`
+	err := r.db.GetContext(ctx, &result, query, workspaceID, inviterID, since)
+	return result.Count, result.Oldest, err
+}
+
+// CountEmailedSince counts email invitations sent from the workspace since
+// the given time and returns when the oldest of them was sent.
+func (r *InvitationHistoryRepository) CountEmailedSince(ctx context.Context, workspaceID uuid.UUID, since time.Time) (int, *time.Time, error) {
+	var result struct {
+		Count  int        `db:"count"`
+		Oldest *time.Time `db:"oldest"`
+	}
+	query := `SELECT COUNT(*) AS count, MIN(created_at) AS oldest FROM workspace_invitation_history
+		WHERE workspace_id = ? AND method = 'email' AND created_at >= ?`
+	err := r.db.GetContext(ctx, &result, query, workspaceID, since)
+	return result.Count, result.Oldest, err
+}
+
 func (r *InvitationHistoryRepository) UpdateStatus(ctx context.Context, id uuid.UUID, status string) error {
 	query := `UPDATE workspace_invitation_history SET status = ? WHERE id = ?`
 	_, err := r.db.ExecContext(ctx, query, status, id)
 	return err
 }
 
+// MarkAccepted marks the pending email invitations for the address as accepted
+// by the given user.
+func (r *InvitationHistoryRepository) MarkAccepted(ctx context.Context, workspaceID uuid.UUID, email string, inviteeID uuid.UUID) error {
+	query := `UPDATE workspace_invitation_history SET status = 'accepted', invitee_id = ?, accepted_at = ?
+		WHERE workspace_id = ? AND invitee_email = ? AND method = 'email' AND status = 'pending'`
+	_, err := r.db.ExecContext(ctx, query, inviteeID, time.Now(), workspaceID, email)
+	return err
+}
+
+// ResolveInviteeID returns the user who most recently accepted an email
+// invitation sent to the address, in any workspace, or nil if none has.
+func (r *InvitationHistoryRepository) ResolveInviteeID(ctx context.Context, email string) (*uuid.UUID, error) {
+	var id uuid.UUID
+	query := `SELECT invitee_id FROM workspace_invitation_history
+		WHERE invitee_email = ? AND method = 'email' AND status = 'accepted' AND invitee_id IS NOT NULL
+		ORDER BY accepted_at DESC LIMIT 1`
+	err := r.db.GetContext(ctx, &id, query, email)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &id, nil
+}
+
+// ExtendPending moves the expiry of a still-pending email invitation, used
+// when the invite is resent.
+func (r *InvitationHistoryRepository) ExtendPending(ctx context.Context, workspaceID uuid.UUID, email string, expiresAt time.Time) error {
+	query := `UPDATE workspace_invitation_history SET expires_at = ?
+		WHERE workspace_id = ? AND invitee_email = ? AND method = 'email' AND status = 'pending'`
+	_, err := r.db.ExecContext(ctx, query, expiresAt, workspaceID, email)
+	return err
+}
+
 func (r *InvitationHistoryRepository) GetStats(ctx context.Context, workspaceID uuid.UUID) (*models.InvitationStats, error) {
 	stats := &models.InvitationStats{ByMethod: make(map[string]int)}
 
@@ -90,3 +154,32 @@ func (r *InvitationHistoryRepository) GetStats(ctx context.Context, workspaceID
 
 	return stats, nil
 }
+
+// CountByMethodAndStatusSince returns invitation counts keyed by method and
+// then status for invitations sent since the given time.
+func (r *InvitationHistoryRepository) CountByMethodAndStatusSince(ctx context.Context, workspaceID uuid.UUID, since time.Time) (map[string]map[string]int, error) {
+	type methodStatusCount struct {
+		Method string `db:"method"`
+		Status string `db:"status"`
+		Count  int    `db:"count"`
+	}
+	var rows []methodStatusCount
+	// Pending invitations past their expiry are reported as expired.
+	query := `SELECT method,
+			CASE WHEN status = 'pending' AND expires_at IS NOT NULL AND expires_at < NOW() THEN 'expired' ELSE status END AS status,
+			COUNT(*) as count
+		FROM workspace_invitation_history
+		WHERE workspace_id = ? AND created_at >= ? GROUP BY 1, 2`
+	if err := r.db.SelectContext(ctx, &rows, query, workspaceID, since); err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]map[string]int)
+	for _, row := range rows {
+		if result[row.Method] == nil {
+			result[row.Method] = make(map[string]int)
+		}
+		result[row.Method][row.Status] = row.Count
+	}
+	return result, nil
+}
@@ -20,10 +20,10 @@ func NewInviteCodeRepository(db *sqlx.DB) *InviteCodeRepository {
 
 func (r *InviteCodeRepository) Create(ctx context.Context, ic *models.WorkspaceInviteCode) error {
 	query := `
-		INSERT INTO workspace_invite_codes (id, workspace_id, code, role, max_uses, use_count, created_by, expires_at, is_active, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO workspace_invite_codes (id, workspace_id, code, role, max_uses, use_count, created_by, expires_at, is_active, approval_required, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
-	_, err := r.db.ExecContext(ctx, query, ic.ID, ic.WorkspaceID, ic.Code, ic.Role, ic.MaxUses, ic.UseCount, ic.CreatedBy, ic.ExpiresAt, ic.IsActive, ic.CreatedAt, ic.UpdatedAt)
+	_, err := r.db.ExecContext(ctx, query, ic.ID, ic.WorkspaceID, ic.Code, ic.Role, ic.MaxUses, ic.UseCount, ic.CreatedBy, ic.ExpiresAt, ic.IsActive, ic.ApprovalRequired, ic.CreatedAt, ic.UpdatedAt)
 	return err
 }
 
@@ -37,6 +37,16 @@ func (r *InviteCodeRepository) GetByCode(ctx context.Context, code string) (*mod
 	return &ic, err
 }
 
+// GetByCodeAnyState looks a code up whether or not it is still usable.
+func (r *InviteCodeRepository) GetByCodeAnyState(ctx context.Context, code string) (*models.WorkspaceInviteCode, error) {
+	var ic models.WorkspaceInviteCode
+	err := r.db.GetContext(ctx, &ic, `SELECT * FROM workspace_invite_codes WHERE code = ?`, code)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return &ic, err
+}
+
 func (r *InviteCodeRepository) ListByWorkspace(ctx context.Context, workspaceID uuid.UUID) ([]*models.WorkspaceInviteCode, error) {
 	var codes []*models.WorkspaceInviteCode
 	query := `SELECT * FROM workspace_invite_codes WHERE workspace_id = ? ORDER BY created_at DESC`
@@ -50,6 +60,25 @@ func (r *InviteCodeRepository) IncrementUseCount(ctx context.Context, id uuid.UU
 	return err
 }
 
+// ClaimUse atomically takes one use of the code, failing when it is already
+// used up. This keeps single-use codes single-use under concurrent joins.
+func (r *InviteCodeRepository) ClaimUse(ctx context.Context, id uuid.UUID) (bool, error) {
+	query := `UPDATE workspace_invite_codes SET use_count = use_count + 1, updated_at = ? WHERE id = ? AND (max_uses = 0 OR use_count < max_uses)`
+	result, err := r.db.ExecContext(ctx, query, time.Now(), id)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n == 1, err
+}
+
+// ReleaseUse gives back a use taken by ClaimUse when the join did not happen.
+func (r *InviteCodeRepository) ReleaseUse(ctx context.Context, id uuid.UUID) error {
+	query := `UPDATE workspace_invite_codes SET use_count = use_count - 1, updated_at = ? WHERE id = ? AND use_count > 0`
+	_, err := r.db.ExecContext(ctx, query, time.Now(), id)
+	return err
+}
+
 func (r *InviteCodeRepository) Deactivate(ctx context.Context, id uuid.UUID) error {
 	query := `UPDATE workspace_invite_codes SET is_active = FALSE, updated_at = ? WHERE id = ?`
 	_, err := r.db.ExecContext(ctx, query, time.Now(), id)
@@ -53,13 +53,38 @@ func (r *InviteRepository) MarkAccepted(ctx context.Context, id uuid.UUID) error
 	return err
 }
 
-func (r *InviteRepository) ListByWorkspace(ctx context.Context, workspaceID uuid.UUID) ([]*models.WorkspaceInvite, error) {
+// ListByWorkspace lists invites in the given status (pending, accepted or
+// expired); an empty status lists them all.
+func (r *InviteRepository) ListByWorkspace(ctx context.Context, workspaceID uuid.UUID, status string) ([]*models.WorkspaceInvite, error) {
 	var invites []*models.WorkspaceInvite
-	query := `SELECT * FROM workspace_invites WHERE workspace_id = ? AND accepted_at IS NULL AND expires_at > NOW() ORDER BY created_at DESC`
+	query := `SELECT * FROM workspace_invites WHERE workspace_id = ?`
+	switch status {
+	case "pending":
+		query += ` AND accepted_at IS NULL AND expires_at > NOW()`
+	case "accepted":
+		query += ` AND accepted_at IS NOT NULL`
+	case "expired":
+		query += ` AND accepted_at IS NULL AND expires_at <= NOW()`
+	}
+	query += ` ORDER BY created_at DESC`
 	err := r.db.SelectContext(ctx, &invites, query, workspaceID)
 	return invites, err
 }
 
+// MarkResent gives the invite a new expiry and records that it was sent
+// again at sentAt. It does nothing, and reports false, if the invite was
+// accepted or last sent after notSince.
+func (r *InviteRepository) MarkResent(ctx context.Context, id uuid.UUID, expiresAt, sentAt, notSince time.Time) (bool, error) {
+	query := `UPDATE workspace_invites SET expires_at = ?, last_sent_at = ?
+		WHERE id = ? AND accepted_at IS NULL AND COALESCE(last_sent_at, created_at) <= ?`
+	result, err := r.db.ExecContext(ctx, query, expiresAt, sentAt, id, notSince)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
+
 func (r *InviteRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	query := `DELETE FROM workspace_invites WHERE id = ?`
 	_, err := r.db.ExecContext(ctx, query, id)
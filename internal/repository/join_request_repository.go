@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/quckapp/workspace-service/internal/models"
+)
+
+type JoinRequestRepository struct {
+	db *sqlx.DB
+}
+
+func NewJoinRequestRepository(db *sqlx.DB) *JoinRequestRepository {
+	return &JoinRequestRepository{db: db}
+}
+
+func (r *JoinRequestRepository) Create(ctx context.Context, jr *models.WorkspaceJoinRequest) error {
+	query := `INSERT INTO workspace_join_requests (id, workspace_id, user_id, invite_code_id, role, status, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+	_, err := r.db.ExecContext(ctx, query, jr.ID, jr.WorkspaceID, jr.UserID, jr.InviteCodeID, jr.Role, jr.Status, jr.CreatedAt, jr.UpdatedAt)
+	return err
+}
+
+func (r *JoinRequestRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.WorkspaceJoinRequest, error) {
+	var jr models.WorkspaceJoinRequest
+	err := r.db.GetContext(ctx, &jr, "SELECT * FROM workspace_join_requests WHERE id = ?", id)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return &jr, err
+}
+
+func (r *JoinRequestRepository) GetByUser(ctx context.Context, workspaceID, userID uuid.UUID) (*models.WorkspaceJoinRequest, error) {
+	var jr models.WorkspaceJoinRequest
+	err := r.db.GetContext(ctx, &jr, "SELECT * FROM workspace_join_requests WHERE workspace_id = ? AND user_id = ?", workspaceID, userID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return &jr, err
+}
+
+// ListByWorkspace lists requests oldest first, so the queue is worked in
+// the order people asked. An empty status lists every request.
+func (r *JoinRequestRepository) ListByWorkspace(ctx context.Context, workspaceID uuid.UUID, status string, page, perPage int) ([]*models.WorkspaceJoinRequest, int64, error) {
+	where := "workspace_id = ?"
+	args := []interface{}{workspaceID}
+	if status != "" {
+		where += " AND status = ?"
+		args = append(args, status)
+	}
+
+	var total int64
+	err := r.db.GetContext(ctx, &total, "SELECT COUNT(*) FROM workspace_join_requests WHERE "+where, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * perPage
+	var requests []*models.WorkspaceJoinRequest
+	err = r.db.SelectContext(ctx, &requests,
+		"SELECT * FROM workspace_join_requests WHERE "+where+" ORDER BY created_at ASC LIMIT ? OFFSET ?",
+		append(args, perPage, offset)...)
+	return requests, total, err
+}
+
+// Reopen turns a decided request back into a pending one for a new code,
+// reporting false when it is still pending.
+func (r *JoinRequestRepository) Reopen(ctx context.Context, jr *models.WorkspaceJoinRequest) (bool, error) {
+	query := `UPDATE workspace_join_requests SET invite_code_id = ?, role = ?, status = 'pending', reviewed_by = NULL, reviewed_at = NULL,
+		created_at = ?, updated_at = ? WHERE id = ? AND status != 'pending'`
+	result, err := r.db.ExecContext(ctx, query, jr.InviteCodeID, jr.Role, jr.CreatedAt, jr.UpdatedAt, jr.ID)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n == 1, err
+}
+
+// Resolve records a decision on a pending request, reporting false when it
+// was already decided, so two admins cannot both act on it.
+func (r *JoinRequestRepository) Resolve(ctx context.Context, id uuid.UUID, status string, reviewedBy uuid.UUID) (bool, error) {
+	now := time.Now()
+	query := `UPDATE workspace_join_requests SET status = ?, reviewed_by = ?, reviewed_at = ?, updated_at = ? WHERE id = ? AND status = 'pending'`
+	result, err := r.db.ExecContext(ctx, query, status, reviewedBy, now, now, id)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n == 1, err
+}
+
+// Unresolve puts an approved request back in the queue when the membership
+// it approved could not be created.
+func (r *JoinRequestRepository) Unresolve(ctx context.Context, id uuid.UUID) error {
+	query := `UPDATE workspace_join_requests SET status = 'pending', reviewed_by = NULL, reviewed_at = NULL, updated_at = ? WHERE id = ? AND status = 'approved'`
+	_, err := r.db.ExecContext(ctx, query, time.Now(), id)
+	return err
+}
@@ -19,11 +19,37 @@ func NewMemberRepository(db *sqlx.DB) *MemberRepository {
 }
 
 func (r *MemberRepository) Create(ctx context.Context, m *models.WorkspaceMember) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := insertMember(ctx, tx, m); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// CreateWithEvent inserts the member and enqueues its outbox event in one
+// transaction, so the event exists if and only if the member does.
+func (r *MemberRepository) CreateWithEvent(ctx context.Context, m *models.WorkspaceMember, event *models.OutboxEvent) error {
+	return execWithEvent(ctx, r.db, event, func(tx *sqlx.Tx) error {
+		return insertMember(ctx, tx, m)
+	})
+}
+
+// insertMember adds m, first clearing the inactive row left behind if the
+// user was a member before, since members are removed by deactivation.
+func insertMember(ctx context.Context, tx *sqlx.Tx, m *models.WorkspaceMember) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM workspace_members WHERE workspace_id = ? AND user_id = ? AND is_active = FALSE`, m.WorkspaceID, m.UserID); err != nil {
+		return err
+	}
 	query := `
 		INSERT INTO workspace_members (id, workspace_id, user_id, role, joined_at, invited_by, is_active, created_at, updated_at)
 		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
-	_, err := r.db.ExecContext(ctx, query, m.ID, m.WorkspaceID, m.UserID, m.Role, m.JoinedAt, m.InvitedBy, m.IsActive, m.CreatedAt, m.UpdatedAt)
+	_, err := tx.ExecContext(ctx, query, m.ID, m.WorkspaceID, m.UserID, m.Role, m.JoinedAt, m.InvitedBy, m.IsActive, m.CreatedAt, m.UpdatedAt)
 	return err
 }
 
@@ -53,18 +79,120 @@ func (r *MemberRepository) ListByWorkspace(ctx context.Context, workspaceID uuid
 	return members, total, err
 }
 
+// ListInactiveSince returns active members who joined before since and have
+// not been seen since then, least recently seen first. Members never seen
+// at all come first.
+func (r *MemberRepository) ListInactiveSince(ctx context.Context, workspaceID uuid.UUID, since time.Time) ([]*models.WorkspaceMember, error) {
+	var members []*models.WorkspaceMember
+	query := `
+		SELECT m.*, p.last_seen_at FROM workspace_members m
+		LEFT JOIN workspace_member_profiles p ON p.workspace_id = m.workspace_id AND p.user_id = m.user_id
+		WHERE m.workspace_id = ? AND m.is_active = TRUE AND m.joined_at < ?
+			AND (p.last_seen_at IS NULL OR p.last_seen_at < ?)
+		ORDER BY p.last_seen_at IS NOT NULL, p.last_seen_at ASC
+	`
+	err := r.db.SelectContext(ctx, &members, query, workspaceID, since, since)
+	return members, err
+}
+
+// Search finds active members whose profile display name or title, or the
+// email they were invited with, contains query.
+func (r *MemberRepository) Search(ctx context.Context, workspaceID uuid.UUID, query string, page, perPage int) ([]*models.WorkspaceMember, int64, error) {
+	where := `m.workspace_id = ? AND m.is_active = TRUE AND (
+			p.display_name LIKE ? OR p.title LIKE ?
+			OR EXISTS (SELECT 1 FROM workspace_invitation_history h
+				WHERE h.workspace_id = m.workspace_id AND h.invitee_id = m.user_id AND h.invitee_email LIKE ?)
+		)`
+	like := "%" + query + "%"
+	args := []interface{}{workspaceID, like, like, like}
+	from := ` FROM workspace_members m
+		LEFT JOIN workspace_member_profiles p ON p.workspace_id = m.workspace_id AND p.user_id = m.user_id
+		WHERE ` + where
+
+	var total int64
+	if err := r.db.GetContext(ctx, &total, "SELECT COUNT(*)"+from, args...); err != nil {
+		return nil, 0, err
+	}
+
+	var members []*models.WorkspaceMember
+	offset := (page - 1) * perPage
+	err := r.db.SelectContext(ctx, &members, "SELECT m.*"+from+" ORDER BY p.display_name ASC, m.joined_at ASC LIMIT ? OFFSET ?", append(args, perPage, offset)...)
+	return members, total, err
+}
+
 func (r *MemberRepository) UpdateRole(ctx context.Context, workspaceID, userID uuid.UUID, role string) error {
+	return updateMemberRole(ctx, r.db, workspaceID, userID, role)
+}
+
+// UpdateRoleWithEvent changes the member's role and enqueues its outbox
+// event in one transaction.
+func (r *MemberRepository) UpdateRoleWithEvent(ctx context.Context, workspaceID, userID uuid.UUID, role string, event *models.OutboxEvent) error {
+	return execWithEvent(ctx, r.db, event, func(tx *sqlx.Tx) error {
+		return updateMemberRole(ctx, tx, workspaceID, userID, role)
+	})
+}
+
+func updateMemberRole(ctx context.Context, exec sqlx.ExecerContext, workspaceID, userID uuid.UUID, role string) error {
 	query := `UPDATE workspace_members SET role = ?, updated_at = ? WHERE workspace_id = ? AND user_id = ?`
-	_, err := r.db.ExecContext(ctx, query, role, time.Now(), workspaceID, userID)
+	_, err := exec.ExecContext(ctx, query, role, time.Now(), workspaceID, userID)
 	return err
 }
 
 func (r *MemberRepository) Remove(ctx context.Context, workspaceID, userID uuid.UUID) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := removeMember(ctx, tx, workspaceID, userID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// RemoveWithEvent deactivates the member and enqueues its outbox event in
+// one transaction.
+func (r *MemberRepository) RemoveWithEvent(ctx context.Context, workspaceID, userID uuid.UUID, event *models.OutboxEvent) error {
+	return execWithEvent(ctx, r.db, event, func(tx *sqlx.Tx) error {
+		return removeMember(ctx, tx, workspaceID, userID)
+	})
+}
+
+// removeMember deactivates the member and drops their custom role
+// assignments, so a member who later rejoins starts without them.
+func removeMember(ctx context.Context, tx *sqlx.Tx, workspaceID, userID uuid.UUID) error {
 	query := `UPDATE workspace_members SET is_active = FALSE, updated_at = ? WHERE workspace_id = ? AND user_id = ?`
-	_, err := r.db.ExecContext(ctx, query, time.Now(), workspaceID, userID)
+	if _, err := tx.ExecContext(ctx, query, time.Now(), workspaceID, userID); err != nil {
+		return err
+	}
+	_, err := tx.ExecContext(ctx, `DELETE FROM workspace_member_roles WHERE workspace_id = ? AND user_id = ?`, workspaceID, userID)
 	return err
 }
 
+// RecordDeparture notes that the user left the workspace of their own
+// accord, replacing any earlier departure.
+func (r *MemberRepository) RecordDeparture(ctx context.Context, workspaceID, userID uuid.UUID, leftAt time.Time) error {
+	query := `INSERT INTO workspace_departures (workspace_id, user_id, left_at) VALUES (?, ?, ?)
+		ON DUPLICATE KEY UPDATE left_at = VALUES(left_at)`
+	_, err := r.db.ExecContext(ctx, query, workspaceID, userID, leftAt)
+	return err
+}
+
+// GetDepartedAt returns when the user last left the workspace, or nil if
+// they never have.
+func (r *MemberRepository) GetDepartedAt(ctx context.Context, workspaceID, userID uuid.UUID) (*time.Time, error) {
+	var leftAt time.Time
+	err := r.db.GetContext(ctx, &leftAt, `SELECT left_at FROM workspace_departures WHERE workspace_id = ? AND user_id = ?`, workspaceID, userID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &leftAt, nil
+}
+
 func (r *MemberRepository) IsMember(ctx context.Context, workspaceID, userID uuid.UUID) (bool, error) {
 	var count int
 	query := `SELECT COUNT(*) FROM workspace_members WHERE workspace_id = ? AND user_id = ? AND is_active = TRUE`
@@ -79,6 +207,41 @@ func (r *MemberRepository) GetRole(ctx context.Context, workspaceID, userID uuid
 	return role, err
 }
 
+func (r *MemberRepository) CountByRole(ctx context.Context, workspaceID uuid.UUID, role string) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM workspace_members WHERE workspace_id = ? AND role = ? AND is_active = TRUE`
+	err := r.db.GetContext(ctx, &count, query, workspaceID, role)
+	return count, err
+}
+
+// GetRoles returns the user's role in each of the given workspaces in one
+// query. Workspaces the user is not an active member of are absent.
+func (r *MemberRepository) GetRoles(ctx context.Context, workspaceIDs []uuid.UUID, userID uuid.UUID) (map[uuid.UUID]string, error) {
+	roles := make(map[uuid.UUID]string, len(workspaceIDs))
+	if len(workspaceIDs) == 0 {
+		return roles, nil
+	}
+
+	query, args, err := sqlx.In(`
+		SELECT workspace_id, role FROM workspace_members
+		WHERE workspace_id IN (?) AND user_id = ? AND is_active = TRUE
+	`, workspaceIDs, userID)
+	if err != nil {
+		return nil, err
+	}
+	var rows []struct {
+		WorkspaceID uuid.UUID `db:"workspace_id"`
+		Role        string    `db:"role"`
+	}
+	if err := r.db.SelectContext(ctx, &rows, r.db.Rebind(query), args...); err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		roles[row.WorkspaceID] = row.Role
+	}
+	return roles, nil
+}
+
 func (r *MemberRepository) GetByID(ctx context.Context, workspaceID, userID uuid.UUID) (*models.WorkspaceMember, error) {
 	var m models.WorkspaceMember
 	query := `SELECT * FROM workspace_members WHERE workspace_id = ? AND user_id = ? AND is_active = TRUE`
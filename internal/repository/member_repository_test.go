@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/quckapp/workspace-service/internal/models"
+)
+
+func TestCreateMemberReplacesInactiveMembership(t *testing.T) {
+	db, mock := newTestDB(t)
+	repo := NewMemberRepository(db)
+	now := time.Now()
+	m := &models.WorkspaceMember{ID: uuid.New(), WorkspaceID: uuid.New(), UserID: uuid.New(), Role: "member", JoinedAt: now, IsActive: true, CreatedAt: now, UpdatedAt: now}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`DELETE FROM workspace_members WHERE workspace_id = \? AND user_id = \? AND is_active = FALSE`).
+		WithArgs(m.WorkspaceID, m.UserID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO workspace_members`).
+		WithArgs(m.ID, m.WorkspaceID, m.UserID, "member", now, nil, true, now, now).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	if err := repo.Create(context.Background(), m); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+}
+
+func TestRemoveMemberDropsCustomRoles(t *testing.T) {
+	db, mock := newTestDB(t)
+	repo := NewMemberRepository(db)
+	workspaceID, userID := uuid.New(), uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE workspace_members SET is_active = FALSE`).
+		WithArgs(sqlmock.AnyArg(), workspaceID, userID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`DELETE FROM workspace_member_roles WHERE workspace_id = \? AND user_id = \?`).
+		WithArgs(workspaceID, userID).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+
+	if err := repo.Remove(context.Background(), workspaceID, userID); err != nil {
+		t.Fatalf("Remove: %v", err)
+	}
+}
+
+func TestRemoveMemberKeepsMembershipWhenRolesCannotBeDropped(t *testing.T) {
+	db, mock := newTestDB(t)
+	repo := NewMemberRepository(db)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE workspace_members SET is_active = FALSE`).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`DELETE FROM workspace_member_roles`).
+		WillReturnError(errors.New("lock wait timeout"))
+	mock.ExpectRollback()
+
+	if err := repo.Remove(context.Background(), uuid.New(), uuid.New()); err == nil {
+		t.Fatal("Remove succeeded although the role assignments were kept")
+	}
+}
+
+func TestGetDepartedAtNeverLeft(t *testing.T) {
+	db, mock := newTestDB(t)
+	repo := NewMemberRepository(db)
+
+	mock.ExpectQuery(`SELECT left_at FROM workspace_departures`).
+		WillReturnRows(sqlmock.NewRows([]string{"left_at"}))
+
+	leftAt, err := repo.GetDepartedAt(context.Background(), uuid.New(), uuid.New())
+	if err != nil || leftAt != nil {
+		t.Errorf("got (%v, %v), want (nil, nil)", leftAt, err)
+	}
+}
@@ -20,11 +20,11 @@ func NewOnboardingRepository(db *sqlx.DB) *OnboardingRepository {
 // ── Checklists ──
 
 func (r *OnboardingRepository) CreateChecklist(ctx context.Context, checklist *models.OnboardingChecklist) error {
-	query := `INSERT INTO onboarding_checklists (id, workspace_id, title, description, is_active, created_by, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+	query := `INSERT INTO onboarding_checklists (id, workspace_id, title, description, is_active, required_only, created_by, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
 	_, err := r.db.ExecContext(ctx, query,
 		checklist.ID, checklist.WorkspaceID, checklist.Title, checklist.Description,
-		checklist.IsActive, checklist.CreatedBy, checklist.CreatedAt, checklist.UpdatedAt)
+		checklist.IsActive, checklist.RequiredOnly, checklist.CreatedBy, checklist.CreatedAt, checklist.UpdatedAt)
 	return err
 }
 
@@ -46,8 +46,8 @@ func (r *OnboardingRepository) ListChecklists(ctx context.Context, workspaceID u
 }
 
 func (r *OnboardingRepository) UpdateChecklist(ctx context.Context, checklist *models.OnboardingChecklist) error {
-	query := `UPDATE onboarding_checklists SET title = ?, description = ?, is_active = ?, updated_at = NOW() WHERE id = ?`
-	_, err := r.db.ExecContext(ctx, query, checklist.Title, checklist.Description, checklist.IsActive, checklist.ID)
+	query := `UPDATE onboarding_checklists SET title = ?, description = ?, is_active = ?, required_only = ?, updated_at = NOW() WHERE id = ?`
+	_, err := r.db.ExecContext(ctx, query, checklist.Title, checklist.Description, checklist.IsActive, checklist.RequiredOnly, checklist.ID)
 	return err
 }
 
@@ -76,6 +76,29 @@ func (r *OnboardingRepository) ListSteps(ctx context.Context, checklistID uuid.U
 	return steps, err
 }
 
+func (r *OnboardingRepository) UpdateStep(ctx context.Context, step *models.OnboardingStep) error {
+	query := `UPDATE onboarding_steps SET title = ?, description = ?, action_type = ?, action_data = ?, is_required = ? WHERE id = ?`
+	_, err := r.db.ExecContext(ctx, query, step.Title, step.Description, step.ActionType, step.ActionData, step.IsRequired, step.ID)
+	return err
+}
+
+func (r *OnboardingRepository) UpdateStepPositions(ctx context.Context, checklistID uuid.UUID, stepIDs []uuid.UUID) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for i, stepID := range stepIDs {
+		_, err := tx.ExecContext(ctx, `UPDATE onboarding_steps SET position = ? WHERE id = ? AND checklist_id = ?`, i+1, stepID, checklistID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
 func (r *OnboardingRepository) DeleteStep(ctx context.Context, id uuid.UUID) error {
 	query := `DELETE FROM onboarding_steps WHERE id = ?`
 	_, err := r.db.ExecContext(ctx, query, id)
@@ -122,6 +145,34 @@ func (r *OnboardingRepository) GetProgress(ctx context.Context, checklistID, use
 	return progress, err
 }
 
+// CountCompletedByUser returns, per active workspace member, how many steps of
+// the checklist they have completed.
+func (r *OnboardingRepository) CountCompletedByUser(ctx context.Context, checklistID uuid.UUID, requiredOnly bool) (map[uuid.UUID]int, error) {
+	type userCount struct {
+		UserID    uuid.UUID `db:"user_id"`
+		Completed int       `db:"completed"`
+	}
+	query := `SELECT p.user_id, COUNT(*) as completed FROM onboarding_progress p
+		JOIN onboarding_steps s ON s.id = p.step_id
+		JOIN onboarding_checklists c ON c.id = s.checklist_id
+		JOIN workspace_members m ON m.workspace_id = c.workspace_id AND m.user_id = p.user_id AND m.is_active = TRUE
+		WHERE s.checklist_id = ?`
+	if requiredOnly {
+		query += ` AND s.is_required = TRUE`
+	}
+	query += ` GROUP BY p.user_id`
+
+	var counts []userCount
+	if err := r.db.SelectContext(ctx, &counts, query, checklistID); err != nil {
+		return nil, err
+	}
+	result := make(map[uuid.UUID]int)
+	for _, uc := range counts {
+		result[uc.UserID] = uc.Completed
+	}
+	return result, nil
+}
+
 func (r *OnboardingRepository) UncompleteStep(ctx context.Context, stepID, userID uuid.UUID) error {
 	query := `DELETE FROM onboarding_progress WHERE step_id = ? AND user_id = ?`
 	_, err := r.db.ExecContext(ctx, query, stepID, userID)
@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/quckapp/workspace-service/internal/models"
+)
+
+type OutboxRepository struct {
+	db *sqlx.DB
+}
+
+func NewOutboxRepository(db *sqlx.DB) *OutboxRepository {
+	return &OutboxRepository{db: db}
+}
+
+// insertOutboxEvent writes an event with any executor so callers can enqueue
+// it inside the transaction that makes the state change.
+func insertOutboxEvent(ctx context.Context, exec sqlx.ExecerContext, e *models.OutboxEvent) error {
+	query := `INSERT INTO workspace_event_outbox (id, topic, event_key, event_type, payload, attempts, created_at)
+		VALUES (?, ?, ?, ?, ?, 0, ?)`
+	_, err := exec.ExecContext(ctx, query, e.ID, e.Topic, e.EventKey, e.EventType, string(e.Payload), e.CreatedAt)
+	return err
+}
+
+// execWithEvent runs write and enqueues event in one transaction, so the
+// event exists if and only if the change does.
+func execWithEvent(ctx context.Context, db *sqlx.DB, event *models.OutboxEvent, write func(*sqlx.Tx) error) error {
+	tx, err := db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := write(tx); err != nil {
+		return err
+	}
+	if err := insertOutboxEvent(ctx, tx, event); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *OutboxRepository) Enqueue(ctx context.Context, e *models.OutboxEvent) error {
+	return insertOutboxEvent(ctx, r.db, e)
+}
+
+// Claim marks up to limit pending events, oldest first, as held by claimID
+// until lease runs out, and returns them. Events another relay holds are
+// skipped so instances sharing the outbox never send the same batch, and
+// events held by a relay that died are claimed again once the lease ends.
+func (r *OutboxRepository) Claim(ctx context.Context, claimID string, lease time.Duration, limit int) ([]*models.OutboxEvent, error) {
+	now := time.Now()
+	query := `UPDATE workspace_event_outbox SET claimed_by = ?, claimed_until = ?
+		WHERE published_at IS NULL AND dead_lettered_at IS NULL AND (claimed_until IS NULL OR claimed_until < ?)
+		ORDER BY created_at ASC, id ASC LIMIT ?`
+	if _, err := r.db.ExecContext(ctx, query, claimID, now.Add(lease), now, limit); err != nil {
+		return nil, err
+	}
+
+	var events []*models.OutboxEvent
+	query = `SELECT * FROM workspace_event_outbox WHERE claimed_by = ? AND published_at IS NULL ORDER BY created_at ASC, id ASC`
+	err := r.db.SelectContext(ctx, &events, query, claimID)
+	return events, err
+}
+
+// Release hands back the events of a claim that were not published, so the
+// next poll can pick them up without waiting for the lease to end.
+func (r *OutboxRepository) Release(ctx context.Context, claimID string) error {
+	query := `UPDATE workspace_event_outbox SET claimed_by = NULL, claimed_until = NULL WHERE claimed_by = ? AND published_at IS NULL`
+	_, err := r.db.ExecContext(ctx, query, claimID)
+	return err
+}
+
+func (r *OutboxRepository) MarkPublished(ctx context.Context, id uuid.UUID) error {
+	query := `UPDATE workspace_event_outbox SET published_at = ?, attempts = attempts + 1, last_error = NULL WHERE id = ?`
+	_, err := r.db.ExecContext(ctx, query, time.Now(), id)
+	return err
+}
+
+// RecordFailure counts a failed attempt and releases the event's claim. The
+// event is dead-lettered, and no longer relayed, once it has failed
+// maxAttempts times.
+func (r *OutboxRepository) RecordFailure(ctx context.Context, id uuid.UUID, errMsg string, maxAttempts int) error {
+	if len(errMsg) > 500 {
+		errMsg = errMsg[:500]
+	}
+	query := `UPDATE workspace_event_outbox SET dead_lettered_at = IF(attempts + 1 >= ?, ?, NULL),
+			attempts = attempts + 1, last_error = ?, claimed_by = NULL, claimed_until = NULL
+		WHERE id = ?`
+	_, err := r.db.ExecContext(ctx, query, maxAttempts, time.Now(), errMsg, id)
+	return err
+}
+
+// DeletePublishedBefore prunes relayed events older than before.
+func (r *OutboxRepository) DeletePublishedBefore(ctx context.Context, before time.Time) (int64, error) {
+	query := `DELETE FROM workspace_event_outbox WHERE published_at IS NOT NULL AND published_at < ?`
+	result, err := r.db.ExecContext(ctx, query, before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func (r *OutboxRepository) GetStats(ctx context.Context) (*models.OutboxStats, error) {
+	var stats models.OutboxStats
+	query := `SELECT COALESCE(SUM(dead_lettered_at IS NULL), 0) AS pending,
+			COALESCE(SUM(dead_lettered_at IS NULL AND attempts > 0), 0) AS failing,
+			COALESCE(SUM(dead_lettered_at IS NOT NULL), 0) AS dead_lettered,
+			MIN(CASE WHEN dead_lettered_at IS NULL THEN created_at END) AS oldest_pending_at
+		FROM workspace_event_outbox WHERE published_at IS NULL`
+	if err := r.db.GetContext(ctx, &stats, query); err != nil {
+		return nil, err
+	}
+	if stats.OldestPendingAt != nil {
+		stats.LagSeconds = time.Since(*stats.OldestPendingAt).Seconds()
+	}
+	return &stats, nil
+}
@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+func TestClaimSkipsEventsHeldByAnotherRelay(t *testing.T) {
+	db, mock := newTestDB(t)
+	repo := NewOutboxRepository(db)
+	id := uuid.New()
+
+	// Only unclaimed or lapsed events are taken, and the batch is read back
+	// by claim ID so two relays never see the same rows.
+	mock.ExpectExec(`UPDATE workspace_event_outbox SET claimed_by = \?, claimed_until = \?\s+WHERE published_at IS NULL AND dead_lettered_at IS NULL AND \(claimed_until IS NULL OR claimed_until < \?\)`).
+		WithArgs("relay-1", sqlmock.AnyArg(), sqlmock.AnyArg(), 10).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`SELECT \* FROM workspace_event_outbox WHERE claimed_by = \? AND published_at IS NULL`).
+		WithArgs("relay-1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "topic", "event_key", "event_type", "attempts"}).
+			AddRow(id.String(), "workspace-events", "k", "workspace.updated", 0))
+
+	events, err := repo.Claim(context.Background(), "relay-1", time.Minute, 10)
+	if err != nil {
+		t.Fatalf("Claim: %v", err)
+	}
+	if len(events) != 1 || events[0].ID != id {
+		t.Errorf("claimed %d events, want the one held by this relay", len(events))
+	}
+}
+
+func TestClaimStopsWhenUpdateFails(t *testing.T) {
+	db, mock := newTestDB(t)
+	repo := NewOutboxRepository(db)
+
+	mock.ExpectExec(`UPDATE workspace_event_outbox SET claimed_by`).
+		WillReturnError(context.DeadlineExceeded)
+
+	if _, err := repo.Claim(context.Background(), "relay-1", time.Minute, 10); err == nil {
+		t.Error("Claim succeeded after the claiming update failed")
+	}
+}
+
+func TestReleaseOnlyReturnsUnpublishedEvents(t *testing.T) {
+	db, mock := newTestDB(t)
+	repo := NewOutboxRepository(db)
+
+	mock.ExpectExec(`UPDATE workspace_event_outbox SET claimed_by = NULL, claimed_until = NULL WHERE claimed_by = \? AND published_at IS NULL`).
+		WithArgs("relay-1").
+		WillReturnResult(sqlmock.NewResult(0, 3))
+
+	if err := repo.Release(context.Background(), "relay-1"); err != nil {
+		t.Fatalf("Release: %v", err)
+	}
+}
+
+func TestRecordFailureDeadLettersAtMaxAttempts(t *testing.T) {
+	db, mock := newTestDB(t)
+	repo := NewOutboxRepository(db)
+	id := uuid.New()
+
+	mock.ExpectExec(`UPDATE workspace_event_outbox SET dead_lettered_at = IF\(attempts \+ 1 >= \?, \?, NULL\),\s+attempts = attempts \+ 1, last_error = \?, claimed_by = NULL, claimed_until = NULL`).
+		WithArgs(10, sqlmock.AnyArg(), "broker down", id).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	if err := repo.RecordFailure(context.Background(), id, "broker down", 10); err != nil {
+		t.Fatalf("RecordFailure: %v", err)
+	}
+}
+
+func TestRecordFailureTruncatesLongErrors(t *testing.T) {
+	db, mock := newTestDB(t)
+	repo := NewOutboxRepository(db)
+	id := uuid.New()
+
+	long := strings.Repeat("x", 800)
+	mock.ExpectExec(`UPDATE workspace_event_outbox SET dead_lettered_at`).
+		WithArgs(10, sqlmock.AnyArg(), long[:500], id).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	if err := repo.RecordFailure(context.Background(), id, long, 10); err != nil {
+		t.Fatalf("RecordFailure: %v", err)
+	}
+}
+
+func TestExecWithEventRollsBackWhenWriteFails(t *testing.T) {
+	db, mock := newTestDB(t)
+
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+
+	err := execWithEvent(context.Background(), db, nil, func(tx *sqlx.Tx) error {
+		return context.Canceled
+	})
+	if err != context.Canceled {
+		t.Errorf("err = %v, want the write error", err)
+	}
+}
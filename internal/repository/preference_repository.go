@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
@@ -40,6 +41,13 @@ func (r *PreferenceRepository) GetByWorkspaceAndUser(ctx context.Context, worksp
 	return &p, err
 }
 
+// ClearExpiredMute clears mute_until when it is no longer in the future.
+// The condition is re-checked in SQL so a mute set concurrently survives.
+func (r *PreferenceRepository) ClearExpiredMute(ctx context.Context, workspaceID, userID uuid.UUID, now time.Time) error {
+	_, err := r.db.ExecContext(ctx, "UPDATE workspace_member_preferences SET mute_until = NULL WHERE workspace_id = ? AND user_id = ? AND mute_until <= ?", workspaceID, userID, now)
+	return err
+}
+
 func (r *PreferenceRepository) Delete(ctx context.Context, workspaceID, userID uuid.UUID) error {
 	_, err := r.db.ExecContext(ctx, "DELETE FROM workspace_member_preferences WHERE workspace_id = ? AND user_id = ?", workspaceID, userID)
 	return err
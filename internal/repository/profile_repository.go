@@ -56,6 +56,19 @@ func (r *ProfileRepository) ListByWorkspace(ctx context.Context, workspaceID uui
 	return profiles, err
 }
 
+func (r *ProfileRepository) ListByUsers(ctx context.Context, workspaceID uuid.UUID, userIDs []uuid.UUID) ([]*models.MemberProfile, error) {
+	if len(userIDs) == 0 {
+		return nil, nil
+	}
+	query, args, err := sqlx.In(`SELECT * FROM workspace_member_profiles WHERE workspace_id = ? AND user_id IN (?)`, workspaceID, userIDs)
+	if err != nil {
+		return nil, err
+	}
+	var profiles []*models.MemberProfile
+	err = r.db.SelectContext(ctx, &profiles, r.db.Rebind(query), args...)
+	return profiles, err
+}
+
 func (r *ProfileRepository) UpdateOnlineStatus(ctx context.Context, workspaceID, userID uuid.UUID, isOnline bool) error {
 	now := time.Now()
 	query := `
@@ -66,6 +79,18 @@ func (r *ProfileRepository) UpdateOnlineStatus(ctx context.Context, workspaceID,
 	return err
 }
 
+// TouchLastSeen records that the member was just seen, creating a bare
+// profile if they have none yet.
+func (r *ProfileRepository) TouchLastSeen(ctx context.Context, workspaceID, userID uuid.UUID, seenAt time.Time) error {
+	query := `
+		INSERT INTO workspace_member_profiles (id, workspace_id, user_id, last_seen_at, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE last_seen_at = VALUES(last_seen_at)
+	`
+	_, err := r.db.ExecContext(ctx, query, uuid.New(), workspaceID, userID, seenAt, seenAt, seenAt)
+	return err
+}
+
 func (r *ProfileRepository) Delete(ctx context.Context, workspaceID, userID uuid.UUID) error {
 	query := `DELETE FROM workspace_member_profiles WHERE workspace_id = ? AND user_id = ?`
 	_, err := r.db.ExecContext(ctx, query, workspaceID, userID)
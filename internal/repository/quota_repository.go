@@ -57,6 +57,72 @@ func (r *QuotaRepository) DecrementUsage(ctx context.Context, workspaceID uuid.U
 	return err
 }
 
+// GetChannelCounts returns current_channels for each workspace that has a
+// quota row; workspaces without one are absent from the map.
+func (r *QuotaRepository) GetChannelCounts(ctx context.Context, workspaceIDs []uuid.UUID) (map[uuid.UUID]int, error) {
+	counts := make(map[uuid.UUID]int, len(workspaceIDs))
+	if len(workspaceIDs) == 0 {
+		return counts, nil
+	}
+
+	query, args, err := sqlx.In(`SELECT workspace_id, current_channels FROM workspace_quotas WHERE workspace_id IN (?)`, workspaceIDs)
+	if err != nil {
+		return nil, err
+	}
+	var rows []struct {
+		WorkspaceID     uuid.UUID `db:"workspace_id"`
+		CurrentChannels int       `db:"current_channels"`
+	}
+	if err := r.db.SelectContext(ctx, &rows, r.db.Rebind(query), args...); err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		counts[row.WorkspaceID] = row.CurrentChannels
+	}
+	return counts, nil
+}
+
+// RecordUsageEntity stores the latest known state of an external entity.
+// An event older than the one already stored is ignored, so replays and
+// out-of-order delivery leave the row unchanged. event_at must be assigned
+// last because MySQL applies the assignments in order.
+func (r *QuotaRepository) RecordUsageEntity(ctx context.Context, e *models.UsageEvent) error {
+	query := `INSERT INTO workspace_external_usage (workspace_id, kind, entity_id, size_bytes, deleted, event_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE
+		size_bytes = IF(VALUES(event_at) > event_at, VALUES(size_bytes), size_bytes),
+		deleted = IF(VALUES(event_at) > event_at, VALUES(deleted), deleted),
+		event_at = GREATEST(event_at, VALUES(event_at))`
+	_, err := r.db.ExecContext(ctx, query, e.WorkspaceID, e.Kind, e.EntityID, e.SizeBytes, e.Deleted, e.OccurredAt)
+	return err
+}
+
+// RecountUsage recomputes current_channels and current_storage_mb from the
+// tracked entities, creating the quota row with default limits if needed.
+func (r *QuotaRepository) RecountUsage(ctx context.Context, workspaceID uuid.UUID) error {
+	var usage struct {
+		Channels     int   `db:"channels"`
+		StorageBytes int64 `db:"storage_bytes"`
+	}
+	err := r.db.GetContext(ctx, &usage, `
+		SELECT COALESCE(SUM(kind = ?), 0) AS channels,
+			COALESCE(SUM(CASE WHEN kind = ? THEN size_bytes ELSE 0 END), 0) AS storage_bytes
+		FROM workspace_external_usage WHERE workspace_id = ? AND deleted = FALSE`,
+		models.UsageKindChannel, models.UsageKindFile, workspaceID)
+	if err != nil {
+		return err
+	}
+
+	const mb = 1 << 20
+	storageMB := int((usage.StorageBytes + mb - 1) / mb)
+	now := time.Now()
+	query := `INSERT INTO workspace_quotas (id, workspace_id, current_channels, current_storage_mb, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE current_channels = VALUES(current_channels), current_storage_mb = VALUES(current_storage_mb), updated_at = VALUES(updated_at)`
+	_, err = r.db.ExecContext(ctx, query, uuid.New(), workspaceID, usage.Channels, storageMB, now, now)
+	return err
+}
+
 func (r *QuotaRepository) Delete(ctx context.Context, workspaceID uuid.UUID) error {
 	_, err := r.db.ExecContext(ctx, "DELETE FROM workspace_quotas WHERE workspace_id = ?", workspaceID)
 	return err
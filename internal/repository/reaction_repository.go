@@ -25,10 +25,13 @@ func (r *ReactionRepository) Create(ctx context.Context, reaction *models.Worksp
 	return err
 }
 
-func (r *ReactionRepository) Delete(ctx context.Context, entityType string, entityID, userID uuid.UUID, emoji string) error {
+func (r *ReactionRepository) Delete(ctx context.Context, entityType string, entityID, userID uuid.UUID, emoji string) (int64, error) {
 	query := `DELETE FROM workspace_reactions WHERE entity_type = ? AND entity_id = ? AND user_id = ? AND emoji = ?`
-	_, err := r.db.ExecContext(ctx, query, entityType, entityID, userID, emoji)
-	return err
+	result, err := r.db.ExecContext(ctx, query, entityType, entityID, userID, emoji)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
 }
 
 func (r *ReactionRepository) Exists(ctx context.Context, entityType string, entityID, userID uuid.UUID, emoji string) (bool, error) {
@@ -56,6 +59,39 @@ func (r *ReactionRepository) GetSummary(ctx context.Context, entityType string,
 	return summaries, err
 }
 
+// CountByEmojiInWorkspace counts reactions per emoji on entities that belong
+// to the workspace, scoped the same way as ListByUserInWorkspace.
+func (r *ReactionRepository) CountByEmojiInWorkspace(ctx context.Context, workspaceID uuid.UUID) ([]models.ReactionSummary, error) {
+	var summaries []models.ReactionSummary
+	query := `
+		SELECT r.emoji, COUNT(*) as count FROM workspace_reactions r
+		WHERE (r.entity_type = 'announcement' AND r.entity_id IN (SELECT id FROM workspace_announcements WHERE workspace_id = ?))
+			OR (r.entity_type = 'pin' AND r.entity_id IN (SELECT id FROM workspace_pinned_items WHERE workspace_id = ?))
+			OR (r.entity_type = 'note' AND r.entity_id IN (SELECT id FROM workspace_member_notes WHERE workspace_id = ?))
+		GROUP BY r.emoji ORDER BY count DESC
+	`
+	err := r.db.SelectContext(ctx, &summaries, query, workspaceID, workspaceID, workspaceID)
+	return summaries, err
+}
+
+// ListByUserInWorkspace returns the user's reactions on entities that belong
+// to the workspace. Reactions carry no workspace ID, so each entity type is
+// scoped through its own table.
+func (r *ReactionRepository) ListByUserInWorkspace(ctx context.Context, workspaceID, userID uuid.UUID) ([]*models.WorkspaceReaction, error) {
+	var reactions []*models.WorkspaceReaction
+	query := `
+		SELECT r.* FROM workspace_reactions r
+		WHERE r.user_id = ? AND (
+			(r.entity_type = 'announcement' AND r.entity_id IN (SELECT id FROM workspace_announcements WHERE workspace_id = ?))
+			OR (r.entity_type = 'pin' AND r.entity_id IN (SELECT id FROM workspace_pinned_items WHERE workspace_id = ?))
+			OR (r.entity_type = 'note' AND r.entity_id IN (SELECT id FROM workspace_member_notes WHERE workspace_id = ?))
+		)
+		ORDER BY r.created_at DESC
+	`
+	err := r.db.SelectContext(ctx, &reactions, query, userID, workspaceID, workspaceID, workspaceID)
+	return reactions, err
+}
+
 func (r *ReactionRepository) DeleteAllByEntity(ctx context.Context, entityType string, entityID uuid.UUID) error {
 	query := `DELETE FROM workspace_reactions WHERE entity_type = ? AND entity_id = ?`
 	_, err := r.db.ExecContext(ctx, query, entityType, entityID)
@@ -28,6 +28,9 @@ func (r *RoleRepository) Create(ctx context.Context, role *models.WorkspaceRole)
 		role.Permissions, role.IsDefault, role.CreatedBy,
 		role.CreatedAt, role.UpdatedAt,
 	)
+	if err == nil {
+		role.Version = 1 // column default
+	}
 	return err
 }
 
@@ -58,13 +61,23 @@ func (r *RoleRepository) ListByWorkspace(ctx context.Context, workspaceID uuid.U
 	return roles, err
 }
 
-func (r *RoleRepository) Update(ctx context.Context, role *models.WorkspaceRole) error {
+// Update writes the role only if its version still matches role.Version,
+// reporting false when another write got there first.
+func (r *RoleRepository) Update(ctx context.Context, role *models.WorkspaceRole) (bool, error) {
 	query := `
-		UPDATE workspace_roles SET name = ?, color = ?, priority = ?, permissions = ?, updated_at = ?
-		WHERE id = ?
+		UPDATE workspace_roles SET name = ?, color = ?, priority = ?, permissions = ?, version = version + 1, updated_at = ?
+		WHERE id = ? AND version = ?
 	`
-	_, err := r.db.ExecContext(ctx, query, role.Name, role.Color, role.Priority, role.Permissions, time.Now(), role.ID)
-	return err
+	result, err := r.db.ExecContext(ctx, query, role.Name, role.Color, role.Priority, role.Permissions, time.Now(), role.ID, role.Version)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	if err != nil || n == 0 {
+		return false, err
+	}
+	role.Version++
+	return true, nil
 }
 
 func (r *RoleRepository) Delete(ctx context.Context, id uuid.UUID) error {
@@ -73,6 +86,70 @@ func (r *RoleRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	return err
 }
 
+// AssignToMember records the assignment unless the member already holds
+// maxRoles custom roles, in which case it reports false. The member's row
+// is locked while counting, so concurrent assignments to the same member
+// cannot both squeeze under the limit.
+func (r *RoleRepository) AssignToMember(ctx context.Context, a *models.MemberRoleAssignment, maxRoles int) (bool, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	var memberID string
+	err = tx.GetContext(ctx, &memberID, `SELECT id FROM workspace_members WHERE workspace_id = ? AND user_id = ? FOR UPDATE`, a.WorkspaceID, a.UserID)
+	if err != nil {
+		return false, err
+	}
+
+	var count int
+	if err := tx.GetContext(ctx, &count, `SELECT COUNT(*) FROM workspace_member_roles WHERE workspace_id = ? AND user_id = ?`, a.WorkspaceID, a.UserID); err != nil {
+		return false, err
+	}
+	if count >= maxRoles {
+		return false, nil
+	}
+
+	query := `
+		INSERT INTO workspace_member_roles (id, workspace_id, user_id, role_id, assigned_by, assigned_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`
+	if _, err := tx.ExecContext(ctx, query, a.ID, a.WorkspaceID, a.UserID, a.RoleID, a.AssignedBy, a.AssignedAt); err != nil {
+		return false, err
+	}
+	return true, tx.Commit()
+}
+
+func (r *RoleRepository) UnassignFromMember(ctx context.Context, workspaceID, userID, roleID uuid.UUID) (bool, error) {
+	query := `DELETE FROM workspace_member_roles WHERE workspace_id = ? AND user_id = ? AND role_id = ?`
+	result, err := r.db.ExecContext(ctx, query, workspaceID, userID, roleID)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
+
+func (r *RoleRepository) IsAssigned(ctx context.Context, workspaceID, userID, roleID uuid.UUID) (bool, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM workspace_member_roles WHERE workspace_id = ? AND user_id = ? AND role_id = ?`
+	err := r.db.GetContext(ctx, &count, query, workspaceID, userID, roleID)
+	return count > 0, err
+}
+
+func (r *RoleRepository) ListByMember(ctx context.Context, workspaceID, userID uuid.UUID) ([]*models.WorkspaceRole, error) {
+	var roles []*models.WorkspaceRole
+	query := `
+		SELECT r.* FROM workspace_roles r
+		JOIN workspace_member_roles mr ON mr.role_id = r.id
+		WHERE mr.workspace_id = ? AND mr.user_id = ?
+		ORDER BY r.priority DESC, r.name ASC
+	`
+	err := r.db.SelectContext(ctx, &roles, query, workspaceID, userID)
+	return roles, err
+}
+
 func (r *RoleRepository) CountByWorkspace(ctx context.Context, workspaceID uuid.UUID) (int, error) {
 	var count int
 	query := `SELECT COUNT(*) FROM workspace_roles WHERE workspace_id = ?`
@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/quckapp/workspace-service/internal/models"
+)
+
+func newAssignment() *models.MemberRoleAssignment {
+	return &models.MemberRoleAssignment{
+		ID:          uuid.New(),
+		WorkspaceID: uuid.New(),
+		UserID:      uuid.New(),
+		RoleID:      uuid.New(),
+		AssignedBy:  uuid.New(),
+		AssignedAt:  time.Now(),
+	}
+}
+
+func TestAssignToMemberUnderLimit(t *testing.T) {
+	db, mock := newTestDB(t)
+	repo := NewRoleRepository(db)
+	a := newAssignment()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT id FROM workspace_members WHERE workspace_id = \? AND user_id = \? FOR UPDATE`).
+		WithArgs(a.WorkspaceID, a.UserID).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.New().String()))
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM workspace_member_roles`).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+	mock.ExpectExec(`INSERT INTO workspace_member_roles`).
+		WithArgs(a.ID, a.WorkspaceID, a.UserID, a.RoleID, a.AssignedBy, a.AssignedAt).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	assigned, err := repo.AssignToMember(context.Background(), a, 3)
+	if err != nil || !assigned {
+		t.Fatalf("AssignToMember = %v, %v; want true, nil", assigned, err)
+	}
+}
+
+func TestAssignToMemberAtLimit(t *testing.T) {
+	db, mock := newTestDB(t)
+	repo := NewRoleRepository(db)
+	a := newAssignment()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FOR UPDATE`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.New().String()))
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM workspace_member_roles`).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+	mock.ExpectRollback()
+
+	assigned, err := repo.AssignToMember(context.Background(), a, 3)
+	if err != nil || assigned {
+		t.Fatalf("AssignToMember = %v, %v; want false, nil", assigned, err)
+	}
+}
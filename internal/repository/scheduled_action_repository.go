@@ -57,6 +57,21 @@ func (r *ScheduledActionRepository) ListDue(ctx context.Context) ([]*models.Sche
 	return actions, err
 }
 
+// ListDueOfTypes returns up to limit actions of the given types whose time
+// has come, oldest first: pending ones, and running ones claimed before
+// staleBefore, whose runner is taken to have died.
+func (r *ScheduledActionRepository) ListDueOfTypes(ctx context.Context, actionTypes []string, staleBefore time.Time, limit int) ([]*models.ScheduledAction, error) {
+	query, args, err := sqlx.In(`SELECT * FROM workspace_scheduled_actions
+		WHERE (status = 'pending' OR (status = 'running' AND updated_at < ?))
+		AND scheduled_at <= NOW() AND action_type IN (?) ORDER BY scheduled_at ASC LIMIT ?`, staleBefore, actionTypes, limit)
+	if err != nil {
+		return nil, err
+	}
+	var actions []*models.ScheduledAction
+	err = r.db.SelectContext(ctx, &actions, r.db.Rebind(query), args...)
+	return actions, err
+}
+
 func (r *ScheduledActionRepository) Update(ctx context.Context, action *models.ScheduledAction) error {
 	query := `UPDATE workspace_scheduled_actions SET payload = ?, scheduled_at = ?, updated_at = ? WHERE id = ?`
 	_, err := r.db.ExecContext(ctx, query, action.Payload, action.ScheduledAt, time.Now(), action.ID)
@@ -68,6 +83,20 @@ func (r *ScheduledActionRepository) UpdateStatus(ctx context.Context, id uuid.UU
 	return err
 }
 
+// Claim moves a pending action to running, reporting false when it was
+// cancelled or another instance got to it first. A running action claimed
+// before staleBefore can be claimed again; updated_at holds the claim time.
+func (r *ScheduledActionRepository) Claim(ctx context.Context, id uuid.UUID, staleBefore time.Time) (bool, error) {
+	query := `UPDATE workspace_scheduled_actions SET status = 'running', updated_at = ?
+		WHERE id = ? AND (status = 'pending' OR (status = 'running' AND updated_at < ?))`
+	result, err := r.db.ExecContext(ctx, query, time.Now(), id, staleBefore)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n == 1, err
+}
+
 func (r *ScheduledActionRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	_, err := r.db.ExecContext(ctx, "DELETE FROM workspace_scheduled_actions WHERE id = ?", id)
 	return err
@@ -77,3 +106,12 @@ func (r *ScheduledActionRepository) CancelPending(ctx context.Context, workspace
 	_, err := r.db.ExecContext(ctx, "UPDATE workspace_scheduled_actions SET status = 'cancelled', updated_at = ? WHERE workspace_id = ? AND status = 'pending'", time.Now(), workspaceID)
 	return err
 }
+
+// CancelPendingByPayload cancels the workspace's pending actions of the type
+// whose payload field holds value.
+func (r *ScheduledActionRepository) CancelPendingByPayload(ctx context.Context, workspaceID uuid.UUID, actionType, field, value string) error {
+	query := `UPDATE workspace_scheduled_actions SET status = 'cancelled', updated_at = ?
+		WHERE workspace_id = ? AND action_type = ? AND status = 'pending' AND JSON_UNQUOTE(JSON_EXTRACT(payload, ?)) = ?`
+	_, err := r.db.ExecContext(ctx, query, time.Now(), workspaceID, actionType, "$."+field, value)
+	return err
+}
@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"time"
 
 	"github.com/google/uuid"
@@ -10,6 +11,14 @@ import (
 	"github.com/quckapp/workspace-service/internal/models"
 )
 
+// streakWriteAttempts bounds how often RecordDailyActivity re-reads the
+// streak after losing a race with a concurrent call.
+const streakWriteAttempts = 3
+
+// ErrStreakContention is returned when the streak kept changing underneath
+// RecordDailyActivity.
+var ErrStreakContention = errors.New("streak changed concurrently")
+
 type StreakRepository struct {
 	db *sqlx.DB
 }
@@ -56,52 +65,94 @@ func (r *StreakRepository) GetLeaderboard(ctx context.Context, workspaceID uuid.
 	return leaderboard, err
 }
 
-func (r *StreakRepository) RecordDailyActivity(ctx context.Context, workspaceID, userID uuid.UUID) error {
-	today := time.Now().Format("2006-01-02")
-
-	existing, err := r.GetByUserID(ctx, workspaceID, userID)
-	if err != nil {
-		return err
+// RecordDailyActivity marks the member active for the calendar day in loc.
+// The streak is read, advanced and written back only if last_active_date
+// is still what was read, so when calls for the same member race, only the
+// first of the day advances the streak and the others re-read and find the
+// day already counted.
+func (r *StreakRepository) RecordDailyActivity(ctx context.Context, workspaceID, userID uuid.UUID, loc *time.Location) error {
+	if loc == nil {
+		loc = time.UTC
 	}
 
-	if existing == nil {
-		streak := &models.MemberActivityStreak{
-			ID:              uuid.New(),
-			WorkspaceID:     workspaceID,
-			UserID:          userID,
-			CurrentStreak:   1,
-			LongestStreak:   1,
-			TotalActiveDays: 1,
-			ActivityScore:   1.0,
-			LastActiveDate:  today,
-			UpdatedAt:       time.Now(),
+	for attempt := 0; attempt < streakWriteAttempts; attempt++ {
+		now := time.Now()
+		existing, err := r.GetByUserID(ctx, workspaceID, userID)
+		if err != nil {
+			return err
+		}
+
+		if existing == nil {
+			streak := &models.MemberActivityStreak{
+				ID:              uuid.New(),
+				WorkspaceID:     workspaceID,
+				UserID:          userID,
+				CurrentStreak:   1,
+				LongestStreak:   1,
+				TotalActiveDays: 1,
+				ActivityScore:   1.0,
+				LastActiveDate:  now.In(loc).Format("2006-01-02"),
+				UpdatedAt:       now,
+			}
+			result, err := r.db.ExecContext(ctx, `INSERT IGNORE INTO member_activity_streaks (id, workspace_id, user_id, current_streak, longest_streak, total_active_days, activity_score, last_active_date, updated_at)
+				VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+				streak.ID, streak.WorkspaceID, streak.UserID, streak.CurrentStreak, streak.LongestStreak,
+				streak.TotalActiveDays, streak.ActivityScore, streak.LastActiveDate, streak.UpdatedAt)
+			if err != nil {
+				return err
+			}
+			if n, err := result.RowsAffected(); err != nil || n == 1 {
+				return err
+			}
+			continue // another call created the row first
+		}
+
+		previous := existing.LastActiveDate
+		if !advanceStreak(existing, now.In(loc)) {
+			return nil
+		}
+		existing.UpdatedAt = now
+
+		result, err := r.db.ExecContext(ctx, `UPDATE member_activity_streaks SET current_streak = ?, longest_streak = ?, total_active_days = ?, activity_score = ?, last_active_date = ?, updated_at = ?
+			WHERE id = ? AND last_active_date = ?`,
+			existing.CurrentStreak, existing.LongestStreak, existing.TotalActiveDays, existing.ActivityScore, existing.LastActiveDate, existing.UpdatedAt,
+			existing.ID, previous)
+		if err != nil {
+			return err
+		}
+		if n, err := result.RowsAffected(); err != nil || n == 1 {
+			return err
 		}
-		return r.Upsert(ctx, streak)
 	}
+	return ErrStreakContention
+}
+
+// advanceStreak applies one day of activity at the local time now to the
+// streak. It returns false when the member was already counted for that day.
+func advanceStreak(streak *models.MemberActivityStreak, now time.Time) bool {
+	today := now.Format("2006-01-02")
 
 	// Already logged today
-	if existing.LastActiveDate == today {
-		return nil
+	if streak.LastActiveDate == today {
+		return false
 	}
 
 	// Check if yesterday was active (continue streak)
-	yesterday := time.Now().AddDate(0, 0, -1).Format("2006-01-02")
-	if existing.LastActiveDate == yesterday {
-		existing.CurrentStreak++
+	yesterday := now.AddDate(0, 0, -1).Format("2006-01-02")
+	if streak.LastActiveDate == yesterday {
+		streak.CurrentStreak++
 	} else {
-		existing.CurrentStreak = 1
+		streak.CurrentStreak = 1
 	}
 
-	if existing.CurrentStreak > existing.LongestStreak {
-		existing.LongestStreak = existing.CurrentStreak
+	if streak.CurrentStreak > streak.LongestStreak {
+		streak.LongestStreak = streak.CurrentStreak
 	}
 
-	existing.TotalActiveDays++
-	existing.ActivityScore = float64(existing.TotalActiveDays) * (1.0 + float64(existing.CurrentStreak)*0.1)
-	existing.LastActiveDate = today
-	existing.UpdatedAt = time.Now()
-
-	return r.Upsert(ctx, existing)
+	streak.TotalActiveDays++
+	streak.ActivityScore = float64(streak.TotalActiveDays) * (1.0 + float64(streak.CurrentStreak)*0.1)
+	streak.LastActiveDate = today
+	return true
 }
 
 func (r *StreakRepository) ResetStreak(ctx context.Context, workspaceID, userID uuid.UUID) error {
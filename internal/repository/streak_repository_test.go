@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/quckapp/workspace-service/internal/models"
+)
+
+func TestAdvanceStreak(t *testing.T) {
+	now := time.Date(2024, 3, 10, 15, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		last     string
+		current  int
+		longest  int
+		total    int
+		advanced bool
+		want     models.MemberActivityStreak
+	}{
+		{"consecutive day", "2024-03-09", 3, 5, 10, true,
+			models.MemberActivityStreak{CurrentStreak: 4, LongestStreak: 5, TotalActiveDays: 11, ActivityScore: 11 * 1.4, LastActiveDate: "2024-03-10"}},
+		{"new longest", "2024-03-09", 5, 5, 10, true,
+			models.MemberActivityStreak{CurrentStreak: 6, LongestStreak: 6, TotalActiveDays: 11, ActivityScore: 11 * 1.6, LastActiveDate: "2024-03-10"}},
+		{"same day", "2024-03-10", 3, 5, 10, false,
+			models.MemberActivityStreak{CurrentStreak: 3, LongestStreak: 5, TotalActiveDays: 10, LastActiveDate: "2024-03-10"}},
+		{"gap", "2024-03-07", 3, 5, 10, true,
+			models.MemberActivityStreak{CurrentStreak: 1, LongestStreak: 5, TotalActiveDays: 11, ActivityScore: 11 * 1.1, LastActiveDate: "2024-03-10"}},
+	}
+	for _, tt := range tests {
+		streak := models.MemberActivityStreak{CurrentStreak: tt.current, LongestStreak: tt.longest, TotalActiveDays: tt.total, LastActiveDate: tt.last}
+		if got := advanceStreak(&streak, now); got != tt.advanced {
+			t.Errorf("%s: advanced = %v, want %v", tt.name, got, tt.advanced)
+		}
+		if math.Abs(streak.ActivityScore-tt.want.ActivityScore) > 1e-9 {
+			t.Errorf("%s: score = %v, want %v", tt.name, streak.ActivityScore, tt.want.ActivityScore)
+		}
+		streak.ActivityScore = tt.want.ActivityScore
+		if streak != tt.want {
+			t.Errorf("%s: got %+v, want %+v", tt.name, streak, tt.want)
+		}
+	}
+}
+
+func TestAdvanceStreakUsesLocalCalendarDay(t *testing.T) {
+	// Kiritimati is UTC+14: 20:00 UTC on the 9th is already the 10th there.
+	loc, err := time.LoadLocation("Pacific/Kiritimati")
+	if err != nil {
+		t.Fatalf("LoadLocation: %v", err)
+	}
+	streak := models.MemberActivityStreak{CurrentStreak: 2, LongestStreak: 2, TotalActiveDays: 2, LastActiveDate: "2024-03-09"}
+
+	if !advanceStreak(&streak, time.Date(2024, 3, 9, 20, 0, 0, 0, time.UTC).In(loc)) {
+		t.Fatal("streak not advanced")
+	}
+	if streak.LastActiveDate != "2024-03-10" || streak.CurrentStreak != 3 {
+		t.Errorf("got %+v, want day 2024-03-10 continuing the streak", streak)
+	}
+}
+
+func expectStreak(mock sqlmock.Sqlmock, id uuid.UUID, current, longest, total int, last string) {
+	mock.ExpectQuery(`SELECT \* FROM member_activity_streaks WHERE workspace_id = \? AND user_id = \?`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "current_streak", "longest_streak", "total_active_days", "last_active_date"}).
+			AddRow(id.String(), current, longest, total, last))
+}
+
+func TestRecordDailyActivityStartsStreakAtOne(t *testing.T) {
+	db, mock := newTestDB(t)
+	repo := NewStreakRepository(db)
+	workspaceID, userID := uuid.New(), uuid.New()
+
+	mock.ExpectQuery(`SELECT \* FROM member_activity_streaks`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectExec(`INSERT IGNORE INTO member_activity_streaks`).
+		WithArgs(sqlmock.AnyArg(), workspaceID, userID, 1, 1, 1, 1.0, time.Now().UTC().Format("2006-01-02"), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	if err := repo.RecordDailyActivity(context.Background(), workspaceID, userID, nil); err != nil {
+		t.Fatalf("RecordDailyActivity: %v", err)
+	}
+}
+
+func TestRecordDailyActivityContinuesStreak(t *testing.T) {
+	db, mock := newTestDB(t)
+	repo := NewStreakRepository(db)
+	id := uuid.New()
+	now := time.Now().UTC()
+	today, yesterday := now.Format("2006-01-02"), now.AddDate(0, 0, -1).Format("2006-01-02")
+
+	expectStreak(mock, id, 3, 3, 7, yesterday)
+	mock.ExpectExec(`UPDATE member_activity_streaks SET .* WHERE id = \? AND last_active_date = \?`).
+		WithArgs(4, 4, 8, 8*1.4, today, sqlmock.AnyArg(), id, yesterday).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	if err := repo.RecordDailyActivity(context.Background(), uuid.New(), uuid.New(), time.UTC); err != nil {
+		t.Fatalf("RecordDailyActivity: %v", err)
+	}
+}
+
+func TestRecordDailyActivitySameDayWritesNothing(t *testing.T) {
+	db, mock := newTestDB(t)
+	repo := NewStreakRepository(db)
+
+	expectStreak(mock, uuid.New(), 3, 3, 7, time.Now().UTC().Format("2006-01-02"))
+
+	if err := repo.RecordDailyActivity(context.Background(), uuid.New(), uuid.New(), time.UTC); err != nil {
+		t.Fatalf("RecordDailyActivity: %v", err)
+	}
+}
+
+func TestRecordDailyActivityResetsAfterGap(t *testing.T) {
+	db, mock := newTestDB(t)
+	repo := NewStreakRepository(db)
+	id := uuid.New()
+	now := time.Now().UTC()
+	lastWeek := now.AddDate(0, 0, -7).Format("2006-01-02")
+
+	expectStreak(mock, id, 6, 6, 20, lastWeek)
+	mock.ExpectExec(`UPDATE member_activity_streaks SET`).
+		WithArgs(1, 6, 21, 21*1.1, now.Format("2006-01-02"), sqlmock.AnyArg(), id, lastWeek).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	if err := repo.RecordDailyActivity(context.Background(), uuid.New(), uuid.New(), time.UTC); err != nil {
+		t.Fatalf("RecordDailyActivity: %v", err)
+	}
+}
+
+func TestRecordDailyActivityLosingRaceCountsDayOnce(t *testing.T) {
+	db, mock := newTestDB(t)
+	repo := NewStreakRepository(db)
+	id := uuid.New()
+	now := time.Now().UTC()
+
+	expectStreak(mock, id, 3, 3, 7, now.AddDate(0, 0, -1).Format("2006-01-02"))
+	mock.ExpectExec(`UPDATE member_activity_streaks SET`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	// The concurrent call already counted today.
+	expectStreak(mock, id, 4, 4, 8, now.Format("2006-01-02"))
+
+	if err := repo.RecordDailyActivity(context.Background(), uuid.New(), uuid.New(), time.UTC); err != nil {
+		t.Fatalf("RecordDailyActivity: %v", err)
+	}
+}
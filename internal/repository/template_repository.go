@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -19,9 +20,9 @@ func NewTemplateRepository(db *sqlx.DB) *TemplateRepository {
 }
 
 func (r *TemplateRepository) Create(ctx context.Context, t *models.WorkspaceTemplate) error {
-	query := `INSERT INTO workspace_templates (id, name, description, created_by, default_roles, default_channels, default_settings, is_public, use_count, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
-	_, err := r.db.ExecContext(ctx, query, t.ID, t.Name, t.Description, t.CreatedBy, t.DefaultRoles, t.DefaultChannels, t.DefaultSettings, t.IsPublic, t.UseCount, t.CreatedAt, t.UpdatedAt)
+	query := `INSERT INTO workspace_templates (id, name, description, created_by, default_roles, default_channels, default_settings, default_tags, default_labels, is_public, use_count, version, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	_, err := r.db.ExecContext(ctx, query, t.ID, t.Name, t.Description, t.CreatedBy, t.DefaultRoles, t.DefaultChannels, t.DefaultSettings, t.DefaultTags, t.DefaultLabels, t.IsPublic, t.UseCount, t.Version, t.CreatedAt, t.UpdatedAt)
 	return err
 }
 
@@ -34,36 +35,135 @@ func (r *TemplateRepository) GetByID(ctx context.Context, id uuid.UUID) (*models
 	return &t, err
 }
 
-func (r *TemplateRepository) ListPublic(ctx context.Context, page, perPage int) ([]*models.WorkspaceTemplate, int64, error) {
+// ListVisible lists templates a user can see: public ones, their own, and
+// those shared with them.
+func (r *TemplateRepository) ListVisible(ctx context.Context, userID uuid.UUID, filter models.TemplateFilter, page, perPage int) ([]*models.WorkspaceTemplate, int64, error) {
+	where := `(is_public = TRUE OR created_by = ? OR id IN (SELECT template_id FROM workspace_template_shares WHERE user_id = ?))`
+	args := []interface{}{userID, userID}
+	if filter.Query != "" {
+		where += ` AND name LIKE ? ESCAPE '\\'`
+		args = append(args, "%"+escapeLike(filter.Query)+"%")
+	}
+
 	var total int64
-	err := r.db.GetContext(ctx, &total, "SELECT COUNT(*) FROM workspace_templates WHERE is_public = TRUE")
+	err := r.db.GetContext(ctx, &total, "SELECT COUNT(*) FROM workspace_templates WHERE "+where, args...)
 	if err != nil {
 		return nil, 0, err
 	}
 
 	offset := (page - 1) * perPage
 	var templates []*models.WorkspaceTemplate
-	err = r.db.SelectContext(ctx, &templates, "SELECT * FROM workspace_templates WHERE is_public = TRUE ORDER BY use_count DESC, created_at DESC LIMIT ? OFFSET ?", perPage, offset)
+	err = r.db.SelectContext(ctx, &templates,
+		"SELECT * FROM workspace_templates WHERE "+where+" ORDER BY "+templateOrderBy(filter)+" LIMIT ? OFFSET ?",
+		append(args, perPage, offset)...)
 	return templates, total, err
 }
 
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// escapeLike escapes the LIKE wildcards in s so it matches literally, using
+// backslash as the escape character.
+func escapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
+
+// templateOrderBy builds the ORDER BY clause from a fixed set of columns so
+// the filter never reaches the query text. id breaks ties to keep paging
+// stable.
+func templateOrderBy(filter models.TemplateFilter) string {
+	dir := "DESC"
+	if filter.Order == "asc" {
+		dir = "ASC"
+	}
+	if filter.Sort == "created_at" {
+		return "created_at " + dir + ", id " + dir
+	}
+	return "use_count " + dir + ", created_at " + dir + ", id " + dir
+}
+
 func (r *TemplateRepository) ListByCreator(ctx context.Context, userID uuid.UUID) ([]*models.WorkspaceTemplate, error) {
 	var templates []*models.WorkspaceTemplate
 	err := r.db.SelectContext(ctx, &templates, "SELECT * FROM workspace_templates WHERE created_by = ? ORDER BY created_at DESC", userID)
 	return templates, err
 }
 
-func (r *TemplateRepository) Update(ctx context.Context, t *models.WorkspaceTemplate) error {
-	query := `UPDATE workspace_templates SET name = ?, description = ?, is_public = ?, updated_at = ? WHERE id = ?`
-	_, err := r.db.ExecContext(ctx, query, t.Name, t.Description, t.IsPublic, time.Now(), t.ID)
+// Update writes the template as its next version, only if its version still
+// matches t.Version, reporting false when another write got there first.
+func (r *TemplateRepository) Update(ctx context.Context, t *models.WorkspaceTemplate) (bool, error) {
+	query := `
+		UPDATE workspace_templates SET name = ?, description = ?, is_public = ?, default_roles = ?, default_channels = ?,
+			default_settings = ?, default_tags = ?, default_labels = ?, version = version + 1, updated_at = ?
+		WHERE id = ? AND version = ?
+	`
+	result, err := r.db.ExecContext(ctx, query, t.Name, t.Description, t.IsPublic, t.DefaultRoles, t.DefaultChannels,
+		t.DefaultSettings, t.DefaultTags, t.DefaultLabels, time.Now(), t.ID, t.Version)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	if err != nil || n == 0 {
+		return false, err
+	}
+	t.Version++
+	return true, nil
+}
+
+// CreateVersion stores a snapshot. Snapshots are immutable, so writing one
+// that already exists is a no-op.
+func (r *TemplateRepository) CreateVersion(ctx context.Context, v *models.WorkspaceTemplateVersion) error {
+	query := `INSERT IGNORE INTO workspace_template_versions (id, template_id, version, name, description, default_roles, default_channels, default_settings, default_tags, default_labels, created_by, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	_, err := r.db.ExecContext(ctx, query, v.ID, v.TemplateID, v.Version, v.Name, v.Description, v.DefaultRoles, v.DefaultChannels, v.DefaultSettings, v.DefaultTags, v.DefaultLabels, v.CreatedBy, v.CreatedAt)
 	return err
 }
 
+func (r *TemplateRepository) GetVersion(ctx context.Context, templateID uuid.UUID, version int) (*models.WorkspaceTemplateVersion, error) {
+	var v models.WorkspaceTemplateVersion
+	err := r.db.GetContext(ctx, &v, "SELECT * FROM workspace_template_versions WHERE template_id = ? AND version = ?", templateID, version)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return &v, err
+}
+
+func (r *TemplateRepository) ListVersions(ctx context.Context, templateID uuid.UUID) ([]*models.WorkspaceTemplateVersion, error) {
+	var versions []*models.WorkspaceTemplateVersion
+	err := r.db.SelectContext(ctx, &versions, "SELECT * FROM workspace_template_versions WHERE template_id = ? ORDER BY version DESC", templateID)
+	return versions, err
+}
+
 func (r *TemplateRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	_, err := r.db.ExecContext(ctx, "DELETE FROM workspace_templates WHERE id = ?", id)
 	return err
 }
 
+// Share is idempotent: sharing with a user who already has access is a no-op.
+func (r *TemplateRepository) Share(ctx context.Context, share *models.WorkspaceTemplateShare) error {
+	query := `INSERT IGNORE INTO workspace_template_shares (template_id, user_id, shared_by, created_at) VALUES (?, ?, ?, ?)`
+	_, err := r.db.ExecContext(ctx, query, share.TemplateID, share.UserID, share.SharedBy, share.CreatedAt)
+	return err
+}
+
+func (r *TemplateRepository) Unshare(ctx context.Context, templateID, userID uuid.UUID) (int64, error) {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM workspace_template_shares WHERE template_id = ? AND user_id = ?", templateID, userID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func (r *TemplateRepository) IsSharedWith(ctx context.Context, templateID, userID uuid.UUID) (bool, error) {
+	var count int
+	err := r.db.GetContext(ctx, &count, "SELECT COUNT(*) FROM workspace_template_shares WHERE template_id = ? AND user_id = ?", templateID, userID)
+	return count > 0, err
+}
+
+func (r *TemplateRepository) ListShares(ctx context.Context, templateID uuid.UUID) ([]*models.WorkspaceTemplateShare, error) {
+	var shares []*models.WorkspaceTemplateShare
+	err := r.db.SelectContext(ctx, &shares, "SELECT * FROM workspace_template_shares WHERE template_id = ? ORDER BY created_at", templateID)
+	return shares, err
+}
+
 func (r *TemplateRepository) IncrementUseCount(ctx context.Context, id uuid.UUID) error {
 	_, err := r.db.ExecContext(ctx, "UPDATE workspace_templates SET use_count = use_count + 1, updated_at = ? WHERE id = ?", time.Now(), id)
 	return err
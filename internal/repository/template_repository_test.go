@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/quckapp/workspace-service/internal/models"
+)
+
+func TestTemplateOrderBy(t *testing.T) {
+	tests := []struct {
+		filter models.TemplateFilter
+		want   string
+	}{
+		{models.TemplateFilter{}, "use_count DESC, created_at DESC, id DESC"},
+		{models.TemplateFilter{Sort: "use_count", Order: "asc"}, "use_count ASC, created_at ASC, id ASC"},
+		{models.TemplateFilter{Sort: "created_at", Order: "desc"}, "created_at DESC, id DESC"},
+		{models.TemplateFilter{Sort: "created_at", Order: "asc"}, "created_at ASC, id ASC"},
+		{models.TemplateFilter{Sort: "name; DROP TABLE workspaces", Order: "sideways"}, "use_count DESC, created_at DESC, id DESC"},
+	}
+	for _, tt := range tests {
+		if got := templateOrderBy(tt.filter); got != tt.want {
+			t.Errorf("templateOrderBy(%+v) = %q, want %q", tt.filter, got, tt.want)
+		}
+	}
+}
+
+func TestListVisibleTemplatesFiltersByName(t *testing.T) {
+	db, mock := newTestDB(t)
+	repo := NewTemplateRepository(db)
+	userID := uuid.New()
+
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM workspace_templates WHERE \(is_public = TRUE OR created_by = \? OR .*\) AND name LIKE \? ESCAPE`).
+		WithArgs(userID, userID, "%onboarding%").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectQuery(`SELECT \* FROM workspace_templates WHERE .* AND name LIKE \? ESCAPE '\\\\' ORDER BY created_at ASC, id ASC LIMIT \? OFFSET \?`).
+		WithArgs(userID, userID, "%onboarding%", 10, 10).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(uuid.New().String(), "Onboarding kit"))
+
+	filter := models.TemplateFilter{Query: "onboarding", Sort: "created_at", Order: "asc"}
+	templates, total, err := repo.ListVisible(context.Background(), userID, filter, 2, 10)
+	if err != nil {
+		t.Fatalf("ListVisible: %v", err)
+	}
+	if total != 1 || len(templates) != 1 {
+		t.Errorf("got %d of %d, want 1 of 1", len(templates), total)
+	}
+}
+
+func TestListVisibleTemplatesWithoutFilter(t *testing.T) {
+	db, mock := newTestDB(t)
+	repo := NewTemplateRepository(db)
+	userID := uuid.New()
+
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM workspace_templates WHERE \(is_public = TRUE OR created_by = \? OR .*\)$`).
+		WithArgs(userID, userID).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectQuery(`\) ORDER BY use_count DESC, created_at DESC, id DESC LIMIT \? OFFSET \?`).
+		WithArgs(userID, userID, 20, 0).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	if _, _, err := repo.ListVisible(context.Background(), userID, models.TemplateFilter{}, 1, 20); err != nil {
+		t.Fatalf("ListVisible: %v", err)
+	}
+}
+
+func TestListVisibleTemplatesEscapesWildcards(t *testing.T) {
+	db, mock := newTestDB(t)
+	repo := NewTemplateRepository(db)
+	userID := uuid.New()
+
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM workspace_templates WHERE .* AND name LIKE \? ESCAPE`).
+		WithArgs(userID, userID, `%100\%\_off\\%`).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectQuery(`SELECT \* FROM workspace_templates WHERE .* AND name LIKE \? ESCAPE`).
+		WithArgs(userID, userID, `%100\%\_off\\%`, 20, 0).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	if _, _, err := repo.ListVisible(context.Background(), userID, models.TemplateFilter{Query: `100%_off\`}, 1, 20); err != nil {
+		t.Fatalf("ListVisible: %v", err)
+	}
+}
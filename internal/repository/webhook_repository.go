@@ -56,7 +56,7 @@ func (r *WebhookRepository) ListActiveByEvent(ctx context.Context, workspaceID u
 	var webhooks []*models.WorkspaceWebhook
 	jsonEvent := fmt.Sprintf(`"%s"`, eventType)
 	err := r.db.SelectContext(ctx, &webhooks,
-		"SELECT * FROM workspace_webhooks WHERE workspace_id = ? AND is_active = TRUE AND JSON_CONTAINS(events, ?)",
+		"SELECT * FROM workspace_webhooks WHERE workspace_id = ? AND is_active = TRUE AND JSON_CONTAINS(events, ?, '$.events')",
 		workspaceID, jsonEvent)
 	return webhooks, err
 }
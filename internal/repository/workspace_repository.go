@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
@@ -24,6 +25,9 @@ func (r *WorkspaceRepository) Create(ctx context.Context, w *models.Workspace) e
 		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 	_, err := r.db.ExecContext(ctx, query, w.ID, w.Name, w.Slug, w.Description, w.IconURL, w.OwnerID, w.Plan, w.Settings, w.IsActive, w.CreatedAt, w.UpdatedAt)
+	if err == nil {
+		w.Version = 1 // column default
+	}
 	return err
 }
 
@@ -47,21 +51,146 @@ func (r *WorkspaceRepository) GetBySlug(ctx context.Context, slug string) (*mode
 	return &w, err
 }
 
-func (r *WorkspaceRepository) Update(ctx context.Context, w *models.Workspace) error {
+// Update writes the workspace only if its version still matches w.Version,
+// reporting false when another write got there first. On success w.Version
+// is advanced to the stored value.
+func (r *WorkspaceRepository) Update(ctx context.Context, w *models.Workspace) (bool, error) {
+	query := `
+		UPDATE workspaces SET name = ?, description = ?, icon_url = ?, settings = ?, version = version + 1, updated_at = ?
+		WHERE id = ? AND version = ? AND deleted_at IS NULL
+	`
+	result, err := r.db.ExecContext(ctx, query, w.Name, w.Description, w.IconURL, w.Settings, time.Now(), w.ID, w.Version)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	if err != nil || n == 0 {
+		return false, err
+	}
+	w.Version++
+	return true, nil
+}
+
+// MergeSettings applies an RFC 7396 JSON merge patch to the workspace
+// settings in a single statement, so concurrent patches to different keys
+// don't overwrite each other.
+func (r *WorkspaceRepository) MergeSettings(ctx context.Context, id uuid.UUID, patch models.JSON) error {
+	data, err := json.Marshal(patch)
+	if err != nil {
+		return err
+	}
 	query := `
-		UPDATE workspaces SET name = ?, description = ?, icon_url = ?, settings = ?, updated_at = ?
+		UPDATE workspaces SET settings = JSON_MERGE_PATCH(COALESCE(settings, JSON_OBJECT()), CAST(? AS JSON)), version = version + 1, updated_at = ?
 		WHERE id = ? AND deleted_at IS NULL
 	`
-	_, err := r.db.ExecContext(ctx, query, w.Name, w.Description, w.IconURL, w.Settings, time.Now(), w.ID)
+	_, err = r.db.ExecContext(ctx, query, string(data), time.Now(), id)
 	return err
 }
 
+func (r *WorkspaceRepository) CreateSettingsChange(ctx context.Context, c *models.WorkspaceSettingsChange) error {
+	query := `INSERT INTO workspace_settings_history (id, workspace_id, actor_id, changes, version, created_at) VALUES (?, ?, ?, ?, ?, ?)`
+	_, err := r.db.ExecContext(ctx, query, c.ID, c.WorkspaceID, c.ActorID, c.Changes, c.Version, c.CreatedAt)
+	return err
+}
+
+func (r *WorkspaceRepository) ListSettingsHistory(ctx context.Context, workspaceID uuid.UUID, page, perPage int) ([]*models.WorkspaceSettingsChange, int64, error) {
+	var total int64
+	err := r.db.GetContext(ctx, &total, "SELECT COUNT(*) FROM workspace_settings_history WHERE workspace_id = ?", workspaceID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * perPage
+	var changes []*models.WorkspaceSettingsChange
+	err = r.db.SelectContext(ctx, &changes,
+		"SELECT * FROM workspace_settings_history WHERE workspace_id = ? ORDER BY created_at DESC, version DESC LIMIT ? OFFSET ?",
+		workspaceID, perPage, offset)
+	return changes, total, err
+}
+
 func (r *WorkspaceRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	query := `UPDATE workspaces SET deleted_at = ? WHERE id = ?`
 	_, err := r.db.ExecContext(ctx, query, time.Now(), id)
 	return err
 }
 
+// GetArchivedByID returns the workspace only while it is archived, since
+// GetByID hides archived workspaces.
+func (r *WorkspaceRepository) GetArchivedByID(ctx context.Context, id uuid.UUID) (*models.Workspace, error) {
+	var w models.Workspace
+	query := `SELECT * FROM workspaces WHERE id = ? AND deleted_at IS NOT NULL`
+	err := r.db.GetContext(ctx, &w, query, id)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return &w, err
+}
+
+// Archive marks a live workspace archived and inactive, reporting false if
+// it was already archived.
+func (r *WorkspaceRepository) Archive(ctx context.Context, id uuid.UUID, archivedAt time.Time) (bool, error) {
+	query := `
+		UPDATE workspaces SET deleted_at = ?, is_active = FALSE, version = version + 1, updated_at = ?
+		WHERE id = ? AND deleted_at IS NULL
+	`
+	result, err := r.db.ExecContext(ctx, query, archivedAt, archivedAt, id)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
+
+// Restore brings an archived workspace back and reactivates it, reporting
+// false if it was not archived.
+func (r *WorkspaceRepository) Restore(ctx context.Context, id uuid.UUID) (bool, error) {
+	query := `
+		UPDATE workspaces SET deleted_at = NULL, is_active = TRUE, version = version + 1, updated_at = ?
+		WHERE id = ? AND deleted_at IS NOT NULL
+	`
+	result, err := r.db.ExecContext(ctx, query, time.Now(), id)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
+
+// ListDeletedBefore returns soft-deleted workspaces whose deletion is older
+// than the cutoff, oldest first.
+func (r *WorkspaceRepository) ListDeletedBefore(ctx context.Context, cutoff time.Time, limit int) ([]*models.Workspace, error) {
+	var workspaces []*models.Workspace
+	query := `SELECT * FROM workspaces WHERE deleted_at IS NOT NULL AND deleted_at < ? ORDER BY deleted_at ASC LIMIT ?`
+	err := r.db.SelectContext(ctx, &workspaces, query, cutoff, limit)
+	return workspaces, err
+}
+
+// HardDelete permanently removes a soft-deleted workspace. Child tables are
+// cleaned up by their ON DELETE CASCADE foreign keys, so the purge is
+// recorded in workspace_purge_log, which has none, in the same transaction.
+func (r *WorkspaceRepository) HardDelete(ctx context.Context, ws *models.Workspace, purgedAt time.Time) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM workspaces WHERE id = ? AND deleted_at IS NOT NULL`, ws.ID)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err != nil || n == 0 {
+		return err
+	}
+
+	query := `INSERT INTO workspace_purge_log (workspace_id, slug, owner_id, deleted_at, purged_at) VALUES (?, ?, ?, ?, ?)`
+	if _, err := tx.ExecContext(ctx, query, ws.ID, ws.Slug, ws.OwnerID, ws.DeletedAt, purgedAt); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
 func (r *WorkspaceRepository) ListByUserID(ctx context.Context, userID uuid.UUID, page, perPage int) ([]*models.Workspace, int64, error) {
 	var workspaces []*models.Workspace
 	var total int64
@@ -92,12 +221,71 @@ func (r *WorkspaceRepository) GetMemberCount(ctx context.Context, workspaceID uu
 	return count, err
 }
 
+// GetMemberCounts returns active member counts for several workspaces in one
+// query. Workspaces without members are absent from the map.
+func (r *WorkspaceRepository) GetMemberCounts(ctx context.Context, workspaceIDs []uuid.UUID) (map[uuid.UUID]int, error) {
+	counts := make(map[uuid.UUID]int, len(workspaceIDs))
+	if len(workspaceIDs) == 0 {
+		return counts, nil
+	}
+
+	query, args, err := sqlx.In(`
+		SELECT workspace_id, COUNT(*) as count FROM workspace_members
+		WHERE workspace_id IN (?) AND is_active = TRUE
+		GROUP BY workspace_id
+	`, workspaceIDs)
+	if err != nil {
+		return nil, err
+	}
+	var rows []struct {
+		WorkspaceID uuid.UUID `db:"workspace_id"`
+		Count       int       `db:"count"`
+	}
+	if err := r.db.SelectContext(ctx, &rows, r.db.Rebind(query), args...); err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		counts[row.WorkspaceID] = row.Count
+	}
+	return counts, nil
+}
+
 func (r *WorkspaceRepository) TransferOwnership(ctx context.Context, workspaceID, newOwnerID uuid.UUID) error {
-	query := `UPDATE workspaces SET owner_id = ?, updated_at = ? WHERE id = ? AND deleted_at IS NULL`
+	query := `UPDATE workspaces SET owner_id = ?, version = version + 1, updated_at = ? WHERE id = ? AND deleted_at IS NULL`
 	_, err := r.db.ExecContext(ctx, query, newOwnerID, time.Now(), workspaceID)
 	return err
 }
 
+// Ownership transfers
+
+func (r *WorkspaceRepository) CreateOwnershipTransfer(ctx context.Context, t *models.OwnershipTransfer) error {
+	query := `INSERT INTO workspace_ownership_transfers (id, workspace_id, from_user_id, to_user_id, status, expires_at, created_at) VALUES (?, ?, ?, ?, ?, ?, ?)`
+	_, err := r.db.ExecContext(ctx, query, t.ID, t.WorkspaceID, t.FromUserID, t.ToUserID, t.Status, t.ExpiresAt, t.CreatedAt)
+	return err
+}
+
+func (r *WorkspaceRepository) GetPendingOwnershipTransfer(ctx context.Context, workspaceID uuid.UUID) (*models.OwnershipTransfer, error) {
+	var t models.OwnershipTransfer
+	query := `SELECT * FROM workspace_ownership_transfers WHERE workspace_id = ? AND status = 'pending' ORDER BY created_at DESC LIMIT 1`
+	err := r.db.GetContext(ctx, &t, query, workspaceID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return &t, err
+}
+
+// ResolveOwnershipTransfer moves a pending transfer to its final status,
+// reporting false if it was no longer pending.
+func (r *WorkspaceRepository) ResolveOwnershipTransfer(ctx context.Context, id uuid.UUID, status string, resolvedBy *uuid.UUID) (bool, error) {
+	query := `UPDATE workspace_ownership_transfers SET status = ?, resolved_by = ?, resolved_at = ? WHERE id = ? AND status = 'pending'`
+	result, err := r.db.ExecContext(ctx, query, status, resolvedBy, time.Now(), id)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n == 1, err
+}
+
 func (r *WorkspaceRepository) Search(ctx context.Context, query string, page, perPage int) ([]*models.Workspace, int64, error) {
 	var workspaces []*models.Workspace
 	var total int64
@@ -177,3 +365,30 @@ func (r *WorkspaceRepository) ListArchivedByUser(ctx context.Context, userID uui
 	err := r.db.SelectContext(ctx, &workspaces, query, userID)
 	return workspaces, err
 }
+
+// AcquireNamedLock takes a MySQL advisory lock (GET_LOCK), waiting up to
+// wait for it. The lock belongs to a dedicated connection, so it does not
+// conflict with row locks taken by the caller's own queries. acquired is
+// false if the wait ran out; release must be called once acquired.
+func (r *WorkspaceRepository) AcquireNamedLock(ctx context.Context, name string, wait time.Duration) (release func(), acquired bool, err error) {
+	conn, err := r.db.Connx(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+
+	var got sql.NullInt64
+	if err := conn.QueryRowxContext(ctx, "SELECT GET_LOCK(?, ?)", name, int(wait.Seconds())).Scan(&got); err != nil {
+		conn.Close()
+		return nil, false, err
+	}
+	if !got.Valid || got.Int64 != 1 {
+		conn.Close()
+		return nil, false, nil
+	}
+
+	release = func() {
+		conn.ExecContext(context.Background(), "SELECT RELEASE_LOCK(?)", name)
+		conn.Close()
+	}
+	return release, true, nil
+}
@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/quckapp/workspace-service/internal/models"
+)
+
+func TestHardDeleteWritesPurgeLogInSameTransaction(t *testing.T) {
+	db, mock := newTestDB(t)
+	repo := NewWorkspaceRepository(db)
+	deletedAt := time.Now().AddDate(0, 0, -40)
+	ws := &models.Workspace{ID: uuid.New(), Slug: "acme", OwnerID: uuid.New(), DeletedAt: &deletedAt}
+	purgedAt := time.Now()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`DELETE FROM workspaces WHERE id = \? AND deleted_at IS NOT NULL`).
+		WithArgs(ws.ID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO workspace_purge_log`).
+		WithArgs(ws.ID, "acme", ws.OwnerID, deletedAt, purgedAt).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	if err := repo.HardDelete(context.Background(), ws, purgedAt); err != nil {
+		t.Fatalf("HardDelete: %v", err)
+	}
+}
+
+func TestHardDeleteSkipsPurgeLogWhenNothingDeleted(t *testing.T) {
+	db, mock := newTestDB(t)
+	repo := NewWorkspaceRepository(db)
+	ws := &models.Workspace{ID: uuid.New()}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`DELETE FROM workspaces`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	if err := repo.HardDelete(context.Background(), ws, time.Now()); err != nil {
+		t.Fatalf("HardDelete: %v", err)
+	}
+}
+
+func TestHardDeleteRollsBackWhenPurgeLogFails(t *testing.T) {
+	db, mock := newTestDB(t)
+	repo := NewWorkspaceRepository(db)
+	ws := &models.Workspace{ID: uuid.New()}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`DELETE FROM workspaces`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO workspace_purge_log`).WillReturnError(context.DeadlineExceeded)
+	mock.ExpectRollback()
+
+	if err := repo.HardDelete(context.Background(), ws, time.Now()); err == nil {
+		t.Error("HardDelete succeeded without a purge record")
+	}
+}
+
+func TestMergeSettingsSendsPatchInOneStatement(t *testing.T) {
+	db, mock := newTestDB(t)
+	repo := NewWorkspaceRepository(db)
+	id := uuid.New()
+
+	mock.ExpectExec(`UPDATE workspaces SET settings = JSON_MERGE_PATCH\(COALESCE\(settings, JSON_OBJECT\(\)\), CAST\(\? AS JSON\)\)`).
+		WithArgs(`{"banner":{"color":"#fff"},"pin_admins_only":null}`, sqlmock.AnyArg(), id).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	patch := models.JSON{"pin_admins_only": nil, "banner": map[string]interface{}{"color": "#fff"}}
+	if err := repo.MergeSettings(context.Background(), id, patch); err != nil {
+		t.Fatalf("MergeSettings: %v", err)
+	}
+}
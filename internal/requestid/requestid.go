@@ -0,0 +1,25 @@
+// Package requestid carries the correlation ID of the HTTP request that
+// caused a piece of work, so logs, events and webhook deliveries it
+// triggers can be tied back to that request.
+package requestid
+
+import "context"
+
+// Header is the HTTP header the ID is accepted from and echoed in.
+const Header = "X-Request-ID"
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying id. An empty id leaves ctx as is.
+func NewContext(ctx context.Context, id string) context.Context {
+	if id == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext returns the ID stored in ctx, or "" if there is none.
+func FromContext(ctx context.Context) string {
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/quckapp/workspace-service/internal/models"
+)
+
+func TestGetAnalyticsCountsDistinctActors(t *testing.T) {
+	s, mock := newTestService(t)
+	workspaceID := uuid.New()
+
+	// The growth, role and contributor queries fail against the mock and
+	// are skipped, as GetAnalytics tolerates.
+	expectRole(mock, "owner")
+	mock.ExpectQuery(`SELECT COUNT\(DISTINCT actor_id\) FROM workspace_activity_log WHERE workspace_id = \? AND created_at >= \?`).
+		WithArgs(workspaceID, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(17))
+
+	analytics, err := s.GetAnalytics(context.Background(), workspaceID, uuid.New(), 30)
+	if err != nil {
+		t.Fatalf("GetAnalytics: %v", err)
+	}
+	if analytics.ActiveMembers != 17 {
+		t.Errorf("active members = %d, want 17", analytics.ActiveMembers)
+	}
+}
+
+func TestGetAnalyticsRequiresPermission(t *testing.T) {
+	s, mock := newTestService(t)
+	expectRole(mock, "member")
+
+	if _, err := s.GetAnalytics(context.Background(), uuid.New(), uuid.New(), 30); err != ErrNotAuthorized {
+		t.Errorf("err = %v, want ErrNotAuthorized", err)
+	}
+}
+
+func TestComputeInviteFunnel(t *testing.T) {
+	funnel := computeInviteFunnel(30, map[string]map[string]int{
+		"email": {"accepted": 3, "pending": 2, "expired": 4, "revoked": 1},
+		"code":  {"accepted": 5},
+	})
+
+	email := funnel.ByMethod["email"]
+	if email.Sent != 10 || email.Accepted != 3 || email.Expired != 4 || email.ConversionRate != 30 {
+		t.Errorf("email stage = %+v, want 10 sent, 3 accepted, 4 expired, 30%%", email)
+	}
+	if code := funnel.ByMethod["code"]; code.Sent != 5 || code.ConversionRate != 100 {
+		t.Errorf("code stage = %+v, want 5 sent at 100%%", code)
+	}
+	if funnel.Total.Sent != 15 || funnel.Total.Accepted != 8 {
+		t.Errorf("total = %+v, want 15 sent and 8 accepted", funnel.Total)
+	}
+}
+
+func TestComputeInviteFunnelEmpty(t *testing.T) {
+	funnel := computeInviteFunnel(7, nil)
+	for _, method := range []string{"email", "code"} {
+		stage, ok := funnel.ByMethod[method]
+		if !ok || stage.Sent != 0 || stage.ConversionRate != 0 {
+			t.Errorf("%s stage = %+v, want present and zero", method, stage)
+		}
+	}
+}
+
+func TestGetInviteFunnelReadsWindow(t *testing.T) {
+	s, mock := newTestService(t)
+	workspaceID := uuid.New()
+
+	expectRole(mock, "owner")
+	mock.ExpectQuery(`FROM workspace_invitation_history\s+WHERE workspace_id = \? AND created_at >= \? GROUP BY 1, 2`).
+		WithArgs(workspaceID, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"method", "status", "count"}).
+			AddRow("email", "accepted", 1).
+			AddRow("email", "expired", 1))
+
+	funnel, err := s.GetInviteFunnel(context.Background(), workspaceID, uuid.New(), 0)
+	if err != nil {
+		t.Fatalf("GetInviteFunnel: %v", err)
+	}
+	if funnel.Days != 30 || funnel.Total.Sent != 2 || funnel.Total.ConversionRate != 50 {
+		t.Errorf("got %d days, %d sent, %v%%; want 30 days, 2 sent, 50%%", funnel.Days, funnel.Total.Sent, funnel.Total.ConversionRate)
+	}
+}
+
+func TestFillDailyCountsZeroFillsGaps(t *testing.T) {
+	start := time.Date(2026, 2, 27, 0, 0, 0, 0, time.UTC)
+	counts := []models.DailyCount{{Date: "2026-02-27", Count: 2}, {Date: "2026-03-01", Count: 5}}
+
+	filled := fillDailyCounts(start, 4, counts)
+	want := []models.DailyCount{
+		{Date: "2026-02-27", Count: 2},
+		{Date: "2026-02-28", Count: 0},
+		{Date: "2026-03-01", Count: 5},
+		{Date: "2026-03-02", Count: 0},
+	}
+	if !reflect.DeepEqual(filled, want) {
+		t.Errorf("got %v, want %v", filled, want)
+	}
+}
+
+func TestGetMemberActivityHeatmapCoversWholeWindow(t *testing.T) {
+	s, mock := newTestService(t)
+	workspaceID, targetID := uuid.New(), uuid.New()
+	today := time.Now().Format("2006-01-02")
+
+	expectMember(mock, true)
+	mock.ExpectQuery(`SELECT DATE_FORMAT\(created_at, '%Y-%m-%d'\) as date, COUNT\(\*\) as count FROM workspace_activity_log\s+WHERE workspace_id = \? AND actor_id = \?`).
+		WithArgs(workspaceID, targetID, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"date", "count"}).AddRow(today, 3))
+
+	heatmap, err := s.GetMemberActivityHeatmap(context.Background(), workspaceID, targetID, uuid.New(), 1000)
+	if err != nil {
+		t.Fatalf("GetMemberActivityHeatmap: %v", err)
+	}
+	if heatmap.Days != 365 || len(heatmap.Counts) != 365 || heatmap.Total != 3 {
+		t.Fatalf("got %d days (%d entries) totalling %d, want 365 totalling 3", heatmap.Days, len(heatmap.Counts), heatmap.Total)
+	}
+	if last := heatmap.Counts[364]; last.Date != today || last.Count != 3 {
+		t.Errorf("last day = %+v, want today with 3", last)
+	}
+}
@@ -0,0 +1,159 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/quckapp/workspace-service/internal/models"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	announcementDigestInterval = 5 * time.Minute
+
+	settingAnnouncementDigestTime = "announcement_digest_time"
+	defaultAnnouncementDigestTime = "09:00"
+	announcementDigestTimeLayout  = "15:04"
+)
+
+// digestSendTime returns the most recent send time at or before now for the
+// workspace's configured HH:MM (UTC) digest time.
+func digestSendTime(settings models.JSON, now time.Time) time.Time {
+	at, _ := time.Parse(announcementDigestTimeLayout, defaultAnnouncementDigestTime)
+	if v, ok := settings[settingAnnouncementDigestTime].(string); ok {
+		if parsed, err := time.Parse(announcementDigestTimeLayout, v); err == nil {
+			at = parsed
+		}
+	}
+
+	now = now.UTC()
+	send := time.Date(now.Year(), now.Month(), now.Day(), at.Hour(), at.Minute(), 0, 0, time.UTC)
+	if send.After(now) {
+		send = send.AddDate(0, 0, -1)
+	}
+	return send
+}
+
+// PublishAnnouncementDigests publishes one workspace.announcement.digest
+// event per workspace whose daily send time has passed, covering the
+// announcements created in the 24 hours before that send time. Workspaces
+// with no announcements in the window are skipped. Each digest is claimed in
+// MySQL first so replicas never send the same day twice.
+func (s *WorkspaceService) PublishAnnouncementDigests(ctx context.Context) (int, error) {
+	now := time.Now().UTC()
+	// A send time can be up to a day old, and its window reaches back
+	// another day before that.
+	workspaceIDs, err := s.announcementRepo.ListWorkspacesWithAnnouncementsSince(ctx, now.Add(-48*time.Hour))
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for _, workspaceID := range workspaceIDs {
+		ok, err := s.publishAnnouncementDigest(ctx, workspaceID, now)
+		if err != nil {
+			s.logger.WithError(err).WithField("workspace_id", workspaceID).Warn("Failed to publish announcement digest")
+			continue
+		}
+		if ok {
+			sent++
+		}
+	}
+	return sent, nil
+}
+
+func (s *WorkspaceService) publishAnnouncementDigest(ctx context.Context, workspaceID uuid.UUID, now time.Time) (bool, error) {
+	workspace, err := s.workspaceRepo.GetByID(ctx, workspaceID)
+	if err != nil || workspace == nil || workspace.DeletedAt != nil {
+		return false, nil
+	}
+
+	periodEnd := digestSendTime(workspace.Settings, now)
+	periodStart := periodEnd.Add(-24 * time.Hour)
+
+	announcements, err := s.announcementRepo.ListCreatedBetween(ctx, workspaceID, periodStart, periodEnd)
+	if err != nil {
+		return false, err
+	}
+	if len(announcements) == 0 {
+		return false, nil
+	}
+
+	if !s.claimAnnouncementDigest(ctx, workspaceID, periodEnd) {
+		return false, nil
+	}
+
+	s.emitWorkspaceEvent(ctx, workspaceID, "workspace.announcement.digest", map[string]interface{}{
+		"workspace_id":  workspaceID,
+		"period_start":  periodStart,
+		"period_end":    periodEnd,
+		"count":         len(announcements),
+		"announcements": announcements,
+	})
+	return true, nil
+}
+
+// claimAnnouncementDigest marks the digest for the send time as sent. It
+// reports false if it was already claimed or the claim fails; skipping a
+// digest is preferable to sending it twice.
+func (s *WorkspaceService) claimAnnouncementDigest(ctx context.Context, workspaceID uuid.UUID, sendTime time.Time) bool {
+	ok, err := s.announcementRepo.ClaimDigest(ctx, workspaceID, sendTime)
+	if err != nil {
+		s.logger.WithError(err).Warn("Failed to claim announcement digest")
+		return false
+	}
+	return ok
+}
+
+// AnnouncementDigester periodically publishes daily announcement digests.
+type AnnouncementDigester struct {
+	workspaces *WorkspaceService
+	logger     *logrus.Logger
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+func NewAnnouncementDigester(workspaces *WorkspaceService, logger *logrus.Logger) *AnnouncementDigester {
+	return &AnnouncementDigester{
+		workspaces: workspaces,
+		logger:     logger,
+		stop:       make(chan struct{}),
+		done:       make(chan struct{}),
+	}
+}
+
+// Start begins checking for due digests on a fixed interval.
+func (d *AnnouncementDigester) Start() {
+	go func() {
+		defer close(d.done)
+		ticker := time.NewTicker(announcementDigestInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				d.publish()
+			case <-d.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the digest loop, waiting for a running pass to finish or for
+// ctx to be done, whichever comes first.
+func (d *AnnouncementDigester) Stop(ctx context.Context) error {
+	close(d.stop)
+	return waitDone(ctx, d.done, "announcement digester")
+}
+
+func (d *AnnouncementDigester) publish() {
+	sent, err := d.workspaces.PublishAnnouncementDigests(context.Background())
+	if err != nil {
+		d.logger.WithError(err).Warn("Failed to publish announcement digests")
+	}
+	if sent > 0 {
+		d.logger.WithField("count", sent).Info("Announcement digests published")
+	}
+}
@@ -0,0 +1,278 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/quckapp/workspace-service/internal/models"
+)
+
+func expectAnnouncement(mock sqlmock.Sqlmock, id, workspaceID uuid.UUID, pinned bool, pinPosition interface{}) {
+	mock.ExpectQuery(`SELECT \* FROM workspace_announcements WHERE id = \?`).
+		WithArgs(id).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "workspace_id", "is_pinned", "pin_position"}).
+			AddRow(id.String(), workspaceID.String(), pinned, pinPosition))
+}
+
+func expectPinnedAnnouncements(mock sqlmock.Sqlmock, workspaceID, viewerID uuid.UUID, ids ...uuid.UUID) {
+	rows := sqlmock.NewRows([]string{"id", "workspace_id", "is_pinned", "pin_position"})
+	for i, id := range ids {
+		rows.AddRow(id.String(), workspaceID.String(), true, i)
+	}
+	mock.ExpectQuery(`SELECT \* FROM workspace_announcements WHERE workspace_id = \? AND is_pinned = TRUE .*\s+AND \(is_published = TRUE OR author_id = \?\)`).
+		WithArgs(workspaceID, viewerID).
+		WillReturnRows(rows)
+}
+
+func TestPinAnnouncementAppendsToPinnedOrder(t *testing.T) {
+	s, mock := newTestService(t)
+	workspaceID, userID, announcementID := uuid.New(), uuid.New(), uuid.New()
+
+	expectRole(mock, "owner")
+	expectAnnouncement(mock, announcementID, workspaceID, false, nil)
+	mock.ExpectQuery(`SELECT MAX\(pin_position\) FROM workspace_announcements`).
+		WithArgs(workspaceID).
+		WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(2))
+	mock.ExpectExec(`UPDATE workspace_announcements SET is_pinned = \?, pin_position = \?`).
+		WithArgs(true, 3, sqlmock.AnyArg(), announcementID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err := s.PinAnnouncement(context.Background(), workspaceID, announcementID, userID, &models.PinAnnouncementRequest{IsPinned: true})
+	if err != nil {
+		t.Fatalf("PinAnnouncement: %v", err)
+	}
+}
+
+func TestPinAnnouncementFirstPinStartsAtZero(t *testing.T) {
+	s, mock := newTestService(t)
+	workspaceID, userID, announcementID := uuid.New(), uuid.New(), uuid.New()
+
+	expectRole(mock, "owner")
+	expectAnnouncement(mock, announcementID, workspaceID, false, nil)
+	mock.ExpectQuery(`SELECT MAX\(pin_position\)`).
+		WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(nil))
+	mock.ExpectExec(`UPDATE workspace_announcements SET is_pinned`).
+		WithArgs(true, 0, sqlmock.AnyArg(), announcementID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err := s.PinAnnouncement(context.Background(), workspaceID, announcementID, userID, &models.PinAnnouncementRequest{IsPinned: true})
+	if err != nil {
+		t.Fatalf("PinAnnouncement: %v", err)
+	}
+}
+
+func TestPinAnnouncementKeepsPositionWhenAlreadyPinned(t *testing.T) {
+	s, mock := newTestService(t)
+	workspaceID, userID, announcementID := uuid.New(), uuid.New(), uuid.New()
+
+	expectRole(mock, "owner")
+	expectAnnouncement(mock, announcementID, workspaceID, true, 1)
+	mock.ExpectExec(`UPDATE workspace_announcements SET is_pinned`).
+		WithArgs(true, 1, sqlmock.AnyArg(), announcementID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err := s.PinAnnouncement(context.Background(), workspaceID, announcementID, userID, &models.PinAnnouncementRequest{IsPinned: true})
+	if err != nil {
+		t.Fatalf("PinAnnouncement: %v", err)
+	}
+}
+
+func TestUnpinAnnouncementClearsPosition(t *testing.T) {
+	s, mock := newTestService(t)
+	workspaceID, userID, announcementID := uuid.New(), uuid.New(), uuid.New()
+
+	expectRole(mock, "owner")
+	expectAnnouncement(mock, announcementID, workspaceID, true, 4)
+	mock.ExpectExec(`UPDATE workspace_announcements SET is_pinned`).
+		WithArgs(false, nil, sqlmock.AnyArg(), announcementID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err := s.PinAnnouncement(context.Background(), workspaceID, announcementID, userID, &models.PinAnnouncementRequest{IsPinned: false})
+	if err != nil {
+		t.Fatalf("PinAnnouncement: %v", err)
+	}
+}
+
+func TestPinAnnouncementRequiresManagePermission(t *testing.T) {
+	s, mock := newTestService(t)
+	expectRole(mock, "member")
+
+	err := s.PinAnnouncement(context.Background(), uuid.New(), uuid.New(), uuid.New(), &models.PinAnnouncementRequest{IsPinned: true})
+	if err != ErrNotAuthorized {
+		t.Errorf("err = %v, want ErrNotAuthorized", err)
+	}
+}
+
+func TestReorderAnnouncementPinsKeepsUnlistedAfterListed(t *testing.T) {
+	s, mock := newTestService(t)
+	workspaceID, userID := uuid.New(), uuid.New()
+	a, b, c := uuid.New(), uuid.New(), uuid.New()
+
+	expectRole(mock, "owner")
+	expectPinnedAnnouncements(mock, workspaceID, userID, a, b, c)
+	mock.ExpectBegin()
+	for i, id := range []uuid.UUID{c, a, b} {
+		mock.ExpectExec(`UPDATE workspace_announcements SET pin_position = \?`).
+			WithArgs(i, id, workspaceID).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+	mock.ExpectCommit()
+	expectPinnedAnnouncements(mock, workspaceID, userID, c, a, b)
+
+	req := &models.ReorderAnnouncementPinsRequest{AnnouncementIDs: []string{c.String(), a.String(), c.String()}}
+	pinned, err := s.ReorderAnnouncementPins(context.Background(), workspaceID, userID, req)
+	if err != nil {
+		t.Fatalf("ReorderAnnouncementPins: %v", err)
+	}
+	if len(pinned) != 3 || pinned[0].ID != c {
+		t.Errorf("got %d pins starting with %v, want 3 starting with %v", len(pinned), pinned[0].ID, c)
+	}
+}
+
+func TestReorderAnnouncementPinsRejectsUnpinnedID(t *testing.T) {
+	s, mock := newTestService(t)
+	workspaceID, userID := uuid.New(), uuid.New()
+
+	expectRole(mock, "owner")
+	expectPinnedAnnouncements(mock, workspaceID, userID, uuid.New())
+
+	req := &models.ReorderAnnouncementPinsRequest{AnnouncementIDs: []string{uuid.New().String()}}
+	if _, err := s.ReorderAnnouncementPins(context.Background(), workspaceID, userID, req); err != ErrAnnouncementNotFound {
+		t.Errorf("err = %v, want ErrAnnouncementNotFound", err)
+	}
+}
+
+func expectDeletableAnnouncement(mock sqlmock.Sqlmock, id, workspaceID uuid.UUID, published bool) {
+	mock.ExpectQuery(`SELECT \* FROM workspace_announcements WHERE id = \?`).
+		WithArgs(id).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "workspace_id", "is_published"}).
+			AddRow(id.String(), workspaceID.String(), published))
+}
+
+func TestDeleteScheduledAnnouncementCancelsPublish(t *testing.T) {
+	s, mock := newTestService(t)
+	workspaceID, announcementID := uuid.New(), uuid.New()
+
+	expectRole(mock, "admin")
+	expectDeletableAnnouncement(mock, announcementID, workspaceID, false)
+	mock.ExpectExec(`UPDATE workspace_scheduled_actions SET status = 'cancelled'`).
+		WithArgs(sqlmock.AnyArg(), workspaceID, actionPublishAnnouncement, "$.announcement_id", announcementID.String()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`DELETE FROM workspace_announcements WHERE id = \?`).
+		WithArgs(announcementID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	if err := s.DeleteAnnouncement(context.Background(), workspaceID, announcementID, uuid.New()); err != nil {
+		t.Fatalf("DeleteAnnouncement: %v", err)
+	}
+}
+
+func TestDeletePublishedAnnouncementLeavesActionsAlone(t *testing.T) {
+	s, mock := newTestService(t)
+	workspaceID, announcementID := uuid.New(), uuid.New()
+
+	expectRole(mock, "admin")
+	expectDeletableAnnouncement(mock, announcementID, workspaceID, true)
+	mock.ExpectExec(`DELETE FROM workspace_announcements WHERE id = \?`).
+		WithArgs(announcementID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	if err := s.DeleteAnnouncement(context.Background(), workspaceID, announcementID, uuid.New()); err != nil {
+		t.Fatalf("DeleteAnnouncement: %v", err)
+	}
+}
+
+func TestNormalizeAnnouncementPriority(t *testing.T) {
+	tests := map[string]string{
+		"important": "high",
+		"urgent":    "urgent",
+		"low":       "low",
+		"":          "",
+	}
+	for in, want := range tests {
+		if got := normalizeAnnouncementPriority(in); got != want {
+			t.Errorf("normalizeAnnouncementPriority(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestDefaultAnnouncementPriority(t *testing.T) {
+	tests := []struct {
+		settings models.JSON
+		want     string
+	}{
+		{nil, "normal"},
+		{models.JSON{settingDefaultAnnouncementPriority: "urgent"}, "urgent"},
+		{models.JSON{settingDefaultAnnouncementPriority: "important"}, "normal"},
+		{models.JSON{settingDefaultAnnouncementPriority: 3}, "normal"},
+	}
+	for _, tt := range tests {
+		if got := defaultAnnouncementPriority(&models.Workspace{Settings: tt.settings}); got != tt.want {
+			t.Errorf("defaultAnnouncementPriority(%v) = %q, want %q", tt.settings, got, tt.want)
+		}
+	}
+}
+
+func TestListAnnouncementsAppliesFilters(t *testing.T) {
+	s, mock := newTestService(t)
+	workspaceID, userID := uuid.New(), uuid.New()
+
+	expectMember(mock, true)
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM workspace_announcements WHERE .* AND \(title LIKE \? OR content LIKE \?\) AND priority = \?`).
+		WithArgs(workspaceID, userID, "%outage%", "%outage%", "high").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectQuery(`SELECT \* FROM workspace_announcements WHERE .* AND priority = \?\s+ORDER BY`).
+		WithArgs(workspaceID, userID, "%outage%", "%outage%", "high", 20, 0).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "workspace_id", "title", "priority"}).
+			AddRow(uuid.New().String(), workspaceID.String(), "Outage tonight", "high"))
+
+	// The legacy "important" level lists as "high", and the search is trimmed.
+	filter := models.AnnouncementFilter{Query: "  outage ", Priority: "important"}
+	announcements, total, err := s.ListAnnouncements(context.Background(), workspaceID, userID, filter, 0, 0)
+	if err != nil {
+		t.Fatalf("ListAnnouncements: %v", err)
+	}
+	if total != 1 || len(announcements) != 1 {
+		t.Errorf("got %d of %d, want 1 of 1", len(announcements), total)
+	}
+}
+
+func TestListAnnouncementsWithoutFilters(t *testing.T) {
+	s, mock := newTestService(t)
+	workspaceID, userID := uuid.New(), uuid.New()
+
+	expectMember(mock, true)
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM workspace_announcements WHERE workspace_id = \? AND .* AND \(is_published = TRUE OR author_id = \?\)$`).
+		WithArgs(workspaceID, userID).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectQuery(`SELECT \* FROM workspace_announcements`).
+		WithArgs(workspaceID, userID, 20, 0).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	if _, _, err := s.ListAnnouncements(context.Background(), workspaceID, userID, models.AnnouncementFilter{}, 1, 20); err != nil {
+		t.Fatalf("ListAnnouncements: %v", err)
+	}
+}
+
+func TestClaimAnnouncementDigestWithoutRedis(t *testing.T) {
+	s, mock := newTestService(t)
+	ctx, workspaceID := context.Background(), uuid.New()
+	sendTime := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+
+	mock.ExpectExec(`INSERT IGNORE INTO workspace_announcement_digests`).
+		WithArgs(workspaceID, "2026-03-01", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT IGNORE INTO workspace_announcement_digests`).
+		WithArgs(workspaceID, "2026-03-01", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	if !s.claimAnnouncementDigest(ctx, workspaceID, sendTime) {
+		t.Fatal("first claim failed")
+	}
+	if s.claimAnnouncementDigest(ctx, workspaceID, sendTime) {
+		t.Error("digest claimed twice for the same day")
+	}
+}
@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/quckapp/workspace-service/internal/models"
+)
+
+const (
+	// apiKeyPrefix marks workspace API keys so the auth middleware can tell
+	// them apart from JWTs.
+	apiKeyPrefix = "wsk_"
+	// apiKeyDisplayLength is how much of the key is kept in clear so owners
+	// can tell their keys apart.
+	apiKeyDisplayLength  = len(apiKeyPrefix) + 8
+	apiKeyLastUsedWindow = time.Minute
+)
+
+func generateAPIKey() string {
+	b := make([]byte, 32)
+	rand.Read(b)
+	return apiKeyPrefix + hex.EncodeToString(b)
+}
+
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// CreateAPIKey issues a key scoped to the given permissions. A key with no
+// permissions can only read.
+func (s *WorkspaceService) CreateAPIKey(ctx context.Context, workspaceID, userID uuid.UUID, req *models.CreateAPIKeyRequest) (*models.CreateAPIKeyResponse, error) {
+	role, _ := s.memberRepo.GetRole(ctx, workspaceID, userID)
+	if role != "owner" && role != "admin" {
+		return nil, ErrNotAuthorized
+	}
+
+	permissions := models.JSON{}
+	for _, key := range req.Permissions {
+		if !permissionKeys[key] {
+			return nil, ErrUnknownPermission
+		}
+		permissions[key] = true
+	}
+
+	key := generateAPIKey()
+	apiKey := &models.WorkspaceAPIKey{
+		ID:          uuid.New(),
+		WorkspaceID: workspaceID,
+		Name:        req.Name,
+		KeyPrefix:   key[:apiKeyDisplayLength],
+		KeyHash:     hashAPIKey(key),
+		Permissions: permissions,
+		CreatedBy:   userID,
+		CreatedAt:   time.Now(),
+	}
+	if err := s.apiKeyRepo.Create(ctx, apiKey); err != nil {
+		return nil, err
+	}
+
+	s.LogActivity(ctx, workspaceID, userID, "api_key.created", "api_key", apiKey.ID.String(), models.JSON{"name": req.Name, "permissions": req.Permissions})
+	return &models.CreateAPIKeyResponse{APIKey: apiKey, Key: key}, nil
+}
+
+func (s *WorkspaceService) ListAPIKeys(ctx context.Context, workspaceID, userID uuid.UUID) ([]*models.WorkspaceAPIKey, error) {
+	role, _ := s.memberRepo.GetRole(ctx, workspaceID, userID)
+	if role != "owner" && role != "admin" {
+		return nil, ErrNotAuthorized
+	}
+	return s.apiKeyRepo.ListByWorkspace(ctx, workspaceID)
+}
+
+func (s *WorkspaceService) RevokeAPIKey(ctx context.Context, workspaceID, keyID, userID uuid.UUID) error {
+	role, _ := s.memberRepo.GetRole(ctx, workspaceID, userID)
+	if role != "owner" && role != "admin" {
+		return ErrNotAuthorized
+	}
+
+	apiKey, err := s.apiKeyRepo.GetByID(ctx, keyID)
+	if err != nil {
+		return err
+	}
+	if apiKey == nil || apiKey.WorkspaceID != workspaceID {
+		return ErrAPIKeyNotFound
+	}
+
+	revoked, err := s.apiKeyRepo.Revoke(ctx, keyID)
+	if err != nil {
+		return err
+	}
+	if revoked == 0 {
+		return ErrAPIKeyNotFound
+	}
+
+	s.LogActivity(ctx, workspaceID, userID, "api_key.revoked", "api_key", keyID.String(), models.JSON{"name": apiKey.Name})
+	return nil
+}
+
+// AuthenticateAPIKey resolves a presented key to its record. Requests made
+// with a key act as the member who created it, so the key stops working
+// once that member is no longer an owner or admin of the workspace.
+func (s *WorkspaceService) AuthenticateAPIKey(ctx context.Context, key string) (*models.WorkspaceAPIKey, error) {
+	if !strings.HasPrefix(key, apiKeyPrefix) {
+		return nil, ErrInvalidAPIKey
+	}
+
+	apiKey, err := s.apiKeyRepo.GetByHash(ctx, hashAPIKey(key))
+	if err != nil {
+		return nil, err
+	}
+	if apiKey == nil || apiKey.RevokedAt != nil {
+		return nil, ErrInvalidAPIKey
+	}
+
+	role, _ := s.memberRepo.GetRole(ctx, apiKey.WorkspaceID, apiKey.CreatedBy)
+	if role != "owner" && role != "admin" {
+		return nil, ErrInvalidAPIKey
+	}
+
+	if err := s.apiKeyRepo.TouchLastUsed(ctx, apiKey.ID, apiKeyLastUsedWindow); err != nil {
+		s.requestLogger(ctx).WithError(err).WithField("api_key_id", apiKey.ID).Warn("Failed to record API key use")
+	}
+	return apiKey, nil
+}
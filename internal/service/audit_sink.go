@@ -0,0 +1,241 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/quckapp/workspace-service/internal/models"
+	"github.com/quckapp/workspace-service/internal/repository"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	auditSinkProvider       = "audit_sink"
+	auditSinkDefaultBatch   = 50
+	auditSinkFlushInterval  = 5 * time.Second
+	auditSinkLookupCacheTTL = time.Minute
+	auditSinkMaxAttempts    = 3
+	auditSinkRetryBackoff   = time.Second
+)
+
+type cachedAuditSink struct {
+	integration *models.WorkspaceIntegration
+	fetchedAt   time.Time
+}
+
+// AuditSink forwards activity log and security audit entries to a
+// workspace's configured external endpoint in signed batches.
+type AuditSink struct {
+	integrationRepo *repository.IntegrationRepository
+	credentials     *CredentialCipher
+	logger          *logrus.Logger
+
+	mu      sync.Mutex
+	pending map[uuid.UUID][]models.AuditSinkRecord
+	sinks   map[uuid.UUID]*cachedAuditSink
+	started bool
+	closed  bool
+
+	deliveries sync.WaitGroup
+	stop       chan struct{}
+	done       chan struct{}
+}
+
+func NewAuditSink(integrationRepo *repository.IntegrationRepository, credentials *CredentialCipher, logger *logrus.Logger) *AuditSink {
+	return &AuditSink{
+		integrationRepo: integrationRepo,
+		credentials:     credentials,
+		logger:          logger,
+		pending:         make(map[uuid.UUID][]models.AuditSinkRecord),
+		sinks:           make(map[uuid.UUID]*cachedAuditSink),
+		stop:            make(chan struct{}),
+		done:            make(chan struct{}),
+	}
+}
+
+// Start begins periodically flushing buffered entries.
+func (a *AuditSink) Start() {
+	a.mu.Lock()
+	a.started = true
+	a.mu.Unlock()
+	go func() {
+		defer close(a.done)
+		ticker := time.NewTicker(auditSinkFlushInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				a.flushAll()
+			case <-a.stop:
+				a.flushAll()
+				return
+			}
+		}
+	}()
+}
+
+// Stop flushes whatever is still buffered, stops the flush loop and waits
+// for deliveries already under way, or for ctx to be done. Entries
+// forwarded after Stop are dropped.
+func (a *AuditSink) Stop(ctx context.Context) error {
+	a.mu.Lock()
+	a.closed = true
+	a.mu.Unlock()
+
+	close(a.stop)
+	if err := waitDone(ctx, a.done, "audit sink flush"); err != nil {
+		return err
+	}
+
+	delivered := make(chan struct{})
+	go func() {
+		a.deliveries.Wait()
+		close(delivered)
+	}()
+	return waitDone(ctx, delivered, "audit sink deliveries")
+}
+
+// Available reports whether batches will actually be delivered: sink
+// secrets can be decrypted and the flush loop is running.
+func (a *AuditSink) Available() bool {
+	if a == nil || a.credentials == nil {
+		return false
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.started && !a.closed
+}
+
+// Forward buffers an entry for the workspace's sink, if one is configured.
+func (a *AuditSink) Forward(ctx context.Context, workspaceID uuid.UUID, source string, entry interface{}) {
+	sink := a.lookup(ctx, workspaceID)
+	if sink == nil || sink.Status != "active" || sink.WebhookURL == nil {
+		return
+	}
+
+	a.mu.Lock()
+	if a.closed {
+		a.mu.Unlock()
+		return
+	}
+	a.pending[workspaceID] = append(a.pending[workspaceID], models.AuditSinkRecord{Source: source, Entry: entry})
+	var batch []models.AuditSinkRecord
+	if len(a.pending[workspaceID]) >= auditSinkBatchSize(sink) {
+		batch = a.pending[workspaceID]
+		delete(a.pending, workspaceID)
+		a.deliveries.Add(1)
+	}
+	a.mu.Unlock()
+
+	if batch != nil {
+		go func() {
+			defer a.deliveries.Done()
+			a.deliver(workspaceID, sink, batch)
+		}()
+	}
+}
+
+// Invalidate drops the cached sink configuration for a workspace.
+func (a *AuditSink) Invalidate(workspaceID uuid.UUID) {
+	a.mu.Lock()
+	delete(a.sinks, workspaceID)
+	a.mu.Unlock()
+}
+
+func (a *AuditSink) lookup(ctx context.Context, workspaceID uuid.UUID) *models.WorkspaceIntegration {
+	a.mu.Lock()
+	cached, ok := a.sinks[workspaceID]
+	a.mu.Unlock()
+	if ok && time.Since(cached.fetchedAt) < auditSinkLookupCacheTTL {
+		return cached.integration
+	}
+
+	var sink *models.WorkspaceIntegration
+	integrations, err := a.integrationRepo.ListByProvider(ctx, workspaceID, auditSinkProvider)
+	if err != nil {
+		a.logger.WithError(err).WithField("workspace_id", workspaceID).Warn("Failed to look up audit sink")
+		return nil
+	}
+	if len(integrations) > 0 {
+		sink = integrations[0]
+	}
+
+	a.mu.Lock()
+	a.sinks[workspaceID] = &cachedAuditSink{integration: sink, fetchedAt: time.Now()}
+	a.mu.Unlock()
+	return sink
+}
+
+func (a *AuditSink) flushAll() {
+	a.mu.Lock()
+	pending := a.pending
+	a.pending = make(map[uuid.UUID][]models.AuditSinkRecord)
+	a.mu.Unlock()
+
+	for workspaceID, batch := range pending {
+		sink := a.lookup(context.Background(), workspaceID)
+		if sink == nil || sink.Status != "active" || sink.WebhookURL == nil {
+			continue
+		}
+		a.deliver(workspaceID, sink, batch)
+	}
+}
+
+func (a *AuditSink) deliver(workspaceID uuid.UUID, sink *models.WorkspaceIntegration, batch []models.AuditSinkRecord) {
+	secret := ""
+	if sink.Credentials != nil {
+		var err error
+		if secret, err = a.credentials.Decrypt(*sink.Credentials); err != nil {
+			a.logger.WithError(err).WithField("workspace_id", workspaceID).Warn("Failed to decrypt audit sink secret")
+			return
+		}
+	}
+
+	payload := map[string]interface{}{
+		"type":         "audit.batch",
+		"workspace_id": workspaceID,
+		"count":        len(batch),
+		"entries":      batch,
+		"timestamp":    time.Now(),
+	}
+
+	var err error
+	for attempt := 1; attempt <= auditSinkMaxAttempts; attempt++ {
+		if err = postSignedJSON(context.Background(), *sink.WebhookURL, secret, payload); err == nil {
+			a.integrationRepo.UpdateSyncTime(context.Background(), sink.ID)
+			return
+		}
+		if attempt == auditSinkMaxAttempts {
+			break
+		}
+		// Back off between attempts, but retry at once when shutting down
+		// so Stop is not held up by the wait.
+		select {
+		case <-time.After(auditSinkRetryBackoff << (attempt - 1)):
+		case <-a.stop:
+		}
+	}
+	a.logger.WithError(err).WithFields(logrus.Fields{
+		"workspace_id": workspaceID,
+		"entries":      len(batch),
+		"attempts":     auditSinkMaxAttempts,
+	}).Warn("Failed to forward audit batch")
+}
+
+func auditSinkBatchSize(sink *models.WorkspaceIntegration) int {
+	if sink.Config != nil {
+		switch v := sink.Config["batch_size"].(type) {
+		case float64:
+			if v >= 1 {
+				return int(v)
+			}
+		case int:
+			if v >= 1 {
+				return v
+			}
+		}
+	}
+	return auditSinkDefaultBatch
+}
@@ -0,0 +1,148 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/quckapp/workspace-service/internal/models"
+	"github.com/quckapp/workspace-service/internal/repository"
+)
+
+func TestIsPublicIP(t *testing.T) {
+	tests := []struct {
+		ip   string
+		want bool
+	}{
+		{"93.184.216.34", true},
+		{"2606:2800:220:1:248:1893:25c8:1946", true},
+		{"127.0.0.1", false},
+		{"::1", false},
+		{"10.1.2.3", false},
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"169.254.169.254", false},
+		{"fe80::1", false},
+		{"100.64.0.1", false},
+		{"100.127.255.254", false},
+		{"100.128.0.1", true},
+		{"0.0.0.0", false},
+		{"224.0.0.1", false},
+	}
+	for _, tt := range tests {
+		if got := isPublicIP(net.ParseIP(tt.ip)); got != tt.want {
+			t.Errorf("isPublicIP(%s) = %v, want %v", tt.ip, got, tt.want)
+		}
+	}
+}
+
+func TestRefusePrivateAddress(t *testing.T) {
+	if err := refusePrivateAddress("tcp", "93.184.216.34:443", nil); err != nil {
+		t.Errorf("public address refused: %v", err)
+	}
+	for _, addr := range []string{"127.0.0.1:443", "10.0.0.5:80", "169.254.169.254:80", "[::1]:443"} {
+		if err := refusePrivateAddress("tcp", addr, nil); err == nil {
+			t.Errorf("%s was allowed", addr)
+		}
+	}
+}
+
+func TestWebhookClientRefusesLoopback(t *testing.T) {
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+	}))
+	defer server.Close()
+
+	if err := postSignedJSON(context.Background(), server.URL, "secret", map[string]string{}); err == nil {
+		t.Error("delivery to a loopback sink succeeded")
+	}
+	if atomic.LoadInt32(&hits) != 0 {
+		t.Error("loopback sink received a request")
+	}
+}
+
+func newTestAuditSink(t *testing.T) (*AuditSink, sqlmock.Sqlmock) {
+	db, mock := newTestDB(t)
+	return NewAuditSink(repository.NewIntegrationRepository(db), nil, testLogger()), mock
+}
+
+func TestAuditSinkDeliverRetriesAndSigns(t *testing.T) {
+	var calls int32
+	var body []byte
+	var signature string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		body, _ = io.ReadAll(r.Body)
+		signature = r.Header.Get("X-Webhook-Signature")
+	}))
+	defer server.Close()
+	useWebhookClient(t, server.Client())
+
+	a, mock := newTestAuditSink(t)
+	sink := &models.WorkspaceIntegration{ID: uuid.New(), Status: "active", WebhookURL: &server.URL}
+	mock.ExpectExec(`UPDATE workspace_integrations SET last_sync_at`).
+		WithArgs(sink.ID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	a.deliver(uuid.New(), sink, []models.AuditSinkRecord{{Source: "activity_log", Entry: "x"}})
+
+	if got := atomic.LoadInt32(&calls); got != 2 {
+		t.Fatalf("sink called %d times, want 2", got)
+	}
+	mac := hmac.New(sha256.New, []byte(""))
+	mac.Write(body)
+	if want := hex.EncodeToString(mac.Sum(nil)); signature != want {
+		t.Errorf("signature = %q, want %q", signature, want)
+	}
+}
+
+func TestAuditSinkDropsEntriesAfterStop(t *testing.T) {
+	a, _ := newTestAuditSink(t)
+	workspaceID := uuid.New()
+	url := "https://siem.example.com/ingest"
+	a.sinks[workspaceID] = &cachedAuditSink{
+		integration: &models.WorkspaceIntegration{ID: uuid.New(), Status: "active", WebhookURL: &url},
+		fetchedAt:   time.Now(),
+	}
+	a.Start()
+	if err := a.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop: %v", err)
+	}
+
+	a.Forward(context.Background(), workspaceID, "activity_log", "late entry")
+	if n := len(a.pending[workspaceID]); n != 0 {
+		t.Errorf("%d entries buffered after Stop, want 0", n)
+	}
+}
+
+func TestAuditSinkBatchSize(t *testing.T) {
+	tests := []struct {
+		config models.JSON
+		want   int
+	}{
+		{nil, auditSinkDefaultBatch},
+		{models.JSON{"batch_size": float64(10)}, 10},
+		{models.JSON{"batch_size": 5}, 5},
+		{models.JSON{"batch_size": float64(0)}, auditSinkDefaultBatch},
+		{models.JSON{"batch_size": "20"}, auditSinkDefaultBatch},
+	}
+	for _, tt := range tests {
+		if got := auditSinkBatchSize(&models.WorkspaceIntegration{Config: tt.config}); got != tt.want {
+			t.Errorf("auditSinkBatchSize(%v) = %d, want %d", tt.config, got, tt.want)
+		}
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/quckapp/workspace-service/internal/models"
 	"github.com/quckapp/workspace-service/internal/repository"
+	"github.com/redis/go-redis/v9"
 	"github.com/sirupsen/logrus"
 )
 
@@ -19,16 +20,18 @@ var (
 	ErrCannotDowngrade      = errors.New("cannot downgrade with current usage")
 	ErrInsufficientSeats    = errors.New("cannot remove seats below current member count")
 	ErrAlreadyOnPlan        = errors.New("workspace is already on this plan")
+	ErrInvalidPlanType      = errors.New("invalid plan type")
 )
 
 type BillingService struct {
 	billingRepo *repository.BillingRepository
 	memberRepo  *repository.MemberRepository
+	kv          kvStore
 	logger      *logrus.Logger
 }
 
-func NewBillingService(billingRepo *repository.BillingRepository, memberRepo *repository.MemberRepository, logger *logrus.Logger) *BillingService {
-	return &BillingService{billingRepo: billingRepo, memberRepo: memberRepo, logger: logger}
+func NewBillingService(billingRepo *repository.BillingRepository, memberRepo *repository.MemberRepository, redis *redis.Client, logger *logrus.Logger) *BillingService {
+	return &BillingService{billingRepo: billingRepo, memberRepo: memberRepo, kv: newKVStore(redis), logger: logger}
 }
 
 func (s *BillingService) GetBillingOverview(ctx context.Context, workspaceID uuid.UUID) (*models.BillingOverview, error) {
@@ -94,9 +97,6 @@ func (s *BillingService) ChangePlan(ctx context.Context, workspaceID, userID uui
 		if req.BillingCycle == "annual" {
 			plan.CurrentPeriodEnd = now.AddDate(1, 0, 0)
 		}
-		if err := s.billingRepo.CreatePlan(ctx, plan); err != nil {
-			return nil, err
-		}
 	} else {
 		if plan.PlanType == req.PlanType {
 			return nil, ErrAlreadyOnPlan
@@ -108,22 +108,54 @@ func (s *BillingService) ChangePlan(ctx context.Context, workspaceID, userID uui
 		plan.PricePerSeat = features.PricePerSeat
 		plan.Status = "active"
 		plan.CanceledAt = nil
-		if err := s.billingRepo.UpdatePlan(ctx, plan); err != nil {
-			return nil, err
-		}
+		plan.UpdatedAt = now
 	}
 
+	if err := s.ApplyPlan(ctx, workspaceID, userID, plan); err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+// ApplyPlan moves a workspace onto plan. The plan record, the workspace's
+// plan column, the quota limits the plan sets and the plan_changed billing
+// event are written in one transaction, so they cannot drift apart when a
+// write fails; workspace caches are cleared once it commits.
+func (s *BillingService) ApplyPlan(ctx context.Context, workspaceID, actorID uuid.UUID, plan *models.WorkspacePlan) error {
+	if !isKnownPlanType(plan.PlanType) {
+		return ErrInvalidPlanType
+	}
+	plan.WorkspaceID = workspaceID
+
+	now := time.Now()
+	features := planFeatures(plan.PlanType)
+	quota := &models.WorkspaceQuota{
+		ID:           uuid.New(),
+		WorkspaceID:  workspaceID,
+		MaxMembers:   features.MaxMembers,
+		MaxChannels:  features.MaxChannels,
+		MaxStorageMB: int(features.MaxStorageMB),
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
 	event := &models.BillingEvent{
 		ID:          uuid.New(),
 		WorkspaceID: workspaceID,
 		EventType:   "plan_changed",
-		Description: fmt.Sprintf("Plan changed to %s (%s)", req.PlanType, req.BillingCycle),
-		ActorID:     userID,
+		Description: fmt.Sprintf("Plan changed to %s (%s)", plan.PlanType, plan.BillingCycle),
+		ActorID:     actorID,
 		CreatedAt:   now,
 	}
-	s.billingRepo.CreateEvent(ctx, event)
 
-	return plan, nil
+	applied, err := s.billingRepo.ApplyPlan(ctx, plan, quota, event)
+	if err != nil {
+		return err
+	}
+	if !applied {
+		return ErrWorkspaceNotFound
+	}
+	s.kv.Del(ctx, workspaceCacheKeys(workspaceID)...)
+	return nil
 }
 
 func (s *BillingService) CancelPlan(ctx context.Context, workspaceID, userID uuid.UUID) error {
@@ -206,6 +238,56 @@ func (s *BillingService) RemoveSeats(ctx context.Context, workspaceID, userID uu
 	return plan, nil
 }
 
+// GetPlanSeatUtilization reconciles purchased seats against active members
+// for every workspace on the plan. Workspaces with the largest overage come
+// first.
+func (s *BillingService) GetPlanSeatUtilization(ctx context.Context, planType string) (*models.PlanSeatUtilization, error) {
+	if !isPlanType(planType) {
+		return nil, ErrInvalidPlanType
+	}
+
+	usage, err := s.billingRepo.ListSeatUsageByPlan(ctx, planType)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.PlanSeatUtilization{
+		PlanType:    planType,
+		Workspaces:  usage,
+		GeneratedAt: time.Now(),
+	}
+	for _, u := range usage {
+		u.Overage = seatOverage(u.PurchasedSeats, u.ActiveMembers)
+		u.Utilization = percentage(u.ActiveMembers, u.PurchasedSeats)
+		report.TotalPurchasedSeats += u.PurchasedSeats
+		report.TotalActiveMembers += u.ActiveMembers
+		report.TotalOverage += u.Overage
+		if u.Overage > 0 {
+			report.WorkspacesOverLimit++
+		}
+	}
+	if report.Workspaces == nil {
+		report.Workspaces = []*models.SeatUtilization{}
+	}
+	return report, nil
+}
+
+// seatOverage is the number of active members beyond the purchased seats.
+func seatOverage(purchased, active int) int {
+	if active <= purchased {
+		return 0
+	}
+	return active - purchased
+}
+
+func isPlanType(planType string) bool {
+	switch planType {
+	case "free", "starter", "pro", "business", "enterprise":
+		return true
+	}
+	return false
+}
+
 func (s *BillingService) ListInvoices(ctx context.Context, workspaceID uuid.UUID, page, perPage int) ([]*models.BillingInvoice, error) {
 	if perPage > 100 {
 		perPage = 100
@@ -287,16 +369,30 @@ func (s *BillingService) GetAvailablePlans() []models.PlanFeatures {
 }
 
 func (s *BillingService) GetPlanFeatures(planType string) models.PlanFeatures {
+	return planFeatures(planType)
+}
+
+func isKnownPlanType(planType string) bool {
+	switch planType {
+	case "free", "starter", "pro", "business", "enterprise":
+		return true
+	}
+	return false
+}
+
+// planFeatures returns the limits and features of a plan type. Unknown plan
+// types get the free plan.
+func planFeatures(planType string) models.PlanFeatures {
 	switch planType {
 	case "starter":
-		return models.PlanFeatures{PlanType: "starter", MaxMembers: 25, MaxChannels: 100, MaxStorageMB: 10240, MaxIntegrations: 5, CustomEmoji: true, AdvancedSecurity: false, AuditLogs: false, Compliance: false, SSO: false, GuestAccess: true, PricePerSeat: 500}
+		return models.PlanFeatures{PlanType: "starter", MaxMembers: 25, MaxChannels: 100, MaxStorageMB: 10240, MaxIntegrations: 5, CustomEmoji: true, AdvancedSecurity: false, AuditLogs: false, Compliance: false, SSO: false, GuestAccess: true, PricePerSeat: 500, MaxBookmarksPerUser: 100, MaxInvitesPerDay: 200}
 	case "pro":
-		return models.PlanFeatures{PlanType: "pro", MaxMembers: 100, MaxChannels: 500, MaxStorageMB: 51200, MaxIntegrations: 20, CustomEmoji: true, AdvancedSecurity: true, AuditLogs: true, Compliance: false, SSO: false, GuestAccess: true, PricePerSeat: 1000}
+		return models.PlanFeatures{PlanType: "pro", MaxMembers: 100, MaxChannels: 500, MaxStorageMB: 51200, MaxIntegrations: 20, CustomEmoji: true, AdvancedSecurity: true, AuditLogs: true, Compliance: false, SSO: false, GuestAccess: true, PricePerSeat: 1000, MaxBookmarksPerUser: 250, MaxInvitesPerDay: 500}
 	case "business":
-		return models.PlanFeatures{PlanType: "business", MaxMembers: 500, MaxChannels: 2000, MaxStorageMB: 204800, MaxIntegrations: 50, CustomEmoji: true, AdvancedSecurity: true, AuditLogs: true, Compliance: true, SSO: true, GuestAccess: true, PricePerSeat: 1500}
+		return models.PlanFeatures{PlanType: "business", MaxMembers: 500, MaxChannels: 2000, MaxStorageMB: 204800, MaxIntegrations: 50, CustomEmoji: true, AdvancedSecurity: true, AuditLogs: true, Compliance: true, SSO: true, GuestAccess: true, PricePerSeat: 1500, MaxBookmarksPerUser: 500, MaxInvitesPerDay: 2000}
 	case "enterprise":
-		return models.PlanFeatures{PlanType: "enterprise", MaxMembers: 10000, MaxChannels: 10000, MaxStorageMB: 1048576, MaxIntegrations: 100, CustomEmoji: true, AdvancedSecurity: true, AuditLogs: true, Compliance: true, SSO: true, GuestAccess: true, PricePerSeat: 2500}
+		return models.PlanFeatures{PlanType: "enterprise", MaxMembers: 10000, MaxChannels: 10000, MaxStorageMB: 1048576, MaxIntegrations: 100, CustomEmoji: true, AdvancedSecurity: true, AuditLogs: true, Compliance: true, SSO: true, GuestAccess: true, PricePerSeat: 2500, MaxBookmarksPerUser: 2000, MaxInvitesPerDay: 10000}
 	default: // free
-		return models.PlanFeatures{PlanType: "free", MaxMembers: 10, MaxChannels: 20, MaxStorageMB: 5120, MaxIntegrations: 2, CustomEmoji: false, AdvancedSecurity: false, AuditLogs: false, Compliance: false, SSO: false, GuestAccess: false, PricePerSeat: 0}
+		return models.PlanFeatures{PlanType: "free", MaxMembers: 10, MaxChannels: 20, MaxStorageMB: 5120, MaxIntegrations: 2, CustomEmoji: false, AdvancedSecurity: false, AuditLogs: false, Compliance: false, SSO: false, GuestAccess: false, PricePerSeat: 0, MaxBookmarksPerUser: 100, MaxInvitesPerDay: 50}
 	}
 }
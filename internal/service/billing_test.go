@@ -0,0 +1,129 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/quckapp/workspace-service/internal/models"
+	"github.com/quckapp/workspace-service/internal/repository"
+)
+
+func newTestBillingService(t *testing.T) (*BillingService, sqlmock.Sqlmock) {
+	db, mock := newTestDB(t)
+	return NewBillingService(repository.NewBillingRepository(db), repository.NewMemberRepository(db), nil, testLogger()), mock
+}
+
+func TestGetPlanSeatUtilizationTotalsOverage(t *testing.T) {
+	s, mock := newTestBillingService(t)
+	mock.ExpectQuery(`FROM workspace_plans p`).
+		WithArgs("pro").
+		WillReturnRows(sqlmock.NewRows([]string{"workspace_id", "workspace_name", "status", "purchased_seats", "active_members"}).
+			AddRow(uuid.New().String(), "Acme", "active", 10, 13).
+			AddRow(uuid.New().String(), "Globex", "active", 20, 5).
+			AddRow(uuid.New().String(), "Initech", "past_due", 0, 2))
+
+	report, err := s.GetPlanSeatUtilization(context.Background(), "pro")
+	if err != nil {
+		t.Fatalf("GetPlanSeatUtilization: %v", err)
+	}
+	if report.TotalPurchasedSeats != 30 || report.TotalActiveMembers != 20 || report.TotalOverage != 5 || report.WorkspacesOverLimit != 2 {
+		t.Errorf("totals = %d purchased, %d active, %d over in %d workspaces; want 30, 20, 5 in 2",
+			report.TotalPurchasedSeats, report.TotalActiveMembers, report.TotalOverage, report.WorkspacesOverLimit)
+	}
+	acme, globex, initech := report.Workspaces[0], report.Workspaces[1], report.Workspaces[2]
+	if acme.Overage != 3 || acme.Utilization != 130 {
+		t.Errorf("Acme = %d over at %v%%, want 3 at 130%%", acme.Overage, acme.Utilization)
+	}
+	if globex.Overage != 0 || globex.Utilization != 25 {
+		t.Errorf("Globex = %d over at %v%%, want 0 at 25%%", globex.Overage, globex.Utilization)
+	}
+	if initech.Overage != 2 || initech.Utilization != 0 {
+		t.Errorf("Initech = %d over at %v%%, want 2 at 0%%", initech.Overage, initech.Utilization)
+	}
+}
+
+func TestGetPlanSeatUtilizationEmptyPlan(t *testing.T) {
+	s, mock := newTestBillingService(t)
+	mock.ExpectQuery(`FROM workspace_plans p`).
+		WillReturnRows(sqlmock.NewRows([]string{"workspace_id"}))
+
+	report, err := s.GetPlanSeatUtilization(context.Background(), "free")
+	if err != nil {
+		t.Fatalf("GetPlanSeatUtilization: %v", err)
+	}
+	if report.Workspaces == nil || len(report.Workspaces) != 0 {
+		t.Errorf("workspaces = %v, want an empty, non-nil list", report.Workspaces)
+	}
+}
+
+func TestGetPlanSeatUtilizationRejectsUnknownPlan(t *testing.T) {
+	s, _ := newTestBillingService(t)
+	if _, err := s.GetPlanSeatUtilization(context.Background(), "platinum"); err != ErrInvalidPlanType {
+		t.Errorf("err = %v, want ErrInvalidPlanType", err)
+	}
+}
+
+func TestSeatOverage(t *testing.T) {
+	tests := []struct{ purchased, active, want int }{
+		{10, 4, 0},
+		{10, 10, 0},
+		{10, 12, 2},
+		{0, 3, 3},
+	}
+	for _, tt := range tests {
+		if got := seatOverage(tt.purchased, tt.active); got != tt.want {
+			t.Errorf("seatOverage(%d, %d) = %d, want %d", tt.purchased, tt.active, got, tt.want)
+		}
+	}
+}
+
+func TestApplyPlanKeepsCachesWhenTransactionFails(t *testing.T) {
+	s, mock := newTestBillingService(t)
+	kv := newMemKVStore()
+	s.kv = kv
+	workspaceID := uuid.New()
+	cacheKey := workspaceCacheKeys(workspaceID)[0]
+	kv.Set(context.Background(), cacheKey, "cached", time.Minute)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE workspaces SET plan = \?`).
+		WithArgs("pro", sqlmock.AnyArg(), workspaceID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO workspace_plans`).
+		WillReturnError(errors.New("deadlock"))
+	mock.ExpectRollback()
+
+	plan := &models.WorkspacePlan{ID: uuid.New(), PlanType: "pro", Status: "active", UpdatedAt: time.Now()}
+	if err := s.ApplyPlan(context.Background(), workspaceID, uuid.New(), plan); err == nil {
+		t.Fatal("ApplyPlan succeeded, want the write error")
+	}
+	if !kv.Exists(context.Background(), cacheKey) {
+		t.Error("workspace cache cleared although nothing changed")
+	}
+}
+
+func TestApplyPlanMissingWorkspace(t *testing.T) {
+	s, mock := newTestBillingService(t)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE workspaces SET plan = \?`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	plan := &models.WorkspacePlan{ID: uuid.New(), PlanType: "pro"}
+	if err := s.ApplyPlan(context.Background(), uuid.New(), uuid.New(), plan); err != ErrWorkspaceNotFound {
+		t.Errorf("err = %v, want ErrWorkspaceNotFound", err)
+	}
+}
+
+func TestApplyPlanRejectsUnknownPlanType(t *testing.T) {
+	s, _ := newTestBillingService(t)
+
+	plan := &models.WorkspacePlan{ID: uuid.New(), PlanType: "platinum"}
+	if err := s.ApplyPlan(context.Background(), uuid.New(), uuid.New(), plan); err != ErrInvalidPlanType {
+		t.Errorf("err = %v, want ErrInvalidPlanType", err)
+	}
+}
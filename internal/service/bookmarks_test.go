@@ -0,0 +1,182 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/quckapp/workspace-service/internal/models"
+)
+
+func expectBookmarkFolders(mock sqlmock.Sqlmock, folders ...string) {
+	rows := sqlmock.NewRows([]string{"folder_name"})
+	for _, f := range folders {
+		rows.AddRow(f)
+	}
+	mock.ExpectQuery(`SELECT DISTINCT folder_name FROM workspace_bookmarks`).WillReturnRows(rows)
+}
+
+func TestRemapBookmarkFoldersTrimsAndSkipsNoOps(t *testing.T) {
+	s, mock := newTestService(t)
+	workspaceID, userID := uuid.New(), uuid.New()
+
+	expectMember(mock, true)
+	mock.ExpectExec(`UPDATE workspace_bookmarks SET folder_name = CASE folder_name WHEN \? THEN \? END`).
+		WithArgs("Inbox", "Archive", workspaceID, userID, "Inbox").
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	expectBookmarkFolders(mock, "Archive", "Same")
+
+	resp, err := s.RemapBookmarkFolders(context.Background(), workspaceID, userID, map[string]string{
+		" Inbox ": "Archive ",
+		"Same":    "Same",
+		"  ":      "Anything",
+	})
+	if err != nil {
+		t.Fatalf("RemapBookmarkFolders: %v", err)
+	}
+	if resp.Updated != 3 || len(resp.Folders) != 2 {
+		t.Errorf("got %+v, want 3 updated and 2 folders", resp)
+	}
+}
+
+func TestRemapBookmarkFoldersWithNothingToDoSkipsUpdate(t *testing.T) {
+	s, mock := newTestService(t)
+
+	expectMember(mock, true)
+	expectBookmarkFolders(mock, "Work")
+
+	resp, err := s.RemapBookmarkFolders(context.Background(), uuid.New(), uuid.New(), map[string]string{"Work": "Work"})
+	if err != nil {
+		t.Fatalf("RemapBookmarkFolders: %v", err)
+	}
+	if resp.Updated != 0 {
+		t.Errorf("updated = %d, want 0", resp.Updated)
+	}
+}
+
+func TestRemapBookmarkFoldersRequiresMembership(t *testing.T) {
+	s, mock := newTestService(t)
+	expectMember(mock, false)
+
+	if _, err := s.RemapBookmarkFolders(context.Background(), uuid.New(), uuid.New(), map[string]string{"a": "b"}); err != ErrNotMember {
+		t.Errorf("err = %v, want ErrNotMember", err)
+	}
+}
+
+func TestRenameBookmarkFolder(t *testing.T) {
+	s, mock := newTestService(t)
+	workspaceID, userID := uuid.New(), uuid.New()
+
+	expectMember(mock, true)
+	mock.ExpectExec(`UPDATE workspace_bookmarks SET folder_name = \?, updated_at = NOW\(\) WHERE workspace_id = \? AND user_id = \? AND folder_name = \?`).
+		WithArgs("Done", workspaceID, userID, "Inbox").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	expectBookmarkFolders(mock, "Done")
+
+	resp, err := s.RenameBookmarkFolder(context.Background(), workspaceID, userID, " Inbox", "Done ")
+	if err != nil {
+		t.Fatalf("RenameBookmarkFolder: %v", err)
+	}
+	if resp.Updated != 2 || len(resp.Folders) != 1 {
+		t.Errorf("got %+v, want 2 updated and 1 folder", resp)
+	}
+}
+
+func TestRenameBookmarkFolderUnknownFolder(t *testing.T) {
+	s, mock := newTestService(t)
+
+	expectMember(mock, true)
+	mock.ExpectExec(`UPDATE workspace_bookmarks SET folder_name = \?`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	if _, err := s.RenameBookmarkFolder(context.Background(), uuid.New(), uuid.New(), "Missing", "Done"); err != ErrBookmarkFolderNotFound {
+		t.Errorf("err = %v, want ErrBookmarkFolderNotFound", err)
+	}
+}
+
+func TestRenameBookmarkFolderRejectsBlankName(t *testing.T) {
+	s, mock := newTestService(t)
+	expectMember(mock, true)
+
+	if _, err := s.RenameBookmarkFolder(context.Background(), uuid.New(), uuid.New(), "Inbox", "  "); err != ErrInvalidFolderName {
+		t.Errorf("err = %v, want ErrInvalidFolderName", err)
+	}
+}
+
+func TestDeleteBookmarkFolderMovesToUnfiled(t *testing.T) {
+	s, mock := newTestService(t)
+	workspaceID, userID := uuid.New(), uuid.New()
+
+	expectMember(mock, true)
+	mock.ExpectExec(`UPDATE workspace_bookmarks SET folder_name = NULL`).
+		WithArgs(workspaceID, userID, "Inbox").
+		WillReturnResult(sqlmock.NewResult(0, 4))
+	expectBookmarkFolders(mock)
+
+	resp, err := s.DeleteBookmarkFolder(context.Background(), workspaceID, userID, "Inbox", true)
+	if err != nil {
+		t.Fatalf("DeleteBookmarkFolder: %v", err)
+	}
+	if resp.Updated != 4 || resp.Folders == nil || len(resp.Folders) != 0 {
+		t.Errorf("got %+v, want 4 updated and an empty folder list", resp)
+	}
+}
+
+func TestDeleteBookmarkFolderDeletesBookmarks(t *testing.T) {
+	s, mock := newTestService(t)
+	workspaceID, userID := uuid.New(), uuid.New()
+
+	expectMember(mock, true)
+	mock.ExpectExec(`DELETE FROM workspace_bookmarks WHERE workspace_id = \? AND user_id = \? AND folder_name = \?`).
+		WithArgs(workspaceID, userID, "Old").
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	expectBookmarkFolders(mock, "Work")
+
+	if _, err := s.DeleteBookmarkFolder(context.Background(), workspaceID, userID, "Old", false); err != nil {
+		t.Fatalf("DeleteBookmarkFolder: %v", err)
+	}
+}
+
+func TestBookmarkLimit(t *testing.T) {
+	tests := []struct {
+		plan     string
+		settings models.JSON
+		role     string
+		want     int
+	}{
+		{"free", nil, "member", 100},
+		{"pro", nil, "member", 250},
+		{"enterprise", nil, "admin", 2000},
+		{"pro", models.JSON{settingMaxBookmarksPerUser: float64(40)}, "member", 40},
+		{"pro", models.JSON{settingMaxBookmarksPerUser: float64(1000)}, "member", 250},
+		{"pro", models.JSON{settingMaxBookmarksPerUser: float64(5)}, guestRole, 5},
+		{"pro", nil, guestRole, guestBookmarkLimit},
+	}
+	for _, tt := range tests {
+		ws := &models.Workspace{Plan: tt.plan, Settings: tt.settings}
+		if got := bookmarkLimit(ws, tt.role); got != tt.want {
+			t.Errorf("bookmarkLimit(%s, %v, %s) = %d, want %d", tt.plan, tt.settings, tt.role, got, tt.want)
+		}
+	}
+}
+
+func TestCreateBookmarkStopsAtLimit(t *testing.T) {
+	s, mock := newTestService(t)
+	workspaceID, userID := uuid.New(), uuid.New()
+
+	expectRole(mock, "member")
+	expectWorkspace(mock, workspaceID)
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM workspace_bookmarks`).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(100))
+
+	_, err := s.CreateBookmark(context.Background(), workspaceID, userID, &models.CreateBookmarkRequest{Title: "Docs"})
+	var limitErr *BookmarkLimitError
+	if !errors.As(err, &limitErr) || !errors.Is(err, ErrBookmarkLimitReached) {
+		t.Fatalf("err = %v, want a BookmarkLimitError", err)
+	}
+	if limitErr.Count != 100 || limitErr.Limit != 100 {
+		t.Errorf("got %d/%d, want 100/100", limitErr.Count, limitErr.Limit)
+	}
+}
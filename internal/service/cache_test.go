@@ -0,0 +1,116 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/quckapp/workspace-service/internal/models"
+)
+
+// ttlKVStore records the TTL of every Set so tests can check which cache
+// setting a write used.
+type ttlKVStore struct {
+	*memKVStore
+	ttls map[string]time.Duration
+}
+
+func newTTLKVStore() *ttlKVStore {
+	return &ttlKVStore{memKVStore: newMemKVStore(), ttls: map[string]time.Duration{}}
+}
+
+func (m *ttlKVStore) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) {
+	m.memKVStore.Set(ctx, key, value, ttl)
+	m.ttls[key] = ttl
+}
+
+func TestCacheConfigWithDefaults(t *testing.T) {
+	c := CacheConfig{StatsTTL: time.Minute}.withDefaults()
+	if c.WorkspaceTTL != cacheTTL || c.StatsTTL != time.Minute || c.NegativeTTL != negativeCacheTTL {
+		t.Errorf("got %+v, want unset TTLs defaulted and StatsTTL kept", c)
+	}
+}
+
+func TestCacheWritesUseConfiguredTTLs(t *testing.T) {
+	s, _ := newTestService(t)
+	kv := newTTLKVStore()
+	s.kv = kv
+	s.cacheConfig = CacheConfig{WorkspaceTTL: time.Minute, StatsTTL: 2 * time.Minute, NegativeTTL: 3 * time.Second}
+	id := uuid.New()
+
+	s.cacheWorkspace(context.Background(), id, &models.Workspace{ID: id})
+	s.cacheStats(context.Background(), id, &models.WorkspaceStats{})
+	s.tombstoneWorkspace(context.Background(), uuid.New())
+
+	var workspace, stats, missing time.Duration
+	for key, ttl := range kv.ttls {
+		switch key {
+		case fmt.Sprintf(cacheKeyWorkspace, id.String()):
+			workspace = ttl
+		case fmt.Sprintf(cacheKeyStats, id.String()):
+			stats = ttl
+		default:
+			missing = ttl
+		}
+	}
+	if workspace != time.Minute || stats != 2*time.Minute || missing != 3*time.Second {
+		t.Errorf("TTLs = %v, %v, %v; want 1m, 2m, 3s", workspace, stats, missing)
+	}
+}
+
+func TestRecordCacheLookupCountsHitsAndMisses(t *testing.T) {
+	s, _ := newTestService(t)
+	s.recordCacheLookup("workspace", true)
+	s.recordCacheLookup("workspace", false)
+	s.recordCacheLookup("workspace", false)
+	s.recordCacheLookup("stats", true)
+
+	if hits, misses := loadCounter(&s.cacheHits, "workspace"), loadCounter(&s.cacheMisses, "workspace"); hits != 1 || misses != 2 {
+		t.Errorf("workspace cache: %d hits, %d misses; want 1 and 2", hits, misses)
+	}
+	if misses := loadCounter(&s.cacheMisses, "stats"); misses != 0 {
+		t.Errorf("stats cache: %d misses, want 0", misses)
+	}
+}
+
+func TestGetWorkspaceRemembersMissingIDs(t *testing.T) {
+	s, mock := newTestService(t)
+	s.kv = newMemKVStore()
+	id := uuid.New()
+
+	mock.ExpectQuery(`SELECT \* FROM workspaces WHERE id = \?`).
+		WithArgs(id).
+		WillReturnError(sql.ErrNoRows)
+
+	for i := 0; i < 2; i++ {
+		if _, err := s.GetWorkspace(context.Background(), id, uuid.New()); err != ErrWorkspaceNotFound {
+			t.Fatalf("lookup %d: err = %v, want ErrWorkspaceNotFound", i+1, err)
+		}
+	}
+	// The second lookup is answered by the tombstone, so MySQL sees only
+	// the first.
+	if hits := loadCounter(&s.cacheHits, "workspace_missing"); hits != 1 {
+		t.Errorf("%d tombstone hits, want 1", hits)
+	}
+}
+
+func TestGetWorkspaceDoesNotTombstoneOnDatabaseError(t *testing.T) {
+	s, mock := newTestService(t)
+	kv := newMemKVStore()
+	s.kv = kv
+	id := uuid.New()
+
+	mock.ExpectQuery(`SELECT \* FROM workspaces WHERE id = \?`).
+		WillReturnError(errors.New("connection reset"))
+
+	if _, err := s.GetWorkspace(context.Background(), id, uuid.New()); err != ErrWorkspaceNotFound {
+		t.Fatalf("err = %v, want ErrWorkspaceNotFound", err)
+	}
+	if kv.Exists(context.Background(), fmt.Sprintf(cacheKeyMissing, id.String())) {
+		t.Error("a failed query tombstoned the workspace")
+	}
+}
@@ -0,0 +1,46 @@
+package service
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestCapabilitiesReflectConfiguredSubsystems(t *testing.T) {
+	s, _ := newTestService(t)
+	if got := s.Capabilities(); len(got) != 0 {
+		t.Errorf("Capabilities() = %v, want none", got)
+	}
+
+	s.kv = newMemKVStore()
+	if got, want := s.Capabilities(), []string{"redis"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Capabilities() = %v, want %v", got, want)
+	}
+}
+
+// TestCapabilitiesReportAuditSinkOnlyWhileRunning wires the audit sink the
+// way cmd/server does and checks it is reported only while it can deliver.
+func TestCapabilitiesReportAuditSinkOnlyWhileRunning(t *testing.T) {
+	s, _ := newTestService(t)
+	credentials, err := NewCredentialCipher(DeriveCredentialKey("jwt-secret"), nil)
+	if err != nil {
+		t.Fatalf("NewCredentialCipher: %v", err)
+	}
+	s.auditSink = NewAuditSink(s.integrationRepo, credentials, testLogger())
+
+	if got := s.Capabilities(); len(got) != 0 {
+		t.Errorf("before Start: Capabilities() = %v, want none", got)
+	}
+
+	s.auditSink.Start()
+	if got, want := s.Capabilities(), []string{"audit_sink"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("running: Capabilities() = %v, want %v", got, want)
+	}
+
+	if err := s.auditSink.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop: %v", err)
+	}
+	if got := s.Capabilities(); len(got) != 0 {
+		t.Errorf("after Stop: Capabilities() = %v, want none", got)
+	}
+}
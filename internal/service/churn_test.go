@@ -0,0 +1,38 @@
+package service
+
+import (
+	"math"
+	"testing"
+)
+
+func TestComputeChurnStats(t *testing.T) {
+	tests := []struct {
+		name                                    string
+		currentMembers, joins, leaves, removals int
+		wantStart, wantNet                      int
+		wantRate                                float64
+	}{
+		{"steady", 100, 10, 5, 5, 100, 0, 10},
+		{"growing", 120, 30, 10, 0, 100, 20, 10},
+		{"no departures", 50, 5, 0, 0, 45, 5, 0},
+		{"empty workspace", 0, 0, 0, 0, 0, 0, 0},
+		// Everyone in the window joined and left again, so there was no
+		// starting membership to measure against.
+		{"all churned within window", 0, 4, 2, 2, 0, 0, 100},
+		{"start clamped at zero", 3, 10, 1, 0, 0, 9, 10},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := computeChurnStats(30, tt.currentMembers, tt.joins, tt.leaves, tt.removals)
+			if got.StartMembers != tt.wantStart || got.NetGrowth != tt.wantNet {
+				t.Errorf("start=%d net=%d, want start=%d net=%d", got.StartMembers, got.NetGrowth, tt.wantStart, tt.wantNet)
+			}
+			if math.Abs(got.ChurnRate-tt.wantRate) > 1e-9 {
+				t.Errorf("churn rate = %v, want %v", got.ChurnRate, tt.wantRate)
+			}
+			if got.Days != 30 || got.Leaves != tt.leaves || got.Removals != tt.removals {
+				t.Errorf("counts not carried through: %+v", got)
+			}
+		})
+	}
+}
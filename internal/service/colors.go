@@ -0,0 +1,40 @@
+package service
+
+import "strings"
+
+// normalizeColor validates a hex color and returns it in canonical #rrggbb
+// form. Short #rgb values are expanded and letters are lowercased, so every
+// color column stores the same shape regardless of what the client sent.
+func normalizeColor(color string) (string, error) {
+	c := strings.ToLower(strings.TrimSpace(color))
+	if !strings.HasPrefix(c, "#") {
+		return "", ErrInvalidColor
+	}
+	hex := c[1:]
+	for _, r := range hex {
+		if !(r >= '0' && r <= '9') && !(r >= 'a' && r <= 'f') {
+			return "", ErrInvalidColor
+		}
+	}
+
+	switch len(hex) {
+	case 6:
+		return c, nil
+	case 3:
+		return "#" + string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]}), nil
+	}
+	return "", ErrInvalidColor
+}
+
+// normalizeOptionalColor is normalizeColor for nullable color fields. Nil
+// stays nil.
+func normalizeOptionalColor(color *string) (*string, error) {
+	if color == nil {
+		return nil, nil
+	}
+	c, err := normalizeColor(*color)
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/quckapp/workspace-service/internal/models"
+)
+
+func TestNormalizeColor(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"#1a2b3c", "#1a2b3c"},
+		{"#1A2B3C", "#1a2b3c"},
+		{"  #abc ", "#aabbcc"},
+		{"#F0A", "#ff00aa"},
+	}
+	for _, tt := range tests {
+		got, err := normalizeColor(tt.in)
+		if err != nil || got != tt.want {
+			t.Errorf("normalizeColor(%q) = %q, %v; want %q", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestNormalizeColorRejectsInvalid(t *testing.T) {
+	for _, in := range []string{"", "#", "1a2b3c", "#12345", "#1234567", "#ggg", "#12345g", "red", "#+1a2b3"} {
+		if got, err := normalizeColor(in); err != ErrInvalidColor {
+			t.Errorf("normalizeColor(%q) = %q, %v; want ErrInvalidColor", in, got, err)
+		}
+	}
+}
+
+func TestNormalizeOptionalColor(t *testing.T) {
+	if got, err := normalizeOptionalColor(nil); got != nil || err != nil {
+		t.Errorf("normalizeOptionalColor(nil) = %v, %v; want nil, nil", got, err)
+	}
+	in := "#ABC"
+	if got, err := normalizeOptionalColor(&in); err != nil || *got != "#aabbcc" {
+		t.Errorf("normalizeOptionalColor(%q) = %v, %v; want #aabbcc", in, got, err)
+	}
+	bad := "blue"
+	if _, err := normalizeOptionalColor(&bad); err != ErrInvalidColor {
+		t.Errorf("normalizeOptionalColor(%q) err = %v, want ErrInvalidColor", bad, err)
+	}
+}
+
+func TestCreateTagStoresNormalizedColor(t *testing.T) {
+	s, mock := newTestService(t)
+	workspaceID, userID := uuid.New(), uuid.New()
+	color := "#F0A"
+
+	expectRole(mock, "owner")
+	mock.ExpectQuery(`SELECT \* FROM workspace_tags WHERE workspace_id = \? AND name = \?`).
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectExec(`INSERT INTO workspace_tags`).
+		WithArgs(sqlmock.AnyArg(), workspaceID, "Design", "#ff00aa", userID, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	tag, err := s.CreateTag(context.Background(), workspaceID, userID, &models.CreateTagRequest{Name: "Design", Color: &color})
+	if err != nil {
+		t.Fatalf("CreateTag: %v", err)
+	}
+	if tag.Color == nil || *tag.Color != "#ff00aa" {
+		t.Errorf("color = %v, want #ff00aa", tag.Color)
+	}
+}
+
+func TestCreateTagRejectsInvalidColor(t *testing.T) {
+	s, mock := newTestService(t)
+	color := "orange"
+	expectRole(mock, "owner")
+
+	if _, err := s.CreateTag(context.Background(), uuid.New(), uuid.New(), &models.CreateTagRequest{Name: "Design", Color: &color}); err != ErrInvalidColor {
+		t.Errorf("err = %v, want ErrInvalidColor", err)
+	}
+}
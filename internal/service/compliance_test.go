@@ -0,0 +1,169 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/quckapp/workspace-service/internal/models"
+)
+
+func expectPolicy(mock sqlmock.Sqlmock, policyID, workspaceID uuid.UUID, reackDays interface{}) {
+	mock.ExpectQuery(`SELECT \* FROM compliance_policies WHERE id = \?`).
+		WithArgs(policyID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "workspace_id", "name", "policy_type", "is_enforced", "reack_interval_days"}).
+			AddRow(policyID.String(), workspaceID.String(), "Code of conduct", "acknowledgement", true, reackDays))
+}
+
+func TestAcknowledgementExpired(t *testing.T) {
+	now := time.Now()
+	days := 30
+	withInterval := &models.CompliancePolicy{ReackIntervalDays: &days}
+
+	if acknowledgementExpired(&models.CompliancePolicy{}, now.AddDate(-5, 0, 0), now) {
+		t.Error("acknowledgement expired on a policy without a re-acknowledgement interval")
+	}
+	if acknowledgementExpired(withInterval, now.AddDate(0, 0, -29), now) {
+		t.Error("29-day-old acknowledgement expired under a 30-day interval")
+	}
+	if !acknowledgementExpired(withInterval, now.AddDate(0, 0, -31), now) {
+		t.Error("31-day-old acknowledgement still valid under a 30-day interval")
+	}
+}
+
+func TestExportPolicyAcknowledgementsIncludesEveryMember(t *testing.T) {
+	s, mock := newTestService(t)
+	workspaceID, userID, policyID := uuid.New(), uuid.New(), uuid.New()
+	fresh, stale, never := uuid.New(), uuid.New(), uuid.New()
+	now := time.Now()
+
+	expectRole(mock, "owner")
+	expectPolicy(mock, policyID, workspaceID, 30)
+	mock.ExpectQuery(`SELECT \* FROM policy_acknowledgements WHERE policy_id = \?`).
+		WithArgs(policyID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "policy_id", "user_id", "acked_at"}).
+			AddRow(uuid.New().String(), policyID.String(), fresh.String(), now.AddDate(0, 0, -1)).
+			AddRow(uuid.New().String(), policyID.String(), stale.String(), now.AddDate(0, 0, -60)))
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM workspace_members`).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+	mock.ExpectQuery(`SELECT \* FROM workspace_members WHERE workspace_id = \?`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "workspace_id", "user_id", "role"}).
+			AddRow(uuid.New().String(), workspaceID.String(), fresh.String(), "member").
+			AddRow(uuid.New().String(), workspaceID.String(), stale.String(), "member").
+			AddRow(uuid.New().String(), workspaceID.String(), never.String(), "guest"))
+
+	export, err := s.ExportPolicyAcknowledgements(context.Background(), workspaceID, policyID, userID)
+	if err != nil {
+		t.Fatalf("ExportPolicyAcknowledgements: %v", err)
+	}
+	if export.TotalMembers != 3 || export.AcknowledgedCount != 1 {
+		t.Fatalf("got %d members, %d acknowledged; want 3 and 1", export.TotalMembers, export.AcknowledgedCount)
+	}
+	rows := map[uuid.UUID]*models.PolicyAcknowledgementRow{}
+	for _, row := range export.Members {
+		rows[row.UserID] = row
+	}
+	if r := rows[fresh]; !r.Acknowledged || r.Expired || r.AckedAt == nil {
+		t.Errorf("fresh acknowledgement = %+v, want acknowledged", r)
+	}
+	if r := rows[stale]; r.Acknowledged || !r.Expired || r.AckedAt == nil {
+		t.Errorf("stale acknowledgement = %+v, want expired", r)
+	}
+	if r := rows[never]; r.Acknowledged || r.Expired || r.AckedAt != nil {
+		t.Errorf("missing acknowledgement = %+v, want an empty row", r)
+	}
+}
+
+func TestExportPolicyAcknowledgementsRejectsPolicyFromAnotherWorkspace(t *testing.T) {
+	s, mock := newTestService(t)
+	policyID := uuid.New()
+
+	expectRole(mock, "owner")
+	expectPolicy(mock, policyID, uuid.New(), nil)
+
+	if _, err := s.ExportPolicyAcknowledgements(context.Background(), uuid.New(), policyID, uuid.New()); err != ErrPolicyNotFound {
+		t.Errorf("err = %v, want ErrPolicyNotFound", err)
+	}
+}
+
+func TestExportPolicyAcknowledgementsRequiresPolicyManagers(t *testing.T) {
+	s, mock := newTestService(t)
+	expectRole(mock, "moderator")
+
+	if _, err := s.ExportPolicyAcknowledgements(context.Background(), uuid.New(), uuid.New(), uuid.New()); err != ErrNotAuthorized {
+		t.Errorf("err = %v, want ErrNotAuthorized", err)
+	}
+}
+
+func expectOutstandingAcks(mock sqlmock.Sqlmock, ids ...uuid.UUID) {
+	rows := sqlmock.NewRows([]string{"id"})
+	for _, id := range ids {
+		rows.AddRow(id.String())
+	}
+	mock.ExpectQuery(`SELECT p.id FROM compliance_policies p`).WillReturnRows(rows)
+}
+
+func cachedAcks(t *testing.T, kv *memKVStore, workspaceID, userID uuid.UUID) cachedPolicyAcks {
+	t.Helper()
+	data, err := kv.HGet(context.Background(), fmt.Sprintf(cacheKeyPolicyAcks, workspaceID.String()), userID.String())
+	if err != nil {
+		t.Fatalf("nothing cached: %v", err)
+	}
+	var cached cachedPolicyAcks
+	if err := json.Unmarshal(data, &cached); err != nil {
+		t.Fatalf("decode cached acks: %v", err)
+	}
+	return cached
+}
+
+func TestOutstandingPolicyAcknowledgementsCachedUntilNextExpiry(t *testing.T) {
+	s, mock := newTestService(t)
+	kv := newMemKVStore()
+	s.kv = kv
+	workspaceID, userID, policyID := uuid.New(), uuid.New(), uuid.New()
+	expiresAt := time.Now().Add(30 * time.Second)
+
+	expectOutstandingAcks(mock, policyID)
+	mock.ExpectQuery(`SELECT MIN\(DATE_ADD\(a.acked_at`).
+		WillReturnRows(sqlmock.NewRows([]string{"next"}).AddRow(expiresAt))
+
+	ids, err := s.OutstandingPolicyAcknowledgements(context.Background(), workspaceID, userID)
+	if err != nil || len(ids) != 1 || ids[0] != policyID {
+		t.Fatalf("got %v, %v; want [%v]", ids, err, policyID)
+	}
+	if cached := cachedAcks(t, kv, workspaceID, userID); cached.ValidUntil.After(expiresAt.Add(time.Millisecond)) {
+		t.Errorf("cached until %v, past the acknowledgement expiry at %v", cached.ValidUntil, expiresAt)
+	}
+
+	// A second check is served from the cache.
+	ids, err = s.OutstandingPolicyAcknowledgements(context.Background(), workspaceID, userID)
+	if err != nil || len(ids) != 1 {
+		t.Fatalf("cached lookup = %v, %v", ids, err)
+	}
+}
+
+func TestOutstandingPolicyAcknowledgementsRefetchesLapsedCache(t *testing.T) {
+	s, mock := newTestService(t)
+	kv := newMemKVStore()
+	s.kv = kv
+	workspaceID, userID, policyID := uuid.New(), uuid.New(), uuid.New()
+
+	stale, _ := json.Marshal(cachedPolicyAcks{PolicyIDs: []uuid.UUID{}, ValidUntil: time.Now().Add(-time.Second)})
+	kv.HSet(context.Background(), fmt.Sprintf(cacheKeyPolicyAcks, workspaceID.String()), userID.String(), stale, cacheTTL)
+
+	expectOutstandingAcks(mock, policyID)
+	mock.ExpectQuery(`SELECT MIN\(DATE_ADD\(a.acked_at`).
+		WillReturnRows(sqlmock.NewRows([]string{"next"}).AddRow(nil))
+
+	ids, err := s.OutstandingPolicyAcknowledgements(context.Background(), workspaceID, userID)
+	if err != nil || len(ids) != 1 || ids[0] != policyID {
+		t.Fatalf("got %v, %v; want the acknowledgement that lapsed while cached", ids, err)
+	}
+	if cached := cachedAcks(t, kv, workspaceID, userID); time.Until(cached.ValidUntil) > cacheTTL {
+		t.Errorf("cached until %v, longer than the cache TTL", cached.ValidUntil)
+	}
+}
@@ -0,0 +1,160 @@
+package service
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// credentialEnvelopePrefix marks a credentials value written by
+// CredentialCipher. Values without it predate encryption and are plaintext.
+const credentialEnvelopePrefix = "enc:v1:"
+
+// credentialsMask replaces credentials in API responses.
+const credentialsMask = "********"
+
+// CredentialCipher encrypts integration credentials at rest with envelope
+// encryption. Each value gets a fresh data key that encrypts the secret;
+// the data key is in turn encrypted with the master key and stored alongside
+// it. The stored form is
+//
+//	enc:v1:<master key id>:<wrapped data key>:<ciphertext>
+//
+// Previous master keys stay usable for decryption so a key can be rotated
+// without rewriting every row at once.
+type CredentialCipher struct {
+	primaryID string
+	keys      map[string][]byte // key id -> 32-byte AES-256 master key
+}
+
+// NewCredentialCipher builds a cipher from base64-encoded 32-byte master
+// keys. New values are encrypted with primary; previous keys only decrypt.
+func NewCredentialCipher(primary string, previous []string) (*CredentialCipher, error) {
+	c := &CredentialCipher{keys: make(map[string][]byte)}
+	id, err := c.addKey(primary)
+	if err != nil {
+		return nil, err
+	}
+	c.primaryID = id
+	for _, k := range previous {
+		if _, err := c.addKey(k); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// DeriveCredentialKey derives a base64 master key from another secret. It
+// exists so development setups work without a dedicated key.
+func DeriveCredentialKey(secret string) string {
+	sum := sha256.Sum256([]byte("integration-credentials:" + secret))
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+func (c *CredentialCipher) addKey(encoded string) (string, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil || len(key) != 32 {
+		return "", fmt.Errorf("credential key must be 32 bytes, base64-encoded")
+	}
+	sum := sha256.Sum256(key)
+	id := hex.EncodeToString(sum[:4])
+	c.keys[id] = key
+	return id, nil
+}
+
+// Encrypt seals plaintext under a new data key wrapped by the primary key.
+func (c *CredentialCipher) Encrypt(plaintext string) (string, error) {
+	dataKey := make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, dataKey); err != nil {
+		return "", err
+	}
+	wrapped, err := sealGCM(c.keys[c.primaryID], dataKey)
+	if err != nil {
+		return "", err
+	}
+	sealed, err := sealGCM(dataKey, []byte(plaintext))
+	if err != nil {
+		return "", err
+	}
+	return credentialEnvelopePrefix + c.primaryID + ":" +
+		base64.RawStdEncoding.EncodeToString(wrapped) + ":" +
+		base64.RawStdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt opens a value produced by Encrypt. Legacy plaintext values are
+// returned unchanged.
+func (c *CredentialCipher) Decrypt(stored string) (string, error) {
+	if !IsEncryptedCredential(stored) {
+		return stored, nil
+	}
+	parts := strings.Split(strings.TrimPrefix(stored, credentialEnvelopePrefix), ":")
+	if len(parts) != 3 {
+		return "", fmt.Errorf("malformed credentials envelope")
+	}
+	masterKey, ok := c.keys[parts[0]]
+	if !ok {
+		return "", ErrCredentialKeyUnknown
+	}
+	wrapped, err := base64.RawStdEncoding.DecodeString(parts[1])
+	if err != nil {
+		return "", fmt.Errorf("malformed credentials envelope")
+	}
+	sealed, err := base64.RawStdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return "", fmt.Errorf("malformed credentials envelope")
+	}
+
+	dataKey, err := openGCM(masterKey, wrapped)
+	if err != nil {
+		return "", err
+	}
+	plaintext, err := openGCM(dataKey, sealed)
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+// IsEncryptedCredential reports whether a stored value is an envelope
+// rather than legacy plaintext.
+func IsEncryptedCredential(stored string) bool {
+	return strings.HasPrefix(stored, credentialEnvelopePrefix)
+}
+
+// sealGCM encrypts with AES-256-GCM, prefixing the random nonce.
+func sealGCM(key, plaintext []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+func openGCM(key, sealed []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return nil, fmt.Errorf("malformed credentials envelope")
+	}
+	return gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], nil)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
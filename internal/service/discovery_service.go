@@ -9,22 +9,32 @@ import (
 	"github.com/google/uuid"
 	"github.com/quckapp/workspace-service/internal/models"
 	"github.com/quckapp/workspace-service/internal/repository"
+	"github.com/redis/go-redis/v9"
 	"github.com/sirupsen/logrus"
 )
 
+const (
+	cacheKeyDirectoryCategories = "discovery:categories"
+	directoryCategoriesTTL      = time.Minute
+)
+
 var (
 	ErrDirectoryEntryNotFound = errors.New("directory entry not found")
+	ErrAlreadyReported        = errors.New("workspace already reported")
+	ErrReportNotFound         = errors.New("report not found")
+	ErrReportAlreadyResolved  = errors.New("report already resolved")
 )
 
 type DiscoveryService struct {
 	discoveryRepo *repository.DiscoveryRepository
 	workspaceRepo *repository.WorkspaceRepository
 	memberRepo    *repository.MemberRepository
+	kv            kvStore
 	logger        *logrus.Logger
 }
 
-func NewDiscoveryService(discoveryRepo *repository.DiscoveryRepository, workspaceRepo *repository.WorkspaceRepository, memberRepo *repository.MemberRepository, logger *logrus.Logger) *DiscoveryService {
-	return &DiscoveryService{discoveryRepo: discoveryRepo, workspaceRepo: workspaceRepo, memberRepo: memberRepo, logger: logger}
+func NewDiscoveryService(discoveryRepo *repository.DiscoveryRepository, workspaceRepo *repository.WorkspaceRepository, memberRepo *repository.MemberRepository, redis *redis.Client, logger *logrus.Logger) *DiscoveryService {
+	return &DiscoveryService{discoveryRepo: discoveryRepo, workspaceRepo: workspaceRepo, memberRepo: memberRepo, kv: newKVStore(redis), logger: logger}
 }
 
 func (s *DiscoveryService) GetDirectoryEntry(ctx context.Context, workspaceID uuid.UUID) (*models.WorkspaceDirectoryEntry, error) {
@@ -89,6 +99,7 @@ func (s *DiscoveryService) UpdateDirectoryEntry(ctx context.Context, workspaceID
 	if err := s.discoveryRepo.UpsertDirectoryEntry(ctx, entry); err != nil {
 		return nil, err
 	}
+	s.kv.Del(ctx, cacheKeyDirectoryCategories)
 	return entry, nil
 }
 
@@ -100,8 +111,29 @@ func (s *DiscoveryService) SearchDirectory(ctx context.Context, params *models.D
 	return s.discoveryRepo.SearchDirectory(ctx, params.Query, params.Category, params.SortBy, params.PerPage, offset)
 }
 
-func (s *DiscoveryService) GetCategories(ctx context.Context) ([]models.WorkspaceCategory, error) {
-	return s.discoveryRepo.GetCategories(ctx)
+// GetDirectoryCategories returns each directory category with the number of
+// listed workspaces in it, most populated first. Results are cached briefly
+// since the browse page hits this on every load.
+func (s *DiscoveryService) GetDirectoryCategories(ctx context.Context) ([]models.WorkspaceCategory, error) {
+	if data, err := s.kv.Get(ctx, cacheKeyDirectoryCategories); err == nil {
+		var categories []models.WorkspaceCategory
+		if json.Unmarshal(data, &categories) == nil {
+			return categories, nil
+		}
+	}
+
+	categories, err := s.discoveryRepo.GetCategories(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if categories == nil {
+		categories = []models.WorkspaceCategory{}
+	}
+
+	if data, err := json.Marshal(categories); err == nil {
+		s.kv.Set(ctx, cacheKeyDirectoryCategories, data, directoryCategoriesTTL)
+	}
+	return categories, nil
 }
 
 func (s *DiscoveryService) GetRecommendations(ctx context.Context, userID uuid.UUID, limit int) ([]*models.WorkspaceRecommendation, error) {
@@ -119,9 +151,108 @@ func (s *DiscoveryService) DismissRecommendation(ctx context.Context, userID uui
 	return s.discoveryRepo.DismissRecommendation(ctx, userID, workspaceID)
 }
 
+// DismissAllRecommendations dismisses every outstanding recommendation for the
+// user and returns how many were dismissed.
+func (s *DiscoveryService) DismissAllRecommendations(ctx context.Context, userID uuid.UUID) (int64, error) {
+	return s.discoveryRepo.DismissAllRecommendations(ctx, userID)
+}
+
 func (s *DiscoveryService) GetTrending(ctx context.Context, limit int) ([]*models.TrendingWorkspace, error) {
 	if limit > 20 {
 		limit = 20
 	}
 	return s.discoveryRepo.GetTrending(ctx, limit)
 }
+
+// ── Directory Reports ──
+
+// ReportDirectoryWorkspace files an abuse report against a listed workspace.
+// A reporter can only have one pending report per workspace.
+func (s *DiscoveryService) ReportDirectoryWorkspace(ctx context.Context, workspaceID, reporterID uuid.UUID, req *models.ReportDirectoryWorkspaceRequest) (*models.DirectoryReport, error) {
+	entry, err := s.discoveryRepo.GetDirectoryEntry(ctx, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil || !entry.IsListed {
+		return nil, ErrDirectoryEntryNotFound
+	}
+
+	pending, err := s.discoveryRepo.HasPendingReport(ctx, workspaceID, reporterID)
+	if err != nil {
+		return nil, err
+	}
+	if pending {
+		return nil, ErrAlreadyReported
+	}
+
+	report := &models.DirectoryReport{
+		ID:          uuid.New(),
+		WorkspaceID: workspaceID,
+		ReporterID:  reporterID,
+		Reason:      req.Reason,
+		Details:     req.Details,
+		Status:      "pending",
+		CreatedAt:   time.Now(),
+	}
+	if err := s.discoveryRepo.CreateReport(ctx, report); err != nil {
+		return nil, err
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"workspace_id": workspaceID,
+		"report_id":    report.ID,
+		"reason":       req.Reason,
+	}).Info("Directory workspace reported")
+	return report, nil
+}
+
+// ListDirectoryReports lists reports in a status, oldest first, for the
+// service admin review queue.
+func (s *DiscoveryService) ListDirectoryReports(ctx context.Context, params *models.DirectoryReportListParams) ([]*models.DirectoryReport, int64, error) {
+	if params.Page < 1 {
+		params.Page = 1
+	}
+	if params.PerPage < 1 || params.PerPage > 100 {
+		params.PerPage = 20
+	}
+	offset := (params.Page - 1) * params.PerPage
+	return s.discoveryRepo.ListReports(ctx, params.Status, params.PerPage, offset)
+}
+
+// ResolveDirectoryReport closes a pending report. Unlisting removes the
+// workspace from the directory and closes every other pending report
+// against it as well.
+func (s *DiscoveryService) ResolveDirectoryReport(ctx context.Context, reportID, adminID uuid.UUID, req *models.ResolveDirectoryReportRequest) (*models.DirectoryReport, error) {
+	report, err := s.discoveryRepo.GetReport(ctx, reportID)
+	if err != nil {
+		return nil, err
+	}
+	if report == nil {
+		return nil, ErrReportNotFound
+	}
+	if report.Status != "pending" {
+		return nil, ErrReportAlreadyResolved
+	}
+
+	if req.Action == "unlist" {
+		if err := s.discoveryRepo.Unlist(ctx, report.WorkspaceID); err != nil {
+			return nil, err
+		}
+		if err := s.discoveryRepo.ResolvePendingReports(ctx, report.WorkspaceID, nil, "actioned", adminID, req.Note); err != nil {
+			return nil, err
+		}
+		s.kv.Del(ctx, cacheKeyDirectoryCategories)
+	} else {
+		if err := s.discoveryRepo.ResolvePendingReports(ctx, report.WorkspaceID, &report.ID, "dismissed", adminID, req.Note); err != nil {
+			return nil, err
+		}
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"workspace_id": report.WorkspaceID,
+		"report_id":    report.ID,
+		"action":       req.Action,
+		"admin_id":     adminID,
+	}).Info("Directory report resolved")
+	return s.discoveryRepo.GetReport(ctx, reportID)
+}
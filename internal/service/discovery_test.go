@@ -0,0 +1,145 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/quckapp/workspace-service/internal/models"
+	"github.com/quckapp/workspace-service/internal/repository"
+)
+
+func newTestDiscoveryService(t *testing.T) (*DiscoveryService, sqlmock.Sqlmock) {
+	db, mock := newTestDB(t)
+	s := NewDiscoveryService(repository.NewDiscoveryRepository(db), repository.NewWorkspaceRepository(db), repository.NewMemberRepository(db), nil, testLogger())
+	return s, mock
+}
+
+func TestGetDirectoryCategoriesIsCached(t *testing.T) {
+	s, mock := newTestDiscoveryService(t)
+	s.kv = newMemKVStore()
+
+	mock.ExpectQuery(`SELECT COALESCE\(category, 'other'\) as name, COUNT\(\*\) as count FROM workspace_directory`).
+		WillReturnRows(sqlmock.NewRows([]string{"name", "count"}).
+			AddRow("engineering", 12).
+			AddRow("other", 3))
+
+	// Only one query is expected, so the second call must be a cache hit.
+	for i := 0; i < 2; i++ {
+		categories, err := s.GetDirectoryCategories(context.Background())
+		if err != nil {
+			t.Fatalf("call %d: %v", i+1, err)
+		}
+		if len(categories) != 2 || categories[0].Name != "engineering" || categories[0].Count != 12 {
+			t.Errorf("call %d: got %+v", i+1, categories)
+		}
+	}
+}
+
+func TestGetDirectoryCategoriesEmptyDirectory(t *testing.T) {
+	s, mock := newTestDiscoveryService(t)
+	mock.ExpectQuery(`FROM workspace_directory`).
+		WillReturnRows(sqlmock.NewRows([]string{"name", "count"}))
+
+	categories, err := s.GetDirectoryCategories(context.Background())
+	if err != nil {
+		t.Fatalf("GetDirectoryCategories: %v", err)
+	}
+	if categories == nil || len(categories) != 0 {
+		t.Errorf("got %v, want an empty, non-nil list", categories)
+	}
+}
+
+func expectDirectoryEntry(mock sqlmock.Sqlmock, workspaceID uuid.UUID, listed bool) {
+	mock.ExpectQuery(`SELECT \* FROM workspace_directory WHERE workspace_id = \?`).
+		WithArgs(workspaceID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "workspace_id", "is_listed"}).
+			AddRow(uuid.New().String(), workspaceID.String(), listed))
+}
+
+func expectDirectoryReport(mock sqlmock.Sqlmock, reportID, workspaceID uuid.UUID, status string) {
+	mock.ExpectQuery(`SELECT \* FROM workspace_directory_reports WHERE id = \?`).
+		WithArgs(reportID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "workspace_id", "reporter_id", "reason", "status"}).
+			AddRow(reportID.String(), workspaceID.String(), uuid.New().String(), "spam", status))
+}
+
+func TestReportDirectoryWorkspaceOnePendingPerReporter(t *testing.T) {
+	s, mock := newTestDiscoveryService(t)
+	workspaceID := uuid.New()
+
+	expectDirectoryEntry(mock, workspaceID, true)
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM workspace_directory_reports WHERE workspace_id = \? AND reporter_id = \? AND status = 'pending'`).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+	req := &models.ReportDirectoryWorkspaceRequest{Reason: "spam"}
+	if _, err := s.ReportDirectoryWorkspace(context.Background(), workspaceID, uuid.New(), req); err != ErrAlreadyReported {
+		t.Errorf("err = %v, want ErrAlreadyReported", err)
+	}
+}
+
+func TestReportDirectoryWorkspaceRejectsUnlisted(t *testing.T) {
+	s, mock := newTestDiscoveryService(t)
+	workspaceID := uuid.New()
+	expectDirectoryEntry(mock, workspaceID, false)
+
+	req := &models.ReportDirectoryWorkspaceRequest{Reason: "spam"}
+	if _, err := s.ReportDirectoryWorkspace(context.Background(), workspaceID, uuid.New(), req); err != ErrDirectoryEntryNotFound {
+		t.Errorf("err = %v, want ErrDirectoryEntryNotFound", err)
+	}
+}
+
+func TestResolveDirectoryReportUnlistClosesEveryPendingReport(t *testing.T) {
+	s, mock := newTestDiscoveryService(t)
+	kv := newMemKVStore()
+	s.kv = kv
+	kv.Set(context.Background(), cacheKeyDirectoryCategories, "[]", 0)
+	reportID, workspaceID, adminID := uuid.New(), uuid.New(), uuid.New()
+
+	expectDirectoryReport(mock, reportID, workspaceID, "pending")
+	mock.ExpectExec(`UPDATE workspace_directory SET is_listed = FALSE`).
+		WithArgs(sqlmock.AnyArg(), workspaceID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	// No report ID argument: every pending report on the workspace closes.
+	mock.ExpectExec(`UPDATE workspace_directory_reports SET status = \?, resolved_by = \?, resolution_note = \?, resolved_at = \? WHERE workspace_id = \? AND status = 'pending'$`).
+		WithArgs("actioned", adminID, nil, sqlmock.AnyArg(), workspaceID).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	expectDirectoryReport(mock, reportID, workspaceID, "actioned")
+
+	report, err := s.ResolveDirectoryReport(context.Background(), reportID, adminID, &models.ResolveDirectoryReportRequest{Action: "unlist"})
+	if err != nil {
+		t.Fatalf("ResolveDirectoryReport: %v", err)
+	}
+	if report.Status != "actioned" {
+		t.Errorf("status = %q, want actioned", report.Status)
+	}
+	if kv.Exists(context.Background(), cacheKeyDirectoryCategories) {
+		t.Error("unlisting left stale category counts cached")
+	}
+}
+
+func TestResolveDirectoryReportDismissClosesOnlyThatReport(t *testing.T) {
+	s, mock := newTestDiscoveryService(t)
+	reportID, workspaceID, adminID := uuid.New(), uuid.New(), uuid.New()
+
+	expectDirectoryReport(mock, reportID, workspaceID, "pending")
+	mock.ExpectExec(`UPDATE workspace_directory_reports SET status = \?.* AND id = \?`).
+		WithArgs("dismissed", adminID, nil, sqlmock.AnyArg(), workspaceID, reportID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectDirectoryReport(mock, reportID, workspaceID, "dismissed")
+
+	if _, err := s.ResolveDirectoryReport(context.Background(), reportID, adminID, &models.ResolveDirectoryReportRequest{Action: "dismiss"}); err != nil {
+		t.Fatalf("ResolveDirectoryReport: %v", err)
+	}
+}
+
+func TestResolveDirectoryReportRejectsResolved(t *testing.T) {
+	s, mock := newTestDiscoveryService(t)
+	reportID := uuid.New()
+	expectDirectoryReport(mock, reportID, uuid.New(), "dismissed")
+
+	if _, err := s.ResolveDirectoryReport(context.Background(), reportID, uuid.New(), &models.ResolveDirectoryReportRequest{Action: "unlist"}); err != ErrReportAlreadyResolved {
+		t.Errorf("err = %v, want ErrReportAlreadyResolved", err)
+	}
+}
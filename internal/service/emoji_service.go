@@ -2,15 +2,28 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/quckapp/workspace-service/internal/models"
 	"github.com/quckapp/workspace-service/internal/repository"
+	"github.com/redis/go-redis/v9"
 	"github.com/sirupsen/logrus"
 )
 
+const (
+	cacheKeyTopEmojis   = "workspace:%s:top_emojis:%d"
+	topEmojisCacheTTL   = 5 * time.Minute
+	defaultTopEmojis    = 10
+	maxTopEmojis        = 50
+	topEmojisCandidates = 200 // custom emojis considered, by usage_count
+)
+
 var (
 	ErrEmojiNotFound    = errors.New("custom emoji not found")
 	ErrEmojiNameExists  = errors.New("emoji name already exists in this workspace")
@@ -20,11 +33,13 @@ var (
 type EmojiService struct {
 	emojiRepo *repository.EmojiRepository
 	memberRepo *repository.MemberRepository
+	reactionRepo *repository.ReactionRepository
+	kv        kvStore
 	logger    *logrus.Logger
 }
 
-func NewEmojiService(emojiRepo *repository.EmojiRepository, memberRepo *repository.MemberRepository, logger *logrus.Logger) *EmojiService {
-	return &EmojiService{emojiRepo: emojiRepo, memberRepo: memberRepo, logger: logger}
+func NewEmojiService(emojiRepo *repository.EmojiRepository, memberRepo *repository.MemberRepository, reactionRepo *repository.ReactionRepository, redis *redis.Client, logger *logrus.Logger) *EmojiService {
+	return &EmojiService{emojiRepo: emojiRepo, memberRepo: memberRepo, reactionRepo: reactionRepo, kv: newKVStore(redis), logger: logger}
 }
 
 func (s *EmojiService) CreateEmoji(ctx context.Context, workspaceID, userID uuid.UUID, req *models.CreateEmojiRequest) (*models.CustomEmoji, error) {
@@ -58,12 +73,12 @@ func (s *EmojiService) CreateEmoji(ctx context.Context, workspaceID, userID uuid
 	return emoji, nil
 }
 
-func (s *EmojiService) GetEmoji(ctx context.Context, id uuid.UUID) (*models.CustomEmoji, error) {
+func (s *EmojiService) GetEmoji(ctx context.Context, workspaceID, id uuid.UUID) (*models.CustomEmoji, error) {
 	emoji, err := s.emojiRepo.GetByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
-	if emoji == nil {
+	if emoji == nil || emoji.WorkspaceID != workspaceID {
 		return nil, ErrEmojiNotFound
 	}
 	return emoji, nil
@@ -92,7 +107,7 @@ func (s *EmojiService) UpdateEmoji(ctx context.Context, workspaceID, userID, emo
 	}
 
 	emoji, err := s.emojiRepo.GetByID(ctx, emojiID)
-	if err != nil || emoji == nil {
+	if err != nil || emoji == nil || emoji.WorkspaceID != workspaceID {
 		return nil, ErrEmojiNotFound
 	}
 
@@ -127,7 +142,7 @@ func (s *EmojiService) DeleteEmoji(ctx context.Context, workspaceID, userID, emo
 	}
 
 	emoji, err := s.emojiRepo.GetByID(ctx, emojiID)
-	if err != nil || emoji == nil {
+	if err != nil || emoji == nil || emoji.WorkspaceID != workspaceID {
 		return ErrEmojiNotFound
 	}
 
@@ -197,6 +212,90 @@ func (s *EmojiService) GetEmojiStats(ctx context.Context, workspaceID uuid.UUID)
 	}, nil
 }
 
+// GetTopEmojis ranks the workspace's most-used emojis, combining custom emoji
+// usage counts with reaction counts. Reactions written as a custom emoji's
+// name (with or without surrounding colons) count toward that emoji. The
+// ranking is cached briefly since it scans every reaction in the workspace.
+func (s *EmojiService) GetTopEmojis(ctx context.Context, workspaceID, userID uuid.UUID, limit int) ([]*models.TopEmoji, error) {
+	isMember, _ := s.memberRepo.IsMember(ctx, workspaceID, userID)
+	if !isMember {
+		return nil, ErrNotMember
+	}
+	if limit < 1 {
+		limit = defaultTopEmojis
+	}
+	if limit > maxTopEmojis {
+		limit = maxTopEmojis
+	}
+
+	key := fmt.Sprintf(cacheKeyTopEmojis, workspaceID.String(), limit)
+	if data, err := s.kv.Get(ctx, key); err == nil {
+		var cached []*models.TopEmoji
+		if json.Unmarshal(data, &cached) == nil {
+			return cached, nil
+		}
+	}
+
+	customs, err := s.emojiRepo.GetTopEmojis(ctx, workspaceID, topEmojisCandidates)
+	if err != nil {
+		return nil, err
+	}
+	reactions, err := s.reactionRepo.CountByEmojiInWorkspace(ctx, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	top := rankTopEmojis(customs, reactions, limit)
+
+	if data, err := json.Marshal(top); err == nil {
+		s.kv.Set(ctx, key, data, topEmojisCacheTTL)
+	}
+	return top, nil
+}
+
+// rankTopEmojis merges custom emoji usage with reaction counts and returns
+// the top limit by total, ties broken by emoji.
+func rankTopEmojis(customs []*models.CustomEmoji, reactions []models.ReactionSummary, limit int) []*models.TopEmoji {
+	byEmoji := make(map[string]*models.TopEmoji)
+	for _, c := range customs {
+		id, imageURL := c.ID, c.ImageURL
+		byEmoji[c.Name] = &models.TopEmoji{
+			Emoji:      c.Name,
+			IsCustom:   true,
+			EmojiID:    &id,
+			ImageURL:   &imageURL,
+			UsageCount: c.UsageCount,
+		}
+	}
+	for _, r := range reactions {
+		entry, ok := byEmoji[strings.Trim(r.Emoji, ":")]
+		if !ok {
+			if entry, ok = byEmoji[r.Emoji]; !ok {
+				entry = &models.TopEmoji{Emoji: r.Emoji}
+				byEmoji[r.Emoji] = entry
+			}
+		}
+		entry.ReactionCount += r.Count
+	}
+
+	top := make([]*models.TopEmoji, 0, len(byEmoji))
+	for _, entry := range byEmoji {
+		entry.Total = entry.ReactionCount + entry.UsageCount
+		if entry.Total > 0 {
+			top = append(top, entry)
+		}
+	}
+	sort.Slice(top, func(i, j int) bool {
+		if top[i].Total != top[j].Total {
+			return top[i].Total > top[j].Total
+		}
+		return top[i].Emoji < top[j].Emoji
+	})
+	if len(top) > limit {
+		top = top[:limit]
+	}
+	return top
+}
+
 // Pack operations
 func (s *EmojiService) CreatePack(ctx context.Context, workspaceID, userID uuid.UUID, req *models.EmojiPackRequest) (*models.EmojiPack, error) {
 	member, err := s.memberRepo.GetByWorkspaceAndUser(ctx, workspaceID, userID)
@@ -0,0 +1,221 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/quckapp/workspace-service/internal/db"
+	"github.com/quckapp/workspace-service/internal/metrics"
+	"github.com/quckapp/workspace-service/internal/models"
+	"github.com/sirupsen/logrus"
+)
+
+// Topics the service consumes to keep usage quotas current.
+const (
+	ChannelEventsTopic = "channel-events"
+	StorageEventsTopic = "storage-events"
+)
+
+const (
+	consumerRetryAttempts = 3
+	consumerRetryBackoff  = time.Second
+)
+
+// errMalformedEvent marks a message that can never be applied, so it is
+// skipped rather than retried.
+var errMalformedEvent = errors.New("malformed event")
+
+// EventConsumer applies channel and storage events from other services to
+// the workspace quotas. Offsets are committed only once a message has been
+// applied or deliberately skipped, so delivery is at-least-once;
+// ApplyUsageEvent is idempotent, which makes redelivery harmless.
+type EventConsumer struct {
+	consumers  []*db.KafkaConsumer
+	workspaces *WorkspaceService
+	metrics    *metrics.Metrics
+	logger     *logrus.Logger
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// eventSource is the part of db.KafkaConsumer the consume loop uses.
+type eventSource interface {
+	Topic() string
+	Fetch(ctx context.Context) (*db.ConsumedMessage, error)
+	Commit(ctx context.Context, m *db.ConsumedMessage) error
+	Lag() int64
+}
+
+func NewEventConsumer(workspaces *WorkspaceService, consumers []*db.KafkaConsumer, metrics *metrics.Metrics, logger *logrus.Logger) *EventConsumer {
+	return &EventConsumer{
+		consumers:  consumers,
+		workspaces: workspaces,
+		metrics:    metrics,
+		logger:     logger,
+	}
+}
+
+// Start begins consuming every topic in its own goroutine.
+func (e *EventConsumer) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	e.cancel = cancel
+	for _, c := range e.consumers {
+		e.wg.Add(1)
+		go func(c *db.KafkaConsumer) {
+			defer e.wg.Done()
+			e.run(ctx, c)
+		}(c)
+	}
+}
+
+// Stop stops fetching, waits for the message in hand to be handled and
+// closes the consumers. It gives up waiting when ctx is done.
+func (e *EventConsumer) Stop(ctx context.Context) error {
+	if e.cancel != nil {
+		e.cancel()
+	}
+	done := make(chan struct{})
+	go func() {
+		e.wg.Wait()
+		close(done)
+	}()
+	err := waitDone(ctx, done, "event consumer")
+	for _, c := range e.consumers {
+		if err := c.Close(); err != nil {
+			e.logger.WithError(err).WithField("topic", c.Topic()).Warn("Failed to close event consumer")
+		}
+	}
+	return err
+}
+
+func (e *EventConsumer) run(ctx context.Context, c eventSource) {
+	for {
+		msg, err := c.Fetch(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			e.logger.WithError(err).WithField("topic", c.Topic()).Warn("Failed to fetch event")
+			if !sleepCtx(ctx, consumerRetryBackoff) {
+				return
+			}
+			continue
+		}
+
+		// A message that failed to apply is retried in place rather than
+		// skipped: committing any later offset would commit this one too.
+		// On shutdown it is left uncommitted and redelivered on restart.
+		for {
+			result := e.handle(ctx, msg)
+			e.metrics.EventConsumed(msg.Topic, result)
+			if result != "failed" {
+				break
+			}
+			if !sleepCtx(ctx, consumerRetryBackoff) {
+				return
+			}
+		}
+		e.metrics.SetConsumerLag(msg.Topic, c.Lag())
+
+		// A failure to commit only means the message is redelivered.
+		if err := c.Commit(context.Background(), msg); err != nil {
+			e.logger.WithError(err).WithField("topic", msg.Topic).Warn("Failed to commit event offset")
+		}
+	}
+}
+
+// handle applies one message, retrying transient failures a few times.
+// Malformed messages are skipped so they cannot stall the partition. It
+// returns the result label for metrics; "failed" means the message must
+// not be committed.
+func (e *EventConsumer) handle(ctx context.Context, msg *db.ConsumedMessage) string {
+	event, err := decodeUsageEvent(msg)
+	if err != nil {
+		e.logger.WithError(err).WithField("topic", msg.Topic).Warn("Skipping malformed event")
+		return "malformed"
+	}
+	if event == nil {
+		return "ignored"
+	}
+
+	for attempt := 1; ; attempt++ {
+		err = e.workspaces.ApplyUsageEvent(ctx, event)
+		if err == nil {
+			return "applied"
+		}
+		if attempt == consumerRetryAttempts || !sleepCtx(ctx, consumerRetryBackoff) {
+			break
+		}
+	}
+	e.logger.WithError(err).WithFields(logrus.Fields{
+		"topic":        msg.Topic,
+		"workspace_id": event.WorkspaceID,
+		"entity_id":    event.EntityID,
+	}).Error("Failed to apply event")
+	return "failed"
+}
+
+// decodeUsageEvent maps a channel or storage event to a UsageEvent. Event
+// types that do not affect usage decode to nil.
+func decodeUsageEvent(msg *db.ConsumedMessage) (*models.UsageEvent, error) {
+	var envelope models.EventEnvelope
+	if err := json.Unmarshal(msg.Value, &envelope); err != nil {
+		return nil, fmt.Errorf("%w: %v", errMalformedEvent, err)
+	}
+
+	event := &models.UsageEvent{OccurredAt: envelope.Timestamp}
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = msg.Time
+	}
+
+	var idField string
+	switch envelope.Type {
+	case "channel.created":
+		event.Kind, idField = models.UsageKindChannel, "channel_id"
+	case "channel.deleted":
+		event.Kind, idField, event.Deleted = models.UsageKindChannel, "channel_id", true
+	case "file.uploaded":
+		event.Kind, idField = models.UsageKindFile, "file_id"
+		size, _ := envelope.Data["size_bytes"].(float64)
+		if size < 0 {
+			return nil, fmt.Errorf("%w: negative size_bytes", errMalformedEvent)
+		}
+		event.SizeBytes = int64(size)
+	case "file.deleted":
+		event.Kind, idField, event.Deleted = models.UsageKindFile, "file_id", true
+	default:
+		return nil, nil
+	}
+
+	workspaceID, _ := envelope.Data["workspace_id"].(string)
+	id, err := uuid.Parse(workspaceID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid workspace_id", errMalformedEvent)
+	}
+	event.WorkspaceID = id
+
+	entityID, _ := envelope.Data[idField].(string)
+	if entityID == "" || len(entityID) > 64 {
+		return nil, fmt.Errorf("%w: invalid %s", errMalformedEvent, idField)
+	}
+	event.EntityID = entityID
+	return event, nil
+}
+
+// sleepCtx waits for d and reports false if ctx was cancelled first.
+func sleepCtx(ctx context.Context, d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
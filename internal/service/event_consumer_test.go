@@ -0,0 +1,166 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/quckapp/workspace-service/internal/db"
+	"github.com/quckapp/workspace-service/internal/models"
+)
+
+func usageMessage(value string) *db.ConsumedMessage {
+	return &db.ConsumedMessage{Topic: StorageEventsTopic, Value: []byte(value), Time: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)}
+}
+
+func TestDecodeUsageEvent(t *testing.T) {
+	workspaceID := uuid.New()
+	ws := `"workspace_id":"` + workspaceID.String() + `"`
+	tests := []struct {
+		name  string
+		value string
+		want  models.UsageEvent
+	}{
+		{"channel created", `{"type":"channel.created","data":{` + ws + `,"channel_id":"c1"}}`,
+			models.UsageEvent{Kind: models.UsageKindChannel, EntityID: "c1"}},
+		{"channel deleted", `{"type":"channel.deleted","data":{` + ws + `,"channel_id":"c1"}}`,
+			models.UsageEvent{Kind: models.UsageKindChannel, EntityID: "c1", Deleted: true}},
+		{"file uploaded", `{"type":"file.uploaded","data":{` + ws + `,"file_id":"f1","size_bytes":2048}}`,
+			models.UsageEvent{Kind: models.UsageKindFile, EntityID: "f1", SizeBytes: 2048}},
+		{"file deleted", `{"type":"file.deleted","data":{` + ws + `,"file_id":"f1"}}`,
+			models.UsageEvent{Kind: models.UsageKindFile, EntityID: "f1", Deleted: true}},
+	}
+	for _, tt := range tests {
+		event, err := decodeUsageEvent(usageMessage(tt.value))
+		if err != nil || event == nil {
+			t.Errorf("%s: got (%v, %v), want an event", tt.name, event, err)
+			continue
+		}
+		tt.want.WorkspaceID = workspaceID
+		tt.want.OccurredAt = usageMessage("").Time
+		if *event != tt.want {
+			t.Errorf("%s: got %+v, want %+v", tt.name, *event, tt.want)
+		}
+	}
+}
+
+func TestDecodeUsageEventPrefersEnvelopeTimestamp(t *testing.T) {
+	value := `{"type":"channel.created","timestamp":"2024-06-01T08:30:00Z","data":{"workspace_id":"` + uuid.New().String() + `","channel_id":"c1"}}`
+
+	event, err := decodeUsageEvent(usageMessage(value))
+	if err != nil {
+		t.Fatalf("decodeUsageEvent: %v", err)
+	}
+	if want := time.Date(2024, 6, 1, 8, 30, 0, 0, time.UTC); !event.OccurredAt.Equal(want) {
+		t.Errorf("occurred at %v, want %v", event.OccurredAt, want)
+	}
+}
+
+func TestDecodeUsageEventIgnoresOtherTypes(t *testing.T) {
+	event, err := decodeUsageEvent(usageMessage(`{"type":"channel.renamed","data":{}}`))
+	if err != nil || event != nil {
+		t.Errorf("got (%v, %v), want (nil, nil)", event, err)
+	}
+}
+
+func TestDecodeUsageEventRejectsMalformed(t *testing.T) {
+	ws := `"workspace_id":"` + uuid.New().String() + `"`
+	tests := map[string]string{
+		"not json":          `{"type":`,
+		"bad workspace":     `{"type":"channel.created","data":{"workspace_id":"acme","channel_id":"c1"}}`,
+		"missing entity":    `{"type":"channel.created","data":{` + ws + `}}`,
+		"entity too long":   `{"type":"file.deleted","data":{` + ws + `,"file_id":"` + strings.Repeat("f", 65) + `"}}`,
+		"negative size":     `{"type":"file.uploaded","data":{` + ws + `,"file_id":"f1","size_bytes":-1}}`,
+		"non-string entity": `{"type":"file.deleted","data":{` + ws + `,"file_id":7}}`,
+	}
+	for name, value := range tests {
+		if _, err := decodeUsageEvent(usageMessage(value)); !errors.Is(err, errMalformedEvent) {
+			t.Errorf("%s: err = %v, want errMalformedEvent", name, err)
+		}
+	}
+}
+
+// fakeEventSource hands out its messages in order, then blocks until the
+// consumer is stopped.
+type fakeEventSource struct {
+	mu        sync.Mutex
+	messages  []*db.ConsumedMessage
+	committed []*db.ConsumedMessage
+}
+
+func (f *fakeEventSource) Topic() string { return StorageEventsTopic }
+func (f *fakeEventSource) Lag() int64    { return 0 }
+
+func (f *fakeEventSource) Fetch(ctx context.Context) (*db.ConsumedMessage, error) {
+	f.mu.Lock()
+	if len(f.messages) > 0 {
+		msg := f.messages[0]
+		f.messages = f.messages[1:]
+		f.mu.Unlock()
+		return msg, nil
+	}
+	f.mu.Unlock()
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func (f *fakeEventSource) Commit(ctx context.Context, m *db.ConsumedMessage) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.committed = append(f.committed, m)
+	return nil
+}
+
+func (f *fakeEventSource) committedCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.committed)
+}
+
+func TestEventConsumerCommitsSkippedMessages(t *testing.T) {
+	s, _ := newTestService(t)
+	e := NewEventConsumer(s, nil, nil, testLogger())
+	source := &fakeEventSource{messages: []*db.ConsumedMessage{
+		usageMessage(`{"type":`),
+		usageMessage(`{"type":"channel.renamed","data":{}}`),
+	}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		e.run(ctx, source)
+	}()
+	deadline := time.Now().Add(time.Second)
+	for source.committedCount() < 2 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	<-done
+
+	if got := source.committedCount(); got != 2 {
+		t.Errorf("committed %d messages, want the malformed and the ignored one", got)
+	}
+}
+
+func TestEventConsumerLeavesFailedMessageUncommitted(t *testing.T) {
+	s, mock := newTestService(t)
+	e := NewEventConsumer(s, nil, nil, testLogger())
+	msg := usageMessage(`{"type":"file.deleted","data":{"workspace_id":"` + uuid.New().String() + `","file_id":"f1"}}`)
+	source := &fakeEventSource{messages: []*db.ConsumedMessage{msg}}
+	mock.ExpectQuery(`SELECT \* FROM workspaces WHERE id = \?`).
+		WillReturnError(errors.New("database unavailable"))
+
+	// Stop while the message is waiting to be retried.
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	e.run(ctx, source)
+
+	if got := source.committedCount(); got != 0 {
+		t.Errorf("committed %d messages, want the failed message left for redelivery", got)
+	}
+}
@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/quckapp/workspace-service/internal/models"
+	"github.com/quckapp/workspace-service/internal/requestid"
+)
+
+func TestNewEventEnvelope(t *testing.T) {
+	ctx := requestid.NewContext(context.Background(), "req-123")
+	envelope := newEventEnvelope(ctx, "member.joined", map[string]interface{}{"user_id": "u1"})
+
+	if envelope.SchemaVersion != models.EventSchemaVersion {
+		t.Errorf("schema version = %d, want %d", envelope.SchemaVersion, models.EventSchemaVersion)
+	}
+	if envelope.Type != "member.joined" || envelope.RequestID != "req-123" {
+		t.Errorf("got type %q request %q", envelope.Type, envelope.RequestID)
+	}
+	if envelope.Timestamp.IsZero() || envelope.Timestamp.Location().String() != "UTC" {
+		t.Errorf("timestamp = %v, want a UTC time", envelope.Timestamp)
+	}
+	if envelope.Data["user_id"] != "u1" {
+		t.Errorf("data = %v, want the payload under data", envelope.Data)
+	}
+}
+
+func TestOutboxEventCarriesVersionedEnvelope(t *testing.T) {
+	envelope := newEventEnvelope(context.Background(), "workspace.updated", map[string]interface{}{"type": "payload field"})
+	event, err := newOutboxEvent("workspace-events", "key", envelope)
+	if err != nil {
+		t.Fatalf("newOutboxEvent: %v", err)
+	}
+	if event.Topic != "workspace-events" || event.EventKey != "key" || event.EventType != "workspace.updated" {
+		t.Errorf("event = %+v", event)
+	}
+
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(event.Payload, &decoded); err != nil {
+		t.Fatalf("payload is not JSON: %v", err)
+	}
+	if decoded["schema_version"] != float64(models.EventSchemaVersion) {
+		t.Errorf("schema_version = %v, want %d", decoded["schema_version"], models.EventSchemaVersion)
+	}
+	// A payload field named like an envelope field must not replace it.
+	if decoded["type"] != "workspace.updated" {
+		t.Errorf("type = %v, want the event type", decoded["type"])
+	}
+	if _, ok := decoded["request_id"]; ok {
+		t.Error("request_id present without a request")
+	}
+}
+
+func TestNewEventEnvelopeDoesNotAliasCallerData(t *testing.T) {
+	data := map[string]interface{}{"name": "before"}
+	envelope := newEventEnvelope(context.Background(), "workspace.updated", data)
+
+	if len(data) != 1 {
+		t.Errorf("caller's map gained fields: %v", data)
+	}
+	data["name"] = "after"
+	if envelope.Data["name"] != "before" {
+		t.Error("changing the caller's map changed the published event")
+	}
+	envelope.Data["extra"] = true
+	if _, ok := data["extra"]; ok {
+		t.Error("changing the event changed the caller's map")
+	}
+}
+
+func TestCopyEventData(t *testing.T) {
+	if copied := copyEventData(nil); copied == nil || len(copied) != 0 {
+		t.Errorf("copyEventData(nil) = %v, want an empty writable map", copied)
+	}
+	data := map[string]interface{}{"a": 1}
+	copied := copyEventData(data)
+	copied["type"] = "x"
+	if _, ok := data["type"]; ok {
+		t.Error("copy shares storage with the original")
+	}
+}
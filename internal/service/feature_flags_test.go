@@ -0,0 +1,159 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/quckapp/workspace-service/internal/models"
+)
+
+func flagRows(workspaceID uuid.UUID) *sqlmock.Rows {
+	return sqlmock.NewRows([]string{"id", "workspace_id", "key", "enabled", "rollout_percentage"}).
+		AddRow(uuid.New().String(), workspaceID.String(), "new_editor", true, 100).
+		AddRow(uuid.New().String(), workspaceID.String(), "dark_mode", false, 100)
+}
+
+func TestCheckFeatureFlagsReportsMissingKeysDisabled(t *testing.T) {
+	s, mock := newTestService(t)
+	workspaceID := uuid.New()
+
+	expectMember(mock, true)
+	mock.ExpectQuery("SELECT \\* FROM workspace_feature_flags WHERE workspace_id = \\? AND `key` IN \\(\\?, \\?, \\?\\)").
+		WithArgs(workspaceID, "new_editor", "dark_mode", "unknown").
+		WillReturnRows(flagRows(workspaceID))
+
+	flags, err := s.CheckFeatureFlags(context.Background(), workspaceID, uuid.New(), []string{"new_editor", "dark_mode", "unknown"})
+	if err != nil {
+		t.Fatalf("CheckFeatureFlags: %v", err)
+	}
+	if len(flags) != 3 || !flags["new_editor"] || flags["dark_mode"] || flags["unknown"] {
+		t.Errorf("got %v, want only new_editor on and every key present", flags)
+	}
+}
+
+func TestCheckFeatureFlagsAgreesWithSingleCheck(t *testing.T) {
+	s, mock := newTestService(t)
+	workspaceID, userID := uuid.New(), uuid.New()
+
+	expectMember(mock, true)
+	mock.ExpectQuery(`SELECT \* FROM workspace_feature_flags WHERE workspace_id = \? AND .key. IN`).
+		WillReturnRows(flagRows(workspaceID))
+	batch, err := s.CheckFeatureFlags(context.Background(), workspaceID, userID, []string{"new_editor", "dark_mode"})
+	if err != nil {
+		t.Fatalf("CheckFeatureFlags: %v", err)
+	}
+
+	for _, key := range []string{"new_editor", "dark_mode"} {
+		expectMember(mock, true)
+		rows := flagRows(workspaceID)
+		if key == "dark_mode" {
+			rows = sqlmock.NewRows([]string{"id", "workspace_id", "key", "enabled", "rollout_percentage"}).
+				AddRow(uuid.New().String(), workspaceID.String(), "dark_mode", false, 100)
+		}
+		mock.ExpectQuery(`SELECT \* FROM workspace_feature_flags WHERE workspace_id = \? AND .key. = \?`).
+			WithArgs(workspaceID, key).
+			WillReturnRows(rows)
+
+		single, err := s.CheckFeatureFlag(context.Background(), workspaceID, userID, key)
+		if err != nil {
+			t.Fatalf("CheckFeatureFlag(%s): %v", key, err)
+		}
+		if single.Enabled != batch[key] {
+			t.Errorf("%s: single check %v, batch check %v", key, single.Enabled, batch[key])
+		}
+	}
+}
+
+func TestCheckFeatureFlagsRequiresMembership(t *testing.T) {
+	s, mock := newTestService(t)
+	expectMember(mock, false)
+
+	if _, err := s.CheckFeatureFlags(context.Background(), uuid.New(), uuid.New(), []string{"a"}); err != ErrNotMember {
+		t.Errorf("err = %v, want ErrNotMember", err)
+	}
+}
+
+// testUsers returns n user IDs that are the same on every run.
+func testUsers(n int) []uuid.UUID {
+	users := make([]uuid.UUID, n)
+	for i := range users {
+		users[i] = uuid.NewSHA1(uuid.NameSpaceOID, []byte(fmt.Sprintf("user-%d", i)))
+	}
+	return users
+}
+
+func TestFeatureFlagBucketIsStable(t *testing.T) {
+	for _, userID := range testUsers(100) {
+		b := featureFlagBucket("new_editor", userID)
+		if b < 0 || b >= 100 {
+			t.Fatalf("bucket %d out of range", b)
+		}
+		if again := featureFlagBucket("new_editor", userID); again != b {
+			t.Fatalf("bucket changed from %d to %d for the same user", b, again)
+		}
+	}
+}
+
+func TestFeatureFlagBucketIsEvenlyDistributed(t *testing.T) {
+	const users = 20000
+	counts := make([]int, 100)
+	for _, userID := range testUsers(users) {
+		counts[featureFlagBucket("new_editor", userID)]++
+	}
+	// 200 per bucket expected; a standard deviation is about 14.
+	for bucket, n := range counts {
+		if n < 140 || n > 260 {
+			t.Errorf("bucket %d holds %d users, want about %d", bucket, n, users/100)
+		}
+	}
+}
+
+func TestFeatureFlagBucketIsIndependentPerFlag(t *testing.T) {
+	same := 0
+	userIDs := testUsers(1000)
+	for _, userID := range userIDs {
+		if featureFlagBucket("new_editor", userID) == featureFlagBucket("dark_mode", userID) {
+			same++
+		}
+	}
+	if same > 30 {
+		t.Errorf("%d of %d users share a bucket across flags, want about 1%%", same, len(userIDs))
+	}
+}
+
+func TestEvaluateFeatureFlagRollout(t *testing.T) {
+	userIDs := testUsers(5000)
+	on := func(percentage int, enabled bool) map[uuid.UUID]bool {
+		flag := &models.WorkspaceFeatureFlag{Key: "new_editor", Enabled: enabled, RolloutPercentage: percentage}
+		result := map[uuid.UUID]bool{}
+		for _, userID := range userIDs {
+			if evaluateFeatureFlag(flag, userID) {
+				result[userID] = true
+			}
+		}
+		return result
+	}
+
+	if n := len(on(0, true)); n != 0 {
+		t.Errorf("0%% rollout on for %d users", n)
+	}
+	if n := len(on(100, true)); n != len(userIDs) {
+		t.Errorf("100%% rollout on for %d of %d users", n, len(userIDs))
+	}
+	if n := len(on(100, false)); n != 0 {
+		t.Errorf("disabled flag on for %d users", n)
+	}
+
+	thirty, sixty := on(30, true), on(60, true)
+	if n := len(thirty); n < 1350 || n > 1650 {
+		t.Errorf("30%% rollout on for %d of %d users", n, len(userIDs))
+	}
+	for userID := range thirty {
+		if !sixty[userID] {
+			t.Fatal("a user in the 30% rollout dropped out when it grew to 60%")
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package service
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// Guests are invited collaborators with a deliberately narrow footprint.
+// A guest can:
+//   - view the workspace and their own membership, profile and preferences
+//   - read announcements and pins
+//   - keep a small set of personal bookmarks (at most guestBookmarkLimit)
+//   - leave the workspace
+//
+// A guest cannot:
+//   - add reactions
+//   - browse, search or facet the member directory
+//   - keep more than guestBookmarkLimit bookmarks
+//   - do anything that already requires owner, admin or a custom permission
+//
+// Capability checks go through requireCapability so the rules stay in one
+// place; the bookmark cap is applied by bookmarkLimit.
+const (
+	guestRole          = "guest"
+	guestBookmarkLimit = 10
+)
+
+type guestCapability string
+
+const (
+	capReact           guestCapability = "react"
+	capMemberDirectory guestCapability = "member_directory"
+)
+
+// guestDenied lists the capabilities withheld from guests.
+var guestDenied = map[guestCapability]bool{
+	capReact:           true,
+	capMemberDirectory: true,
+}
+
+// requireCapability checks that the user is a member and that their role may
+// use the capability. Non-members get ErrNotMember; guests asking for a
+// withheld capability get ErrNotAuthorized.
+func (s *WorkspaceService) requireCapability(ctx context.Context, workspaceID, userID uuid.UUID, capability guestCapability) error {
+	role, err := s.memberRepo.GetRole(ctx, workspaceID, userID)
+	if err != nil || role == "" {
+		return ErrNotMember
+	}
+	if role == guestRole && guestDenied[capability] {
+		return ErrNotAuthorized
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/quckapp/workspace-service/internal/models"
+)
+
+func TestRequireCapability(t *testing.T) {
+	tests := []struct {
+		role       string
+		capability guestCapability
+		want       error
+	}{
+		{"owner", capReact, nil},
+		{"member", capMemberDirectory, nil},
+		{"guest", capReact, ErrNotAuthorized},
+		{"guest", capMemberDirectory, ErrNotAuthorized},
+		{"", capReact, ErrNotMember},
+	}
+	for _, tt := range tests {
+		s, mock := newTestService(t)
+		expectRole(mock, tt.role)
+		if err := s.requireCapability(context.Background(), uuid.New(), uuid.New(), tt.capability); err != tt.want {
+			t.Errorf("requireCapability(%q, %s) = %v, want %v", tt.role, tt.capability, err, tt.want)
+		}
+	}
+}
+
+func TestGuestCannotReact(t *testing.T) {
+	s, mock := newTestService(t)
+	expectRole(mock, "guest")
+
+	req := &models.AddReactionRequest{EntityType: "announcement", EntityID: uuid.New().String(), Emoji: "👍"}
+	if err := s.AddReaction(context.Background(), uuid.New(), uuid.New(), req); err != ErrNotAuthorized {
+		t.Errorf("err = %v, want ErrNotAuthorized", err)
+	}
+}
+
+func TestGuestCannotListMembers(t *testing.T) {
+	s, mock := newTestService(t)
+	expectRole(mock, "guest")
+
+	if _, _, err := s.ListMembers(context.Background(), uuid.New(), uuid.New(), 1, 20); err != ErrNotAuthorized {
+		t.Errorf("err = %v, want ErrNotAuthorized", err)
+	}
+}
+
+func TestGuestBookmarkLimit(t *testing.T) {
+	ws := &models.Workspace{Plan: "enterprise"}
+	if got := bookmarkLimit(ws, guestRole); got != guestBookmarkLimit {
+		t.Errorf("guest limit = %d, want %d", got, guestBookmarkLimit)
+	}
+	if got := bookmarkLimit(ws, "member"); got <= guestBookmarkLimit {
+		t.Errorf("member limit = %d, want more than the guest limit", got)
+	}
+}
@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/quckapp/workspace-service/internal/db"
+	"github.com/quckapp/workspace-service/internal/models"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+)
+
+const healthCheckTimeout = 2 * time.Second
+
+var errNotConnected = errors.New("not connected")
+
+// HealthChecker reports the state of the service's backing dependencies.
+// MySQL is required; Redis and Kafka are optional because the service keeps
+// running without them, so losing either only degrades readiness. Ping
+// errors are logged rather than reported, since the readiness endpoint is
+// unauthenticated.
+type HealthChecker struct {
+	mysql  *sqlx.DB
+	redis  *redis.Client
+	kafka  *db.KafkaProducer
+	logger *logrus.Logger
+}
+
+func NewHealthChecker(mysql *sqlx.DB, redis *redis.Client, kafka *db.KafkaProducer, logger *logrus.Logger) *HealthChecker {
+	return &HealthChecker{mysql: mysql, redis: redis, kafka: kafka, logger: logger}
+}
+
+// Readiness pings every dependency. The report is "unready" if a required
+// dependency is down, "degraded" if only optional ones are, else "ready".
+func (h *HealthChecker) Readiness(ctx context.Context) *models.ReadinessReport {
+	report := &models.ReadinessReport{
+		Status:    "ready",
+		CheckedAt: time.Now().UTC(),
+		Dependencies: []*models.DependencyStatus{
+			h.check(ctx, "mysql", true, func(ctx context.Context) error {
+				if h.mysql == nil {
+					return errNotConnected
+				}
+				return h.mysql.PingContext(ctx)
+			}),
+			h.check(ctx, "redis", false, func(ctx context.Context) error {
+				if h.redis == nil {
+					return errNotConnected
+				}
+				return h.redis.Ping(ctx).Err()
+			}),
+			h.check(ctx, "kafka", false, func(ctx context.Context) error {
+				if h.kafka == nil {
+					return errNotConnected
+				}
+				return h.kafka.Ping(ctx)
+			}),
+		},
+	}
+
+	for _, dep := range report.Dependencies {
+		if dep.Status == "up" {
+			continue
+		}
+		if dep.Required {
+			report.Status = "unready"
+			break
+		}
+		report.Status = "degraded"
+	}
+	return report
+}
+
+func (h *HealthChecker) check(ctx context.Context, name string, required bool, ping func(context.Context) error) *models.DependencyStatus {
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+
+	start := time.Now()
+	err := ping(ctx)
+	status := &models.DependencyStatus{
+		Name:      name,
+		Status:    "up",
+		Required:  required,
+		LatencyMs: time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		status.Status = "down"
+		h.logger.WithError(err).WithField("dependency", name).Warn("Dependency health check failed")
+	}
+	return status
+}
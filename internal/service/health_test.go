@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/sirupsen/logrus/hooks/test"
+)
+
+func TestReadinessDegradedWithoutOptionalDependencies(t *testing.T) {
+	db, _ := newTestDB(t)
+	report := NewHealthChecker(db, nil, nil, testLogger()).Readiness(context.Background())
+
+	if report.Status != "degraded" {
+		t.Errorf("status = %q, want degraded with Redis and Kafka down", report.Status)
+	}
+	for _, dep := range report.Dependencies {
+		want := "down"
+		if dep.Name == "mysql" {
+			want = "up"
+		}
+		if dep.Status != want {
+			t.Errorf("%s is %q, want %q", dep.Name, dep.Status, want)
+		}
+	}
+}
+
+func TestReadinessUnreadyWithoutMySQL(t *testing.T) {
+	report := NewHealthChecker(nil, nil, nil, testLogger()).Readiness(context.Background())
+	if report.Status != "unready" {
+		t.Errorf("status = %q, want unready", report.Status)
+	}
+}
+
+func TestReadinessLogsErrorsWithoutReportingThem(t *testing.T) {
+	conn, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	if err != nil {
+		t.Fatalf("sqlmock: %v", err)
+	}
+	defer conn.Close()
+	mock.ExpectPing().WillReturnError(errors.New("dial tcp 10.0.3.7:3306: connection refused"))
+
+	logger, hook := test.NewNullLogger()
+	report := NewHealthChecker(sqlx.NewDb(conn, "mysql"), nil, nil, logger).Readiness(context.Background())
+
+	if report.Status != "unready" {
+		t.Errorf("status = %q, want unready", report.Status)
+	}
+	body, _ := json.Marshal(report)
+	if strings.Contains(string(body), "10.0.3.7") {
+		t.Errorf("readiness report leaks the ping error: %s", body)
+	}
+	logged := false
+	for _, entry := range hook.AllEntries() {
+		if entry.Data["dependency"] == "mysql" && entry.Data["error"] != nil {
+			logged = true
+		}
+	}
+	if !logged {
+		t.Error("the MySQL ping error was not logged")
+	}
+}
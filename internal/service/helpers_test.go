@@ -0,0 +1,232 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/quckapp/workspace-service/internal/repository"
+	"github.com/sirupsen/logrus"
+)
+
+// newTestDB returns a sqlx handle backed by sqlmock. Expectations are
+// checked when the test ends.
+func newTestDB(t testing.TB) (*sqlx.DB, sqlmock.Sqlmock) {
+	t.Helper()
+	conn, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("unmet expectations: %v", err)
+		}
+		conn.Close()
+	})
+	return sqlx.NewDb(conn, "mysql"), mock
+}
+
+func testLogger() *logrus.Logger {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	return logger
+}
+
+// newTestService wires a WorkspaceService to a mock database, with no Redis
+// and no Kafka. Activity log writes the test does not expect fail against
+// the mock and are dropped, as they would be in production.
+func newTestService(t testing.TB) (*WorkspaceService, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock := newTestDB(t)
+	integrationRepo := repository.NewIntegrationRepository(db)
+	s := NewWorkspaceService(
+		repository.NewWorkspaceRepository(db),
+		repository.NewMemberRepository(db),
+		repository.NewInviteRepository(db),
+		repository.NewInviteCodeRepository(db),
+		repository.NewActivityRepository(db),
+		repository.NewProfileRepository(db),
+		repository.NewRoleRepository(db),
+		repository.NewTemplateRepository(db),
+		repository.NewPreferenceRepository(db),
+		repository.NewTagRepository(db),
+		repository.NewModerationRepository(db),
+		repository.NewAnnouncementRepository(db),
+		repository.NewWebhookRepository(db),
+		repository.NewFavoriteRepository(db),
+		repository.NewMemberNoteRepository(db),
+		repository.NewScheduledActionRepository(db),
+		repository.NewQuotaRepository(db),
+		repository.NewPinnedItemRepository(db),
+		repository.NewGroupRepository(db),
+		repository.NewCustomFieldRepository(db),
+		repository.NewReactionRepository(db),
+		repository.NewBookmarkRepository(db),
+		repository.NewInvitationHistoryRepository(db),
+		repository.NewAccessLogRepository(db),
+		repository.NewFeatureFlagRepository(db),
+		integrationRepo,
+		repository.NewLabelRepository(db),
+		repository.NewStreakRepository(db),
+		repository.NewOnboardingRepository(db),
+		repository.NewComplianceRepository(db),
+		repository.NewOutboxRepository(db),
+		repository.NewSecurityRepository(db),
+		repository.NewDiscoveryRepository(db),
+		repository.NewAPIKeyRepository(db),
+		repository.NewJoinRequestRepository(db),
+		NewAuditSink(integrationRepo, nil, testLogger()),
+		nil,
+		nil,
+		nil,
+		CacheConfig{},
+		IconConfig{},
+		"https://app.example.com",
+		false,
+		nil,
+		nil,
+		testLogger(),
+	)
+	return s, mock
+}
+
+// expectRole expects the member role lookup; an empty role means the user
+// is not a member.
+func expectRole(mock sqlmock.Sqlmock, role string) {
+	q := mock.ExpectQuery(`SELECT role FROM workspace_members`)
+	if role == "" {
+		q.WillReturnError(sql.ErrNoRows)
+		return
+	}
+	q.WillReturnRows(sqlmock.NewRows([]string{"role"}).AddRow(role))
+}
+
+// expectMember expects the active membership check.
+func expectMember(mock sqlmock.Sqlmock, member bool) {
+	n := 0
+	if member {
+		n = 1
+	}
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM workspace_members WHERE workspace_id = \? AND user_id = \?`).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(n))
+}
+
+// expectDefaultRole expects the lookups memberPermissions makes for a member
+// of a workspace that has no stored roles, so the built-in defaults apply.
+// It does not cover the pin_admins_only check.
+func expectDefaultRole(mock sqlmock.Sqlmock, role string) {
+	expectRole(mock, role)
+	if role == "" || role == "owner" {
+		return
+	}
+	mock.ExpectQuery(`SELECT \* FROM workspace_roles WHERE workspace_id = \? AND name = \?`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectQuery(`FROM workspace_roles r\s+JOIN workspace_member_roles`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+}
+
+// memKVStore is an in-memory kvStore for tests that need Redis semantics.
+type memKVStore struct {
+	mu     sync.Mutex
+	values map[string][]byte
+	hashes map[string]map[string][]byte
+}
+
+func newMemKVStore() *memKVStore {
+	return &memKVStore{values: map[string][]byte{}, hashes: map[string]map[string][]byte{}}
+}
+
+func kvBytes(value interface{}) []byte {
+	switch v := value.(type) {
+	case []byte:
+		return v
+	case string:
+		return []byte(v)
+	}
+	return nil
+}
+
+func (m *memKVStore) Available() bool { return true }
+
+func (m *memKVStore) Get(ctx context.Context, key string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	v, ok := m.values[key]
+	if !ok {
+		return nil, errKVMiss
+	}
+	return v, nil
+}
+
+func (m *memKVStore) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.values[key] = kvBytes(value)
+}
+
+func (m *memKVStore) Exists(ctx context.Context, key string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, ok := m.values[key]
+	return ok
+}
+
+func (m *memKVStore) Del(ctx context.Context, keys ...string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, key := range keys {
+		delete(m.values, key)
+		delete(m.hashes, key)
+	}
+}
+
+func (m *memKVStore) HGet(ctx context.Context, key, field string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	v, ok := m.hashes[key][field]
+	if !ok {
+		return nil, errKVMiss
+	}
+	return v, nil
+}
+
+func (m *memKVStore) HSet(ctx context.Context, key, field string, value interface{}, ttl time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.hashes[key] == nil {
+		m.hashes[key] = map[string][]byte{}
+	}
+	m.hashes[key][field] = kvBytes(value)
+}
+
+func (m *memKVStore) HDel(ctx context.Context, key string, fields ...string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, field := range fields {
+		delete(m.hashes[key], field)
+	}
+}
+
+func (m *memKVStore) SetNX(ctx context.Context, key string, value interface{}, ttl time.Duration) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.values[key]; ok {
+		return false, nil
+	}
+	m.values[key] = kvBytes(value)
+	return true, nil
+}
+
+func (m *memKVStore) DelIfEqual(ctx context.Context, key, value string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if string(m.values[key]) == value {
+		delete(m.values, key)
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/quckapp/workspace-service/internal/models"
+)
+
+// integrationProvider describes a provider integrations can be created for:
+// the config keys and credentials it needs, and its syncer if it can sync.
+type integrationProvider struct {
+	models.IntegrationProvider
+	syncer IntegrationSyncer
+}
+
+// integrationProviders is the registry of supported providers. Add a
+// provider here, with an IntegrationSyncer if it supports sync.
+var integrationProviders = map[string]integrationProvider{
+	"slack": {
+		IntegrationProvider: models.IntegrationProvider{
+			Provider: "slack", DisplayName: "Slack",
+			RequiredConfig: []string{"channel"}, RequiresCredentials: true, CredentialsLabel: "Bot token",
+		},
+		syncer: slackSyncer{},
+	},
+	"github": {
+		IntegrationProvider: models.IntegrationProvider{
+			Provider: "github", DisplayName: "GitHub",
+			RequiredConfig: []string{"repository"}, RequiresCredentials: true, CredentialsLabel: "Access token",
+		},
+		syncer: githubSyncer{},
+	},
+	"jira": {
+		IntegrationProvider: models.IntegrationProvider{
+			Provider: "jira", DisplayName: "Jira",
+			RequiredConfig: []string{"site_url", "project_key"}, RequiresCredentials: true, CredentialsLabel: "API token",
+		},
+	},
+	"discord": {
+		IntegrationProvider: models.IntegrationProvider{
+			Provider: "discord", DisplayName: "Discord",
+			RequiredConfig: []string{"guild_id"}, RequiresCredentials: true, CredentialsLabel: "Bot token",
+		},
+	},
+	"linear": {
+		IntegrationProvider: models.IntegrationProvider{
+			Provider: "linear", DisplayName: "Linear",
+			RequiredConfig: []string{"team_id"}, RequiresCredentials: true, CredentialsLabel: "API key",
+		},
+	},
+	"notion": {
+		IntegrationProvider: models.IntegrationProvider{
+			Provider: "notion", DisplayName: "Notion",
+			RequiredConfig: []string{"database_id"}, RequiresCredentials: true, CredentialsLabel: "Integration token",
+		},
+	},
+}
+
+// ListSupportedProviders returns the provider registry, sorted by provider,
+// so clients can render the right form for each.
+func (s *WorkspaceService) ListSupportedProviders() []models.IntegrationProvider {
+	providers := make([]models.IntegrationProvider, 0, len(integrationProviders))
+	for _, p := range integrationProviders {
+		def := p.IntegrationProvider
+		def.SupportsSync = p.syncer != nil
+		providers = append(providers, def)
+	}
+	sort.Slice(providers, func(i, j int) bool { return providers[i].Provider < providers[j].Provider })
+	return providers
+}
+
+// IntegrationValidationError reports every problem with an integration
+// request at once, keyed by field ("provider", "credentials", "config.<key>").
+type IntegrationValidationError struct {
+	Fields map[string]string `json:"fields"`
+}
+
+func (e *IntegrationValidationError) Error() string {
+	keys := make([]string, 0, len(e.Fields))
+	for k := range e.Fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return "invalid integration: " + strings.Join(keys, ", ")
+}
+
+// validateIntegration checks a provider's required config keys and
+// credentials. Unknown providers are rejected outright.
+func validateIntegration(provider string, config models.JSON, hasCredentials bool) error {
+	p, ok := integrationProviders[provider]
+	if !ok {
+		return &IntegrationValidationError{Fields: map[string]string{"provider": "unknown provider"}}
+	}
+
+	problems := map[string]string{}
+	for _, key := range p.RequiredConfig {
+		if v, ok := config[key].(string); !ok || strings.TrimSpace(v) == "" {
+			problems["config."+key] = fmt.Sprintf("required by %s", p.DisplayName)
+		}
+	}
+	if p.RequiresCredentials && !hasCredentials {
+		problems["credentials"] = fmt.Sprintf("%s is required by %s", strings.ToLower(p.CredentialsLabel), p.DisplayName)
+	}
+	if len(problems) > 0 {
+		return &IntegrationValidationError{Fields: problems}
+	}
+	return nil
+}
@@ -0,0 +1,218 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/quckapp/workspace-service/internal/models"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	integrationSyncInterval  = 15 * time.Minute
+	integrationSyncBatchSize = 100
+	integrationSyncTimeout   = 30 * time.Second
+)
+
+// IntegrationSyncer pulls state from one external provider. credentials is
+// the decrypted secret, or "" when the integration has none. A returned
+// error is stored on the integration and flips its status to error.
+type IntegrationSyncer interface {
+	Sync(ctx context.Context, integration *models.WorkspaceIntegration, credentials string) error
+}
+
+// integrationSyncer returns the provider's syncer, or nil if the provider
+// is unknown or cannot sync. Syncers are registered in integrationProviders.
+func integrationSyncer(provider string) IntegrationSyncer {
+	return integrationProviders[provider].syncer
+}
+
+// githubSyncer is a stub until the GitHub client lands; it checks the
+// integration is configured well enough to sync.
+type githubSyncer struct{}
+
+func (githubSyncer) Sync(ctx context.Context, integration *models.WorkspaceIntegration, credentials string) error {
+	if credentials == "" {
+		return fmt.Errorf("github: missing access token")
+	}
+	if repo, _ := integration.Config["repository"].(string); repo == "" {
+		return fmt.Errorf("github: config.repository is required")
+	}
+	return nil
+}
+
+// slackSyncer is a stub until the Slack client lands; it checks the
+// integration is configured well enough to sync.
+type slackSyncer struct{}
+
+func (slackSyncer) Sync(ctx context.Context, integration *models.WorkspaceIntegration, credentials string) error {
+	if credentials == "" {
+		return fmt.Errorf("slack: missing bot token")
+	}
+	return nil
+}
+
+// SyncIntegration runs a sync now and returns the resulting status. A failed
+// sync is recorded on the integration rather than returned as an error.
+func (s *WorkspaceService) SyncIntegration(ctx context.Context, workspaceID, userID, integrationID uuid.UUID) (*models.IntegrationSyncStatus, error) {
+	role, _ := s.memberRepo.GetRole(ctx, workspaceID, userID)
+	if role != "owner" && role != "admin" {
+		return nil, ErrNotAuthorized
+	}
+
+	integration, err := s.integrationRepo.GetByID(ctx, integrationID)
+	if err != nil {
+		return nil, err
+	}
+	if integration == nil || integration.WorkspaceID != workspaceID {
+		return nil, ErrIntegrationNotFound
+	}
+	if integrationSyncer(integration.Provider) == nil {
+		return nil, ErrSyncNotSupported
+	}
+
+	if err := s.syncIntegration(ctx, integration); err != nil {
+		return nil, err
+	}
+
+	s.LogActivity(ctx, workspaceID, userID, "integration.synced", "integration", integrationID.String(), models.JSON{"status": integration.Status})
+	return integrationSyncStatus(integration), nil
+}
+
+// GetIntegrationSyncStatus reports when an integration last synced and
+// whether the last attempt failed.
+func (s *WorkspaceService) GetIntegrationSyncStatus(ctx context.Context, workspaceID, userID, integrationID uuid.UUID) (*models.IntegrationSyncStatus, error) {
+	isMember, _ := s.memberRepo.IsMember(ctx, workspaceID, userID)
+	if !isMember {
+		return nil, ErrNotMember
+	}
+
+	integration, err := s.integrationRepo.GetByID(ctx, integrationID)
+	if err != nil {
+		return nil, err
+	}
+	if integration == nil || integration.WorkspaceID != workspaceID {
+		return nil, ErrIntegrationNotFound
+	}
+	return integrationSyncStatus(integration), nil
+}
+
+// SyncDueIntegrations syncs active integrations that have not synced within
+// one interval, returning how many were attempted.
+func (s *WorkspaceService) SyncDueIntegrations(ctx context.Context) (int, error) {
+	var providers []string
+	for provider, p := range integrationProviders {
+		if p.syncer != nil {
+			providers = append(providers, provider)
+		}
+	}
+
+	integrations, err := s.integrationRepo.ListDueForSync(ctx, providers, time.Now().Add(-integrationSyncInterval), integrationSyncBatchSize)
+	if err != nil {
+		return 0, err
+	}
+	for _, integration := range integrations {
+		if err := s.syncIntegration(ctx, integration); err != nil {
+			s.logger.WithError(err).WithField("integration_id", integration.ID).Warn("Failed to record integration sync")
+		}
+	}
+	return len(integrations), nil
+}
+
+// syncIntegration runs the provider's syncer and records the outcome on the
+// integration. It only returns an error if the outcome could not be saved.
+func (s *WorkspaceService) syncIntegration(ctx context.Context, integration *models.WorkspaceIntegration) error {
+	syncErr := ErrSyncNotSupported
+	if syncer := integrationSyncer(integration.Provider); syncer != nil {
+		credentials, err := s.integrationCredentials(integration)
+		if err != nil {
+			syncErr = err
+		} else {
+			syncCtx, cancel := context.WithTimeout(ctx, integrationSyncTimeout)
+			syncErr = syncer.Sync(syncCtx, integration, credentials)
+			cancel()
+		}
+	}
+
+	now := time.Now()
+	if syncErr != nil {
+		msg := syncErr.Error()
+		integration.Status = "error"
+		integration.ErrorMessage = &msg
+		s.logger.WithError(syncErr).WithFields(logrus.Fields{
+			"integration_id": integration.ID,
+			"provider":       integration.Provider,
+		}).Warn("Integration sync failed")
+		return s.integrationRepo.MarkSyncFailed(ctx, integration.ID, msg)
+	}
+
+	integration.Status = "active"
+	integration.ErrorMessage = nil
+	integration.LastSyncAt = &now
+	return s.integrationRepo.MarkSyncSucceeded(ctx, integration.ID, now)
+}
+
+func integrationSyncStatus(integration *models.WorkspaceIntegration) *models.IntegrationSyncStatus {
+	return &models.IntegrationSyncStatus{
+		IntegrationID: integration.ID,
+		Provider:      integration.Provider,
+		Status:        integration.Status,
+		Supported:     integrationSyncer(integration.Provider) != nil,
+		LastSyncAt:    integration.LastSyncAt,
+		ErrorMessage:  integration.ErrorMessage,
+	}
+}
+
+// IntegrationSyncScheduler periodically syncs active integrations.
+type IntegrationSyncScheduler struct {
+	workspaces *WorkspaceService
+	logger     *logrus.Logger
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+func NewIntegrationSyncScheduler(workspaces *WorkspaceService, logger *logrus.Logger) *IntegrationSyncScheduler {
+	return &IntegrationSyncScheduler{
+		workspaces: workspaces,
+		logger:     logger,
+		stop:       make(chan struct{}),
+		done:       make(chan struct{}),
+	}
+}
+
+// Start begins syncing on a fixed interval.
+func (j *IntegrationSyncScheduler) Start() {
+	go func() {
+		defer close(j.done)
+		ticker := time.NewTicker(integrationSyncInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				j.sync()
+			case <-j.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the sync loop, waiting for a running pass to finish or for
+// ctx to be done, whichever comes first.
+func (j *IntegrationSyncScheduler) Stop(ctx context.Context) error {
+	close(j.stop)
+	return waitDone(ctx, j.done, "integration sync")
+}
+
+func (j *IntegrationSyncScheduler) sync() {
+	synced, err := j.workspaces.SyncDueIntegrations(context.Background())
+	if err != nil {
+		j.logger.WithError(err).Warn("Failed to sync integrations")
+	}
+	if synced > 0 {
+		j.logger.WithField("count", synced).Info("Integration sync pass finished")
+	}
+}
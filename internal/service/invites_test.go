@@ -0,0 +1,495 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/quckapp/workspace-service/internal/models"
+)
+
+func expectWorkspace(mock sqlmock.Sqlmock, workspaceID uuid.UUID) {
+	mock.ExpectQuery(`SELECT \* FROM workspaces WHERE id = \? AND deleted_at IS NULL`).
+		WithArgs(workspaceID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "slug", "owner_id"}).
+			AddRow(workspaceID.String(), "Acme", "acme", uuid.New().String()))
+}
+
+func expectInvitesSent(mock sqlmock.Sqlmock, count int, oldest interface{}) {
+	mock.ExpectQuery(`SELECT COUNT\(\*\) AS count, MIN\(created_at\) AS oldest FROM workspace_invitation_history`).
+		WillReturnRows(sqlmock.NewRows([]string{"count", "oldest"}).AddRow(count, oldest))
+}
+
+func TestGetMyInviteQuotaCountsRollingWindow(t *testing.T) {
+	s, mock := newTestService(t)
+	workspaceID := uuid.New()
+	oldest := time.Now().Add(-3 * time.Hour).Truncate(time.Second)
+
+	expectRole(mock, "member")
+	expectWorkspace(mock, workspaceID)
+	expectInvitesSent(mock, 12, oldest)
+
+	quota, err := s.GetMyInviteQuota(context.Background(), workspaceID, uuid.New())
+	if err != nil {
+		t.Fatalf("GetMyInviteQuota: %v", err)
+	}
+	if quota.Limit != defaultInviteLimitPerMember || quota.Used != 12 || quota.Remaining != defaultInviteLimitPerMember-12 {
+		t.Errorf("got %d/%d with %d remaining", quota.Used, quota.Limit, quota.Remaining)
+	}
+	want := oldest.Add(defaultInviteLimitWindow * time.Hour)
+	if quota.ResetsAt == nil || !quota.ResetsAt.Equal(want) {
+		t.Errorf("resets at %v, want %v", quota.ResetsAt, want)
+	}
+}
+
+func TestGetMyInviteQuotaIgnoresCodeJoins(t *testing.T) {
+	s, mock := newTestService(t)
+	workspaceID, userID := uuid.New(), uuid.New()
+
+	// The inviter has sent 3 email invites and 40 people joined through
+	// their invite code; only the emails use up the quota.
+	expectRole(mock, "admin")
+	expectWorkspace(mock, workspaceID)
+	mock.ExpectQuery(`FROM workspace_invitation_history\s+WHERE workspace_id = \? AND inviter_id = \? AND method = 'email'`).
+		WithArgs(workspaceID, userID, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"count", "oldest"}).AddRow(3, time.Now()))
+
+	quota, err := s.GetMyInviteQuota(context.Background(), workspaceID, userID)
+	if err != nil {
+		t.Fatalf("GetMyInviteQuota: %v", err)
+	}
+	if quota.Used != 3 || quota.Remaining != defaultInviteLimitPerMember-3 {
+		t.Errorf("got %d used with %d remaining, want 3 used", quota.Used, quota.Remaining)
+	}
+}
+
+func TestGetMyInviteQuotaNeverGoesNegative(t *testing.T) {
+	s, mock := newTestService(t)
+	workspaceID := uuid.New()
+
+	expectRole(mock, "admin")
+	expectWorkspace(mock, workspaceID)
+	expectInvitesSent(mock, defaultInviteLimitPerMember+5, time.Now())
+
+	quota, err := s.GetMyInviteQuota(context.Background(), workspaceID, uuid.New())
+	if err != nil {
+		t.Fatalf("GetMyInviteQuota: %v", err)
+	}
+	if quota.Remaining != 0 {
+		t.Errorf("remaining = %d, want 0", quota.Remaining)
+	}
+}
+
+func TestGetMyInviteQuotaOwnerIsUnlimited(t *testing.T) {
+	s, mock := newTestService(t)
+	expectRole(mock, "owner")
+
+	quota, err := s.GetMyInviteQuota(context.Background(), uuid.New(), uuid.New())
+	if err != nil {
+		t.Fatalf("GetMyInviteQuota: %v", err)
+	}
+	if !quota.Unlimited {
+		t.Error("the owner's invites are limited")
+	}
+}
+
+func TestGetMyInviteQuotaRequiresMembership(t *testing.T) {
+	s, mock := newTestService(t)
+	expectRole(mock, "")
+
+	if _, err := s.GetMyInviteQuota(context.Background(), uuid.New(), uuid.New()); err != ErrNotMember {
+		t.Errorf("err = %v, want ErrNotMember", err)
+	}
+}
+
+func expectInviteCode(mock sqlmock.Sqlmock, codeID, workspaceID uuid.UUID, code string, maxUses, useCount int) {
+	mock.ExpectQuery(`SELECT \* FROM workspace_invite_codes WHERE code = \?`).
+		WithArgs(code).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "workspace_id", "code", "max_uses", "use_count", "created_by", "is_active"}).
+			AddRow(codeID.String(), workspaceID.String(), code, maxUses, useCount, uuid.New().String(), true))
+}
+
+// expectJoinChecks expects the ban, membership and workspace lookups
+// JoinByCode makes before claiming a use of the code.
+func expectJoinChecks(mock sqlmock.Sqlmock, workspaceID uuid.UUID) {
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM workspace_bans`).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	expectMember(mock, false)
+	expectWorkspace(mock, workspaceID)
+}
+
+func TestCreateMagicJoinLinkAllowsOneUse(t *testing.T) {
+	s, mock := newTestService(t)
+	workspaceID, userID := uuid.New(), uuid.New()
+
+	expectRole(mock, "owner")
+	mock.ExpectExec(`INSERT INTO workspace_invite_codes`).
+		WithArgs(sqlmock.AnyArg(), workspaceID, sqlmock.AnyArg(), "member", 1, 0, userID, sqlmock.AnyArg(), true, false, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	link, err := s.CreateMagicJoinLink(context.Background(), workspaceID, "member", userID)
+	if err != nil {
+		t.Fatalf("CreateMagicJoinLink: %v", err)
+	}
+	if len(link.Code) != magicLinkCodeLength || link.URL != "https://app.example.com/join?code="+link.Code {
+		t.Errorf("code %q, url %q", link.Code, link.URL)
+	}
+	if ttl := time.Until(link.ExpiresAt); ttl <= 0 || ttl > magicLinkTTL {
+		t.Errorf("expires in %v, want within %v", ttl, magicLinkTTL)
+	}
+}
+
+func TestJoinByCodeRejectsUsedMagicLink(t *testing.T) {
+	s, mock := newTestService(t)
+	expectInviteCode(mock, uuid.New(), uuid.New(), "magic", 1, 1)
+
+	if _, _, err := s.JoinByCode(context.Background(), "magic", uuid.New()); err != ErrInviteCodeMaxUsed {
+		t.Errorf("err = %v, want ErrInviteCodeMaxUsed", err)
+	}
+}
+
+func TestJoinByCodeMagicLinkRaceHasOneWinner(t *testing.T) {
+	s, mock := newTestService(t)
+	codeID, workspaceID := uuid.New(), uuid.New()
+	first, second := uuid.New(), uuid.New()
+
+	// Both users read the code before either claims it.
+	expectInviteCode(mock, codeID, workspaceID, "magic", 1, 0)
+	expectJoinChecks(mock, workspaceID)
+	mock.ExpectExec(`UPDATE workspace_invite_codes SET use_count = use_count \+ 1`).
+		WithArgs(sqlmock.AnyArg(), codeID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectBegin()
+	mock.ExpectExec(`DELETE FROM workspace_members`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`INSERT INTO workspace_members`).
+		WithArgs(sqlmock.AnyArg(), workspaceID, first, "member", sqlmock.AnyArg(), sqlmock.AnyArg(), true, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	expectWorkspace(mock, workspaceID)
+
+	if _, _, err := s.JoinByCode(context.Background(), "magic", first); err != nil {
+		t.Fatalf("first JoinByCode: %v", err)
+	}
+
+	expectInviteCode(mock, codeID, workspaceID, "magic", 1, 0)
+	expectJoinChecks(mock, workspaceID)
+	mock.ExpectExec(`UPDATE workspace_invite_codes SET use_count = use_count \+ 1`).
+		WithArgs(sqlmock.AnyArg(), codeID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	if _, _, err := s.JoinByCode(context.Background(), "magic", second); err != ErrInviteCodeMaxUsed {
+		t.Errorf("second JoinByCode err = %v, want ErrInviteCodeMaxUsed", err)
+	}
+}
+
+func TestInviteStatus(t *testing.T) {
+	now := time.Now()
+	accepted := now.Add(-time.Hour)
+	tests := []struct {
+		invite *models.WorkspaceInvite
+		want   string
+	}{
+		{&models.WorkspaceInvite{ExpiresAt: now.Add(time.Hour)}, "pending"},
+		{&models.WorkspaceInvite{ExpiresAt: now}, "expired"},
+		{&models.WorkspaceInvite{ExpiresAt: now.Add(-time.Hour)}, "expired"},
+		{&models.WorkspaceInvite{ExpiresAt: now.Add(-time.Hour), AcceptedAt: &accepted}, "accepted"},
+	}
+	for _, tt := range tests {
+		if got := inviteStatus(tt.invite, now); got != tt.want {
+			t.Errorf("inviteStatus(expires %v, accepted %v) = %q, want %q", tt.invite.ExpiresAt, tt.invite.AcceptedAt, got, tt.want)
+		}
+	}
+}
+
+func TestListInvitesAllSetsEachStatus(t *testing.T) {
+	s, mock := newTestService(t)
+	workspaceID := uuid.New()
+	now := time.Now()
+
+	expectRole(mock, "owner")
+	mock.ExpectQuery(`SELECT \* FROM workspace_invites WHERE workspace_id = \? ORDER BY created_at DESC`).
+		WithArgs(workspaceID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "workspace_id", "email", "expires_at", "accepted_at"}).
+			AddRow(uuid.New().String(), workspaceID.String(), "a@example.com", now.Add(time.Hour), nil).
+			AddRow(uuid.New().String(), workspaceID.String(), "b@example.com", now.Add(-time.Hour), nil).
+			AddRow(uuid.New().String(), workspaceID.String(), "c@example.com", now.Add(-time.Hour), now.Add(-2*time.Hour)))
+
+	invites, err := s.ListInvites(context.Background(), workspaceID, uuid.New(), "all")
+	if err != nil {
+		t.Fatalf("ListInvites: %v", err)
+	}
+	var got []string
+	for _, inv := range invites {
+		got = append(got, inv.Status)
+	}
+	if want := []string{"pending", "expired", "accepted"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("statuses = %v, want %v", got, want)
+	}
+}
+
+func TestListInvitesFiltersByStatus(t *testing.T) {
+	s, mock := newTestService(t)
+	workspaceID := uuid.New()
+
+	expectRole(mock, "owner")
+	mock.ExpectQuery(`SELECT \* FROM workspace_invites WHERE workspace_id = \? AND accepted_at IS NULL AND expires_at <= NOW\(\) ORDER BY`).
+		WithArgs(workspaceID).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	if _, err := s.ListInvites(context.Background(), workspaceID, uuid.New(), "expired"); err != nil {
+		t.Fatalf("ListInvites: %v", err)
+	}
+}
+
+func TestCheckDailyInviteCapAllowsUpToPlanLimit(t *testing.T) {
+	s, mock := newTestService(t)
+	workspaceID := uuid.New()
+
+	expectWorkspace(mock, workspaceID)
+	expectInvitesSent(mock, planFeatures("free").MaxInvitesPerDay-2, time.Now())
+
+	if err := s.checkDailyInviteCap(context.Background(), workspaceID, 2); err != nil {
+		t.Errorf("checkDailyInviteCap: %v", err)
+	}
+}
+
+func TestCheckDailyInviteCapReportsReset(t *testing.T) {
+	s, mock := newTestService(t)
+	workspaceID := uuid.New()
+	limit := planFeatures("free").MaxInvitesPerDay
+	oldest := time.Now().Add(-20 * time.Hour).Truncate(time.Second)
+
+	expectWorkspace(mock, workspaceID)
+	expectInvitesSent(mock, limit-1, oldest)
+
+	err := s.checkDailyInviteCap(context.Background(), workspaceID, 2)
+	var capErr *InviteCapError
+	if !errors.As(err, &capErr) || !errors.Is(err, ErrDailyInviteCapReached) {
+		t.Fatalf("err = %v, want an InviteCapError", err)
+	}
+	if capErr.Used != limit-1 || capErr.Limit != limit {
+		t.Errorf("got %d/%d, want %d/%d", capErr.Used, capErr.Limit, limit-1, limit)
+	}
+	if want := oldest.Add(24 * time.Hour); capErr.ResetsAt == nil || !capErr.ResetsAt.Equal(want) {
+		t.Errorf("resets at %v, want %v", capErr.ResetsAt, want)
+	}
+}
+
+func expectResendableInvite(mock sqlmock.Sqlmock, inviteID, workspaceID uuid.UUID) {
+	mock.ExpectQuery(`SELECT \* FROM workspace_invites WHERE id = \?`).
+		WithArgs(inviteID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "workspace_id", "email", "expires_at"}).
+			AddRow(inviteID.String(), workspaceID.String(), "a@example.com", time.Now().Add(-time.Hour)))
+}
+
+func TestResendInviteWithinCooldownIsRateLimited(t *testing.T) {
+	s, mock := newTestService(t)
+	workspaceID, inviteID := uuid.New(), uuid.New()
+
+	expectRole(mock, "owner")
+	expectResendableInvite(mock, inviteID, workspaceID)
+	expectWorkspace(mock, workspaceID)
+	expectInvitesSent(mock, 0, nil)
+	expectWorkspace(mock, workspaceID)
+	mock.ExpectExec(`UPDATE workspace_invites SET expires_at = \?, last_sent_at = \?`).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), inviteID, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	if _, err := s.ResendInvite(context.Background(), workspaceID, inviteID, uuid.New()); err != ErrRateLimited {
+		t.Errorf("err = %v, want ErrRateLimited", err)
+	}
+}
+
+func TestResendInviteCountsAgainstDailyCap(t *testing.T) {
+	s, mock := newTestService(t)
+	workspaceID, inviteID := uuid.New(), uuid.New()
+
+	expectRole(mock, "owner")
+	expectResendableInvite(mock, inviteID, workspaceID)
+	expectWorkspace(mock, workspaceID)
+	expectInvitesSent(mock, planFeatures("free").MaxInvitesPerDay, time.Now())
+
+	if _, err := s.ResendInvite(context.Background(), workspaceID, inviteID, uuid.New()); !errors.Is(err, ErrDailyInviteCapReached) {
+		t.Errorf("err = %v, want ErrDailyInviteCapReached", err)
+	}
+}
+
+func TestNormalizeInviteEmail(t *testing.T) {
+	tests := []struct {
+		email string
+		gmail bool
+		want  string
+	}{
+		{"  Alice@Example.COM ", false, "alice@example.com"},
+		{"first.last+work@gmail.com", false, "first.last+work@gmail.com"},
+		{"First.Last+work@Gmail.com", true, "firstlast@gmail.com"},
+		{"first.last@googlemail.com", true, "firstlast@gmail.com"},
+		{"first.last+work@example.com", true, "first.last+work@example.com"},
+		{"no-at-sign", true, "no-at-sign"},
+	}
+	for _, tt := range tests {
+		s := &WorkspaceService{canonicalizeGmail: tt.gmail}
+		if got := s.normalizeInviteEmail(tt.email); got != tt.want {
+			t.Errorf("normalizeInviteEmail(%q, gmail=%v) = %q, want %q", tt.email, tt.gmail, got, tt.want)
+		}
+	}
+}
+
+func TestInviteMemberReusesInviteForCaseVariant(t *testing.T) {
+	s, mock := newTestService(t)
+	workspaceID, existingID := uuid.New(), uuid.New()
+
+	expectRole(mock, "owner")
+	mock.ExpectQuery(`SELECT invitee_id FROM workspace_invitation_history`).
+		WithArgs("alice@example.com").
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectQuery(`SELECT \* FROM workspace_invites WHERE workspace_id = \? AND email = \?`).
+		WithArgs(workspaceID, "alice@example.com").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "workspace_id", "email", "expires_at"}).
+			AddRow(existingID.String(), workspaceID.String(), "alice@example.com", time.Now().Add(time.Hour)))
+
+	invite, err := s.InviteMember(context.Background(), workspaceID, uuid.New(), &models.InviteMemberRequest{Email: " Alice@Example.COM", Role: "member"})
+	if err != nil {
+		t.Fatalf("InviteMember: %v", err)
+	}
+	if invite.ID != existingID || invite.Status != "pending" {
+		t.Errorf("got invite %v (%s), want the pending invite %v", invite.ID, invite.Status, existingID)
+	}
+}
+
+func expectResolvedInvitee(mock sqlmock.Sqlmock, email string, inviteeID uuid.UUID) {
+	mock.ExpectQuery(`SELECT invitee_id FROM workspace_invitation_history`).
+		WithArgs(email).
+		WillReturnRows(sqlmock.NewRows([]string{"invitee_id"}).AddRow(inviteeID.String()))
+}
+
+func TestInviteMemberRejectsExistingMember(t *testing.T) {
+	s, mock := newTestService(t)
+	workspaceID, inviteeID := uuid.New(), uuid.New()
+
+	expectRole(mock, "owner")
+	expectResolvedInvitee(mock, "bob@example.com", inviteeID)
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM workspace_members WHERE workspace_id = \? AND user_id = \?`).
+		WithArgs(workspaceID, inviteeID).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+	_, err := s.InviteMember(context.Background(), workspaceID, uuid.New(), &models.InviteMemberRequest{Email: "Bob@example.com", Role: "member"})
+	if err != ErrAlreadyMember {
+		t.Errorf("err = %v, want ErrAlreadyMember", err)
+	}
+}
+
+func TestInviteMemberInvitesFormerMember(t *testing.T) {
+	s, mock := newTestService(t)
+	workspaceID, existingID := uuid.New(), uuid.New()
+
+	expectRole(mock, "owner")
+	expectResolvedInvitee(mock, "bob@example.com", uuid.New())
+	expectMember(mock, false)
+	mock.ExpectQuery(`SELECT \* FROM workspace_invites WHERE workspace_id = \? AND email = \?`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "workspace_id", "email"}).
+			AddRow(existingID.String(), workspaceID.String(), "bob@example.com"))
+
+	invite, err := s.InviteMember(context.Background(), workspaceID, uuid.New(), &models.InviteMemberRequest{Email: "bob@example.com", Role: "member"})
+	if err != nil || invite.ID != existingID {
+		t.Errorf("got %v, %v; want the pending invite", invite, err)
+	}
+}
+
+func TestCodeJoinRole(t *testing.T) {
+	tests := []struct {
+		codeRole string
+		settings models.JSON
+		want     string
+	}{
+		{"admin", models.JSON{settingDefaultMemberRole: "guest"}, "admin"},
+		{"", models.JSON{settingDefaultMemberRole: "guest"}, "guest"},
+		{"", models.JSON{settingDefaultMemberRole: "owner"}, defaultMemberRole},
+		{"", models.JSON{settingDefaultMemberRole: 1}, defaultMemberRole},
+		{"", nil, defaultMemberRole},
+	}
+	for _, tt := range tests {
+		ws := &models.Workspace{Settings: tt.settings}
+		if got := codeJoinRole(ws, &models.WorkspaceInviteCode{Role: tt.codeRole}); got != tt.want {
+			t.Errorf("codeJoinRole(%q, %v) = %q, want %q", tt.codeRole, tt.settings, got, tt.want)
+		}
+	}
+}
+
+func expectInviteLookup(mock sqlmock.Sqlmock, workspaceID uuid.UUID, email string, pendingID *uuid.UUID) {
+	mock.ExpectQuery(`SELECT invitee_id FROM workspace_invitation_history`).
+		WithArgs(email).
+		WillReturnError(sql.ErrNoRows)
+	rows := sqlmock.NewRows([]string{"id", "workspace_id", "email", "expires_at"})
+	if pendingID != nil {
+		rows.AddRow(pendingID.String(), workspaceID.String(), email, time.Now().Add(time.Hour))
+	}
+	mock.ExpectQuery(`SELECT \* FROM workspace_invites WHERE workspace_id = \? AND email = \?`).
+		WithArgs(workspaceID, email).
+		WillReturnRows(rows)
+}
+
+func TestBulkInviteCapCountsOnlyNewEmails(t *testing.T) {
+	s, mock := newTestService(t)
+	workspaceID, pendingID := uuid.New(), uuid.New()
+	limit := planFeatures("free").MaxInvitesPerDay
+
+	// One slot is left today: the pending address is only reused, so the
+	// batch fits.
+	expectRole(mock, "owner")
+	expectInviteLookup(mock, workspaceID, "pending@example.com", &pendingID)
+	expectInviteLookup(mock, workspaceID, "new@example.com", nil)
+	expectWorkspace(mock, workspaceID)
+	expectInvitesSent(mock, limit-1, time.Now())
+
+	expectRole(mock, "owner")
+	expectInviteLookup(mock, workspaceID, "pending@example.com", &pendingID)
+
+	expectRole(mock, "owner")
+	expectInviteLookup(mock, workspaceID, "new@example.com", nil)
+	expectWorkspace(mock, workspaceID)
+	expectInvitesSent(mock, limit-1, time.Now())
+	expectWorkspace(mock, workspaceID)
+	mock.ExpectExec(`INSERT INTO workspace_invites`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO workspace_invitation_history`).WillReturnResult(sqlmock.NewResult(0, 1))
+
+	resp, err := s.BulkInvite(context.Background(), workspaceID, uuid.New(), &models.BulkInviteRequest{
+		Invites: []models.InviteMemberRequest{
+			{Email: "Pending@example.com", Role: "member"},
+			{Email: "new@example.com", Role: "member"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("BulkInvite: %v", err)
+	}
+	if len(resp.Successful) != 2 || len(resp.Failed) != 0 {
+		t.Errorf("got %d sent and %d failed, want both sent", len(resp.Successful), len(resp.Failed))
+	}
+}
+
+func TestBulkInviteRejectsBatchOverCap(t *testing.T) {
+	s, mock := newTestService(t)
+	workspaceID := uuid.New()
+	limit := planFeatures("free").MaxInvitesPerDay
+
+	expectRole(mock, "owner")
+	expectInviteLookup(mock, workspaceID, "a@example.com", nil)
+	expectInviteLookup(mock, workspaceID, "b@example.com", nil)
+	expectWorkspace(mock, workspaceID)
+	expectInvitesSent(mock, limit-1, time.Now())
+
+	_, err := s.BulkInvite(context.Background(), workspaceID, uuid.New(), &models.BulkInviteRequest{
+		Invites: []models.InviteMemberRequest{
+			{Email: "a@example.com", Role: "member"},
+			{Email: "b@example.com", Role: "member"},
+		},
+	})
+	if !errors.Is(err, ErrDailyInviteCapReached) {
+		t.Errorf("err = %v, want ErrDailyInviteCapReached", err)
+	}
+}
@@ -0,0 +1,182 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/quckapp/workspace-service/internal/models"
+)
+
+// requestToJoin opens a join request instead of a membership for a code
+// that requires approval. The request takes one use of the code, given back
+// if it is rejected. Owners and admins learn of it from the
+// member.join_requested event.
+func (s *WorkspaceService) requestToJoin(ctx context.Context, inviteCode *models.WorkspaceInviteCode, userID uuid.UUID) (*models.WorkspaceJoinRequest, error) {
+	existing, err := s.joinRequestRepo.GetByUser(ctx, inviteCode.WorkspaceID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil && existing.Status == models.JoinRequestPending {
+		return nil, ErrJoinRequestPending
+	}
+
+	claimed, err := s.inviteCodeRepo.ClaimUse(ctx, inviteCode.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !claimed {
+		return nil, ErrInviteCodeMaxUsed
+	}
+
+	now := time.Now()
+	request := &models.WorkspaceJoinRequest{
+		ID:           uuid.New(),
+		WorkspaceID:  inviteCode.WorkspaceID,
+		UserID:       userID,
+		InviteCodeID: inviteCode.ID,
+		Role:         inviteCode.Role,
+		Status:       models.JoinRequestPending,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	if existing != nil {
+		request.ID = existing.ID
+		var reopened bool
+		reopened, err = s.joinRequestRepo.Reopen(ctx, request)
+		if err == nil && !reopened {
+			err = ErrJoinRequestPending
+		}
+	} else {
+		err = s.joinRequestRepo.Create(ctx, request)
+	}
+	if err != nil {
+		s.inviteCodeRepo.ReleaseUse(ctx, inviteCode.ID)
+		return nil, err
+	}
+
+	s.LogActivity(ctx, inviteCode.WorkspaceID, userID, "member.join_requested", "join_request", request.ID.String(), models.JSON{"role": request.Role})
+	s.publishEvent(ctx, "workspace-events", inviteCode.WorkspaceID.String(), "member.join_requested", map[string]interface{}{
+		"workspace_id":    inviteCode.WorkspaceID,
+		"user_id":         userID,
+		"join_request_id": request.ID,
+		"role":            request.Role,
+	})
+	return request, nil
+}
+
+// ListJoinRequests lists a workspace's join requests with the given status,
+// or all of them when status is empty.
+func (s *WorkspaceService) ListJoinRequests(ctx context.Context, workspaceID, userID uuid.UUID, status string, page, perPage int) ([]*models.WorkspaceJoinRequest, int64, error) {
+	role, _ := s.memberRepo.GetRole(ctx, workspaceID, userID)
+	if role != "owner" && role != "admin" {
+		return nil, 0, ErrNotAuthorized
+	}
+	if page < 1 {
+		page = 1
+	}
+	if perPage < 1 || perPage > 100 {
+		perPage = 20
+	}
+	return s.joinRequestRepo.ListByWorkspace(ctx, workspaceID, status, page, perPage)
+}
+
+// pendingJoinRequest loads a request of workspaceID that is still awaiting
+// a decision.
+func (s *WorkspaceService) pendingJoinRequest(ctx context.Context, workspaceID, requestID uuid.UUID) (*models.WorkspaceJoinRequest, error) {
+	request, err := s.joinRequestRepo.GetByID(ctx, requestID)
+	if err != nil {
+		return nil, err
+	}
+	if request == nil || request.WorkspaceID != workspaceID || request.Status != models.JoinRequestPending {
+		return nil, ErrJoinRequestNotFound
+	}
+	return request, nil
+}
+
+// ApproveJoinRequest adds the requester as a member with the role the code
+// granted.
+func (s *WorkspaceService) ApproveJoinRequest(ctx context.Context, workspaceID, requestID, reviewerID uuid.UUID) (*models.WorkspaceMember, error) {
+	role, _ := s.memberRepo.GetRole(ctx, workspaceID, reviewerID)
+	if role != "owner" && role != "admin" {
+		return nil, ErrNotAuthorized
+	}
+
+	request, err := s.pendingJoinRequest(ctx, workspaceID, requestID)
+	if err != nil {
+		return nil, err
+	}
+	if isBanned, _ := s.moderationRepo.IsUserBanned(ctx, workspaceID, request.UserID); isBanned {
+		return nil, ErrUserBanned
+	}
+	if isMember, _ := s.memberRepo.IsMember(ctx, workspaceID, request.UserID); isMember {
+		return nil, ErrAlreadyMember
+	}
+
+	resolved, err := s.joinRequestRepo.Resolve(ctx, request.ID, models.JoinRequestApproved, reviewerID)
+	if err != nil {
+		return nil, err
+	}
+	if !resolved {
+		return nil, ErrJoinRequestNotFound
+	}
+
+	now := time.Now()
+	member := &models.WorkspaceMember{
+		ID:          uuid.New(),
+		WorkspaceID: workspaceID,
+		UserID:      request.UserID,
+		Role:        request.Role,
+		JoinedAt:    now,
+		InvitedBy:   &reviewerID,
+		IsActive:    true,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	err = s.createMemberWithEvent(ctx, member, "workspace-events", workspaceID.String(), "member.joined", map[string]interface{}{
+		"workspace_id":    workspaceID,
+		"user_id":         request.UserID,
+		"role":            request.Role,
+		"join_request_id": request.ID,
+	})
+	if err != nil {
+		s.joinRequestRepo.Unresolve(ctx, request.ID)
+		return nil, err
+	}
+
+	s.invalidateWorkspace(ctx, workspaceID)
+	s.invalidateUserWorkspaces(ctx, request.UserID)
+	s.LogActivity(ctx, workspaceID, reviewerID, "member.join_approved", "join_request", request.ID.String(), models.JSON{"user_id": request.UserID.String(), "role": request.Role})
+	s.LogActivity(ctx, workspaceID, request.UserID, "member.joined", "member", request.UserID.String(), models.JSON{"method": "join_request", "role": request.Role})
+	return member, nil
+}
+
+// RejectJoinRequest declines a pending request and gives its use of the
+// invite code back.
+func (s *WorkspaceService) RejectJoinRequest(ctx context.Context, workspaceID, requestID, reviewerID uuid.UUID) error {
+	role, _ := s.memberRepo.GetRole(ctx, workspaceID, reviewerID)
+	if role != "owner" && role != "admin" {
+		return ErrNotAuthorized
+	}
+
+	request, err := s.pendingJoinRequest(ctx, workspaceID, requestID)
+	if err != nil {
+		return err
+	}
+	resolved, err := s.joinRequestRepo.Resolve(ctx, request.ID, models.JoinRequestRejected, reviewerID)
+	if err != nil {
+		return err
+	}
+	if !resolved {
+		return ErrJoinRequestNotFound
+	}
+	s.inviteCodeRepo.ReleaseUse(ctx, request.InviteCodeID)
+
+	s.LogActivity(ctx, workspaceID, reviewerID, "member.join_rejected", "join_request", request.ID.String(), models.JSON{"user_id": request.UserID.String()})
+	s.publishEvent(ctx, "workspace-events", workspaceID.String(), "member.join_rejected", map[string]interface{}{
+		"workspace_id":    workspaceID,
+		"user_id":         request.UserID,
+		"join_request_id": request.ID,
+	})
+	return nil
+}
@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// errKVUnavailable is returned by operations whose result callers must not
+// mistake for a real answer when Redis is not configured.
+var errKVUnavailable = errors.New("kv store unavailable")
+
+// errKVMiss is returned by reads when the key is absent, expired, or the
+// store is unavailable.
+var errKVMiss = errors.New("kv miss")
+
+// kvStore is the shared, cross-replica state the services keep in Redis:
+// caches and short-lived claims. Redis is optional, so services hold a
+// kvStore rather than a *redis.Client and never check for nil themselves.
+// Without Redis, caches behave as permanently empty and writes are dropped;
+// SetNX reports errKVUnavailable so each caller decides how to degrade.
+type kvStore interface {
+	Available() bool
+	Get(ctx context.Context, key string) ([]byte, error)
+	Set(ctx context.Context, key string, value interface{}, ttl time.Duration)
+	Exists(ctx context.Context, key string) bool
+	Del(ctx context.Context, keys ...string)
+	HGet(ctx context.Context, key, field string) ([]byte, error)
+	// HSet sets one hash field and (re)arms the expiry of the whole hash.
+	HSet(ctx context.Context, key, field string, value interface{}, ttl time.Duration)
+	HDel(ctx context.Context, key string, fields ...string)
+	SetNX(ctx context.Context, key string, value interface{}, ttl time.Duration) (bool, error)
+	// DelIfEqual deletes key only if it still holds value, so a lock holder
+	// never releases a lock that expired and was taken by someone else.
+	DelIfEqual(ctx context.Context, key, value string) error
+}
+
+// newKVStore wraps client, or returns the no-op store when client is nil.
+func newKVStore(client *redis.Client) kvStore {
+	if client == nil {
+		return noopKVStore{}
+	}
+	return &redisKVStore{client: client}
+}
+
+type redisKVStore struct {
+	client *redis.Client
+}
+
+func (r *redisKVStore) Available() bool { return true }
+
+func (r *redisKVStore) Get(ctx context.Context, key string) ([]byte, error) {
+	data, err := r.client.Get(ctx, key).Bytes()
+	if err == redis.Nil {
+		return nil, errKVMiss
+	}
+	return data, err
+}
+
+func (r *redisKVStore) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) {
+	r.client.Set(ctx, key, value, ttl)
+}
+
+func (r *redisKVStore) Exists(ctx context.Context, key string) bool {
+	n, err := r.client.Exists(ctx, key).Result()
+	return err == nil && n > 0
+}
+
+func (r *redisKVStore) Del(ctx context.Context, keys ...string) {
+	r.client.Del(ctx, keys...)
+}
+
+func (r *redisKVStore) HGet(ctx context.Context, key, field string) ([]byte, error) {
+	data, err := r.client.HGet(ctx, key, field).Bytes()
+	if err == redis.Nil {
+		return nil, errKVMiss
+	}
+	return data, err
+}
+
+func (r *redisKVStore) HSet(ctx context.Context, key, field string, value interface{}, ttl time.Duration) {
+	r.client.HSet(ctx, key, field, value)
+	r.client.Expire(ctx, key, ttl)
+}
+
+func (r *redisKVStore) HDel(ctx context.Context, key string, fields ...string) {
+	r.client.HDel(ctx, key, fields...)
+}
+
+func (r *redisKVStore) SetNX(ctx context.Context, key string, value interface{}, ttl time.Duration) (bool, error) {
+	return r.client.SetNX(ctx, key, value, ttl).Result()
+}
+
+var delIfEqualScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+
+func (r *redisKVStore) DelIfEqual(ctx context.Context, key, value string) error {
+	return delIfEqualScript.Run(ctx, r.client, []string{key}, value).Err()
+}
+
+// noopKVStore stands in when Redis is not configured. Nothing is kept
+// in-process: another replica could not see or invalidate it.
+type noopKVStore struct{}
+
+func (noopKVStore) Available() bool { return false }
+
+func (noopKVStore) Get(context.Context, string) ([]byte, error) { return nil, errKVMiss }
+
+func (noopKVStore) Set(context.Context, string, interface{}, time.Duration) {}
+
+func (noopKVStore) Exists(context.Context, string) bool { return false }
+
+func (noopKVStore) Del(context.Context, ...string) {}
+
+func (noopKVStore) HGet(context.Context, string, string) ([]byte, error) { return nil, errKVMiss }
+
+func (noopKVStore) HSet(context.Context, string, string, interface{}, time.Duration) {}
+
+func (noopKVStore) HDel(context.Context, string, ...string) {}
+
+func (noopKVStore) SetNX(context.Context, string, interface{}, time.Duration) (bool, error) {
+	return false, errKVUnavailable
+}
+
+func (noopKVStore) DelIfEqual(context.Context, string, string) error { return errKVUnavailable }
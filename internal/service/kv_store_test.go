@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+)
+
+func TestKVStoreWithoutRedisIsEmpty(t *testing.T) {
+	ctx := context.Background()
+	kv := newKVStore(nil)
+
+	if kv.Available() {
+		t.Error("store without Redis reports available")
+	}
+	kv.Set(ctx, "k", "v", time.Minute)
+	if _, err := kv.Get(ctx, "k"); err != errKVMiss {
+		t.Errorf("Get after Set: err = %v, want errKVMiss", err)
+	}
+	if kv.Exists(ctx, "k") {
+		t.Error("Exists after Set = true, want false")
+	}
+	kv.HSet(ctx, "h", "f", "v", time.Minute)
+	if _, err := kv.HGet(ctx, "h", "f"); err != errKVMiss {
+		t.Errorf("HGet after HSet: err = %v, want errKVMiss", err)
+	}
+	if ok, err := kv.SetNX(ctx, "k", "v", time.Minute); ok || err != errKVUnavailable {
+		t.Errorf("SetNX = (%v, %v), want (false, errKVUnavailable)", ok, err)
+	}
+	if err := kv.DelIfEqual(ctx, "k", "v"); err != errKVUnavailable {
+		t.Errorf("DelIfEqual: err = %v, want errKVUnavailable", err)
+	}
+}
+
+func expectTouchLastSeen(mock sqlmock.Sqlmock, workspaceID, userID uuid.UUID) {
+	mock.ExpectExec(`INSERT INTO workspace_member_profiles .* ON DUPLICATE KEY UPDATE last_seen_at`).
+		WithArgs(sqlmock.AnyArg(), workspaceID, userID, sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+}
+
+func TestTouchMemberSeenWithoutRedisWritesEveryTime(t *testing.T) {
+	s, mock := newTestService(t)
+	workspaceID, userID := uuid.New(), uuid.New()
+
+	expectTouchLastSeen(mock, workspaceID, userID)
+	expectTouchLastSeen(mock, workspaceID, userID)
+
+	for i := 0; i < 2; i++ {
+		if err := s.TouchMemberSeen(context.Background(), workspaceID, userID); err != nil {
+			t.Fatalf("TouchMemberSeen: %v", err)
+		}
+	}
+}
+
+func TestTouchMemberSeenDebouncesWithRedis(t *testing.T) {
+	s, mock := newTestService(t)
+	s.kv = newMemKVStore()
+	workspaceID, userID := uuid.New(), uuid.New()
+
+	expectTouchLastSeen(mock, workspaceID, userID)
+
+	for i := 0; i < 3; i++ {
+		if err := s.TouchMemberSeen(context.Background(), workspaceID, userID); err != nil {
+			t.Fatalf("TouchMemberSeen: %v", err)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+)
+
+func expectCustomField(mock sqlmock.Sqlmock, fieldID, workspaceID uuid.UUID, name string) {
+	mock.ExpectQuery(`SELECT \* FROM workspace_custom_fields WHERE id = \?`).
+		WithArgs(fieldID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "workspace_id", "name", "field_type"}).
+			AddRow(fieldID.String(), workspaceID.String(), name, "select"))
+}
+
+func TestGetMemberFacetsCountsEachValue(t *testing.T) {
+	s, mock := newTestService(t)
+	workspaceID, userID, fieldID := uuid.New(), uuid.New(), uuid.New()
+
+	expectRole(mock, "member")
+	expectCustomField(mock, fieldID, workspaceID, "Team")
+	mock.ExpectQuery(`SELECT v.value, COUNT\(\*\) as count FROM workspace_custom_field_values`).
+		WithArgs(fieldID, workspaceID).
+		WillReturnRows(sqlmock.NewRows([]string{"value", "count"}).
+			AddRow("Engineering", 5).
+			AddRow("Design", 2))
+
+	resp, err := s.GetMemberFacets(context.Background(), workspaceID, userID, fieldID)
+	if err != nil {
+		t.Fatalf("GetMemberFacets: %v", err)
+	}
+	if resp.FieldName != "Team" || len(resp.Facets) != 2 || resp.Total != 7 {
+		t.Fatalf("got %q with %d facets totalling %d, want Team with 2 totalling 7", resp.FieldName, len(resp.Facets), resp.Total)
+	}
+	if resp.Facets[0].Value != "Engineering" || resp.Facets[0].Count != 5 {
+		t.Errorf("first facet = %+v, want Engineering/5", resp.Facets[0])
+	}
+}
+
+func TestGetMemberFacetsEmptyFieldReturnsEmptyList(t *testing.T) {
+	s, mock := newTestService(t)
+	workspaceID, userID, fieldID := uuid.New(), uuid.New(), uuid.New()
+
+	expectRole(mock, "member")
+	expectCustomField(mock, fieldID, workspaceID, "Team")
+	mock.ExpectQuery(`FROM workspace_custom_field_values`).
+		WillReturnRows(sqlmock.NewRows([]string{"value", "count"}))
+
+	resp, err := s.GetMemberFacets(context.Background(), workspaceID, userID, fieldID)
+	if err != nil {
+		t.Fatalf("GetMemberFacets: %v", err)
+	}
+	if resp.Facets == nil || len(resp.Facets) != 0 || resp.Total != 0 {
+		t.Errorf("got %+v, want an empty, non-nil facet list", resp)
+	}
+}
+
+func TestGetMemberFacetsRejectsFieldFromAnotherWorkspace(t *testing.T) {
+	s, mock := newTestService(t)
+	workspaceID, userID, fieldID := uuid.New(), uuid.New(), uuid.New()
+
+	expectRole(mock, "member")
+	expectCustomField(mock, fieldID, uuid.New(), "Team")
+
+	if _, err := s.GetMemberFacets(context.Background(), workspaceID, userID, fieldID); err != ErrCustomFieldNotFound {
+		t.Errorf("err = %v, want ErrCustomFieldNotFound", err)
+	}
+}
+
+func TestGetMemberFacetsRequiresMembership(t *testing.T) {
+	s, mock := newTestService(t)
+	expectRole(mock, "")
+
+	if _, err := s.GetMemberFacets(context.Background(), uuid.New(), uuid.New(), uuid.New()); err != ErrNotMember {
+		t.Errorf("err = %v, want ErrNotMember", err)
+	}
+}
@@ -0,0 +1,250 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/quckapp/workspace-service/internal/models"
+)
+
+func expectOwnerCount(mock sqlmock.Sqlmock, workspaceID uuid.UUID, n int) {
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM workspace_members WHERE workspace_id = \? AND role = \?`).
+		WithArgs(workspaceID, "owner").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(n))
+}
+
+func TestUpdateMemberRoleKeepsLastOwner(t *testing.T) {
+	s, mock := newTestService(t)
+	workspaceID, userID := uuid.New(), uuid.New()
+
+	expectRole(mock, "owner")
+	expectRole(mock, "owner")
+	expectOwnerCount(mock, workspaceID, 1)
+
+	if err := s.UpdateMemberRole(context.Background(), workspaceID, userID, userID, "admin"); err != ErrLastOwner {
+		t.Errorf("err = %v, want ErrLastOwner", err)
+	}
+}
+
+func TestUpdateMemberRoleDemotesOneOfSeveralOwners(t *testing.T) {
+	s, mock := newTestService(t)
+	workspaceID, requestorID, memberID := uuid.New(), uuid.New(), uuid.New()
+
+	expectRole(mock, "owner")
+	expectRole(mock, "owner")
+	expectOwnerCount(mock, workspaceID, 2)
+	mock.ExpectExec(`UPDATE workspace_members SET role = \?`).
+		WithArgs("admin", sqlmock.AnyArg(), workspaceID, memberID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	if err := s.UpdateMemberRole(context.Background(), workspaceID, memberID, requestorID, "admin"); err != nil {
+		t.Errorf("UpdateMemberRole: %v", err)
+	}
+}
+
+func TestRemoveMemberKeepsLastOwner(t *testing.T) {
+	s, mock := newTestService(t)
+	workspaceID := uuid.New()
+
+	expectRole(mock, "owner")
+	expectRole(mock, "owner")
+	expectOwnerCount(mock, workspaceID, 1)
+
+	if err := s.RemoveMember(context.Background(), workspaceID, uuid.New(), uuid.New()); err != ErrLastOwner {
+		t.Errorf("err = %v, want ErrLastOwner", err)
+	}
+}
+
+func TestLeaveWorkspaceKeepsLastOwner(t *testing.T) {
+	s, mock := newTestService(t)
+	workspaceID := uuid.New()
+
+	expectWorkspace(mock, workspaceID)
+	expectMember(mock, true)
+	expectRole(mock, "owner")
+	expectOwnerCount(mock, workspaceID, 1)
+
+	if err := s.LeaveWorkspace(context.Background(), workspaceID, uuid.New()); err != ErrLastOwner {
+		t.Errorf("err = %v, want ErrLastOwner", err)
+	}
+}
+
+func TestSuggestInactiveMembersSkipsOwnersAndRecentActors(t *testing.T) {
+	s, mock := newTestService(t)
+	workspaceID := uuid.New()
+	owner, actor, idle := uuid.New(), uuid.New(), uuid.New()
+
+	expectRole(mock, "owner")
+	mock.ExpectQuery(`SELECT m.\*, p.last_seen_at FROM workspace_members m`).
+		WithArgs(workspaceID, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "workspace_id", "user_id", "role"}).
+			AddRow(uuid.New().String(), workspaceID.String(), owner.String(), "owner").
+			AddRow(uuid.New().String(), workspaceID.String(), actor.String(), "member").
+			AddRow(uuid.New().String(), workspaceID.String(), idle.String(), "member"))
+	mock.ExpectQuery(`SELECT DISTINCT actor_id FROM workspace_activity_log`).
+		WillReturnRows(sqlmock.NewRows([]string{"actor_id"}).AddRow(actor.String()))
+
+	suggestions, err := s.SuggestInactiveMembers(context.Background(), workspaceID, uuid.New(), 30)
+	if err != nil {
+		t.Fatalf("SuggestInactiveMembers: %v", err)
+	}
+	if len(suggestions) != 1 || suggestions[0].UserID != idle {
+		t.Errorf("got %d suggestions, want only the idle member", len(suggestions))
+	}
+}
+
+func TestBulkRemoveMembersReportsEachFailure(t *testing.T) {
+	s, mock := newTestService(t)
+	workspaceID, requestorID := uuid.New(), uuid.New()
+	owner, member := uuid.New(), uuid.New()
+
+	expectRole(mock, "owner")
+	// The co-owner is refused.
+	expectRole(mock, "owner")
+	expectRole(mock, "owner")
+	expectOwnerCount(mock, workspaceID, 2)
+	// The member is removed.
+	expectRole(mock, "owner")
+	expectRole(mock, "member")
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE workspace_members SET is_active = FALSE`).
+		WithArgs(sqlmock.AnyArg(), workspaceID, member).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`DELETE FROM workspace_member_roles`).
+		WithArgs(workspaceID, member).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+
+	req := &models.BulkRemoveMembersRequest{UserIDs: []string{"not-a-uuid", requestorID.String(), owner.String(), member.String()}}
+	resp, err := s.BulkRemoveMembers(context.Background(), workspaceID, requestorID, req)
+	if err != nil {
+		t.Fatalf("BulkRemoveMembers: %v", err)
+	}
+	if len(resp.Removed) != 1 || resp.Removed[0] != member.String() {
+		t.Errorf("removed = %v, want only %s", resp.Removed, member)
+	}
+	reasons := map[string]string{}
+	for _, f := range resp.Failed {
+		reasons[f.UserID] = f.Reason
+	}
+	want := map[string]string{
+		"not-a-uuid":         "invalid user ID",
+		requestorID.String(): "cannot remove yourself",
+		owner.String():       ErrNotAuthorized.Error(),
+	}
+	if !reflect.DeepEqual(reasons, want) {
+		t.Errorf("failures = %v, want %v", reasons, want)
+	}
+}
+
+func cooldownWorkspace(hours interface{}) *models.Workspace {
+	return &models.Workspace{ID: uuid.New(), Settings: models.JSON{settingRejoinCooldownHours: hours}}
+}
+
+func expectDepartedAt(mock sqlmock.Sqlmock, workspaceID, userID uuid.UUID, leftAt time.Time) {
+	mock.ExpectQuery(`SELECT left_at FROM workspace_departures WHERE workspace_id = \? AND user_id = \?`).
+		WithArgs(workspaceID, userID).
+		WillReturnRows(sqlmock.NewRows([]string{"left_at"}).AddRow(leftAt))
+}
+
+func TestCheckRejoinCooldownRefusesRecentLeaver(t *testing.T) {
+	s, mock := newTestService(t)
+	workspace, userID := cooldownWorkspace(float64(24)), uuid.New()
+	leftAt := time.Now().Add(-time.Hour)
+
+	expectDepartedAt(mock, workspace.ID, userID, leftAt)
+
+	err := s.checkRejoinCooldown(context.Background(), workspace, userID, nil)
+	var cooldownErr *RejoinCooldownError
+	if !errors.As(err, &cooldownErr) || !errors.Is(err, ErrRejoinCooldown) {
+		t.Fatalf("err = %v, want a RejoinCooldownError", err)
+	}
+	if want := leftAt.Add(24 * time.Hour); !cooldownErr.Until.Equal(want) {
+		t.Errorf("until = %v, want %v", cooldownErr.Until, want)
+	}
+}
+
+func TestCheckRejoinCooldownAllowsAfterCooldown(t *testing.T) {
+	s, mock := newTestService(t)
+	workspace, userID := cooldownWorkspace(float64(24)), uuid.New()
+
+	expectDepartedAt(mock, workspace.ID, userID, time.Now().Add(-25*time.Hour))
+
+	if err := s.checkRejoinCooldown(context.Background(), workspace, userID, nil); err != nil {
+		t.Errorf("err = %v, want nil", err)
+	}
+}
+
+func TestCheckRejoinCooldownWaivedByLaterInvite(t *testing.T) {
+	for _, role := range []string{"owner", "admin"} {
+		s, mock := newTestService(t)
+		workspace, userID := cooldownWorkspace(float64(24)), uuid.New()
+		leftAt := time.Now().Add(-time.Hour)
+		invite := &models.WorkspaceInvite{InvitedBy: uuid.New(), CreatedAt: leftAt.Add(time.Minute)}
+
+		expectDepartedAt(mock, workspace.ID, userID, leftAt)
+		mock.ExpectQuery(`SELECT role FROM workspace_members`).
+			WithArgs(workspace.ID, invite.InvitedBy).
+			WillReturnRows(sqlmock.NewRows([]string{"role"}).AddRow(role))
+
+		if err := s.checkRejoinCooldown(context.Background(), workspace, userID, invite); err != nil {
+			t.Errorf("%s invite: err = %v, want the invite to waive the cooldown", role, err)
+		}
+	}
+}
+
+func TestCheckRejoinCooldownNotWaivedByMemberInvite(t *testing.T) {
+	for _, role := range []string{"member", "moderator", ""} {
+		s, mock := newTestService(t)
+		workspace, userID := cooldownWorkspace(float64(24)), uuid.New()
+		leftAt := time.Now().Add(-time.Hour)
+
+		expectDepartedAt(mock, workspace.ID, userID, leftAt)
+		expectRole(mock, role)
+
+		invite := &models.WorkspaceInvite{InvitedBy: uuid.New(), CreatedAt: leftAt.Add(time.Minute)}
+		if err := s.checkRejoinCooldown(context.Background(), workspace, userID, invite); !errors.Is(err, ErrRejoinCooldown) {
+			t.Errorf("invite from %q: err = %v, want ErrRejoinCooldown", role, err)
+		}
+	}
+}
+
+func TestCheckRejoinCooldownIgnoresInviteFromBeforeLeaving(t *testing.T) {
+	s, mock := newTestService(t)
+	workspace, userID := cooldownWorkspace(float64(24)), uuid.New()
+	leftAt := time.Now().Add(-time.Hour)
+
+	expectDepartedAt(mock, workspace.ID, userID, leftAt)
+
+	invite := &models.WorkspaceInvite{InvitedBy: uuid.New(), CreatedAt: leftAt.Add(-time.Minute)}
+	if err := s.checkRejoinCooldown(context.Background(), workspace, userID, invite); !errors.Is(err, ErrRejoinCooldown) {
+		t.Errorf("err = %v, want ErrRejoinCooldown", err)
+	}
+}
+
+func TestCheckRejoinCooldownNeverLeft(t *testing.T) {
+	s, mock := newTestService(t)
+	workspace, userID := cooldownWorkspace(float64(24)), uuid.New()
+
+	mock.ExpectQuery(`SELECT left_at FROM workspace_departures`).
+		WillReturnRows(sqlmock.NewRows([]string{"left_at"}))
+
+	if err := s.checkRejoinCooldown(context.Background(), workspace, userID, nil); err != nil {
+		t.Errorf("err = %v, want nil", err)
+	}
+}
+
+func TestCheckRejoinCooldownDisabledSkipsLookup(t *testing.T) {
+	s, _ := newTestService(t)
+
+	for _, workspace := range []*models.Workspace{{ID: uuid.New()}, cooldownWorkspace(float64(0))} {
+		if err := s.checkRejoinCooldown(context.Background(), workspace, uuid.New(), nil); err != nil {
+			t.Errorf("err = %v, want nil", err)
+		}
+	}
+}
@@ -0,0 +1,228 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/quckapp/workspace-service/internal/models"
+)
+
+type testStep struct {
+	id       uuid.UUID
+	required bool
+}
+
+func newTestSteps(required ...bool) []testStep {
+	steps := make([]testStep, len(required))
+	for i, r := range required {
+		steps[i] = testStep{id: uuid.New(), required: r}
+	}
+	return steps
+}
+
+func expectSteps(mock sqlmock.Sqlmock, checklistID uuid.UUID, steps []testStep) {
+	rows := sqlmock.NewRows([]string{"id", "checklist_id", "title", "position", "is_required"})
+	for i, step := range steps {
+		rows.AddRow(step.id.String(), checklistID.String(), "Step", i+1, step.required)
+	}
+	mock.ExpectQuery(`SELECT \* FROM onboarding_steps WHERE checklist_id = \?`).
+		WithArgs(checklistID).
+		WillReturnRows(rows)
+}
+
+func expectProgress(mock sqlmock.Sqlmock, userID uuid.UUID, completed ...testStep) {
+	rows := sqlmock.NewRows([]string{"id", "step_id", "user_id"})
+	for _, step := range completed {
+		rows.AddRow(uuid.New().String(), step.id.String(), userID.String())
+	}
+	mock.ExpectQuery(`FROM onboarding_progress p`).WillReturnRows(rows)
+}
+
+func TestOnboardingChecklistStatusPercentage(t *testing.T) {
+	s, mock := newTestService(t)
+	userID := uuid.New()
+	checklist := &models.OnboardingChecklist{ID: uuid.New(), IsActive: true}
+	steps := newTestSteps(true, false, false, false)
+
+	expectSteps(mock, checklist.ID, steps)
+	expectProgress(mock, userID, steps[0])
+
+	status, err := s.onboardingChecklistStatus(context.Background(), checklist, userID)
+	if err != nil {
+		t.Fatalf("onboardingChecklistStatus: %v", err)
+	}
+	if status.CompletedCount != 1 || status.TotalSteps != 4 || status.Percentage != 25 || status.IsComplete {
+		t.Errorf("got %d/%d (%v%%, complete=%v), want 1/4 (25%%, incomplete)",
+			status.CompletedCount, status.TotalSteps, status.Percentage, status.IsComplete)
+	}
+}
+
+func TestOnboardingChecklistStatusRequiredOnly(t *testing.T) {
+	s, mock := newTestService(t)
+	userID := uuid.New()
+	checklist := &models.OnboardingChecklist{ID: uuid.New(), IsActive: true, RequiredOnly: true}
+	steps := newTestSteps(true, false, true)
+
+	expectSteps(mock, checklist.ID, steps)
+	expectProgress(mock, userID, steps[0], steps[2])
+
+	status, err := s.onboardingChecklistStatus(context.Background(), checklist, userID)
+	if err != nil {
+		t.Fatalf("onboardingChecklistStatus: %v", err)
+	}
+	if status.TotalSteps != 2 || status.Percentage != 100 || !status.IsComplete {
+		t.Errorf("got %d/%d (%v%%), want the optional step ignored and the checklist complete",
+			status.CompletedCount, status.TotalSteps, status.Percentage)
+	}
+	if len(status.Steps) != 3 || status.Steps[1].Completed {
+		t.Errorf("steps = %+v, want all three listed with the optional one open", status.Steps)
+	}
+}
+
+func TestOnboardingChecklistStatusRequiredOnlyWithoutRequiredSteps(t *testing.T) {
+	s, mock := newTestService(t)
+	userID := uuid.New()
+	checklist := &models.OnboardingChecklist{ID: uuid.New(), RequiredOnly: true}
+	steps := newTestSteps(false, false)
+
+	expectSteps(mock, checklist.ID, steps)
+	expectProgress(mock, userID, steps[0])
+
+	status, err := s.onboardingChecklistStatus(context.Background(), checklist, userID)
+	if err != nil {
+		t.Fatalf("onboardingChecklistStatus: %v", err)
+	}
+	if status.TotalSteps != 2 || status.Percentage != 50 {
+		t.Errorf("got %d/%d (%v%%), want every step to count", status.CompletedCount, status.TotalSteps, status.Percentage)
+	}
+}
+
+func TestOnboardingChecklistStatusEmptyChecklistIsComplete(t *testing.T) {
+	s, mock := newTestService(t)
+	checklist := &models.OnboardingChecklist{ID: uuid.New()}
+
+	expectSteps(mock, checklist.ID, nil)
+	expectProgress(mock, uuid.New())
+
+	status, err := s.onboardingChecklistStatus(context.Background(), checklist, uuid.New())
+	if err != nil {
+		t.Fatalf("onboardingChecklistStatus: %v", err)
+	}
+	if status.Percentage != 100 || !status.IsComplete {
+		t.Errorf("got %v%% complete=%v, want 100%% and complete", status.Percentage, status.IsComplete)
+	}
+}
+
+func TestPercentage(t *testing.T) {
+	if got := percentage(1, 4); got != 25 {
+		t.Errorf("percentage(1, 4) = %v, want 25", got)
+	}
+	if got := percentage(3, 0); got != 0 {
+		t.Errorf("percentage(3, 0) = %v, want 0", got)
+	}
+}
+
+func expectChecklist(mock sqlmock.Sqlmock, checklistID, workspaceID uuid.UUID) {
+	mock.ExpectQuery(`SELECT \* FROM onboarding_checklists WHERE id = \?`).
+		WithArgs(checklistID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "workspace_id", "title", "is_active"}).
+			AddRow(checklistID.String(), workspaceID.String(), "Welcome", true))
+}
+
+func TestReorderOnboardingStepsKeepsUnlistedAtEnd(t *testing.T) {
+	s, mock := newTestService(t)
+	workspaceID, userID, checklistID := uuid.New(), uuid.New(), uuid.New()
+	steps := newTestSteps(false, false, false)
+
+	expectRole(mock, "owner")
+	expectChecklist(mock, checklistID, workspaceID)
+	expectSteps(mock, checklistID, steps)
+	mock.ExpectBegin()
+	for i, step := range []testStep{steps[2], steps[0], steps[1]} {
+		mock.ExpectExec(`UPDATE onboarding_steps SET position = \?`).
+			WithArgs(i+1, step.id, checklistID).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+	mock.ExpectCommit()
+	expectSteps(mock, checklistID, []testStep{steps[2], steps[0], steps[1]})
+
+	req := &models.ReorderStepsRequest{StepIDs: []string{steps[2].id.String(), steps[2].id.String()}}
+	reordered, err := s.ReorderOnboardingSteps(context.Background(), workspaceID, userID, checklistID, req)
+	if err != nil {
+		t.Fatalf("ReorderOnboardingSteps: %v", err)
+	}
+	if len(reordered) != 3 || reordered[0].ID != steps[2].id {
+		t.Errorf("got %d steps, want 3 starting with the moved step", len(reordered))
+	}
+}
+
+func TestReorderOnboardingStepsRejectsStepFromAnotherChecklist(t *testing.T) {
+	s, mock := newTestService(t)
+	workspaceID, checklistID := uuid.New(), uuid.New()
+
+	expectRole(mock, "owner")
+	expectChecklist(mock, checklistID, workspaceID)
+	expectSteps(mock, checklistID, newTestSteps(false))
+
+	req := &models.ReorderStepsRequest{StepIDs: []string{uuid.New().String()}}
+	if _, err := s.ReorderOnboardingSteps(context.Background(), workspaceID, uuid.New(), checklistID, req); err != ErrOnboardingStepNotFound {
+		t.Errorf("err = %v, want ErrOnboardingStepNotFound", err)
+	}
+}
+
+func TestReorderOnboardingStepsRejectsChecklistFromAnotherWorkspace(t *testing.T) {
+	s, mock := newTestService(t)
+	checklistID := uuid.New()
+
+	expectRole(mock, "owner")
+	expectChecklist(mock, checklistID, uuid.New())
+
+	req := &models.ReorderStepsRequest{}
+	if _, err := s.ReorderOnboardingSteps(context.Background(), uuid.New(), uuid.New(), checklistID, req); err != ErrChecklistNotFound {
+		t.Errorf("err = %v, want ErrChecklistNotFound", err)
+	}
+}
+
+func TestUpdateOnboardingStepAppliesOnlyGivenFields(t *testing.T) {
+	s, mock := newTestService(t)
+	workspaceID, checklistID, stepID := uuid.New(), uuid.New(), uuid.New()
+
+	expectRole(mock, "owner")
+	mock.ExpectQuery(`SELECT \* FROM onboarding_steps WHERE id = \?`).
+		WithArgs(stepID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "checklist_id", "title", "action_type", "is_required"}).
+			AddRow(stepID.String(), checklistID.String(), "Old title", "task", true))
+	expectChecklist(mock, checklistID, workspaceID)
+	mock.ExpectExec(`UPDATE onboarding_steps SET title = \?`).
+		WithArgs("New title", nil, "task", nil, true, stepID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	title := "New title"
+	step, err := s.UpdateOnboardingStep(context.Background(), workspaceID, uuid.New(), stepID, &models.UpdateStepRequest{Title: &title})
+	if err != nil {
+		t.Fatalf("UpdateOnboardingStep: %v", err)
+	}
+	if step.Title != title || !step.IsRequired || step.ActionType != "task" {
+		t.Errorf("step = %+v, want only the title changed", step)
+	}
+}
+
+func TestReadinessPercentage(t *testing.T) {
+	tests := []struct {
+		completed, total, want int
+	}{
+		{0, 0, 100},
+		{0, 4, 0},
+		{1, 3, 33},
+		{2, 3, 66},
+		{199, 200, 99},
+		{5, 5, 100},
+	}
+	for _, tt := range tests {
+		if got := readinessPercentage(tt.completed, tt.total); got != tt.want {
+			t.Errorf("readinessPercentage(%d, %d) = %d, want %d", tt.completed, tt.total, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/quckapp/workspace-service/internal/db"
+	"github.com/quckapp/workspace-service/internal/metrics"
+	"github.com/quckapp/workspace-service/internal/repository"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	outboxPollInterval  = time.Second
+	outboxBatchSize     = 100
+	outboxRetention     = 24 * time.Hour
+	outboxPruneInterval = time.Hour
+	outboxClaimLease    = time.Minute
+	outboxMaxAttempts   = 10
+)
+
+// OutboxRelay publishes events from the transactional outbox to Kafka.
+// Delivery is at-least-once: an event is marked published only after Kafka
+// accepts it, so a crash in between re-sends it once its claim lapses. An
+// event that keeps failing is dead-lettered after outboxMaxAttempts tries.
+type OutboxRelay struct {
+	outboxRepo *repository.OutboxRepository
+	kafka      *db.KafkaProducer
+	metrics    *metrics.Metrics
+	logger     *logrus.Logger
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+func NewOutboxRelay(outboxRepo *repository.OutboxRepository, kafka *db.KafkaProducer, metrics *metrics.Metrics, logger *logrus.Logger) *OutboxRelay {
+	return &OutboxRelay{
+		outboxRepo: outboxRepo,
+		kafka:      kafka,
+		metrics:    metrics,
+		logger:     logger,
+		stop:       make(chan struct{}),
+		done:       make(chan struct{}),
+	}
+}
+
+// Start begins polling the outbox.
+func (o *OutboxRelay) Start() {
+	go func() {
+		defer close(o.done)
+		ticker := time.NewTicker(outboxPollInterval)
+		defer ticker.Stop()
+		lastPrune := time.Now()
+		for {
+			select {
+			case <-ticker.C:
+				o.relay()
+				o.recordLag()
+				if time.Since(lastPrune) >= outboxPruneInterval {
+					o.prune()
+					lastPrune = time.Now()
+				}
+			case <-o.stop:
+				o.relay()
+				return
+			}
+		}
+	}()
+}
+
+// Stop relays whatever is pending one last time and stops polling, giving
+// up on the last pass when ctx is done.
+func (o *OutboxRelay) Stop(ctx context.Context) error {
+	close(o.stop)
+	return waitDone(ctx, o.done, "outbox relay")
+}
+
+func (o *OutboxRelay) relay() {
+	if o.kafka == nil {
+		return
+	}
+	ctx := context.Background()
+
+	claimID := uuid.New().String()
+	events, err := o.outboxRepo.Claim(ctx, claimID, outboxClaimLease, outboxBatchSize)
+	if err != nil {
+		o.logger.WithError(err).Warn("Failed to read event outbox")
+		return
+	}
+	defer func() {
+		if err := o.outboxRepo.Release(ctx, claimID); err != nil {
+			o.logger.WithError(err).Warn("Failed to release outbox claim")
+		}
+	}()
+
+	for _, e := range events {
+		if err := o.kafka.Publish(ctx, e.Topic, e.EventKey, json.RawMessage(e.Payload)); err != nil {
+			o.metrics.KafkaPublishFailed()
+			if err := o.outboxRepo.RecordFailure(ctx, e.ID, err.Error(), outboxMaxAttempts); err != nil {
+				o.logger.WithError(err).WithField("event_id", e.ID).Warn("Failed to record outbox failure")
+			}
+			entry := o.logger.WithError(err).WithFields(logrus.Fields{
+				"event_id":   e.ID,
+				"event_type": e.EventType,
+				"attempts":   e.Attempts + 1,
+			})
+			if e.Attempts+1 >= outboxMaxAttempts {
+				entry.Error("Outbox event dead-lettered")
+			} else {
+				entry.Warn("Failed to relay outbox event")
+			}
+			// Stop here to keep per-key ordering; the rest go on the next poll
+			return
+		}
+		if err := o.outboxRepo.MarkPublished(ctx, e.ID); err != nil {
+			o.logger.WithError(err).WithField("event_id", e.ID).Warn("Failed to mark outbox event published")
+		}
+	}
+}
+
+func (o *OutboxRelay) recordLag() {
+	if o.metrics == nil {
+		return
+	}
+	stats, err := o.outboxRepo.GetStats(context.Background())
+	if err != nil {
+		return
+	}
+	o.metrics.SetOutboxLag(stats.Pending, stats.LagSeconds)
+}
+
+func (o *OutboxRelay) prune() {
+	n, err := o.outboxRepo.DeletePublishedBefore(context.Background(), time.Now().Add(-outboxRetention))
+	if err != nil {
+		o.logger.WithError(err).Warn("Failed to prune event outbox")
+		return
+	}
+	if n > 0 {
+		o.logger.WithField("deleted", n).Debug("Pruned event outbox")
+	}
+}
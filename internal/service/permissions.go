@@ -0,0 +1,214 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/quckapp/workspace-service/internal/models"
+	"github.com/sirupsen/logrus"
+)
+
+// permissionCatalog is the single list of permission keys a custom role may
+// grant. Role validation and the published catalog both read from it.
+var permissionCatalog = []models.PermissionDefinition{
+	{Key: "workspace.manage", Category: "workspace", Description: "Edit workspace name, description, icon and settings"},
+	{Key: "workspace.archive", Category: "workspace", Description: "Archive and restore the workspace"},
+	{Key: "members.invite", Category: "members", Description: "Invite new members and create invite codes"},
+	{Key: "members.remove", Category: "members", Description: "Remove members from the workspace"},
+	{Key: "members.manage_roles", Category: "members", Description: "Change member roles and assign custom roles"},
+	{Key: "members.view_notes", Category: "members", Description: "Read private notes left on members"},
+	{Key: "roles.manage", Category: "roles", Description: "Create, edit and delete custom roles"},
+	{Key: "channels.create", Category: "channels", Description: "Create channels"},
+	{Key: "channels.manage", Category: "channels", Description: "Edit, archive and delete any channel"},
+	{Key: "messages.pin", Category: "messages", Description: "Pin and unpin items in the workspace"},
+	{Key: "messages.delete_any", Category: "messages", Description: "Delete messages posted by other members"},
+	{Key: "announcements.manage", Category: "announcements", Description: "Create, pin and delete announcements"},
+	{Key: "moderation.ban", Category: "moderation", Description: "Ban and unban members"},
+	{Key: "moderation.mute", Category: "moderation", Description: "Mute and unmute members"},
+	{Key: "integrations.manage", Category: "integrations", Description: "Install and configure integrations and webhooks"},
+	{Key: "analytics.view", Category: "analytics", Description: "View workspace analytics and exports"},
+	{Key: "audit.view", Category: "compliance", Description: "View and export the audit log"},
+	{Key: "policies.manage", Category: "compliance", Description: "Create and edit compliance policies"},
+	{Key: "billing.manage", Category: "billing", Description: "Change the plan and manage billing"},
+}
+
+type defaultRole struct {
+	name        string
+	priority    int
+	permissions []string
+}
+
+// defaultRoles are seeded into every new workspace so the custom-role
+// hierarchy works out of the box. Higher priority outranks lower.
+var defaultRoles = []defaultRole{
+	{name: "admin", priority: 100, permissions: []string{
+		"workspace.manage", "members.invite", "members.remove", "members.manage_roles", "members.view_notes",
+		"roles.manage", "channels.create", "channels.manage", "messages.pin", "messages.delete_any",
+		"announcements.manage", "moderation.ban", "moderation.mute", "integrations.manage",
+		"analytics.view", "audit.view", "policies.manage",
+	}},
+	{name: "moderator", priority: 50, permissions: []string{
+		"members.invite", "channels.create", "channels.manage", "messages.pin", "messages.delete_any",
+		"announcements.manage", "moderation.mute",
+	}},
+	{name: "member", priority: 10, permissions: []string{
+		"members.invite", "channels.create", "messages.pin",
+	}},
+	{name: "guest", priority: 0, permissions: []string{}},
+}
+
+// seedDefaultRoles creates the default roles for a new workspace. Failures
+// are logged rather than failing workspace creation.
+func (s *WorkspaceService) seedDefaultRoles(ctx context.Context, workspaceID, ownerID uuid.UUID) {
+	now := time.Now()
+	for _, d := range defaultRoles {
+		permissions := models.JSON{}
+		for _, key := range d.permissions {
+			permissions[key] = true
+		}
+		role := &models.WorkspaceRole{
+			ID:          uuid.New(),
+			WorkspaceID: workspaceID,
+			Name:        d.name,
+			Priority:    d.priority,
+			Permissions: permissions,
+			IsDefault:   true,
+			CreatedBy:   ownerID,
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		}
+		if err := s.roleRepo.Create(ctx, role); err != nil {
+			s.logger.WithError(err).WithFields(logrus.Fields{
+				"workspace_id": workspaceID,
+				"role":         d.name,
+			}).Warn("Failed to seed default role")
+		}
+	}
+}
+
+var permissionKeys = func() map[string]bool {
+	keys := make(map[string]bool, len(permissionCatalog))
+	for _, p := range permissionCatalog {
+		keys[p.Key] = true
+	}
+	return keys
+}()
+
+func (s *WorkspaceService) GetPermissionCatalog(ctx context.Context, workspaceID, userID uuid.UUID) ([]models.PermissionDefinition, error) {
+	isMember, _ := s.memberRepo.IsMember(ctx, workspaceID, userID)
+	if !isMember {
+		return nil, ErrNotMember
+	}
+
+	catalog := make([]models.PermissionDefinition, len(permissionCatalog))
+	copy(catalog, permissionCatalog)
+	return catalog, nil
+}
+
+// validatePermissions checks that every key is in the catalog and maps to a
+// boolean grant.
+func validatePermissions(permissions models.JSON) error {
+	for key, value := range permissions {
+		if !permissionKeys[key] {
+			return ErrUnknownPermission
+		}
+		if _, ok := value.(bool); !ok {
+			return ErrUnknownPermission
+		}
+	}
+	return nil
+}
+
+// memberPermissions resolves the permissions a member holds. Owners hold
+// every permission. Other members get the grants of the workspace's default
+// role named after their base role (or the built-in defaults when the
+// workspace has none), plus those of each custom role assigned to them.
+// messages.pin follows CreatePinnedItem rather than the grants: every member
+// may pin unless pin_admins_only is on, when only owners and admins may.
+func (s *WorkspaceService) memberPermissions(ctx context.Context, workspaceID, userID uuid.UUID) (string, map[string]bool, error) {
+	role, _ := s.memberRepo.GetRole(ctx, workspaceID, userID)
+	if role == "" {
+		return "", nil, ErrNotMember
+	}
+
+	granted := map[string]bool{}
+	if role == "owner" {
+		for key := range permissionKeys {
+			granted[key] = true
+		}
+		return role, granted, nil
+	}
+
+	baseRole, err := s.roleRepo.GetByName(ctx, workspaceID, role)
+	if err != nil {
+		return "", nil, err
+	}
+	if baseRole != nil && baseRole.IsDefault {
+		addGrants(granted, baseRole.Permissions)
+	} else {
+		for _, d := range defaultRoles {
+			if d.name == role {
+				for _, key := range d.permissions {
+					granted[key] = true
+				}
+			}
+		}
+	}
+
+	assigned, err := s.roleRepo.ListByMember(ctx, workspaceID, userID)
+	if err != nil {
+		return "", nil, err
+	}
+	for _, r := range assigned {
+		addGrants(granted, r.Permissions)
+	}
+
+	workspace, err := s.workspaceRepo.GetByID(ctx, workspaceID)
+	if err != nil {
+		return "", nil, err
+	}
+	if workspace != nil && canPin(role, workspace.Settings) {
+		granted["messages.pin"] = true
+	} else {
+		delete(granted, "messages.pin")
+	}
+	return role, granted, nil
+}
+
+// canPin reports whether a member with the base role may pin items: anyone
+// may, unless pin_admins_only is on, when only owners and admins may.
+func canPin(role string, settings models.JSON) bool {
+	return role == "owner" || role == "admin" || !settingBool(settings, settingPinAdminsOnly)
+}
+
+// addGrants adds the catalog keys permissions grants to granted.
+func addGrants(granted map[string]bool, permissions models.JSON) {
+	for key, value := range permissions {
+		if allowed, ok := value.(bool); ok && allowed && permissionKeys[key] {
+			granted[key] = true
+		}
+	}
+}
+
+// GetMyPermissions lists the permissions the user holds in the workspace,
+// sorted, so clients can decide which actions to offer.
+func (s *WorkspaceService) GetMyPermissions(ctx context.Context, workspaceID, userID uuid.UUID) (*models.EffectivePermissions, error) {
+	role, granted, err := s.memberPermissions(ctx, workspaceID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	permissions := make([]string, 0, len(granted))
+	for key := range granted {
+		permissions = append(permissions, key)
+	}
+	sort.Strings(permissions)
+	return &models.EffectivePermissions{
+		WorkspaceID: workspaceID,
+		UserID:      userID,
+		Role:        role,
+		Permissions: permissions,
+	}, nil
+}
@@ -0,0 +1,231 @@
+package service
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/quckapp/workspace-service/internal/models"
+	"github.com/sirupsen/logrus/hooks/test"
+)
+
+func TestPermissionCatalogIsWellFormed(t *testing.T) {
+	seen := map[string]bool{}
+	for _, p := range permissionCatalog {
+		if seen[p.Key] {
+			t.Errorf("duplicate permission %q", p.Key)
+		}
+		seen[p.Key] = true
+		if p.Category == "" || p.Description == "" {
+			t.Errorf("permission %q is missing a category or description", p.Key)
+		}
+	}
+	for _, role := range defaultRoles {
+		for _, key := range role.permissions {
+			if !permissionKeys[key] {
+				t.Errorf("default role %q grants %q, which is not in the catalog", role.name, key)
+			}
+		}
+	}
+}
+
+func TestValidatePermissions(t *testing.T) {
+	tests := []struct {
+		name        string
+		permissions models.JSON
+		wantErr     bool
+	}{
+		{"empty", models.JSON{}, false},
+		{"known grants", models.JSON{"members.invite": true, "roles.manage": false}, false},
+		{"unknown key", models.JSON{"members.invite": true, "root": true}, true},
+		{"non-boolean grant", models.JSON{"members.invite": "yes"}, true},
+	}
+	for _, tt := range tests {
+		err := validatePermissions(tt.permissions)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: err = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+		if err != nil && err != ErrUnknownPermission {
+			t.Errorf("%s: err = %v, want ErrUnknownPermission", tt.name, err)
+		}
+	}
+}
+
+func TestGetPermissionCatalogReturnsCopy(t *testing.T) {
+	s, mock := newTestService(t)
+	expectMember(mock, true)
+
+	catalog, err := s.GetPermissionCatalog(context.Background(), uuid.New(), uuid.New())
+	if err != nil {
+		t.Fatalf("GetPermissionCatalog: %v", err)
+	}
+	if len(catalog) != len(permissionCatalog) {
+		t.Fatalf("got %d permissions, want %d", len(catalog), len(permissionCatalog))
+	}
+	catalog[0].Description = "changed"
+	if permissionCatalog[0].Description == "changed" {
+		t.Error("changing the returned catalog changed the shared one")
+	}
+}
+
+func TestGetPermissionCatalogRequiresMembership(t *testing.T) {
+	s, mock := newTestService(t)
+	expectMember(mock, false)
+
+	if _, err := s.GetPermissionCatalog(context.Background(), uuid.New(), uuid.New()); err != ErrNotMember {
+		t.Errorf("err = %v, want ErrNotMember", err)
+	}
+}
+
+func TestDefaultRolesDescendInPriority(t *testing.T) {
+	for i := 1; i < len(defaultRoles); i++ {
+		if defaultRoles[i].priority >= defaultRoles[i-1].priority {
+			t.Errorf("%q (priority %d) does not rank below %q (priority %d)",
+				defaultRoles[i].name, defaultRoles[i].priority, defaultRoles[i-1].name, defaultRoles[i-1].priority)
+		}
+	}
+	for _, role := range defaultRoles {
+		for _, key := range role.permissions {
+			if key == "workspace.archive" || key == "billing.manage" {
+				t.Errorf("default role %q grants the owner-only permission %q", role.name, key)
+			}
+		}
+	}
+}
+
+func TestSeedDefaultRolesKeepsGoingAfterFailure(t *testing.T) {
+	s, _ := newTestService(t)
+	logger, hook := test.NewNullLogger()
+	s.logger = logger
+
+	// Nothing is expected, so every insert fails against the mock.
+	s.seedDefaultRoles(context.Background(), uuid.New(), uuid.New())
+
+	if n := len(hook.AllEntries()); n != len(defaultRoles) {
+		t.Errorf("%d failures logged, want one per default role (%d)", n, len(defaultRoles))
+	}
+}
+
+func TestGetMyPermissionsOwnerHoldsEveryPermission(t *testing.T) {
+	s, mock := newTestService(t)
+	expectDefaultRole(mock, "owner")
+
+	perms, err := s.GetMyPermissions(context.Background(), uuid.New(), uuid.New())
+	if err != nil {
+		t.Fatalf("GetMyPermissions: %v", err)
+	}
+	if perms.Role != "owner" || len(perms.Permissions) != len(permissionCatalog) {
+		t.Fatalf("owner holds %d of %d permissions", len(perms.Permissions), len(permissionCatalog))
+	}
+	if !sort.StringsAreSorted(perms.Permissions) {
+		t.Errorf("permissions %v are not sorted", perms.Permissions)
+	}
+}
+
+func TestGetMyPermissionsGuestIsRestricted(t *testing.T) {
+	s, mock := newTestService(t)
+	workspaceID := uuid.New()
+	expectDefaultRole(mock, "guest")
+	expectWorkspace(mock, workspaceID)
+
+	perms, err := s.GetMyPermissions(context.Background(), workspaceID, uuid.New())
+	if err != nil {
+		t.Fatalf("GetMyPermissions: %v", err)
+	}
+	if want := []string{"messages.pin"}; perms.Role != "guest" || !reflect.DeepEqual(perms.Permissions, want) {
+		t.Errorf("guest holds %v, want %v", perms.Permissions, want)
+	}
+}
+
+func TestGetMyPermissionsMemberGetsDefaults(t *testing.T) {
+	s, mock := newTestService(t)
+	workspaceID := uuid.New()
+	expectDefaultRole(mock, "member")
+	expectWorkspace(mock, workspaceID)
+
+	perms, err := s.GetMyPermissions(context.Background(), workspaceID, uuid.New())
+	if err != nil {
+		t.Fatalf("GetMyPermissions: %v", err)
+	}
+	if want := []string{"channels.create", "members.invite", "messages.pin"}; !reflect.DeepEqual(perms.Permissions, want) {
+		t.Errorf("member holds %v, want %v", perms.Permissions, want)
+	}
+}
+
+func TestAddGrantsKeepsOnlyGrantedCatalogKeys(t *testing.T) {
+	granted := map[string]bool{"members.invite": true}
+	addGrants(granted, models.JSON{"analytics.view": true, "audit.view": false, "root": true, "roles.manage": "yes"})
+
+	want := map[string]bool{"members.invite": true, "analytics.view": true}
+	if !reflect.DeepEqual(granted, want) {
+		t.Errorf("granted = %v, want %v", granted, want)
+	}
+}
+
+func TestCanPin(t *testing.T) {
+	adminsOnly := models.JSON{settingPinAdminsOnly: true}
+	tests := []struct {
+		role     string
+		settings models.JSON
+		want     bool
+	}{
+		{"member", nil, true},
+		{"guest", nil, true},
+		{"owner", adminsOnly, true},
+		{"admin", adminsOnly, true},
+		{"member", adminsOnly, false},
+		{"guest", adminsOnly, false},
+	}
+	for _, tt := range tests {
+		if got := canPin(tt.role, tt.settings); got != tt.want {
+			t.Errorf("canPin(%q, %v) = %v, want %v", tt.role, tt.settings, got, tt.want)
+		}
+	}
+}
+
+func TestGetMyPermissionsRequiresMembership(t *testing.T) {
+	s, mock := newTestService(t)
+	expectDefaultRole(mock, "")
+
+	if _, err := s.GetMyPermissions(context.Background(), uuid.New(), uuid.New()); err != ErrNotMember {
+		t.Errorf("err = %v, want ErrNotMember", err)
+	}
+}
+
+// TestDefaultGrantsDoNotWidenAccess checks that holding a permission in the
+// default role catalog does not let plain members or admins past the role
+// checks the actions themselves make.
+func TestDefaultGrantsDoNotWidenAccess(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name string
+		role string
+		call func(s *WorkspaceService) error
+	}{
+		{"member creates invite code", "member", func(s *WorkspaceService) error {
+			_, err := s.CreateInviteCode(ctx, uuid.New(), uuid.New(), &models.CreateInviteCodeRequest{})
+			return err
+		}},
+		{"member lists join requests", "member", func(s *WorkspaceService) error {
+			_, _, err := s.ListJoinRequests(ctx, uuid.New(), uuid.New(), "", 1, 20)
+			return err
+		}},
+		{"member approves join request", "member", func(s *WorkspaceService) error {
+			_, err := s.ApproveJoinRequest(ctx, uuid.New(), uuid.New(), uuid.New())
+			return err
+		}},
+		{"admin creates role", "admin", func(s *WorkspaceService) error {
+			_, err := s.CreateRole(ctx, uuid.New(), uuid.New(), &models.CreateRoleRequest{Name: "superuser"})
+			return err
+		}},
+	}
+	for _, tt := range tests {
+		s, mock := newTestService(t)
+		expectRole(mock, tt.role)
+		if err := tt.call(s); err != ErrNotAuthorized {
+			t.Errorf("%s: err = %v, want ErrNotAuthorized", tt.name, err)
+		}
+	}
+}
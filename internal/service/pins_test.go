@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/quckapp/workspace-service/internal/models"
+)
+
+func expectPinCount(mock sqlmock.Sqlmock, workspaceID uuid.UUID, n int) {
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM workspace_pinned_items WHERE workspace_id = \?`).
+		WithArgs(workspaceID).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(n))
+}
+
+func TestCreatePinnedItemAppendsUnderLimit(t *testing.T) {
+	s, mock := newTestService(t)
+	workspaceID, userID := uuid.New(), uuid.New()
+
+	expectRole(mock, "owner")
+	expectWorkspace(mock, workspaceID)
+	expectPinCount(mock, workspaceID, defaultMaxPinnedItems-1)
+	mock.ExpectQuery(`SELECT MAX\(position\) FROM workspace_pinned_items`).
+		WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(7))
+	mock.ExpectExec(`INSERT INTO workspace_pinned_items`).
+		WithArgs(sqlmock.AnyArg(), workspaceID, "link", nil, "Roadmap", nil, sqlmock.AnyArg(), userID, 8, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	url := "https://example.com/roadmap"
+	item, err := s.CreatePinnedItem(context.Background(), workspaceID, userID, &models.CreatePinnedItemRequest{ItemType: "link", Title: "Roadmap", URL: &url})
+	if err != nil {
+		t.Fatalf("CreatePinnedItem: %v", err)
+	}
+	if item.Position != 8 {
+		t.Errorf("position = %d, want 8", item.Position)
+	}
+}
+
+func TestCreatePinnedItemStopsAtLimit(t *testing.T) {
+	s, mock := newTestService(t)
+	workspaceID := uuid.New()
+
+	expectRole(mock, "owner")
+	expectWorkspace(mock, workspaceID)
+	expectPinCount(mock, workspaceID, defaultMaxPinnedItems)
+
+	_, err := s.CreatePinnedItem(context.Background(), workspaceID, uuid.New(), &models.CreatePinnedItemRequest{ItemType: "note", Title: "One too many"})
+	if err != ErrPinLimitReached {
+		t.Errorf("err = %v, want ErrPinLimitReached", err)
+	}
+}
+
+func TestCreatePinnedItemRejectsUnknownType(t *testing.T) {
+	s, mock := newTestService(t)
+	expectRole(mock, "member")
+
+	_, err := s.CreatePinnedItem(context.Background(), uuid.New(), uuid.New(), &models.CreatePinnedItemRequest{ItemType: "channel", Title: "General"})
+	if err != ErrInvalidPinType {
+		t.Errorf("err = %v, want ErrInvalidPinType", err)
+	}
+}
+
+func TestCreatePinnedItemRequiresMembership(t *testing.T) {
+	s, mock := newTestService(t)
+	expectRole(mock, "")
+
+	_, err := s.CreatePinnedItem(context.Background(), uuid.New(), uuid.New(), &models.CreatePinnedItemRequest{ItemType: "note", Title: "Hi"})
+	if err != ErrNotMember {
+		t.Errorf("err = %v, want ErrNotMember", err)
+	}
+}
@@ -0,0 +1,188 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/quckapp/workspace-service/internal/models"
+)
+
+func TestResolveNotification(t *testing.T) {
+	now := time.Now()
+	later, earlier := now.Add(time.Hour), now.Add(-time.Hour)
+	tests := []struct {
+		name      string
+		pref      *models.WorkspaceMemberPreference
+		eventType string
+		channel   string
+		want      bool
+		reason    string
+	}{
+		{"no preferences", nil, "message", "email", true, "default"},
+		{"muted", &models.WorkspaceMemberPreference{NotificationLevel: "all", MuteUntil: &later}, "mention", "in_app", false, "muted"},
+		{"mute lapsed", &models.WorkspaceMemberPreference{NotificationLevel: "all", MuteUntil: &earlier}, "message", "in_app", true, "allowed"},
+		{"off", &models.WorkspaceMemberPreference{NotificationLevel: "none"}, "mention", "in_app", false, "notifications_off"},
+		{"mentions only skips others", &models.WorkspaceMemberPreference{NotificationLevel: "mentions"}, "message", "in_app", false, "mentions_only"},
+		{"mentions only allows mention", &models.WorkspaceMemberPreference{NotificationLevel: "mentions"}, "mention", "in_app", true, "allowed"},
+		{"mentions only allows suffixed mention", &models.WorkspaceMemberPreference{NotificationLevel: "mentions"}, "channel.mention", "in_app", true, "allowed"},
+		{"email off", &models.WorkspaceMemberPreference{NotificationLevel: "all"}, "message", "email", false, "email_disabled"},
+		{"email off leaves in-app", &models.WorkspaceMemberPreference{NotificationLevel: "all"}, "message", "in_app", true, "allowed"},
+		{"email on", &models.WorkspaceMemberPreference{NotificationLevel: "all", EmailNotifications: true}, "message", "email", true, "allowed"},
+	}
+	for _, tt := range tests {
+		got, reason := resolveNotification(tt.pref, tt.eventType, tt.channel, now)
+		if got != tt.want || reason != tt.reason {
+			t.Errorf("%s: got (%v, %q), want (%v, %q)", tt.name, got, reason, tt.want, tt.reason)
+		}
+	}
+}
+
+func TestShouldNotifySkipsNonMembers(t *testing.T) {
+	s, mock := newTestService(t)
+	expectMember(mock, true)
+	expectMember(mock, false)
+
+	ok, reason, err := s.ShouldNotify(context.Background(), uuid.New(), uuid.New(), uuid.New(), "mention", "in_app")
+	if err != nil || ok || reason != "not_member" {
+		t.Errorf("got (%v, %q, %v), want (false, \"not_member\", nil)", ok, reason, err)
+	}
+}
+
+func TestShouldNotifyRequiresMemberRequester(t *testing.T) {
+	s, mock := newTestService(t)
+	expectMember(mock, false)
+
+	// Only the membership check runs: the requester's preferences are
+	// neither read nor touched.
+	if _, _, err := s.ShouldNotify(context.Background(), uuid.New(), uuid.New(), uuid.New(), "mention", "in_app"); err != ErrNotMember {
+		t.Errorf("err = %v, want ErrNotMember", err)
+	}
+}
+
+func expectPreferences(mock sqlmock.Sqlmock, workspaceID, userID uuid.UUID, muteUntil interface{}) {
+	mock.ExpectQuery(`SELECT \* FROM workspace_member_preferences WHERE workspace_id = \? AND user_id = \?`).
+		WithArgs(workspaceID, userID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "workspace_id", "user_id", "notification_level", "mute_until"}).
+			AddRow(uuid.New().String(), workspaceID.String(), userID.String(), "all", muteUntil))
+}
+
+func TestGetPreferencesReportsActiveMute(t *testing.T) {
+	s, mock := newTestService(t)
+	workspaceID, userID := uuid.New(), uuid.New()
+
+	expectMember(mock, true)
+	expectPreferences(mock, workspaceID, userID, time.Now().Add(time.Hour))
+
+	pref, err := s.GetPreferences(context.Background(), workspaceID, userID)
+	if err != nil {
+		t.Fatalf("GetPreferences: %v", err)
+	}
+	if !pref.IsMuted || pref.MuteUntil == nil {
+		t.Errorf("is_muted = %v, mute_until = %v, want muted", pref.IsMuted, pref.MuteUntil)
+	}
+}
+
+func TestGetPreferencesClearsLapsedMute(t *testing.T) {
+	s, mock := newTestService(t)
+	workspaceID, userID := uuid.New(), uuid.New()
+
+	expectMember(mock, true)
+	expectPreferences(mock, workspaceID, userID, time.Now().Add(-time.Minute))
+	mock.ExpectExec(`UPDATE workspace_member_preferences SET mute_until = NULL WHERE workspace_id = \? AND user_id = \? AND mute_until <= \?`).
+		WithArgs(workspaceID, userID, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	pref, err := s.GetPreferences(context.Background(), workspaceID, userID)
+	if err != nil {
+		t.Fatalf("GetPreferences: %v", err)
+	}
+	if pref.IsMuted || pref.MuteUntil != nil {
+		t.Errorf("is_muted = %v, mute_until = %v, want unmuted and cleared", pref.IsMuted, pref.MuteUntil)
+	}
+}
+
+func TestGetPreferencesUnmutedWhenCleanupFails(t *testing.T) {
+	s, mock := newTestService(t)
+	workspaceID, userID := uuid.New(), uuid.New()
+
+	expectMember(mock, true)
+	expectPreferences(mock, workspaceID, userID, time.Now().Add(-time.Minute))
+	mock.ExpectExec(`UPDATE workspace_member_preferences SET mute_until = NULL`).
+		WillReturnError(sql.ErrConnDone)
+
+	pref, err := s.GetPreferences(context.Background(), workspaceID, userID)
+	if err != nil {
+		t.Fatalf("GetPreferences: %v", err)
+	}
+	if pref.IsMuted {
+		t.Error("lapsed mute reported as muted")
+	}
+}
+
+func TestRoleDefaultPreferences(t *testing.T) {
+	settings := models.JSON{settingRoleDefaultPreferences: map[string]interface{}{
+		"guest": map[string]interface{}{"notification_level": "mentions", "email_notifications": false},
+	}}
+
+	defaults, ok := roleDefaultPreferences(settings, "guest")
+	if !ok || defaults["notification_level"] != "mentions" || defaults["email_notifications"] != false {
+		t.Errorf("guest defaults = %v (%v), want mentions without email", defaults, ok)
+	}
+	if _, ok := roleDefaultPreferences(settings, "member"); ok {
+		t.Error("member got defaults, want none configured")
+	}
+	if _, ok := roleDefaultPreferences(nil, "guest"); ok {
+		t.Error("workspace without the setting returned defaults")
+	}
+
+	invalid := models.JSON{settingRoleDefaultPreferences: map[string]interface{}{
+		"guest": map[string]interface{}{"notification_level": "sometimes"},
+	}}
+	if _, ok := roleDefaultPreferences(invalid, "guest"); ok {
+		t.Error("invalid setting was applied")
+	}
+}
+
+func TestValidateSettingsRoleDefaultPreferences(t *testing.T) {
+	valid := map[string]interface{}{
+		"admin": map[string]interface{}{"theme": "dark", "email_notifications": true},
+	}
+	if err := validateSettings(models.JSON{settingRoleDefaultPreferences: valid}, true); err != nil {
+		t.Errorf("valid role defaults rejected: %v", err)
+	}
+
+	for name, value := range map[string]interface{}{
+		"not an object":   "guest",
+		"unknown role":    map[string]interface{}{"visitor": map[string]interface{}{}},
+		"role not object": map[string]interface{}{"guest": "mentions"},
+		"unknown field":   map[string]interface{}{"guest": map[string]interface{}{"sidebar_position": float64(1)}},
+		"bad level":       map[string]interface{}{"guest": map[string]interface{}{"notification_level": "loud"}},
+	} {
+		if err := validateSettings(models.JSON{settingRoleDefaultPreferences: value}, true); err == nil {
+			t.Errorf("%s: accepted", name)
+		}
+	}
+}
+
+func TestGetPreferencesWithoutSavedPreferencesUsesDefaults(t *testing.T) {
+	s, mock := newTestService(t)
+	workspaceID, userID := uuid.New(), uuid.New()
+
+	expectMember(mock, true)
+	mock.ExpectQuery(`SELECT \* FROM workspace_member_preferences`).
+		WillReturnError(sql.ErrNoRows)
+	expectWorkspace(mock, workspaceID)
+	expectRole(mock, "guest")
+
+	pref, err := s.GetPreferences(context.Background(), workspaceID, userID)
+	if err != nil {
+		t.Fatalf("GetPreferences: %v", err)
+	}
+	if pref.NotificationLevel != "all" || !pref.EmailNotifications || pref.UserID != userID {
+		t.Errorf("pref = %+v, want the global defaults", pref)
+	}
+}
@@ -0,0 +1,45 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/quckapp/workspace-service/internal/models"
+)
+
+func TestRequiredProfileFields(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings models.JSON
+		want     []string
+	}{
+		{"no settings", nil, []string{}},
+		{"string slice", models.JSON{settingRequiredProfileFields: []string{"title", "timezone"}}, []string{"title", "timezone"}},
+		// Settings read back from MySQL decode lists as []interface{}.
+		{"decoded JSON", models.JSON{settingRequiredProfileFields: []interface{}{"display_name", 7}}, []string{"display_name"}},
+		{"unknown fields dropped", models.JSON{settingRequiredProfileFields: []string{"avatar", "title"}}, []string{"title"}},
+		{"wrong type", models.JSON{settingRequiredProfileFields: "title"}, []string{}},
+	}
+	for _, tt := range tests {
+		got := requiredProfileFields(&models.Workspace{Settings: tt.settings})
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestMissingProfileFields(t *testing.T) {
+	name, blank := "Ada", "   "
+	profile := &models.MemberProfile{DisplayName: &name, Title: &blank}
+	required := []string{"display_name", "title", "timezone"}
+
+	if got, want := missingProfileFields(profile, required), []string{"title", "timezone"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if got := missingProfileFields(nil, required); len(got) != 3 {
+		t.Errorf("no profile: got %v, want every field missing", got)
+	}
+	if got := missingProfileFields(profile, nil); got == nil || len(got) != 0 {
+		t.Errorf("nothing required: got %v, want an empty, non-nil list", got)
+	}
+}
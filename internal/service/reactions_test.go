@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/quckapp/workspace-service/internal/models"
+)
+
+func TestGroupReactionsByEmojiSortsByCountThenEmoji(t *testing.T) {
+	first, second := uuid.New(), uuid.New()
+	reactions := []*models.WorkspaceReaction{
+		{Emoji: "🎉", EntityType: "announcement", EntityID: uuid.New()},
+		{Emoji: "👍", EntityType: "announcement", EntityID: first},
+		{Emoji: "❤️", EntityType: "pin", EntityID: uuid.New()},
+		{Emoji: "👍", EntityType: "note", EntityID: second},
+	}
+
+	groups := groupReactionsByEmoji(reactions)
+	if len(groups) != 3 {
+		t.Fatalf("got %d groups, want 3", len(groups))
+	}
+	if groups[0].Emoji != "👍" || groups[0].Count != 2 {
+		t.Errorf("first group = %s x%d, want 👍 x2", groups[0].Emoji, groups[0].Count)
+	}
+	if groups[0].Entities[0].EntityID != first || groups[0].Entities[1].EntityID != second {
+		t.Error("entities are not kept in input order")
+	}
+	if groups[1].Emoji > groups[2].Emoji {
+		t.Errorf("ties ordered %s, %s; want emoji order", groups[1].Emoji, groups[2].Emoji)
+	}
+}
+
+func TestGroupReactionsByEmojiEmpty(t *testing.T) {
+	if groups := groupReactionsByEmoji(nil); groups == nil || len(groups) != 0 {
+		t.Errorf("got %v, want an empty, non-nil list", groups)
+	}
+}
+
+func TestGetMyReactionsByEmojiScopesToWorkspace(t *testing.T) {
+	s, mock := newTestService(t)
+	workspaceID, userID := uuid.New(), uuid.New()
+
+	expectMember(mock, true)
+	mock.ExpectQuery(`SELECT r.\* FROM workspace_reactions r`).
+		WithArgs(userID, workspaceID, workspaceID, workspaceID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "entity_type", "entity_id", "user_id", "emoji"}).
+			AddRow(uuid.New().String(), "pin", uuid.New().String(), userID.String(), "👀"))
+
+	groups, err := s.GetMyReactionsByEmoji(context.Background(), workspaceID, userID)
+	if err != nil {
+		t.Fatalf("GetMyReactionsByEmoji: %v", err)
+	}
+	if len(groups) != 1 || groups[0].Emoji != "👀" {
+		t.Errorf("got %+v, want one 👀 group", groups)
+	}
+}
+
+func TestGetMyReactionsByEmojiRequiresMembership(t *testing.T) {
+	s, mock := newTestService(t)
+	expectMember(mock, false)
+
+	if _, err := s.GetMyReactionsByEmoji(context.Background(), uuid.New(), uuid.New()); err != ErrNotMember {
+		t.Errorf("err = %v, want ErrNotMember", err)
+	}
+}
+
+func TestRemoveReaction(t *testing.T) {
+	s, mock := newTestService(t)
+	workspaceID, userID, entityID := uuid.New(), uuid.New(), uuid.New()
+
+	expectMember(mock, true)
+	mock.ExpectExec(`DELETE FROM workspace_reactions WHERE entity_type = \? AND entity_id = \? AND user_id = \? AND emoji = \?`).
+		WithArgs("announcement", entityID, userID, "👍").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	if err := s.RemoveReaction(context.Background(), workspaceID, userID, "announcement", entityID, "👍"); err != nil {
+		t.Errorf("RemoveReaction: %v", err)
+	}
+}
+
+func TestRemoveReactionNotFound(t *testing.T) {
+	s, mock := newTestService(t)
+
+	expectMember(mock, true)
+	mock.ExpectExec(`DELETE FROM workspace_reactions`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	if err := s.RemoveReaction(context.Background(), uuid.New(), uuid.New(), "announcement", uuid.New(), "👍"); err != ErrReactionNotFound {
+		t.Errorf("err = %v, want ErrReactionNotFound", err)
+	}
+}
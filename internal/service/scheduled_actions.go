@@ -0,0 +1,176 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/quckapp/workspace-service/internal/models"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	scheduledActionInterval  = time.Minute
+	scheduledActionBatchSize = 100
+	scheduledActionLease     = 10 * time.Minute
+
+	actionPublishAnnouncement = "publish_announcement"
+)
+
+// scheduledActionHandlers carries out each kind of scheduled action the
+// runner knows about. Actions of other types stay pending.
+var scheduledActionHandlers = map[string]func(*WorkspaceService, context.Context, *models.ScheduledAction) error{
+	actionPublishAnnouncement: (*WorkspaceService).publishScheduledAnnouncement,
+}
+
+// RunDueScheduledActions carries out pending actions whose time has come.
+// Each action is claimed before it runs, so a cancelled action is skipped
+// and two instances never run the same one. A claim lasts
+// scheduledActionLease; an action still running after that is assumed
+// abandoned by a crashed instance and is run again, so handlers must be
+// safe to repeat. It returns how many ran successfully.
+func (s *WorkspaceService) RunDueScheduledActions(ctx context.Context) (int, error) {
+	actionTypes := make([]string, 0, len(scheduledActionHandlers))
+	for actionType := range scheduledActionHandlers {
+		actionTypes = append(actionTypes, actionType)
+	}
+
+	staleBefore := time.Now().Add(-scheduledActionLease)
+	actions, err := s.scheduledActionRepo.ListDueOfTypes(ctx, actionTypes, staleBefore, scheduledActionBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	ran := 0
+	for _, action := range actions {
+		claimed, err := s.scheduledActionRepo.Claim(ctx, action.ID, staleBefore)
+		if err != nil || !claimed {
+			continue
+		}
+
+		status := "executed"
+		if err := scheduledActionHandlers[action.ActionType](s, ctx, action); err != nil {
+			s.logger.WithError(err).WithFields(logrus.Fields{
+				"scheduled_action_id": action.ID,
+				"action_type":         action.ActionType,
+				"workspace_id":        action.WorkspaceID,
+			}).Warn("Scheduled action failed")
+			status = "failed"
+		} else {
+			ran++
+		}
+		if err := s.scheduledActionRepo.UpdateStatus(ctx, action.ID, status); err != nil {
+			s.logger.WithError(err).WithField("scheduled_action_id", action.ID).Warn("Failed to record scheduled action status")
+		}
+	}
+	return ran, nil
+}
+
+// scheduleAnnouncement queues the publish of an announcement held back
+// until its publish_at time.
+func (s *WorkspaceService) scheduleAnnouncement(ctx context.Context, announcement *models.WorkspaceAnnouncement) error {
+	now := time.Now()
+	return s.scheduledActionRepo.Create(ctx, &models.ScheduledAction{
+		ID:          uuid.New(),
+		WorkspaceID: announcement.WorkspaceID,
+		ActionType:  actionPublishAnnouncement,
+		Payload:     models.JSON{"announcement_id": announcement.ID.String()},
+		ScheduledAt: *announcement.PublishAt,
+		Status:      "pending",
+		CreatedBy:   announcement.AuthorID,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	})
+}
+
+// cancelAnnouncementSchedule cancels the pending publish of a scheduled
+// announcement, so deleting it does not leave an action that can only fail.
+func (s *WorkspaceService) cancelAnnouncementSchedule(ctx context.Context, announcement *models.WorkspaceAnnouncement) error {
+	return s.scheduledActionRepo.CancelPendingByPayload(ctx, announcement.WorkspaceID, actionPublishAnnouncement, "announcement_id", announcement.ID.String())
+}
+
+// publishScheduledAnnouncement makes a scheduled announcement visible and
+// sends the announcement.created event its author's request deferred.
+func (s *WorkspaceService) publishScheduledAnnouncement(ctx context.Context, action *models.ScheduledAction) error {
+	raw, _ := action.Payload["announcement_id"].(string)
+	announcementID, err := uuid.Parse(raw)
+	if err != nil {
+		return fmt.Errorf("invalid announcement_id %q", raw)
+	}
+
+	announcement, err := s.announcementRepo.GetByID(ctx, announcementID)
+	if err != nil {
+		return err
+	}
+	if announcement == nil || announcement.WorkspaceID != action.WorkspaceID {
+		return ErrAnnouncementNotFound
+	}
+
+	now := time.Now()
+	published, err := s.announcementRepo.Publish(ctx, announcement.ID, now)
+	if err != nil || !published {
+		return err
+	}
+	announcement.IsPublished = true
+	announcement.PublishAt = &now
+	announcement.UpdatedAt = now
+
+	s.LogActivity(ctx, announcement.WorkspaceID, announcement.AuthorID, "announcement.published", "announcement", announcement.ID.String(), models.JSON{"title": announcement.Title})
+	s.publishEvent(ctx, "workspace-events", announcement.WorkspaceID.String(), "workspace.announcement.created", map[string]interface{}{
+		"announcement": announcement,
+	})
+	return nil
+}
+
+// ScheduledActionRunner periodically carries out due scheduled actions.
+type ScheduledActionRunner struct {
+	workspaces *WorkspaceService
+	logger     *logrus.Logger
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+func NewScheduledActionRunner(workspaces *WorkspaceService, logger *logrus.Logger) *ScheduledActionRunner {
+	return &ScheduledActionRunner{
+		workspaces: workspaces,
+		logger:     logger,
+		stop:       make(chan struct{}),
+		done:       make(chan struct{}),
+	}
+}
+
+// Start begins checking for due actions on a fixed interval.
+func (r *ScheduledActionRunner) Start() {
+	go func() {
+		defer close(r.done)
+		ticker := time.NewTicker(scheduledActionInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				r.run()
+			case <-r.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the runner loop, waiting for a running pass to finish or for
+// ctx to be done, whichever comes first.
+func (r *ScheduledActionRunner) Stop(ctx context.Context) error {
+	close(r.stop)
+	return waitDone(ctx, r.done, "scheduled action runner")
+}
+
+func (r *ScheduledActionRunner) run() {
+	ran, err := r.workspaces.RunDueScheduledActions(context.Background())
+	if err != nil {
+		r.logger.WithError(err).Warn("Failed to run scheduled actions")
+	}
+	if ran > 0 {
+		r.logger.WithField("count", ran).Info("Scheduled actions executed")
+	}
+}
@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/quckapp/workspace-service/internal/models"
+)
+
+func publishAction(workspaceID, announcementID uuid.UUID) *models.ScheduledAction {
+	return &models.ScheduledAction{
+		ID:          uuid.New(),
+		WorkspaceID: workspaceID,
+		ActionType:  actionPublishAnnouncement,
+		Payload:     models.JSON{"announcement_id": announcementID.String()},
+	}
+}
+
+func TestPublishScheduledAnnouncement(t *testing.T) {
+	s, mock := newTestService(t)
+	workspaceID, announcementID := uuid.New(), uuid.New()
+
+	expectAnnouncement(mock, announcementID, workspaceID, false, nil)
+	mock.ExpectExec(`UPDATE workspace_announcements SET is_published = TRUE, publish_at = \?, .* AND is_published = FALSE`).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), announcementID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	if err := s.publishScheduledAnnouncement(context.Background(), publishAction(workspaceID, announcementID)); err != nil {
+		t.Fatalf("publishScheduledAnnouncement: %v", err)
+	}
+}
+
+func TestPublishScheduledAnnouncementAlreadyPublished(t *testing.T) {
+	s, mock := newTestService(t)
+	workspaceID, announcementID := uuid.New(), uuid.New()
+
+	expectAnnouncement(mock, announcementID, workspaceID, false, nil)
+	mock.ExpectExec(`UPDATE workspace_announcements SET is_published = TRUE`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	if err := s.publishScheduledAnnouncement(context.Background(), publishAction(workspaceID, announcementID)); err != nil {
+		t.Errorf("err = %v, want a repeated publish to be a no-op", err)
+	}
+}
+
+func TestPublishScheduledAnnouncementFromAnotherWorkspace(t *testing.T) {
+	s, mock := newTestService(t)
+	announcementID := uuid.New()
+
+	expectAnnouncement(mock, announcementID, uuid.New(), false, nil)
+
+	if err := s.publishScheduledAnnouncement(context.Background(), publishAction(uuid.New(), announcementID)); err != ErrAnnouncementNotFound {
+		t.Errorf("err = %v, want ErrAnnouncementNotFound", err)
+	}
+}
+
+func TestPublishScheduledAnnouncementInvalidPayload(t *testing.T) {
+	s, _ := newTestService(t)
+	action := &models.ScheduledAction{ID: uuid.New(), Payload: models.JSON{"announcement_id": "nope"}}
+
+	if err := s.publishScheduledAnnouncement(context.Background(), action); err == nil {
+		t.Error("invalid payload accepted")
+	}
+}
+
+func expectDueActions(mock sqlmock.Sqlmock, ids ...uuid.UUID) {
+	rows := sqlmock.NewRows([]string{"id", "workspace_id", "action_type", "status"})
+	for _, id := range ids {
+		rows.AddRow(id.String(), uuid.New().String(), actionPublishAnnouncement, "pending")
+	}
+	mock.ExpectQuery(`SELECT \* FROM workspace_scheduled_actions\s+WHERE \(status = 'pending' OR \(status = 'running' AND updated_at < \?\)\)`).
+		WithArgs(sqlmock.AnyArg(), actionPublishAnnouncement, scheduledActionBatchSize).
+		WillReturnRows(rows)
+}
+
+func TestRunDueScheduledActionsSkipsUnclaimed(t *testing.T) {
+	s, mock := newTestService(t)
+	actionID := uuid.New()
+
+	expectDueActions(mock, actionID)
+	mock.ExpectExec(`UPDATE workspace_scheduled_actions SET status = 'running'`).
+		WithArgs(sqlmock.AnyArg(), actionID, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	ran, err := s.RunDueScheduledActions(context.Background())
+	if err != nil || ran != 0 {
+		t.Errorf("got (%d, %v), want (0, nil)", ran, err)
+	}
+}
+
+func TestRunDueScheduledActionsRecordsFailure(t *testing.T) {
+	s, mock := newTestService(t)
+	actionID := uuid.New()
+
+	// The action has no payload, so the publish handler fails.
+	expectDueActions(mock, actionID)
+	mock.ExpectExec(`UPDATE workspace_scheduled_actions SET status = 'running'`).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE workspace_scheduled_actions SET status = \?, executed_at = \?`).
+		WithArgs("failed", sqlmock.AnyArg(), sqlmock.AnyArg(), actionID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	ran, err := s.RunDueScheduledActions(context.Background())
+	if err != nil || ran != 0 {
+		t.Errorf("got (%d, %v), want (0, nil)", ran, err)
+	}
+}
+
+func TestCreateAnnouncementRejectsExpiryBeforePublish(t *testing.T) {
+	s, mock := newTestService(t)
+	expectRole(mock, "owner")
+
+	publishAt := time.Now().Add(2 * time.Hour)
+	expiresAt := publishAt.Add(-time.Minute)
+	req := &models.CreateAnnouncementRequest{Title: "Maintenance", Priority: "high", PublishAt: &publishAt, ExpiresAt: &expiresAt}
+	if _, err := s.CreateAnnouncement(context.Background(), uuid.New(), uuid.New(), req); err != ErrExpiresBeforePublish {
+		t.Errorf("err = %v, want ErrExpiresBeforePublish", err)
+	}
+}
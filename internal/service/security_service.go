@@ -20,11 +20,12 @@ var (
 type SecurityService struct {
 	securityRepo *repository.SecurityRepository
 	memberRepo   *repository.MemberRepository
+	auditSink    *AuditSink
 	logger       *logrus.Logger
 }
 
-func NewSecurityService(securityRepo *repository.SecurityRepository, memberRepo *repository.MemberRepository, logger *logrus.Logger) *SecurityService {
-	return &SecurityService{securityRepo: securityRepo, memberRepo: memberRepo, logger: logger}
+func NewSecurityService(securityRepo *repository.SecurityRepository, memberRepo *repository.MemberRepository, auditSink *AuditSink, logger *logrus.Logger) *SecurityService {
+	return &SecurityService{securityRepo: securityRepo, memberRepo: memberRepo, auditSink: auditSink, logger: logger}
 }
 
 // IP Allowlist
@@ -133,7 +134,11 @@ func (s *SecurityService) RevokeSession(ctx context.Context, workspaceID, userID
 		}
 	}
 
-	return s.securityRepo.RevokeSession(ctx, sessionID)
+	if err := s.securityRepo.RevokeSession(ctx, sessionID); err != nil {
+		return err
+	}
+	s.recordAudit(ctx, workspaceID, userID, "session_revoked", "Session revoked", "info", models.JSON{"session_id": sessionID.String()})
+	return nil
 }
 
 func (s *SecurityService) RevokeSessions(ctx context.Context, workspaceID, userID uuid.UUID, req *models.RevokeSessionsRequest) error {
@@ -146,7 +151,11 @@ func (s *SecurityService) RevokeSessions(ctx context.Context, workspaceID, userI
 	}
 
 	if req.AllUsers {
-		return s.securityRepo.RevokeAllSessions(ctx, workspaceID)
+		if err := s.securityRepo.RevokeAllSessions(ctx, workspaceID); err != nil {
+			return err
+		}
+		s.recordAudit(ctx, workspaceID, userID, "session_revoked", "All sessions revoked", "warning", models.JSON{"all_users": true})
+		return nil
 	}
 
 	if req.UserID != nil {
@@ -154,7 +163,10 @@ func (s *SecurityService) RevokeSessions(ctx context.Context, workspaceID, userI
 		if err != nil {
 			return err
 		}
-		return s.securityRepo.RevokeUserSessions(ctx, workspaceID, targetUserID)
+		if err := s.securityRepo.RevokeUserSessions(ctx, workspaceID, targetUserID); err != nil {
+			return err
+		}
+		s.recordAudit(ctx, workspaceID, userID, "session_revoked", "User sessions revoked", "info", models.JSON{"target_user_id": targetUserID.String()})
 	}
 
 	return nil
@@ -236,6 +248,7 @@ func (s *SecurityService) UpdateSecurityPolicy(ctx context.Context, workspaceID,
 	if err := s.securityRepo.UpdateSecurityPolicy(ctx, policy); err != nil {
 		return nil, err
 	}
+	s.recordAudit(ctx, workspaceID, userID, "policy_change", "Security policy updated", "warning", nil)
 	return policy, nil
 }
 
@@ -248,6 +261,26 @@ func (s *SecurityService) ListSecurityAudit(ctx context.Context, workspaceID uui
 	return s.securityRepo.ListAuditEntries(ctx, workspaceID, severity, perPage, offset)
 }
 
+// recordAudit writes a security audit entry and forwards it to the
+// workspace's audit sink. Failures are logged, never returned.
+func (s *SecurityService) recordAudit(ctx context.Context, workspaceID, userID uuid.UUID, eventType, description, severity string, metadata models.JSON) {
+	entry := &models.SecurityAuditEntry{
+		ID:          uuid.New(),
+		WorkspaceID: workspaceID,
+		UserID:      userID,
+		EventType:   eventType,
+		Description: description,
+		Severity:    severity,
+		Metadata:    metadata,
+		CreatedAt:   time.Now(),
+	}
+	if err := s.securityRepo.CreateAuditEntry(ctx, entry); err != nil {
+		s.logger.WithError(err).Warn("Failed to record security audit entry")
+		return
+	}
+	s.auditSink.Forward(ctx, workspaceID, "security_audit", entry)
+}
+
 // Security Overview
 func (s *SecurityService) GetSecurityOverview(ctx context.Context, workspaceID uuid.UUID) (*models.SecurityOverview, error) {
 	policy, err := s.GetSecurityPolicy(ctx, workspaceID)
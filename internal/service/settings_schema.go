@@ -0,0 +1,259 @@
+package service
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/quckapp/workspace-service/internal/models"
+)
+
+// settingRule validates the value of one known settings key. It returns a
+// human-readable problem, or "" when the value is acceptable.
+type settingRule func(value interface{}) string
+
+// settingsSchema lists the settings keys the service knows about. Keys not
+// listed here are free-form unless the caller asks for strict validation.
+var settingsSchema = map[string]settingRule{
+	settingMaxRolesPerMember:           positiveIntRule,
+	settingDefaultAnnouncementPriority: enumRule("low", "normal", "high", "urgent"),
+	settingRequiredProfileFields:       enumListRule("display_name", "title", "timezone"),
+	settingInviteLimitPerMember:        positiveIntRule,
+	settingInviteLimitWindow:           positiveIntRule,
+	settingInviteLandingMessage:        maxLengthRule(500),
+	settingInviteExpiryDays:            intRangeRule(1, maxInviteExpiryDays),
+	settingAnnouncementDigestTime:      timeOfDayRule,
+	settingMaxPinnedItems:              positiveIntRule,
+	settingMaxBookmarksPerUser:         positiveIntRule,
+	settingPinAdminsOnly:               boolRule,
+	settingDefaultMemberRole:           enumRule("member", "guest"),
+	settingRoleDefaultPreferences:      roleDefaultPreferencesRule,
+	settingRejoinCooldownHours:         intRangeRule(0, maxRejoinCooldownHours),
+	"default_notification_level":       enumRule("all", "mentions", "none"),
+}
+
+// readOnlySettings are kept by dedicated endpoints that check their values
+// more closely than a settings rule can. Settings writes may repeat the
+// current value but not change it.
+var readOnlySettings = map[string]string{
+	settingIconThumbnailURL: "is read-only; set it with the workspace icon",
+}
+
+// SettingsValidationError reports every invalid settings key at once so
+// clients can fix them in one round trip.
+type SettingsValidationError struct {
+	Fields map[string]string `json:"fields"`
+}
+
+func (e *SettingsValidationError) Error() string {
+	keys := make([]string, 0, len(e.Fields))
+	for k := range e.Fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return "invalid settings: " + strings.Join(keys, ", ")
+}
+
+// validateSettings checks settings against the schema. Null values are
+// skipped because a merge patch uses them to delete keys. With strict set,
+// unknown keys are rejected as well.
+func validateSettings(settings models.JSON, strict bool) error {
+	problems := map[string]string{}
+	for key, value := range settings {
+		if value == nil {
+			continue
+		}
+		if _, readOnly := readOnlySettings[key]; readOnly {
+			continue
+		}
+		rule, known := settingsSchema[key]
+		if !known {
+			if strict {
+				problems[key] = "unknown setting"
+			}
+			continue
+		}
+		if msg := rule(value); msg != "" {
+			problems[key] = msg
+		}
+	}
+	if len(problems) > 0 {
+		return &SettingsValidationError{Fields: problems}
+	}
+	return nil
+}
+
+// rejectReadOnlySettings reports read-only keys that settings would change
+// from their current value. A null value counts as removing the key.
+func rejectReadOnlySettings(current, settings models.JSON) error {
+	problems := map[string]string{}
+	for key, msg := range readOnlySettings {
+		value, present := settings[key]
+		if !present {
+			continue
+		}
+		if !reflect.DeepEqual(value, current[key]) {
+			problems[key] = msg
+		}
+	}
+	if len(problems) > 0 {
+		return &SettingsValidationError{Fields: problems}
+	}
+	return nil
+}
+
+// keepReadOnlySettings prepares settings to replace current: read-only keys
+// must be left as they are or omitted, and omitted ones are carried over.
+func keepReadOnlySettings(current, settings models.JSON) error {
+	if err := rejectReadOnlySettings(current, settings); err != nil {
+		return err
+	}
+	for key := range readOnlySettings {
+		if value, ok := current[key]; ok {
+			settings[key] = value
+		}
+	}
+	return nil
+}
+
+// diffSettings returns the top-level keys whose value differs between old
+// and new, each mapped to {"old": ..., "new": ...}. Absent keys read as nil.
+func diffSettings(old, new models.JSON) models.JSON {
+	changes := models.JSON{}
+	for key, before := range old {
+		if after := new[key]; !reflect.DeepEqual(before, after) {
+			changes[key] = map[string]interface{}{"old": before, "new": after}
+		}
+	}
+	for key, after := range new {
+		if _, seen := old[key]; !seen && after != nil {
+			changes[key] = map[string]interface{}{"old": nil, "new": after}
+		}
+	}
+	return changes
+}
+
+func positiveIntRule(value interface{}) string {
+	switch v := value.(type) {
+	case float64:
+		if v >= 1 && v == float64(int(v)) {
+			return ""
+		}
+	case int:
+		if v >= 1 {
+			return ""
+		}
+	}
+	return "must be a positive integer"
+}
+
+func boolRule(value interface{}) string {
+	if _, ok := value.(bool); ok {
+		return ""
+	}
+	return "must be true or false"
+}
+
+func intRangeRule(min, max int) settingRule {
+	return func(value interface{}) string {
+		var n float64
+		switch v := value.(type) {
+		case float64:
+			n = v
+		case int:
+			n = float64(v)
+		default:
+			return fmt.Sprintf("must be an integer between %d and %d", min, max)
+		}
+		if n != float64(int(n)) || int(n) < min || int(n) > max {
+			return fmt.Sprintf("must be an integer between %d and %d", min, max)
+		}
+		return ""
+	}
+}
+
+func timeOfDayRule(value interface{}) string {
+	if s, ok := value.(string); ok {
+		if _, err := time.Parse(announcementDigestTimeLayout, s); err == nil && len(s) == len(announcementDigestTimeLayout) {
+			return ""
+		}
+	}
+	return "must be a UTC time of day in HH:MM form"
+}
+
+func maxLengthRule(max int) settingRule {
+	return func(value interface{}) string {
+		if s, ok := value.(string); ok && len(s) <= max {
+			return ""
+		}
+		return fmt.Sprintf("must be a string of at most %d characters", max)
+	}
+}
+
+func enumRule(allowed ...string) settingRule {
+	return func(value interface{}) string {
+		if s, ok := value.(string); ok {
+			for _, a := range allowed {
+				if s == a {
+					return ""
+				}
+			}
+		}
+		return fmt.Sprintf("must be one of: %s", strings.Join(allowed, ", "))
+	}
+}
+
+// roleDefaultPreferencesRule accepts an object keyed by member role whose
+// values hold a subset of the member preference fields.
+func roleDefaultPreferencesRule(value interface{}) string {
+	roles, ok := value.(map[string]interface{})
+	if !ok {
+		return "must be an object keyed by role"
+	}
+	level := enumRule("all", "mentions", "none")
+	theme := maxLengthRule(50)
+	for role, v := range roles {
+		if msg := enumRule("owner", "admin", "member", "guest")(role); msg != "" {
+			return "role " + msg
+		}
+		defaults, ok := v.(map[string]interface{})
+		if !ok {
+			return fmt.Sprintf("%s: must be an object", role)
+		}
+		for key, field := range defaults {
+			var msg string
+			switch key {
+			case "notification_level":
+				msg = level(field)
+			case "email_notifications":
+				msg = boolRule(field)
+			case "theme":
+				msg = theme(field)
+			default:
+				msg = "is not a default-able preference"
+			}
+			if msg != "" {
+				return fmt.Sprintf("%s.%s %s", role, key, msg)
+			}
+		}
+	}
+	return ""
+}
+
+func enumListRule(allowed ...string) settingRule {
+	item := enumRule(allowed...)
+	return func(value interface{}) string {
+		list, ok := value.([]interface{})
+		if !ok {
+			return "must be a list"
+		}
+		for _, v := range list {
+			if msg := item(v); msg != "" {
+				return "each entry " + msg
+			}
+		}
+		return ""
+	}
+}
@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/quckapp/workspace-service/internal/models"
+)
+
+func TestSettingInt(t *testing.T) {
+	tests := []struct {
+		settings models.JSON
+		want     int
+	}{
+		{nil, 7},
+		{models.JSON{}, 7},
+		{models.JSON{"n": float64(3)}, 3},
+		{models.JSON{"n": 4}, 4},
+		{models.JSON{"n": float64(0)}, 7},
+		{models.JSON{"n": -2}, 7},
+		{models.JSON{"n": "5"}, 7},
+	}
+	for _, tt := range tests {
+		if got := settingInt(tt.settings, "n", 7); got != tt.want {
+			t.Errorf("settingInt(%v) = %d, want %d", tt.settings, got, tt.want)
+		}
+	}
+}
+
+func TestWithSettingCopies(t *testing.T) {
+	original := models.JSON{"a": 1, "b": 2}
+
+	updated := withSetting(original, "a", 5)
+	if updated["a"] != 5 || original["a"] != 1 {
+		t.Errorf("withSetting changed the original or missed the update: original=%v updated=%v", original, updated)
+	}
+
+	removed := withSetting(original, "b", nil)
+	if _, ok := removed["b"]; ok {
+		t.Error("nil value did not remove the setting")
+	}
+	if _, ok := original["b"]; !ok {
+		t.Error("removing a setting changed the original")
+	}
+}
+
+func TestMaxRolesPerMember(t *testing.T) {
+	if got := maxRolesPerMember(&models.Workspace{}); got != defaultMaxRolesPerMember {
+		t.Errorf("default = %d, want %d", got, defaultMaxRolesPerMember)
+	}
+	ws := &models.Workspace{Settings: models.JSON{settingMaxRolesPerMember: float64(2)}}
+	if got := maxRolesPerMember(ws); got != 2 {
+		t.Errorf("configured = %d, want 2", got)
+	}
+}
+
+func TestValidateSettings(t *testing.T) {
+	if err := validateSettings(models.JSON{settingMaxPinnedItems: float64(10), "theme": "dark"}, false); err != nil {
+		t.Errorf("valid settings rejected: %v", err)
+	}
+	if err := validateSettings(models.JSON{settingMaxPinnedItems: nil}, true); err != nil {
+		t.Errorf("null value rejected: %v", err)
+	}
+
+	err := validateSettings(models.JSON{settingMaxPinnedItems: float64(-1), settingPinAdminsOnly: "yes", "theme": "dark"}, true)
+	verr, ok := err.(*SettingsValidationError)
+	if !ok {
+		t.Fatalf("err = %v, want a SettingsValidationError", err)
+	}
+	for _, key := range []string{settingMaxPinnedItems, settingPinAdminsOnly, "theme"} {
+		if verr.Fields[key] == "" {
+			t.Errorf("no problem reported for %q in %v", key, verr.Fields)
+		}
+	}
+}
+
+func TestMergeWorkspaceSettingsAppliesPatch(t *testing.T) {
+	s, mock := newTestService(t)
+	workspaceID, userID := uuid.New(), uuid.New()
+
+	expectWorkspace(mock, workspaceID)
+	expectRole(mock, "admin")
+	mock.ExpectExec(`UPDATE workspaces SET settings = JSON_MERGE_PATCH`).
+		WithArgs(`{"max_pinned_items":5,"pin_admins_only":null}`, sqlmock.AnyArg(), workspaceID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectWorkspace(mock, workspaceID)
+
+	patch := models.JSON{settingMaxPinnedItems: float64(5), settingPinAdminsOnly: nil}
+	if _, err := s.MergeWorkspaceSettings(context.Background(), workspaceID, userID, patch, false); err != nil {
+		t.Fatalf("MergeWorkspaceSettings: %v", err)
+	}
+}
+
+func TestMergeWorkspaceSettingsRejectsInvalidPatch(t *testing.T) {
+	s, mock := newTestService(t)
+	workspaceID := uuid.New()
+
+	expectWorkspace(mock, workspaceID)
+	expectRole(mock, "owner")
+
+	_, err := s.MergeWorkspaceSettings(context.Background(), workspaceID, uuid.New(), models.JSON{settingMaxPinnedItems: "lots"}, false)
+	if _, ok := err.(*SettingsValidationError); !ok {
+		t.Errorf("err = %v, want a SettingsValidationError", err)
+	}
+}
+
+func TestMergeWorkspaceSettingsEmptyPatchWritesNothing(t *testing.T) {
+	s, mock := newTestService(t)
+	workspaceID := uuid.New()
+
+	expectWorkspace(mock, workspaceID)
+	expectRole(mock, "owner")
+
+	if _, err := s.MergeWorkspaceSettings(context.Background(), workspaceID, uuid.New(), models.JSON{}, false); err != nil {
+		t.Errorf("MergeWorkspaceSettings: %v", err)
+	}
+}
+
+func TestMergeWorkspaceSettingsRequiresManagePermission(t *testing.T) {
+	s, mock := newTestService(t)
+	workspaceID := uuid.New()
+
+	expectWorkspace(mock, workspaceID)
+	expectRole(mock, "member")
+
+	if _, err := s.MergeWorkspaceSettings(context.Background(), workspaceID, uuid.New(), models.JSON{"theme": "dark"}, false); err != ErrNotAuthorized {
+		t.Errorf("err = %v, want ErrNotAuthorized", err)
+	}
+}
@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+type shutdownStep struct {
+	name string
+	fn   func(ctx context.Context) error
+}
+
+// ShutdownCoordinator runs teardown steps in the order they were added, so
+// producers are stopped before the sinks they write to are closed. Every
+// step runs even if an earlier one fails or the drain deadline passes;
+// steps that wait on work are expected to give up when ctx is done.
+type ShutdownCoordinator struct {
+	steps  []shutdownStep
+	logger *logrus.Logger
+}
+
+func NewShutdownCoordinator(logger *logrus.Logger) *ShutdownCoordinator {
+	return &ShutdownCoordinator{logger: logger}
+}
+
+// Add appends a step to run after all previously added steps.
+func (c *ShutdownCoordinator) Add(name string, fn func(ctx context.Context) error) {
+	c.steps = append(c.steps, shutdownStep{name: name, fn: fn})
+}
+
+// Run executes the steps with a shared deadline of drainTimeout.
+func (c *ShutdownCoordinator) Run(drainTimeout time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), drainTimeout)
+	defer cancel()
+
+	for _, step := range c.steps {
+		start := time.Now()
+		if err := step.fn(ctx); err != nil {
+			c.logger.WithError(err).WithField("step", step.name).Error("Shutdown step failed")
+			continue
+		}
+		c.logger.WithFields(logrus.Fields{
+			"step":     step.name,
+			"duration": time.Since(start).String(),
+		}).Debug("Shutdown step completed")
+	}
+}
+
+// waitDone waits for done to close, giving up with an error naming what is
+// still running once ctx is done.
+func waitDone(ctx context.Context, done <-chan struct{}, what string) error {
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("%s still running: %w", what, ctx.Err())
+	}
+}
@@ -0,0 +1,282 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/quckapp/workspace-service/internal/models"
+)
+
+const (
+	maxTemplateRoles    = 50
+	maxTemplateChannels = 100
+	maxTemplateTags     = 100
+	maxTemplateLabels   = 100
+)
+
+// TemplateValidationError reports every problem in a template's content at
+// once, keyed by path such as "roles[2].name" or "settings.invite_expiry_days".
+type TemplateValidationError struct {
+	Fields map[string]string `json:"fields"`
+}
+
+func (e *TemplateValidationError) Error() string {
+	keys := make([]string, 0, len(e.Fields))
+	for k := range e.Fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return "invalid template: " + strings.Join(keys, ", ")
+}
+
+// decodeTemplateSpec reads the typed spec out of a template's columns. Each
+// column holds an object keyed by its section, e.g. {"roles": [...]}.
+// Unknown fields are rejected so typos surface instead of being ignored.
+func decodeTemplateSpec(t *models.WorkspaceTemplate) (*models.TemplateSpec, error) {
+	spec := &models.TemplateSpec{Settings: t.DefaultSettings}
+	problems := map[string]string{}
+
+	sections := []struct {
+		name string
+		data models.JSON
+		out  interface{}
+	}{
+		{"roles", t.DefaultRoles, &spec.Roles},
+		{"channels", t.DefaultChannels, &spec.Channels},
+		{"tags", t.DefaultTags, &spec.Tags},
+		{"labels", t.DefaultLabels, &spec.Labels},
+	}
+	for _, sec := range sections {
+		raw, ok := sec.data[sec.name]
+		if !ok || raw == nil {
+			continue
+		}
+		data, err := json.Marshal(raw)
+		if err != nil {
+			problems[sec.name] = err.Error()
+			continue
+		}
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(sec.out); err != nil {
+			problems[sec.name] = err.Error()
+		}
+	}
+
+	if len(problems) > 0 {
+		return nil, &TemplateValidationError{Fields: problems}
+	}
+	return spec, nil
+}
+
+// encodeTemplateSpec writes a spec into the template's columns.
+func encodeTemplateSpec(t *models.WorkspaceTemplate, spec *models.TemplateSpec) {
+	t.DefaultRoles = models.JSON{"roles": spec.Roles}
+	t.DefaultChannels = models.JSON{"channels": spec.Channels}
+	t.DefaultTags = models.JSON{"tags": spec.Tags}
+	t.DefaultLabels = models.JSON{"labels": spec.Labels}
+	t.DefaultSettings = spec.Settings
+}
+
+// validateTemplateSpec checks names are present and unique within each
+// section, colors are valid hex, and settings pass the settings schema.
+func validateTemplateSpec(spec *models.TemplateSpec) error {
+	problems := map[string]string{}
+
+	checkCount := func(section string, n, max int) {
+		if n > max {
+			problems[section] = fmt.Sprintf("at most %d allowed", max)
+		}
+	}
+	checkCount("roles", len(spec.Roles), maxTemplateRoles)
+	checkCount("channels", len(spec.Channels), maxTemplateChannels)
+	checkCount("tags", len(spec.Tags), maxTemplateTags)
+	checkCount("labels", len(spec.Labels), maxTemplateLabels)
+
+	checkName := func(section string, i int, name string, maxLen int, seen map[string]bool) {
+		path := fmt.Sprintf("%s[%d].name", section, i)
+		name = strings.TrimSpace(name)
+		switch {
+		case name == "":
+			problems[path] = "required"
+		case len(name) > maxLen:
+			problems[path] = fmt.Sprintf("must be at most %d characters", maxLen)
+		case seen[strings.ToLower(name)]:
+			problems[path] = "duplicate name"
+		}
+		seen[strings.ToLower(name)] = true
+	}
+	checkColor := func(section string, i int, color *string) {
+		if _, err := normalizeOptionalColor(color); err != nil {
+			problems[fmt.Sprintf("%s[%d].color", section, i)] = "must be a hex color"
+		}
+	}
+
+	seen := map[string]bool{}
+	for i, r := range spec.Roles {
+		checkName("roles", i, r.Name, 50, seen)
+		checkColor("roles", i, r.Color)
+	}
+	seen = map[string]bool{}
+	for i, c := range spec.Channels {
+		checkName("channels", i, c.Name, 80, seen)
+	}
+	seen = map[string]bool{}
+	for i, t := range spec.Tags {
+		checkName("tags", i, t.Name, 50, seen)
+		checkColor("tags", i, t.Color)
+	}
+	seen = map[string]bool{}
+	for i, l := range spec.Labels {
+		checkName("labels", i, l.Name, 50, seen)
+		checkColor("labels", i, &l.Color)
+	}
+
+	if err := validateSettings(spec.Settings, false); err != nil {
+		if settingsErr, ok := err.(*SettingsValidationError); ok {
+			for key, msg := range settingsErr.Fields {
+				problems["settings."+key] = msg
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		return &TemplateValidationError{Fields: problems}
+	}
+	return nil
+}
+
+// templateSpecFromWorkspace captures a workspace's roles, tags, labels and
+// settings as a template spec.
+func (s *WorkspaceService) templateSpecFromWorkspace(ctx context.Context, workspace *models.Workspace, channels []models.TemplateChannel) (*models.TemplateSpec, error) {
+	spec := &models.TemplateSpec{Channels: channels, Settings: workspace.Settings}
+
+	roles, err := s.roleRepo.ListByWorkspace(ctx, workspace.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range roles {
+		spec.Roles = append(spec.Roles, models.TemplateRole{
+			Name: r.Name, Color: r.Color, Priority: r.Priority, Permissions: r.Permissions, IsDefault: r.IsDefault,
+		})
+	}
+
+	tags, err := s.tagRepo.ListByWorkspace(ctx, workspace.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range tags {
+		spec.Tags = append(spec.Tags, models.TemplateTag{Name: t.Name, Color: t.Color})
+	}
+
+	labels, err := s.labelRepo.ListByWorkspace(ctx, workspace.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, l := range labels {
+		spec.Labels = append(spec.Labels, models.TemplateLabel{Name: l.Name, Color: l.Color, Description: l.Description})
+	}
+
+	return spec, nil
+}
+
+// templateSettings returns the spec's settings minus any key that fails the
+// settings schema, so templates saved before validation cannot push bad
+// values into a new workspace.
+func templateSettings(spec *models.TemplateSpec) models.JSON {
+	if spec.Settings == nil {
+		return nil
+	}
+	settings := make(models.JSON, len(spec.Settings))
+	for key, value := range spec.Settings {
+		if _, readOnly := readOnlySettings[key]; readOnly {
+			continue
+		}
+		if rule, known := settingsSchema[key]; known && value != nil && rule(value) != "" {
+			continue
+		}
+		settings[key] = value
+	}
+	return settings
+}
+
+// applyTemplateSpec provisions a spec's roles, tags and labels in a new
+// workspace and asks the channel service to create its channels. Individual
+// failures are logged so one bad entry does not abort the rest.
+func (s *WorkspaceService) applyTemplateSpec(ctx context.Context, workspaceID, userID, templateID uuid.UUID, spec *models.TemplateSpec) {
+	now := time.Now()
+
+	for _, r := range spec.Roles {
+		color, _ := normalizeOptionalColor(r.Color)
+		role := &models.WorkspaceRole{
+			ID:          uuid.New(),
+			WorkspaceID: workspaceID,
+			Name:        strings.TrimSpace(r.Name),
+			Color:       color,
+			Priority:    r.Priority,
+			Permissions: r.Permissions,
+			IsDefault:   r.IsDefault,
+			CreatedBy:   userID,
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		}
+		if err := s.roleRepo.Create(ctx, role); err != nil {
+			s.logger.WithError(err).WithField("role", role.Name).Warn("Failed to create role from template")
+		}
+	}
+
+	for _, t := range spec.Tags {
+		color, _ := normalizeOptionalColor(t.Color)
+		tag := &models.WorkspaceTag{
+			ID:          uuid.New(),
+			WorkspaceID: workspaceID,
+			Name:        strings.TrimSpace(t.Name),
+			Color:       color,
+			CreatedBy:   userID,
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		}
+		if err := s.tagRepo.Create(ctx, tag); err != nil {
+			s.logger.WithError(err).WithField("tag", tag.Name).Warn("Failed to create tag from template")
+		}
+	}
+
+	for i, l := range spec.Labels {
+		color, err := normalizeColor(l.Color)
+		if err != nil {
+			continue
+		}
+		label := &models.WorkspaceLabel{
+			ID:          uuid.New(),
+			WorkspaceID: workspaceID,
+			Name:        strings.TrimSpace(l.Name),
+			Color:       color,
+			Description: l.Description,
+			Position:    i + 1,
+			CreatedBy:   userID,
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		}
+		if err := s.labelRepo.Create(ctx, label); err != nil {
+			s.logger.WithError(err).WithField("label", label.Name).Warn("Failed to create label from template")
+		}
+	}
+
+	for i, c := range spec.Channels {
+		s.publishEvent(ctx, "workspace-events", workspaceID.String(), "workspace.channel_provision_requested", map[string]interface{}{
+			"workspace_id": workspaceID,
+			"template_id":  templateID,
+			"requested_by": userID,
+			"name":         strings.TrimSpace(c.Name),
+			"description":  c.Description,
+			"is_private":   c.IsPrivate,
+			"position":     i + 1,
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/quckapp/workspace-service/internal/metrics"
+	"github.com/quckapp/workspace-service/internal/models"
+	"github.com/quckapp/workspace-service/internal/repository"
+	"github.com/quckapp/workspace-service/internal/requestid"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	webhookDefaultWorkers   = 8
+	webhookDefaultQueueSize = 1000
+)
+
+type webhookJob struct {
+	webhook   *models.WorkspaceWebhook
+	payload   map[string]interface{}
+	requestID string
+}
+
+// WebhookDispatcher delivers webhook events from a bounded queue with a
+// fixed number of workers. When the queue is full new deliveries are
+// dropped and counted rather than blocking the request that raised them.
+type WebhookDispatcher struct {
+	webhookRepo *repository.WebhookRepository
+	metrics     *metrics.Metrics
+	logger      *logrus.Logger
+	workers     int
+
+	mu     sync.RWMutex
+	closed bool
+	queue  chan webhookJob
+	wg     sync.WaitGroup
+}
+
+func NewWebhookDispatcher(webhookRepo *repository.WebhookRepository, metrics *metrics.Metrics, workers, queueSize int, logger *logrus.Logger) *WebhookDispatcher {
+	if workers <= 0 {
+		workers = webhookDefaultWorkers
+	}
+	if queueSize <= 0 {
+		queueSize = webhookDefaultQueueSize
+	}
+	return &WebhookDispatcher{
+		webhookRepo: webhookRepo,
+		metrics:     metrics,
+		logger:      logger,
+		workers:     workers,
+		queue:       make(chan webhookJob, queueSize),
+	}
+}
+
+// Start launches the delivery workers.
+func (d *WebhookDispatcher) Start() {
+	for i := 0; i < d.workers; i++ {
+		d.wg.Add(1)
+		go func() {
+			defer d.wg.Done()
+			for job := range d.queue {
+				d.metrics.SetWebhookQueueDepth(len(d.queue))
+				d.deliver(job)
+			}
+		}()
+	}
+}
+
+// Stop stops accepting deliveries and waits for the queued ones to finish,
+// or for ctx to be done, whichever comes first.
+func (d *WebhookDispatcher) Stop(ctx context.Context) error {
+	d.mu.Lock()
+	if !d.closed {
+		d.closed = true
+		close(d.queue)
+	}
+	d.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("%d webhook deliveries still queued: %w", len(d.queue), ctx.Err())
+	}
+}
+
+// Enqueue schedules a delivery and reports whether it was accepted. Only
+// ctx's request ID is kept; the delivery outlives the request itself.
+func (d *WebhookDispatcher) Enqueue(ctx context.Context, webhook *models.WorkspaceWebhook, payload map[string]interface{}) bool {
+	requestID := requestid.FromContext(ctx)
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if d.closed {
+		d.dropped(webhook, requestID, "dispatcher stopped")
+		return false
+	}
+
+	select {
+	case d.queue <- webhookJob{webhook: webhook, payload: payload, requestID: requestID}:
+		d.metrics.SetWebhookQueueDepth(len(d.queue))
+		return true
+	default:
+		d.dropped(webhook, requestID, "queue full")
+		return false
+	}
+}
+
+func (d *WebhookDispatcher) dropped(webhook *models.WorkspaceWebhook, requestID, reason string) {
+	d.metrics.WebhookDropped()
+	d.logger.WithFields(logrus.Fields{
+		"webhook_id":   webhook.ID,
+		"workspace_id": webhook.WorkspaceID,
+		"request_id":   requestID,
+		"reason":       reason,
+	}).Warn("Dropped webhook delivery")
+}
+
+func (d *WebhookDispatcher) deliver(job webhookJob) {
+	ctx := requestid.NewContext(context.Background(), job.requestID)
+	err := postSignedJSON(ctx, job.webhook.URL, job.webhook.Secret, job.payload)
+	d.metrics.WebhookDelivered(err)
+	if err != nil {
+		d.webhookRepo.IncrementFailureCount(ctx, job.webhook.ID)
+		d.logger.WithError(err).WithFields(logrus.Fields{
+			"webhook_id": job.webhook.ID,
+			"request_id": job.requestID,
+		}).Warn("Failed to trigger webhook")
+		return
+	}
+	d.webhookRepo.UpdateLastTriggered(ctx, job.webhook.ID)
+	d.webhookRepo.ResetFailureCount(ctx, job.webhook.ID)
+}
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/quckapp/workspace-service/internal/models"
+	"github.com/quckapp/workspace-service/internal/repository"
+)
+
+func newTestDispatcher(t *testing.T, workers, queueSize int) *WebhookDispatcher {
+	db, _ := newTestDB(t)
+	return NewWebhookDispatcher(repository.NewWebhookRepository(db), nil, workers, queueSize, testLogger())
+}
+
+// useWebhookClient lets tests deliver to local servers, which webhookClient
+// refuses.
+func useWebhookClient(t *testing.T, client *http.Client) {
+	saved := webhookClient
+	webhookClient = client
+	t.Cleanup(func() { webhookClient = saved })
+}
+
+func TestWebhookDispatcherUsesFixedWorkers(t *testing.T) {
+	var inFlight, maxInFlight, delivered int32
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		for {
+			peak := atomic.LoadInt32(&maxInFlight)
+			if n <= peak || atomic.CompareAndSwapInt32(&maxInFlight, peak, n) {
+				break
+			}
+		}
+		<-release
+		atomic.AddInt32(&inFlight, -1)
+		atomic.AddInt32(&delivered, 1)
+	}))
+	defer server.Close()
+	useWebhookClient(t, server.Client())
+
+	const events = 20
+	d := newTestDispatcher(t, 2, events)
+	webhook := &models.WorkspaceWebhook{ID: uuid.New(), WorkspaceID: uuid.New(), URL: server.URL}
+
+	before := runtime.NumGoroutine()
+	for i := 0; i < events; i++ {
+		if !d.Enqueue(context.Background(), webhook, map[string]interface{}{"n": i}) {
+			t.Fatalf("event %d was dropped", i)
+		}
+	}
+	if after := runtime.NumGoroutine(); after > before {
+		t.Errorf("enqueueing %d events started %d goroutines, want none", events, after-before)
+	}
+
+	d.Start()
+	deadline := time.Now().Add(5 * time.Second)
+	for atomic.LoadInt32(&inFlight) < 2 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(20 * time.Millisecond)
+	if n := atomic.LoadInt32(&inFlight); n != 2 {
+		t.Errorf("%d deliveries in flight, want one per worker", n)
+	}
+	if n := len(d.queue); n != events-2 {
+		t.Errorf("%d events queued, want %d waiting for a worker", n, events-2)
+	}
+
+	close(release)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := d.Stop(ctx); err != nil {
+		t.Fatalf("Stop: %v", err)
+	}
+	if n := atomic.LoadInt32(&delivered); n != events {
+		t.Errorf("%d events delivered, want %d", n, events)
+	}
+	if n := atomic.LoadInt32(&maxInFlight); n > 2 {
+		t.Errorf("%d deliveries ran at once, want at most 2", n)
+	}
+}
+
+func TestWebhookDispatcherDropsWhenQueueFull(t *testing.T) {
+	d := newTestDispatcher(t, 1, 2)
+	webhook := &models.WorkspaceWebhook{ID: uuid.New(), URL: "https://hooks.example.com"}
+
+	for i := 0; i < 2; i++ {
+		if !d.Enqueue(context.Background(), webhook, nil) {
+			t.Fatalf("event %d dropped with room in the queue", i)
+		}
+	}
+	if d.Enqueue(context.Background(), webhook, nil) {
+		t.Error("event accepted into a full queue")
+	}
+}
+
+func TestWebhookDispatcherDropsAfterStop(t *testing.T) {
+	d := newTestDispatcher(t, 1, 10)
+	d.Start()
+	if err := d.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop: %v", err)
+	}
+	if d.Enqueue(context.Background(), &models.WorkspaceWebhook{ID: uuid.New()}, nil) {
+		t.Error("event accepted after Stop")
+	}
+}
@@ -0,0 +1,39 @@
+package service
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/quckapp/workspace-service/internal/models"
+)
+
+// webhookEventCatalog lists the event types delivered to workspace webhooks.
+// A webhook subscribes by listing any of these types in its events.
+var webhookEventCatalog = []models.WebhookEventDefinition{
+	{Type: "member.custom_role_assigned", Description: "A custom role was assigned to a member; includes the role name and permission snapshot"},
+	{Type: "member.custom_role_unassigned", Description: "A custom role was removed from a member; includes the role name and permission snapshot"},
+	{Type: "workspace.announcement.digest", Description: "Daily digest of the announcements posted in the 24 hours before the workspace's announcement_digest_time (UTC)"},
+	{Type: "webhook.test", Description: "Sent when a webhook test is requested"},
+}
+
+func (s *WorkspaceService) GetWebhookEventCatalog(ctx context.Context, workspaceID, userID uuid.UUID) ([]models.WebhookEventDefinition, error) {
+	role, _ := s.memberRepo.GetRole(ctx, workspaceID, userID)
+	if role != "owner" && role != "admin" {
+		return nil, ErrNotAuthorized
+	}
+
+	catalog := make([]models.WebhookEventDefinition, len(webhookEventCatalog))
+	copy(catalog, webhookEventCatalog)
+	return catalog, nil
+}
+
+// emitWorkspaceEvent publishes an event on the workspace topic and delivers
+// it to the workspace's subscribed webhooks.
+func (s *WorkspaceService) emitWorkspaceEvent(ctx context.Context, workspaceID uuid.UUID, eventType string, data map[string]interface{}) {
+	s.publishEvent(ctx, "workspace-events", workspaceID.String(), eventType, data)
+
+	payload := copyEventData(data)
+	payload["type"] = eventType
+	payload["workspace_id"] = workspaceID
+	s.TriggerWebhooks(ctx, workspaceID, eventType, payload)
+}
@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/quckapp/workspace-service/internal/models"
+)
+
+func TestWebhookHealthStatus(t *testing.T) {
+	tests := []struct {
+		active   bool
+		failures int
+		want     string
+	}{
+		{true, 0, "healthy"},
+		{true, webhookDegradedFailures - 1, "healthy"},
+		{true, webhookDegradedFailures, "degraded"},
+		{false, 0, "disabled"},
+		{false, webhookDegradedFailures, "disabled"},
+	}
+	for _, tt := range tests {
+		w := &models.WorkspaceWebhook{IsActive: tt.active, FailureCount: tt.failures}
+		if got := webhookHealthStatus(w); got != tt.want {
+			t.Errorf("webhookHealthStatus(active=%v, failures=%d) = %q, want %q", tt.active, tt.failures, got, tt.want)
+		}
+	}
+}
+
+func TestGetWebhookHealthCountsEachStatus(t *testing.T) {
+	s, mock := newTestService(t)
+	workspaceID := uuid.New()
+
+	expectRole(mock, "owner")
+	mock.ExpectQuery(`SELECT \* FROM workspace_webhooks WHERE workspace_id = \?`).
+		WithArgs(workspaceID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "workspace_id", "name", "url", "is_active", "failure_count"}).
+			AddRow(uuid.New().String(), workspaceID.String(), "ok", "https://a.example.com", true, 0).
+			AddRow(uuid.New().String(), workspaceID.String(), "flaky", "https://b.example.com", true, 5).
+			AddRow(uuid.New().String(), workspaceID.String(), "off", "https://c.example.com", false, 0))
+
+	report, err := s.GetWebhookHealth(context.Background(), workspaceID, uuid.New())
+	if err != nil {
+		t.Fatalf("GetWebhookHealth: %v", err)
+	}
+	if report.Healthy != 1 || report.Degraded != 1 || report.Disabled != 1 || len(report.Webhooks) != 3 {
+		t.Errorf("got %d healthy, %d degraded, %d disabled of %d, want one of each",
+			report.Healthy, report.Degraded, report.Disabled, len(report.Webhooks))
+	}
+}
+
+func TestGetWebhookHealthRequiresManagePermission(t *testing.T) {
+	s, mock := newTestService(t)
+	expectRole(mock, "member")
+
+	if _, err := s.GetWebhookHealth(context.Background(), uuid.New(), uuid.New()); err != ErrNotAuthorized {
+		t.Errorf("err = %v, want ErrNotAuthorized", err)
+	}
+}
@@ -0,0 +1,272 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/quckapp/workspace-service/internal/models"
+)
+
+const (
+	workspaceExportSchemaVersion = 1
+	workspaceExportPageSize      = 500
+)
+
+// WorkspaceExport is an authorized export of a single workspace. The document
+// is only assembled when it is written, so large workspaces are streamed
+// page by page rather than held in memory.
+type WorkspaceExport struct {
+	Workspace  *models.Workspace
+	ExportedBy uuid.UUID
+	ExportedAt time.Time
+
+	s *WorkspaceService
+}
+
+// ExportWorkspace authorizes a full data export of the workspace. Only the
+// owner may export, and only with explicit confirmation. The export is
+// recorded in the security audit log before any data is written.
+func (s *WorkspaceService) ExportWorkspace(ctx context.Context, workspaceID, userID uuid.UUID, confirmed bool) (*WorkspaceExport, error) {
+	role, _ := s.memberRepo.GetRole(ctx, workspaceID, userID)
+	if role != "owner" {
+		return nil, ErrNotAuthorized
+	}
+	if !confirmed {
+		return nil, ErrExportNotConfirmed
+	}
+
+	workspace, err := s.workspaceRepo.GetByID(ctx, workspaceID)
+	if err != nil || workspace == nil {
+		return nil, ErrWorkspaceNotFound
+	}
+
+	now := time.Now()
+	s.recordSecurityAudit(ctx, workspaceID, userID, "workspace.exported", "Full workspace data export", "warning", models.JSON{
+		"exported_at": now.UTC(),
+	})
+	s.LogActivity(ctx, workspaceID, userID, "workspace.exported", "workspace", workspaceID.String(), nil)
+
+	return &WorkspaceExport{Workspace: workspace, ExportedBy: userID, ExportedAt: now, s: s}, nil
+}
+
+// Stream writes the export as a single JSON document.
+func (e *WorkspaceExport) Stream(ctx context.Context, w io.Writer) error {
+	s := e.s
+	workspaceID := e.Workspace.ID
+	x := &exportWriter{w: w}
+
+	x.raw("{")
+	x.field("schema_version", workspaceExportSchemaVersion)
+	x.field("exported_at", e.ExportedAt.UTC())
+	x.field("exported_by", e.ExportedBy)
+	x.field("workspace", e.Workspace)
+	x.field("settings", e.Workspace.Settings)
+
+	roles, err := s.roleRepo.ListByWorkspace(ctx, workspaceID)
+	if err != nil {
+		return err
+	}
+	x.field("roles", roles)
+
+	tags, err := s.tagRepo.ListByWorkspace(ctx, workspaceID)
+	if err != nil {
+		return err
+	}
+	x.field("tags", tags)
+
+	x.beginArray("members")
+	for page := 1; x.err == nil; page++ {
+		members, _, err := s.memberRepo.ListByWorkspace(ctx, workspaceID, page, workspaceExportPageSize)
+		if err != nil {
+			return err
+		}
+		for _, m := range members {
+			x.item(m)
+		}
+		if len(members) < workspaceExportPageSize {
+			break
+		}
+	}
+	x.raw("]")
+
+	x.beginArray("announcements")
+	for page := 1; x.err == nil; page++ {
+		announcements, err := s.announcementRepo.ListAllByWorkspace(ctx, workspaceID, page, workspaceExportPageSize)
+		if err != nil {
+			return err
+		}
+		for _, a := range announcements {
+			x.item(a)
+		}
+		if len(announcements) < workspaceExportPageSize {
+			break
+		}
+	}
+	x.raw("]")
+
+	x.beginArray("activity")
+	for page := 1; x.err == nil; page++ {
+		activities, _, err := s.activityRepo.ListByWorkspace(ctx, workspaceID, page, workspaceExportPageSize)
+		if err != nil {
+			return err
+		}
+		for _, a := range activities {
+			x.item(a)
+		}
+		if len(activities) < workspaceExportPageSize {
+			break
+		}
+	}
+	x.raw("]")
+
+	x.raw("}")
+	return x.err
+}
+
+// ExportMemberData gathers everything tied to one user in the workspace for
+// subject-access requests. Owners and admins can export any member; members
+// can only export themselves.
+func (s *WorkspaceService) ExportMemberData(ctx context.Context, workspaceID, targetUserID, userID uuid.UUID) (*models.MemberDataExport, error) {
+	if targetUserID != userID {
+		role, _ := s.memberRepo.GetRole(ctx, workspaceID, userID)
+		if role != "owner" && role != "admin" {
+			return nil, ErrNotAuthorized
+		}
+	}
+
+	membership, err := s.memberRepo.GetByWorkspaceAndUser(ctx, workspaceID, targetUserID)
+	if err != nil || membership == nil {
+		return nil, ErrNotMember
+	}
+
+	export := &models.MemberDataExport{
+		WorkspaceID: workspaceID,
+		UserID:      targetUserID,
+		ExportedAt:  time.Now(),
+		Membership:  membership,
+	}
+
+	if export.Roles, err = s.roleRepo.ListByMember(ctx, workspaceID, targetUserID); err != nil {
+		return nil, err
+	}
+	if export.Profile, err = s.profileRepo.GetByWorkspaceAndUser(ctx, workspaceID, targetUserID); err != nil {
+		return nil, err
+	}
+	if export.Preferences, err = s.preferenceRepo.GetByWorkspaceAndUser(ctx, workspaceID, targetUserID); err != nil {
+		return nil, err
+	}
+	if export.NotesAboutMember, err = s.memberNoteRepo.ListByTarget(ctx, workspaceID, targetUserID); err != nil {
+		return nil, err
+	}
+	if export.Reactions, err = s.reactionRepo.ListByUserInWorkspace(ctx, workspaceID, targetUserID); err != nil {
+		return nil, err
+	}
+	if export.Bookmarks, err = s.bookmarkRepo.ListByUser(ctx, workspaceID, targetUserID); err != nil {
+		return nil, err
+	}
+
+	for page := 1; ; page++ {
+		activities, _, err := s.activityRepo.ListByActor(ctx, workspaceID, targetUserID, page, workspaceExportPageSize)
+		if err != nil {
+			return nil, err
+		}
+		export.Activity = append(export.Activity, activities...)
+		if len(activities) < workspaceExportPageSize {
+			break
+		}
+	}
+
+	checklists, err := s.onboardingRepo.ListChecklists(ctx, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	for _, cl := range checklists {
+		status, err := s.onboardingChecklistStatus(ctx, cl, targetUserID)
+		if err != nil {
+			return nil, err
+		}
+		export.Onboarding = append(export.Onboarding, status)
+	}
+
+	s.LogActivity(ctx, workspaceID, userID, "member.data_exported", "member", targetUserID.String(), nil)
+	return export, nil
+}
+
+// recordSecurityAudit writes a security audit entry and forwards it to the
+// workspace's audit sink. Failures are logged, never returned.
+func (s *WorkspaceService) recordSecurityAudit(ctx context.Context, workspaceID, userID uuid.UUID, eventType, description, severity string, metadata models.JSON) {
+	entry := &models.SecurityAuditEntry{
+		ID:          uuid.New(),
+		WorkspaceID: workspaceID,
+		UserID:      userID,
+		EventType:   eventType,
+		Description: description,
+		Severity:    severity,
+		Metadata:    metadata,
+		CreatedAt:   time.Now(),
+	}
+	if err := s.securityRepo.CreateAuditEntry(ctx, entry); err != nil {
+		s.logger.WithError(err).Warn("Failed to record security audit entry")
+		return
+	}
+	s.auditSink.Forward(ctx, workspaceID, "security_audit", entry)
+}
+
+// exportWriter writes a JSON object incrementally and remembers the first
+// error so callers can check once at the end.
+type exportWriter struct {
+	w        io.Writer
+	err      error
+	fields   int
+	arrayLen int
+}
+
+func (x *exportWriter) raw(s string) {
+	if x.err != nil {
+		return
+	}
+	_, x.err = io.WriteString(x.w, s)
+}
+
+func (x *exportWriter) value(v interface{}) {
+	if x.err != nil {
+		return
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		x.err = err
+		return
+	}
+	_, x.err = x.w.Write(data)
+}
+
+func (x *exportWriter) key(name string) {
+	if x.fields > 0 {
+		x.raw(",")
+	}
+	x.fields++
+	x.value(name)
+	x.raw(":")
+}
+
+func (x *exportWriter) field(name string, v interface{}) {
+	x.key(name)
+	x.value(v)
+}
+
+func (x *exportWriter) beginArray(name string) {
+	x.key(name)
+	x.raw("[")
+	x.arrayLen = 0
+}
+
+func (x *exportWriter) item(v interface{}) {
+	if x.arrayLen > 0 {
+		x.raw(",")
+	}
+	x.arrayLen++
+	x.value(v)
+}
@@ -0,0 +1,145 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/quckapp/workspace-service/internal/models"
+)
+
+func TestExportWriterWritesValidJSON(t *testing.T) {
+	var buf bytes.Buffer
+	x := &exportWriter{w: &buf}
+
+	x.raw("{")
+	x.field("schema_version", 1)
+	x.beginArray("members")
+	x.item(map[string]string{"role": "owner"})
+	x.item(map[string]string{"role": "member"})
+	x.raw("]")
+	x.beginArray("activity")
+	x.raw("]")
+	x.raw("}")
+	if x.err != nil {
+		t.Fatalf("write: %v", x.err)
+	}
+
+	var doc struct {
+		SchemaVersion int                 `json:"schema_version"`
+		Members       []map[string]string `json:"members"`
+		Activity      []interface{}       `json:"activity"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("export is not valid JSON: %v\n%s", err, buf.String())
+	}
+	if doc.SchemaVersion != 1 || len(doc.Members) != 2 || doc.Activity == nil || len(doc.Activity) != 0 {
+		t.Errorf("got %+v", doc)
+	}
+}
+
+type failingWriter struct{ writes int }
+
+func (f *failingWriter) Write(p []byte) (int, error) {
+	f.writes++
+	return 0, errors.New("client went away")
+}
+
+func TestExportWriterStopsAtFirstError(t *testing.T) {
+	w := &failingWriter{}
+	x := &exportWriter{w: w}
+
+	x.raw("{")
+	x.field("a", 1)
+	x.item("b")
+	if x.err == nil || w.writes != 1 {
+		t.Errorf("err = %v after %d writes, want the first error and no further writes", x.err, w.writes)
+	}
+}
+
+func TestExportWorkspaceOwnerOnly(t *testing.T) {
+	s, mock := newTestService(t)
+	expectRole(mock, "admin")
+
+	if _, err := s.ExportWorkspace(context.Background(), uuid.New(), uuid.New(), true); err != ErrNotAuthorized {
+		t.Errorf("err = %v, want ErrNotAuthorized", err)
+	}
+}
+
+func TestExportWorkspaceRequiresConfirmation(t *testing.T) {
+	s, mock := newTestService(t)
+	expectRole(mock, "owner")
+
+	if _, err := s.ExportWorkspace(context.Background(), uuid.New(), uuid.New(), false); err != ErrExportNotConfirmed {
+		t.Errorf("err = %v, want ErrExportNotConfirmed", err)
+	}
+}
+
+func TestExportMemberDataOtherMemberNeedsAnalytics(t *testing.T) {
+	s, mock := newTestService(t)
+	expectRole(mock, "member")
+
+	if _, err := s.ExportMemberData(context.Background(), uuid.New(), uuid.New(), uuid.New()); err != ErrNotAuthorized {
+		t.Errorf("err = %v, want ErrNotAuthorized", err)
+	}
+}
+
+func TestExportMemberDataSelfSkipsPermissionCheck(t *testing.T) {
+	s, mock := newTestService(t)
+	workspaceID, userID := uuid.New(), uuid.New()
+
+	// No role lookup: the first query is the membership fetch.
+	mock.ExpectQuery(`SELECT \* FROM workspace_members WHERE workspace_id = \? AND user_id = \?`).
+		WithArgs(workspaceID, userID).
+		WillReturnError(sql.ErrNoRows)
+
+	if _, err := s.ExportMemberData(context.Background(), workspaceID, userID, userID); err != ErrNotMember {
+		t.Errorf("err = %v, want ErrNotMember", err)
+	}
+}
+
+func TestExportStreamIncludesExpiredAndScheduledAnnouncements(t *testing.T) {
+	s, mock := newTestService(t)
+	workspaceID, expiredID, scheduledID := uuid.New(), uuid.New(), uuid.New()
+	now := time.Now()
+
+	mock.ExpectQuery(`SELECT \* FROM workspace_roles WHERE workspace_id = \?`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectQuery(`SELECT \* FROM workspace_tags WHERE workspace_id = \?`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM workspace_members`).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectQuery(`SELECT \* FROM workspace_members WHERE workspace_id = \?`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectQuery(`SELECT \* FROM workspace_announcements WHERE workspace_id = \? ORDER BY created_at ASC, id ASC LIMIT \? OFFSET \?`).
+		WithArgs(workspaceID, workspaceExportPageSize, 0).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "workspace_id", "title", "expires_at", "is_published", "publish_at"}).
+			AddRow(expiredID.String(), workspaceID.String(), "Expired", now.Add(-time.Hour), true, nil).
+			AddRow(scheduledID.String(), workspaceID.String(), "Scheduled", nil, false, now.Add(time.Hour)))
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM workspace_activity_log`).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectQuery(`SELECT \* FROM workspace_activity_log WHERE workspace_id = \?`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	export := &WorkspaceExport{Workspace: &models.Workspace{ID: workspaceID}, ExportedAt: now, s: s}
+	var buf bytes.Buffer
+	if err := export.Stream(context.Background(), &buf); err != nil {
+		t.Fatalf("Stream: %v", err)
+	}
+
+	var doc struct {
+		Announcements []models.WorkspaceAnnouncement `json:"announcements"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("export is not valid JSON: %v", err)
+	}
+	if len(doc.Announcements) != 2 || doc.Announcements[0].ID != expiredID || doc.Announcements[1].ID != scheduledID {
+		t.Errorf("exported %+v, want the expired and the scheduled announcement", doc.Announcements)
+	}
+}
@@ -0,0 +1,215 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/quckapp/workspace-service/internal/models"
+)
+
+// IconConfig restricts where workspace icons may be served from and how
+// large they may be.
+type IconConfig struct {
+	AllowedHosts []string // a leading "." also matches subdomains
+	MaxBytes     int64
+}
+
+// allowsHost reports whether host is on the allowlist.
+func (c IconConfig) allowsHost(host string) bool {
+	host = strings.ToLower(host)
+	for _, allowed := range c.AllowedHosts {
+		allowed = strings.ToLower(strings.TrimSpace(allowed))
+		if strings.HasPrefix(allowed, ".") {
+			if strings.HasSuffix(host, allowed) || host == allowed[1:] {
+				return true
+			}
+		} else if host == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// iconContentTypes are the image types accepted as icons. SVG is left out
+// because it can carry script.
+var iconContentTypes = map[string]bool{
+	"image/png":  true,
+	"image/jpeg": true,
+	"image/gif":  true,
+	"image/webp": true,
+}
+
+// iconClient checks icons before they are accepted. Redirects are not
+// followed so the checked URL is the one on the allowlist.
+var iconClient = &http.Client{
+	Timeout: 5 * time.Second,
+	CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	},
+}
+
+// validateIconURL accepts only https URLs on an allowed host, which rules
+// out javascript: and data: URIs along with everything else.
+func (s *WorkspaceService) validateIconURL(raw string) (*url.URL, error) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || u.Scheme != "https" || u.Host == "" || u.User != nil {
+		return nil, ErrInvalidIconURL
+	}
+	if !s.iconConfig.allowsHost(u.Hostname()) {
+		return nil, ErrInvalidIconURL
+	}
+	return u, nil
+}
+
+// checkIcon validates the icon URL and then asks the host for the image's
+// content type and size. The icon is refused unless the host reports an
+// accepted image type and, when MaxBytes is set, a size within it.
+func (s *WorkspaceService) checkIcon(ctx context.Context, raw string) error {
+	u, err := s.validateIconURL(raw)
+	if err != nil {
+		return err
+	}
+
+	contentType, size, err := probeIcon(ctx, u)
+	if err != nil {
+		return ErrInvalidIcon
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil || !iconContentTypes[mediaType] {
+		return ErrInvalidIcon
+	}
+	if s.iconConfig.MaxBytes > 0 && (size < 0 || size > s.iconConfig.MaxBytes) {
+		return ErrInvalidIcon
+	}
+	return nil
+}
+
+// probeIcon returns the content type and size the host reports for the
+// icon, with a size of -1 when it reports none. Hosts that refuse HEAD are
+// asked for the first byte instead, whose Content-Range carries the size.
+func probeIcon(ctx context.Context, u *url.URL) (string, int64, error) {
+	resp, err := requestIcon(ctx, http.MethodHead, u)
+	if err != nil {
+		return "", 0, err
+	}
+	if resp.StatusCode == http.StatusMethodNotAllowed || resp.StatusCode == http.StatusNotImplemented {
+		if resp, err = requestIcon(ctx, http.MethodGet, u); err != nil {
+			return "", 0, err
+		}
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return "", 0, fmt.Errorf("icon host returned status %d", resp.StatusCode)
+	}
+
+	size := resp.ContentLength
+	if resp.StatusCode == http.StatusPartialContent {
+		size = contentRangeSize(resp.Header.Get("Content-Range"))
+	}
+	return resp.Header.Get("Content-Type"), size, nil
+}
+
+// requestIcon sends a HEAD, or a GET for the first byte only, and closes
+// the body unread.
+func requestIcon(ctx context.Context, method string, u *url.URL) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	if method == http.MethodGet {
+		req.Header.Set("Range", "bytes=0-0")
+	}
+	resp, err := iconClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+	return resp, nil
+}
+
+// contentRangeSize returns the complete length from a Content-Range header
+// such as "bytes 0-0/2048", or -1 when it is missing or unknown.
+func contentRangeSize(header string) int64 {
+	i := strings.LastIndex(header, "/")
+	if i < 0 {
+		return -1
+	}
+	size, err := strconv.ParseInt(header[i+1:], 10, 64)
+	if err != nil || size < 0 {
+		return -1
+	}
+	return size
+}
+
+// SetWorkspaceIcon sets the workspace icon after checking it, along with an
+// optional thumbnail kept in the icon_thumbnail_url setting.
+func (s *WorkspaceService) SetWorkspaceIcon(ctx context.Context, workspaceID, userID uuid.UUID, req *models.SetWorkspaceIconRequest) (*models.Workspace, error) {
+	workspace, err := s.workspaceRepo.GetByID(ctx, workspaceID)
+	if err != nil || workspace == nil {
+		return nil, ErrWorkspaceNotFound
+	}
+
+	role, _ := s.memberRepo.GetRole(ctx, workspaceID, userID)
+	if role != "owner" && role != "admin" {
+		return nil, ErrNotAuthorized
+	}
+
+	if err := s.checkIcon(ctx, req.IconURL); err != nil {
+		return nil, err
+	}
+	if req.ThumbnailURL != nil {
+		if err := s.checkIcon(ctx, *req.ThumbnailURL); err != nil {
+			return nil, err
+		}
+	}
+
+	iconURL := strings.TrimSpace(req.IconURL)
+	var thumbnailURL interface{}
+	if req.ThumbnailURL != nil {
+		thumbnailURL = strings.TrimSpace(*req.ThumbnailURL)
+	}
+	return s.saveWorkspaceIcon(ctx, workspace, userID, &iconURL, thumbnailURL)
+}
+
+// RemoveWorkspaceIcon clears the workspace icon and its thumbnail.
+func (s *WorkspaceService) RemoveWorkspaceIcon(ctx context.Context, workspaceID, userID uuid.UUID) (*models.Workspace, error) {
+	workspace, err := s.workspaceRepo.GetByID(ctx, workspaceID)
+	if err != nil || workspace == nil {
+		return nil, ErrWorkspaceNotFound
+	}
+
+	role, _ := s.memberRepo.GetRole(ctx, workspaceID, userID)
+	if role != "owner" && role != "admin" {
+		return nil, ErrNotAuthorized
+	}
+
+	return s.saveWorkspaceIcon(ctx, workspace, userID, nil, nil)
+}
+
+// saveWorkspaceIcon stores the icon and replaces the thumbnail setting; a
+// nil thumbnail removes it.
+func (s *WorkspaceService) saveWorkspaceIcon(ctx context.Context, workspace *models.Workspace, userID uuid.UUID, iconURL *string, thumbnailURL interface{}) (*models.Workspace, error) {
+	workspace.IconURL = iconURL
+	if err := s.saveWorkspaceSettings(ctx, workspace, userID, withSetting(workspace.Settings, settingIconThumbnailURL, thumbnailURL)); err != nil {
+		return nil, err
+	}
+
+	s.invalidateWorkspace(ctx, workspace.ID)
+
+	action := "workspace.icon_updated"
+	if iconURL == nil {
+		action = "workspace.icon_removed"
+	}
+	s.LogActivity(ctx, workspace.ID, userID, action, "workspace", workspace.ID.String(), models.JSON{"icon_url": iconURL, "thumbnail_url": thumbnailURL})
+	s.publishEvent(ctx, "workspace-events", workspace.ID.String(), "workspace.updated", map[string]interface{}{
+		"workspace":  workspace,
+		"updated_by": userID,
+	})
+	return workspace, nil
+}
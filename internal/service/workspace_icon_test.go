@@ -0,0 +1,137 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestValidateIconURL(t *testing.T) {
+	s, _ := newTestService(t)
+	s.iconConfig = IconConfig{AllowedHosts: []string{"cdn.example.com", ".images.example.org"}}
+
+	tests := map[string]bool{
+		"https://cdn.example.com/logo.png":       true,
+		" https://cdn.example.com/logo.png ":     true,
+		"https://a.images.example.org/logo.png":  true,
+		"https://images.example.org/logo.png":    true,
+		"http://cdn.example.com/logo.png":        false,
+		"javascript:alert(1)":                    false,
+		"data:image/png;base64,iVBORw0KGgo=":     false,
+		"https://evil.example.net/logo.png":      false,
+		"https://cdn.example.com.evil.net/a.png": false,
+		"https://user@cdn.example.com/logo.png":  false,
+		"//cdn.example.com/logo.png":             false,
+		"https://notimages.example.org/logo.png": false,
+	}
+	for raw, ok := range tests {
+		_, err := s.validateIconURL(raw)
+		if ok && err != nil {
+			t.Errorf("%q: err = %v, want accepted", raw, err)
+		}
+		if !ok && err != ErrInvalidIconURL {
+			t.Errorf("%q: err = %v, want ErrInvalidIconURL", raw, err)
+		}
+	}
+}
+
+// newIconHost serves handler over TLS, allowlists it and points iconClient
+// at it for the rest of the test.
+func newIconHost(t *testing.T, s *WorkspaceService, handler http.HandlerFunc) string {
+	server := httptest.NewTLSServer(handler)
+	t.Cleanup(server.Close)
+	saved := iconClient
+	iconClient = server.Client()
+	t.Cleanup(func() { iconClient = saved })
+
+	u, _ := url.Parse(server.URL)
+	s.iconConfig = IconConfig{AllowedHosts: []string{u.Hostname()}, MaxBytes: 4096}
+	return server.URL + "/logo.png"
+}
+
+func TestCheckIcon(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		wantErr error
+	}{
+		{"png within limit", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "image/png")
+			w.Header().Set("Content-Length", "2048")
+		}, nil},
+		{"svg", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "image/svg+xml")
+			w.Header().Set("Content-Length", "2048")
+		}, ErrInvalidIcon},
+		{"no content type", func(w http.ResponseWriter, r *http.Request) {
+			w.Header()["Content-Type"] = nil
+			w.Header().Set("Content-Length", "2048")
+		}, ErrInvalidIcon},
+		{"too large", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "image/png")
+			w.Header().Set("Content-Length", "4097")
+		}, ErrInvalidIcon},
+		{"no length", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "image/png")
+		}, ErrInvalidIcon},
+		{"not found", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+		}, ErrInvalidIcon},
+		{"HEAD refused, ranged GET within limit", func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodHead {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+			if r.Header.Get("Range") != "bytes=0-0" {
+				t.Errorf("fallback GET sent Range %q", r.Header.Get("Range"))
+			}
+			w.Header().Set("Content-Type", "image/webp")
+			w.Header().Set("Content-Range", "bytes 0-0/2048")
+			w.WriteHeader(http.StatusPartialContent)
+			w.Write([]byte{0})
+		}, nil},
+		{"HEAD refused, ranged GET too large", func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodHead {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+			w.Header().Set("Content-Type", "image/png")
+			w.Header().Set("Content-Range", "bytes 0-0/1048576")
+			w.WriteHeader(http.StatusPartialContent)
+			w.Write([]byte{0})
+		}, ErrInvalidIcon},
+		{"HEAD refused, ranged GET without type", func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodHead {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+			w.Header()["Content-Type"] = nil
+			w.Header().Set("Content-Range", "bytes 0-0/2048")
+			w.WriteHeader(http.StatusPartialContent)
+			w.Write([]byte{0})
+		}, ErrInvalidIcon},
+	}
+	for _, tt := range tests {
+		s, _ := newTestService(t)
+		iconURL := newIconHost(t, s, tt.handler)
+		if err := s.checkIcon(context.Background(), iconURL); err != tt.wantErr {
+			t.Errorf("%s: err = %v, want %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestContentRangeSize(t *testing.T) {
+	tests := map[string]int64{
+		"bytes 0-0/2048": 2048,
+		"bytes 0-0/*":    -1,
+		"":               -1,
+		"bytes 0-0/-5":   -1,
+	}
+	for header, want := range tests {
+		if got := contentRangeSize(header); got != want {
+			t.Errorf("contentRangeSize(%q) = %d, want %d", header, got, want)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	cacheKeyWorkspaceLock = "workspace:%s:lock"
+
+	// workspaceLockTTL bounds how long a crashed holder can block others.
+	// Operations run under the lock must finish well within it.
+	workspaceLockTTL  = 30 * time.Second
+	workspaceLockWait = 5 * time.Second
+	workspaceLockPoll = 100 * time.Millisecond
+)
+
+// WithWorkspaceLock runs fn while holding a per-workspace advisory lock so
+// owner-level changes (ownership transfer, archive, restore, delete) on the
+// same workspace run one at a time across replicas. The lock lives in Redis;
+// without Redis it falls back to a MySQL named lock. If the lock cannot be
+// taken within workspaceLockWait, ErrWorkspaceBusy is returned and fn is not
+// run.
+func (s *WorkspaceService) WithWorkspaceLock(ctx context.Context, workspaceID uuid.UUID, fn func(ctx context.Context) error) error {
+	key := fmt.Sprintf(cacheKeyWorkspaceLock, workspaceID.String())
+	token := uuid.New().String()
+	deadline := time.Now().Add(workspaceLockWait)
+
+	for {
+		acquired, err := s.kv.SetNX(ctx, key, token, workspaceLockTTL)
+		if err == errKVUnavailable {
+			return s.withDBWorkspaceLock(ctx, key, fn)
+		}
+		if err != nil {
+			return err
+		}
+		if acquired {
+			break
+		}
+		if time.Now().After(deadline) {
+			return ErrWorkspaceBusy
+		}
+		if !sleepCtx(ctx, workspaceLockPoll) {
+			return ctx.Err()
+		}
+	}
+
+	defer func() {
+		if err := s.kv.DelIfEqual(context.Background(), key, token); err != nil {
+			s.requestLogger(ctx).WithError(err).WithField("workspace_id", workspaceID).Warn("Failed to release workspace lock")
+		}
+	}()
+	return fn(ctx)
+}
+
+func (s *WorkspaceService) withDBWorkspaceLock(ctx context.Context, name string, fn func(ctx context.Context) error) error {
+	release, acquired, err := s.workspaceRepo.AcquireNamedLock(ctx, name, workspaceLockWait)
+	if err != nil {
+		return err
+	}
+	if !acquired {
+		return ErrWorkspaceBusy
+	}
+	defer release()
+	return fn(ctx)
+}
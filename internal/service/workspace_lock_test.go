@@ -0,0 +1,191 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+)
+
+func TestWithWorkspaceLockSerializesCallers(t *testing.T) {
+	s, _ := newTestService(t)
+	s.kv = newMemKVStore()
+	workspaceID := uuid.New()
+
+	entered, release := make(chan struct{}), make(chan struct{})
+	first := make(chan error, 1)
+	go func() {
+		first <- s.WithWorkspaceLock(context.Background(), workspaceID, func(ctx context.Context) error {
+			close(entered)
+			<-release
+			return nil
+		})
+	}()
+	<-entered
+
+	secondRan := make(chan struct{})
+	second := make(chan error, 1)
+	go func() {
+		second <- s.WithWorkspaceLock(context.Background(), workspaceID, func(ctx context.Context) error {
+			close(secondRan)
+			return nil
+		})
+	}()
+
+	select {
+	case <-secondRan:
+		t.Fatal("second caller ran while the lock was held")
+	case <-time.After(3 * workspaceLockPoll):
+	}
+
+	close(release)
+	if err := <-first; err != nil {
+		t.Fatalf("first caller: %v", err)
+	}
+	if err := <-second; err != nil {
+		t.Fatalf("second caller: %v", err)
+	}
+}
+
+func TestWithWorkspaceLockIsPerWorkspace(t *testing.T) {
+	s, _ := newTestService(t)
+	s.kv = newMemKVStore()
+	other := uuid.New()
+
+	err := s.WithWorkspaceLock(context.Background(), uuid.New(), func(ctx context.Context) error {
+		return s.WithWorkspaceLock(ctx, other, func(ctx context.Context) error { return nil })
+	})
+	if err != nil {
+		t.Errorf("lock on another workspace: %v", err)
+	}
+}
+
+func TestWithWorkspaceLockReleasesAfterError(t *testing.T) {
+	s, _ := newTestService(t)
+	kv := newMemKVStore()
+	s.kv = kv
+	workspaceID := uuid.New()
+	failed := errors.New("failed")
+
+	if err := s.WithWorkspaceLock(context.Background(), workspaceID, func(ctx context.Context) error { return failed }); err != failed {
+		t.Fatalf("err = %v, want the callback's error", err)
+	}
+	if kv.Exists(context.Background(), fmt.Sprintf(cacheKeyWorkspaceLock, workspaceID.String())) {
+		t.Error("lock still held after the callback returned")
+	}
+}
+
+func TestWithWorkspaceLockGivesUpWhenContextEnds(t *testing.T) {
+	s, _ := newTestService(t)
+	kv := newMemKVStore()
+	s.kv = kv
+	workspaceID := uuid.New()
+	key := fmt.Sprintf(cacheKeyWorkspaceLock, workspaceID.String())
+	kv.Set(context.Background(), key, "held-elsewhere", workspaceLockTTL)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*workspaceLockPoll)
+	defer cancel()
+	err := s.WithWorkspaceLock(ctx, workspaceID, func(ctx context.Context) error {
+		t.Error("callback ran without the lock")
+		return nil
+	})
+	if err != context.DeadlineExceeded {
+		t.Errorf("err = %v, want context.DeadlineExceeded", err)
+	}
+	if got, _ := kv.Get(context.Background(), key); string(got) != "held-elsewhere" {
+		t.Errorf("lock holder = %q, want the other holder's lock left alone", got)
+	}
+}
+
+func TestWithWorkspaceLockFallsBackToNamedLock(t *testing.T) {
+	s, mock := newTestService(t)
+	workspaceID := uuid.New()
+	key := fmt.Sprintf(cacheKeyWorkspaceLock, workspaceID.String())
+
+	mock.ExpectQuery(`SELECT GET_LOCK\(\?, \?\)`).
+		WithArgs(key, int(workspaceLockWait.Seconds())).
+		WillReturnRows(sqlmock.NewRows([]string{"lock"}).AddRow(1))
+	mock.ExpectExec(`SELECT RELEASE_LOCK\(\?\)`).
+		WithArgs(key).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	ran := false
+	err := s.WithWorkspaceLock(context.Background(), workspaceID, func(ctx context.Context) error {
+		ran = true
+		return nil
+	})
+	if err != nil || !ran {
+		t.Errorf("err = %v, ran = %v, want the callback run under the named lock", err, ran)
+	}
+}
+
+func TestWithWorkspaceLockNamedLockBusy(t *testing.T) {
+	s, mock := newTestService(t)
+
+	mock.ExpectQuery(`SELECT GET_LOCK`).
+		WillReturnRows(sqlmock.NewRows([]string{"lock"}).AddRow(0))
+
+	err := s.WithWorkspaceLock(context.Background(), uuid.New(), func(ctx context.Context) error {
+		t.Error("callback ran without the lock")
+		return nil
+	})
+	if err != ErrWorkspaceBusy {
+		t.Errorf("err = %v, want ErrWorkspaceBusy", err)
+	}
+}
+
+func expectOwnedWorkspace(mock sqlmock.Sqlmock, workspaceID, ownerID uuid.UUID) *sqlmock.ExpectedQuery {
+	return mock.ExpectQuery(`SELECT \* FROM workspaces WHERE id = \? AND deleted_at IS NULL`).
+		WithArgs(workspaceID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "slug", "owner_id"}).
+			AddRow(workspaceID.String(), "Acme", "acme", ownerID.String()))
+}
+
+// Both transfers read the owner before writing it. sqlmock matches queries
+// in order, so if the second read ran before the first transfer finished it
+// would hit the membership check and fail.
+func TestConcurrentOwnershipTransfersAreSerialized(t *testing.T) {
+	s, mock := newTestService(t)
+	s.kv = newMemKVStore()
+	workspaceID, ownerID, newOwnerID := uuid.New(), uuid.New(), uuid.New()
+
+	expectOwnedWorkspace(mock, workspaceID, ownerID).WillDelayFor(50 * time.Millisecond)
+	expectMember(mock, true)
+	mock.ExpectQuery(`SELECT \* FROM workspace_ownership_transfers WHERE workspace_id = \? AND status = 'pending'`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectExec(`UPDATE workspaces SET owner_id = \?`).
+		WithArgs(newOwnerID, sqlmock.AnyArg(), workspaceID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE workspace_members SET role = \?`).
+		WithArgs("owner", sqlmock.AnyArg(), workspaceID, newOwnerID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE workspace_members SET role = \?`).
+		WithArgs("admin", sqlmock.AnyArg(), workspaceID, ownerID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	// The activity log write is not expected and is dropped; the second
+	// transfer then finds the ownership already moved.
+	expectOwnedWorkspace(mock, workspaceID, newOwnerID)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 2)
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- s.TransferOwnership(context.Background(), workspaceID, uuid.New(), newOwnerID)
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Errorf("TransferOwnership: %v", err)
+		}
+	}
+}
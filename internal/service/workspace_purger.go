@@ -0,0 +1,125 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	purgeInterval  = time.Hour
+	purgeBatchSize = 100
+)
+
+// PurgeDeletedWorkspaces permanently deletes workspaces that were soft-deleted
+// longer than retention ago. Each purge is recorded in workspace_purge_log,
+// which outlives the workspace, and published as workspace.purged. A
+// workspace that fails to purge is logged and left for the next pass. In
+// dry-run mode it only logs what would be purged.
+func (s *WorkspaceService) PurgeDeletedWorkspaces(ctx context.Context, retention time.Duration, dryRun bool) ([]uuid.UUID, error) {
+	cutoff := time.Now().Add(-retention)
+	var purged []uuid.UUID
+
+	for {
+		workspaces, err := s.workspaceRepo.ListDeletedBefore(ctx, cutoff, purgeBatchSize)
+		if err != nil {
+			return purged, err
+		}
+
+		failed := 0
+		for _, ws := range workspaces {
+			fields := logrus.Fields{
+				"workspace_id": ws.ID,
+				"slug":         ws.Slug,
+				"owner_id":     ws.OwnerID,
+				"deleted_at":   ws.DeletedAt,
+			}
+			if dryRun {
+				s.logger.WithFields(fields).Info("Would purge deleted workspace (dry run)")
+				purged = append(purged, ws.ID)
+				continue
+			}
+
+			if err := s.workspaceRepo.HardDelete(ctx, ws, time.Now()); err != nil {
+				s.logger.WithError(err).WithFields(fields).Error("Failed to purge deleted workspace")
+				failed++
+				continue
+			}
+			s.invalidateWorkspace(ctx, ws.ID)
+			s.logger.WithFields(fields).Info("Purged deleted workspace")
+			s.publishEvent(ctx, "workspace-events", ws.ID.String(), "workspace.purged", map[string]interface{}{
+				"workspace_id": ws.ID,
+				"owner_id":     ws.OwnerID,
+				"deleted_at":   ws.DeletedAt,
+			})
+			purged = append(purged, ws.ID)
+		}
+
+		// Dry runs leave the rows in place, and so do failed purges, so
+		// paging again would repeat them; the next pass retries failures.
+		if dryRun || failed > 0 || len(workspaces) < purgeBatchSize {
+			return purged, nil
+		}
+	}
+}
+
+// WorkspacePurger periodically purges workspaces past their retention window.
+type WorkspacePurger struct {
+	workspaces *WorkspaceService
+	retention  time.Duration
+	dryRun     bool
+	logger     *logrus.Logger
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+func NewWorkspacePurger(workspaces *WorkspaceService, retentionDays int, dryRun bool, logger *logrus.Logger) *WorkspacePurger {
+	return &WorkspacePurger{
+		workspaces: workspaces,
+		retention:  time.Duration(retentionDays) * 24 * time.Hour,
+		dryRun:     dryRun,
+		logger:     logger,
+		stop:       make(chan struct{}),
+		done:       make(chan struct{}),
+	}
+}
+
+// Start begins purging on a fixed interval.
+func (p *WorkspacePurger) Start() {
+	go func() {
+		defer close(p.done)
+		ticker := time.NewTicker(purgeInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				p.purge()
+			case <-p.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the purge loop, waiting for a running purge to finish or for
+// ctx to be done, whichever comes first.
+func (p *WorkspacePurger) Stop(ctx context.Context) error {
+	close(p.stop)
+	return waitDone(ctx, p.done, "workspace purger")
+}
+
+func (p *WorkspacePurger) purge() {
+	purged, err := p.workspaces.PurgeDeletedWorkspaces(context.Background(), p.retention, p.dryRun)
+	if err != nil {
+		p.logger.WithError(err).Warn("Failed to purge deleted workspaces")
+	}
+	if len(purged) > 0 {
+		p.logger.WithFields(logrus.Fields{
+			"count":   len(purged),
+			"dry_run": p.dryRun,
+		}).Info("Deleted workspace purge finished")
+	}
+}
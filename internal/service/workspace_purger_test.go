@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+)
+
+func expectDeletedWorkspaces(mock sqlmock.Sqlmock, ids ...uuid.UUID) {
+	rows := sqlmock.NewRows([]string{"id", "slug", "owner_id", "deleted_at"})
+	for _, id := range ids {
+		rows.AddRow(id.String(), "ws-"+id.String()[:8], uuid.New().String(), time.Now().AddDate(0, 0, -60))
+	}
+	mock.ExpectQuery(`SELECT \* FROM workspaces WHERE deleted_at IS NOT NULL AND deleted_at < \?`).
+		WillReturnRows(rows)
+}
+
+func TestPurgeDeletedWorkspacesSkipsFailures(t *testing.T) {
+	s, mock := newTestService(t)
+	failing, ok := uuid.New(), uuid.New()
+
+	expectDeletedWorkspaces(mock, failing, ok)
+	mock.ExpectBegin().WillReturnError(errors.New("too many connections"))
+	mock.ExpectBegin()
+	mock.ExpectExec(`DELETE FROM workspaces WHERE id = \?`).
+		WithArgs(ok).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO workspace_purge_log`).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	// With a failure in the batch no second page is read, so the failed
+	// workspace is not retried until the next pass.
+	purged, err := s.PurgeDeletedWorkspaces(context.Background(), 30*24*time.Hour, false)
+	if err != nil {
+		t.Fatalf("PurgeDeletedWorkspaces: %v", err)
+	}
+	if len(purged) != 1 || purged[0] != ok {
+		t.Errorf("purged %v, want only %v", purged, ok)
+	}
+}
+
+func TestPurgeDeletedWorkspacesDryRunDeletesNothing(t *testing.T) {
+	s, mock := newTestService(t)
+	a, b := uuid.New(), uuid.New()
+	expectDeletedWorkspaces(mock, a, b)
+
+	purged, err := s.PurgeDeletedWorkspaces(context.Background(), 30*24*time.Hour, true)
+	if err != nil {
+		t.Fatalf("PurgeDeletedWorkspaces: %v", err)
+	}
+	if len(purged) != 2 {
+		t.Errorf("dry run reported %d workspaces, want 2", len(purged))
+	}
+}
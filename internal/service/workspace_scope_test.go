@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/quckapp/workspace-service/internal/models"
+	"github.com/quckapp/workspace-service/internal/repository"
+)
+
+// An ID that belongs to another workspace is reported as not found, the
+// same as an ID that does not exist, so responses do not reveal it.
+func TestResourcesOfAnotherWorkspaceAreNotFound(t *testing.T) {
+	tests := []struct {
+		name    string
+		table   string
+		call    func(s *WorkspaceService, workspaceID, id, userID uuid.UUID) error
+		wantErr error
+	}{
+		{"invite", "workspace_invites", func(s *WorkspaceService, workspaceID, id, userID uuid.UUID) error {
+			return s.RevokeInvite(context.Background(), workspaceID, id, userID)
+		}, ErrInviteNotFound},
+		{"role", "workspace_roles", func(s *WorkspaceService, workspaceID, id, userID uuid.UUID) error {
+			_, err := s.UpdateRole(context.Background(), workspaceID, id, userID, &models.UpdateRoleRequest{})
+			return err
+		}, ErrRoleNotFound},
+		{"tag", "workspace_tags", func(s *WorkspaceService, workspaceID, id, userID uuid.UUID) error {
+			return s.DeleteTag(context.Background(), workspaceID, id, userID)
+		}, ErrTagNotFound},
+		{"announcement", "workspace_announcements", func(s *WorkspaceService, workspaceID, id, userID uuid.UUID) error {
+			return s.DeleteAnnouncement(context.Background(), workspaceID, id, userID)
+		}, ErrAnnouncementNotFound},
+		{"webhook", "workspace_webhooks", func(s *WorkspaceService, workspaceID, id, userID uuid.UUID) error {
+			return s.DeleteWebhook(context.Background(), workspaceID, id, userID)
+		}, ErrWebhookNotFound},
+	}
+	for _, tt := range tests {
+		s, mock := newTestService(t)
+		id := uuid.New()
+
+		expectRole(mock, "owner")
+		mock.ExpectQuery(`SELECT \* FROM ` + tt.table + ` WHERE id = \?`).
+			WithArgs(id).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "workspace_id"}).AddRow(id.String(), uuid.New().String()))
+
+		if err := tt.call(s, uuid.New(), id, uuid.New()); err != tt.wantErr {
+			t.Errorf("%s: err = %v, want %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestMemberNoteOfAnotherWorkspaceIsNotFound(t *testing.T) {
+	s, mock := newTestService(t)
+	noteID, authorID := uuid.New(), uuid.New()
+
+	mock.ExpectQuery(`SELECT \* FROM workspace_member_notes WHERE id = \?`).
+		WithArgs(noteID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "workspace_id", "author_id"}).
+			AddRow(noteID.String(), uuid.New().String(), authorID.String()))
+
+	if err := s.DeleteMemberNote(context.Background(), uuid.New(), noteID, authorID); err != ErrMemberNoteNotFound {
+		t.Errorf("err = %v, want ErrMemberNoteNotFound", err)
+	}
+}
+
+func TestEmojiOfAnotherWorkspaceIsNotFound(t *testing.T) {
+	db, mock := newTestDB(t)
+	s := NewEmojiService(repository.NewEmojiRepository(db), repository.NewMemberRepository(db), repository.NewReactionRepository(db), nil, testLogger())
+	emojiID := uuid.New()
+
+	mock.ExpectQuery(`SELECT \* FROM workspace_custom_emojis WHERE id = \?`).
+		WithArgs(emojiID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "workspace_id", "name"}).
+			AddRow(emojiID.String(), uuid.New().String(), "party"))
+
+	if _, err := s.GetEmoji(context.Background(), uuid.New(), emojiID); err != ErrEmojiNotFound {
+		t.Errorf("err = %v, want ErrEmojiNotFound", err)
+	}
+}
@@ -6,52 +6,77 @@ import (
 	"crypto/hmac"
 	"crypto/rand"
 	"crypto/sha256"
+	"encoding/binary"
 	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"math/big"
+	"net"
 	"net/http"
+	"net/url"
+	"sort"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/quckapp/workspace-service/internal/db"
+	"github.com/quckapp/workspace-service/internal/metrics"
 	"github.com/quckapp/workspace-service/internal/models"
 	"github.com/quckapp/workspace-service/internal/repository"
+	"github.com/quckapp/workspace-service/internal/requestid"
 	"github.com/redis/go-redis/v9"
 	"github.com/sirupsen/logrus"
 )
 
 var (
-	ErrWorkspaceNotFound  = errors.New("workspace not found")
-	ErrSlugExists         = errors.New("slug already exists")
-	ErrNotMember          = errors.New("not a member of this workspace")
-	ErrNotAuthorized      = errors.New("not authorized")
-	ErrInviteNotFound     = errors.New("invite not found or expired")
-	ErrAlreadyMember      = errors.New("already a member")
-	ErrInviteCodeNotFound = errors.New("invite code not found or expired")
-	ErrInviteCodeMaxUsed  = errors.New("invite code has reached max uses")
-	ErrCannotLeaveAsOwner = errors.New("owner cannot leave workspace, transfer ownership first")
-	ErrRoleNotFound        = errors.New("role not found")
-	ErrRoleNameExists      = errors.New("role name already exists in this workspace")
-	ErrCannotDeleteDefault = errors.New("cannot delete default role")
-	ErrTemplateNotFound    = errors.New("template not found")
-	ErrTagNotFound         = errors.New("tag not found")
-	ErrTagNameExists       = errors.New("tag name already exists in this workspace")
-	ErrUserBanned          = errors.New("user is banned from this workspace")
-	ErrUserNotBanned       = errors.New("user is not banned")
-	ErrUserNotMuted        = errors.New("user is not muted")
-	ErrCannotBanOwner      = errors.New("cannot ban workspace owner")
-	ErrCannotMuteOwner     = errors.New("cannot mute workspace owner")
+	ErrWorkspaceNotFound       = errors.New("workspace not found")
+	ErrSlugExists              = errors.New("slug already exists")
+	ErrNotMember               = errors.New("not a member of this workspace")
+	ErrNotAuthorized           = errors.New("not authorized")
+	ErrInviteNotFound          = errors.New("invite not found or expired")
+	ErrAlreadyMember           = errors.New("already a member")
+	ErrInviteCodeNotFound      = errors.New("invite code not found or expired")
+	ErrInviteCodeMaxUsed       = errors.New("invite code has reached max uses")
+	ErrCannotLeaveAsOwner      = errors.New("owner cannot leave workspace, transfer ownership first")
+	ErrRoleNotFound            = errors.New("role not found")
+	ErrRoleNameExists          = errors.New("role name already exists in this workspace")
+	ErrCannotDeleteDefault     = errors.New("cannot delete default role")
+	ErrTemplateNotFound        = errors.New("template not found")
+	ErrTemplateVersionNotFound = errors.New("template version not found")
+	ErrInvalidShareTarget      = errors.New("cannot share a template with its creator")
+	ErrTemplateShareNotFound   = errors.New("template is not shared with this user")
+	ErrTagNotFound             = errors.New("tag not found")
+	ErrTagNameExists           = errors.New("tag name already exists in this workspace")
+	ErrUserBanned              = errors.New("user is banned from this workspace")
+	ErrUserNotBanned           = errors.New("user is not banned")
+	ErrUserNotMuted            = errors.New("user is not muted")
+	ErrCannotBanOwner          = errors.New("cannot ban workspace owner")
+	ErrCannotMuteOwner         = errors.New("cannot mute workspace owner")
 	ErrAnnouncementNotFound    = errors.New("announcement not found")
+	ErrExpiresBeforePublish    = errors.New("announcement would expire before it is published")
+	ErrInvalidIconURL          = errors.New("icon URL must be https on an allowed host")
+	ErrInvalidIcon             = errors.New("icon must be a PNG, JPEG, GIF or WebP image within the size limit")
 	ErrWebhookNotFound         = errors.New("webhook not found")
+	ErrAPIKeyNotFound          = errors.New("API key not found")
+	ErrInvalidAPIKey           = errors.New("invalid or revoked API key")
+	ErrJoinRequestNotFound     = errors.New("join request not found or already decided")
+	ErrJoinRequestPending      = errors.New("a join request is already pending")
 	ErrAlreadyFavorited        = errors.New("workspace already favorited")
 	ErrNotFavorited            = errors.New("workspace is not favorited")
 	ErrMemberNoteNotFound      = errors.New("member note not found")
 	ErrScheduledActionNotFound = errors.New("scheduled action not found")
 	ErrScheduledActionPast     = errors.New("scheduled time must be in the future")
 	ErrQuotaExceeded           = errors.New("workspace quota exceeded")
+	ErrDailyInviteCapReached   = errors.New("daily invite cap reached for this workspace")
+	ErrRejoinCooldown          = errors.New("cannot rejoin this workspace yet")
+	ErrReactionNotFound        = errors.New("reaction not found")
+	ErrActivityNotFound        = errors.New("activity not found")
+	ErrActivityNotPinned       = errors.New("activity is not pinned")
+	ErrActivityPinLimitReached = errors.New("pinned activity limit reached for this workspace")
 	ErrWorkspaceArchived       = errors.New("workspace is archived")
 	ErrWorkspaceNotArchived    = errors.New("workspace is not archived")
 	ErrPinnedItemNotFound      = errors.New("pinned item not found")
@@ -67,55 +92,158 @@ var (
 	ErrFeatureFlagNotFound     = errors.New("feature flag not found")
 	ErrFeatureFlagKeyExists    = errors.New("feature flag key already exists in this workspace")
 	ErrIntegrationNotFound     = errors.New("integration not found")
+	ErrAuditSinkInsecureURL    = errors.New("audit sink URL must use https")
 	ErrLabelNotFound           = errors.New("label not found")
 	ErrLabelNameExists         = errors.New("label name already exists in this workspace")
 	ErrChecklistNotFound       = errors.New("checklist not found")
 	ErrOnboardingStepNotFound  = errors.New("onboarding step not found")
 	ErrPolicyNotFound          = errors.New("compliance policy not found")
+	ErrRoleAlreadyAssigned     = errors.New("role is already assigned to this member")
+	ErrRoleNotAssigned         = errors.New("role is not assigned to this member")
+	ErrMemberRoleLimitReached  = errors.New("member has reached the maximum number of roles")
+	ErrUnknownPermission       = errors.New("unknown permission key or non-boolean grant")
+	ErrRateLimited             = errors.New("invite limit reached, try again later")
+	ErrExportNotConfirmed      = errors.New("workspace export must be explicitly confirmed")
+	ErrProfileFieldsRequired   = errors.New("required profile fields are missing")
+	ErrInvalidColor            = errors.New("color must be a hex value like #1a2b3c or #abc")
+	ErrConflict                = errors.New("resource was modified by another request")
+	ErrTransferNotFound        = errors.New("no pending ownership transfer")
+	ErrTransferPending         = errors.New("an ownership transfer is already pending")
+	ErrWorkspaceBusy           = errors.New("another change to this workspace is in progress")
+	ErrLastOwner               = errors.New("workspace must keep at least one owner")
+	ErrPinLimitReached         = errors.New("pinned item limit reached for this workspace")
+	ErrInvalidPinType          = errors.New("pinned item type must be one of message, link, file, note")
+	ErrBookmarkFolderNotFound  = errors.New("bookmark folder not found")
+	ErrInvalidFolderName       = errors.New("folder name must not be empty")
+	ErrCredentialKeyUnknown    = errors.New("credentials were encrypted with an unknown key")
+	ErrNoCredentials           = errors.New("integration has no credentials")
+	ErrSyncNotSupported        = errors.New("integration provider does not support sync")
 )
 
 const (
-	cacheTTL             = 15 * time.Minute
-	cacheKeyWorkspace    = "workspace:%s"
-	cacheKeyMembers      = "workspace:%s:members"
-	cacheKeyStats        = "workspace:%s:stats"
-	cacheKeyUserWsList   = "user:%s:workspaces"
+	cacheTTL           = 15 * time.Minute
+	negativeCacheTTL   = 30 * time.Second
+	cacheKeyWorkspace  = "workspace:%s"
+	cacheKeyMissing    = "workspace:%s:missing" // tombstone for IDs that resolved to not found
+	cacheKeyMembers    = "workspace:%s:members"
+	cacheKeyStats      = "workspace:%s:stats"
+	cacheKeyUserWsList = "user:%s:workspaces"
+	cacheKeyPolicyAcks = "workspace:%s:policy_acks" // hash of user_id -> outstanding policy IDs
+	cacheKeyMemberSeen = "workspace:%s:seen:%s"
+	memberSeenDebounce = time.Minute
+
+	webhookDegradedFailures = 3
+
+	settingMaxRolesPerMember           = "max_roles_per_member"
+	settingDefaultAnnouncementPriority = "default_announcement_priority"
+	settingRequiredProfileFields       = "required_profile_fields"
+	settingInviteLandingMessage        = "invite_landing_message"
+	defaultMaxRolesPerMember           = 10
+
+	settingInviteLimitPerMember = "invite_limit_per_member"
+	settingInviteLimitWindow    = "invite_limit_window_hours"
+	defaultInviteLimitPerMember = 50
+	defaultInviteLimitWindow    = 24
+
+	settingInviteExpiryDays = "invite_expiry_days"
+	defaultInviteExpiryDays = 7
+	maxInviteExpiryDays     = 30
+	inviteResendCooldown    = time.Hour
+
+	settingMaxBookmarksPerUser = "max_bookmarks_per_user"
+
+	settingMaxPinnedItems = "max_pinned_items"
+	settingPinAdminsOnly  = "pin_admins_only"
+	defaultMaxPinnedItems = 50
+
+	maxPinnedActivity = 10
+
+	settingDefaultMemberRole = "default_member_role"
+	defaultMemberRole        = "member"
+
+	settingRoleDefaultPreferences = "role_default_preferences"
+
+	settingRejoinCooldownHours = "rejoin_cooldown_hours"
+	maxRejoinCooldownHours     = 24 * 365
+
+	settingIconThumbnailURL = "icon_thumbnail_url"
+
+	ownershipTransferTTL = 72 * time.Hour
+
+	magicLinkTTL        = time.Hour
+	magicLinkCodeLength = 16 // fits the invite code column; ~80 bits
 )
 
+// CacheConfig sets the Redis TTL per cached entity type. Zero values fall
+// back to cacheTTL, or negativeCacheTTL for NegativeTTL.
+type CacheConfig struct {
+	WorkspaceTTL time.Duration
+	StatsTTL     time.Duration
+	NegativeTTL  time.Duration // how long a workspace lookup miss is remembered
+}
+
+func (c CacheConfig) withDefaults() CacheConfig {
+	if c.WorkspaceTTL <= 0 {
+		c.WorkspaceTTL = cacheTTL
+	}
+	if c.StatsTTL <= 0 {
+		c.StatsTTL = cacheTTL
+	}
+	if c.NegativeTTL <= 0 {
+		c.NegativeTTL = negativeCacheTTL
+	}
+	return c
+}
+
 type WorkspaceService struct {
-	workspaceRepo    *repository.WorkspaceRepository
-	memberRepo       *repository.MemberRepository
-	inviteRepo       *repository.InviteRepository
-	inviteCodeRepo   *repository.InviteCodeRepository
-	activityRepo     *repository.ActivityRepository
-	profileRepo      *repository.ProfileRepository
-	roleRepo         *repository.RoleRepository
-	templateRepo     *repository.TemplateRepository
-	preferenceRepo   *repository.PreferenceRepository
-	tagRepo          *repository.TagRepository
-	moderationRepo   *repository.ModerationRepository
-	announcementRepo *repository.AnnouncementRepository
-	webhookRepo        *repository.WebhookRepository
-	favoriteRepo       *repository.FavoriteRepository
-	memberNoteRepo     *repository.MemberNoteRepository
-	scheduledActionRepo *repository.ScheduledActionRepository
-	quotaRepo          *repository.QuotaRepository
-	pinnedItemRepo         *repository.PinnedItemRepository
-	groupRepo              *repository.GroupRepository
-	customFieldRepo        *repository.CustomFieldRepository
-	reactionRepo           *repository.ReactionRepository
-	bookmarkRepo           *repository.BookmarkRepository
-	invitationHistoryRepo  *repository.InvitationHistoryRepository
-	accessLogRepo          *repository.AccessLogRepository
-	featureFlagRepo        *repository.FeatureFlagRepository
-	integrationRepo        *repository.IntegrationRepository
-	labelRepo              *repository.LabelRepository
-	streakRepo             *repository.StreakRepository
-	onboardingRepo         *repository.OnboardingRepository
-	complianceRepo         *repository.ComplianceRepository
-	redis                  *redis.Client
-	kafka                  *db.KafkaProducer
-	logger                 *logrus.Logger
+	workspaceRepo         *repository.WorkspaceRepository
+	memberRepo            *repository.MemberRepository
+	inviteRepo            *repository.InviteRepository
+	inviteCodeRepo        *repository.InviteCodeRepository
+	activityRepo          *repository.ActivityRepository
+	profileRepo           *repository.ProfileRepository
+	roleRepo              *repository.RoleRepository
+	templateRepo          *repository.TemplateRepository
+	preferenceRepo        *repository.PreferenceRepository
+	tagRepo               *repository.TagRepository
+	moderationRepo        *repository.ModerationRepository
+	announcementRepo      *repository.AnnouncementRepository
+	webhookRepo           *repository.WebhookRepository
+	favoriteRepo          *repository.FavoriteRepository
+	memberNoteRepo        *repository.MemberNoteRepository
+	scheduledActionRepo   *repository.ScheduledActionRepository
+	quotaRepo             *repository.QuotaRepository
+	pinnedItemRepo        *repository.PinnedItemRepository
+	groupRepo             *repository.GroupRepository
+	customFieldRepo       *repository.CustomFieldRepository
+	reactionRepo          *repository.ReactionRepository
+	bookmarkRepo          *repository.BookmarkRepository
+	invitationHistoryRepo *repository.InvitationHistoryRepository
+	accessLogRepo         *repository.AccessLogRepository
+	featureFlagRepo       *repository.FeatureFlagRepository
+	integrationRepo       *repository.IntegrationRepository
+	labelRepo             *repository.LabelRepository
+	streakRepo            *repository.StreakRepository
+	onboardingRepo        *repository.OnboardingRepository
+	complianceRepo        *repository.ComplianceRepository
+	outboxRepo            *repository.OutboxRepository
+	securityRepo          *repository.SecurityRepository
+	discoveryRepo         *repository.DiscoveryRepository
+	apiKeyRepo            *repository.APIKeyRepository
+	joinRequestRepo       *repository.JoinRequestRepository
+	auditSink             *AuditSink
+	webhooks              *WebhookDispatcher
+	credentials           *CredentialCipher
+	metrics               *metrics.Metrics
+	cacheConfig           CacheConfig
+	iconConfig            IconConfig
+	joinBaseURL           string
+	canonicalizeGmail     bool
+	cacheHits             sync.Map // cache name -> *int64
+	cacheMisses           sync.Map // cache name -> *int64
+	kv                    kvStore
+	kafka                 *db.KafkaProducer
+	logger                *logrus.Logger
 }
 
 func NewWorkspaceService(
@@ -149,6 +277,19 @@ func NewWorkspaceService(
 	streakRepo *repository.StreakRepository,
 	onboardingRepo *repository.OnboardingRepository,
 	complianceRepo *repository.ComplianceRepository,
+	outboxRepo *repository.OutboxRepository,
+	securityRepo *repository.SecurityRepository,
+	discoveryRepo *repository.DiscoveryRepository,
+	apiKeyRepo *repository.APIKeyRepository,
+	joinRequestRepo *repository.JoinRequestRepository,
+	auditSink *AuditSink,
+	webhooks *WebhookDispatcher,
+	credentials *CredentialCipher,
+	metrics *metrics.Metrics,
+	cacheConfig CacheConfig,
+	iconConfig IconConfig,
+	joinBaseURL string,
+	canonicalizeGmail bool,
 	redis *redis.Client,
 	kafka *db.KafkaProducer,
 	logger *logrus.Logger,
@@ -184,7 +325,20 @@ func NewWorkspaceService(
 		streakRepo:            streakRepo,
 		onboardingRepo:        onboardingRepo,
 		complianceRepo:        complianceRepo,
-		redis:                 redis,
+		outboxRepo:            outboxRepo,
+		securityRepo:          securityRepo,
+		discoveryRepo:         discoveryRepo,
+		apiKeyRepo:            apiKeyRepo,
+		joinRequestRepo:       joinRequestRepo,
+		auditSink:             auditSink,
+		webhooks:              webhooks,
+		credentials:           credentials,
+		metrics:               metrics,
+		cacheConfig:           cacheConfig.withDefaults(),
+		iconConfig:            iconConfig,
+		joinBaseURL:           strings.TrimRight(joinBaseURL, "/"),
+		canonicalizeGmail:     canonicalizeGmail,
+		kv:                    newKVStore(redis),
 		kafka:                 kafka,
 		logger:                logger,
 	}
@@ -225,6 +379,11 @@ func (s *WorkspaceService) CreateWorkspace(ctx context.Context, ownerID uuid.UUI
 		UpdatedAt:   time.Now(),
 	}
 	s.memberRepo.Create(ctx, member)
+	s.clearWorkspaceTombstone(ctx, workspace.ID)
+
+	if !req.SkipDefaultRoles {
+		s.seedDefaultRoles(ctx, workspace.ID, ownerID)
+	}
 
 	s.invalidateUserWorkspaces(ctx, ownerID)
 	s.publishEvent(ctx, "workspace-events", workspace.ID.String(), "workspace.created", map[string]interface{}{
@@ -239,9 +398,15 @@ func (s *WorkspaceService) GetWorkspace(ctx context.Context, id uuid.UUID, userI
 	if cached, err := s.getCachedWorkspaceResponse(ctx, id, userID); err == nil && cached != nil {
 		return cached, nil
 	}
+	if s.isWorkspaceTombstoned(ctx, id) {
+		return nil, ErrWorkspaceNotFound
+	}
 
 	workspace, err := s.workspaceRepo.GetByID(ctx, id)
 	if err != nil || workspace == nil {
+		if err == nil {
+			s.tombstoneWorkspace(ctx, id)
+		}
 		return nil, ErrWorkspaceNotFound
 	}
 
@@ -249,9 +414,10 @@ func (s *WorkspaceService) GetWorkspace(ctx context.Context, id uuid.UUID, userI
 	role, _ := s.memberRepo.GetRole(ctx, id, userID)
 
 	resp := &models.WorkspaceResponse{
-		Workspace:   workspace,
-		MemberCount: memberCount,
-		MyRole:      role,
+		Workspace:    workspace,
+		MemberCount:  memberCount,
+		ChannelCount: s.channelCount(ctx, id),
+		MyRole:       role,
 	}
 
 	s.cacheWorkspace(ctx, id, workspace)
@@ -269,6 +435,9 @@ func (s *WorkspaceService) UpdateWorkspace(ctx context.Context, id uuid.UUID, us
 		return nil, ErrNotAuthorized
 	}
 
+	if req.Version != nil && *req.Version != workspace.Version {
+		return nil, ErrConflict
+	}
 	if req.Name != nil {
 		workspace.Name = *req.Name
 	}
@@ -276,26 +445,85 @@ func (s *WorkspaceService) UpdateWorkspace(ctx context.Context, id uuid.UUID, us
 		workspace.Description = req.Description
 	}
 	if req.IconURL != nil {
+		if *req.IconURL != "" {
+			if err := s.checkIcon(ctx, *req.IconURL); err != nil {
+				return nil, err
+			}
+		}
 		workspace.IconURL = req.IconURL
 	}
+	settings := workspace.Settings
 	if req.Settings != nil {
-		workspace.Settings = req.Settings
+		if err := keepReadOnlySettings(workspace.Settings, req.Settings); err != nil {
+			return nil, err
+		}
+		if err := validateSettings(req.Settings, false); err != nil {
+			return nil, err
+		}
+		settings = req.Settings
 	}
 
-	if err := s.workspaceRepo.Update(ctx, workspace); err != nil {
+	if err := s.saveWorkspaceSettings(ctx, workspace, userID, settings); err != nil {
 		return nil, err
 	}
 
 	s.invalidateWorkspace(ctx, id)
 	s.publishEvent(ctx, "workspace-events", id.String(), "workspace.updated", map[string]interface{}{
-		"workspace": workspace,
+		"workspace":  workspace,
 		"updated_by": userID,
 	})
 
 	return workspace, nil
 }
 
+// saveWorkspaceSettings saves the workspace with settings in place of its
+// current settings and records what changed in the settings history. Every
+// settings write goes through here so the history stays complete.
+func (s *WorkspaceService) saveWorkspaceSettings(ctx context.Context, workspace *models.Workspace, actorID uuid.UUID, settings models.JSON) error {
+	previous := workspace.Settings
+	workspace.Settings = settings
+	if err := s.saveWorkspace(ctx, workspace); err != nil {
+		return err
+	}
+	s.recordSettingsChange(ctx, workspace, actorID, diffSettings(previous, settings))
+	return nil
+}
+
+// withSetting returns a copy of settings with key set to value, or without
+// key when value is nil.
+func withSetting(settings models.JSON, key string, value interface{}) models.JSON {
+	updated := make(models.JSON, len(settings)+1)
+	for k, v := range settings {
+		updated[k] = v
+	}
+	if value == nil {
+		delete(updated, key)
+	} else {
+		updated[key] = value
+	}
+	return updated
+}
+
+// saveWorkspace writes a workspace read earlier in the request, failing with
+// ErrConflict if it changed in between.
+func (s *WorkspaceService) saveWorkspace(ctx context.Context, workspace *models.Workspace) error {
+	ok, err := s.workspaceRepo.Update(ctx, workspace)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrConflict
+	}
+	return nil
+}
+
 func (s *WorkspaceService) DeleteWorkspace(ctx context.Context, id uuid.UUID, userID uuid.UUID) error {
+	return s.WithWorkspaceLock(ctx, id, func(ctx context.Context) error {
+		return s.deleteWorkspace(ctx, id, userID)
+	})
+}
+
+func (s *WorkspaceService) deleteWorkspace(ctx context.Context, id uuid.UUID, userID uuid.UUID) error {
 	workspace, err := s.workspaceRepo.GetByID(ctx, id)
 	if err != nil || workspace == nil {
 		return ErrWorkspaceNotFound
@@ -325,14 +553,30 @@ func (s *WorkspaceService) ListWorkspaces(ctx context.Context, userID uuid.UUID,
 		return nil, err
 	}
 
+	ids := make([]uuid.UUID, len(workspaces))
+	for i, w := range workspaces {
+		ids[i] = w.ID
+	}
+	memberCounts, err := s.workspaceRepo.GetMemberCounts(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	roles, err := s.memberRepo.GetRoles(ctx, ids, userID)
+	if err != nil {
+		return nil, err
+	}
+	channelCounts, err := s.quotaRepo.GetChannelCounts(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
 	var responses []*models.WorkspaceResponse
 	for _, w := range workspaces {
-		memberCount, _ := s.workspaceRepo.GetMemberCount(ctx, w.ID)
-		role, _ := s.memberRepo.GetRole(ctx, w.ID, userID)
 		responses = append(responses, &models.WorkspaceResponse{
-			Workspace:   w,
-			MemberCount: memberCount,
-			MyRole:      role,
+			Workspace:    w,
+			MemberCount:  memberCounts[w.ID],
+			ChannelCount: channelCounts[w.ID],
+			MyRole:       roles[w.ID],
 		})
 	}
 
@@ -346,6 +590,37 @@ func (s *WorkspaceService) ListWorkspaces(ctx context.Context, userID uuid.UUID,
 
 // ── Workspace Stats ──
 
+// channelCount reads the workspace's channel count as last reported by the
+// channel service; zero until the first channel event arrives.
+func (s *WorkspaceService) channelCount(ctx context.Context, workspaceID uuid.UUID) int {
+	quota, _ := s.quotaRepo.GetByWorkspace(ctx, workspaceID)
+	if quota == nil {
+		return 0
+	}
+	return quota.CurrentChannels
+}
+
+// ApplyUsageEvent records a channel or file change reported by another
+// service and recounts the workspace's channel and storage usage. Events
+// for unknown workspaces are ignored.
+func (s *WorkspaceService) ApplyUsageEvent(ctx context.Context, event *models.UsageEvent) error {
+	workspace, err := s.workspaceRepo.GetByID(ctx, event.WorkspaceID)
+	if err != nil {
+		return err
+	}
+	if workspace == nil {
+		return nil
+	}
+	if err := s.quotaRepo.RecordUsageEntity(ctx, event); err != nil {
+		return err
+	}
+	if err := s.quotaRepo.RecountUsage(ctx, event.WorkspaceID); err != nil {
+		return err
+	}
+	s.invalidateWorkspace(ctx, event.WorkspaceID)
+	return nil
+}
+
 func (s *WorkspaceService) GetWorkspaceStats(ctx context.Context, workspaceID uuid.UUID, userID uuid.UUID) (*models.WorkspaceStats, error) {
 	workspace, err := s.workspaceRepo.GetByID(ctx, workspaceID)
 	if err != nil || workspace == nil {
@@ -367,11 +642,12 @@ func (s *WorkspaceService) GetWorkspaceStats(ctx context.Context, workspaceID uu
 	roleCounts, _ := s.workspaceRepo.GetRoleCounts(ctx, workspaceID)
 
 	stats := &models.WorkspaceStats{
-		MemberCount: memberCount,
-		InviteCount: inviteCount,
-		RoleCounts:  roleCounts,
-		CreatedAt:   workspace.CreatedAt,
-		Plan:        workspace.Plan,
+		MemberCount:  memberCount,
+		ChannelCount: s.channelCount(ctx, workspaceID),
+		InviteCount:  inviteCount,
+		RoleCounts:   roleCounts,
+		CreatedAt:    workspace.CreatedAt,
+		Plan:         workspace.Plan,
 	}
 
 	s.cacheStats(ctx, workspaceID, stats)
@@ -397,7 +673,9 @@ func (s *WorkspaceService) GetWorkspaceSettings(ctx context.Context, workspaceID
 	return workspace.Settings, nil
 }
 
-func (s *WorkspaceService) UpdateWorkspaceSettings(ctx context.Context, workspaceID uuid.UUID, userID uuid.UUID, settings models.JSON) (models.JSON, error) {
+// UpdateWorkspaceSettings replaces the settings wholesale. Known keys are
+// checked against settingsSchema; strict also rejects unknown keys.
+func (s *WorkspaceService) UpdateWorkspaceSettings(ctx context.Context, workspaceID uuid.UUID, userID uuid.UUID, settings models.JSON, strict bool) (models.JSON, error) {
 	workspace, err := s.workspaceRepo.GetByID(ctx, workspaceID)
 	if err != nil || workspace == nil {
 		return nil, ErrWorkspaceNotFound
@@ -408,8 +686,17 @@ func (s *WorkspaceService) UpdateWorkspaceSettings(ctx context.Context, workspac
 		return nil, ErrNotAuthorized
 	}
 
-	workspace.Settings = settings
-	if err := s.workspaceRepo.Update(ctx, workspace); err != nil {
+	if settings == nil {
+		settings = models.JSON{}
+	}
+	if err := keepReadOnlySettings(workspace.Settings, settings); err != nil {
+		return nil, err
+	}
+	if err := validateSettings(settings, strict); err != nil {
+		return nil, err
+	}
+
+	if err := s.saveWorkspaceSettings(ctx, workspace, userID, settings); err != nil {
 		return nil, err
 	}
 
@@ -417,6 +704,100 @@ func (s *WorkspaceService) UpdateWorkspaceSettings(ctx context.Context, workspac
 	return settings, nil
 }
 
+// MergeWorkspaceSettings patches the settings instead of replacing them.
+// Merge rules (RFC 7396 JSON merge patch):
+//   - a key set to null is removed
+//   - a key whose patch value and current value are both objects is merged
+//     recursively with the same rules
+//   - any other value, arrays included, replaces the current value
+//   - keys absent from the patch are left untouched
+//
+// Patched values are validated like UpdateWorkspaceSettings.
+func (s *WorkspaceService) MergeWorkspaceSettings(ctx context.Context, workspaceID, userID uuid.UUID, patch models.JSON, strict bool) (models.JSON, error) {
+	workspace, err := s.workspaceRepo.GetByID(ctx, workspaceID)
+	if err != nil || workspace == nil {
+		return nil, ErrWorkspaceNotFound
+	}
+
+	role, _ := s.memberRepo.GetRole(ctx, workspaceID, userID)
+	if role != "owner" && role != "admin" {
+		return nil, ErrNotAuthorized
+	}
+
+	if err := rejectReadOnlySettings(workspace.Settings, patch); err != nil {
+		return nil, err
+	}
+	if err := validateSettings(patch, strict); err != nil {
+		return nil, err
+	}
+	if len(patch) == 0 {
+		return workspace.Settings, nil
+	}
+	if err := s.workspaceRepo.MergeSettings(ctx, workspaceID, patch); err != nil {
+		return nil, err
+	}
+	s.invalidateWorkspace(ctx, workspaceID)
+
+	updated, err := s.workspaceRepo.GetByID(ctx, workspaceID)
+	if err != nil || updated == nil {
+		return nil, ErrWorkspaceNotFound
+	}
+
+	keys := make([]string, 0, len(patch))
+	for k := range patch {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	s.LogActivity(ctx, workspaceID, userID, "workspace.settings_patched", "workspace", workspaceID.String(), models.JSON{"keys": keys})
+
+	// The patch is applied in SQL, so the new values are read back. Only the
+	// patched keys are compared, so a concurrent patch to other keys is not
+	// attributed to this actor.
+	changes := diffSettings(workspace.Settings, updated.Settings)
+	for key := range changes {
+		if _, patched := patch[key]; !patched {
+			delete(changes, key)
+		}
+	}
+	s.recordSettingsChange(ctx, updated, userID, changes)
+	return updated.Settings, nil
+}
+
+// recordSettingsChange appends changes to the workspace's settings history.
+// Updates that changed nothing are not recorded, and a failure to record is
+// logged rather than failing the update that already happened.
+func (s *WorkspaceService) recordSettingsChange(ctx context.Context, workspace *models.Workspace, actorID uuid.UUID, changes models.JSON) {
+	if len(changes) == 0 {
+		return
+	}
+	change := &models.WorkspaceSettingsChange{
+		ID:          uuid.New(),
+		WorkspaceID: workspace.ID,
+		ActorID:     actorID,
+		Changes:     changes,
+		Version:     workspace.Version,
+		CreatedAt:   time.Now(),
+	}
+	if err := s.workspaceRepo.CreateSettingsChange(ctx, change); err != nil {
+		s.requestLogger(ctx).WithError(err).WithField("workspace_id", workspace.ID).Warn("Failed to record settings change")
+	}
+}
+
+// GetSettingsHistory lists settings changes, newest first.
+func (s *WorkspaceService) GetSettingsHistory(ctx context.Context, workspaceID, userID uuid.UUID, page, perPage int) ([]*models.WorkspaceSettingsChange, int64, error) {
+	role, _ := s.memberRepo.GetRole(ctx, workspaceID, userID)
+	if role != "owner" && role != "admin" {
+		return nil, 0, ErrNotAuthorized
+	}
+	if page < 1 {
+		page = 1
+	}
+	if perPage < 1 || perPage > 100 {
+		perPage = 20
+	}
+	return s.workspaceRepo.ListSettingsHistory(ctx, workspaceID, page, perPage)
+}
+
 // ── Leave Workspace ──
 
 func (s *WorkspaceService) LeaveWorkspace(ctx context.Context, workspaceID uuid.UUID, userID uuid.UUID) error {
@@ -433,18 +814,66 @@ func (s *WorkspaceService) LeaveWorkspace(ctx context.Context, workspaceID uuid.
 	if workspace.OwnerID == userID {
 		return ErrCannotLeaveAsOwner
 	}
+	role, _ := s.memberRepo.GetRole(ctx, workspaceID, userID)
+	if err := s.guardLastOwner(ctx, workspaceID, role); err != nil {
+		return err
+	}
 
-	if err := s.memberRepo.Remove(ctx, workspaceID, userID); err != nil {
+	if err := s.removeMemberWithEvent(ctx, workspaceID, userID, "member.left", map[string]interface{}{
+		"workspace_id": workspaceID,
+		"user_id":      userID,
+	}); err != nil {
 		return err
 	}
+	if err := s.memberRepo.RecordDeparture(ctx, workspaceID, userID, time.Now()); err != nil {
+		s.logger.WithError(err).Warn("Failed to record workspace departure")
+	}
 
 	s.invalidateWorkspace(ctx, workspaceID)
 	s.invalidateUserWorkspaces(ctx, userID)
-	s.publishEvent(ctx, "workspace-events", workspaceID.String(), "member.left", map[string]interface{}{
-		"workspace_id": workspaceID,
-		"user_id":      userID,
-	})
+	s.LogActivity(ctx, workspaceID, userID, "member.left", "member", userID.String(), nil)
+
+	return nil
+}
+
+// RejoinCooldownError reports that a member who left is asking to rejoin
+// before the workspace's rejoin_cooldown_hours have passed. It matches
+// ErrRejoinCooldown with errors.Is.
+type RejoinCooldownError struct {
+	Until time.Time
+}
 
+func (e *RejoinCooldownError) Error() string {
+	return fmt.Sprintf("cannot rejoin this workspace until %s", e.Until.UTC().Format(time.RFC3339))
+}
+
+func (e *RejoinCooldownError) Is(target error) bool {
+	return target == ErrRejoinCooldown
+}
+
+// checkRejoinCooldown fails if the user left the workspace within its
+// rejoin cooldown. An invite an owner or admin sent after the user left
+// waives the cooldown; invites from anyone else, or sent before the user
+// left, do not. Pass a nil invite when joining some other way.
+func (s *WorkspaceService) checkRejoinCooldown(ctx context.Context, workspace *models.Workspace, userID uuid.UUID, invite *models.WorkspaceInvite) error {
+	hours := settingInt(workspace.Settings, settingRejoinCooldownHours, 0)
+	if hours == 0 {
+		return nil
+	}
+	leftAt, err := s.memberRepo.GetDepartedAt(ctx, workspace.ID, userID)
+	if err != nil || leftAt == nil {
+		return err
+	}
+	if invite != nil && invite.CreatedAt.After(*leftAt) {
+		inviterRole, _ := s.memberRepo.GetRole(ctx, workspace.ID, invite.InvitedBy)
+		if inviterRole == "owner" || inviterRole == "admin" {
+			return nil
+		}
+	}
+	until := leftAt.Add(time.Duration(hours) * time.Hour)
+	if time.Now().Before(until) {
+		return &RejoinCooldownError{Until: until}
+	}
 	return nil
 }
 
@@ -460,14 +889,24 @@ func (s *WorkspaceService) GetMember(ctx context.Context, workspaceID, memberUse
 
 // ── Ownership Transfer ──
 
-func (s *WorkspaceService) TransferOwnership(ctx context.Context, workspaceID, currentOwnerID, newOwnerID uuid.UUID) error {
+// TransferOwnership hands the workspace to another member immediately,
+// without the consent of either. It is reserved for service admins, who
+// reach it through the admin API; owners go through
+// InitiateOwnershipTransfer / AcceptOwnershipTransfer.
+func (s *WorkspaceService) TransferOwnership(ctx context.Context, workspaceID, adminID, newOwnerID uuid.UUID) error {
+	return s.WithWorkspaceLock(ctx, workspaceID, func(ctx context.Context) error {
+		return s.transferOwnership(ctx, workspaceID, adminID, newOwnerID)
+	})
+}
+
+func (s *WorkspaceService) transferOwnership(ctx context.Context, workspaceID, adminID, newOwnerID uuid.UUID) error {
 	workspace, err := s.workspaceRepo.GetByID(ctx, workspaceID)
 	if err != nil || workspace == nil {
 		return ErrWorkspaceNotFound
 	}
-
-	if workspace.OwnerID != currentOwnerID {
-		return ErrNotAuthorized
+	currentOwnerID := workspace.OwnerID
+	if currentOwnerID == newOwnerID {
+		return nil
 	}
 
 	isMember, _ := s.memberRepo.IsMember(ctx, workspaceID, newOwnerID)
@@ -475,101 +914,530 @@ func (s *WorkspaceService) TransferOwnership(ctx context.Context, workspaceID, c
 		return ErrNotMember
 	}
 
-	if err := s.workspaceRepo.TransferOwnership(ctx, workspaceID, newOwnerID); err != nil {
+	// A forced transfer supersedes any pending one
+	if pending, _ := s.workspaceRepo.GetPendingOwnershipTransfer(ctx, workspaceID); pending != nil {
+		s.workspaceRepo.ResolveOwnershipTransfer(ctx, pending.ID, "canceled", &adminID)
+	}
+	if err := s.completeOwnershipTransfer(ctx, workspaceID, currentOwnerID, newOwnerID); err != nil {
 		return err
 	}
 
-	// Update roles
-	s.memberRepo.UpdateRole(ctx, workspaceID, newOwnerID, "owner")
-	s.memberRepo.UpdateRole(ctx, workspaceID, currentOwnerID, "admin")
-
-	s.invalidateWorkspace(ctx, workspaceID)
-	s.publishEvent(ctx, "workspace-events", workspaceID.String(), "ownership.transferred", map[string]interface{}{
-		"workspace_id":   workspaceID,
+	s.LogActivity(ctx, workspaceID, adminID, "ownership.force_transferred", "workspace", workspaceID.String(), models.JSON{
 		"previous_owner": currentOwnerID,
 		"new_owner":      newOwnerID,
 	})
-
 	return nil
 }
 
-// ── Member Management ──
+// InitiateOwnershipTransfer offers the workspace to another member. Nothing
+// changes until the target accepts; the offer expires after
+// ownershipTransferTTL.
+func (s *WorkspaceService) InitiateOwnershipTransfer(ctx context.Context, workspaceID, ownerID, targetID uuid.UUID) (*models.OwnershipTransfer, error) {
+	var transfer *models.OwnershipTransfer
+	err := s.WithWorkspaceLock(ctx, workspaceID, func(ctx context.Context) error {
+		var err error
+		transfer, err = s.initiateOwnershipTransfer(ctx, workspaceID, ownerID, targetID)
+		return err
+	})
+	return transfer, err
+}
 
-func (s *WorkspaceService) InviteMember(ctx context.Context, workspaceID uuid.UUID, inviterID uuid.UUID, req *models.InviteMemberRequest) (*models.WorkspaceInvite, error) {
-	role, _ := s.memberRepo.GetRole(ctx, workspaceID, inviterID)
-	if role != "owner" && role != "admin" {
+func (s *WorkspaceService) initiateOwnershipTransfer(ctx context.Context, workspaceID, ownerID, targetID uuid.UUID) (*models.OwnershipTransfer, error) {
+	workspace, err := s.workspaceRepo.GetByID(ctx, workspaceID)
+	if err != nil || workspace == nil {
+		return nil, ErrWorkspaceNotFound
+	}
+	if workspace.OwnerID != ownerID || targetID == ownerID {
 		return nil, ErrNotAuthorized
 	}
 
-	existing, _ := s.inviteRepo.GetPendingByEmail(ctx, workspaceID, req.Email)
-	if existing != nil {
-		return existing, nil
+	isMember, _ := s.memberRepo.IsMember(ctx, workspaceID, targetID)
+	if !isMember {
+		return nil, ErrNotMember
 	}
 
-	token := generateToken()
-	invite := &models.WorkspaceInvite{
+	if _, err := s.pendingOwnershipTransfer(ctx, workspaceID); err == nil {
+		return nil, ErrTransferPending
+	} else if err != ErrTransferNotFound {
+		return nil, err
+	}
+
+	now := time.Now()
+	transfer := &models.OwnershipTransfer{
 		ID:          uuid.New(),
 		WorkspaceID: workspaceID,
-		Email:       req.Email,
-		Role:        req.Role,
-		Token:       token,
-		InvitedBy:   inviterID,
-		ExpiresAt:   time.Now().Add(7 * 24 * time.Hour),
-		CreatedAt:   time.Now(),
+		FromUserID:  ownerID,
+		ToUserID:    targetID,
+		Status:      "pending",
+		ExpiresAt:   now.Add(ownershipTransferTTL),
+		CreatedAt:   now,
 	}
-
-	if err := s.inviteRepo.Create(ctx, invite); err != nil {
+	if err := s.workspaceRepo.CreateOwnershipTransfer(ctx, transfer); err != nil {
 		return nil, err
 	}
 
-	s.publishEvent(ctx, "notification-events", invite.ID.String(), "workspace.invite", map[string]interface{}{
-		"invite": invite,
+	s.LogActivity(ctx, workspaceID, ownerID, "ownership.transfer_initiated", "member", targetID.String(), models.JSON{"expires_at": transfer.ExpiresAt})
+	s.publishEvent(ctx, "workspace-events", workspaceID.String(), "ownership.transfer_initiated", map[string]interface{}{
+		"workspace_id": workspaceID,
+		"from_user_id": ownerID,
+		"to_user_id":   targetID,
+		"expires_at":   transfer.ExpiresAt,
 	})
-
-	return invite, nil
+	return transfer, nil
 }
 
-func (s *WorkspaceService) BulkInvite(ctx context.Context, workspaceID uuid.UUID, inviterID uuid.UUID, req *models.BulkInviteRequest) (*models.BulkInviteResponse, error) {
-	role, _ := s.memberRepo.GetRole(ctx, workspaceID, inviterID)
-	if role != "owner" && role != "admin" {
-		return nil, ErrNotAuthorized
+// GetOwnershipTransfer returns the pending transfer. Only the owner and the
+// named target can see it.
+func (s *WorkspaceService) GetOwnershipTransfer(ctx context.Context, workspaceID, userID uuid.UUID) (*models.OwnershipTransfer, error) {
+	transfer, err := s.pendingOwnershipTransfer(ctx, workspaceID)
+	if err != nil {
+		return nil, err
 	}
-
-	resp := &models.BulkInviteResponse{}
-	for _, inv := range req.Invites {
-		_, err := s.InviteMember(ctx, workspaceID, inviterID, &inv)
-		if err != nil {
-			resp.Failed = append(resp.Failed, struct {
-				Email  string `json:"email"`
-				Reason string `json:"reason"`
-			}{Email: inv.Email, Reason: err.Error()})
-		} else {
-			resp.Successful = append(resp.Successful, inv.Email)
-		}
+	if userID != transfer.FromUserID && userID != transfer.ToUserID {
+		return nil, ErrNotAuthorized
 	}
+	return transfer, nil
+}
 
-	return resp, nil
+// AcceptOwnershipTransfer completes the pending transfer. Only the named
+// target can accept, and only while the initiator still owns the workspace.
+func (s *WorkspaceService) AcceptOwnershipTransfer(ctx context.Context, workspaceID, targetID uuid.UUID) error {
+	return s.WithWorkspaceLock(ctx, workspaceID, func(ctx context.Context) error {
+		return s.acceptOwnershipTransfer(ctx, workspaceID, targetID)
+	})
 }
 
-func (s *WorkspaceService) AcceptInvite(ctx context.Context, token string, userID uuid.UUID) (*models.Workspace, error) {
-	invite, err := s.inviteRepo.GetByToken(ctx, token)
-	if err != nil || invite == nil {
-		return nil, ErrInviteNotFound
+func (s *WorkspaceService) acceptOwnershipTransfer(ctx context.Context, workspaceID, targetID uuid.UUID) error {
+	transfer, err := s.pendingOwnershipTransfer(ctx, workspaceID)
+	if err != nil {
+		return err
 	}
-
-	// Check if user is banned
-	isBanned, _ := s.moderationRepo.IsUserBanned(ctx, invite.WorkspaceID, userID)
-	if isBanned {
-		return nil, ErrUserBanned
+	if transfer.ToUserID != targetID {
+		return ErrNotAuthorized
 	}
 
-	isMember, _ := s.memberRepo.IsMember(ctx, invite.WorkspaceID, userID)
-	if isMember {
-		return nil, ErrAlreadyMember
+	workspace, err := s.workspaceRepo.GetByID(ctx, workspaceID)
+	if err != nil || workspace == nil {
+		return ErrWorkspaceNotFound
+	}
+	isMember, _ := s.memberRepo.IsMember(ctx, workspaceID, targetID)
+	if workspace.OwnerID != transfer.FromUserID || !isMember {
+		s.workspaceRepo.ResolveOwnershipTransfer(ctx, transfer.ID, "canceled", nil)
+		return ErrTransferNotFound
 	}
 
-	member := &models.WorkspaceMember{
-		ID:          uuid.New(),
+	resolved, err := s.workspaceRepo.ResolveOwnershipTransfer(ctx, transfer.ID, "accepted", &targetID)
+	if err != nil {
+		return err
+	}
+	if !resolved {
+		return ErrTransferNotFound
+	}
+	return s.completeOwnershipTransfer(ctx, workspaceID, transfer.FromUserID, targetID)
+}
+
+// CancelOwnershipTransfer withdraws (owner) or declines (target) the
+// pending transfer.
+func (s *WorkspaceService) CancelOwnershipTransfer(ctx context.Context, workspaceID, userID uuid.UUID) error {
+	transfer, err := s.pendingOwnershipTransfer(ctx, workspaceID)
+	if err != nil {
+		return err
+	}
+	if userID != transfer.FromUserID && userID != transfer.ToUserID {
+		return ErrNotAuthorized
+	}
+
+	resolved, err := s.workspaceRepo.ResolveOwnershipTransfer(ctx, transfer.ID, "canceled", &userID)
+	if err != nil {
+		return err
+	}
+	if !resolved {
+		return ErrTransferNotFound
+	}
+
+	s.LogActivity(ctx, workspaceID, userID, "ownership.transfer_canceled", "member", transfer.ToUserID.String(), nil)
+	return nil
+}
+
+// pendingOwnershipTransfer returns the live pending transfer, marking it
+// expired on the way if its window has passed.
+func (s *WorkspaceService) pendingOwnershipTransfer(ctx context.Context, workspaceID uuid.UUID) (*models.OwnershipTransfer, error) {
+	transfer, err := s.workspaceRepo.GetPendingOwnershipTransfer(ctx, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	if transfer == nil {
+		return nil, ErrTransferNotFound
+	}
+	if !time.Now().Before(transfer.ExpiresAt) {
+		s.workspaceRepo.ResolveOwnershipTransfer(ctx, transfer.ID, "expired", nil)
+		return nil, ErrTransferNotFound
+	}
+	return transfer, nil
+}
+
+// completeOwnershipTransfer swaps the owner and demotes the previous owner
+// to admin.
+func (s *WorkspaceService) completeOwnershipTransfer(ctx context.Context, workspaceID, currentOwnerID, newOwnerID uuid.UUID) error {
+	if err := s.workspaceRepo.TransferOwnership(ctx, workspaceID, newOwnerID); err != nil {
+		return err
+	}
+
+	// Update roles
+	s.memberRepo.UpdateRole(ctx, workspaceID, newOwnerID, "owner")
+	s.memberRepo.UpdateRole(ctx, workspaceID, currentOwnerID, "admin")
+
+	s.invalidateWorkspace(ctx, workspaceID)
+	s.publishEvent(ctx, "workspace-events", workspaceID.String(), "ownership.transferred", map[string]interface{}{
+		"workspace_id":   workspaceID,
+		"previous_owner": currentOwnerID,
+		"new_owner":      newOwnerID,
+	})
+
+	return nil
+}
+
+// ── Member Management ──
+
+func (s *WorkspaceService) InviteMember(ctx context.Context, workspaceID uuid.UUID, inviterID uuid.UUID, req *models.InviteMemberRequest) (*models.WorkspaceInvite, error) {
+	role, _ := s.memberRepo.GetRole(ctx, workspaceID, inviterID)
+	if role != "owner" && role != "admin" {
+		return nil, ErrNotAuthorized
+	}
+
+	email := s.normalizeInviteEmail(req.Email)
+
+	existing, err := s.existingInvite(ctx, workspaceID, email)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		existing.Status = "pending"
+		return existing, nil
+	}
+
+	quota, err := s.inviteQuota(ctx, workspaceID, inviterID, role)
+	if err != nil {
+		return nil, err
+	}
+	if !quota.Unlimited && quota.Remaining <= 0 {
+		return nil, ErrRateLimited
+	}
+	if err := s.checkDailyInviteCap(ctx, workspaceID, 1); err != nil {
+		return nil, err
+	}
+
+	token := generateToken()
+	invite := &models.WorkspaceInvite{
+		ID:          uuid.New(),
+		WorkspaceID: workspaceID,
+		Email:       email,
+		Role:        req.Role,
+		Token:       token,
+		InvitedBy:   inviterID,
+		ExpiresAt:   time.Now().Add(s.inviteExpiry(ctx, workspaceID)),
+		CreatedAt:   time.Now(),
+		Status:      "pending",
+	}
+
+	if err := s.inviteRepo.Create(ctx, invite); err != nil {
+		return nil, err
+	}
+	s.RecordInvitation(ctx, workspaceID, inviterID, email, nil, "email", req.Role, &invite.ExpiresAt)
+
+	s.publishEvent(ctx, "notification-events", invite.ID.String(), "workspace.invite", map[string]interface{}{
+		"invite": invite,
+	})
+
+	return invite, nil
+}
+
+// existingInvite returns the pending invite an invite to the normalized
+// address would reuse, or ErrAlreadyMember if it belongs to a member. Neither
+// sends an email, so neither counts toward the daily cap or the per-member
+// quota.
+func (s *WorkspaceService) existingInvite(ctx context.Context, workspaceID uuid.UUID, email string) (*models.WorkspaceInvite, error) {
+	// This service has no user directory, so an address can only be matched
+	// to a user who has accepted an invite sent to it before. Addresses we
+	// cannot resolve are treated as external and invited as usual.
+	if inviteeID, _ := s.invitationHistoryRepo.ResolveInviteeID(ctx, email); inviteeID != nil {
+		if isMember, _ := s.memberRepo.IsMember(ctx, workspaceID, *inviteeID); isMember {
+			return nil, ErrAlreadyMember
+		}
+	}
+
+	existing, _ := s.inviteRepo.GetPendingByEmail(ctx, workspaceID, email)
+	return existing, nil
+}
+
+// ResendInvite re-sends a pending or expired invite with a fresh expiry from
+// the workspace's invite_expiry_days setting. The token is kept so links
+// already sent keep working. A resend needs room under the same per-member
+// quota and daily cap as a new invite, and each invite can be re-sent at
+// most once per inviteResendCooldown.
+func (s *WorkspaceService) ResendInvite(ctx context.Context, workspaceID, inviteID, userID uuid.UUID) (*models.WorkspaceInvite, error) {
+	role, _ := s.memberRepo.GetRole(ctx, workspaceID, userID)
+	if role != "owner" && role != "admin" {
+		return nil, ErrNotAuthorized
+	}
+
+	invite, err := s.inviteRepo.GetByID(ctx, inviteID)
+	if err != nil || invite == nil || invite.WorkspaceID != workspaceID || invite.AcceptedAt != nil {
+		return nil, ErrInviteNotFound
+	}
+
+	quota, err := s.inviteQuota(ctx, workspaceID, userID, role)
+	if err != nil {
+		return nil, err
+	}
+	if !quota.Unlimited && quota.Remaining <= 0 {
+		return nil, ErrRateLimited
+	}
+	if err := s.checkDailyInviteCap(ctx, workspaceID, 1); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	invite.ExpiresAt = now.Add(s.inviteExpiry(ctx, workspaceID))
+	resent, err := s.inviteRepo.MarkResent(ctx, inviteID, invite.ExpiresAt, now, now.Add(-inviteResendCooldown))
+	if err != nil {
+		return nil, err
+	}
+	if !resent {
+		return nil, ErrRateLimited
+	}
+	invite.LastSentAt = &now
+	if err := s.invitationHistoryRepo.ExtendPending(ctx, workspaceID, invite.Email, invite.ExpiresAt); err != nil {
+		s.logger.WithError(err).Warn("Failed to extend invitation history expiry")
+	}
+	invite.Status = "pending"
+
+	s.LogActivity(ctx, workspaceID, userID, "invite.resent", "invite", inviteID.String(), models.JSON{"email": invite.Email, "expires_at": invite.ExpiresAt})
+	s.publishEvent(ctx, "notification-events", invite.ID.String(), "workspace.invite", map[string]interface{}{
+		"invite": invite,
+	})
+	return invite, nil
+}
+
+// inviteExpiry is how long new and resent invites stay valid, read from the
+// invite_expiry_days setting and capped at maxInviteExpiryDays.
+func (s *WorkspaceService) inviteExpiry(ctx context.Context, workspaceID uuid.UUID) time.Duration {
+	days := defaultInviteExpiryDays
+	if workspace, _ := s.workspaceRepo.GetByID(ctx, workspaceID); workspace != nil {
+		days = settingInt(workspace.Settings, settingInviteExpiryDays, defaultInviteExpiryDays)
+	}
+	if days > maxInviteExpiryDays {
+		days = maxInviteExpiryDays
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// normalizeInviteEmail trims and lowercases an address so case variants
+// resolve to one invite. With canonicalizeGmail set, Gmail addresses also
+// lose dots and any +tag in the local part, since Gmail ignores both.
+func (s *WorkspaceService) normalizeInviteEmail(email string) string {
+	email = strings.ToLower(strings.TrimSpace(email))
+	if !s.canonicalizeGmail {
+		return email
+	}
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return email
+	}
+	local, domain := email[:at], email[at+1:]
+	if domain != "gmail.com" && domain != "googlemail.com" {
+		return email
+	}
+	if plus := strings.Index(local, "+"); plus >= 0 {
+		local = local[:plus]
+	}
+	return strings.ReplaceAll(local, ".", "") + "@gmail.com"
+}
+
+// GetMyInviteQuota reports how many invites the caller can still send in the
+// current rolling window.
+func (s *WorkspaceService) GetMyInviteQuota(ctx context.Context, workspaceID, userID uuid.UUID) (*models.InviteQuota, error) {
+	role, _ := s.memberRepo.GetRole(ctx, workspaceID, userID)
+	if role == "" {
+		return nil, ErrNotMember
+	}
+	return s.inviteQuota(ctx, workspaceID, userID, role)
+}
+
+// inviteQuota applies the workspace's per-member invite limit over a rolling
+// window of invitation history. The owner is not limited.
+func (s *WorkspaceService) inviteQuota(ctx context.Context, workspaceID, userID uuid.UUID, role string) (*models.InviteQuota, error) {
+	if role == "owner" {
+		return &models.InviteQuota{Unlimited: true}, nil
+	}
+
+	workspace, err := s.workspaceRepo.GetByID(ctx, workspaceID)
+	if err != nil || workspace == nil {
+		return nil, ErrWorkspaceNotFound
+	}
+	limit := settingInt(workspace.Settings, settingInviteLimitPerMember, defaultInviteLimitPerMember)
+	windowHours := settingInt(workspace.Settings, settingInviteLimitWindow, defaultInviteLimitWindow)
+	window := time.Duration(windowHours) * time.Hour
+
+	used, oldest, err := s.invitationHistoryRepo.CountByInviterSince(ctx, workspaceID, userID, time.Now().Add(-window))
+	if err != nil {
+		return nil, err
+	}
+
+	quota := &models.InviteQuota{
+		Limit:       limit,
+		Used:        used,
+		Remaining:   limit - used,
+		WindowHours: windowHours,
+	}
+	if quota.Remaining < 0 {
+		quota.Remaining = 0
+	}
+	if oldest != nil {
+		resetsAt := oldest.Add(window)
+		quota.ResetsAt = &resetsAt
+	}
+	return quota, nil
+}
+
+// InviteCapError reports that a workspace has sent its plan's daily invite
+// allowance. It matches ErrDailyInviteCapReached with errors.Is.
+type InviteCapError struct {
+	Used     int
+	Limit    int
+	ResetsAt *time.Time
+}
+
+func (e *InviteCapError) Error() string {
+	return fmt.Sprintf("daily invite cap reached (%d/%d)", e.Used, e.Limit)
+}
+
+func (e *InviteCapError) Is(target error) bool {
+	return target == ErrDailyInviteCapReached
+}
+
+// checkDailyInviteCap fails if sending n more invites would take the
+// workspace past its plan's invite cap over the last 24 hours. Unlike the
+// per-member quota this applies to the owner too.
+func (s *WorkspaceService) checkDailyInviteCap(ctx context.Context, workspaceID uuid.UUID, n int) error {
+	workspace, err := s.workspaceRepo.GetByID(ctx, workspaceID)
+	if err != nil || workspace == nil {
+		return ErrWorkspaceNotFound
+	}
+	limit := planFeatures(workspace.Plan).MaxInvitesPerDay
+
+	used, oldest, err := s.invitationHistoryRepo.CountEmailedSince(ctx, workspaceID, time.Now().Add(-24*time.Hour))
+	if err != nil {
+		return err
+	}
+	if used+n <= limit {
+		return nil
+	}
+
+	capErr := &InviteCapError{Used: used, Limit: limit}
+	if oldest != nil {
+		resetsAt := oldest.Add(24 * time.Hour)
+		capErr.ResetsAt = &resetsAt
+	}
+	return capErr
+}
+
+// settingBool reads a boolean workspace setting; anything else is false.
+func settingBool(settings models.JSON, key string) bool {
+	v, _ := settings[key].(bool)
+	return v
+}
+
+// settingInt reads a positive integer workspace setting, falling back to def.
+func settingInt(settings models.JSON, key string, def int) int {
+	if settings != nil {
+		switch v := settings[key].(type) {
+		case float64:
+			if v >= 1 {
+				return int(v)
+			}
+		case int:
+			if v >= 1 {
+				return v
+			}
+		}
+	}
+	return def
+}
+
+func (s *WorkspaceService) BulkInvite(ctx context.Context, workspaceID uuid.UUID, inviterID uuid.UUID, req *models.BulkInviteRequest) (*models.BulkInviteResponse, error) {
+	role, _ := s.memberRepo.GetRole(ctx, workspaceID, inviterID)
+	if role != "owner" && role != "admin" {
+		return nil, ErrNotAuthorized
+	}
+	// Collapse addresses that normalize to the same invitee
+	seen := make(map[string]bool, len(req.Invites))
+	invites := make([]models.InviteMemberRequest, 0, len(req.Invites))
+	for _, inv := range req.Invites {
+		email := s.normalizeInviteEmail(inv.Email)
+		if seen[email] {
+			continue
+		}
+		seen[email] = true
+		inv.Email = email
+		invites = append(invites, inv)
+	}
+
+	// Reject the whole batch rather than sending part of it. Only addresses
+	// that will get an email count, as in InviteMember.
+	emails := 0
+	for _, inv := range invites {
+		if existing, err := s.existingInvite(ctx, workspaceID, inv.Email); err == nil && existing == nil {
+			emails++
+		}
+	}
+	if err := s.checkDailyInviteCap(ctx, workspaceID, emails); err != nil {
+		return nil, err
+	}
+
+	resp := &models.BulkInviteResponse{}
+	for _, inv := range invites {
+		_, err := s.InviteMember(ctx, workspaceID, inviterID, &inv)
+		if err != nil {
+			resp.Failed = append(resp.Failed, struct {
+				Email  string `json:"email"`
+				Reason string `json:"reason"`
+			}{Email: inv.Email, Reason: err.Error()})
+		} else {
+			resp.Successful = append(resp.Successful, inv.Email)
+		}
+	}
+
+	return resp, nil
+}
+
+func (s *WorkspaceService) AcceptInvite(ctx context.Context, token string, userID uuid.UUID) (*models.Workspace, error) {
+	invite, err := s.inviteRepo.GetByToken(ctx, token)
+	if err != nil || invite == nil {
+		return nil, ErrInviteNotFound
+	}
+
+	// Check if user is banned
+	isBanned, _ := s.moderationRepo.IsUserBanned(ctx, invite.WorkspaceID, userID)
+	if isBanned {
+		return nil, ErrUserBanned
+	}
+
+	isMember, _ := s.memberRepo.IsMember(ctx, invite.WorkspaceID, userID)
+	if isMember {
+		return nil, ErrAlreadyMember
+	}
+
+	workspace, err := s.workspaceRepo.GetByID(ctx, invite.WorkspaceID)
+	if err != nil || workspace == nil {
+		return nil, ErrWorkspaceNotFound
+	}
+	if err := s.checkRejoinCooldown(ctx, workspace, userID, invite); err != nil {
+		return nil, err
+	}
+
+	member := &models.WorkspaceMember{
+		ID:          uuid.New(),
 		WorkspaceID: invite.WorkspaceID,
 		UserID:      userID,
 		Role:        invite.Role,
@@ -580,18 +1448,20 @@ func (s *WorkspaceService) AcceptInvite(ctx context.Context, token string, userI
 		UpdatedAt:   time.Now(),
 	}
 
-	if err := s.memberRepo.Create(ctx, member); err != nil {
+	err = s.createMemberWithEvent(ctx, member, "workspace-events", invite.WorkspaceID.String(), "member.joined", map[string]interface{}{
+		"workspace_id": invite.WorkspaceID,
+		"user_id":      userID,
+		"role":         invite.Role,
+	})
+	if err != nil {
 		return nil, err
 	}
 
 	s.inviteRepo.MarkAccepted(ctx, invite.ID)
+	s.invitationHistoryRepo.MarkAccepted(ctx, invite.WorkspaceID, invite.Email, userID)
 	s.invalidateWorkspace(ctx, invite.WorkspaceID)
 	s.invalidateUserWorkspaces(ctx, userID)
-	s.publishEvent(ctx, "workspace-events", invite.WorkspaceID.String(), "member.joined", map[string]interface{}{
-		"workspace_id": invite.WorkspaceID,
-		"user_id":      userID,
-		"role":         invite.Role,
-	})
+	s.LogActivity(ctx, invite.WorkspaceID, userID, "member.joined", "member", userID.String(), models.JSON{"method": "invite", "role": invite.Role})
 
 	return s.workspaceRepo.GetByID(ctx, invite.WorkspaceID)
 }
@@ -603,59 +1473,256 @@ func (s *WorkspaceService) RemoveMember(ctx context.Context, workspaceID, member
 	}
 
 	memberRole, _ := s.memberRepo.GetRole(ctx, workspaceID, memberUserID)
+	if err := s.guardLastOwner(ctx, workspaceID, memberRole); err != nil {
+		return err
+	}
 	if memberRole == "owner" {
 		return ErrNotAuthorized
 	}
 
-	if err := s.memberRepo.Remove(ctx, workspaceID, memberUserID); err != nil {
+	if err := s.removeMemberWithEvent(ctx, workspaceID, memberUserID, "member.removed", map[string]interface{}{
+		"workspace_id": workspaceID,
+		"user_id":      memberUserID,
+		"removed_by":   requestorID,
+	}); err != nil {
 		return err
 	}
 
 	s.invalidateWorkspace(ctx, workspaceID)
 	s.invalidateUserWorkspaces(ctx, memberUserID)
-	s.publishEvent(ctx, "workspace-events", workspaceID.String(), "member.removed", map[string]interface{}{
-		"workspace_id": workspaceID,
-		"user_id":      memberUserID,
-		"removed_by":   requestorID,
-	})
+	s.LogActivity(ctx, workspaceID, requestorID, "member.removed", "member", memberUserID.String(), nil)
 
 	return nil
 }
 
+// SuggestInactiveMembers lists members an admin may want to prune: not seen
+// and with no logged activity in the last inactiveDays days. Owners are never
+// suggested. Nothing is changed; see BulkRemoveMembers.
+func (s *WorkspaceService) SuggestInactiveMembers(ctx context.Context, workspaceID, userID uuid.UUID, inactiveDays int) ([]*models.WorkspaceMember, error) {
+	role, _ := s.memberRepo.GetRole(ctx, workspaceID, userID)
+	if role != "owner" && role != "admin" {
+		return nil, ErrNotAuthorized
+	}
+
+	since := time.Now().AddDate(0, 0, -inactiveDays)
+	candidates, err := s.memberRepo.ListInactiveSince(ctx, workspaceID, since)
+	if err != nil {
+		return nil, err
+	}
+	actors, err := s.activityRepo.ListActorsSince(ctx, workspaceID, since)
+	if err != nil {
+		return nil, err
+	}
+	active := make(map[uuid.UUID]bool, len(actors))
+	for _, id := range actors {
+		active[id] = true
+	}
+
+	suggestions := make([]*models.WorkspaceMember, 0, len(candidates))
+	for _, m := range candidates {
+		if m.Role == "owner" || active[m.UserID] {
+			continue
+		}
+		suggestions = append(suggestions, m)
+	}
+	return suggestions, nil
+}
+
+// BulkRemoveMembers removes each member in turn with RemoveMember's rules,
+// reporting per-member failures instead of stopping at the first.
+func (s *WorkspaceService) BulkRemoveMembers(ctx context.Context, workspaceID, requestorID uuid.UUID, req *models.BulkRemoveMembersRequest) (*models.BulkRemoveMembersResponse, error) {
+	role, _ := s.memberRepo.GetRole(ctx, workspaceID, requestorID)
+	if role != "owner" && role != "admin" {
+		return nil, ErrNotAuthorized
+	}
+
+	resp := &models.BulkRemoveMembersResponse{}
+	for _, raw := range req.UserIDs {
+		reason := ""
+		if memberUserID, err := uuid.Parse(raw); err != nil {
+			reason = "invalid user ID"
+		} else if memberUserID == requestorID {
+			reason = "cannot remove yourself"
+		} else if err := s.RemoveMember(ctx, workspaceID, memberUserID, requestorID); err != nil {
+			reason = err.Error()
+		}
+
+		if reason != "" {
+			resp.Failed = append(resp.Failed, struct {
+				UserID string `json:"user_id"`
+				Reason string `json:"reason"`
+			}{UserID: raw, Reason: reason})
+		} else {
+			resp.Removed = append(resp.Removed, raw)
+		}
+	}
+	return resp, nil
+}
+
 func (s *WorkspaceService) UpdateMemberRole(ctx context.Context, workspaceID, memberUserID, requestorID uuid.UUID, newRole string) error {
 	role, _ := s.memberRepo.GetRole(ctx, workspaceID, requestorID)
 	if role != "owner" {
 		return ErrNotAuthorized
 	}
 
-	if err := s.memberRepo.UpdateRole(ctx, workspaceID, memberUserID, newRole); err != nil {
-		return err
+	if newRole != "owner" {
+		currentRole, _ := s.memberRepo.GetRole(ctx, workspaceID, memberUserID)
+		if err := s.guardLastOwner(ctx, workspaceID, currentRole); err != nil {
+			return err
+		}
 	}
 
-	s.invalidateWorkspace(ctx, workspaceID)
-	s.publishEvent(ctx, "workspace-events", workspaceID.String(), "member.role_updated", map[string]interface{}{
+	if err := s.updateMemberRoleWithEvent(ctx, workspaceID, memberUserID, newRole, "member.role_updated", map[string]interface{}{
 		"workspace_id": workspaceID,
 		"user_id":      memberUserID,
 		"new_role":     newRole,
 		"updated_by":   requestorID,
-	})
+	}); err != nil {
+		return err
+	}
+
+	s.invalidateWorkspace(ctx, workspaceID)
+
+	return nil
+}
 
+// guardLastOwner rejects taking the owner role away from a member (demote,
+// remove or leave) when they are the only owner left.
+func (s *WorkspaceService) guardLastOwner(ctx context.Context, workspaceID uuid.UUID, memberRole string) error {
+	if memberRole != "owner" {
+		return nil
+	}
+	owners, err := s.memberRepo.CountByRole(ctx, workspaceID, "owner")
+	if err != nil {
+		return err
+	}
+	if owners <= 1 {
+		return ErrLastOwner
+	}
 	return nil
 }
 
-func (s *WorkspaceService) ListMembers(ctx context.Context, workspaceID uuid.UUID, page, perPage int) ([]*models.WorkspaceMember, int64, error) {
-	return s.memberRepo.ListByWorkspace(ctx, workspaceID, page, perPage)
+// ListMembers lists the member directory. Guests cannot browse it.
+func (s *WorkspaceService) ListMembers(ctx context.Context, workspaceID, userID uuid.UUID, page, perPage int) ([]*models.WorkspaceMember, int64, error) {
+	if err := s.requireCapability(ctx, workspaceID, userID, capMemberDirectory); err != nil {
+		return nil, 0, err
+	}
+	members, total, err := s.memberRepo.ListByWorkspace(ctx, workspaceID, page, perPage)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	userIDs := make([]uuid.UUID, len(members))
+	for i, m := range members {
+		userIDs[i] = m.UserID
+	}
+	profiles, err := s.profileRepo.ListByUsers(ctx, workspaceID, userIDs)
+	if err != nil {
+		return nil, 0, err
+	}
+	lastSeen := make(map[uuid.UUID]*time.Time, len(profiles))
+	for _, p := range profiles {
+		lastSeen[p.UserID] = p.LastSeenAt
+	}
+	for _, m := range members {
+		m.LastSeenAt = lastSeen[m.UserID]
+	}
+	return members, total, nil
+}
+
+// TouchMemberSeen records that a member was just active. Writes are
+// debounced through Redis to at most one per member per minute; without
+// Redis every call writes.
+func (s *WorkspaceService) TouchMemberSeen(ctx context.Context, workspaceID, userID uuid.UUID) error {
+	key := fmt.Sprintf(cacheKeyMemberSeen, workspaceID.String(), userID.String())
+	if fresh, err := s.kv.SetNX(ctx, key, 1, memberSeenDebounce); err == nil && !fresh {
+		return nil
+	}
+	return s.profileRepo.TouchLastSeen(ctx, workspaceID, userID, time.Now())
+}
+
+// GetInactiveMembers lists members not seen since the given time, for
+// admins looking for dormant accounts. Members who joined after since are
+// not included.
+func (s *WorkspaceService) GetInactiveMembers(ctx context.Context, workspaceID, userID uuid.UUID, since time.Time) ([]*models.WorkspaceMember, error) {
+	role, _ := s.memberRepo.GetRole(ctx, workspaceID, userID)
+	if role != "owner" && role != "admin" {
+		return nil, ErrNotAuthorized
+	}
+	return s.memberRepo.ListInactiveSince(ctx, workspaceID, since)
+}
+
+// SearchMembers finds members by profile display name, title or invited
+// email. Any member of the workspace except guests may search.
+func (s *WorkspaceService) SearchMembers(ctx context.Context, workspaceID, userID uuid.UUID, query string, page, perPage int) ([]*models.MemberWithProfile, int64, error) {
+	if err := s.requireCapability(ctx, workspaceID, userID, capMemberDirectory); err != nil {
+		return nil, 0, err
+	}
+
+	if page < 1 {
+		page = 1
+	}
+	if perPage < 1 || perPage > 100 {
+		perPage = 20
+	}
+
+	members, total, err := s.memberRepo.Search(ctx, workspaceID, strings.TrimSpace(query), page, perPage)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	userIDs := make([]uuid.UUID, len(members))
+	for i, m := range members {
+		userIDs[i] = m.UserID
+	}
+	profiles, err := s.profileRepo.ListByUsers(ctx, workspaceID, userIDs)
+	if err != nil {
+		return nil, 0, err
+	}
+	byUser := make(map[uuid.UUID]*models.MemberProfile, len(profiles))
+	for _, p := range profiles {
+		byUser[p.UserID] = p
+	}
+
+	results := make([]*models.MemberWithProfile, len(members))
+	for i, m := range members {
+		results[i] = &models.MemberWithProfile{WorkspaceMember: *m, Profile: byUser[m.UserID]}
+	}
+	return results, total, nil
 }
 
 // ── Invite Management ──
 
-func (s *WorkspaceService) ListInvites(ctx context.Context, workspaceID uuid.UUID, userID uuid.UUID) ([]*models.WorkspaceInvite, error) {
+// ListInvites lists the workspace's invites filtered by status; "all" or ""
+// returns every invite. Each invite carries its computed status.
+func (s *WorkspaceService) ListInvites(ctx context.Context, workspaceID uuid.UUID, userID uuid.UUID, status string) ([]*models.WorkspaceInvite, error) {
 	role, _ := s.memberRepo.GetRole(ctx, workspaceID, userID)
 	if role != "owner" && role != "admin" {
 		return nil, ErrNotAuthorized
 	}
 
-	return s.inviteRepo.ListByWorkspace(ctx, workspaceID)
+	if status == "all" {
+		status = ""
+	}
+	invites, err := s.inviteRepo.ListByWorkspace(ctx, workspaceID, status)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	for _, inv := range invites {
+		inv.Status = inviteStatus(inv, now)
+	}
+	return invites, nil
+}
+
+func inviteStatus(inv *models.WorkspaceInvite, now time.Time) string {
+	switch {
+	case inv.AcceptedAt != nil:
+		return "accepted"
+	case !now.Before(inv.ExpiresAt):
+		return "expired"
+	}
+	return "pending"
 }
 
 func (s *WorkspaceService) RevokeInvite(ctx context.Context, workspaceID uuid.UUID, inviteID uuid.UUID, userID uuid.UUID) error {
@@ -665,14 +1732,10 @@ func (s *WorkspaceService) RevokeInvite(ctx context.Context, workspaceID uuid.UU
 	}
 
 	invite, err := s.inviteRepo.GetByID(ctx, inviteID)
-	if err != nil || invite == nil {
+	if err != nil || invite == nil || invite.WorkspaceID != workspaceID {
 		return ErrInviteNotFound
 	}
 
-	if invite.WorkspaceID != workspaceID {
-		return ErrNotAuthorized
-	}
-
 	return s.inviteRepo.Delete(ctx, inviteID)
 }
 
@@ -686,16 +1749,17 @@ func (s *WorkspaceService) CreateInviteCode(ctx context.Context, workspaceID uui
 
 	code := generateInviteCode()
 	inviteCode := &models.WorkspaceInviteCode{
-		ID:          uuid.New(),
-		WorkspaceID: workspaceID,
-		Code:        code,
-		Role:        req.Role,
-		MaxUses:     req.MaxUses,
-		UseCount:    0,
-		CreatedBy:   userID,
-		IsActive:    true,
-		CreatedAt:   time.Now(),
-		UpdatedAt:   time.Now(),
+		ID:               uuid.New(),
+		WorkspaceID:      workspaceID,
+		Code:             code,
+		Role:             req.Role,
+		MaxUses:          req.MaxUses,
+		UseCount:         0,
+		CreatedBy:        userID,
+		IsActive:         true,
+		ApprovalRequired: req.ApprovalRequired,
+		CreatedAt:        time.Now(),
+		UpdatedAt:        time.Now(),
 	}
 
 	if err := s.inviteCodeRepo.Create(ctx, inviteCode); err != nil {
@@ -705,25 +1769,42 @@ func (s *WorkspaceService) CreateInviteCode(ctx context.Context, workspaceID uui
 	return inviteCode, nil
 }
 
-func (s *WorkspaceService) JoinByCode(ctx context.Context, code string, userID uuid.UUID) (*models.Workspace, error) {
+// JoinByCode adds the user to the code's workspace. For a code that
+// requires approval it opens a join request instead and returns that, with
+// a nil workspace.
+func (s *WorkspaceService) JoinByCode(ctx context.Context, code string, userID uuid.UUID) (*models.Workspace, *models.WorkspaceJoinRequest, error) {
 	inviteCode, err := s.inviteCodeRepo.GetByCode(ctx, code)
 	if err != nil || inviteCode == nil {
-		return nil, ErrInviteCodeNotFound
+		return nil, nil, ErrInviteCodeNotFound
 	}
 
 	if inviteCode.MaxUses > 0 && inviteCode.UseCount >= inviteCode.MaxUses {
-		return nil, ErrInviteCodeMaxUsed
+		return nil, nil, ErrInviteCodeMaxUsed
 	}
 
 	// Check if user is banned
 	isBanned, _ := s.moderationRepo.IsUserBanned(ctx, inviteCode.WorkspaceID, userID)
 	if isBanned {
-		return nil, ErrUserBanned
+		return nil, nil, ErrUserBanned
 	}
 
 	isMember, _ := s.memberRepo.IsMember(ctx, inviteCode.WorkspaceID, userID)
 	if isMember {
-		return nil, ErrAlreadyMember
+		return nil, nil, ErrAlreadyMember
+	}
+
+	workspace, err := s.workspaceRepo.GetByID(ctx, inviteCode.WorkspaceID)
+	if err != nil || workspace == nil {
+		return nil, nil, ErrWorkspaceNotFound
+	}
+	if err := s.checkRejoinCooldown(ctx, workspace, userID, nil); err != nil {
+		return nil, nil, err
+	}
+	inviteCode.Role = codeJoinRole(workspace, inviteCode)
+
+	if inviteCode.ApprovalRequired {
+		request, err := s.requestToJoin(ctx, inviteCode, userID)
+		return nil, request, err
 	}
 
 	member := &models.WorkspaceMember{
@@ -738,20 +1819,128 @@ func (s *WorkspaceService) JoinByCode(ctx context.Context, code string, userID u
 		UpdatedAt:   time.Now(),
 	}
 
-	if err := s.memberRepo.Create(ctx, member); err != nil {
-		return nil, err
+	claimed, err := s.inviteCodeRepo.ClaimUse(ctx, inviteCode.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !claimed {
+		return nil, nil, ErrInviteCodeMaxUsed
 	}
 
-	s.inviteCodeRepo.IncrementUseCount(ctx, inviteCode.ID)
-	s.invalidateWorkspace(ctx, inviteCode.WorkspaceID)
-	s.invalidateUserWorkspaces(ctx, userID)
-	s.publishEvent(ctx, "workspace-events", inviteCode.WorkspaceID.String(), "member.joined_by_code", map[string]interface{}{
+	err = s.createMemberWithEvent(ctx, member, "workspace-events", inviteCode.WorkspaceID.String(), "member.joined_by_code", map[string]interface{}{
 		"workspace_id": inviteCode.WorkspaceID,
 		"user_id":      userID,
 		"invite_code":  code,
 	})
+	if err != nil {
+		s.inviteCodeRepo.ReleaseUse(ctx, inviteCode.ID)
+		return nil, nil, err
+	}
+
+	s.recordCodeJoin(ctx, inviteCode, userID)
+	s.invalidateWorkspace(ctx, inviteCode.WorkspaceID)
+	s.invalidateUserWorkspaces(ctx, userID)
+	s.LogActivity(ctx, inviteCode.WorkspaceID, userID, "member.joined", "member", userID.String(), models.JSON{"method": "invite_code", "role": inviteCode.Role})
+
+	workspace, err = s.workspaceRepo.GetByID(ctx, inviteCode.WorkspaceID)
+	return workspace, nil, err
+}
+
+// codeJoinRole is the role a member joining through the code gets. In order
+// of precedence: the code's own role, the workspace's default_member_role
+// setting, then "member".
+func codeJoinRole(workspace *models.Workspace, inviteCode *models.WorkspaceInviteCode) string {
+	if inviteCode.Role != "" {
+		return inviteCode.Role
+	}
+	if role, ok := workspace.Settings[settingDefaultMemberRole].(string); ok && settingsSchema[settingDefaultMemberRole](role) == "" {
+		return role
+	}
+	return defaultMemberRole
+}
+
+// GetInviteCodePreview returns what an invite landing page may show about a
+// code without joining. It needs no authentication, so only public workspace
+// details are included; settings and the workspace ID stay hidden unless
+// the workspace is listed in the directory.
+func (s *WorkspaceService) GetInviteCodePreview(ctx context.Context, code string) (*models.InviteCodePreview, error) {
+	inviteCode, err := s.inviteCodeRepo.GetByCodeAnyState(ctx, code)
+	if err != nil || inviteCode == nil {
+		return nil, ErrInviteCodeNotFound
+	}
+
+	workspace, err := s.workspaceRepo.GetByID(ctx, inviteCode.WorkspaceID)
+	if err != nil || workspace == nil || !workspace.IsActive {
+		return nil, ErrInviteCodeNotFound
+	}
+	memberCount, _ := s.workspaceRepo.GetMemberCount(ctx, workspace.ID)
+
+	preview := &models.InviteCodePreview{
+		Status:      inviteCodeStatus(inviteCode, time.Now()),
+		Name:        workspace.Name,
+		Description: workspace.Description,
+		IconURL:     workspace.IconURL,
+		MemberCount: memberCount,
+		Role:        codeJoinRole(workspace, inviteCode),
+		ExpiresAt:   inviteCode.ExpiresAt,
+	}
+	preview.Valid = preview.Status == "valid"
+
+	if entry, _ := s.discoveryRepo.GetDirectoryEntry(ctx, workspace.ID); entry != nil && entry.IsListed {
+		preview.WorkspaceID = &workspace.ID
+	}
+	if msg, ok := workspace.Settings[settingInviteLandingMessage].(string); ok && msg != "" {
+		preview.LandingMessage = &msg
+	}
+	return preview, nil
+}
+
+func inviteCodeStatus(code *models.WorkspaceInviteCode, now time.Time) string {
+	switch {
+	case !code.IsActive:
+		return "revoked"
+	case code.ExpiresAt != nil && !now.Before(*code.ExpiresAt):
+		return "expired"
+	case code.MaxUses > 0 && code.UseCount >= code.MaxUses:
+		return "full"
+	}
+	return "valid"
+}
+
+// CreateMagicJoinLink creates a single-use, short-lived invite code that is
+// not tied to an email address, along with the link to share.
+func (s *WorkspaceService) CreateMagicJoinLink(ctx context.Context, workspaceID uuid.UUID, role string, userID uuid.UUID) (*models.MagicJoinLink, error) {
+	requesterRole, _ := s.memberRepo.GetRole(ctx, workspaceID, userID)
+	if requesterRole != "owner" && requesterRole != "admin" {
+		return nil, ErrNotAuthorized
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(magicLinkTTL)
+	inviteCode := &models.WorkspaceInviteCode{
+		ID:          uuid.New(),
+		WorkspaceID: workspaceID,
+		Code:        randomCode(magicLinkCodeLength),
+		Role:        role,
+		MaxUses:     1,
+		CreatedBy:   userID,
+		ExpiresAt:   &expiresAt,
+		IsActive:    true,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := s.inviteCodeRepo.Create(ctx, inviteCode); err != nil {
+		return nil, err
+	}
 
-	return s.workspaceRepo.GetByID(ctx, inviteCode.WorkspaceID)
+	s.LogActivity(ctx, workspaceID, userID, "invite.magic_link_created", "invite_code", inviteCode.ID.String(), models.JSON{"role": role})
+	return &models.MagicJoinLink{
+		InviteCodeID: inviteCode.ID,
+		Code:         inviteCode.Code,
+		URL:          s.joinBaseURL + "/join?code=" + url.QueryEscape(inviteCode.Code),
+		Role:         role,
+		ExpiresAt:    expiresAt,
+	}, nil
 }
 
 func (s *WorkspaceService) ListInviteCodes(ctx context.Context, workspaceID uuid.UUID, userID uuid.UUID) ([]*models.WorkspaceInviteCode, error) {
@@ -774,39 +1963,135 @@ func (s *WorkspaceService) RevokeInviteCode(ctx context.Context, workspaceID uui
 
 // ── Activity Log ──
 
-func (s *WorkspaceService) LogActivity(ctx context.Context, workspaceID, actorID uuid.UUID, action, entityType, entityID string, details models.JSON) {
-	log := &models.ActivityLog{
-		ID:          uuid.New(),
+func (s *WorkspaceService) LogActivity(ctx context.Context, workspaceID, actorID uuid.UUID, action, entityType, entityID string, details models.JSON) {
+	if id := requestid.FromContext(ctx); id != "" {
+		tagged := make(models.JSON, len(details)+1)
+		for k, v := range details {
+			tagged[k] = v
+		}
+		tagged["request_id"] = id
+		details = tagged
+	}
+	log := &models.ActivityLog{
+		ID:          uuid.New(),
+		WorkspaceID: workspaceID,
+		ActorID:     actorID,
+		Action:      action,
+		EntityType:  entityType,
+		EntityID:    entityID,
+		Details:     details,
+		CreatedAt:   time.Now(),
+	}
+	if err := s.activityRepo.Create(ctx, log); err != nil {
+		s.requestLogger(ctx).WithError(err).Warn("Failed to log activity")
+		return
+	}
+	s.auditSink.Forward(ctx, workspaceID, "activity_log", log)
+}
+
+// GetActivityLog pages through the activity log, newest first. Pinned
+// entries are flagged; with pinnedFirst they are also lifted to the top of
+// the first page and left out of the chronological pages.
+func (s *WorkspaceService) GetActivityLog(ctx context.Context, workspaceID uuid.UUID, userID uuid.UUID, page, perPage int, pinnedFirst bool) (*models.ActivityLogResponse, error) {
+	isMember, _ := s.memberRepo.IsMember(ctx, workspaceID, userID)
+	if !isMember {
+		return nil, ErrNotMember
+	}
+
+	activities, total, err := s.activityRepo.ListByWorkspace(ctx, workspaceID, page, perPage)
+	if err != nil {
+		return nil, err
+	}
+
+	pinned, err := s.activityRepo.ListPinned(ctx, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	pinnedIDs := make(map[uuid.UUID]bool, len(pinned))
+	for _, a := range pinned {
+		a.IsPinned = true
+		pinnedIDs[a.ID] = true
+	}
+
+	if pinnedFirst {
+		rest := make([]*models.ActivityLog, 0, len(activities))
+		for _, a := range activities {
+			if !pinnedIDs[a.ID] {
+				rest = append(rest, a)
+			}
+		}
+		activities = rest
+		if page <= 1 {
+			activities = append(pinned, activities...)
+		}
+	} else {
+		for _, a := range activities {
+			a.IsPinned = pinnedIDs[a.ID]
+		}
+	}
+
+	return &models.ActivityLogResponse{
+		Activities: activities,
+		Total:      total,
+		Page:       page,
+		PerPage:    perPage,
+	}, nil
+}
+
+// PinActivity highlights an activity entry for the workspace. Owners and
+// admins only; at most maxPinnedActivity entries can be pinned.
+func (s *WorkspaceService) PinActivity(ctx context.Context, workspaceID, userID, activityID uuid.UUID) (*models.PinnedActivity, error) {
+	role, _ := s.memberRepo.GetRole(ctx, workspaceID, userID)
+	if role != "owner" && role != "admin" {
+		return nil, ErrNotAuthorized
+	}
+
+	activity, err := s.activityRepo.GetByID(ctx, activityID)
+	if err != nil {
+		return nil, err
+	}
+	if activity == nil || activity.WorkspaceID != workspaceID {
+		return nil, ErrActivityNotFound
+	}
+
+	pin := &models.PinnedActivity{
 		WorkspaceID: workspaceID,
-		ActorID:     actorID,
-		Action:      action,
-		EntityType:  entityType,
-		EntityID:    entityID,
-		Details:     details,
+		ActivityID:  activityID,
+		PinnedBy:    userID,
 		CreatedAt:   time.Now(),
 	}
-	if err := s.activityRepo.Create(ctx, log); err != nil {
-		s.logger.WithError(err).Warn("Failed to log activity")
+	if already, _ := s.activityRepo.IsPinned(ctx, workspaceID, activityID); already {
+		return pin, nil
+	}
+	count, err := s.activityRepo.CountPinned(ctx, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	if count >= maxPinnedActivity {
+		return nil, ErrActivityPinLimitReached
+	}
+
+	if err := s.activityRepo.Pin(ctx, pin); err != nil {
+		return nil, err
 	}
+	return pin, nil
 }
 
-func (s *WorkspaceService) GetActivityLog(ctx context.Context, workspaceID uuid.UUID, userID uuid.UUID, page, perPage int) (*models.ActivityLogResponse, error) {
-	isMember, _ := s.memberRepo.IsMember(ctx, workspaceID, userID)
-	if !isMember {
-		return nil, ErrNotMember
+// UnpinActivity removes an entry's highlight. Owners and admins only.
+func (s *WorkspaceService) UnpinActivity(ctx context.Context, workspaceID, userID, activityID uuid.UUID) error {
+	role, _ := s.memberRepo.GetRole(ctx, workspaceID, userID)
+	if role != "owner" && role != "admin" {
+		return ErrNotAuthorized
 	}
 
-	activities, total, err := s.activityRepo.ListByWorkspace(ctx, workspaceID, page, perPage)
+	removed, err := s.activityRepo.Unpin(ctx, workspaceID, activityID)
 	if err != nil {
-		return nil, err
+		return err
 	}
-
-	return &models.ActivityLogResponse{
-		Activities: activities,
-		Total:      total,
-		Page:       page,
-		PerPage:    perPage,
-	}, nil
+	if removed == 0 {
+		return ErrActivityNotPinned
+	}
+	return nil
 }
 
 func (s *WorkspaceService) GetActivityLogByActor(ctx context.Context, workspaceID, actorID, userID uuid.UUID, page, perPage int) (*models.ActivityLogResponse, error) {
@@ -880,6 +2165,14 @@ func (s *WorkspaceService) UpdateMemberProfile(ctx context.Context, workspaceID,
 		profile.Timezone = req.Timezone
 	}
 
+	workspace, err := s.workspaceRepo.GetByID(ctx, workspaceID)
+	if err != nil || workspace == nil {
+		return nil, ErrWorkspaceNotFound
+	}
+	if len(missingProfileFields(profile, requiredProfileFields(workspace))) > 0 {
+		return nil, ErrProfileFieldsRequired
+	}
+
 	if err := s.profileRepo.Upsert(ctx, profile); err != nil {
 		return nil, err
 	}
@@ -888,6 +2181,104 @@ func (s *WorkspaceService) UpdateMemberProfile(ctx context.Context, workspaceID,
 	return profile, nil
 }
 
+// SetRequiredProfileFields sets which profile fields members must fill in.
+// An empty list removes the requirement.
+func (s *WorkspaceService) SetRequiredProfileFields(ctx context.Context, workspaceID, userID uuid.UUID, fields []string) (models.JSON, error) {
+	workspace, err := s.workspaceRepo.GetByID(ctx, workspaceID)
+	if err != nil || workspace == nil {
+		return nil, ErrWorkspaceNotFound
+	}
+
+	role, _ := s.memberRepo.GetRole(ctx, workspaceID, userID)
+	if role != "owner" && role != "admin" {
+		return nil, ErrNotAuthorized
+	}
+
+	var value interface{}
+	if len(fields) > 0 {
+		value = fields
+	}
+	if err := s.saveWorkspaceSettings(ctx, workspace, userID, withSetting(workspace.Settings, settingRequiredProfileFields, value)); err != nil {
+		return nil, err
+	}
+
+	s.invalidateWorkspace(ctx, workspaceID)
+	s.LogActivity(ctx, workspaceID, userID, "profile.required_fields_updated", "workspace", workspaceID.String(), models.JSON{"fields": fields})
+	return workspace.Settings, nil
+}
+
+func (s *WorkspaceService) GetProfileCompleteness(ctx context.Context, workspaceID, userID uuid.UUID) (*models.ProfileCompleteness, error) {
+	isMember, _ := s.memberRepo.IsMember(ctx, workspaceID, userID)
+	if !isMember {
+		return nil, ErrNotMember
+	}
+
+	workspace, err := s.workspaceRepo.GetByID(ctx, workspaceID)
+	if err != nil || workspace == nil {
+		return nil, ErrWorkspaceNotFound
+	}
+	profile, err := s.profileRepo.GetByWorkspaceAndUser(ctx, workspaceID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	required := requiredProfileFields(workspace)
+	missing := missingProfileFields(profile, required)
+	return &models.ProfileCompleteness{
+		RequiredFields: required,
+		MissingFields:  missing,
+		IsComplete:     len(missing) == 0,
+	}, nil
+}
+
+// requiredProfileFields reads the required_profile_fields setting, ignoring
+// anything that is not a known profile field.
+func requiredProfileFields(workspace *models.Workspace) []string {
+	fields := []string{}
+	if workspace.Settings == nil {
+		return fields
+	}
+	var raw []string
+	switch v := workspace.Settings[settingRequiredProfileFields].(type) {
+	case []string:
+		raw = v
+	case []interface{}:
+		for _, f := range v {
+			if s, ok := f.(string); ok {
+				raw = append(raw, s)
+			}
+		}
+	}
+	for _, f := range raw {
+		switch f {
+		case "display_name", "title", "timezone":
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+
+func missingProfileFields(profile *models.MemberProfile, required []string) []string {
+	missing := []string{}
+	for _, field := range required {
+		var value *string
+		if profile != nil {
+			switch field {
+			case "display_name":
+				value = profile.DisplayName
+			case "title":
+				value = profile.Title
+			case "timezone":
+				value = profile.Timezone
+			}
+		}
+		if value == nil || strings.TrimSpace(*value) == "" {
+			missing = append(missing, field)
+		}
+	}
+	return missing
+}
+
 func (s *WorkspaceService) SetOnlineStatus(ctx context.Context, workspaceID, userID uuid.UUID, isOnline bool) error {
 	return s.profileRepo.UpdateOnlineStatus(ctx, workspaceID, userID, isOnline)
 }
@@ -900,6 +2291,14 @@ func (s *WorkspaceService) CreateRole(ctx context.Context, workspaceID, userID u
 		return nil, ErrNotAuthorized
 	}
 
+	if err := validatePermissions(req.Permissions); err != nil {
+		return nil, err
+	}
+	color, err := normalizeOptionalColor(req.Color)
+	if err != nil {
+		return nil, err
+	}
+
 	existing, _ := s.roleRepo.GetByName(ctx, workspaceID, req.Name)
 	if existing != nil {
 		return nil, ErrRoleNameExists
@@ -909,7 +2308,7 @@ func (s *WorkspaceService) CreateRole(ctx context.Context, workspaceID, userID u
 		ID:          uuid.New(),
 		WorkspaceID: workspaceID,
 		Name:        req.Name,
-		Color:       req.Color,
+		Color:       color,
 		Priority:    req.Priority,
 		Permissions: req.Permissions,
 		IsDefault:   false,
@@ -937,12 +2336,11 @@ func (s *WorkspaceService) UpdateRole(ctx context.Context, workspaceID, roleID,
 	}
 
 	existingRole, err := s.roleRepo.GetByID(ctx, roleID)
-	if err != nil || existingRole == nil {
+	if err != nil || existingRole == nil || existingRole.WorkspaceID != workspaceID {
 		return nil, ErrRoleNotFound
 	}
-
-	if existingRole.WorkspaceID != workspaceID {
-		return nil, ErrNotAuthorized
+	if req.Version != nil && *req.Version != existingRole.Version {
+		return nil, ErrConflict
 	}
 
 	if req.Name != nil {
@@ -953,18 +2351,29 @@ func (s *WorkspaceService) UpdateRole(ctx context.Context, workspaceID, roleID,
 		existingRole.Name = *req.Name
 	}
 	if req.Color != nil {
-		existingRole.Color = req.Color
+		color, err := normalizeOptionalColor(req.Color)
+		if err != nil {
+			return nil, err
+		}
+		existingRole.Color = color
 	}
 	if req.Priority != nil {
 		existingRole.Priority = *req.Priority
 	}
 	if req.Permissions != nil {
+		if err := validatePermissions(req.Permissions); err != nil {
+			return nil, err
+		}
 		existingRole.Permissions = req.Permissions
 	}
 
-	if err := s.roleRepo.Update(ctx, existingRole); err != nil {
+	ok, err := s.roleRepo.Update(ctx, existingRole)
+	if err != nil {
 		return nil, err
 	}
+	if !ok {
+		return nil, ErrConflict
+	}
 
 	s.LogActivity(ctx, workspaceID, userID, "role.updated", "role", roleID.String(), models.JSON{"name": existingRole.Name})
 	return existingRole, nil
@@ -993,6 +2402,129 @@ func (s *WorkspaceService) DeleteRole(ctx context.Context, workspaceID, roleID,
 	return nil
 }
 
+// SetMaxRolesPerMember stores the cap on custom roles a single member may
+// hold at once in the workspace settings.
+func (s *WorkspaceService) SetMaxRolesPerMember(ctx context.Context, workspaceID, userID uuid.UUID, maxRoles int) (models.JSON, error) {
+	workspace, err := s.workspaceRepo.GetByID(ctx, workspaceID)
+	if err != nil || workspace == nil {
+		return nil, ErrWorkspaceNotFound
+	}
+
+	role, _ := s.memberRepo.GetRole(ctx, workspaceID, userID)
+	if role != "owner" {
+		return nil, ErrNotAuthorized
+	}
+
+	if err := s.saveWorkspaceSettings(ctx, workspace, userID, withSetting(workspace.Settings, settingMaxRolesPerMember, maxRoles)); err != nil {
+		return nil, err
+	}
+
+	s.invalidateWorkspace(ctx, workspaceID)
+	s.LogActivity(ctx, workspaceID, userID, "role.member_limit_updated", "workspace", workspaceID.String(), models.JSON{"max_roles": maxRoles})
+	return workspace.Settings, nil
+}
+
+func (s *WorkspaceService) AssignMemberRole(ctx context.Context, workspaceID, memberUserID, roleID, requestorID uuid.UUID) (*models.MemberRoleAssignment, error) {
+	workspace, err := s.workspaceRepo.GetByID(ctx, workspaceID)
+	if err != nil || workspace == nil {
+		return nil, ErrWorkspaceNotFound
+	}
+
+	requestorRole, _ := s.memberRepo.GetRole(ctx, workspaceID, requestorID)
+	if requestorRole != "owner" && requestorRole != "admin" {
+		return nil, ErrNotAuthorized
+	}
+
+	isMember, _ := s.memberRepo.IsMember(ctx, workspaceID, memberUserID)
+	if !isMember {
+		return nil, ErrNotMember
+	}
+
+	customRole, err := s.roleRepo.GetByID(ctx, roleID)
+	if err != nil || customRole == nil || customRole.WorkspaceID != workspaceID {
+		return nil, ErrRoleNotFound
+	}
+
+	assigned, err := s.roleRepo.IsAssigned(ctx, workspaceID, memberUserID, roleID)
+	if err != nil {
+		return nil, err
+	}
+	if assigned {
+		return nil, ErrRoleAlreadyAssigned
+	}
+
+	assignment := &models.MemberRoleAssignment{
+		ID:          uuid.New(),
+		WorkspaceID: workspaceID,
+		UserID:      memberUserID,
+		RoleID:      roleID,
+		AssignedBy:  requestorID,
+		AssignedAt:  time.Now(),
+	}
+	ok, err := s.roleRepo.AssignToMember(ctx, assignment, maxRolesPerMember(workspace))
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrMemberRoleLimitReached
+	}
+
+	s.LogActivity(ctx, workspaceID, requestorID, "role.assigned", "member", memberUserID.String(), models.JSON{"role_id": roleID, "role_name": customRole.Name})
+	s.emitWorkspaceEvent(ctx, workspaceID, "member.custom_role_assigned", customRoleEventData(customRole, memberUserID, requestorID))
+	return assignment, nil
+}
+
+func (s *WorkspaceService) UnassignMemberRole(ctx context.Context, workspaceID, memberUserID, roleID, requestorID uuid.UUID) error {
+	requestorRole, _ := s.memberRepo.GetRole(ctx, workspaceID, requestorID)
+	if requestorRole != "owner" && requestorRole != "admin" {
+		return ErrNotAuthorized
+	}
+
+	customRole, err := s.roleRepo.GetByID(ctx, roleID)
+	if err != nil || customRole == nil || customRole.WorkspaceID != workspaceID {
+		return ErrRoleNotFound
+	}
+
+	removed, err := s.roleRepo.UnassignFromMember(ctx, workspaceID, memberUserID, roleID)
+	if err != nil {
+		return err
+	}
+	if !removed {
+		return ErrRoleNotAssigned
+	}
+
+	s.LogActivity(ctx, workspaceID, requestorID, "role.unassigned", "member", memberUserID.String(), models.JSON{"role_id": roleID, "role_name": customRole.Name})
+	s.emitWorkspaceEvent(ctx, workspaceID, "member.custom_role_unassigned", customRoleEventData(customRole, memberUserID, requestorID))
+	return nil
+}
+
+// customRoleEventData is the payload for custom role assignment events. The
+// permissions are a snapshot at the time of the change so consumers don't
+// need to look the role up.
+func customRoleEventData(role *models.WorkspaceRole, memberUserID, actorID uuid.UUID) map[string]interface{} {
+	return map[string]interface{}{
+		"user_id":     memberUserID,
+		"role_id":     role.ID,
+		"role_name":   role.Name,
+		"permissions": role.Permissions,
+		"actor_id":    actorID,
+	}
+}
+
+func (s *WorkspaceService) ListMemberRoles(ctx context.Context, workspaceID, memberUserID, requestorID uuid.UUID) ([]*models.WorkspaceRole, error) {
+	isMember, _ := s.memberRepo.IsMember(ctx, workspaceID, requestorID)
+	if !isMember {
+		return nil, ErrNotMember
+	}
+	return s.roleRepo.ListByMember(ctx, workspaceID, memberUserID)
+}
+
+// maxRolesPerMember reads the configured per-member role cap, falling back
+// to the default when unset or invalid.
+func maxRolesPerMember(workspace *models.Workspace) int {
+	return settingInt(workspace.Settings, settingMaxRolesPerMember, defaultMaxRolesPerMember)
+}
+
 // ── Workspace Search ──
 
 func (s *WorkspaceService) SearchWorkspaces(ctx context.Context, query string, page, perPage int) ([]*models.Workspace, int64, error) {
@@ -1021,14 +2553,11 @@ func (s *WorkspaceService) GetAnalytics(ctx context.Context, workspaceID uuid.UU
 	roleCounts, _ := s.workspaceRepo.GetRoleCounts(ctx, workspaceID)
 	joinMethodStats, _ := s.workspaceRepo.GetJoinMethodStats(ctx, workspaceID)
 
-	topContributors, _ := s.activityRepo.GetTopContributors(ctx, workspaceID, time.Now().AddDate(0, 0, -days), 10)
+	since := time.Now().AddDate(0, 0, -days)
+	topContributors, _ := s.activityRepo.GetTopContributors(ctx, workspaceID, since, 10)
 
-	// Count active members from activity log in last 30 days
-	allActivities, _, _ := s.activityRepo.ListByWorkspace(ctx, workspaceID, 1, 1)
-	activeCount := 0
-	if allActivities != nil {
-		activeCount = len(allActivities)
-	}
+	// Count distinct actors in the activity log over the window
+	activeCount, _ := s.activityRepo.CountDistinctActors(ctx, workspaceID, since)
 
 	return &models.WorkspaceAnalytics{
 		MemberGrowth:     memberGrowth,
@@ -1039,6 +2568,172 @@ func (s *WorkspaceService) GetAnalytics(ctx context.Context, workspaceID uuid.UU
 	}, nil
 }
 
+func (s *WorkspaceService) GetChurnStats(ctx context.Context, workspaceID, userID uuid.UUID, days int) (*models.ChurnStats, error) {
+	role, _ := s.memberRepo.GetRole(ctx, workspaceID, userID)
+	if role != "owner" && role != "admin" {
+		return nil, ErrNotAuthorized
+	}
+
+	if days < 1 || days > 365 {
+		days = 30
+	}
+
+	counts, err := s.activityRepo.CountActionsSince(ctx, workspaceID, []string{"member.joined", "member.left", "member.removed"}, time.Now().AddDate(0, 0, -days))
+	if err != nil {
+		return nil, err
+	}
+	currentMembers, err := s.workspaceRepo.GetMemberCount(ctx, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	return computeChurnStats(days, currentMembers, counts["member.joined"], counts["member.left"], counts["member.removed"]), nil
+}
+
+func (s *WorkspaceService) GetInviteFunnel(ctx context.Context, workspaceID, userID uuid.UUID, days int) (*models.InviteFunnel, error) {
+	role, _ := s.memberRepo.GetRole(ctx, workspaceID, userID)
+	if role != "owner" && role != "admin" {
+		return nil, ErrNotAuthorized
+	}
+
+	if days < 1 || days > 365 {
+		days = 30
+	}
+
+	counts, err := s.invitationHistoryRepo.CountByMethodAndStatusSince(ctx, workspaceID, time.Now().AddDate(0, 0, -days))
+	if err != nil {
+		return nil, err
+	}
+	return computeInviteFunnel(days, counts), nil
+}
+
+// computeInviteFunnel rolls per-method status counts up into funnel stages.
+// Every recorded invitation counts as sent regardless of its current status.
+func computeInviteFunnel(days int, counts map[string]map[string]int) *models.InviteFunnel {
+	funnel := &models.InviteFunnel{
+		Days:     days,
+		Total:    &models.InviteFunnelStage{},
+		ByMethod: make(map[string]*models.InviteFunnelStage),
+	}
+	for _, method := range []string{"email", "code"} {
+		funnel.ByMethod[method] = &models.InviteFunnelStage{}
+	}
+
+	for method, statuses := range counts {
+		stage, ok := funnel.ByMethod[method]
+		if !ok {
+			stage = &models.InviteFunnelStage{}
+			funnel.ByMethod[method] = stage
+		}
+		for status, n := range statuses {
+			for _, st := range []*models.InviteFunnelStage{stage, funnel.Total} {
+				st.Sent += n
+				switch status {
+				case "accepted":
+					st.Accepted += n
+				case "expired":
+					st.Expired += n
+				case "pending":
+					st.Pending += n
+				case "revoked":
+					st.Revoked += n
+				}
+			}
+		}
+	}
+
+	for _, stage := range funnel.ByMethod {
+		stage.ConversionRate = conversionRate(stage)
+	}
+	funnel.Total.ConversionRate = conversionRate(funnel.Total)
+	return funnel
+}
+
+func conversionRate(stage *models.InviteFunnelStage) float64 {
+	if stage.Sent == 0 {
+		return 0
+	}
+	return float64(stage.Accepted) / float64(stage.Sent) * 100
+}
+
+func (s *WorkspaceService) GetMemberActivityHeatmap(ctx context.Context, workspaceID, targetUserID, userID uuid.UUID, days int) (*models.MemberActivityHeatmap, error) {
+	isMember, _ := s.memberRepo.IsMember(ctx, workspaceID, userID)
+	if !isMember {
+		return nil, ErrNotMember
+	}
+
+	if days < 1 {
+		days = 90
+	}
+	if days > 365 {
+		days = 365
+	}
+
+	now := time.Now()
+	start := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location()).AddDate(0, 0, -(days - 1))
+	counts, err := s.activityRepo.GetDailyActionCountsByActor(ctx, workspaceID, targetUserID, start)
+	if err != nil {
+		return nil, err
+	}
+
+	heatmap := fillDailyCounts(start, days, counts)
+	total := 0
+	for _, day := range heatmap {
+		total += day.Count
+	}
+	return &models.MemberActivityHeatmap{
+		UserID: targetUserID,
+		Days:   days,
+		Total:  total,
+		Counts: heatmap,
+	}, nil
+}
+
+// fillDailyCounts expands sparse per-day counts into one entry for each of
+// the days starting at start, with zero for days that had none.
+func fillDailyCounts(start time.Time, days int, counts []models.DailyCount) []models.DailyCount {
+	byDate := make(map[string]int, len(counts))
+	for _, c := range counts {
+		byDate[c.Date] = c.Count
+	}
+	filled := make([]models.DailyCount, days)
+	for i := range filled {
+		date := start.AddDate(0, 0, i).Format("2006-01-02")
+		filled[i] = models.DailyCount{Date: date, Count: byDate[date]}
+	}
+	return filled
+}
+
+// computeChurnStats derives net growth and churn rate for a window. The churn
+// rate is departures over the member count at the start of the window.
+func computeChurnStats(days, currentMembers, joins, leaves, removals int) *models.ChurnStats {
+	departures := leaves + removals
+	netGrowth := joins - departures
+	startMembers := currentMembers - netGrowth
+	if startMembers < 0 {
+		startMembers = 0
+	}
+
+	churnRate := 0.0
+	if startMembers > 0 {
+		churnRate = float64(departures) / float64(startMembers) * 100
+	} else if departures > 0 {
+		// Everyone who left also joined inside the window
+		churnRate = float64(departures) / float64(joins) * 100
+	}
+
+	return &models.ChurnStats{
+		Days:           days,
+		Joins:          joins,
+		Leaves:         leaves,
+		Removals:       removals,
+		NetGrowth:      netGrowth,
+		StartMembers:   startMembers,
+		CurrentMembers: currentMembers,
+		ChurnRate:      churnRate,
+	}
+}
+
 // ── Workspace Templates ──
 
 func (s *WorkspaceService) CreateTemplateFromWorkspace(ctx context.Context, workspaceID, userID uuid.UUID, req *models.CreateTemplateFromWorkspaceRequest) (*models.WorkspaceTemplate, error) {
@@ -1052,31 +2747,33 @@ func (s *WorkspaceService) CreateTemplateFromWorkspace(ctx context.Context, work
 		return nil, ErrWorkspaceNotFound
 	}
 
-	roles, _ := s.roleRepo.ListByWorkspace(ctx, workspaceID)
-	var rolesData []map[string]interface{}
-	for _, r := range roles {
-		rolesData = append(rolesData, map[string]interface{}{
-			"name": r.Name, "color": r.Color, "priority": r.Priority, "permissions": r.Permissions, "is_default": r.IsDefault,
-		})
+	spec, err := s.templateSpecFromWorkspace(ctx, workspace, req.Channels)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateTemplateSpec(spec); err != nil {
+		return nil, err
 	}
 
 	template := &models.WorkspaceTemplate{
-		ID:              uuid.New(),
-		Name:            req.Name,
-		Description:     req.Description,
-		CreatedBy:       userID,
-		DefaultRoles:    models.JSON{"roles": rolesData},
-		DefaultChannels: models.JSON{},
-		DefaultSettings: models.JSON(workspace.Settings),
-		IsPublic:        req.IsPublic,
-		UseCount:        0,
-		CreatedAt:       time.Now(),
-		UpdatedAt:       time.Now(),
+		ID:          uuid.New(),
+		Name:        req.Name,
+		Description: req.Description,
+		CreatedBy:   userID,
+		IsPublic:    req.IsPublic,
+		UseCount:    0,
+		Version:     1,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
 	}
+	encodeTemplateSpec(template, spec)
 
 	if err := s.templateRepo.Create(ctx, template); err != nil {
 		return nil, err
 	}
+	if err := s.templateRepo.CreateVersion(ctx, templateSnapshot(template)); err != nil {
+		return nil, err
+	}
 
 	s.LogActivity(ctx, workspaceID, userID, "template.created", "template", template.ID.String(), models.JSON{"name": req.Name})
 	return template, nil
@@ -1089,27 +2786,32 @@ func (s *WorkspaceService) CreateWorkspaceFromTemplate(ctx context.Context, user
 	}
 
 	template, err := s.templateRepo.GetByID(ctx, templateID)
-	if err != nil || template == nil {
+	if err != nil || template == nil || !s.templateVisible(ctx, template, userID) {
 		return nil, ErrTemplateNotFound
 	}
+	if req.Version != nil {
+		if template, err = s.templateAtVersion(ctx, template, *req.Version); err != nil {
+			return nil, err
+		}
+	}
+
+	spec, err := decodeTemplateSpec(template)
+	if err != nil {
+		return nil, err
+	}
 
 	existing, _ := s.workspaceRepo.GetBySlug(ctx, req.Slug)
 	if existing != nil {
 		return nil, ErrSlugExists
 	}
 
-	var settings models.JSON
-	if template.DefaultSettings != nil {
-		settings = template.DefaultSettings
-	}
-
 	workspace := &models.Workspace{
 		ID:        uuid.New(),
 		Name:      req.Name,
 		Slug:      req.Slug,
 		OwnerID:   userID,
 		Plan:      "free",
-		Settings:  settings,
+		Settings:  templateSettings(spec),
 		IsActive:  true,
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
@@ -1131,50 +2833,7 @@ func (s *WorkspaceService) CreateWorkspaceFromTemplate(ctx context.Context, user
 	}
 	s.memberRepo.Create(ctx, member)
 
-	// Create roles from template
-	if template.DefaultRoles != nil {
-		if rolesRaw, ok := template.DefaultRoles["roles"]; ok {
-			if rolesSlice, ok := rolesRaw.([]interface{}); ok {
-				for _, roleRaw := range rolesSlice {
-					if roleMap, ok := roleRaw.(map[string]interface{}); ok {
-						name, _ := roleMap["name"].(string)
-						if name == "" {
-							continue
-						}
-						priority := 0
-						if p, ok := roleMap["priority"].(float64); ok {
-							priority = int(p)
-						}
-						var color *string
-						if c, ok := roleMap["color"].(string); ok {
-							color = &c
-						}
-						isDefault := false
-						if d, ok := roleMap["is_default"].(bool); ok {
-							isDefault = d
-						}
-						var permissions models.JSON
-						if p, ok := roleMap["permissions"].(map[string]interface{}); ok {
-							permissions = models.JSON(p)
-						}
-						newRole := &models.WorkspaceRole{
-							ID:          uuid.New(),
-							WorkspaceID: workspace.ID,
-							Name:        name,
-							Color:       color,
-							Priority:    priority,
-							Permissions: permissions,
-							IsDefault:   isDefault,
-							CreatedBy:   userID,
-							CreatedAt:   time.Now(),
-							UpdatedAt:   time.Now(),
-						}
-						s.roleRepo.Create(ctx, newRole)
-					}
-				}
-			}
-		}
-	}
+	s.applyTemplateSpec(ctx, workspace.ID, userID, templateID, spec)
 
 	s.templateRepo.IncrementUseCount(ctx, templateID)
 	s.invalidateUserWorkspaces(ctx, userID)
@@ -1186,24 +2845,124 @@ func (s *WorkspaceService) CreateWorkspaceFromTemplate(ctx context.Context, user
 	return workspace, nil
 }
 
-func (s *WorkspaceService) ListTemplates(ctx context.Context, page, perPage int) ([]*models.WorkspaceTemplate, int64, error) {
+// ListTemplates lists the templates the user can see: public ones, their
+// own, and those shared with them.
+func (s *WorkspaceService) ListTemplates(ctx context.Context, userID uuid.UUID, filter models.TemplateFilter, page, perPage int) ([]*models.WorkspaceTemplate, int64, error) {
 	if page < 1 {
 		page = 1
 	}
 	if perPage < 1 || perPage > 100 {
 		perPage = 20
 	}
-	return s.templateRepo.ListPublic(ctx, page, perPage)
+	return s.templateRepo.ListVisible(ctx, userID, filter, page, perPage)
 }
 
-func (s *WorkspaceService) GetTemplate(ctx context.Context, templateID uuid.UUID) (*models.WorkspaceTemplate, error) {
+func (s *WorkspaceService) GetTemplate(ctx context.Context, templateID, userID uuid.UUID) (*models.WorkspaceTemplate, error) {
 	template, err := s.templateRepo.GetByID(ctx, templateID)
 	if err != nil || template == nil {
 		return nil, ErrTemplateNotFound
 	}
+	if !s.templateVisible(ctx, template, userID) {
+		return nil, ErrTemplateNotFound
+	}
 	return template, nil
 }
 
+// ShareTemplate makes a private template visible to one more user. Only the
+// creator can share.
+func (s *WorkspaceService) ShareTemplate(ctx context.Context, templateID, ownerID, targetUserID uuid.UUID) (*models.WorkspaceTemplateShare, error) {
+	template, err := s.templateRepo.GetByID(ctx, templateID)
+	if err != nil || template == nil {
+		return nil, ErrTemplateNotFound
+	}
+	if template.CreatedBy != ownerID {
+		return nil, ErrNotAuthorized
+	}
+	if targetUserID == ownerID {
+		return nil, ErrInvalidShareTarget
+	}
+
+	share := &models.WorkspaceTemplateShare{
+		TemplateID: templateID,
+		UserID:     targetUserID,
+		SharedBy:   ownerID,
+		CreatedAt:  time.Now(),
+	}
+	if err := s.templateRepo.Share(ctx, share); err != nil {
+		return nil, err
+	}
+	return share, nil
+}
+
+// UnshareTemplate revokes a user's access to a template. Only the creator
+// can unshare.
+func (s *WorkspaceService) UnshareTemplate(ctx context.Context, templateID, ownerID, targetUserID uuid.UUID) error {
+	template, err := s.templateRepo.GetByID(ctx, templateID)
+	if err != nil || template == nil {
+		return ErrTemplateNotFound
+	}
+	if template.CreatedBy != ownerID {
+		return ErrNotAuthorized
+	}
+
+	removed, err := s.templateRepo.Unshare(ctx, templateID, targetUserID)
+	if err != nil {
+		return err
+	}
+	if removed == 0 {
+		return ErrTemplateShareNotFound
+	}
+	return nil
+}
+
+// ListTemplateShares lists who a template is shared with. Only the creator
+// can see this.
+func (s *WorkspaceService) ListTemplateShares(ctx context.Context, templateID, ownerID uuid.UUID) ([]*models.WorkspaceTemplateShare, error) {
+	template, err := s.templateRepo.GetByID(ctx, templateID)
+	if err != nil || template == nil {
+		return nil, ErrTemplateNotFound
+	}
+	if template.CreatedBy != ownerID {
+		return nil, ErrNotAuthorized
+	}
+	return s.templateRepo.ListShares(ctx, templateID)
+}
+
+// PreviewTemplate returns what CreateWorkspaceFromTemplate would provision
+// without creating anything.
+func (s *WorkspaceService) PreviewTemplate(ctx context.Context, templateID, userID uuid.UUID) (*models.TemplatePreview, error) {
+	template, err := s.templateRepo.GetByID(ctx, templateID)
+	if err != nil || template == nil {
+		return nil, ErrTemplateNotFound
+	}
+	if !s.templateVisible(ctx, template, userID) {
+		return nil, ErrTemplateNotFound
+	}
+
+	spec, err := decodeTemplateSpec(template)
+	if err != nil {
+		return nil, err
+	}
+
+	preview := &models.TemplatePreview{
+		TemplateID: template.ID,
+		Name:       template.Name,
+		Valid:      true,
+		Roles:      spec.Roles,
+		Channels:   spec.Channels,
+		Tags:       spec.Tags,
+		Labels:     spec.Labels,
+		Settings:   templateSettings(spec),
+	}
+	if err := validateTemplateSpec(spec); err != nil {
+		if templateErr, ok := err.(*TemplateValidationError); ok {
+			preview.Valid = false
+			preview.Problems = templateErr.Fields
+		}
+	}
+	return preview, nil
+}
+
 func (s *WorkspaceService) UpdateTemplate(ctx context.Context, templateID, userID uuid.UUID, req *models.UpdateTemplateRequest) (*models.WorkspaceTemplate, error) {
 	template, err := s.templateRepo.GetByID(ctx, templateID)
 	if err != nil || template == nil {
@@ -1213,6 +2972,15 @@ func (s *WorkspaceService) UpdateTemplate(ctx context.Context, templateID, userI
 	if template.CreatedBy != userID {
 		return nil, ErrNotAuthorized
 	}
+	if req.Version != nil && *req.Version != template.Version {
+		return nil, ErrConflict
+	}
+
+	// Templates created before versioning have no snapshot of their
+	// current content yet; keep it before it is replaced.
+	if err := s.templateRepo.CreateVersion(ctx, templateSnapshot(template)); err != nil {
+		return nil, err
+	}
 
 	if req.Name != nil {
 		template.Name = *req.Name
@@ -1223,14 +2991,103 @@ func (s *WorkspaceService) UpdateTemplate(ctx context.Context, templateID, userI
 	if req.IsPublic != nil {
 		template.IsPublic = *req.IsPublic
 	}
+	if req.Spec != nil {
+		if err := validateTemplateSpec(req.Spec); err != nil {
+			return nil, err
+		}
+		encodeTemplateSpec(template, req.Spec)
+	}
 
-	if err := s.templateRepo.Update(ctx, template); err != nil {
+	ok, err := s.templateRepo.Update(ctx, template)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrConflict
+	}
+	if err := s.templateRepo.CreateVersion(ctx, templateSnapshot(template)); err != nil {
 		return nil, err
 	}
 
 	return template, nil
 }
 
+// ListTemplateVersions returns a template's snapshots, newest first.
+func (s *WorkspaceService) ListTemplateVersions(ctx context.Context, templateID, userID uuid.UUID) ([]*models.WorkspaceTemplateVersion, error) {
+	template, err := s.templateRepo.GetByID(ctx, templateID)
+	if err != nil || template == nil {
+		return nil, ErrTemplateNotFound
+	}
+	if !s.templateVisible(ctx, template, userID) {
+		return nil, ErrTemplateNotFound
+	}
+
+	versions, err := s.templateRepo.ListVersions(ctx, templateID)
+	if err != nil {
+		return nil, err
+	}
+	if len(versions) == 0 || versions[0].Version != template.Version {
+		// Not yet snapshotted; the template row is the latest version
+		versions = append([]*models.WorkspaceTemplateVersion{templateSnapshot(template)}, versions...)
+	}
+	return versions, nil
+}
+
+// templateAtVersion returns the template with its content as of version.
+// The latest version is read from the template row itself, so templates
+// created before versioning still resolve.
+func (s *WorkspaceService) templateAtVersion(ctx context.Context, template *models.WorkspaceTemplate, version int) (*models.WorkspaceTemplate, error) {
+	if version == template.Version {
+		return template, nil
+	}
+	v, err := s.templateRepo.GetVersion(ctx, template.ID, version)
+	if err != nil {
+		return nil, err
+	}
+	if v == nil {
+		return nil, ErrTemplateVersionNotFound
+	}
+
+	pinned := *template
+	pinned.Name = v.Name
+	pinned.Description = v.Description
+	pinned.DefaultRoles = v.DefaultRoles
+	pinned.DefaultChannels = v.DefaultChannels
+	pinned.DefaultSettings = v.DefaultSettings
+	pinned.DefaultTags = v.DefaultTags
+	pinned.DefaultLabels = v.DefaultLabels
+	pinned.Version = v.Version
+	return &pinned, nil
+}
+
+// templateVisible reports whether a user may see a template: public
+// templates are visible to everyone, private ones to their creator and the
+// users it is shared with.
+func (s *WorkspaceService) templateVisible(ctx context.Context, template *models.WorkspaceTemplate, userID uuid.UUID) bool {
+	if template.IsPublic || template.CreatedBy == userID {
+		return true
+	}
+	shared, _ := s.templateRepo.IsSharedWith(ctx, template.ID, userID)
+	return shared
+}
+
+func templateSnapshot(t *models.WorkspaceTemplate) *models.WorkspaceTemplateVersion {
+	return &models.WorkspaceTemplateVersion{
+		ID:              uuid.New(),
+		TemplateID:      t.ID,
+		Version:         t.Version,
+		Name:            t.Name,
+		Description:     t.Description,
+		DefaultRoles:    t.DefaultRoles,
+		DefaultChannels: t.DefaultChannels,
+		DefaultSettings: t.DefaultSettings,
+		DefaultTags:     t.DefaultTags,
+		DefaultLabels:   t.DefaultLabels,
+		CreatedBy:       t.CreatedBy,
+		CreatedAt:       time.Now(),
+	}
+}
+
 func (s *WorkspaceService) DeleteTemplate(ctx context.Context, templateID, userID uuid.UUID) error {
 	template, err := s.templateRepo.GetByID(ctx, templateID)
 	if err != nil || template == nil {
@@ -1254,18 +3111,73 @@ func (s *WorkspaceService) GetPreferences(ctx context.Context, workspaceID, user
 
 	pref, _ := s.preferenceRepo.GetByWorkspaceAndUser(ctx, workspaceID, userID)
 	if pref == nil {
-		// Return defaults
-		return &models.WorkspaceMemberPreference{
-			WorkspaceID:        workspaceID,
-			UserID:             userID,
-			NotificationLevel:  "all",
-			EmailNotifications: true,
-			SidebarPosition:    0,
-		}, nil
+		pref, _ = s.defaultPreferences(ctx, workspaceID, userID)
+		return pref, nil
+	}
+
+	now := time.Now()
+	if pref.MuteUntil != nil && !now.Before(*pref.MuteUntil) {
+		// A lapsed mute means "not muted"; tidy the row while we're here
+		if err := s.preferenceRepo.ClearExpiredMute(ctx, workspaceID, userID, now); err != nil {
+			s.logger.WithError(err).Warn("Failed to clear expired mute")
+		}
+		pref.MuteUntil = nil
 	}
+	pref.IsMuted = isMuted(pref, now)
 	return pref, nil
 }
 
+// defaultPreferences is what a member without saved preferences gets: the
+// global defaults, overlaid with the workspace's role_default_preferences
+// entry for the member's role. fromRole reports whether such an entry
+// applied.
+func (s *WorkspaceService) defaultPreferences(ctx context.Context, workspaceID, userID uuid.UUID) (pref *models.WorkspaceMemberPreference, fromRole bool) {
+	pref = &models.WorkspaceMemberPreference{
+		WorkspaceID:        workspaceID,
+		UserID:             userID,
+		NotificationLevel:  "all",
+		EmailNotifications: true,
+		SidebarPosition:    0,
+	}
+
+	workspace, _ := s.workspaceRepo.GetByID(ctx, workspaceID)
+	if workspace == nil {
+		return pref, false
+	}
+	role, _ := s.memberRepo.GetRole(ctx, workspaceID, userID)
+	defaults, ok := roleDefaultPreferences(workspace.Settings, role)
+	if !ok {
+		return pref, false
+	}
+
+	if level, ok := defaults["notification_level"].(string); ok {
+		pref.NotificationLevel = level
+	}
+	if email, ok := defaults["email_notifications"].(bool); ok {
+		pref.EmailNotifications = email
+	}
+	if theme, ok := defaults["theme"].(string); ok {
+		pref.Theme = &theme
+	}
+	return pref, true
+}
+
+// roleDefaultPreferences returns the role's entry from the
+// role_default_preferences setting, ignoring it if it fails validation.
+func roleDefaultPreferences(settings models.JSON, role string) (map[string]interface{}, bool) {
+	all, ok := settings[settingRoleDefaultPreferences].(map[string]interface{})
+	if !ok || settingsSchema[settingRoleDefaultPreferences](all) != "" {
+		return nil, false
+	}
+	defaults, ok := all[role].(map[string]interface{})
+	return defaults, ok
+}
+
+// isMuted reports whether the preference's mute window is still open.
+func isMuted(pref *models.WorkspaceMemberPreference, now time.Time) bool {
+	return pref.MuteUntil != nil && now.Before(*pref.MuteUntil)
+}
+
 func (s *WorkspaceService) UpdatePreferences(ctx context.Context, workspaceID, userID uuid.UUID, req *models.UpdatePreferencesRequest) (*models.WorkspaceMemberPreference, error) {
 	isMember, _ := s.memberRepo.IsMember(ctx, workspaceID, userID)
 	if !isMember {
@@ -1275,13 +3187,10 @@ func (s *WorkspaceService) UpdatePreferences(ctx context.Context, workspaceID, u
 	existing, _ := s.preferenceRepo.GetByWorkspaceAndUser(ctx, workspaceID, userID)
 	now := time.Now()
 
-	pref := &models.WorkspaceMemberPreference{
-		WorkspaceID:        workspaceID,
-		UserID:             userID,
-		NotificationLevel:  "all",
-		EmailNotifications: true,
-		UpdatedAt:          now,
-	}
+	// A first save starts from the member's defaults so fields the request
+	// leaves out keep their role default rather than the global one.
+	pref, _ := s.defaultPreferences(ctx, workspaceID, userID)
+	pref.UpdatedAt = now
 
 	if existing != nil {
 		pref.ID = existing.ID
@@ -1316,9 +3225,12 @@ func (s *WorkspaceService) UpdatePreferences(ctx context.Context, workspaceID, u
 		return nil, err
 	}
 
+	pref.IsMuted = isMuted(pref, now)
 	return pref, nil
 }
 
+// ResetPreferences drops the member's saved preferences, so reads fall back
+// to their role default and then the global default.
 func (s *WorkspaceService) ResetPreferences(ctx context.Context, workspaceID, userID uuid.UUID) error {
 	isMember, _ := s.memberRepo.IsMember(ctx, workspaceID, userID)
 	if !isMember {
@@ -1328,6 +3240,57 @@ func (s *WorkspaceService) ResetPreferences(ctx context.Context, workspaceID, us
 	return s.preferenceRepo.Delete(ctx, workspaceID, userID)
 }
 
+// ShouldNotify decides whether the user should be notified about an event
+// right now on the given channel ("in_app" or "email") and why. Producers
+// call it before fanning out. Mention events are "mention" or end in
+// ".mention". Producers check on behalf of workspace members, so the
+// requester must be one.
+func (s *WorkspaceService) ShouldNotify(ctx context.Context, workspaceID, requesterID, userID uuid.UUID, eventType, channel string) (bool, string, error) {
+	isMember, _ := s.memberRepo.IsMember(ctx, workspaceID, requesterID)
+	if !isMember {
+		return false, "", ErrNotMember
+	}
+
+	isMember, _ = s.memberRepo.IsMember(ctx, workspaceID, userID)
+	if !isMember {
+		return false, "not_member", nil
+	}
+
+	pref, _ := s.preferenceRepo.GetByWorkspaceAndUser(ctx, workspaceID, userID)
+	if pref == nil {
+		if defaults, fromRole := s.defaultPreferences(ctx, workspaceID, userID); fromRole {
+			pref = defaults
+		}
+	}
+	notify, reason := resolveNotification(pref, eventType, channel, time.Now())
+	return notify, reason, nil
+}
+
+// resolveNotification applies a member's preferences to one event. A nil
+// preference means the defaults: everything on, email included.
+func resolveNotification(pref *models.WorkspaceMemberPreference, eventType, channel string, now time.Time) (bool, string) {
+	if pref == nil {
+		return true, "default"
+	}
+	if isMuted(pref, now) {
+		return false, "muted"
+	}
+
+	switch pref.NotificationLevel {
+	case "none":
+		return false, "notifications_off"
+	case "mentions":
+		if eventType != "mention" && !strings.HasSuffix(eventType, ".mention") {
+			return false, "mentions_only"
+		}
+	}
+
+	if channel == "email" && !pref.EmailNotifications {
+		return false, "email_disabled"
+	}
+	return true, "allowed"
+}
+
 // ── Workspace Tags ──
 
 func (s *WorkspaceService) CreateTag(ctx context.Context, workspaceID, userID uuid.UUID, req *models.CreateTagRequest) (*models.WorkspaceTag, error) {
@@ -1336,6 +3299,11 @@ func (s *WorkspaceService) CreateTag(ctx context.Context, workspaceID, userID uu
 		return nil, ErrNotAuthorized
 	}
 
+	color, err := normalizeOptionalColor(req.Color)
+	if err != nil {
+		return nil, err
+	}
+
 	existing, _ := s.tagRepo.GetByName(ctx, workspaceID, req.Name)
 	if existing != nil {
 		return nil, ErrTagNameExists
@@ -1345,7 +3313,7 @@ func (s *WorkspaceService) CreateTag(ctx context.Context, workspaceID, userID uu
 		ID:          uuid.New(),
 		WorkspaceID: workspaceID,
 		Name:        req.Name,
-		Color:       req.Color,
+		Color:       color,
 		CreatedBy:   userID,
 		CreatedAt:   time.Now(),
 		UpdatedAt:   time.Now(),
@@ -1370,14 +3338,10 @@ func (s *WorkspaceService) UpdateTag(ctx context.Context, workspaceID, tagID, us
 	}
 
 	tag, err := s.tagRepo.GetByID(ctx, tagID)
-	if err != nil || tag == nil {
+	if err != nil || tag == nil || tag.WorkspaceID != workspaceID {
 		return nil, ErrTagNotFound
 	}
 
-	if tag.WorkspaceID != workspaceID {
-		return nil, ErrNotAuthorized
-	}
-
 	if req.Name != nil {
 		dup, _ := s.tagRepo.GetByName(ctx, workspaceID, *req.Name)
 		if dup != nil && dup.ID != tagID {
@@ -1386,7 +3350,11 @@ func (s *WorkspaceService) UpdateTag(ctx context.Context, workspaceID, tagID, us
 		tag.Name = *req.Name
 	}
 	if req.Color != nil {
-		tag.Color = req.Color
+		color, err := normalizeOptionalColor(req.Color)
+		if err != nil {
+			return nil, err
+		}
+		tag.Color = color
 	}
 
 	if err := s.tagRepo.Update(ctx, tag); err != nil {
@@ -1404,14 +3372,10 @@ func (s *WorkspaceService) DeleteTag(ctx context.Context, workspaceID, tagID, us
 	}
 
 	tag, err := s.tagRepo.GetByID(ctx, tagID)
-	if err != nil || tag == nil {
+	if err != nil || tag == nil || tag.WorkspaceID != workspaceID {
 		return ErrTagNotFound
 	}
 
-	if tag.WorkspaceID != workspaceID {
-		return ErrNotAuthorized
-	}
-
 	if err := s.tagRepo.Delete(ctx, tagID); err != nil {
 		return err
 	}
@@ -1454,16 +3418,17 @@ func (s *WorkspaceService) BanMember(ctx context.Context, workspaceID, targetUse
 	}
 
 	// Remove member from workspace
-	s.memberRepo.Remove(ctx, workspaceID, targetUserID)
+	if err := s.removeMemberWithEvent(ctx, workspaceID, targetUserID, "member.banned", map[string]interface{}{
+		"workspace_id": workspaceID,
+		"user_id":      targetUserID,
+		"banned_by":    actorID,
+	}); err != nil {
+		return nil, err
+	}
 	s.invalidateWorkspace(ctx, workspaceID)
 	s.invalidateUserWorkspaces(ctx, targetUserID)
 
 	s.LogActivity(ctx, workspaceID, actorID, "member.banned", "member", targetUserID.String(), models.JSON{"reason": req.Reason, "is_permanent": req.IsPermanent})
-	s.publishEvent(ctx, "workspace-events", workspaceID.String(), "member.banned", map[string]interface{}{
-		"workspace_id": workspaceID,
-		"user_id":      targetUserID,
-		"banned_by":    actorID,
-	})
 
 	return ban, nil
 }
@@ -1575,23 +3540,57 @@ func (s *WorkspaceService) CreateAnnouncement(ctx context.Context, workspaceID,
 		return nil, ErrNotAuthorized
 	}
 
+	priority := normalizeAnnouncementPriority(req.Priority)
+	if priority == "" {
+		workspace, err := s.workspaceRepo.GetByID(ctx, workspaceID)
+		if err != nil || workspace == nil {
+			return nil, ErrWorkspaceNotFound
+		}
+		priority = defaultAnnouncementPriority(workspace)
+	}
+
 	announcement := &models.WorkspaceAnnouncement{
 		ID:          uuid.New(),
 		WorkspaceID: workspaceID,
 		Title:       req.Title,
 		Content:     req.Content,
-		Priority:    req.Priority,
+		Priority:    priority,
 		AuthorID:    userID,
 		IsPinned:    req.IsPinned,
 		ExpiresAt:   req.ExpiresAt,
+		IsPublished: true,
 		CreatedAt:   time.Now(),
 		UpdatedAt:   time.Now(),
 	}
+	if req.IsPinned {
+		maxPos, _ := s.announcementRepo.GetMaxPinPosition(ctx, workspaceID)
+		pos := maxPos + 1
+		announcement.PinPosition = &pos
+	}
+
+	// A future publish time keeps the announcement to its author until the
+	// scheduled action runner publishes it
+	if req.PublishAt != nil && req.PublishAt.After(announcement.CreatedAt) {
+		if req.ExpiresAt != nil && !req.ExpiresAt.After(*req.PublishAt) {
+			return nil, ErrExpiresBeforePublish
+		}
+		announcement.IsPublished = false
+		announcement.PublishAt = req.PublishAt
+	}
 
 	if err := s.announcementRepo.Create(ctx, announcement); err != nil {
 		return nil, err
 	}
 
+	if !announcement.IsPublished {
+		if err := s.scheduleAnnouncement(ctx, announcement); err != nil {
+			s.announcementRepo.Delete(ctx, announcement.ID)
+			return nil, err
+		}
+		s.LogActivity(ctx, workspaceID, userID, "announcement.scheduled", "announcement", announcement.ID.String(), models.JSON{"title": req.Title, "publish_at": announcement.PublishAt})
+		return announcement, nil
+	}
+
 	s.LogActivity(ctx, workspaceID, userID, "announcement.created", "announcement", announcement.ID.String(), models.JSON{"title": req.Title})
 	s.publishEvent(ctx, "workspace-events", workspaceID.String(), "workspace.announcement.created", map[string]interface{}{
 		"announcement": announcement,
@@ -1600,7 +3599,48 @@ func (s *WorkspaceService) CreateAnnouncement(ctx context.Context, workspaceID,
 	return announcement, nil
 }
 
-func (s *WorkspaceService) ListAnnouncements(ctx context.Context, workspaceID, userID uuid.UUID, page, perPage int) ([]*models.WorkspaceAnnouncement, int64, error) {
+// SetDefaultAnnouncementPriority stores the priority applied to new
+// announcements that do not specify one.
+func (s *WorkspaceService) SetDefaultAnnouncementPriority(ctx context.Context, workspaceID, userID uuid.UUID, priority string) (models.JSON, error) {
+	workspace, err := s.workspaceRepo.GetByID(ctx, workspaceID)
+	if err != nil || workspace == nil {
+		return nil, ErrWorkspaceNotFound
+	}
+
+	role, _ := s.memberRepo.GetRole(ctx, workspaceID, userID)
+	if role != "owner" && role != "admin" {
+		return nil, ErrNotAuthorized
+	}
+
+	if err := s.saveWorkspaceSettings(ctx, workspace, userID, withSetting(workspace.Settings, settingDefaultAnnouncementPriority, priority)); err != nil {
+		return nil, err
+	}
+
+	s.invalidateWorkspace(ctx, workspaceID)
+	s.LogActivity(ctx, workspaceID, userID, "announcement.default_priority_updated", "workspace", workspaceID.String(), models.JSON{"priority": priority})
+	return workspace.Settings, nil
+}
+
+// normalizeAnnouncementPriority maps the legacy "important" level onto
+// "high"; other values pass through unchanged.
+func normalizeAnnouncementPriority(priority string) string {
+	if priority == "important" {
+		return "high"
+	}
+	return priority
+}
+
+func defaultAnnouncementPriority(workspace *models.Workspace) string {
+	if workspace.Settings != nil {
+		switch p, _ := workspace.Settings[settingDefaultAnnouncementPriority].(string); p {
+		case "low", "normal", "high", "urgent":
+			return p
+		}
+	}
+	return "normal"
+}
+
+func (s *WorkspaceService) ListAnnouncements(ctx context.Context, workspaceID, userID uuid.UUID, filter models.AnnouncementFilter, page, perPage int) ([]*models.WorkspaceAnnouncement, int64, error) {
 	isMember, _ := s.memberRepo.IsMember(ctx, workspaceID, userID)
 	if !isMember {
 		return nil, 0, ErrNotMember
@@ -1613,7 +3653,10 @@ func (s *WorkspaceService) ListAnnouncements(ctx context.Context, workspaceID, u
 		perPage = 20
 	}
 
-	return s.announcementRepo.ListByWorkspace(ctx, workspaceID, page, perPage)
+	filter.Query = strings.TrimSpace(filter.Query)
+	filter.Priority = normalizeAnnouncementPriority(filter.Priority)
+	filter.ViewerID = userID
+	return s.announcementRepo.ListByWorkspace(ctx, workspaceID, filter, page, perPage)
 }
 
 func (s *WorkspaceService) UpdateAnnouncement(ctx context.Context, workspaceID, announcementID, userID uuid.UUID, req *models.UpdateAnnouncementRequest) (*models.WorkspaceAnnouncement, error) {
@@ -1623,12 +3666,11 @@ func (s *WorkspaceService) UpdateAnnouncement(ctx context.Context, workspaceID,
 	}
 
 	announcement, err := s.announcementRepo.GetByID(ctx, announcementID)
-	if err != nil || announcement == nil {
+	if err != nil || announcement == nil || announcement.WorkspaceID != workspaceID {
 		return nil, ErrAnnouncementNotFound
 	}
-
-	if announcement.WorkspaceID != workspaceID {
-		return nil, ErrNotAuthorized
+	if req.Version != nil && *req.Version != announcement.Version {
+		return nil, ErrConflict
 	}
 
 	if req.Title != nil {
@@ -1638,15 +3680,19 @@ func (s *WorkspaceService) UpdateAnnouncement(ctx context.Context, workspaceID,
 		announcement.Content = *req.Content
 	}
 	if req.Priority != nil {
-		announcement.Priority = *req.Priority
+		announcement.Priority = normalizeAnnouncementPriority(*req.Priority)
 	}
 	if req.ExpiresAt != nil {
 		announcement.ExpiresAt = req.ExpiresAt
 	}
 
-	if err := s.announcementRepo.Update(ctx, announcement); err != nil {
+	ok, err := s.announcementRepo.Update(ctx, announcement)
+	if err != nil {
 		return nil, err
 	}
+	if !ok {
+		return nil, ErrConflict
+	}
 
 	s.LogActivity(ctx, workspaceID, userID, "announcement.updated", "announcement", announcementID.String(), nil)
 	return announcement, nil
@@ -1659,15 +3705,23 @@ func (s *WorkspaceService) PinAnnouncement(ctx context.Context, workspaceID, ann
 	}
 
 	announcement, err := s.announcementRepo.GetByID(ctx, announcementID)
-	if err != nil || announcement == nil {
+	if err != nil || announcement == nil || announcement.WorkspaceID != workspaceID {
 		return ErrAnnouncementNotFound
 	}
 
-	if announcement.WorkspaceID != workspaceID {
-		return ErrNotAuthorized
+	// Newly pinned announcements go to the end of the pinned order
+	var pinPosition *int
+	if req.IsPinned {
+		if announcement.IsPinned && announcement.PinPosition != nil {
+			pinPosition = announcement.PinPosition
+		} else {
+			maxPos, _ := s.announcementRepo.GetMaxPinPosition(ctx, workspaceID)
+			pos := maxPos + 1
+			pinPosition = &pos
+		}
 	}
 
-	if err := s.announcementRepo.UpdatePinStatus(ctx, announcementID, req.IsPinned); err != nil {
+	if err := s.announcementRepo.UpdatePinStatus(ctx, announcementID, req.IsPinned, pinPosition); err != nil {
 		return err
 	}
 
@@ -1679,6 +3733,50 @@ func (s *WorkspaceService) PinAnnouncement(ctx context.Context, workspaceID, ann
 	return nil
 }
 
+func (s *WorkspaceService) ReorderAnnouncementPins(ctx context.Context, workspaceID, userID uuid.UUID, req *models.ReorderAnnouncementPinsRequest) ([]*models.WorkspaceAnnouncement, error) {
+	role, _ := s.memberRepo.GetRole(ctx, workspaceID, userID)
+	if role != "owner" && role != "admin" {
+		return nil, ErrNotAuthorized
+	}
+
+	pinned, err := s.announcementRepo.ListPinned(ctx, workspaceID, userID)
+	if err != nil {
+		return nil, err
+	}
+	pinnedSet := make(map[uuid.UUID]bool, len(pinned))
+	for _, a := range pinned {
+		pinnedSet[a.ID] = true
+	}
+
+	var ids []uuid.UUID
+	seen := make(map[uuid.UUID]bool)
+	for _, raw := range req.AnnouncementIDs {
+		id, err := uuid.Parse(raw)
+		if err != nil || !pinnedSet[id] {
+			return nil, ErrAnnouncementNotFound
+		}
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		ids = append(ids, id)
+	}
+
+	// Pinned announcements left out of the request keep their relative order after the listed ones
+	for _, a := range pinned {
+		if !seen[a.ID] {
+			ids = append(ids, a.ID)
+		}
+	}
+
+	if err := s.announcementRepo.UpdatePinPositions(ctx, workspaceID, ids); err != nil {
+		return nil, err
+	}
+
+	s.LogActivity(ctx, workspaceID, userID, "announcement.pins_reordered", "announcement", workspaceID.String(), models.JSON{"count": len(ids)})
+	return s.announcementRepo.ListPinned(ctx, workspaceID, userID)
+}
+
 func (s *WorkspaceService) DeleteAnnouncement(ctx context.Context, workspaceID, announcementID, userID uuid.UUID) error {
 	role, _ := s.memberRepo.GetRole(ctx, workspaceID, userID)
 	if role != "owner" && role != "admin" {
@@ -1686,12 +3784,13 @@ func (s *WorkspaceService) DeleteAnnouncement(ctx context.Context, workspaceID,
 	}
 
 	announcement, err := s.announcementRepo.GetByID(ctx, announcementID)
-	if err != nil || announcement == nil {
+	if err != nil || announcement == nil || announcement.WorkspaceID != workspaceID {
 		return ErrAnnouncementNotFound
 	}
-
-	if announcement.WorkspaceID != workspaceID {
-		return ErrNotAuthorized
+	if !announcement.IsPublished {
+		if err := s.cancelAnnouncementSchedule(ctx, announcement); err != nil {
+			return err
+		}
 	}
 
 	if err := s.announcementRepo.Delete(ctx, announcementID); err != nil {
@@ -1743,21 +3842,66 @@ func (s *WorkspaceService) ListWebhooks(ctx context.Context, workspaceID, userID
 	return s.webhookRepo.ListByWorkspace(ctx, workspaceID)
 }
 
-func (s *WorkspaceService) UpdateWebhook(ctx context.Context, workspaceID, webhookID, userID uuid.UUID, req *models.UpdateWebhookRequest) (*models.WorkspaceWebhook, error) {
+// GetWebhookHealth summarises delivery health for every webhook in the
+// workspace.
+func (s *WorkspaceService) GetWebhookHealth(ctx context.Context, workspaceID, userID uuid.UUID) (*models.WebhookHealthReport, error) {
 	role, _ := s.memberRepo.GetRole(ctx, workspaceID, userID)
 	if role != "owner" && role != "admin" {
 		return nil, ErrNotAuthorized
 	}
 
-	webhook, err := s.webhookRepo.GetByID(ctx, webhookID)
-	if err != nil || webhook == nil {
-		return nil, ErrWebhookNotFound
+	webhooks, err := s.webhookRepo.ListByWorkspace(ctx, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.WebhookHealthReport{Webhooks: []*models.WebhookHealth{}}
+	for _, w := range webhooks {
+		status := webhookHealthStatus(w)
+		switch status {
+		case "healthy":
+			report.Healthy++
+		case "degraded":
+			report.Degraded++
+		case "disabled":
+			report.Disabled++
+		}
+		report.Webhooks = append(report.Webhooks, &models.WebhookHealth{
+			WebhookID:       w.ID,
+			Name:            w.Name,
+			URL:             w.URL,
+			FailureCount:    w.FailureCount,
+			LastTriggeredAt: w.LastTriggeredAt,
+			Status:          status,
+		})
+	}
+	return report, nil
+}
+
+// webhookHealthStatus classifies a webhook: inactive ones are disabled, and
+// active ones with webhookDegradedFailures or more consecutive failures are
+// degraded.
+func webhookHealthStatus(w *models.WorkspaceWebhook) string {
+	if !w.IsActive {
+		return "disabled"
+	}
+	if w.FailureCount >= webhookDegradedFailures {
+		return "degraded"
 	}
+	return "healthy"
+}
 
-	if webhook.WorkspaceID != workspaceID {
+func (s *WorkspaceService) UpdateWebhook(ctx context.Context, workspaceID, webhookID, userID uuid.UUID, req *models.UpdateWebhookRequest) (*models.WorkspaceWebhook, error) {
+	role, _ := s.memberRepo.GetRole(ctx, workspaceID, userID)
+	if role != "owner" && role != "admin" {
 		return nil, ErrNotAuthorized
 	}
 
+	webhook, err := s.webhookRepo.GetByID(ctx, webhookID)
+	if err != nil || webhook == nil || webhook.WorkspaceID != workspaceID {
+		return nil, ErrWebhookNotFound
+	}
+
 	if req.Name != nil {
 		webhook.Name = *req.Name
 	}
@@ -1786,14 +3930,10 @@ func (s *WorkspaceService) DeleteWebhook(ctx context.Context, workspaceID, webho
 	}
 
 	webhook, err := s.webhookRepo.GetByID(ctx, webhookID)
-	if err != nil || webhook == nil {
+	if err != nil || webhook == nil || webhook.WorkspaceID != workspaceID {
 		return ErrWebhookNotFound
 	}
 
-	if webhook.WorkspaceID != workspaceID {
-		return ErrNotAuthorized
-	}
-
 	if err := s.webhookRepo.Delete(ctx, webhookID); err != nil {
 		return err
 	}
@@ -1809,21 +3949,17 @@ func (s *WorkspaceService) TestWebhook(ctx context.Context, workspaceID, webhook
 	}
 
 	webhook, err := s.webhookRepo.GetByID(ctx, webhookID)
-	if err != nil || webhook == nil {
+	if err != nil || webhook == nil || webhook.WorkspaceID != workspaceID {
 		return ErrWebhookNotFound
 	}
 
-	if webhook.WorkspaceID != workspaceID {
-		return ErrNotAuthorized
-	}
-
 	payload := map[string]interface{}{
 		"type":         "webhook.test",
 		"workspace_id": workspaceID,
 		"timestamp":    time.Now(),
 	}
 
-	if err := s.sendWebhookRequest(webhook.URL, webhook.Secret, payload); err != nil {
+	if err := s.sendWebhookRequest(ctx, webhook.URL, webhook.Secret, payload); err != nil {
 		s.webhookRepo.IncrementFailureCount(ctx, webhookID)
 		return fmt.Errorf("webhook test failed: %w", err)
 	}
@@ -1839,20 +3975,71 @@ func (s *WorkspaceService) TriggerWebhooks(ctx context.Context, workspaceID uuid
 		return
 	}
 
+	// Deliveries run after this returns, so snapshot the payload rather than
+	// sharing a map the caller may go on to reuse.
+	payload = copyEventData(payload)
 	for _, webhook := range webhooks {
-		go func(w *models.WorkspaceWebhook) {
-			if err := s.sendWebhookRequest(w.URL, w.Secret, payload); err != nil {
-				s.webhookRepo.IncrementFailureCount(ctx, w.ID)
-				s.logger.WithError(err).WithField("webhook_id", w.ID).Warn("Failed to trigger webhook")
-			} else {
-				s.webhookRepo.UpdateLastTriggered(ctx, w.ID)
-				s.webhookRepo.ResetFailureCount(ctx, w.ID)
-			}
-		}(webhook)
+		s.webhooks.Enqueue(ctx, webhook, payload)
+	}
+}
+
+func (s *WorkspaceService) sendWebhookRequest(ctx context.Context, url, secret string, payload map[string]interface{}) error {
+	err := postSignedJSON(ctx, url, secret, payload)
+	s.metrics.WebhookDelivered(err)
+	return err
+}
+
+// webhookClient is shared by all outbound webhook and audit sink
+// deliveries. Both URLs are set by workspace admins, so the dialer refuses
+// loopback, private and link-local addresses to keep signed payloads from
+// being pointed at internal services; the check runs on the resolved
+// address, which also covers DNS names that point inward. Redirects are not
+// followed so a signed payload is only ever sent to the configured URL.
+var webhookClient = &http.Client{
+	Timeout: 10 * time.Second,
+	Transport: &http.Transport{
+		DialContext: (&net.Dialer{
+			Timeout: 5 * time.Second,
+			Control: refusePrivateAddress,
+		}).DialContext,
+		TLSHandshakeTimeout: 5 * time.Second,
+	},
+	CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	},
+}
+
+// refusePrivateAddress is a net.Dialer Control hook that fails connections
+// to addresses that are not publicly routable.
+func refusePrivateAddress(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || !isPublicIP(ip) {
+		return fmt.Errorf("refusing to connect to non-public address %s", host)
+	}
+	return nil
+}
+
+// isPublicIP reports whether ip is a routable unicast address outside the
+// private, loopback, link-local and carrier-grade NAT ranges.
+func isPublicIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return false
 	}
+	if ip4 := ip.To4(); ip4 != nil && ip4[0] == 100 && ip4[1]&0xc0 == 64 {
+		return false
+	}
+	return true
 }
 
-func (s *WorkspaceService) sendWebhookRequest(url, secret string, payload map[string]interface{}) error {
+// postSignedJSON posts payload to url with an HMAC-SHA256 signature of the
+// body in the X-Webhook-Signature header. The request is abandoned when ctx
+// is done or webhookClient's timeout elapses, whichever is sooner.
+func postSignedJSON(ctx context.Context, url, secret string, payload interface{}) error {
 	body, err := json.Marshal(payload)
 	if err != nil {
 		return err
@@ -1862,16 +4049,18 @@ func (s *WorkspaceService) sendWebhookRequest(url, secret string, payload map[st
 	mac.Write(body)
 	signature := hex.EncodeToString(mac.Sum(nil))
 
-	req, err := http.NewRequest("POST", url, bytes.NewReader(body))
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(body))
 	if err != nil {
 		return err
 	}
 
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("X-Webhook-Signature", signature)
+	if id := requestid.FromContext(ctx); id != "" {
+		req.Header.Set(requestid.Header, id)
+	}
 
-	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Do(req)
+	resp, err := webhookClient.Do(req)
 	if err != nil {
 		return err
 	}
@@ -2004,14 +4193,10 @@ func (s *WorkspaceService) ListMemberNotes(ctx context.Context, workspaceID, tar
 
 func (s *WorkspaceService) UpdateMemberNote(ctx context.Context, workspaceID, noteID, userID uuid.UUID, req *models.UpdateMemberNoteRequest) (*models.MemberNote, error) {
 	note, err := s.memberNoteRepo.GetByID(ctx, noteID)
-	if err != nil || note == nil {
+	if err != nil || note == nil || note.WorkspaceID != workspaceID {
 		return nil, ErrMemberNoteNotFound
 	}
 
-	if note.WorkspaceID != workspaceID {
-		return nil, ErrNotAuthorized
-	}
-
 	if note.AuthorID != userID {
 		return nil, ErrNotAuthorized
 	}
@@ -2027,14 +4212,10 @@ func (s *WorkspaceService) UpdateMemberNote(ctx context.Context, workspaceID, no
 
 func (s *WorkspaceService) DeleteMemberNote(ctx context.Context, workspaceID, noteID, userID uuid.UUID) error {
 	note, err := s.memberNoteRepo.GetByID(ctx, noteID)
-	if err != nil || note == nil {
+	if err != nil || note == nil || note.WorkspaceID != workspaceID {
 		return ErrMemberNoteNotFound
 	}
 
-	if note.WorkspaceID != workspaceID {
-		return ErrNotAuthorized
-	}
-
 	// Only author or owner can delete
 	if note.AuthorID != userID {
 		role, _ := s.memberRepo.GetRole(ctx, workspaceID, userID)
@@ -2094,14 +4275,10 @@ func (s *WorkspaceService) UpdateScheduledAction(ctx context.Context, workspaceI
 	}
 
 	action, err := s.scheduledActionRepo.GetByID(ctx, actionID)
-	if err != nil || action == nil {
+	if err != nil || action == nil || action.WorkspaceID != workspaceID {
 		return nil, ErrScheduledActionNotFound
 	}
 
-	if action.WorkspaceID != workspaceID {
-		return nil, ErrNotAuthorized
-	}
-
 	if action.Status != "pending" {
 		return nil, fmt.Errorf("cannot update action with status: %s", action.Status)
 	}
@@ -2131,14 +4308,10 @@ func (s *WorkspaceService) CancelScheduledAction(ctx context.Context, workspaceI
 	}
 
 	action, err := s.scheduledActionRepo.GetByID(ctx, actionID)
-	if err != nil || action == nil {
+	if err != nil || action == nil || action.WorkspaceID != workspaceID {
 		return ErrScheduledActionNotFound
 	}
 
-	if action.WorkspaceID != workspaceID {
-		return ErrNotAuthorized
-	}
-
 	if action.Status != "pending" {
 		return fmt.Errorf("cannot cancel action with status: %s", action.Status)
 	}
@@ -2158,14 +4331,10 @@ func (s *WorkspaceService) DeleteScheduledAction(ctx context.Context, workspaceI
 	}
 
 	action, err := s.scheduledActionRepo.GetByID(ctx, actionID)
-	if err != nil || action == nil {
+	if err != nil || action == nil || action.WorkspaceID != workspaceID {
 		return ErrScheduledActionNotFound
 	}
 
-	if action.WorkspaceID != workspaceID {
-		return ErrNotAuthorized
-	}
-
 	if err := s.scheduledActionRepo.Delete(ctx, actionID); err != nil {
 		return err
 	}
@@ -2312,27 +4481,39 @@ func (s *WorkspaceService) UpdateQuota(ctx context.Context, workspaceID, userID
 // ── Workspace Archive / Restore ──
 
 func (s *WorkspaceService) ArchiveWorkspace(ctx context.Context, workspaceID, userID uuid.UUID, req *models.ArchiveWorkspaceRequest) error {
+	return s.WithWorkspaceLock(ctx, workspaceID, func(ctx context.Context) error {
+		return s.archiveWorkspace(ctx, workspaceID, userID, req)
+	})
+}
+
+func (s *WorkspaceService) archiveWorkspace(ctx context.Context, workspaceID, userID uuid.UUID, req *models.ArchiveWorkspaceRequest) error {
 	role, _ := s.memberRepo.GetRole(ctx, workspaceID, userID)
 	if role != "owner" {
 		return ErrNotAuthorized
 	}
 
 	workspace, err := s.workspaceRepo.GetByID(ctx, workspaceID)
-	if err != nil || workspace == nil {
-		return ErrWorkspaceNotFound
+	if err != nil {
+		return err
 	}
-
-	if workspace.DeletedAt != nil {
-		return ErrWorkspaceArchived
+	if workspace == nil {
+		archived, err := s.workspaceRepo.GetArchivedByID(ctx, workspaceID)
+		if err != nil {
+			return err
+		}
+		if archived != nil {
+			return ErrWorkspaceArchived
+		}
+		return ErrWorkspaceNotFound
 	}
 
-	now := time.Now()
-	workspace.DeletedAt = &now
-	workspace.IsActive = false
-
-	if err := s.workspaceRepo.Update(ctx, workspace); err != nil {
+	archived, err := s.workspaceRepo.Archive(ctx, workspaceID, time.Now())
+	if err != nil {
 		return err
 	}
+	if !archived {
+		return ErrWorkspaceArchived
+	}
 
 	s.invalidateWorkspace(ctx, workspaceID)
 	s.LogActivity(ctx, workspaceID, userID, "workspace.archived", "workspace", workspaceID.String(), models.JSON{"reason": req.Reason})
@@ -2341,28 +4522,42 @@ func (s *WorkspaceService) ArchiveWorkspace(ctx context.Context, workspaceID, us
 }
 
 func (s *WorkspaceService) RestoreWorkspace(ctx context.Context, workspaceID, userID uuid.UUID) error {
+	return s.WithWorkspaceLock(ctx, workspaceID, func(ctx context.Context) error {
+		return s.restoreWorkspace(ctx, workspaceID, userID)
+	})
+}
+
+func (s *WorkspaceService) restoreWorkspace(ctx context.Context, workspaceID, userID uuid.UUID) error {
 	role, _ := s.memberRepo.GetRole(ctx, workspaceID, userID)
 	if role != "owner" {
 		return ErrNotAuthorized
 	}
 
-	workspace, err := s.workspaceRepo.GetByID(ctx, workspaceID)
-	if err != nil || workspace == nil {
-		return ErrWorkspaceNotFound
+	workspace, err := s.workspaceRepo.GetArchivedByID(ctx, workspaceID)
+	if err != nil {
+		return err
 	}
-
-	if workspace.DeletedAt == nil {
-		return ErrWorkspaceNotArchived
+	if workspace == nil {
+		live, err := s.workspaceRepo.GetByID(ctx, workspaceID)
+		if err != nil {
+			return err
+		}
+		if live != nil {
+			return ErrWorkspaceNotArchived
+		}
+		return ErrWorkspaceNotFound
 	}
 
-	workspace.DeletedAt = nil
-	workspace.IsActive = true
-
-	if err := s.workspaceRepo.Update(ctx, workspace); err != nil {
+	restored, err := s.workspaceRepo.Restore(ctx, workspaceID)
+	if err != nil {
 		return err
 	}
+	if !restored {
+		return ErrWorkspaceNotArchived
+	}
 
 	s.invalidateWorkspace(ctx, workspaceID)
+	s.clearWorkspaceTombstone(ctx, workspaceID)
 	s.LogActivity(ctx, workspaceID, userID, "workspace.restored", "workspace", workspaceID.String(), nil)
 	s.publishEvent(ctx, "workspace.events", workspaceID.String(), "workspace.restored", map[string]interface{}{"workspace_id": workspaceID, "restored_by": userID})
 	return nil
@@ -2467,11 +4662,33 @@ func (s *WorkspaceService) CloneWorkspace(ctx context.Context, sourceID, userID
 
 // ── Pinned Items ──
 
+// CreatePinnedItem pins an item to the workspace. Any member may pin unless
+// the pin_admins_only setting is on, and the workspace holds at most
+// max_pinned_items pins.
 func (s *WorkspaceService) CreatePinnedItem(ctx context.Context, workspaceID, userID uuid.UUID, req *models.CreatePinnedItemRequest) (*models.WorkspacePinnedItem, error) {
 	role, _ := s.memberRepo.GetRole(ctx, workspaceID, userID)
 	if role == "" {
 		return nil, ErrNotMember
 	}
+	if !pinItemTypes[req.ItemType] {
+		return nil, ErrInvalidPinType
+	}
+
+	workspace, err := s.workspaceRepo.GetByID(ctx, workspaceID)
+	if err != nil || workspace == nil {
+		return nil, ErrWorkspaceNotFound
+	}
+	if !canPin(role, workspace.Settings) {
+		return nil, ErrNotAuthorized
+	}
+
+	count, err := s.pinnedItemRepo.CountByWorkspace(ctx, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	if count >= settingInt(workspace.Settings, settingMaxPinnedItems, defaultMaxPinnedItems) {
+		return nil, ErrPinLimitReached
+	}
 
 	maxPos, _ := s.pinnedItemRepo.GetMaxPosition(ctx, workspaceID)
 
@@ -2497,6 +4714,14 @@ func (s *WorkspaceService) CreatePinnedItem(ctx context.Context, workspaceID, us
 	return item, nil
 }
 
+// pinItemTypes are the kinds of item that can be pinned.
+var pinItemTypes = map[string]bool{
+	"message": true,
+	"link":    true,
+	"file":    true,
+	"note":    true,
+}
+
 func (s *WorkspaceService) ListPinnedItems(ctx context.Context, workspaceID, userID uuid.UUID) ([]*models.WorkspacePinnedItem, error) {
 	isMember, _ := s.memberRepo.IsMember(ctx, workspaceID, userID)
 	if !isMember {
@@ -2508,14 +4733,10 @@ func (s *WorkspaceService) ListPinnedItems(ctx context.Context, workspaceID, use
 
 func (s *WorkspaceService) UpdatePinnedItem(ctx context.Context, workspaceID, pinID, userID uuid.UUID, req *models.UpdatePinnedItemRequest) (*models.WorkspacePinnedItem, error) {
 	item, err := s.pinnedItemRepo.GetByID(ctx, pinID)
-	if err != nil || item == nil {
+	if err != nil || item == nil || item.WorkspaceID != workspaceID {
 		return nil, ErrPinnedItemNotFound
 	}
 
-	if item.WorkspaceID != workspaceID {
-		return nil, ErrNotAuthorized
-	}
-
 	// Only pinner or admin/owner can update
 	if item.PinnedBy != userID {
 		role, _ := s.memberRepo.GetRole(ctx, workspaceID, userID)
@@ -2543,14 +4764,10 @@ func (s *WorkspaceService) UpdatePinnedItem(ctx context.Context, workspaceID, pi
 
 func (s *WorkspaceService) DeletePinnedItem(ctx context.Context, workspaceID, pinID, userID uuid.UUID) error {
 	item, err := s.pinnedItemRepo.GetByID(ctx, pinID)
-	if err != nil || item == nil {
+	if err != nil || item == nil || item.WorkspaceID != workspaceID {
 		return ErrPinnedItemNotFound
 	}
 
-	if item.WorkspaceID != workspaceID {
-		return ErrNotAuthorized
-	}
-
 	// Only pinner or admin/owner can delete
 	if item.PinnedBy != userID {
 		role, _ := s.memberRepo.GetRole(ctx, workspaceID, userID)
@@ -2593,6 +4810,11 @@ func (s *WorkspaceService) CreateGroup(ctx context.Context, workspaceID, userID
 		return nil, ErrNotAuthorized
 	}
 
+	color, err := normalizeOptionalColor(req.Color)
+	if err != nil {
+		return nil, err
+	}
+
 	existing, _ := s.groupRepo.GetByName(ctx, workspaceID, req.Name)
 	if existing != nil {
 		return nil, ErrGroupNameExists
@@ -2603,7 +4825,7 @@ func (s *WorkspaceService) CreateGroup(ctx context.Context, workspaceID, userID
 		WorkspaceID: workspaceID,
 		Name:        req.Name,
 		Description: req.Description,
-		Color:       req.Color,
+		Color:       color,
 		CreatedBy:   userID,
 		MemberCount: 0,
 		CreatedAt:   time.Now(),
@@ -2634,14 +4856,10 @@ func (s *WorkspaceService) GetGroup(ctx context.Context, workspaceID, groupID, u
 	}
 
 	group, err := s.groupRepo.GetByID(ctx, groupID)
-	if err != nil || group == nil {
+	if err != nil || group == nil || group.WorkspaceID != workspaceID {
 		return nil, ErrGroupNotFound
 	}
 
-	if group.WorkspaceID != workspaceID {
-		return nil, ErrNotAuthorized
-	}
-
 	members, _ := s.groupRepo.ListGroupMembers(ctx, groupID)
 
 	return &models.MemberGroupWithMembers{
@@ -2657,14 +4875,10 @@ func (s *WorkspaceService) UpdateGroup(ctx context.Context, workspaceID, groupID
 	}
 
 	group, err := s.groupRepo.GetByID(ctx, groupID)
-	if err != nil || group == nil {
+	if err != nil || group == nil || group.WorkspaceID != workspaceID {
 		return nil, ErrGroupNotFound
 	}
 
-	if group.WorkspaceID != workspaceID {
-		return nil, ErrNotAuthorized
-	}
-
 	if req.Name != nil {
 		// Check for name conflict
 		existing, _ := s.groupRepo.GetByName(ctx, workspaceID, *req.Name)
@@ -2677,7 +4891,11 @@ func (s *WorkspaceService) UpdateGroup(ctx context.Context, workspaceID, groupID
 		group.Description = req.Description
 	}
 	if req.Color != nil {
-		group.Color = req.Color
+		color, err := normalizeOptionalColor(req.Color)
+		if err != nil {
+			return nil, err
+		}
+		group.Color = color
 	}
 
 	if err := s.groupRepo.Update(ctx, group); err != nil {
@@ -2695,14 +4913,10 @@ func (s *WorkspaceService) DeleteGroup(ctx context.Context, workspaceID, groupID
 	}
 
 	group, err := s.groupRepo.GetByID(ctx, groupID)
-	if err != nil || group == nil {
+	if err != nil || group == nil || group.WorkspaceID != workspaceID {
 		return ErrGroupNotFound
 	}
 
-	if group.WorkspaceID != workspaceID {
-		return ErrNotAuthorized
-	}
-
 	if err := s.groupRepo.Delete(ctx, groupID); err != nil {
 		return err
 	}
@@ -2718,14 +4932,10 @@ func (s *WorkspaceService) AddGroupMembers(ctx context.Context, workspaceID, gro
 	}
 
 	group, err := s.groupRepo.GetByID(ctx, groupID)
-	if err != nil || group == nil {
+	if err != nil || group == nil || group.WorkspaceID != workspaceID {
 		return nil, ErrGroupNotFound
 	}
 
-	if group.WorkspaceID != workspaceID {
-		return nil, ErrNotAuthorized
-	}
-
 	var added []uuid.UUID
 	for _, uidStr := range req.UserIDs {
 		uid, err := uuid.Parse(uidStr)
@@ -2770,14 +4980,10 @@ func (s *WorkspaceService) RemoveGroupMember(ctx context.Context, workspaceID, g
 	}
 
 	group, err := s.groupRepo.GetByID(ctx, groupID)
-	if err != nil || group == nil {
+	if err != nil || group == nil || group.WorkspaceID != workspaceID {
 		return ErrGroupNotFound
 	}
 
-	if group.WorkspaceID != workspaceID {
-		return ErrNotAuthorized
-	}
-
 	inGroup, _ := s.groupRepo.IsMemberOfGroup(ctx, groupID, targetID)
 	if !inGroup {
 		return ErrNotGroupMember
@@ -2854,14 +5060,10 @@ func (s *WorkspaceService) UpdateCustomField(ctx context.Context, workspaceID, f
 	}
 
 	field, err := s.customFieldRepo.GetByID(ctx, fieldID)
-	if err != nil || field == nil {
+	if err != nil || field == nil || field.WorkspaceID != workspaceID {
 		return nil, ErrCustomFieldNotFound
 	}
 
-	if field.WorkspaceID != workspaceID {
-		return nil, ErrNotAuthorized
-	}
-
 	if req.Name != nil {
 		existing, _ := s.customFieldRepo.GetByName(ctx, workspaceID, *req.Name)
 		if existing != nil && existing.ID != fieldID {
@@ -2894,14 +5096,10 @@ func (s *WorkspaceService) DeleteCustomField(ctx context.Context, workspaceID, f
 	}
 
 	field, err := s.customFieldRepo.GetByID(ctx, fieldID)
-	if err != nil || field == nil {
+	if err != nil || field == nil || field.WorkspaceID != workspaceID {
 		return ErrCustomFieldNotFound
 	}
 
-	if field.WorkspaceID != workspaceID {
-		return ErrNotAuthorized
-	}
-
 	if err := s.customFieldRepo.Delete(ctx, fieldID); err != nil {
 		return err
 	}
@@ -2917,14 +5115,10 @@ func (s *WorkspaceService) SetCustomFieldValue(ctx context.Context, workspaceID,
 	}
 
 	field, err := s.customFieldRepo.GetByID(ctx, fieldID)
-	if err != nil || field == nil {
+	if err != nil || field == nil || field.WorkspaceID != workspaceID {
 		return nil, ErrCustomFieldNotFound
 	}
 
-	if field.WorkspaceID != workspaceID {
-		return nil, ErrNotAuthorized
-	}
-
 	value := &models.WorkspaceCustomFieldValue{
 		ID:        uuid.New(),
 		FieldID:   fieldID,
@@ -2969,12 +5163,42 @@ func (s *WorkspaceService) GetCustomFieldValues(ctx context.Context, workspaceID
 	return results, nil
 }
 
+func (s *WorkspaceService) GetMemberFacets(ctx context.Context, workspaceID, userID, fieldID uuid.UUID) (*models.MemberFacetsResponse, error) {
+	if err := s.requireCapability(ctx, workspaceID, userID, capMemberDirectory); err != nil {
+		return nil, err
+	}
+
+	field, err := s.customFieldRepo.GetByID(ctx, fieldID)
+	if err != nil || field == nil || field.WorkspaceID != workspaceID {
+		return nil, ErrCustomFieldNotFound
+	}
+
+	facets, err := s.customFieldRepo.GetMemberFacets(ctx, workspaceID, fieldID)
+	if err != nil {
+		return nil, err
+	}
+	if facets == nil {
+		facets = []*models.CustomFieldFacet{}
+	}
+
+	total := 0
+	for _, f := range facets {
+		total += f.Count
+	}
+
+	return &models.MemberFacetsResponse{
+		FieldID:   field.ID,
+		FieldName: field.Name,
+		Facets:    facets,
+		Total:     total,
+	}, nil
+}
+
 // ── Reactions ──
 
 func (s *WorkspaceService) AddReaction(ctx context.Context, workspaceID, userID uuid.UUID, req *models.AddReactionRequest) error {
-	isMember, _ := s.memberRepo.IsMember(ctx, workspaceID, userID)
-	if !isMember {
-		return ErrNotMember
+	if err := s.requireCapability(ctx, workspaceID, userID, capReact); err != nil {
+		return err
 	}
 
 	entityID, err := uuid.Parse(req.EntityID)
@@ -3007,7 +5231,58 @@ func (s *WorkspaceService) RemoveReaction(ctx context.Context, workspaceID, user
 	if !isMember {
 		return ErrNotMember
 	}
-	return s.reactionRepo.Delete(ctx, entityType, entityID, userID, emoji)
+	removed, err := s.reactionRepo.Delete(ctx, entityType, entityID, userID, emoji)
+	if err != nil {
+		return err
+	}
+	if removed == 0 {
+		return ErrReactionNotFound
+	}
+	return nil
+}
+
+// GetMyReactionsByEmoji groups the caller's reactions in the workspace by
+// emoji, most used first.
+func (s *WorkspaceService) GetMyReactionsByEmoji(ctx context.Context, workspaceID, userID uuid.UUID) ([]*models.EmojiReactionGroup, error) {
+	isMember, _ := s.memberRepo.IsMember(ctx, workspaceID, userID)
+	if !isMember {
+		return nil, ErrNotMember
+	}
+
+	reactions, err := s.reactionRepo.ListByUserInWorkspace(ctx, workspaceID, userID)
+	if err != nil {
+		return nil, err
+	}
+	return groupReactionsByEmoji(reactions), nil
+}
+
+// groupReactionsByEmoji keeps each group's entities in the input order and
+// sorts groups by count, then emoji.
+func groupReactionsByEmoji(reactions []*models.WorkspaceReaction) []*models.EmojiReactionGroup {
+	groups := []*models.EmojiReactionGroup{}
+	byEmoji := make(map[string]*models.EmojiReactionGroup)
+	for _, r := range reactions {
+		group, ok := byEmoji[r.Emoji]
+		if !ok {
+			group = &models.EmojiReactionGroup{Emoji: r.Emoji, Entities: []*models.ReactedEntity{}}
+			byEmoji[r.Emoji] = group
+			groups = append(groups, group)
+		}
+		group.Entities = append(group.Entities, &models.ReactedEntity{
+			EntityType: r.EntityType,
+			EntityID:   r.EntityID,
+			ReactedAt:  r.CreatedAt,
+		})
+		group.Count++
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool {
+		if groups[i].Count != groups[j].Count {
+			return groups[i].Count > groups[j].Count
+		}
+		return groups[i].Emoji < groups[j].Emoji
+	})
+	return groups
 }
 
 func (s *WorkspaceService) ListReactions(ctx context.Context, workspaceID, userID uuid.UUID, entityType string, entityID uuid.UUID) ([]*models.WorkspaceReaction, error) {
@@ -3029,17 +5304,22 @@ func (s *WorkspaceService) GetReactionSummary(ctx context.Context, workspaceID,
 // ── Bookmarks ──
 
 func (s *WorkspaceService) CreateBookmark(ctx context.Context, workspaceID, userID uuid.UUID, req *models.CreateBookmarkRequest) (*models.WorkspaceBookmark, error) {
-	isMember, _ := s.memberRepo.IsMember(ctx, workspaceID, userID)
-	if !isMember {
+	role, _ := s.memberRepo.GetRole(ctx, workspaceID, userID)
+	if role == "" {
 		return nil, ErrNotMember
 	}
 
+	workspace, err := s.workspaceRepo.GetByID(ctx, workspaceID)
+	if err != nil || workspace == nil {
+		return nil, ErrWorkspaceNotFound
+	}
+
 	count, err := s.bookmarkRepo.CountByUser(ctx, workspaceID, userID)
 	if err != nil {
 		return nil, err
 	}
-	if count >= 100 {
-		return nil, ErrBookmarkLimitReached
+	if limit := bookmarkLimit(workspace, role); count >= limit {
+		return nil, &BookmarkLimitError{Count: count, Limit: limit}
 	}
 
 	maxPos, _ := s.bookmarkRepo.GetMaxPosition(ctx, workspaceID, userID)
@@ -3109,17 +5389,149 @@ func (s *WorkspaceService) UpdateBookmark(ctx context.Context, workspaceID, user
 	if req.URL != nil {
 		bookmark.URL = req.URL
 	}
-	if req.Notes != nil {
-		bookmark.Notes = req.Notes
+	if req.Notes != nil {
+		bookmark.Notes = req.Notes
+	}
+	if req.FolderName != nil {
+		bookmark.FolderName = req.FolderName
+	}
+
+	if err := s.bookmarkRepo.Update(ctx, bookmark); err != nil {
+		return nil, err
+	}
+	return bookmark, nil
+}
+
+func (s *WorkspaceService) RemapBookmarkFolders(ctx context.Context, workspaceID, userID uuid.UUID, mapping map[string]string) (*models.RemapBookmarkFoldersResponse, error) {
+	isMember, _ := s.memberRepo.IsMember(ctx, workspaceID, userID)
+	if !isMember {
+		return nil, ErrNotMember
+	}
+
+	cleaned := make(map[string]string, len(mapping))
+	for oldName, newName := range mapping {
+		oldName = strings.TrimSpace(oldName)
+		newName = strings.TrimSpace(newName)
+		if oldName == "" || oldName == newName {
+			continue
+		}
+		cleaned[oldName] = newName
+	}
+
+	updated := int64(0)
+	if len(cleaned) > 0 {
+		var err error
+		updated, err = s.bookmarkRepo.RemapFolders(ctx, workspaceID, userID, cleaned)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	folders, err := s.bookmarkRepo.ListFolders(ctx, workspaceID, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &models.RemapBookmarkFoldersResponse{Updated: updated, Folders: folders}, nil
+}
+
+// BookmarkLimitError reports that a member has used their whole bookmark
+// allowance. It matches ErrBookmarkLimitReached with errors.Is.
+type BookmarkLimitError struct {
+	Count int
+	Limit int
+}
+
+func (e *BookmarkLimitError) Error() string {
+	return fmt.Sprintf("bookmark limit reached (%d/%d)", e.Count, e.Limit)
+}
+
+func (e *BookmarkLimitError) Is(target error) bool {
+	return target == ErrBookmarkLimitReached
+}
+
+// bookmarkLimit returns how many bookmarks a member with the role may keep.
+// The plan sets the ceiling; the max_bookmarks_per_user setting can lower it
+// but never raise it. Guests are further capped at guestBookmarkLimit.
+func bookmarkLimit(workspace *models.Workspace, role string) int {
+	limit := planFeatures(workspace.Plan).MaxBookmarksPerUser
+	if configured := settingInt(workspace.Settings, settingMaxBookmarksPerUser, limit); configured < limit {
+		limit = configured
+	}
+	if role == guestRole && guestBookmarkLimit < limit {
+		limit = guestBookmarkLimit
+	}
+	return limit
+}
+
+// RenameBookmarkFolder renames one of the caller's folders. Renaming onto an
+// existing folder merges the two.
+func (s *WorkspaceService) RenameBookmarkFolder(ctx context.Context, workspaceID, userID uuid.UUID, oldName, newName string) (*models.BookmarkFolderResponse, error) {
+	isMember, _ := s.memberRepo.IsMember(ctx, workspaceID, userID)
+	if !isMember {
+		return nil, ErrNotMember
+	}
+
+	oldName = strings.TrimSpace(oldName)
+	newName = strings.TrimSpace(newName)
+	if oldName == "" || newName == "" {
+		return nil, ErrInvalidFolderName
+	}
+
+	updated := int64(0)
+	if oldName != newName {
+		var err error
+		updated, err = s.bookmarkRepo.RenameFolder(ctx, workspaceID, userID, oldName, newName)
+		if err != nil {
+			return nil, err
+		}
+		if updated == 0 {
+			return nil, ErrBookmarkFolderNotFound
+		}
+	}
+
+	return s.bookmarkFolderResponse(ctx, workspaceID, userID, updated)
+}
+
+// DeleteBookmarkFolder removes one of the caller's folders. With
+// moveToUnfiled its bookmarks are kept without a folder; otherwise they are
+// deleted along with it.
+func (s *WorkspaceService) DeleteBookmarkFolder(ctx context.Context, workspaceID, userID uuid.UUID, name string, moveToUnfiled bool) (*models.BookmarkFolderResponse, error) {
+	isMember, _ := s.memberRepo.IsMember(ctx, workspaceID, userID)
+	if !isMember {
+		return nil, ErrNotMember
+	}
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, ErrInvalidFolderName
+	}
+
+	var updated int64
+	var err error
+	if moveToUnfiled {
+		updated, err = s.bookmarkRepo.UnfileFolder(ctx, workspaceID, userID, name)
+	} else {
+		updated, err = s.bookmarkRepo.DeleteFolder(ctx, workspaceID, userID, name)
 	}
-	if req.FolderName != nil {
-		bookmark.FolderName = req.FolderName
+	if err != nil {
+		return nil, err
+	}
+	if updated == 0 {
+		return nil, ErrBookmarkFolderNotFound
 	}
 
-	if err := s.bookmarkRepo.Update(ctx, bookmark); err != nil {
+	return s.bookmarkFolderResponse(ctx, workspaceID, userID, updated)
+}
+
+func (s *WorkspaceService) bookmarkFolderResponse(ctx context.Context, workspaceID, userID uuid.UUID, updated int64) (*models.BookmarkFolderResponse, error) {
+	folders, err := s.bookmarkRepo.ListFolders(ctx, workspaceID, userID)
+	if err != nil {
 		return nil, err
 	}
-	return bookmark, nil
+	if folders == nil {
+		folders = []string{}
+	}
+	return &models.BookmarkFolderResponse{Updated: updated, Folders: folders}, nil
 }
 
 func (s *WorkspaceService) DeleteBookmark(ctx context.Context, workspaceID, userID, bookmarkID uuid.UUID) error {
@@ -3157,6 +5569,26 @@ func (s *WorkspaceService) RecordInvitation(ctx context.Context, workspaceID, in
 	return s.invitationHistoryRepo.Create(ctx, record)
 }
 
+// recordCodeJoin records a join through an invite code in the invitation
+// history. Code joins have no pending phase, so they are recorded as accepted.
+func (s *WorkspaceService) recordCodeJoin(ctx context.Context, inviteCode *models.WorkspaceInviteCode, userID uuid.UUID) {
+	now := time.Now()
+	record := &models.InvitationHistory{
+		ID:          uuid.New(),
+		WorkspaceID: inviteCode.WorkspaceID,
+		InviterID:   inviteCode.CreatedBy,
+		InviteeID:   &userID,
+		Method:      "code",
+		Role:        inviteCode.Role,
+		Status:      "accepted",
+		AcceptedAt:  &now,
+		CreatedAt:   now,
+	}
+	if err := s.invitationHistoryRepo.Create(ctx, record); err != nil {
+		s.logger.WithError(err).Warn("Failed to record invite code join")
+	}
+}
+
 func (s *WorkspaceService) ListInvitationHistory(ctx context.Context, workspaceID, userID uuid.UUID, page, perPage int) ([]*models.InvitationHistory, int64, error) {
 	role, _ := s.memberRepo.GetRole(ctx, workspaceID, userID)
 	if role != "owner" && role != "admin" {
@@ -3251,22 +5683,29 @@ func (s *WorkspaceService) CreateFeatureFlag(ctx context.Context, workspaceID, u
 		return nil, ErrFeatureFlagKeyExists
 	}
 
+	rollout := 100
+	if req.RolloutPercentage != nil {
+		rollout = *req.RolloutPercentage
+	}
+
 	flag := &models.WorkspaceFeatureFlag{
-		ID:          uuid.New(),
-		WorkspaceID: workspaceID,
-		Key:         req.Key,
-		Enabled:     req.Enabled,
-		Description: req.Description,
-		Metadata:    req.Metadata,
-		CreatedBy:   userID,
-		UpdatedBy:   &userID,
-		CreatedAt:   time.Now(),
-		UpdatedAt:   time.Now(),
+		ID:                uuid.New(),
+		WorkspaceID:       workspaceID,
+		Key:               req.Key,
+		Enabled:           req.Enabled,
+		RolloutPercentage: rollout,
+		Description:       req.Description,
+		Metadata:          req.Metadata,
+		CreatedBy:         userID,
+		UpdatedBy:         &userID,
+		CreatedAt:         time.Now(),
+		UpdatedAt:         time.Now(),
 	}
 
 	if err := s.featureFlagRepo.Create(ctx, flag); err != nil {
 		return nil, err
 	}
+	s.recordFeatureFlagChange(ctx, flag, "created", userID)
 	return flag, nil
 }
 
@@ -3291,10 +5730,14 @@ func (s *WorkspaceService) UpdateFeatureFlag(ctx context.Context, workspaceID, u
 	if flag == nil || flag.WorkspaceID != workspaceID {
 		return nil, ErrFeatureFlagNotFound
 	}
+	wasEnabled, oldRollout := flag.Enabled, flag.RolloutPercentage
 
 	if req.Enabled != nil {
 		flag.Enabled = *req.Enabled
 	}
+	if req.RolloutPercentage != nil {
+		flag.RolloutPercentage = *req.RolloutPercentage
+	}
 	if req.Description != nil {
 		flag.Description = req.Description
 	}
@@ -3306,9 +5749,62 @@ func (s *WorkspaceService) UpdateFeatureFlag(ctx context.Context, workspaceID, u
 	if err := s.featureFlagRepo.Update(ctx, flag); err != nil {
 		return nil, err
 	}
+
+	switch {
+	case flag.Enabled != wasEnabled && flag.Enabled:
+		s.recordFeatureFlagChange(ctx, flag, "enabled", userID)
+	case flag.Enabled != wasEnabled:
+		s.recordFeatureFlagChange(ctx, flag, "disabled", userID)
+	case flag.RolloutPercentage != oldRollout:
+		s.recordFeatureFlagChange(ctx, flag, "rollout_changed", userID)
+	}
 	return flag, nil
 }
 
+// GetFeatureFlagHistory returns a flag's state changes, newest first.
+func (s *WorkspaceService) GetFeatureFlagHistory(ctx context.Context, workspaceID, userID, flagID uuid.UUID) ([]*models.FeatureFlagHistoryEntry, error) {
+	role, _ := s.memberRepo.GetRole(ctx, workspaceID, userID)
+	if role != "owner" && role != "admin" {
+		return nil, ErrNotAuthorized
+	}
+
+	flag, err := s.featureFlagRepo.GetByID(ctx, flagID)
+	if err != nil {
+		return nil, err
+	}
+	if flag == nil || flag.WorkspaceID != workspaceID {
+		return nil, ErrFeatureFlagNotFound
+	}
+
+	history, err := s.featureFlagRepo.ListHistory(ctx, flagID)
+	if err != nil {
+		return nil, err
+	}
+	if history == nil {
+		history = []*models.FeatureFlagHistoryEntry{}
+	}
+	return history, nil
+}
+
+// recordFeatureFlagChange appends to the flag's history. Failures are
+// logged rather than failing the change itself.
+func (s *WorkspaceService) recordFeatureFlagChange(ctx context.Context, flag *models.WorkspaceFeatureFlag, action string, userID uuid.UUID) {
+	entry := &models.FeatureFlagHistoryEntry{
+		ID:                uuid.New(),
+		WorkspaceID:       flag.WorkspaceID,
+		FlagID:            flag.ID,
+		FlagKey:           flag.Key,
+		Action:            action,
+		Enabled:           flag.Enabled,
+		RolloutPercentage: flag.RolloutPercentage,
+		ChangedBy:         userID,
+		CreatedAt:         time.Now(),
+	}
+	if err := s.featureFlagRepo.CreateHistory(ctx, entry); err != nil {
+		s.logger.WithError(err).WithField("flag_id", flag.ID).Warn("Failed to record feature flag history")
+	}
+}
+
 func (s *WorkspaceService) DeleteFeatureFlag(ctx context.Context, workspaceID, userID, flagID uuid.UUID) error {
 	role, _ := s.memberRepo.GetRole(ctx, workspaceID, userID)
 	if role != "owner" && role != "admin" {
@@ -3340,7 +5836,50 @@ func (s *WorkspaceService) CheckFeatureFlag(ctx context.Context, workspaceID, us
 		return &models.FeatureFlagCheckResponse{Key: key, Enabled: false}, nil
 	}
 
-	return &models.FeatureFlagCheckResponse{Key: key, Enabled: flag.Enabled}, nil
+	return &models.FeatureFlagCheckResponse{Key: key, Enabled: evaluateFeatureFlag(flag, userID)}, nil
+}
+
+// CheckFeatureFlags evaluates several flags for the user in one query. Keys
+// without a flag are reported as disabled. Clients should prefer this over
+// per-key checks when bootstrapping.
+func (s *WorkspaceService) CheckFeatureFlags(ctx context.Context, workspaceID, userID uuid.UUID, keys []string) (map[string]bool, error) {
+	isMember, _ := s.memberRepo.IsMember(ctx, workspaceID, userID)
+	if !isMember {
+		return nil, ErrNotMember
+	}
+
+	flags, err := s.featureFlagRepo.ListByKeys(ctx, workspaceID, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		result[key] = false
+	}
+	for _, flag := range flags {
+		result[flag.Key] = evaluateFeatureFlag(flag, userID)
+	}
+	return result, nil
+}
+
+// evaluateFeatureFlag decides whether a flag is on for a user. Single and
+// batch checks must both go through here so they never disagree. An enabled
+// flag is on for the users whose bucket falls under its rollout percentage,
+// so at 100 it is on for everyone.
+func evaluateFeatureFlag(flag *models.WorkspaceFeatureFlag, userID uuid.UUID) bool {
+	if !flag.Enabled {
+		return false
+	}
+	return featureFlagBucket(flag.Key, userID) < flag.RolloutPercentage
+}
+
+// featureFlagBucket places a user in one of 100 buckets for a flag. The
+// bucket depends only on the key and user, so a user keeps the same result
+// as a rollout grows, and different flags bucket users independently.
+func featureFlagBucket(key string, userID uuid.UUID) int {
+	sum := sha256.Sum256([]byte(key + ":" + userID.String()))
+	return int(binary.BigEndian.Uint32(sum[:4]) % 100)
 }
 
 // ── Integrations ──
@@ -3351,6 +5890,16 @@ func (s *WorkspaceService) CreateIntegration(ctx context.Context, workspaceID, u
 		return nil, ErrNotAuthorized
 	}
 
+	hasCredentials := req.Credentials != nil && strings.TrimSpace(*req.Credentials) != ""
+	if err := validateIntegration(req.Provider, req.Config, hasCredentials); err != nil {
+		return nil, err
+	}
+
+	credentials, err := s.sealCredentials(req.Credentials)
+	if err != nil {
+		return nil, err
+	}
+
 	integration := &models.WorkspaceIntegration{
 		ID:          uuid.New(),
 		WorkspaceID: workspaceID,
@@ -3358,7 +5907,7 @@ func (s *WorkspaceService) CreateIntegration(ctx context.Context, workspaceID, u
 		Name:        req.Name,
 		Status:      "active",
 		Config:      req.Config,
-		Credentials: req.Credentials,
+		Credentials: credentials,
 		CreatedBy:   userID,
 		CreatedAt:   time.Now(),
 		UpdatedAt:   time.Now(),
@@ -3367,7 +5916,7 @@ func (s *WorkspaceService) CreateIntegration(ctx context.Context, workspaceID, u
 	if err := s.integrationRepo.Create(ctx, integration); err != nil {
 		return nil, err
 	}
-	return integration, nil
+	return maskIntegration(integration), nil
 }
 
 func (s *WorkspaceService) ListIntegrations(ctx context.Context, workspaceID, userID uuid.UUID) ([]*models.WorkspaceIntegration, error) {
@@ -3375,7 +5924,15 @@ func (s *WorkspaceService) ListIntegrations(ctx context.Context, workspaceID, us
 	if !isMember {
 		return nil, ErrNotMember
 	}
-	return s.integrationRepo.ListByWorkspace(ctx, workspaceID)
+
+	integrations, err := s.integrationRepo.ListByWorkspace(ctx, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	for _, integration := range integrations {
+		maskIntegration(integration)
+	}
+	return integrations, nil
 }
 
 func (s *WorkspaceService) GetIntegration(ctx context.Context, workspaceID, userID, integrationID uuid.UUID) (*models.WorkspaceIntegration, error) {
@@ -3391,7 +5948,7 @@ func (s *WorkspaceService) GetIntegration(ctx context.Context, workspaceID, user
 	if integration == nil || integration.WorkspaceID != workspaceID {
 		return nil, ErrIntegrationNotFound
 	}
-	return integration, nil
+	return maskIntegration(integration), nil
 }
 
 func (s *WorkspaceService) UpdateIntegration(ctx context.Context, workspaceID, userID, integrationID uuid.UUID, req *models.UpdateIntegrationRequest) (*models.WorkspaceIntegration, error) {
@@ -3418,13 +5975,97 @@ func (s *WorkspaceService) UpdateIntegration(ctx context.Context, workspaceID, u
 		integration.Config = req.Config
 	}
 	if req.Credentials != nil {
-		integration.Credentials = req.Credentials
+		if integration.Credentials, err = s.sealCredentials(req.Credentials); err != nil {
+			return nil, err
+		}
+	}
+	// The audit sink is configured through its own endpoint and is not in
+	// the provider registry.
+	if integration.Provider != auditSinkProvider && (req.Config != nil || req.Credentials != nil) {
+		hasCredentials := integration.Credentials != nil && (req.Credentials == nil || strings.TrimSpace(*req.Credentials) != "")
+		if err := validateIntegration(integration.Provider, integration.Config, hasCredentials); err != nil {
+			return nil, err
+		}
 	}
 
 	if err := s.integrationRepo.Update(ctx, integration); err != nil {
 		return nil, err
 	}
-	return integration, nil
+	return maskIntegration(integration), nil
+}
+
+// RotateIntegrationCredentials re-encrypts an integration's credentials under
+// a fresh data key and the current master key. When credentials is given it
+// replaces the stored secret as well; otherwise the existing secret is kept.
+func (s *WorkspaceService) RotateIntegrationCredentials(ctx context.Context, workspaceID, userID, integrationID uuid.UUID, credentials *string) (*models.WorkspaceIntegration, error) {
+	role, _ := s.memberRepo.GetRole(ctx, workspaceID, userID)
+	if role != "owner" && role != "admin" {
+		return nil, ErrNotAuthorized
+	}
+
+	integration, err := s.integrationRepo.GetByID(ctx, integrationID)
+	if err != nil {
+		return nil, err
+	}
+	if integration == nil || integration.WorkspaceID != workspaceID {
+		return nil, ErrIntegrationNotFound
+	}
+
+	if credentials == nil {
+		plaintext, err := s.integrationCredentials(integration)
+		if err != nil {
+			return nil, err
+		}
+		if plaintext == "" {
+			return nil, ErrNoCredentials
+		}
+		credentials = &plaintext
+	}
+
+	sealed, err := s.sealCredentials(credentials)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.integrationRepo.UpdateCredentials(ctx, integrationID, sealed); err != nil {
+		return nil, err
+	}
+	integration.Credentials = sealed
+	if integration.Provider == auditSinkProvider {
+		s.auditSink.Invalidate(workspaceID)
+	}
+
+	s.LogActivity(ctx, workspaceID, userID, "integration.credentials_rotated", "integration", integrationID.String(), models.JSON{"provider": integration.Provider})
+	return maskIntegration(integration), nil
+}
+
+// sealCredentials encrypts credentials for storage. Nil stays nil.
+func (s *WorkspaceService) sealCredentials(plaintext *string) (*string, error) {
+	if plaintext == nil {
+		return nil, nil
+	}
+	sealed, err := s.credentials.Encrypt(*plaintext)
+	if err != nil {
+		return nil, err
+	}
+	return &sealed, nil
+}
+
+// integrationCredentials decrypts an integration's credentials. Call it only
+// right before an outbound request that needs them.
+func (s *WorkspaceService) integrationCredentials(integration *models.WorkspaceIntegration) (string, error) {
+	if integration.Credentials == nil {
+		return "", nil
+	}
+	return s.credentials.Decrypt(*integration.Credentials)
+}
+
+// maskIntegration hides stored credentials before an integration is
+// returned to a client.
+func maskIntegration(integration *models.WorkspaceIntegration) *models.WorkspaceIntegration {
+	if integration.Credentials != nil && *integration.Credentials != "" {
+		integration.CredentialsMasked = credentialsMask
+	}
+	return integration
 }
 
 func (s *WorkspaceService) DeleteIntegration(ctx context.Context, workspaceID, userID, integrationID uuid.UUID) error {
@@ -3444,6 +6085,72 @@ func (s *WorkspaceService) DeleteIntegration(ctx context.Context, workspaceID, u
 	return s.integrationRepo.Delete(ctx, integrationID)
 }
 
+// ── Audit Sink ──
+
+func (s *WorkspaceService) ConfigureAuditSink(ctx context.Context, workspaceID, userID uuid.UUID, req *models.ConfigureAuditSinkRequest) (*models.WorkspaceIntegration, error) {
+	role, _ := s.memberRepo.GetRole(ctx, workspaceID, userID)
+	if role != "owner" && role != "admin" {
+		return nil, ErrNotAuthorized
+	}
+
+	if !strings.HasPrefix(strings.ToLower(req.URL), "https://") {
+		return nil, ErrAuditSinkInsecureURL
+	}
+
+	secret, err := s.sealCredentials(&req.Secret)
+	if err != nil {
+		return nil, err
+	}
+
+	status := "active"
+	if req.Enabled != nil && !*req.Enabled {
+		status = "inactive"
+	}
+	config := models.JSON{"batch_size": auditSinkDefaultBatch}
+	if req.BatchSize != nil {
+		config["batch_size"] = *req.BatchSize
+	}
+
+	existing, err := s.integrationRepo.ListByProvider(ctx, workspaceID, auditSinkProvider)
+	if err != nil {
+		return nil, err
+	}
+
+	var sink *models.WorkspaceIntegration
+	if len(existing) > 0 {
+		sink = existing[0]
+		sink.Status = status
+		sink.Config = config
+		sink.WebhookURL = &req.URL
+		sink.Credentials = secret
+		sink.ErrorMessage = nil
+		if err := s.integrationRepo.Update(ctx, sink); err != nil {
+			return nil, err
+		}
+	} else {
+		sink = &models.WorkspaceIntegration{
+			ID:          uuid.New(),
+			WorkspaceID: workspaceID,
+			Provider:    auditSinkProvider,
+			Name:        "Audit sink",
+			Status:      status,
+			Config:      config,
+			Credentials: secret,
+			WebhookURL:  &req.URL,
+			CreatedBy:   userID,
+			CreatedAt:   time.Now(),
+			UpdatedAt:   time.Now(),
+		}
+		if err := s.integrationRepo.Create(ctx, sink); err != nil {
+			return nil, err
+		}
+	}
+
+	s.auditSink.Invalidate(workspaceID)
+	s.LogActivity(ctx, workspaceID, userID, "audit_sink.configured", "integration", sink.ID.String(), models.JSON{"status": status})
+	return maskIntegration(sink), nil
+}
+
 // ── Labels ──
 
 func (s *WorkspaceService) CreateLabel(ctx context.Context, workspaceID, userID uuid.UUID, req *models.CreateLabelRequest) (*models.WorkspaceLabel, error) {
@@ -3452,6 +6159,11 @@ func (s *WorkspaceService) CreateLabel(ctx context.Context, workspaceID, userID
 		return nil, ErrNotAuthorized
 	}
 
+	color, err := normalizeColor(req.Color)
+	if err != nil {
+		return nil, err
+	}
+
 	existing, _ := s.labelRepo.GetByName(ctx, workspaceID, req.Name)
 	if existing != nil {
 		return nil, ErrLabelNameExists
@@ -3463,7 +6175,7 @@ func (s *WorkspaceService) CreateLabel(ctx context.Context, workspaceID, userID
 		ID:          uuid.New(),
 		WorkspaceID: workspaceID,
 		Name:        req.Name,
-		Color:       req.Color,
+		Color:       color,
 		Description: req.Description,
 		Position:    maxPos + 1,
 		UsageCount:  0,
@@ -3508,7 +6220,11 @@ func (s *WorkspaceService) UpdateLabel(ctx context.Context, workspaceID, userID,
 		label.Name = *req.Name
 	}
 	if req.Color != nil {
-		label.Color = *req.Color
+		color, err := normalizeColor(*req.Color)
+		if err != nil {
+			return nil, err
+		}
+		label.Color = color
 	}
 	if req.Description != nil {
 		label.Description = req.Description
@@ -3544,7 +6260,23 @@ func (s *WorkspaceService) RecordActivity(ctx context.Context, workspaceID, user
 	if !isMember {
 		return ErrNotMember
 	}
-	return s.streakRepo.RecordDailyActivity(ctx, workspaceID, userID)
+	if err := s.TouchMemberSeen(ctx, workspaceID, userID); err != nil {
+		s.logger.WithError(err).Warn("Failed to record member last seen")
+	}
+	return s.streakRepo.RecordDailyActivity(ctx, workspaceID, userID, s.memberLocation(ctx, workspaceID, userID))
+}
+
+// memberLocation resolves the member's profile timezone, falling back to UTC.
+func (s *WorkspaceService) memberLocation(ctx context.Context, workspaceID, userID uuid.UUID) *time.Location {
+	profile, _ := s.profileRepo.GetByWorkspaceAndUser(ctx, workspaceID, userID)
+	if profile == nil || profile.Timezone == nil || *profile.Timezone == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(*profile.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
 }
 
 func (s *WorkspaceService) GetMyStreak(ctx context.Context, workspaceID, userID uuid.UUID) (*models.MemberActivityStreak, error) {
@@ -3586,14 +6318,15 @@ func (s *WorkspaceService) CreateChecklist(ctx context.Context, workspaceID, use
 	}
 
 	checklist := &models.OnboardingChecklist{
-		ID:          uuid.New(),
-		WorkspaceID: workspaceID,
-		Title:       req.Title,
-		Description: req.Description,
-		IsActive:    true,
-		CreatedBy:   userID,
-		CreatedAt:   time.Now(),
-		UpdatedAt:   time.Now(),
+		ID:           uuid.New(),
+		WorkspaceID:  workspaceID,
+		Title:        req.Title,
+		Description:  req.Description,
+		IsActive:     true,
+		RequiredOnly: req.RequiredOnly,
+		CreatedBy:    userID,
+		CreatedAt:    time.Now(),
+		UpdatedAt:    time.Now(),
 	}
 
 	if err := s.onboardingRepo.CreateChecklist(ctx, checklist); err != nil {
@@ -3655,6 +6388,9 @@ func (s *WorkspaceService) UpdateChecklist(ctx context.Context, workspaceID, use
 	if req.IsActive != nil {
 		checklist.IsActive = *req.IsActive
 	}
+	if req.RequiredOnly != nil {
+		checklist.RequiredOnly = *req.RequiredOnly
+	}
 
 	if err := s.onboardingRepo.UpdateChecklist(ctx, checklist); err != nil {
 		return nil, err
@@ -3695,22 +6431,113 @@ func (s *WorkspaceService) AddOnboardingStep(ctx context.Context, workspaceID, u
 
 	maxPos, _ := s.onboardingRepo.GetMaxStepPosition(ctx, checklistID)
 
-	step := &models.OnboardingStep{
-		ID:          uuid.New(),
-		ChecklistID: checklistID,
-		Title:       req.Title,
-		Description: req.Description,
-		ActionType:  req.ActionType,
-		ActionData:  req.ActionData,
-		Position:    maxPos + 1,
-		IsRequired:  req.IsRequired,
-		CreatedAt:   time.Now(),
+	step := &models.OnboardingStep{
+		ID:          uuid.New(),
+		ChecklistID: checklistID,
+		Title:       req.Title,
+		Description: req.Description,
+		ActionType:  req.ActionType,
+		ActionData:  req.ActionData,
+		Position:    maxPos + 1,
+		IsRequired:  req.IsRequired,
+		CreatedAt:   time.Now(),
+	}
+
+	if err := s.onboardingRepo.AddStep(ctx, step); err != nil {
+		return nil, err
+	}
+	return step, nil
+}
+
+func (s *WorkspaceService) UpdateOnboardingStep(ctx context.Context, workspaceID, userID, stepID uuid.UUID, req *models.UpdateStepRequest) (*models.OnboardingStep, error) {
+	role, _ := s.memberRepo.GetRole(ctx, workspaceID, userID)
+	if role != "owner" && role != "admin" {
+		return nil, ErrNotAuthorized
+	}
+
+	step, err := s.onboardingRepo.GetStepByID(ctx, stepID)
+	if err != nil {
+		return nil, err
+	}
+	if step == nil {
+		return nil, ErrOnboardingStepNotFound
+	}
+
+	checklist, err := s.onboardingRepo.GetChecklistByID(ctx, step.ChecklistID)
+	if err != nil || checklist == nil || checklist.WorkspaceID != workspaceID {
+		return nil, ErrOnboardingStepNotFound
+	}
+
+	if req.Title != nil {
+		step.Title = *req.Title
+	}
+	if req.Description != nil {
+		step.Description = req.Description
+	}
+	if req.ActionType != nil {
+		step.ActionType = *req.ActionType
+	}
+	if req.ActionData != nil {
+		step.ActionData = req.ActionData
+	}
+	if req.IsRequired != nil {
+		step.IsRequired = *req.IsRequired
+	}
+
+	if err := s.onboardingRepo.UpdateStep(ctx, step); err != nil {
+		return nil, err
+	}
+	return step, nil
+}
+
+func (s *WorkspaceService) ReorderOnboardingSteps(ctx context.Context, workspaceID, userID, checklistID uuid.UUID, req *models.ReorderStepsRequest) ([]models.OnboardingStep, error) {
+	role, _ := s.memberRepo.GetRole(ctx, workspaceID, userID)
+	if role != "owner" && role != "admin" {
+		return nil, ErrNotAuthorized
+	}
+
+	checklist, err := s.onboardingRepo.GetChecklistByID(ctx, checklistID)
+	if err != nil {
+		return nil, err
+	}
+	if checklist == nil || checklist.WorkspaceID != workspaceID {
+		return nil, ErrChecklistNotFound
+	}
+
+	steps, err := s.onboardingRepo.ListSteps(ctx, checklistID)
+	if err != nil {
+		return nil, err
+	}
+	inChecklist := make(map[uuid.UUID]bool, len(steps))
+	for _, step := range steps {
+		inChecklist[step.ID] = true
+	}
+
+	var stepIDs []uuid.UUID
+	seen := make(map[uuid.UUID]bool)
+	for _, raw := range req.StepIDs {
+		id, err := uuid.Parse(raw)
+		if err != nil || !inChecklist[id] {
+			return nil, ErrOnboardingStepNotFound
+		}
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		stepIDs = append(stepIDs, id)
+	}
+
+	// Steps left out of the request keep their relative order at the end
+	for _, step := range steps {
+		if !seen[step.ID] {
+			stepIDs = append(stepIDs, step.ID)
+		}
 	}
 
-	if err := s.onboardingRepo.AddStep(ctx, step); err != nil {
+	if err := s.onboardingRepo.UpdateStepPositions(ctx, checklistID, stepIDs); err != nil {
 		return nil, err
 	}
-	return step, nil
+	return s.onboardingRepo.ListSteps(ctx, checklistID)
 }
 
 func (s *WorkspaceService) DeleteOnboardingStep(ctx context.Context, workspaceID, userID, stepID uuid.UUID) error {
@@ -3750,6 +6577,13 @@ func (s *WorkspaceService) CompleteOnboardingStep(ctx context.Context, workspace
 		return ErrOnboardingStepNotFound
 	}
 
+	checklist, err := s.onboardingRepo.GetChecklistByID(ctx, step.ChecklistID)
+	if err != nil || checklist == nil || checklist.WorkspaceID != workspaceID {
+		return ErrOnboardingStepNotFound
+	}
+
+	before, _ := s.onboardingChecklistStatus(ctx, checklist, userID)
+
 	now := time.Now()
 	progress := &models.OnboardingProgress{
 		ID:          uuid.New(),
@@ -3759,7 +6593,25 @@ func (s *WorkspaceService) CompleteOnboardingStep(ctx context.Context, workspace
 		CreatedAt:   now,
 	}
 
-	return s.onboardingRepo.CompleteStep(ctx, progress)
+	if err := s.onboardingRepo.CompleteStep(ctx, progress); err != nil {
+		return err
+	}
+
+	if !checklist.IsActive || (before != nil && before.IsComplete) {
+		return nil
+	}
+	after, err := s.onboardingChecklistStatus(ctx, checklist, userID)
+	if err != nil || !after.IsComplete {
+		return nil
+	}
+
+	s.LogActivity(ctx, workspaceID, userID, "onboarding.completed", "onboarding_checklist", checklist.ID.String(), models.JSON{"title": checklist.Title})
+	s.publishEvent(ctx, "workspace-events", workspaceID.String(), "onboarding.completed", map[string]interface{}{
+		"workspace_id": workspaceID,
+		"checklist_id": checklist.ID,
+		"user_id":      userID,
+	})
+	return nil
 }
 
 func (s *WorkspaceService) GetMyOnboardingStatus(ctx context.Context, workspaceID, userID uuid.UUID) ([]*models.UserOnboardingStatus, error) {
@@ -3779,38 +6631,227 @@ func (s *WorkspaceService) GetMyOnboardingStatus(ctx context.Context, workspaceI
 			continue
 		}
 
-		steps, _ := s.onboardingRepo.ListSteps(ctx, cl.ID)
-		progress, _ := s.onboardingRepo.GetProgress(ctx, cl.ID, userID)
+		status, err := s.onboardingChecklistStatus(ctx, cl, userID)
+		if err != nil {
+			return nil, err
+		}
+		statuses = append(statuses, status)
+	}
+
+	return statuses, nil
+}
+
+// onboardingChecklistStatus computes a user's progress through a checklist.
+// When the checklist is RequiredOnly, only required steps count towards
+// completion unless it has no required steps at all.
+func (s *WorkspaceService) onboardingChecklistStatus(ctx context.Context, cl *models.OnboardingChecklist, userID uuid.UUID) (*models.UserOnboardingStatus, error) {
+	steps, err := s.onboardingRepo.ListSteps(ctx, cl.ID)
+	if err != nil {
+		return nil, err
+	}
+	progress, err := s.onboardingRepo.GetProgress(ctx, cl.ID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	progressMap := make(map[uuid.UUID]*models.OnboardingProgress)
+	for _, p := range progress {
+		progressMap[p.StepID] = p
+	}
+
+	requiredOnly := cl.RequiredOnly && countRequiredSteps(steps) > 0
 
-		progressMap := make(map[uuid.UUID]*models.OnboardingProgress)
-		for _, p := range progress {
-			progressMap[p.StepID] = p
+	var stepsWithProgress []models.StepWithProgress
+	completedCount, totalSteps := 0, 0
+	for _, step := range steps {
+		swp := models.StepWithProgress{
+			OnboardingStep: step,
+		}
+		counts := !requiredOnly || step.IsRequired
+		if counts {
+			totalSteps++
+		}
+		if p, ok := progressMap[step.ID]; ok {
+			swp.Completed = true
+			swp.CompletedAt = p.CompletedAt
+			if counts {
+				completedCount++
+			}
 		}
+		stepsWithProgress = append(stepsWithProgress, swp)
+	}
+
+	pct := 100.0
+	if totalSteps > 0 {
+		pct = percentage(completedCount, totalSteps)
+	}
+
+	return &models.UserOnboardingStatus{
+		Checklist:      *cl,
+		Steps:          stepsWithProgress,
+		CompletedCount: completedCount,
+		TotalSteps:     totalSteps,
+		Percentage:     pct,
+		IsComplete:     completedCount >= totalSteps,
+	}, nil
+}
 
-		var stepsWithProgress []models.StepWithProgress
-		completedCount := 0
-		for _, step := range steps {
-			swp := models.StepWithProgress{
-				OnboardingStep: step,
+// GetMyWorkspaceReadiness combines the member's onboarding progress on active
+// checklists with their enforced policy acknowledgements into one score.
+// Every countable step and every policy weighs the same.
+func (s *WorkspaceService) GetMyWorkspaceReadiness(ctx context.Context, workspaceID, userID uuid.UUID) (*models.MemberReadiness, error) {
+	statuses, err := s.GetMyOnboardingStatus(ctx, workspaceID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	readiness := &models.MemberReadiness{Remaining: []*models.ReadinessItem{}}
+	for _, status := range statuses {
+		readiness.OnboardingCompleted += status.CompletedCount
+		readiness.OnboardingTotal += status.TotalSteps
+
+		// Mirror onboardingChecklistStatus: optional steps don't count on
+		// required-only checklists
+		hasRequired := false
+		for _, step := range status.Steps {
+			hasRequired = hasRequired || step.IsRequired
+		}
+		requiredOnly := status.Checklist.RequiredOnly && hasRequired
+		checklistID := status.Checklist.ID
+		for _, step := range status.Steps {
+			if step.Completed || (requiredOnly && !step.IsRequired) {
+				continue
 			}
-			if p, ok := progressMap[step.ID]; ok {
-				swp.Completed = true
-				swp.CompletedAt = p.CompletedAt
-				completedCount++
+			readiness.Remaining = append(readiness.Remaining, &models.ReadinessItem{
+				Type:        "onboarding_step",
+				ID:          step.ID,
+				Title:       step.Title,
+				ChecklistID: &checklistID,
+			})
+		}
+	}
+
+	policies, err := s.complianceRepo.ListByType(ctx, workspaceID, "acknowledgement")
+	if err != nil {
+		return nil, err
+	}
+	outstanding, err := s.OutstandingPolicyAcknowledgements(ctx, workspaceID, userID)
+	if err != nil {
+		return nil, err
+	}
+	outstandingSet := make(map[uuid.UUID]bool, len(outstanding))
+	for _, id := range outstanding {
+		outstandingSet[id] = true
+	}
+	for _, policy := range policies {
+		if !policy.IsEnforced {
+			continue
+		}
+		readiness.PoliciesTotal++
+		if !outstandingSet[policy.ID] {
+			readiness.PoliciesAcknowledged++
+			continue
+		}
+		readiness.Remaining = append(readiness.Remaining, &models.ReadinessItem{
+			Type:  "policy_acknowledgement",
+			ID:    policy.ID,
+			Title: policy.Name,
+		})
+	}
+
+	readiness.CompletedItems = readiness.OnboardingCompleted + readiness.PoliciesAcknowledged
+	readiness.TotalItems = readiness.OnboardingTotal + readiness.PoliciesTotal
+	readiness.Percentage = readinessPercentage(readiness.CompletedItems, readiness.TotalItems)
+	readiness.IsReady = readiness.CompletedItems >= readiness.TotalItems
+	return readiness, nil
+}
+
+// readinessPercentage rounds down so a member only reaches 100 once every
+// item is done. Nothing to do counts as fully ready.
+func readinessPercentage(completed, total int) int {
+	if total == 0 || completed >= total {
+		return 100
+	}
+	return completed * 100 / total
+}
+
+func (s *WorkspaceService) GetWorkspaceOnboardingStats(ctx context.Context, workspaceID, userID uuid.UUID) (*models.WorkspaceOnboardingStats, error) {
+	role, _ := s.memberRepo.GetRole(ctx, workspaceID, userID)
+	if role != "owner" && role != "admin" {
+		return nil, ErrNotAuthorized
+	}
+
+	memberCount, err := s.workspaceRepo.GetMemberCount(ctx, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	checklists, err := s.onboardingRepo.ListChecklists(ctx, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &models.WorkspaceOnboardingStats{
+		MemberCount: memberCount,
+		Checklists:  []*models.ChecklistCompletionStats{},
+	}
+	for _, cl := range checklists {
+		if !cl.IsActive {
+			continue
+		}
+
+		steps, err := s.onboardingRepo.ListSteps(ctx, cl.ID)
+		if err != nil {
+			return nil, err
+		}
+		requiredOnly := cl.RequiredOnly && countRequiredSteps(steps) > 0
+		totalSteps := len(steps)
+		if requiredOnly {
+			totalSteps = countRequiredSteps(steps)
+		}
+
+		perUser, err := s.onboardingRepo.CountCompletedByUser(ctx, cl.ID, requiredOnly)
+		if err != nil {
+			return nil, err
+		}
+
+		completed := 0
+		for _, n := range perUser {
+			if n >= totalSteps {
+				completed++
 			}
-			stepsWithProgress = append(stepsWithProgress, swp)
+		}
+		if totalSteps == 0 {
+			completed = memberCount
 		}
 
-		statuses = append(statuses, &models.UserOnboardingStatus{
+		stats.Checklists = append(stats.Checklists, &models.ChecklistCompletionStats{
 			Checklist:      *cl,
-			Steps:          stepsWithProgress,
-			CompletedCount: completedCount,
-			TotalSteps:     len(steps),
-			IsComplete:     completedCount >= len(steps),
+			TotalSteps:     totalSteps,
+			StartedCount:   len(perUser),
+			CompletedCount: completed,
+			CompletionRate: percentage(completed, memberCount),
 		})
 	}
 
-	return statuses, nil
+	return stats, nil
+}
+
+func countRequiredSteps(steps []models.OnboardingStep) int {
+	n := 0
+	for _, step := range steps {
+		if step.IsRequired {
+			n++
+		}
+	}
+	return n
+}
+
+func percentage(part, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(part) / float64(total) * 100
 }
 
 // ── Compliance Policies ──
@@ -3822,22 +6863,24 @@ func (s *WorkspaceService) CreatePolicy(ctx context.Context, workspaceID, userID
 	}
 
 	policy := &models.CompliancePolicy{
-		ID:          uuid.New(),
-		WorkspaceID: workspaceID,
-		Name:        req.Name,
-		Description: req.Description,
-		PolicyType:  req.PolicyType,
-		Rules:       req.Rules,
-		Severity:    req.Severity,
-		IsEnforced:  req.IsEnforced,
-		CreatedBy:   userID,
-		CreatedAt:   time.Now(),
-		UpdatedAt:   time.Now(),
+		ID:                uuid.New(),
+		WorkspaceID:       workspaceID,
+		Name:              req.Name,
+		Description:       req.Description,
+		PolicyType:        req.PolicyType,
+		Rules:             req.Rules,
+		Severity:          req.Severity,
+		IsEnforced:        req.IsEnforced,
+		ReackIntervalDays: req.ReackIntervalDays,
+		CreatedBy:         userID,
+		CreatedAt:         time.Now(),
+		UpdatedAt:         time.Now(),
 	}
 
 	if err := s.complianceRepo.Create(ctx, policy); err != nil {
 		return nil, err
 	}
+	s.invalidatePolicyAcks(ctx, workspaceID)
 	return policy, nil
 }
 
@@ -3878,10 +6921,18 @@ func (s *WorkspaceService) UpdatePolicy(ctx context.Context, workspaceID, userID
 	if req.IsEnforced != nil {
 		policy.IsEnforced = *req.IsEnforced
 	}
+	if req.ReackIntervalDays != nil {
+		if *req.ReackIntervalDays == 0 {
+			policy.ReackIntervalDays = nil
+		} else {
+			policy.ReackIntervalDays = req.ReackIntervalDays
+		}
+	}
 
 	if err := s.complianceRepo.Update(ctx, policy); err != nil {
 		return nil, err
 	}
+	s.invalidatePolicyAcks(ctx, workspaceID)
 	return policy, nil
 }
 
@@ -3899,7 +6950,11 @@ func (s *WorkspaceService) DeletePolicy(ctx context.Context, workspaceID, userID
 		return ErrPolicyNotFound
 	}
 
-	return s.complianceRepo.Delete(ctx, policyID)
+	if err := s.complianceRepo.Delete(ctx, policyID); err != nil {
+		return err
+	}
+	s.invalidatePolicyAcks(ctx, workspaceID)
+	return nil
 }
 
 func (s *WorkspaceService) AcknowledgePolicy(ctx context.Context, workspaceID, userID, policyID uuid.UUID) error {
@@ -3923,7 +6978,59 @@ func (s *WorkspaceService) AcknowledgePolicy(ctx context.Context, workspaceID, u
 		AckedAt:  time.Now(),
 	}
 
-	return s.complianceRepo.Acknowledge(ctx, ack)
+	if err := s.complianceRepo.Acknowledge(ctx, ack); err != nil {
+		return err
+	}
+	s.kv.HDel(ctx, fmt.Sprintf(cacheKeyPolicyAcks, workspaceID.String()), userID.String())
+	return nil
+}
+
+// cachedPolicyAcks is a user's entry in the policy_acks cache. ValidUntil
+// is stored with it because the hash's own TTL is shared by every user in
+// the workspace.
+type cachedPolicyAcks struct {
+	PolicyIDs  []uuid.UUID `json:"policy_ids"`
+	ValidUntil time.Time   `json:"valid_until"`
+}
+
+// IsMember reports whether the user is an active member of the workspace.
+func (s *WorkspaceService) IsMember(ctx context.Context, workspaceID, userID uuid.UUID) (bool, error) {
+	return s.memberRepo.IsMember(ctx, workspaceID, userID)
+}
+
+// OutstandingPolicyAcknowledgements lists the enforced acknowledgement
+// policies the user still has to acknowledge. Results are cached per user in
+// Redis since it is checked on every write request, but never past the
+// moment one of the user's acknowledgements expires.
+func (s *WorkspaceService) OutstandingPolicyAcknowledgements(ctx context.Context, workspaceID, userID uuid.UUID) ([]uuid.UUID, error) {
+	key := fmt.Sprintf(cacheKeyPolicyAcks, workspaceID.String())
+	data, err := s.kv.HGet(ctx, key, userID.String())
+	var cached cachedPolicyAcks
+	hit := err == nil && json.Unmarshal(data, &cached) == nil && time.Now().Before(cached.ValidUntil)
+	s.recordCacheLookup("policy_acks", hit)
+	if hit {
+		return cached.PolicyIDs, nil
+	}
+
+	ids, err := s.complianceRepo.ListOutstandingAcknowledgements(ctx, workspaceID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	ttl := cacheTTL
+	next, err := s.complianceRepo.NextAcknowledgementExpiry(ctx, workspaceID, userID)
+	if err != nil {
+		return ids, nil
+	}
+	if next != nil && time.Until(*next) < ttl {
+		ttl = time.Until(*next)
+	}
+	if ttl > 0 {
+		if data, err := json.Marshal(cachedPolicyAcks{PolicyIDs: ids, ValidUntil: time.Now().Add(ttl)}); err == nil {
+			s.kv.HSet(ctx, key, userID.String(), data, cacheTTL)
+		}
+	}
+	return ids, nil
 }
 
 func (s *WorkspaceService) GetPolicyComplianceStatus(ctx context.Context, workspaceID, userID, policyID uuid.UUID) (*models.PolicyComplianceStatus, error) {
@@ -3959,26 +7066,108 @@ func (s *WorkspaceService) GetPolicyComplianceStatus(ctx context.Context, worksp
 	}, nil
 }
 
+// ExportPolicyAcknowledgements lists every active member with whether and
+// when they acknowledged the policy, including members who never have.
+func (s *WorkspaceService) ExportPolicyAcknowledgements(ctx context.Context, workspaceID, policyID, userID uuid.UUID) (*models.PolicyAcknowledgementExport, error) {
+	role, _ := s.memberRepo.GetRole(ctx, workspaceID, userID)
+	if role != "owner" && role != "admin" {
+		return nil, ErrNotAuthorized
+	}
+
+	policy, err := s.complianceRepo.GetByID(ctx, policyID)
+	if err != nil {
+		return nil, err
+	}
+	if policy == nil || policy.WorkspaceID != workspaceID {
+		return nil, ErrPolicyNotFound
+	}
+
+	acks, err := s.complianceRepo.ListAcknowledgements(ctx, policyID)
+	if err != nil {
+		return nil, err
+	}
+	ackedAt := make(map[uuid.UUID]time.Time, len(acks))
+	for _, ack := range acks {
+		ackedAt[ack.UserID] = ack.AckedAt
+	}
+
+	export := &models.PolicyAcknowledgementExport{
+		PolicyID:   policy.ID,
+		PolicyName: policy.Name,
+		Members:    []*models.PolicyAcknowledgementRow{},
+		ExportedAt: time.Now(),
+	}
+
+	const perPage = 500
+	for page := 1; ; page++ {
+		members, total, err := s.memberRepo.ListByWorkspace(ctx, workspaceID, page, perPage)
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range members {
+			row := &models.PolicyAcknowledgementRow{UserID: m.UserID, Role: m.Role}
+			if at, ok := ackedAt[m.UserID]; ok {
+				at := at
+				row.AckedAt = &at
+				if acknowledgementExpired(policy, at, export.ExportedAt) {
+					row.Expired = true
+				} else {
+					row.Acknowledged = true
+					export.AcknowledgedCount++
+				}
+			}
+			export.Members = append(export.Members, row)
+		}
+		if len(members) < perPage || int64(page*perPage) >= total {
+			break
+		}
+	}
+	export.TotalMembers = len(export.Members)
+
+	return export, nil
+}
+
+// acknowledgementExpired reports whether an acknowledgement made at ackedAt
+// has lapsed under the policy's re-acknowledgement interval.
+func acknowledgementExpired(policy *models.CompliancePolicy, ackedAt, now time.Time) bool {
+	if policy.ReackIntervalDays == nil {
+		return false
+	}
+	return ackedAt.Before(now.AddDate(0, 0, -*policy.ReackIntervalDays))
+}
+
+// ── Capabilities ──
+
+// Capabilities lists the optional subsystems that are active in this process.
+func (s *WorkspaceService) Capabilities() []string {
+	capabilities := []string{}
+	if s.kv.Available() {
+		capabilities = append(capabilities, "redis")
+	}
+	if s.kafka != nil {
+		capabilities = append(capabilities, "kafka")
+	}
+	if s.auditSink.Available() {
+		capabilities = append(capabilities, "audit_sink")
+	}
+	return capabilities
+}
+
 // ── Redis Cache Helpers ──
 
 func (s *WorkspaceService) cacheWorkspace(ctx context.Context, id uuid.UUID, workspace *models.Workspace) {
-	if s.redis == nil {
-		return
-	}
 	data, err := json.Marshal(workspace)
 	if err != nil {
 		return
 	}
 	key := fmt.Sprintf(cacheKeyWorkspace, id.String())
-	s.redis.Set(ctx, key, data, cacheTTL)
+	s.kv.Set(ctx, key, data, s.cacheConfig.WorkspaceTTL)
 }
 
 func (s *WorkspaceService) getCachedWorkspaceResponse(ctx context.Context, id uuid.UUID, userID uuid.UUID) (*models.WorkspaceResponse, error) {
-	if s.redis == nil {
-		return nil, fmt.Errorf("no redis")
-	}
 	key := fmt.Sprintf(cacheKeyWorkspace, id.String())
-	data, err := s.redis.Get(ctx, key).Bytes()
+	data, err := s.kv.Get(ctx, key)
+	s.recordCacheLookup("workspace", err == nil)
 	if err != nil {
 		return nil, err
 	}
@@ -3989,30 +7178,26 @@ func (s *WorkspaceService) getCachedWorkspaceResponse(ctx context.Context, id uu
 	memberCount, _ := s.workspaceRepo.GetMemberCount(ctx, id)
 	role, _ := s.memberRepo.GetRole(ctx, id, userID)
 	return &models.WorkspaceResponse{
-		Workspace:   &workspace,
-		MemberCount: memberCount,
-		MyRole:      role,
+		Workspace:    &workspace,
+		MemberCount:  memberCount,
+		ChannelCount: s.channelCount(ctx, id),
+		MyRole:       role,
 	}, nil
 }
 
 func (s *WorkspaceService) cacheStats(ctx context.Context, workspaceID uuid.UUID, stats *models.WorkspaceStats) {
-	if s.redis == nil {
-		return
-	}
 	data, err := json.Marshal(stats)
 	if err != nil {
 		return
 	}
 	key := fmt.Sprintf(cacheKeyStats, workspaceID.String())
-	s.redis.Set(ctx, key, data, cacheTTL)
+	s.kv.Set(ctx, key, data, s.cacheConfig.StatsTTL)
 }
 
 func (s *WorkspaceService) getCachedStats(ctx context.Context, workspaceID uuid.UUID) (*models.WorkspaceStats, error) {
-	if s.redis == nil {
-		return nil, fmt.Errorf("no redis")
-	}
 	key := fmt.Sprintf(cacheKeyStats, workspaceID.String())
-	data, err := s.redis.Get(ctx, key).Bytes()
+	data, err := s.kv.Get(ctx, key)
+	s.recordCacheLookup("stats", err == nil)
 	if err != nil {
 		return nil, err
 	}
@@ -4023,24 +7208,71 @@ func (s *WorkspaceService) getCachedStats(ctx context.Context, workspaceID uuid.
 	return &stats, nil
 }
 
-func (s *WorkspaceService) invalidateWorkspace(ctx context.Context, workspaceID uuid.UUID) {
-	if s.redis == nil {
-		return
+// tombstoneWorkspace remembers briefly that id does not exist so repeated
+// lookups of unknown IDs do not each reach MySQL.
+func (s *WorkspaceService) tombstoneWorkspace(ctx context.Context, id uuid.UUID) {
+	s.kv.Set(ctx, fmt.Sprintf(cacheKeyMissing, id.String()), 1, s.cacheConfig.NegativeTTL)
+}
+
+func (s *WorkspaceService) isWorkspaceTombstoned(ctx context.Context, id uuid.UUID) bool {
+	hit := s.kv.Exists(ctx, fmt.Sprintf(cacheKeyMissing, id.String()))
+	s.recordCacheLookup("workspace_missing", hit)
+	return hit
+}
+
+func (s *WorkspaceService) clearWorkspaceTombstone(ctx context.Context, id uuid.UUID) {
+	s.kv.Del(ctx, fmt.Sprintf(cacheKeyMissing, id.String()))
+}
+
+// recordCacheLookup counts a hit or miss for the named cache in the metrics
+// registry and in running totals logged at debug level.
+func (s *WorkspaceService) recordCacheLookup(cache string, hit bool) {
+	s.metrics.CacheLookup(cache, hit)
+
+	counters := &s.cacheMisses
+	if hit {
+		counters = &s.cacheHits
+	}
+	v, _ := counters.LoadOrStore(cache, new(int64))
+	atomic.AddInt64(v.(*int64), 1)
+
+	if s.logger.IsLevelEnabled(logrus.DebugLevel) {
+		s.logger.WithFields(logrus.Fields{
+			"cache":  cache,
+			"hit":    hit,
+			"hits":   loadCounter(&s.cacheHits, cache),
+			"misses": loadCounter(&s.cacheMisses, cache),
+		}).Debug("Cache lookup")
+	}
+}
+
+func loadCounter(counters *sync.Map, cache string) int64 {
+	if v, ok := counters.Load(cache); ok {
+		return atomic.LoadInt64(v.(*int64))
 	}
-	keys := []string{
+	return 0
+}
+
+func (s *WorkspaceService) invalidateWorkspace(ctx context.Context, workspaceID uuid.UUID) {
+	s.kv.Del(ctx, workspaceCacheKeys(workspaceID)...)
+}
+
+// workspaceCacheKeys are the cache entries derived from a workspace row and
+// its members, for any service that changes them.
+func workspaceCacheKeys(workspaceID uuid.UUID) []string {
+	return []string{
 		fmt.Sprintf(cacheKeyWorkspace, workspaceID.String()),
 		fmt.Sprintf(cacheKeyMembers, workspaceID.String()),
 		fmt.Sprintf(cacheKeyStats, workspaceID.String()),
 	}
-	s.redis.Del(ctx, keys...)
 }
 
 func (s *WorkspaceService) invalidateUserWorkspaces(ctx context.Context, userID uuid.UUID) {
-	if s.redis == nil {
-		return
-	}
-	key := fmt.Sprintf(cacheKeyUserWsList, userID.String())
-	s.redis.Del(ctx, key)
+	s.kv.Del(ctx, fmt.Sprintf(cacheKeyUserWsList, userID.String()))
+}
+
+func (s *WorkspaceService) invalidatePolicyAcks(ctx context.Context, workspaceID uuid.UUID) {
+	s.kv.Del(ctx, fmt.Sprintf(cacheKeyPolicyAcks, workspaceID.String()))
 }
 
 // ── Kafka Event Helpers ──
@@ -4049,11 +7281,132 @@ func (s *WorkspaceService) publishEvent(ctx context.Context, topic, key, eventTy
 	if s.kafka == nil {
 		return
 	}
-	data["type"] = eventType
-	data["timestamp"] = time.Now()
-	if err := s.kafka.Publish(ctx, topic, key, data); err != nil {
-		s.logger.WithError(err).WithField("event_type", eventType).Warn("Failed to publish event")
+	// The payload travels under "data" so the caller's map is never mutated
+	// and envelope fields cannot collide with payload fields.
+	envelope := newEventEnvelope(ctx, eventType, data)
+
+	// Prefer the outbox so the event survives a broker outage; publish
+	// directly only if it cannot be written there.
+	if s.outboxRepo != nil {
+		event, err := newOutboxEvent(topic, key, envelope)
+		if err == nil {
+			err = s.outboxRepo.Enqueue(ctx, event)
+		}
+		if err == nil {
+			return
+		}
+		s.requestLogger(ctx).WithError(err).WithField("event_type", eventType).Warn("Failed to enqueue event, publishing directly")
+	}
+
+	if err := s.kafka.Publish(ctx, topic, key, envelope); err != nil {
+		s.metrics.KafkaPublishFailed()
+		s.requestLogger(ctx).WithError(err).WithField("event_type", eventType).Warn("Failed to publish event")
+	}
+}
+
+// createMemberWithEvent inserts the member and, when the outbox is in use,
+// enqueues the join event in the same transaction.
+func (s *WorkspaceService) createMemberWithEvent(ctx context.Context, member *models.WorkspaceMember, topic, key, eventType string, data map[string]interface{}) error {
+	return s.writeWithEvent(ctx,
+		func() error { return s.memberRepo.Create(ctx, member) },
+		func(event *models.OutboxEvent) error { return s.memberRepo.CreateWithEvent(ctx, member, event) },
+		topic, key, eventType, data)
+}
+
+// removeMemberWithEvent deactivates the member and, when the outbox is in
+// use, enqueues eventType in the same transaction.
+func (s *WorkspaceService) removeMemberWithEvent(ctx context.Context, workspaceID, userID uuid.UUID, eventType string, data map[string]interface{}) error {
+	return s.writeWithEvent(ctx,
+		func() error { return s.memberRepo.Remove(ctx, workspaceID, userID) },
+		func(event *models.OutboxEvent) error {
+			return s.memberRepo.RemoveWithEvent(ctx, workspaceID, userID, event)
+		},
+		"workspace-events", workspaceID.String(), eventType, data)
+}
+
+// updateMemberRoleWithEvent changes the member's role and, when the outbox
+// is in use, enqueues eventType in the same transaction.
+func (s *WorkspaceService) updateMemberRoleWithEvent(ctx context.Context, workspaceID, userID uuid.UUID, role, eventType string, data map[string]interface{}) error {
+	return s.writeWithEvent(ctx,
+		func() error { return s.memberRepo.UpdateRole(ctx, workspaceID, userID, role) },
+		func(event *models.OutboxEvent) error {
+			return s.memberRepo.UpdateRoleWithEvent(ctx, workspaceID, userID, role, event)
+		},
+		"workspace-events", workspaceID.String(), eventType, data)
+}
+
+// writeWithEvent makes a state change together with the event announcing
+// it. With the outbox in use, writeTx makes the change and enqueues the
+// event in one transaction; otherwise write makes it and the event is
+// published afterwards.
+func (s *WorkspaceService) writeWithEvent(ctx context.Context, write func() error, writeTx func(*models.OutboxEvent) error, topic, key, eventType string, data map[string]interface{}) error {
+	if s.kafka == nil || s.outboxRepo == nil {
+		if err := write(); err != nil {
+			return err
+		}
+		s.publishEvent(ctx, topic, key, eventType, data)
+		return nil
+	}
+
+	event, err := newOutboxEvent(topic, key, newEventEnvelope(ctx, eventType, data))
+	if err != nil {
+		return err
+	}
+	return writeTx(event)
+}
+
+// newEventEnvelope wraps data for publishing, tagged with the ID of the
+// request that raised it, if any.
+func newEventEnvelope(ctx context.Context, eventType string, data map[string]interface{}) models.EventEnvelope {
+	return models.EventEnvelope{
+		SchemaVersion: models.EventSchemaVersion,
+		Type:          eventType,
+		Timestamp:     time.Now().UTC(),
+		RequestID:     requestid.FromContext(ctx),
+		Data:          copyEventData(data),
+	}
+}
+
+// requestLogger returns the service logger annotated with ctx's request ID.
+func (s *WorkspaceService) requestLogger(ctx context.Context) *logrus.Entry {
+	entry := logrus.NewEntry(s.logger)
+	if id := requestid.FromContext(ctx); id != "" {
+		entry = entry.WithField("request_id", id)
+	}
+	return entry
+}
+
+func newOutboxEvent(topic, key string, envelope models.EventEnvelope) (*models.OutboxEvent, error) {
+	payload, err := json.Marshal(envelope)
+	if err != nil {
+		return nil, err
+	}
+	return &models.OutboxEvent{
+		ID:        uuid.New(),
+		Topic:     topic,
+		EventKey:  key,
+		EventType: envelope.Type,
+		Payload:   payload,
+		CreatedAt: time.Now(),
+	}, nil
+}
+
+// OutboxStats reports how far the outbox relay is behind.
+func (s *WorkspaceService) OutboxStats(ctx context.Context) (*models.OutboxStats, error) {
+	if s.outboxRepo == nil {
+		return &models.OutboxStats{}, nil
+	}
+	return s.outboxRepo.GetStats(ctx)
+}
+
+// copyEventData returns a shallow copy of an event payload. A nil payload
+// becomes an empty map so consumers always see an object under "data".
+func copyEventData(data map[string]interface{}) map[string]interface{} {
+	copied := make(map[string]interface{}, len(data))
+	for k, v := range data {
+		copied[k] = v
 	}
+	return copied
 }
 
 // ── Token/Code Generators ──
@@ -4065,9 +7418,15 @@ func generateToken() string {
 }
 
 func generateInviteCode() string {
+	return randomCode(8)
+}
+
+// randomCode returns a random code from an alphabet without look-alike
+// characters.
+func randomCode(length int) string {
 	const charset = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
 	var sb strings.Builder
-	for i := 0; i < 8; i++ {
+	for i := 0; i < length; i++ {
 		n, _ := rand.Int(rand.Reader, big.NewInt(int64(len(charset))))
 		sb.WriteByte(charset[n.Int64()])
 	}
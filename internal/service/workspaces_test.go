@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+)
+
+// expectWorkspaceList expects the queries ListWorkspaces makes for a user in
+// n workspaces: the page itself and one batched lookup each for member
+// counts, roles and channel counts.
+func expectWorkspaceList(mock sqlmock.Sqlmock, userID uuid.UUID, n int) []uuid.UUID {
+	ids := make([]uuid.UUID, n)
+	list := sqlmock.NewRows([]string{"id", "name", "slug", "owner_id"})
+	counts := sqlmock.NewRows([]string{"workspace_id", "count"})
+	roles := sqlmock.NewRows([]string{"workspace_id", "role"})
+	channels := sqlmock.NewRows([]string{"workspace_id", "current_channels"})
+	for i := range ids {
+		ids[i] = uuid.New()
+		list.AddRow(ids[i].String(), "Workspace", "workspace", uuid.New().String())
+		counts.AddRow(ids[i].String(), i+1)
+		roles.AddRow(ids[i].String(), "member")
+		channels.AddRow(ids[i].String(), 3)
+	}
+
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM workspaces w`).
+		WithArgs(userID).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(n))
+	mock.ExpectQuery(`SELECT w.\* FROM workspaces w`).
+		WithArgs(userID, n, 0).
+		WillReturnRows(list)
+	mock.ExpectQuery(`SELECT workspace_id, COUNT\(\*\) as count FROM workspace_members\s+WHERE workspace_id IN`).
+		WillReturnRows(counts)
+	mock.ExpectQuery(`SELECT workspace_id, role FROM workspace_members\s+WHERE workspace_id IN`).
+		WillReturnRows(roles)
+	mock.ExpectQuery(`SELECT workspace_id, current_channels FROM workspace_quotas WHERE workspace_id IN`).
+		WillReturnRows(channels)
+	return ids
+}
+
+func TestListWorkspacesBatchesPerWorkspaceLookups(t *testing.T) {
+	s, mock := newTestService(t)
+	userID := uuid.New()
+	ids := expectWorkspaceList(mock, userID, 50)
+
+	// Only the five expected queries are answered; a per-workspace lookup
+	// would fail against the mock.
+	resp, err := s.ListWorkspaces(context.Background(), userID, 1, 50)
+	if err != nil {
+		t.Fatalf("ListWorkspaces: %v", err)
+	}
+	if len(resp.Workspaces) != 50 || resp.Total != 50 {
+		t.Fatalf("got %d of %d workspaces, want 50", len(resp.Workspaces), resp.Total)
+	}
+	last := resp.Workspaces[49]
+	if last.Workspace.ID != ids[49] || last.MemberCount != 50 || last.MyRole != "member" || last.ChannelCount != 3 {
+		t.Errorf("last workspace = %+v, want its batched counts and role", last)
+	}
+}
+
+func BenchmarkListWorkspaces50(b *testing.B) {
+	s, mock := newTestService(b)
+	userID := uuid.New()
+	ctx := context.Background()
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		expectWorkspaceList(mock, userID, 50)
+		b.StartTimer()
+
+		if _, err := s.ListWorkspaces(ctx, userID, 1, 50); err != nil {
+			b.Fatalf("ListWorkspaces: %v", err)
+		}
+	}
+}
@@ -0,0 +1,13 @@
+package version
+
+// Set at build time via
+//
+//	-ldflags "-X github.com/quckapp/workspace-service/internal/version.Version=... -X github.com/quckapp/workspace-service/internal/version.Commit=..."
+var (
+	Version   = "dev"
+	Commit    = "unknown"
+	BuildTime = ""
+)
+
+// APIVersion is the HTTP API version served under /api.
+const APIVersion = "v1"